@@ -0,0 +1,507 @@
+// Package bootstrap wires the repositories, services, event subscribers and
+// background workers behind the API server. The serve command runs it with
+// the configured providers; integration tests run it with fakes in their
+// place.
+package bootstrap
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/events"
+	"github.com/techagentng/citizenx/geo"
+	"github.com/techagentng/citizenx/identity"
+	"github.com/techagentng/citizenx/jobs"
+	"github.com/techagentng/citizenx/mailingservices"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/outbox"
+	"github.com/techagentng/citizenx/payments"
+	"github.com/techagentng/citizenx/search"
+	"github.com/techagentng/citizenx/server"
+	"github.com/techagentng/citizenx/services"
+	"github.com/techagentng/citizenx/sms"
+	"github.com/techagentng/citizenx/storage"
+	"github.com/techagentng/citizenx/streaming"
+)
+
+// SigningKeyReload is how often a server reloads signing keys. Rotated keys
+// are published for longer than this before they start signing.
+const SigningKeyReload = 5 * time.Minute
+
+// Providers are the outside services the server talks to. A nil provider
+// turns off the features that need it, as an unconfigured one does in
+// production.
+type Providers struct {
+	Mail      mailingservices.Mailer
+	SMS       sms.Sender
+	Store     storage.Store
+	Geocoder  *geo.GoogleGeocoder
+	IPLocator geo.IPLocator
+	Identity  identity.Verifier
+	Payments  payments.Provider
+}
+
+// ConfiguredProviders returns the providers selected by the configuration
+func ConfiguredProviders(conf *config.Config) (Providers, error) {
+	mailgunClient := &mailingservices.Mailgun{}
+	mailgunClient.Init()
+	providers := Providers{
+		Mail:     mailgunClient,
+		Geocoder: geo.NewGoogleGeocoder(conf.GoogleMapsApiKey),
+	}
+
+	store, err := storage.New(conf)
+	if err != nil {
+		return Providers{}, err
+	}
+	providers.Store = store
+
+	// The constructors return nil pointers for providers without
+	// credentials, which must stay nil interfaces
+	if termii := sms.NewTermii(conf.TermiiAPIKey, conf.SMSSenderID); termii != nil {
+		providers.SMS = termii
+	}
+	if locator := geo.NewMaxMindLocator(conf.MaxMindAccountID, conf.MaxMindLicenseKey); locator != nil {
+		providers.IPLocator = locator
+	}
+	if dojah := identity.NewDojah(conf.DojahAppID, conf.DojahSecretKey); dojah != nil {
+		providers.Identity = dojah
+	}
+	if paystack := payments.NewPaystack(conf.PaystackSecretKey); paystack != nil {
+		providers.Payments = paystack
+	}
+	return providers, nil
+}
+
+// App is a wired server with its background workers running
+type App struct {
+	Server *server.Server
+
+	bus         *events.InProcessBus
+	publisher   streaming.Publisher
+	stopWorkers context.CancelFunc
+	workers     sync.WaitGroup
+}
+
+// New wires the server on gormDB and starts its background workers, which
+// run until Close
+func New(conf *config.Config, gormDB *db.GormDB, providers Providers) (*App, error) {
+	a := &App{}
+	var run []func(ctx context.Context)
+	runWorker := func(worker func(ctx context.Context)) {
+		run = append(run, worker)
+	}
+
+	authRepo := db.NewAuthRepo(gormDB)
+	mediaRepo := db.NewMediaRepo(gormDB)
+	incidentReportRepo := db.NewIncidentReportRepo(gormDB)
+	rewardRepo := db.NewRewardRepo(gormDB)
+	likeRepo := db.NewLikeRepo(gormDB)
+	postRepo := db.NewPostRepo(gormDB)
+	notificationRepo := db.NewNotificationRepo(gormDB)
+	geoRepo := db.NewGeoRepo(gormDB)
+	analyticsRepo := db.NewAnalyticsRepo(gormDB)
+	locationRepo := db.NewLocationRepo(gormDB)
+	adminRepo := db.NewAdminRepo(gormDB)
+	growthRepo := db.NewGrowthRepo(gormDB)
+	jobRepo := db.NewJobRepo(gormDB)
+	activityRepo := db.NewActivityRepo(gormDB)
+	announcementRepo := db.NewAnnouncementRepo(gormDB)
+
+	outboxRepo := db.NewOutboxRepo(gormDB)
+
+	// Subscribers react to the domain events the services save to the
+	// outbox; the relay delivers them
+	a.bus = events.NewBus()
+	notificationService := services.NewNotificationService(notificationRepo, conf)
+	if providers.Mail != nil {
+		notificationService.SetSender(models.ChannelEmail, services.NewEmailSender(authRepo, providers.Mail))
+	}
+	shortLinkService := services.NewShortLinkService(db.NewShortLinkRepo(gormDB), conf)
+	// Phone sign-in and SMS notifications are unavailable until an SMS
+	// provider is configured
+	if providers.SMS != nil {
+		notificationService.SetSender(models.ChannelSMS, services.NewSMSSender(authRepo, providers.SMS, shortLinkService))
+	}
+	a.bus.Subscribe(events.AllEvents, events.LogEvents)
+	activityService := services.NewActivityService(activityRepo, jobRepo, conf)
+	var addressGeocoder geo.AddressGeocoder
+	if providers.Geocoder != nil {
+		services.NewWardService(geoRepo, providers.Geocoder, conf).Subscribe(a.bus)
+		addressGeocoder = providers.Geocoder
+	}
+	landmarkService := services.NewLandmarkService(db.NewLandmarkRepo(gormDB), addressGeocoder, conf)
+	landmarkService.Subscribe(a.bus)
+	roadService := services.NewRoadService(db.NewRoadRepo(gormDB), conf)
+	roadService.Subscribe(a.bus)
+	webhookService := services.NewWebhookService(db.NewWebhookRepo(gormDB), conf)
+
+	publisher, err := streaming.New(conf)
+	if err != nil {
+		return nil, err
+	}
+	if publisher != nil {
+		a.publisher = publisher
+		a.bus.Subscribe(events.AllEvents, streaming.Subscriber(publisher))
+	}
+
+	searchIndex := search.New(conf)
+	if searchIndex != nil {
+		if err := searchIndex.EnsureIndex(context.Background()); err != nil {
+			a.closePublisher()
+			return nil, err
+		}
+	}
+	subscribeDomainEvents(a.bus, notificationService, activityService, webhookService, searchIndex, incidentReportRepo)
+
+	runWorker(outbox.NewRelay(outboxRepo, a.bus).Run)
+	// Webhook dispatchers skip deliveries another one is sending
+	for i := 0; i < max(conf.WebhookWorkers, 1); i++ {
+		runWorker(webhookService.Run)
+	}
+
+	// Tokens are signed with the stored signing keys; reloading picks up
+	// keys rotated from another server before they start signing
+	signingKeyService := services.NewSigningKeyService(db.NewSigningKeyRepo(gormDB), conf)
+	if err := signingKeyService.Load(); err != nil {
+		a.closePublisher()
+		return nil, fmt.Errorf("loading signing keys: %w", err)
+	}
+	runWorker(every(SigningKeyReload, func() {
+		if err := signingKeyService.Load(); err != nil {
+			log.Printf("reloading signing keys: %v", err)
+		}
+	}))
+	runWorker(every(time.Minute, func() {
+		if _, err := notificationService.FlushPending(); err != nil {
+			log.Printf("sending queued notifications: %v", err)
+		}
+	}))
+	announcementService := services.NewAnnouncementService(announcementRepo, notificationService, conf)
+	runWorker(every(time.Minute, func() {
+		if _, err := announcementService.SendDue(); err != nil {
+			log.Printf("sending announcements: %v", err)
+		}
+	}))
+	reengagementService := services.NewReengagementService(db.NewReengagementRepo(gormDB), notificationService, conf)
+	runWorker(every(24*time.Hour, func() {
+		if users, err := reengagementService.NudgeInactiveUsers(); err != nil {
+			log.Printf("nudging inactive users: %v", err)
+		} else if users > 0 {
+			log.Printf("sent re-engagement notifications to %d users", users)
+		}
+	}))
+	streakService := services.NewStreakService(db.NewStreakRepo(gormDB), notificationService, conf)
+	streakService.Subscribe(a.bus)
+	runWorker(every(time.Hour, func() {
+		if users, err := streakService.RemindAtRisk(); err != nil {
+			log.Printf("reminding users of streaks at risk: %v", err)
+		} else if users > 0 {
+			log.Printf("sent streak reminders to %d users", users)
+		}
+	}))
+	digestService := services.NewDigestService(db.NewDigestRepo(gormDB), authRepo, providers.Mail, conf)
+	// Digests wait for an email provider, and go out once it is configured
+	if providers.Mail != nil {
+		runWorker(every(15*time.Minute, func() {
+			if digests, err := digestService.SendDue(); err != nil {
+				log.Printf("sending report digests: %v", err)
+			} else if digests > 0 {
+				log.Printf("sent %d report digests", digests)
+			}
+		}))
+	}
+	autoCloseService := services.NewAutoCloseService(db.NewAutoCloseRepo(gormDB), conf)
+	runWorker(every(time.Hour, func() {
+		if reports, err := autoCloseService.CloseStaleReports(); err != nil {
+			log.Printf("closing stale reports: %v", err)
+		} else if reports > 0 {
+			log.Printf("closed %d stale reports", reports)
+		}
+	}))
+
+	locationService := services.NewLocationService(locationRepo, conf)
+	runWorker(every(24*time.Hour, func() {
+		if reports, err := locationService.PurgeExpired(); err != nil {
+			log.Printf("purging location history: %v", err)
+		} else if reports > 0 {
+			log.Printf("purged location history from %d reports", reports)
+		}
+	}))
+
+	sessionService := services.NewSessionService(db.NewSessionRepo(gormDB), authRepo, conf)
+	authService := services.NewAuthService(authRepo, sessionService, conf)
+	objectService := services.NewObjectService(db.NewObjectRepo(gormDB), providers.Store, conf)
+	runWorker(every(6*time.Hour, func() {
+		if objects, err := objectService.DeleteOrphans(); err != nil {
+			log.Printf("deleting orphaned objects: %v", err)
+		} else if objects > 0 {
+			log.Printf("deleted %d orphaned objects", objects)
+		}
+	}))
+	feedbackService := services.NewFeedbackService(db.NewFeedbackRepo(gormDB), objectService, conf)
+	runWorker(every(time.Minute, func() {
+		if _, err := feedbackService.Forward(); err != nil {
+			log.Printf("forwarding feedback: %v", err)
+		}
+	}))
+	warehouseService := services.NewWarehouseService(db.NewWarehouseRepo(gormDB), providers.Store, conf)
+	if conf.WarehouseExport {
+		runWorker(every(time.Hour, func() {
+			if partitions, err := warehouseService.ExportPending(); err != nil {
+				log.Printf("exporting to the warehouse: %v", err)
+			} else if partitions > 0 {
+				log.Printf("exported %d warehouse partitions", partitions)
+			}
+		}))
+	}
+	mediaService := services.NewMediaService(mediaRepo, rewardRepo, incidentReportRepo, objectService, conf)
+	draftRepo := db.NewReportDraftRepo(gormDB)
+	reputationService := services.NewReputationService(db.NewReputationRepo(gormDB), conf)
+	identityService := services.NewIdentityService(db.NewIdentityRepo(gormDB), authRepo, providers.Identity, reputationService, conf)
+	runWorker(every(24*time.Hour, func() {
+		if reporters, err := reputationService.Recompute(); err != nil {
+			log.Printf("recomputing reporter reputations: %v", err)
+		} else if reporters > 0 {
+			log.Printf("recomputed reputations of %d reporters", reporters)
+		}
+	}))
+	autoPublishService := services.NewAutoPublishService(db.NewReportAuditRepo(gormDB), reputationService, conf)
+	ipLocationService := services.NewIPLocationService(providers.IPLocator, conf)
+	submissionWindowService := services.NewSubmissionWindowService(db.NewSubmissionWindowRepo(gormDB), conf)
+	reportCapService := services.NewReportCapService(db.NewReportCapRepo(gormDB), conf)
+	duplicateService := services.NewDuplicateService(db.NewReportClusterRepo(gormDB), incidentReportRepo, conf)
+	incidentReportService := services.NewIncidentReportService(incidentReportRepo, rewardRepo, mediaRepo, draftRepo, autoPublishService, ipLocationService, submissionWindowService, shortLinkService, reportCapService, duplicateService, conf)
+	reportIngestService := services.NewReportIngestService(db.NewReportSubmissionRepo(gormDB), incidentReportRepo, incidentReportService, submissionWindowService, reportCapService, conf)
+
+	// Job workers share the queue, skipping jobs another worker holds, so
+	// running more of them drains queued reports faster
+	jobWorker := jobs.NewWorker(jobRepo)
+	activityService.RegisterJobs(jobWorker)
+	reportIngestService.RegisterJobs(jobWorker)
+	for i := 0; i < max(conf.ReportIngestWorkers, 1); i++ {
+		runWorker(jobWorker.Run)
+	}
+	uploadService := services.NewUploadService(db.NewUploadRepo(gormDB), draftRepo, mediaService, objectService, conf)
+	runWorker(every(time.Hour, func() {
+		if uploads, err := uploadService.PurgeStaleUploads(); err != nil {
+			log.Printf("purging stale uploads: %v", err)
+		} else if uploads > 0 {
+			log.Printf("purged %d stale uploads", uploads)
+		}
+		if drafts, err := incidentReportService.PurgeAbandonedDrafts(); err != nil {
+			log.Printf("purging abandoned report drafts: %v", err)
+		} else if drafts > 0 {
+			log.Printf("purged %d abandoned report drafts", drafts)
+		}
+	}))
+	moderationRepo := db.NewModerationRepo(gormDB)
+	collaboratorRepo := db.NewCollaboratorRepo(gormDB)
+	reportStatusRepo := db.NewReportStatusRepo(gormDB)
+	rewardBonusService := services.NewRewardBonusService(db.NewRewardBonusRepo(gormDB), conf)
+	rewardService := services.NewRewardService(rewardRepo, incidentReportRepo, moderationRepo, collaboratorRepo, reportStatusRepo, rewardBonusService, conf)
+	rewardStatementService := services.NewRewardStatementService(db.NewPointLedgerRepo(gormDB), conf)
+	runWorker(every(24*time.Hour, func() {
+		if users, err := rewardStatementService.ExpirePoints(); err != nil {
+			log.Printf("expiring reward points: %v", err)
+		} else if users > 0 {
+			log.Printf("expired unspent points of %d users", users)
+		}
+	}))
+	// Cash payouts are unavailable until a payment provider is configured
+	payoutService := services.NewPayoutService(db.NewPayoutRepo(gormDB), authRepo, providers.Payments, notificationService, conf)
+	runWorker(every(time.Minute, func() {
+		if payouts, err := payoutService.Send(); err != nil {
+			log.Printf("sending payouts: %v", err)
+		} else if payouts > 0 {
+			log.Printf("sent %d payouts", payouts)
+		}
+	}))
+	likeService := services.NewLikeService(likeRepo, conf)
+	// Votes rescore their report at once; this picks up media and
+	// reputations that changed since
+	runWorker(every(15*time.Minute, func() {
+		if _, err := likeService.RefreshCredibility(); err != nil {
+			log.Printf("refreshing credibility scores: %v", err)
+		}
+	}))
+	postService := services.NewPostService(postRepo, conf)
+	searchService := services.NewSearchService(incidentReportRepo, searchIndex, conf)
+	mapService := services.NewMapService(geoRepo, conf)
+	calendarService := services.NewCalendarService(db.NewCalendarRepo(gormDB), conf)
+	analyticsService := services.NewAnalyticsService(analyticsRepo, calendarService, conf)
+	runWorker(every(time.Hour, func() {
+		if rows, err := analyticsService.TakeDailySnapshot(); err != nil {
+			log.Printf("taking aggregate snapshot: %v", err)
+		} else if rows > 0 {
+			log.Printf("recorded %d aggregate snapshot rows", rows)
+		}
+	}))
+	agencyService := services.NewAgencyService(db.NewAgencyRepo(gormDB), conf)
+	runWorker(every(time.Hour, func() {
+		if agencies, err := agencyService.TakeMonthlySnapshot(); err != nil {
+			log.Printf("taking agency scorecard snapshot: %v", err)
+		} else if agencies > 0 {
+			log.Printf("recorded scorecard snapshots for %d agencies", agencies)
+		}
+	}))
+	dataShareService := services.NewDataShareService(db.NewDataShareRepo(gormDB), conf)
+	runWorker(every(time.Hour, func() {
+		if shares, err := dataShareService.ExpireShares(); err != nil {
+			log.Printf("expiring data-sharing agreements: %v", err)
+		} else if shares > 0 {
+			log.Printf("expired %d data-sharing agreements", shares)
+		}
+	}))
+	ambassadorService := services.NewAmbassadorService(db.NewAmbassadorRepo(gormDB), incidentReportRepo, autoPublishService, rewardService, conf)
+	runWorker(every(time.Hour, func() {
+		if ambassadors, err := ambassadorService.TakeMonthlySummary(); err != nil {
+			log.Printf("summarising ambassador activity: %v", err)
+		} else if ambassadors > 0 {
+			log.Printf("recorded monthly summaries for %d ambassadors", ambassadors)
+		}
+	}))
+	informationRequestService := services.NewInformationRequestService(db.NewInformationRequestRepo(gormDB), incidentReportRepo, notificationService, conf)
+	runWorker(every(time.Hour, func() {
+		reminded, expired, err := informationRequestService.RemindAndExpire()
+		if err != nil {
+			log.Printf("reminding reporters of information requests: %v", err)
+		}
+		if reminded > 0 || expired > 0 {
+			log.Printf("sent %d information request reminders, expired %d requests", reminded, expired)
+		}
+	}))
+	adminService := services.NewAdminService(adminRepo, moderationRepo, conf)
+	growthService := services.NewGrowthService(growthRepo, conf)
+	evidenceService, err := services.NewEvidenceService(incidentReportRepo, mediaRepo, reportStatusRepo, db.NewReportAuditRepo(gormDB), objectService, conf)
+	if err != nil {
+		a.closePublisher()
+		return nil, err
+	}
+
+	a.Server = &server.Server{
+		Mail:                      providers.Mail,
+		Config:                    conf,
+		AuthRepository:            authRepo,
+		AuthService:               authService,
+		MediaRepository:           mediaRepo,
+		MediaService:              mediaService,
+		IncidentReportService:     incidentReportService,
+		IncidentReportRepository:  incidentReportRepo,
+		RewardService:             rewardService,
+		RewardRepository:          rewardRepo,
+		LikeService:               likeService,
+		PostService:               postService,
+		PostRepository:            postRepo,
+		SearchService:             searchService,
+		MapService:                mapService,
+		AnalyticsService:          analyticsService,
+		LocationService:           locationService,
+		AdminService:              adminService,
+		GrowthService:             growthService,
+		ActivityService:           activityService,
+		EvidenceService:           evidenceService,
+		ReportPrintService:        services.NewReportPrintService(incidentReportRepo, conf),
+		UploadService:             uploadService,
+		NotificationService:       notificationService,
+		ObjectService:             objectService,
+		AnnouncementService:       announcementService,
+		AutoCloseService:          autoCloseService,
+		SubmissionWindowService:   submissionWindowService,
+		IncidentGroupService:      services.NewIncidentGroupService(db.NewIncidentRepo(gormDB), conf),
+		WarehouseService:          warehouseService,
+		AgencyService:             agencyService,
+		AmbassadorService:         ambassadorService,
+		DataShareService:          dataShareService,
+		ShortLinkService:          shortLinkService,
+		LegalService:              services.NewLegalService(db.NewLegalRepo(gormDB), conf),
+		FeedbackService:           feedbackService,
+		IdentityService:           identityService,
+		RewardStatementService:    rewardStatementService,
+		PayoutService:             payoutService,
+		AccountMergeService:       services.NewAccountMergeService(db.NewAccountMergeRepo(gormDB), conf),
+		ReportTransferService:     services.NewReportTransferService(db.NewReportTransferRepo(gormDB), conf),
+		PublicIDRepository:        db.NewPublicIDRepo(gormDB),
+		ReportStatusService:       services.NewReportStatusService(reportStatusRepo, conf),
+		ReportCapService:          reportCapService,
+		ReportIngestService:       reportIngestService,
+		DuplicateService:          duplicateService,
+		ReportExportService:       services.NewReportExportService(db.NewReportExportRepo(gormDB), conf),
+		WebhookService:            webhookService,
+		CommentService:            services.NewCommentService(db.NewCommentRepo(gormDB), incidentReportRepo, conf),
+		RewardBonusService:        rewardBonusService,
+		StreakService:             streakService,
+		CalendarService:           calendarService,
+		DigestService:             digestService,
+		HelpService:               services.NewHelpService(db.NewHelpRepo(gormDB), conf),
+		ReputationService:         reputationService,
+		AutoPublishService:        autoPublishService,
+		LandmarkService:           landmarkService,
+		RoadService:               roadService,
+		IPLocationService:         ipLocationService,
+		UserStatsService:          services.NewUserStatsService(db.NewUserStatsRepo(gormDB), streakService, conf),
+		InformationRequestService: informationRequestService,
+		CollaboratorService:       services.NewCollaboratorService(collaboratorRepo, incidentReportRepo, authRepo, conf),
+		DisplayNameService:        services.NewDisplayNameService(db.NewDisplayNameRepo(gormDB), conf),
+		PhoneLoginService:         services.NewPhoneLoginService(db.NewPhoneLoginRepo(gormDB), authRepo, providers.SMS, sessionService, conf),
+		SessionService:            sessionService,
+		MagicLinkService:          services.NewMagicLinkService(db.NewMagicLinkRepo(gormDB), authRepo, providers.Mail, sessionService, conf),
+		DB:                        db.GormDB{},
+	}
+
+	// Background workers run until the app is closed
+	ctx, stop := context.WithCancel(context.Background())
+	a.stopWorkers = stop
+	for _, worker := range run {
+		worker := worker
+		a.workers.Add(1)
+		go func() {
+			defer a.workers.Done()
+			worker(ctx)
+		}()
+	}
+	return a, nil
+}
+
+// Handler returns the server's routes, for serving without Server.Start
+func (a *App) Handler() http.Handler {
+	return a.Server.Handler()
+}
+
+// Close stops the background workers and lets them, and the event
+// subscribers, finish what they started
+func (a *App) Close() {
+	a.stopWorkers()
+	a.workers.Wait()
+	a.bus.Wait()
+	a.closePublisher()
+}
+
+func (a *App) closePublisher() {
+	if a.publisher != nil {
+		a.publisher.Close()
+	}
+}
+
+// every returns a worker that calls fn at start-up and then once per
+// interval until its context is cancelled.
+func every(interval time.Duration, fn func()) func(ctx context.Context) {
+	return func(ctx context.Context) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			fn()
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}
+}
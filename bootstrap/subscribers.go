@@ -0,0 +1,21 @@
+package bootstrap
+
+import (
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/events"
+	"github.com/techagentng/citizenx/search"
+	"github.com/techagentng/citizenx/services"
+)
+
+// subscribeDomainEvents wires the subscribers the domain events exist for,
+// which used to be called inline by the handlers: notifications, activity
+// analytics, webhooks and, when configured, the search index. Services
+// that follow other events subscribe where they are built.
+func subscribeDomainEvents(bus events.Bus, notifications services.NotificationService, activity services.ActivityService, webhooks services.WebhookService, searchIndex *search.Client, reports db.IncidentReportRepository) {
+	notifications.Subscribe(bus)
+	activity.Subscribe(bus)
+	webhooks.Subscribe(bus)
+	if searchIndex != nil {
+		searchIndex.Subscribe(bus, reports)
+	}
+}
@@ -0,0 +1,42 @@
+package bootstrap
+
+import (
+	"testing"
+
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/events"
+	"github.com/techagentng/citizenx/search"
+	"github.com/techagentng/citizenx/services"
+)
+
+// countingBus counts the handlers subscribed to each event
+type countingBus map[string]int
+
+func (b countingBus) Publish(events.Event) {}
+
+func (b countingBus) Subscribe(name string, _ events.Handler) { b[name]++ }
+
+func TestSubscribeDomainEvents(t *testing.T) {
+	subscribe := func(conf *config.Config) countingBus {
+		bus := countingBus{}
+		subscribeDomainEvents(bus,
+			services.NewNotificationService(nil, conf),
+			services.NewActivityService(nil, nil, conf),
+			services.NewWebhookService(nil, conf),
+			search.New(conf), nil)
+		return bus
+	}
+
+	bus := subscribe(&config.Config{})
+	for _, name := range []string{events.ReportCreatedEvent, events.ReportVerifiedEvent, events.CommentAddedEvent, events.RewardEarnedEvent} {
+		if bus[name] == 0 {
+			t.Errorf("nothing subscribes to %s", name)
+		}
+	}
+
+	// The search index follows reports only once it is configured
+	withSearch := subscribe(&config.Config{SearchURL: "http://search.invalid"})
+	if withSearch[events.ReportCreatedEvent] != bus[events.ReportCreatedEvent]+1 {
+		t.Fatalf("got %d subscribers to %s with search configured, want %d", withSearch[events.ReportCreatedEvent], events.ReportCreatedEvent, bus[events.ReportCreatedEvent]+1)
+	}
+}
@@ -0,0 +1,108 @@
+// Package cache holds short-lived in-memory caches for expensive read
+// endpoints such as map tiles and analytics aggregates.
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+type entry[V any] struct {
+	value   V
+	expires time.Time
+}
+
+// TTL caches values for a fixed time after they are loaded. It is safe for
+// concurrent use.
+type TTL[V any] struct {
+	ttl        time.Duration
+	maxEntries int
+	mu         sync.Mutex
+	entries    map[string]entry[V]
+}
+
+// New returns a cache whose entries live for ttl.
+func New[V any](ttl time.Duration) *TTL[V] {
+	return &TTL[V]{ttl: ttl, entries: map[string]entry[V]{}}
+}
+
+// NewBounded returns a cache whose entries live for ttl and that holds at
+// most maxEntries, for values keyed by request parameters. When full, the
+// entry closest to expiring makes way for the new one.
+func NewBounded[V any](ttl time.Duration, maxEntries int) *TTL[V] {
+	return &TTL[V]{ttl: ttl, maxEntries: maxEntries, entries: map[string]entry[V]{}}
+}
+
+// Get returns the live value stored under key.
+func (c *TTL[V]) Get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok || time.Now().After(e.expires) {
+		var zero V
+		return zero, false
+	}
+	return e.value, true
+}
+
+// Set stores value under key, evicting expired entries as it goes so the
+// map does not grow without bound.
+func (c *TTL[V]) Set(key string, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for k, e := range c.entries {
+		if now.After(e.expires) {
+			delete(c.entries, k)
+		}
+	}
+	if _, ok := c.entries[key]; !ok && c.maxEntries > 0 {
+		for len(c.entries) >= c.maxEntries {
+			c.evictOldest()
+		}
+	}
+	c.entries[key] = entry[V]{value: value, expires: now.Add(c.ttl)}
+}
+
+// evictOldest drops the entry closest to expiring. Every entry lives for
+// the same ttl, so that is the one stored first.
+func (c *TTL[V]) evictOldest() {
+	var oldest string
+	var expires time.Time
+	for k, e := range c.entries {
+		if expires.IsZero() || e.expires.Before(expires) {
+			oldest, expires = k, e.expires
+		}
+	}
+	delete(c.entries, oldest)
+}
+
+// GetOrLoad returns the cached value for key, calling load and caching its
+// result on a miss. Errors are not cached.
+func (c *TTL[V]) GetOrLoad(key string, load func() (V, error)) (V, error) {
+	if v, ok := c.Get(key); ok {
+		return v, nil
+	}
+	v, err := load()
+	if err != nil {
+		return v, err
+	}
+	c.Set(key, v)
+	return v, nil
+}
+
+// Clear empties the cache, for when the values it holds have changed
+func (c *TTL[V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = map[string]entry[V]{}
+}
+
+// Delete drops the value stored under key
+func (c *TTL[V]) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
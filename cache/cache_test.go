@@ -0,0 +1,29 @@
+package cache
+
+import (
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestBoundedEvictsOldest(t *testing.T) {
+	c := NewBounded[int](time.Minute, 3)
+	for i := 0; i < 5; i++ {
+		c.Set(strconv.Itoa(i), i)
+		time.Sleep(time.Millisecond)
+	}
+	if n := len(c.entries); n != 3 {
+		t.Fatalf("cache holds %d entries, want 3", n)
+	}
+	for i := 0; i < 5; i++ {
+		if _, ok := c.Get(strconv.Itoa(i)); ok != (i >= 2) {
+			t.Errorf("entry %d cached = %v", i, ok)
+		}
+	}
+
+	// Replacing a cached key does not evict anything
+	c.Set("4", 40)
+	if v, ok := c.Get("2"); !ok || v != 2 {
+		t.Fatalf("replacing a key evicted entry 2")
+	}
+}
@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/techagentng/citizenx/bootstrap"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/services"
+	"github.com/techagentng/citizenx/services/jwt"
+)
+
+var createAdminCmd = &cobra.Command{
+	Use:   "create-admin",
+	Short: "Create an admin user, or promote an existing user to admin",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		email, _ := cmd.Flags().GetString("email")
+		password, _ := cmd.Flags().GetString("password")
+		fullname, _ := cmd.Flags().GetString("fullname")
+		username, _ := cmd.Flags().GetString("username")
+		telephone, _ := cmd.Flags().GetString("telephone")
+
+		gormDB := openDB()
+		if err := db.SeedRoles(gormDB.DB); err != nil {
+			return err
+		}
+		authRepo := db.NewAuthRepo(gormDB)
+
+		role, err := authRepo.FindRoleByName(models.RoleAdmin)
+		if err != nil {
+			return fmt.Errorf("loading admin role: %w", err)
+		}
+
+		// Promote the user if the email is already registered
+		if user, err := authRepo.FindUserByEmail(email); err == nil {
+			if err := authRepo.UpdateUserRole(user.ID, role); err != nil {
+				return err
+			}
+			log.Printf("promoted %s (user %d) to admin", email, user.ID)
+			return nil
+		}
+
+		if password == "" || fullname == "" || username == "" {
+			return errors.New("--password, --fullname and --username are required to create a new admin")
+		}
+		if err := models.ValidatePassword(password); err != nil {
+			return err
+		}
+		hashedPassword, err := services.GenerateHashPassword(password)
+		if err != nil {
+			return err
+		}
+
+		user, err := authRepo.CreateUser(&models.User{
+			Fullname:       fullname,
+			Username:       username,
+			Email:          email,
+			Telephone:      telephone,
+			HashedPassword: hashedPassword,
+			IsEmailActive:  true,
+			AdminStatus:    true,
+			RoleID:         role.ID,
+		})
+		if err != nil {
+			return err
+		}
+		log.Printf("created admin %s (user %d)", email, user.ID)
+		return nil
+	},
+}
+
+var rotateSigningKeyCmd = &cobra.Command{
+	Use:   "rotate-signing-key",
+	Short: "Create a new token signing key and delete keys no longer needed to validate tokens",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		algorithm, _ := cmd.Flags().GetString("algorithm")
+		activateIn, _ := cmd.Flags().GetDuration("activate-in")
+		if activateIn <= bootstrap.SigningKeyReload {
+			return fmt.Errorf("--activate-in must be longer than %s so every server publishes the key before it signs", bootstrap.SigningKeyReload)
+		}
+
+		signingKeys := services.NewSigningKeyService(db.NewSigningKeyRepo(openDB()), conf)
+		key, err := signingKeys.Rotate(algorithm, activateIn)
+		if err != nil {
+			return err
+		}
+		log.Printf("created %s signing key %s, signing from %s", key.Algorithm, key.ID, time.Unix(key.ActiveFrom, 0).Format(time.RFC3339))
+
+		pruned, err := signingKeys.Prune()
+		if err != nil {
+			return err
+		}
+		log.Printf("deleted %d expired signing keys", pruned)
+		return nil
+	},
+}
+
+func init() {
+	createAdminCmd.Flags().String("email", "", "email address of the admin")
+	createAdminCmd.Flags().String("password", "", "password for a new admin")
+	createAdminCmd.Flags().String("fullname", "", "full name for a new admin")
+	createAdminCmd.Flags().String("username", "", "username for a new admin")
+	createAdminCmd.Flags().String("telephone", "", "telephone number for a new admin")
+	_ = createAdminCmd.MarkFlagRequired("email")
+	rootCmd.AddCommand(createAdminCmd)
+
+	rotateSigningKeyCmd.Flags().String("algorithm", jwt.AlgorithmEdDSA, "signing algorithm of the new key, EdDSA or RS256")
+	rotateSigningKeyCmd.Flags().Duration("activate-in", 2*bootstrap.SigningKeyReload, "how long the new key is published before it starts signing")
+	rootCmd.AddCommand(rotateSigningKeyCmd)
+}
@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"log"
+	"sort"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/search"
+)
+
+var reindexSearchCmd = &cobra.Command{
+	Use:   "reindex-search",
+	Short: "Rebuild the report search indexes, including OpenSearch when configured",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		gormDB := openDB()
+		if err := db.NewMaintenanceRepo(gormDB).ReindexReports(); err != nil {
+			return err
+		}
+		log.Println("report indexes rebuilt")
+
+		// Mirror every report into OpenSearch when it is configured
+		index := search.New(conf)
+		if index == nil {
+			return nil
+		}
+		ctx := cmd.Context()
+		if err := index.EnsureIndex(ctx); err != nil {
+			return err
+		}
+		indexed := 0
+		err := db.NewIncidentReportRepo(gormDB).EachReportBatch(500, func(reports []models.IncidentReport) error {
+			indexed += len(reports)
+			return index.Bulk(ctx, reports)
+		})
+		if err != nil {
+			return err
+		}
+		log.Printf("indexed %d reports in opensearch", indexed)
+		return nil
+	},
+}
+
+var recomputeAggregatesCmd = &cobra.Command{
+	Use:   "recompute-aggregates",
+	Short: "Recompute cached vote counts and user points from their source tables",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		repo := db.NewMaintenanceRepo(openDB())
+
+		reports, err := repo.RecomputeVoteCounts()
+		if err != nil {
+			return err
+		}
+		log.Printf("corrected vote counts on %d reports", reports)
+
+		users, err := repo.RecomputeUserPoints()
+		if err != nil {
+			return err
+		}
+		log.Printf("corrected points for %d users", users)
+		return nil
+	},
+}
+
+var expirePointsCmd = &cobra.Command{
+	Use:   "expire-points",
+	Short: "Expire reward points older than the retention period",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		days, _ := cmd.Flags().GetInt("days")
+		if !cmd.Flags().Changed("days") {
+			days = conf.PointsExpiryDays
+		}
+		before := time.Now().AddDate(0, 0, -days)
+
+		rewards, points, err := db.NewMaintenanceRepo(openDB()).ExpirePoints(before)
+		if err != nil {
+			return err
+		}
+		log.Printf("expired %d points across %d rewards earned before %s", points, rewards, before.Format("2006-01-02"))
+		return nil
+	},
+}
+
+var purgeSoftDeletedCmd = &cobra.Command{
+	Use:   "purge-soft-deleted",
+	Short: "Permanently remove rows deleted longer ago than the retention period",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		days, _ := cmd.Flags().GetInt("days")
+		before := time.Now().AddDate(0, 0, -days)
+
+		purged, err := db.NewMaintenanceRepo(openDB()).PurgeSoftDeleted(before)
+		tables := make([]string, 0, len(purged))
+		for table := range purged {
+			tables = append(tables, table)
+		}
+		sort.Strings(tables)
+		for _, table := range tables {
+			log.Printf("purged %d rows from %s", purged[table], table)
+		}
+		return err
+	},
+}
+
+var purgeLocationHistoryCmd = &cobra.Command{
+	Use:   "purge-location-history",
+	Short: "Coarsen report coordinates older than the location retention period",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		days, _ := cmd.Flags().GetInt("days")
+		if !cmd.Flags().Changed("days") {
+			days = conf.LocationRetentionDays
+		}
+		if days <= 0 {
+			log.Println("location retention is disabled, nothing to purge")
+			return nil
+		}
+		before := time.Now().AddDate(0, 0, -days)
+
+		reports, err := db.NewLocationRepo(openDB()).PurgeLocationHistory(before)
+		if err != nil {
+			return err
+		}
+		log.Printf("purged location history from %d reports submitted before %s", reports, before.Format("2006-01-02"))
+		return nil
+	},
+}
+
+var requeueFailedWebhooksCmd = &cobra.Command{
+	Use:   "requeue-failed-webhooks",
+	Short: "Reschedule failed webhook deliveries for another attempt",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		requeued, err := db.NewMaintenanceRepo(openDB()).RequeueFailedWebhooks()
+		if err != nil {
+			return err
+		}
+		log.Printf("requeued %d webhook deliveries", requeued)
+		return nil
+	},
+}
+
+var backfillPlusCodesCmd = &cobra.Command{
+	Use:   "backfill-plus-codes",
+	Short: "Store plus codes for reports submitted before they were generated",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filled, err := db.NewMaintenanceRepo(openDB()).BackfillPlusCodes()
+		if err != nil {
+			return err
+		}
+		log.Printf("stored plus codes for %d reports", filled)
+		return nil
+	},
+}
+
+var encryptPIICmd = &cobra.Command{
+	Use:   "encrypt-pii",
+	Short: "Encrypt personal data columns with the active key, re-encrypting values sealed with older keys",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		changed, err := db.NewMaintenanceRepo(openDB()).EncryptPII()
+		columns := make([]string, 0, len(changed))
+		for column := range changed {
+			columns = append(columns, column)
+		}
+		sort.Strings(columns)
+		for _, column := range columns {
+			log.Printf("encrypted %d values of %s", changed[column], column)
+		}
+		return err
+	},
+}
+
+var backfillPublicIDsCmd = &cobra.Command{
+	Use:   "backfill-public-ids",
+	Short: "Give external IDs to users, posts, agencies, landmarks and road segments created before they had them",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		filled, err := db.NewMaintenanceRepo(openDB()).BackfillPublicIDs()
+		tables := make([]string, 0, len(filled))
+		for table := range filled {
+			tables = append(tables, table)
+		}
+		sort.Strings(tables)
+		for _, table := range tables {
+			log.Printf("assigned %d external IDs in %s", filled[table], table)
+		}
+		return err
+	},
+}
+
+func init() {
+	expirePointsCmd.Flags().Int("days", 0, "expire points earned more than this many days ago (default points_expiry_days)")
+	purgeSoftDeletedCmd.Flags().Int("days", 30, "purge rows deleted more than this many days ago")
+	purgeLocationHistoryCmd.Flags().Int("days", 0, "purge location history older than this many days (default location_retention_days)")
+
+	rootCmd.AddCommand(
+		reindexSearchCmd,
+		recomputeAggregatesCmd,
+		expirePointsCmd,
+		purgeSoftDeletedCmd,
+		purgeLocationHistoryCmd,
+		requeueFailedWebhooksCmd,
+		backfillPlusCodesCmd,
+		encryptPIICmd,
+		backfillPublicIDsCmd,
+	)
+}
@@ -0,0 +1,47 @@
+// Package cmd implements the citizenx command line: the API server and the
+// operational subcommands run against its database.
+package cmd
+
+import (
+	"log"
+
+	"github.com/spf13/cobra"
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/pii"
+)
+
+// conf is loaded once before any command runs.
+var conf *config.Config
+
+var rootCmd = &cobra.Command{
+	Use:           "citizenx",
+	Short:         "CitizenX API server and operational tools",
+	SilenceUsage:  true,
+	SilenceErrors: true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		var err error
+		conf, err = config.Load()
+		if err != nil {
+			return err
+		}
+		return pii.Configure(conf.PIIEncryptionKeys, conf.PIIIndexKey)
+	},
+	// Running the binary without a subcommand starts the API server, as it
+	// always has.
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return serve()
+	},
+}
+
+// Execute runs the command selected by the process arguments.
+func Execute() {
+	if err := rootCmd.Execute(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// openDB connects to the configured database and applies migrations.
+func openDB() *db.GormDB {
+	return db.GetDB(conf)
+}
@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"log"
+	"math/rand"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/techagentng/citizenx/db"
+)
+
+var seedCmd = &cobra.Command{
+	Use:   "seed",
+	Short: "Fill the database with demo data for frontend and QA environments",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reports, _ := cmd.Flags().GetInt("reports")
+		seed, _ := cmd.Flags().GetInt64("seed")
+
+		gormDB := openDB()
+		if err := db.SeedDemoData(gormDB.DB, reports, rand.New(rand.NewSource(seed))); err != nil {
+			return err
+		}
+
+		log.Printf("seeded %d incident reports; demo users demo1..demo10@citizenx.ng use password %q", reports, db.DemoPassword)
+		return nil
+	},
+}
+
+func init() {
+	seedCmd.Flags().Int("reports", 300, "number of incident reports to create")
+	seedCmd.Flags().Int64("seed", time.Now().UnixNano(), "random seed, for reproducible data")
+	rootCmd.AddCommand(seedCmd)
+}
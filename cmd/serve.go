@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/techagentng/citizenx/bootstrap"
+	"github.com/techagentng/citizenx/db"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Start the API server",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return serve()
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(serveCmd)
+}
+
+func serve() error {
+	providers, err := bootstrap.ConfiguredProviders(conf)
+	if err != nil {
+		return err
+	}
+
+	gormDB := openDB()
+	// Seed roles
+	if err := db.SeedRoles(gormDB.DB); err != nil {
+		return err
+	}
+	app, err := bootstrap.New(conf, gormDB, providers)
+	if err != nil {
+		return err
+	}
+
+	app.Server.Start()
+
+	// Let workers and subscribers finish what they started
+	app.Close()
+	return nil
+}
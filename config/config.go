@@ -36,6 +36,103 @@ type Config struct {
 	AWS_REGION                   string `envconfig:"aws_region"`
 	AWS_ACCESS_KEY_ID            string `envconfig:"aws_access_key_id"`
 	AWS_SECRET_ACCESS_KEY        string `envconfig:"aws_secret_access_key"`
+	AWS_ENDPOINT                 string `envconfig:"aws_endpoint"` // S3-compatible store such as MinIO
+	StreamBackend                string `envconfig:"stream_backend"`
+	StreamBrokers                string `envconfig:"stream_brokers"`
+	StreamTopic                  string `envconfig:"stream_topic" default:"citizenx.events"`
+	SearchURL                    string `envconfig:"search_url"`
+	SearchIndex                  string `envconfig:"search_index" default:"incident_reports"`
+	SearchUsername               string `envconfig:"search_username"`
+	SearchPassword               string `envconfig:"search_password"`
+	LocationRetentionDays        int    `envconfig:"location_retention_days" default:"365"`
+	WatermarkMedia               bool   `envconfig:"watermark_media"`
+	WatermarkSecret              string `envconfig:"watermark_secret"`
+	EvidenceSigningKey           string `envconfig:"evidence_signing_key"`
+	BannedWords                  string `envconfig:"banned_words"` // comma separated
+	DraftReportTTLHours          int    `envconfig:"draft_report_ttl_hours" default:"24"`
+	StorageDir                   string `envconfig:"storage_dir" default:"media/store"`
+	MaxUploadSize                int64  `envconfig:"max_upload_size" default:"104857600"`
+	OrphanObjectGraceHours       int    `envconfig:"orphan_object_grace_hours" default:"72"`
+	RestrictMediaOriginals       bool   `envconfig:"restrict_media_originals" default:"true"`
+	SignedURLTTLSeconds          int    `envconfig:"signed_url_ttl_seconds" default:"300"`
+	MediaSigningSecret           string `envconfig:"media_signing_secret"`
+	PrivateMediaBaseURL          string `envconfig:"private_media_base_url" default:"/api/v1/media/private"`
+	MaxImageUploadSize           int64  `envconfig:"max_image_upload_size" default:"15728640"`
+	MaxVideoUploadSize           int64  `envconfig:"max_video_upload_size" default:"104857600"`
+	MaxAudioUploadSize           int64  `envconfig:"max_audio_upload_size" default:"20971520"`
+	MaxImageDimension            int    `envconfig:"max_image_dimension" default:"12000"`
+	MaxImagePixels               int    `envconfig:"max_image_pixels" default:"60000000"`
+	NotificationBatchSeconds     int    `envconfig:"notification_batch_seconds" default:"120"`
+	PointsExpiryDays             int    `envconfig:"points_expiry_days" default:"365"`
+	ReengagementInactiveDays     int    `envconfig:"reengagement_inactive_days" default:"30"`
+	ReengagementCooldownDays     int    `envconfig:"reengagement_cooldown_days" default:"30"`
+	PointsExpiryWarningDays      int    `envconfig:"points_expiry_warning_days" default:"30"`
+	WarehouseExport              bool   `envconfig:"warehouse_export"`
+	WarehousePrefix              string `envconfig:"warehouse_prefix" default:"warehouse"`
+	ReputationHalfLifeDays       int    `envconfig:"reputation_half_life_days" default:"180"`
+	AutoPublishCategories        string `envconfig:"auto_publish_categories"`
+	AutoPublishAuditPercent      int    `envconfig:"auto_publish_audit_percent" default:"20"`
+	AutoPublishSuspensionDays    int    `envconfig:"auto_publish_suspension_days" default:"90"`
+	RoadCategories               string `envconfig:"road_categories" default:"Roads"`
+	RoadSegmentToleranceMeters   int    `envconfig:"road_segment_tolerance_meters" default:"30"`
+	MaxMindAccountID             string `envconfig:"maxmind_account_id"`
+	MaxMindLicenseKey            string `envconfig:"maxmind_license_key"`
+	InformationRequestDays       int    `envconfig:"information_request_days" default:"7"`
+	InformationReminderHours     int    `envconfig:"information_reminder_hours" default:"48"`
+	ReservedDisplayNames         string `envconfig:"reserved_display_names" default:"admin,administrator,moderator,mod,citizenx,support,helpdesk,staff,official,system,root,null,anonymous"`                                              // comma separated
+	OfficialTitles               string `envconfig:"official_titles" default:"president,vice president,governor,deputy governor,senator,minister,commissioner,chairman,inspector general,igp,police,inec,efcc,honourable,hon,excellency"` // comma separated
+	TermiiAPIKey                 string `envconfig:"termii_api_key"`
+	SMSSenderID                  string `envconfig:"sms_sender_id" default:"CitizenX"`
+	OTPTTLMinutes                int    `envconfig:"otp_ttl_minutes" default:"5"`
+	OTPMaxPerHour                int    `envconfig:"otp_max_per_hour" default:"5"`
+	OTPMaxPerIPPerHour           int    `envconfig:"otp_max_per_ip_per_hour" default:"20"`
+	OTPPasswordFallback          bool   `envconfig:"otp_password_fallback" default:"true"` // offer password sign-in when codes cannot be sent
+	MagicLinkTTLMinutes          int    `envconfig:"magic_link_ttl_minutes" default:"15"`
+	MagicLinkMaxPerHour          int    `envconfig:"magic_link_max_per_hour" default:"5"`
+	SessionIdleMinutes           int    `envconfig:"session_idle_minutes" default:"10080"` // sign out after this long without activity
+	SessionMaxHours              int    `envconfig:"session_max_hours" default:"720"`      // sign out this long after signing in, whatever the activity
+	AccessTokenMinutes           int    `envconfig:"access_token_minutes" default:"30"`
+	StepUpMinutes                int    `envconfig:"step_up_minutes" default:"10"`
+	PIIEncryptionKeys            string `envconfig:"pii_encryption_keys"`             // comma separated id:base64key, the first encrypts; keep old keys until encrypt-pii has run
+	PIIIndexKey                  string `envconfig:"pii_index_key"`                   // keys the blind indexes of encrypted columns, at least 32 bytes; changing it breaks lookups
+	JWTAcceptHS256               bool   `envconfig:"jwt_accept_hs256" default:"true"` // keep accepting tokens signed with jwt_secret after the first signing key; turn off once they have expired
+	MinimumAge                   int    `envconfig:"minimum_age" default:"13"`
+	AdultAge                     int    `envconfig:"adult_age" default:"18"`                      // younger users are in restricted mode
+	GraphicCategories            string `envconfig:"graphic_categories" default:"Security"`       // comma separated; categories whose media is often graphic
+	ShortLinkBaseURL             string `envconfig:"short_link_base_url" default:"https://cx.ng"` // the short domain, which the proxy rewrites to /s/ on this server
+	ShareLinkDays                int    `envconfig:"share_link_days" default:"365"`
+	NotificationLinkDays         int    `envconfig:"notification_link_days" default:"30"`
+	FeedbackWebhookURL           string `envconfig:"feedback_webhook_url"`   // issue tracker feedback is forwarded to; unset keeps it in the admin triage list only
+	FeedbackWebhookToken         string `envconfig:"feedback_webhook_token"` // sent as a bearer token
+	DojahAppID                   string `envconfig:"dojah_app_id"`           // identity verification of NINs and BVNs; unset turns it off
+	DojahSecretKey               string `envconfig:"dojah_secret_key"`
+	IdentityMaxAttempts          int    `envconfig:"identity_max_attempts" default:"3"`           // failed verifications allowed a day, each a paid lookup
+	PointValueKobo               int64  `envconfig:"point_value_kobo" default:"100"`              // what one reward point is worth
+	PointsExpireMonths           int    `envconfig:"points_expire_months" default:"0"`            // unspent points expire this long after they are earned; 0 keeps them forever
+	PaystackSecretKey            string `envconfig:"paystack_secret_key"`                         // enables cash payouts through Paystack
+	AcceptNumericIDs             bool   `envconfig:"accept_numeric_ids" default:"true"`           // accept numeric IDs as well as public IDs in routes; turn off once clients send public IDs
+	ReportTransferRewards        string `envconfig:"report_transfer_rewards" default:"recipient"` // who keeps the points of a report an admin transfers: recipient or reporter; reporters always keep them when transferring their own
+	ReportDailyCap               int    `envconfig:"report_daily_cap" default:"20"`               // reports a user or device may file a day; 0 lifts the cap
+	ReportDailyCapOverrides      string `envconfig:"report_daily_cap_overrides"`                  // lower caps for single categories, e.g. "security=5,election=10"
+	ReportIngestQueue            bool   `envconfig:"report_ingest_queue"`                         // acknowledge report submissions once queued and save them in the background; turn on for mass incidents
+	ReportIngestWorkers          int    `envconfig:"report_ingest_workers" default:"4"`           // job workers running side by side, which save queued reports
+	DuplicateWindowHours         int    `envconfig:"duplicate_window_hours" default:"6"`          // how far apart reports of the same thing may be made; 0 turns duplicate detection off
+	DuplicateRadiusM             int    `envconfig:"duplicate_radius_m" default:"300"`            // how close located reports of the same thing are made
+	WebhookWorkers               int    `envconfig:"webhook_workers" default:"2"`                 // webhook deliveries sent side by side
+	TrendingDays                 int    `envconfig:"trending_days" default:"7"`                   // how far back the trending feed reaches; credibility scores of newer reports are kept fresh
+	RewardCategoryMultipliers    string `envconfig:"reward_category_multipliers"`                 // bonus for priority categories at approval, e.g. "security=1.5,election=2"
+	RewardAreaMultipliers        string `envconfig:"reward_area_multipliers"`                     // bonus for chosen LGAs at approval, e.g. "Borno/Gwoza=2"
+	RewardScarcityPercent        int    `envconfig:"reward_scarcity_percent" default:"150"`       // points of reports from LGAs with few recent reports, as a percentage; 100 turns the bonus off
+	RewardScarcityDays           int    `envconfig:"reward_scarcity_days" default:"30"`           // how far back report volume is counted to find under-reported LGAs
+	RewardMaxPercent             int    `envconfig:"reward_max_percent" default:"300"`            // the most a report's points are raised to, as a percentage, however bonuses combine
+	ReportEditWindowMinutes      int    `envconfig:"report_edit_window_minutes" default:"60"`     // how long after submitting a report the reporter may edit it; 0 turns editing off
+	StreakDailyGoal              int    `envconfig:"streak_daily_goal" default:"1"`               // reports a day that keep a reporting streak going, for users who have not set their own goal
+	StreakBonusPoints            int    `envconfig:"streak_bonus_points" default:"5"`             // points per day of a streak paid when it reaches a streak badge; 0 turns streak bonuses off
+	StreakReminderHour           int    `envconfig:"streak_reminder_hour" default:"18"`           // hour of the day, in Lagos, from which users are reminded of streaks about to end; -1 turns reminders off
+	AnomalyThresholdPercent      int    `envconfig:"anomaly_threshold_percent" default:"200"`     // how far above the count expected of a period, as a percentage, analytics flag it as an anomaly; 0 turns flagging off
+	DigestHour                   int    `envconfig:"digest_hour" default:"7"`                     // hour of the day, in Lagos, report digests are emailed; weekly digests go out on Mondays
+	HistoryRadiusM               int    `envconfig:"history_radius_m" default:"100"`              // how close earlier reports of the same category are counted as made at the same place in a report's history
+	TrustedProxies               string `envconfig:"trusted_proxies"`                             // comma-separated addresses or CIDRs of the proxies whose X-Forwarded-For is believed; empty trusts none, so a client's IP is the address it connected from
 }
 
 func Load() (*Config, error) {
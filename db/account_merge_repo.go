@@ -0,0 +1,160 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AccountMergeRepository finds accounts that may belong to one person and
+// folds them together
+type AccountMergeRepository interface {
+	UserContacts(afterID uint, limit int) ([]models.User, error)
+	MergeAccounts(sourceID, targetID, mergedBy uint, at int64) (*models.AccountMerge, error)
+	ListMerges(page int) ([]models.AccountMerge, error)
+}
+
+type accountMergeRepo struct {
+	DB *gorm.DB
+}
+
+func NewAccountMergeRepo(db *GormDB) AccountMergeRepository {
+	return &accountMergeRepo{db.DB}
+}
+
+// UserContacts returns the next limit active users after afterID with the
+// details duplicates are told apart by
+func (a *accountMergeRepo) UserContacts(afterID uint, limit int) ([]models.User, error) {
+	var users []models.User
+	err := a.DB.Scopes(activeUsers).
+		Select("id", "fullname", "username", "email", "telephone", "mac_address", "created_at").
+		Where("users.id > ?", afterID).
+		Order("users.id ASC").
+		Limit(limit).
+		Find(&users).Error
+	return users, err
+}
+
+// MergeAccounts moves the source account's reports, rewards, point debits
+// and bookmarks to the target and closes the source: it is marked deleted,
+// loses the email, phone number and password it signed in with and has its
+// sessions revoked. The target takes the source's phone number when it has
+// none. It returns gorm.ErrRecordNotFound unless both accounts are active.
+func (a *accountMergeRepo) MergeAccounts(sourceID, targetID, mergedBy uint, at int64) (*models.AccountMerge, error) {
+	merge := &models.AccountMerge{SourceID: sourceID, TargetID: targetID, MergedBy: mergedBy, CreatedAt: at}
+	err := a.DB.Transaction(func(tx *gorm.DB) error {
+		// Read without the serializer so the sealed phone number can be
+		// moved as it is
+		var accounts []struct {
+			ID             uint
+			Telephone      *string
+			TelephoneIndex *string
+		}
+		if err := tx.Table("users").Clauses(clause.Locking{Strength: "UPDATE"}).
+			Scopes(activeUsers).
+			Select("id", "telephone", "telephone_index").
+			Where("users.id IN ?", []uint{sourceID, targetID}).
+			Scan(&accounts).Error; err != nil {
+			return err
+		}
+		if len(accounts) != 2 {
+			return gorm.ErrRecordNotFound
+		}
+		source, target := accounts[0], accounts[1]
+		if source.ID != sourceID {
+			source, target = target, source
+		}
+
+		var reportIDs []string
+		if err := tx.Model(&models.IncidentReport{}).Where("user_id = ?", sourceID).Pluck("id", &reportIDs).Error; err != nil {
+			return err
+		}
+		for _, model := range []interface{}{&models.IncidentReport{}, &models.ReportType{}, &models.Media{}, &models.PointDebit{}, &models.ReportComment{}} {
+			if err := tx.Model(model).Where("user_id = ?", sourceID).Update("user_id", targetID).Error; err != nil {
+				return err
+			}
+		}
+		merge.Reports = int64(len(reportIDs))
+		result := tx.Model(&models.Reward{}).Where("user_id = ?", sourceID).Update("user_id", targetID)
+		if result.Error != nil {
+			return result.Error
+		}
+		merge.Rewards = result.RowsAffected
+
+		// Bookmarks of reports the target has bookmarked too are dropped
+		// rather than doubled
+		if err := tx.Where("user_id = ? AND report_id IN (?)", sourceID,
+			tx.Model(&models.Bookmark{}).Select("report_id").Where("user_id = ?", targetID)).
+			Delete(&models.Bookmark{}).Error; err != nil {
+			return err
+		}
+		result = tx.Model(&models.Bookmark{}).Where("user_id = ?", sourceID).Update("user_id", targetID)
+		if result.Error != nil {
+			return result.Error
+		}
+		merge.Bookmarks = result.RowsAffected
+		if err := tx.Where("user_id = ? AND incident_report_id IN (?)", sourceID,
+			tx.Model(&models.IncidentReportUser{}).Select("incident_report_id").Where("user_id = ?", targetID)).
+			Delete(&models.IncidentReportUser{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.IncidentReportUser{}).Where("user_id = ?", sourceID).Update("user_id", targetID).Error; err != nil {
+			return err
+		}
+
+		// The telephone index is unique, so the source gives its number up
+		// before the target takes it
+		if err := tx.Model(&models.User{}).Where("id = ?", sourceID).UpdateColumns(map[string]interface{}{
+			"deleted_at":        at,
+			"email":             nil,
+			"telephone":         nil,
+			"telephone_index":   nil,
+			"mac_address":       "",
+			"mac_address_index": nil,
+			"hashed_password":   "",
+			"access_token":      "",
+			"online":            false,
+			"updated_at":        at,
+		}).Error; err != nil {
+			return err
+		}
+		if target.TelephoneIndex == nil && source.TelephoneIndex != nil {
+			if err := tx.Table("users").Where("id = ?", targetID).UpdateColumns(map[string]interface{}{
+				"telephone":       source.Telephone,
+				"telephone_index": source.TelephoneIndex,
+			}).Error; err != nil {
+				return err
+			}
+			merge.MovedTelephone = true
+		}
+		if err := tx.Model(&models.Session{}).Where("user_id = ? AND revoked_at = 0", sourceID).Update("revoked_at", at).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Create(merge).Error; err != nil {
+			return err
+		}
+		return writeAudit(tx, &mergedBy, models.AuditAccountsMerged, "user", fmt.Sprint(targetID), map[string]interface{}{
+			"merge_id":        merge.ID,
+			"source_id":       sourceID,
+			"report_ids":      reportIDs,
+			"rewards":         merge.Rewards,
+			"bookmarks":       merge.Bookmarks,
+			"moved_telephone": merge.MovedTelephone,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return merge, nil
+}
+
+func (a *accountMergeRepo) ListMerges(page int) ([]models.AccountMerge, error) {
+	var merges []models.AccountMerge
+	err := a.DB.Order("created_at DESC").
+		Offset((page - 1) * DefaultPageSize).Limit(DefaultPageSize).
+		Find(&merges).Error
+	return merges, err
+}
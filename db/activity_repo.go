@@ -0,0 +1,25 @@
+package db
+
+import (
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ActivityRepository stores user activity events
+type ActivityRepository interface {
+	RecordActivity(event *models.ActivityEvent) error
+}
+
+type activityRepo struct {
+	DB *gorm.DB
+}
+
+func NewActivityRepo(db *GormDB) ActivityRepository {
+	return &activityRepo{db.DB}
+}
+
+// RecordActivity stores event, skipping it if its dedup key was seen before
+func (a *activityRepo) RecordActivity(event *models.ActivityEvent) error {
+	return a.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(event).Error
+}
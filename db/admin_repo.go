@@ -0,0 +1,89 @@
+package db
+
+import (
+	"time"
+
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+// AdminRepository holds the queries behind the admin dashboard.
+type AdminRepository interface {
+	GetOverview(since time.Time) (*models.AdminOverview, error)
+}
+
+type adminRepo struct {
+	DB *gorm.DB
+}
+
+func NewAdminRepo(db *GormDB) AdminRepository {
+	return &adminRepo{db.DB}
+}
+
+// GetOverview gathers the dashboard counters. Reports are counted from
+// since, normally the start of the day.
+func (a *adminRepo) GetOverview(since time.Time) (*models.AdminOverview, error) {
+	now := time.Now()
+	overview := &models.AdminOverview{ReportsToday: map[string]int64{}, GeneratedAt: now}
+
+	var byStatus []struct {
+		ReportStatus string
+		Count        int64
+	}
+	if err := a.DB.Model(&models.IncidentReport{}).
+		Select("report_status, COUNT(*) AS count").
+		Where("created_at >= ?", since.Unix()).
+		Group("report_status").
+		Scan(&byStatus).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range byStatus {
+		status := row.ReportStatus
+		if status == "" {
+			status = "pending"
+		}
+		overview.ReportsToday[status] += row.Count
+	}
+
+	var pending struct {
+		Count  int64
+		Oldest *int64
+	}
+	if err := a.DB.Model(&models.IncidentReport{}).
+		Select("COUNT(*) AS count, MIN(created_at) AS oldest").
+		Where("report_status = 'pending' OR report_status = ''").
+		Scan(&pending).Error; err != nil {
+		return nil, err
+	}
+	overview.PendingModeration = pending.Count
+	if pending.Oldest != nil {
+		oldest := time.Unix(*pending.Oldest, 0)
+		overview.OldestPendingAt = &oldest
+		overview.OldestPendingAgeSeconds = int64(now.Sub(oldest).Seconds())
+	}
+
+	if err := a.DB.Model(&models.User{}).Where("online = true").Count(&overview.ActiveUsers).Error; err != nil {
+		return nil, err
+	}
+
+	if a.DB.Migrator().HasTable("webhook_deliveries") {
+		if err := a.DB.Table("webhook_deliveries").Where("status = 'failed'").Count(&overview.WebhookFailures).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	// Queue depth covers background jobs and events awaiting relay
+	var jobs, outbox int64
+	if err := a.DB.Model(&models.Job{}).Where("status = ?", models.JobPending).Count(&jobs).Error; err != nil {
+		return nil, err
+	}
+	if err := a.DB.Model(&models.OutboxEvent{}).Where("published_at IS NULL").Count(&outbox).Error; err != nil {
+		return nil, err
+	}
+	overview.JobQueueDepth = jobs + outbox
+
+	if err := a.DB.Model(&models.Reward{}).Select("COALESCE(SUM(balance), 0)").Scan(&overview.RewardLiability).Error; err != nil {
+		return nil, err
+	}
+	return overview, nil
+}
@@ -0,0 +1,231 @@
+package db
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/techagentng/citizenx/events"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrReportResolved is returned when resolving a report that is already
+// resolved, including by another request at the same time
+var ErrReportResolved = errors.New("report is already resolved")
+
+// AgencyRepository persists responding agencies, the reports assigned to
+// them and their scorecards
+type AgencyRepository interface {
+	CreateAgency(agency *models.Agency) error
+	ListAgencies() ([]models.Agency, error)
+	GetAgency(id uint) (*models.Agency, error)
+	SetMemberAgency(userID uint, agencyID *uint) error
+	MemberAgencyID(userID uint) (*uint, error)
+	GetReport(reportID string) (*models.IncidentReport, error)
+	AssignReport(reportID string, agencyID uint, at int64) error
+	AcknowledgeReport(report *models.IncidentReport, note string, at time.Time) (bool, error)
+	ResolveReport(report *models.IncidentReport, resolvedBy uint, note string, evidence []string, at time.Time) error
+	ConfirmResolution(reportID string, confirmed bool) error
+	Scorecards(start, end time.Time) ([]models.AgencyScorecard, error)
+	HasScorecardSnapshot(month time.Time) (bool, error)
+	SaveScorecardSnapshots(snapshots []models.AgencyScorecardSnapshot) error
+	ScorecardSnapshots(agencyID uint) ([]models.AgencyScorecardSnapshot, error)
+}
+
+type agencyRepo struct {
+	DB *gorm.DB
+}
+
+func NewAgencyRepo(db *GormDB) AgencyRepository {
+	return &agencyRepo{db.DB}
+}
+
+func (a *agencyRepo) CreateAgency(agency *models.Agency) error {
+	return a.DB.Create(agency).Error
+}
+
+func (a *agencyRepo) ListAgencies() ([]models.Agency, error) {
+	var agencies []models.Agency
+	err := a.DB.Order("name").Find(&agencies).Error
+	return agencies, err
+}
+
+func (a *agencyRepo) GetAgency(id uint) (*models.Agency, error) {
+	var agency models.Agency
+	if err := a.DB.First(&agency, id).Error; err != nil {
+		return nil, err
+	}
+	return &agency, nil
+}
+
+// SetMemberAgency makes a user staff of an agency, or with a nil agencyID
+// removes them from it
+func (a *agencyRepo) SetMemberAgency(userID uint, agencyID *uint) error {
+	result := a.DB.Model(&models.User{}).Where("id = ?", userID).Update("agency_id", agencyID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// MemberAgencyID returns the agency the user works for, or nil
+func (a *agencyRepo) MemberAgencyID(userID uint) (*uint, error) {
+	var user models.User
+	if err := a.DB.Select("id", "agency_id").First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	return user.AgencyID, nil
+}
+
+func (a *agencyRepo) GetReport(reportID string) (*models.IncidentReport, error) {
+	var report models.IncidentReport
+	if err := a.DB.Where("id = ?", reportID).First(&report).Error; err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// AssignReport hands a report to an agency. Reassigning starts the clock
+// again, so any acknowledgement or resolution by the previous agency is
+// cleared.
+func (a *agencyRepo) AssignReport(reportID string, agencyID uint, at int64) error {
+	return a.DB.Model(&models.IncidentReport{}).Where("id = ?", reportID).Updates(map[string]interface{}{
+		"agency_id":            agencyID,
+		"assigned_at":          at,
+		"acknowledged_at":      0,
+		"resolved_at":          0,
+		"resolution_confirmed": nil,
+		"resolution_evidence":  nil,
+	}).Error
+}
+
+// AcknowledgeReport records that the agency has seen a report, with any
+// note as the official response, and publishes ReportAcknowledged. Only the
+// first acknowledgement is recorded, and false is returned for the others.
+func (a *agencyRepo) AcknowledgeReport(report *models.IncidentReport, note string, at time.Time) (bool, error) {
+	acknowledged := false
+	err := a.DB.Transaction(func(tx *gorm.DB) error {
+		updates := map[string]interface{}{"acknowledged_at": at.Unix()}
+		if note != "" {
+			updates["official_response"] = note
+			updates["official_response_at"] = at.Unix()
+		}
+		result := tx.Model(&models.IncidentReport{}).
+			Where("id = ? AND acknowledged_at = 0", report.ID).
+			Updates(updates)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		acknowledged = true
+		return writeOutbox(tx, events.ReportAcknowledged{
+			ReportID:   report.ID,
+			UserID:     report.UserID,
+			AgencyID:   *report.AgencyID,
+			Note:       note,
+			OccurredAt: at,
+		})
+	})
+	return acknowledged && err == nil, err
+}
+
+// ResolveReport moves a report to resolved on behalf of its agency,
+// recording the agency's note as the official response, and publishes
+// ReportResolved in the same transaction. A report resolved without being
+// acknowledged counts as acknowledged then. ErrReportResolved is returned
+// when the report was resolved first, however recently.
+func (a *agencyRepo) ResolveReport(report *models.IncidentReport, resolvedBy uint, note string, evidence []string, at time.Time) error {
+	return a.DB.Transaction(func(tx *gorm.DB) error {
+		// Claiming the resolution locks the report until the transaction
+		// ends, so a second resolve waits and then finds it taken
+		claim := tx.Model(&models.IncidentReport{}).Where("id = ? AND resolved_at = 0", report.ID).Update("resolved_at", at.Unix())
+		if claim.Error != nil {
+			return claim.Error
+		}
+		if claim.RowsAffected == 0 {
+			return ErrReportResolved
+		}
+
+		updates := map[string]interface{}{"resolved_at": at.Unix()}
+		if report.AcknowledgedAt == 0 {
+			updates["acknowledged_at"] = at.Unix()
+		}
+		if note != "" {
+			updates["official_response"] = note
+			updates["official_response_at"] = at.Unix()
+		}
+		if len(evidence) > 0 {
+			// Map updates skip the column's JSON serializer
+			encoded, err := json.Marshal(evidence)
+			if err != nil {
+				return err
+			}
+			updates["resolution_evidence"] = string(encoded)
+		}
+		if _, err := transitionStatus(tx, report.ID, models.ReportStatusResolved, &resolvedBy, note, at, updates); err != nil {
+			return err
+		}
+		return writeOutbox(tx, events.ReportResolved{
+			ReportID:   report.ID,
+			UserID:     report.UserID,
+			AgencyID:   *report.AgencyID,
+			Note:       note,
+			Evidence:   evidence,
+			OccurredAt: at,
+		})
+	})
+}
+
+func (a *agencyRepo) ConfirmResolution(reportID string, confirmed bool) error {
+	return a.DB.Model(&models.IncidentReport{}).Where("id = ?", reportID).Update("resolution_confirmed", confirmed).Error
+}
+
+// Scorecards measures every agency over the reports assigned to it between
+// start and end. Agencies with no reports in the period are included with
+// zero counts.
+func (a *agencyRepo) Scorecards(start, end time.Time) ([]models.AgencyScorecard, error) {
+	var scorecards []models.AgencyScorecard
+	err := a.DB.Table("agencies").
+		Select(`agencies.id AS agency_id, agencies.name AS agency_name,
+			COUNT(r.id) AS assigned,
+			COUNT(r.id) FILTER (WHERE r.acknowledged_at > 0) AS acknowledged,
+			PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY r.acknowledged_at - r.assigned_at) FILTER (WHERE r.acknowledged_at > 0) AS median_acknowledge_seconds,
+			COUNT(r.id) FILTER (WHERE r.resolved_at > 0) AS resolved,
+			PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY r.resolved_at - r.assigned_at) FILTER (WHERE r.resolved_at > 0) AS median_resolution_seconds,
+			COUNT(r.id) FILTER (WHERE r.resolved_at > 0 AND r.resolution_confirmed) AS confirmed,
+			COUNT(r.id) FILTER (WHERE r.resolved_at > 0 AND NOT r.resolution_confirmed) AS disputed`).
+		Joins("LEFT JOIN incident_reports r ON r.agency_id = agencies.id AND r.assigned_at >= ? AND r.assigned_at < ?", start.Unix(), end.Unix()).
+		Group("agencies.id, agencies.name").
+		Order("agencies.name").
+		Scan(&scorecards).Error
+	return scorecards, err
+}
+
+// HasScorecardSnapshot reports whether the month starting at month has been
+// snapshotted
+func (a *agencyRepo) HasScorecardSnapshot(month time.Time) (bool, error) {
+	var count int64
+	err := a.DB.Model(&models.AgencyScorecardSnapshot{}).
+		Where("month = ?", month.Format("2006-01-02")).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// SaveScorecardSnapshots stores snapshots, keeping any already taken for
+// the same agency and month
+func (a *agencyRepo) SaveScorecardSnapshots(snapshots []models.AgencyScorecardSnapshot) error {
+	if len(snapshots) == 0 {
+		return nil
+	}
+	return a.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&snapshots).Error
+}
+
+func (a *agencyRepo) ScorecardSnapshots(agencyID uint) ([]models.AgencyScorecardSnapshot, error) {
+	var snapshots []models.AgencyScorecardSnapshot
+	err := a.DB.Where("agency_id = ?", agencyID).Order("month DESC").Find(&snapshots).Error
+	return snapshots, err
+}
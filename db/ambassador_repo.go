@@ -0,0 +1,135 @@
+package db
+
+import (
+	"time"
+
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// closedStatuses are the report statuses that need nothing more from anyone
+var closedStatuses = []string{"rejected", models.ReportStatusClosed, models.ReportStatusWithdrawn}
+
+// AmbassadorRepository stores who the ambassador of each LGA is and
+// measures their areas
+type AmbassadorRepository interface {
+	FindLGAName(name string) (string, error)
+	SetAmbassadorLGA(userID uint, lga string) error
+	Dashboard(lga string, newSince, activeSince int64) (*models.AmbassadorDashboard, error)
+	PendingReports(lga string, categories []string, limit int) ([]models.AmbassadorReportBrief, error)
+	Summaries(start, end time.Time) ([]models.AmbassadorSummary, error)
+	HasSummaries(month time.Time) (bool, error)
+	SaveSummaries(summaries []models.AmbassadorSummary) error
+	ListSummaries(month time.Time) ([]models.AmbassadorSummary, error)
+	UserSummaries(userID uint) ([]models.AmbassadorSummary, error)
+}
+
+type ambassadorRepo struct {
+	DB *gorm.DB
+}
+
+func NewAmbassadorRepo(db *GormDB) AmbassadorRepository {
+	return &ambassadorRepo{db.DB}
+}
+
+// FindLGAName returns the name of the LGA called name, whatever its case
+func (a *ambassadorRepo) FindLGAName(name string) (string, error) {
+	var lga models.LGA
+	if err := a.DB.Where("LOWER(name) = LOWER(?)", name).First(&lga).Error; err != nil {
+		return "", err
+	}
+	return lga.Name, nil
+}
+
+// SetAmbassadorLGA makes a user ambassador of an LGA, or with an empty lga
+// ends their role
+func (a *ambassadorRepo) SetAmbassadorLGA(userID uint, lga string) error {
+	result := a.DB.Model(&models.User{}).Where("id = ?", userID).Update("ambassador_lga", lga)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Dashboard counts the reports of an LGA made since newSince, those still
+// open and the reporters active since activeSince
+func (a *ambassadorRepo) Dashboard(lga string, newSince, activeSince int64) (*models.AmbassadorDashboard, error) {
+	dashboard := &models.AmbassadorDashboard{LGA: lga}
+	err := a.DB.Model(&models.IncidentReport{}).
+		Select(`COUNT(*) FILTER (WHERE created_at >= ?) AS new_reports,
+			COUNT(*) FILTER (WHERE resolved_at = 0 AND report_status NOT IN ?) AS unresolved,
+			COUNT(DISTINCT user_id) FILTER (WHERE created_at >= ?) AS active_reporters`,
+			newSince, closedStatuses, activeSince).
+		Where("lga_name = ?", lga).
+		Scan(dashboard).Error
+	return dashboard, err
+}
+
+// PendingReports returns the oldest reports of an LGA awaiting review in
+// categories, leaving out those sent through anonymizers or datacenters
+func (a *ambassadorRepo) PendingReports(lga string, categories []string, limit int) ([]models.AmbassadorReportBrief, error) {
+	reports := []models.AmbassadorReportBrief{}
+	if len(categories) == 0 {
+		return reports, nil
+	}
+	err := a.DB.Model(&models.IncidentReport{}).
+		Select("id, category, description, created_at").
+		Where("lga_name = ? AND LOWER(category) IN ? AND network_flag = ''", lga, categories).
+		Where("report_status = 'pending' OR report_status = ''").
+		Order("created_at ASC").
+		Limit(limit).
+		Scan(&reports).Error
+	return reports, err
+}
+
+// Summaries measures every ambassador's LGA between start and end, with the
+// reports they approved in that time
+func (a *ambassadorRepo) Summaries(start, end time.Time) ([]models.AmbassadorSummary, error) {
+	var summaries []models.AmbassadorSummary
+	err := a.DB.Table("users").
+		Select(`users.id AS user_id, users.ambassador_lga AS lga,
+			(SELECT COUNT(*) FROM incident_reports r WHERE r.lga_name = users.ambassador_lga AND r.created_at >= @start AND r.created_at < @end) AS new_reports,
+			(SELECT COUNT(*) FROM incident_reports r WHERE r.lga_name = users.ambassador_lga AND r.resolved_at >= @start AND r.resolved_at < @end) AS resolved,
+			(SELECT COUNT(DISTINCT r.user_id) FROM incident_reports r WHERE r.lga_name = users.ambassador_lga AND r.created_at >= @start AND r.created_at < @end) AS active_reporters,
+			(SELECT COUNT(*) FROM moderation_decisions d WHERE d.moderator_id = users.id AND d.decision = 'approved' AND d.decided_at >= @start AND d.decided_at < @end) AS verified`,
+			map[string]interface{}{"start": start.Unix(), "end": end.Unix()}).
+		Where("users.ambassador_lga <> '' AND users.deleted_at IS NULL").
+		Order("users.id").
+		Scan(&summaries).Error
+	return summaries, err
+}
+
+// HasSummaries reports whether the month starting at month has been
+// summarised
+func (a *ambassadorRepo) HasSummaries(month time.Time) (bool, error) {
+	var count int64
+	err := a.DB.Model(&models.AmbassadorSummary{}).
+		Where("month = ?", month.Format("2006-01-02")).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// SaveSummaries stores summaries, keeping any already taken for the same
+// ambassador and month
+func (a *ambassadorRepo) SaveSummaries(summaries []models.AmbassadorSummary) error {
+	if len(summaries) == 0 {
+		return nil
+	}
+	return a.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&summaries).Error
+}
+
+func (a *ambassadorRepo) ListSummaries(month time.Time) ([]models.AmbassadorSummary, error) {
+	var summaries []models.AmbassadorSummary
+	err := a.DB.Where("month = ?", month.Format("2006-01-02")).Order("verified DESC, user_id").Find(&summaries).Error
+	return summaries, err
+}
+
+func (a *ambassadorRepo) UserSummaries(userID uint) ([]models.AmbassadorSummary, error) {
+	var summaries []models.AmbassadorSummary
+	err := a.DB.Where("user_id = ?", userID).Order("month DESC").Find(&summaries).Error
+	return summaries, err
+}
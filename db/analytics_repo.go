@@ -0,0 +1,210 @@
+package db
+
+import (
+	"errors"
+	"time"
+
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrUnknownDimension is returned when a report aggregation is grouped by
+	// something other than the names in ReportDimensions.
+	ErrUnknownDimension = errors.New("unknown grouping dimension")
+	// ErrUnknownInterval is returned for a timeseries interval other than
+	// day, week or month.
+	ErrUnknownInterval = errors.New("unknown timeseries interval")
+	// ErrUnknownSnapshotScope is returned for a snapshot scope other than
+	// national, state or category.
+	ErrUnknownSnapshotScope = errors.New("unknown snapshot scope")
+)
+
+// snapshotScopes maps each snapshot scope to the column it groups reports
+// by; the national scope is not grouped
+var snapshotScopes = map[string]string{
+	models.SnapshotNational: "",
+	models.SnapshotState:    "state_name",
+	models.SnapshotCategory: "category",
+}
+
+// ReportDimensions maps the grouping names accepted by the analytics
+// endpoints to incident report columns, from coarsest to finest.
+var ReportDimensions = map[string]string{
+	"state":    "state_name",
+	"lga":      "lga_name",
+	"ward":     "ward_name",
+	"category": "category",
+}
+
+var timeseriesIntervals = map[string]bool{"day": true, "week": true, "month": true}
+
+// AggregateQuery selects and groups the reports of an aggregation. Empty
+// filters match everything.
+type AggregateQuery struct {
+	GroupBy   string
+	StateName string
+	LGAName   string
+	WardName  string
+	Category  string
+	// Values restricts the groups to those listed, for comparisons
+	Values     []string
+	Start, End *time.Time
+}
+
+// AnalyticsRepository holds the grouped report aggregations behind the
+// dashboards.
+type AnalyticsRepository interface {
+	CountReports(q AggregateQuery) ([]models.GroupCount, error)
+	ReportTimeseries(q AggregateQuery, interval string) ([]models.TimeseriesPoint, error)
+	HasSnapshot(date time.Time) (bool, error)
+	TakeSnapshot(date time.Time) (int64, error)
+	SnapshotOn(scope string, date time.Time) (time.Time, []models.AggregateSnapshot, error)
+	QueryReports(q ReportQuery) ([]map[string]interface{}, error)
+}
+
+type analyticsRepo struct {
+	DB *gorm.DB
+}
+
+func NewAnalyticsRepo(db *GormDB) AnalyticsRepository {
+	return &analyticsRepo{db.DB}
+}
+
+// filtered applies q's filters and returns the column it groups by. Reports
+// with no value for that column, such as those not yet placed in a ward,
+// are left out.
+func (a *analyticsRepo) filtered(q AggregateQuery) (*gorm.DB, string, error) {
+	column, ok := ReportDimensions[q.GroupBy]
+	if !ok {
+		return nil, "", ErrUnknownDimension
+	}
+
+	query := a.DB.Table("incident_reports").Where(column + " <> ''")
+	for _, filter := range [][2]string{
+		{"state_name", q.StateName},
+		{"lga_name", q.LGAName},
+		{"ward_name", q.WardName},
+		{"category", q.Category},
+	} {
+		if filter[1] != "" {
+			query = query.Where(filter[0]+" = ?", filter[1])
+		}
+	}
+	if len(q.Values) > 0 {
+		query = query.Where(column+" IN ?", q.Values)
+	}
+	if q.Start != nil {
+		query = query.Where("timeof_incidence >= ?", *q.Start)
+	}
+	if q.End != nil {
+		query = query.Where("timeof_incidence < ?", *q.End)
+	}
+	return query, column, nil
+}
+
+// CountReports returns the number of reports per group, largest first.
+func (a *analyticsRepo) CountReports(q AggregateQuery) ([]models.GroupCount, error) {
+	query, column, err := a.filtered(q)
+	if err != nil {
+		return nil, err
+	}
+
+	var counts []models.GroupCount
+	err = query.Select(column + " AS \"group\", COUNT(*) AS count").
+		Group(column).
+		Order("count DESC, \"group\"").
+		Scan(&counts).Error
+	return counts, err
+}
+
+// ReportTimeseries returns the number of reports per group per interval, in
+// time order.
+func (a *analyticsRepo) ReportTimeseries(q AggregateQuery, interval string) ([]models.TimeseriesPoint, error) {
+	if !timeseriesIntervals[interval] {
+		return nil, ErrUnknownInterval
+	}
+	query, column, err := a.filtered(q)
+	if err != nil {
+		return nil, err
+	}
+
+	var points []models.TimeseriesPoint
+	err = query.Select("DATE_TRUNC(?, timeof_incidence) AS period, "+column+" AS \"group\", COUNT(*) AS count", interval).
+		Group("period, " + column).
+		Order("period, \"group\"").
+		Scan(&points).Error
+	return points, err
+}
+
+// HasSnapshot reports whether the snapshot for date has been taken
+func (a *analyticsRepo) HasSnapshot(date time.Time) (bool, error) {
+	var count int64
+	err := a.DB.Model(&models.AggregateSnapshot{}).
+		Where("snapshot_date = ?", date.Format("2006-01-02")).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// TakeSnapshot records the current report totals of every scope under date
+// and returns the number of rows written. A snapshot already taken for the
+// date is left as it was, so history never changes once recorded.
+func (a *analyticsRepo) TakeSnapshot(date time.Time) (int64, error) {
+	var written int64
+	err := a.DB.Transaction(func(tx *gorm.DB) error {
+		for scope, column := range snapshotScopes {
+			group, where, groupBy := "''", "", ""
+			if column != "" {
+				group, where, groupBy = column, "WHERE "+column+" <> ''", "GROUP BY "+column
+			}
+			result := tx.Exec(`
+                INSERT INTO aggregate_snapshots
+                    (snapshot_date, scope, group_name, reports, pending, verified, rejected, closed, resolution_rate, taken_at)
+                SELECT ?, ?, `+group+`,
+                    COUNT(*),
+                    COUNT(*) FILTER (WHERE report_status IN ('pending', '')),
+                    COUNT(*) FILTER (WHERE LOWER(report_status) IN `+publishedStatuses+`),
+                    COUNT(*) FILTER (WHERE LOWER(report_status) = 'rejected'),
+                    COUNT(*) FILTER (WHERE LOWER(report_status) = ?),
+                    COALESCE(COUNT(*) FILTER (WHERE report_status NOT IN ('pending', ''))::float / NULLIF(COUNT(*), 0), 0),
+                    NOW()
+                FROM incident_reports
+                `+where+`
+                `+groupBy+`
+                ON CONFLICT (snapshot_date, scope, group_name) DO NOTHING
+            `, date.Format("2006-01-02"), scope, models.ReportStatusClosed)
+			if result.Error != nil {
+				return result.Error
+			}
+			written += result.RowsAffected
+		}
+		return nil
+	})
+	return written, err
+}
+
+// SnapshotOn returns the latest snapshot of scope taken for date or before
+// it, and the date it was taken for. Groups are ordered by report count,
+// largest first.
+func (a *analyticsRepo) SnapshotOn(scope string, date time.Time) (time.Time, []models.AggregateSnapshot, error) {
+	if _, ok := snapshotScopes[scope]; !ok {
+		return time.Time{}, nil, ErrUnknownSnapshotScope
+	}
+
+	var latest *time.Time
+	if err := a.DB.Model(&models.AggregateSnapshot{}).
+		Select("MAX(snapshot_date)").
+		Where("scope = ? AND snapshot_date <= ?", scope, date.Format("2006-01-02")).
+		Scan(&latest).Error; err != nil {
+		return time.Time{}, nil, err
+	}
+	if latest == nil {
+		return time.Time{}, nil, gorm.ErrRecordNotFound
+	}
+
+	var snapshots []models.AggregateSnapshot
+	err := a.DB.Where("scope = ? AND snapshot_date = ?", scope, latest.Format("2006-01-02")).
+		Order("reports DESC, group_name ASC").
+		Find(&snapshots).Error
+	return *latest, snapshots, err
+}
@@ -0,0 +1,130 @@
+package db
+
+import (
+	"time"
+
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+// AnnouncementRepository stores announcements and selects their audiences
+type AnnouncementRepository interface {
+	CreateAnnouncement(announcement *models.Announcement) error
+	GetAnnouncement(id uint) (*models.Announcement, error)
+	ListAnnouncements(page int) ([]models.Announcement, error)
+	DueAnnouncements(now time.Time) ([]models.Announcement, error)
+	SetAnnouncementStatus(id uint, status string, recipients int64) error
+	CountAudience(segment models.AnnouncementSegment) (int64, error)
+	AudiencePage(segment models.AnnouncementSegment, afterID uint, limit int) ([]uint, error)
+	AnnouncementDelivery(id uint) (delivered, opened int64, err error)
+}
+
+type announcementRepo struct {
+	DB *gorm.DB
+}
+
+func NewAnnouncementRepo(db *GormDB) AnnouncementRepository {
+	return &announcementRepo{db.DB}
+}
+
+func (a *announcementRepo) CreateAnnouncement(announcement *models.Announcement) error {
+	return a.DB.Create(announcement).Error
+}
+
+func (a *announcementRepo) GetAnnouncement(id uint) (*models.Announcement, error) {
+	var announcement models.Announcement
+	if err := a.DB.First(&announcement, id).Error; err != nil {
+		return nil, err
+	}
+	return &announcement, nil
+}
+
+// ListAnnouncements returns the newest announcements first
+func (a *announcementRepo) ListAnnouncements(page int) ([]models.Announcement, error) {
+	var announcements []models.Announcement
+	err := a.DB.Order("created_at DESC, id DESC").
+		Offset((page - 1) * DefaultPageSize).
+		Limit(DefaultPageSize).
+		Find(&announcements).Error
+	return announcements, err
+}
+
+// DueAnnouncements returns announcements whose time has come, including any
+// left part sent when the server stopped
+func (a *announcementRepo) DueAnnouncements(now time.Time) ([]models.Announcement, error) {
+	var announcements []models.Announcement
+	err := a.DB.Where("status IN ? AND scheduled_at <= ?",
+		[]string{models.AnnouncementScheduled, models.AnnouncementSending}, now.Unix()).
+		Order("scheduled_at ASC").
+		Find(&announcements).Error
+	return announcements, err
+}
+
+func (a *announcementRepo) SetAnnouncementStatus(id uint, status string, recipients int64) error {
+	updates := map[string]interface{}{
+		"status":     status,
+		"recipients": recipients,
+		"updated_at": time.Now().Unix(),
+	}
+	if status == models.AnnouncementSent {
+		updates["sent_at"] = time.Now().Unix()
+	}
+	return a.DB.Model(&models.Announcement{}).Where("id = ?", id).Updates(updates).Error
+}
+
+// audience narrows the users table to a segment. Users have an LGA but no
+// state, so the state is matched through the LGAs that belong to it.
+func (a *announcementRepo) audience(segment models.AnnouncementSegment) *gorm.DB {
+	query := a.DB.Model(&models.User{}).Scopes(activeUsers)
+	if segment.LGAName != "" {
+		query = query.Where("users.lga_name ILIKE ?", segment.LGAName)
+	}
+	if segment.StateName != "" {
+		query = query.Where("users.lga_name IN (?)", a.DB.Table("lgas").
+			Select("lgas.name").
+			Joins("JOIN states ON states.id = lgas.state_id").
+			Where("states.name ILIKE ?", segment.StateName))
+	}
+	if segment.Role != "" {
+		query = query.Where("users.role_id IN (?)", a.DB.Model(&models.Role{}).Select("id").Where("name ILIKE ?", segment.Role))
+	}
+	if segment.InactiveDays > 0 {
+		since := time.Now().AddDate(0, 0, -segment.InactiveDays)
+		query = query.Where("users.id NOT IN (?)", a.DB.Model(&models.ActivityEvent{}).
+			Select("DISTINCT user_id").
+			Where("occurred_at >= ?", since))
+	}
+	return query
+}
+
+func (a *announcementRepo) CountAudience(segment models.AnnouncementSegment) (int64, error) {
+	var count int64
+	err := a.audience(segment).Count(&count).Error
+	return count, err
+}
+
+// AudiencePage returns the IDs of the next limit users in the segment after
+// afterID, so large audiences can be walked in batches
+func (a *announcementRepo) AudiencePage(segment models.AnnouncementSegment, afterID uint, limit int) ([]uint, error) {
+	var ids []uint
+	err := a.audience(segment).
+		Where("users.id > ?", afterID).
+		Order("users.id ASC").
+		Limit(limit).
+		Pluck("users.id", &ids).Error
+	return ids, err
+}
+
+// AnnouncementDelivery counts the in-app notifications an announcement
+// created and how many of them have been read
+func (a *announcementRepo) AnnouncementDelivery(id uint) (int64, int64, error) {
+	var counts struct {
+		Delivered int64
+		Opened    int64
+	}
+	err := a.DB.Model(&models.Notification{}).
+		Select("COUNT(*) AS delivered, COUNT(*) FILTER (WHERE is_read) AS opened").
+		Where("announcement_id = ?", id).
+		Scan(&counts).Error
+	return counts.Delivered, counts.Opened, err
+}
@@ -0,0 +1,26 @@
+package db
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+// writeAudit records an audit entry for a change in tx, the transaction that
+// makes the change, with details encoded as JSON
+func writeAudit(tx *gorm.DB, actorID *uint, action, targetType, targetID string, details interface{}) error {
+	encoded, err := json.Marshal(details)
+	if err != nil {
+		return err
+	}
+	return tx.Create(&models.AuditEntry{
+		ActorID:    actorID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Details:    string(encoded),
+		CreatedAt:  time.Now().Unix(),
+	}).Error
+}
@@ -5,11 +5,14 @@ import (
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
 	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/pii"
 	"gorm.io/gorm"
 	"log"
 	"strings"
 )
 
+//go:generate mockgen -destination=../mocks/auth_repository_mock.go -package=mocks github.com/techagentng/citizenx/db AuthRepository
+
 type AuthRepository interface {
 	CreateUser(user *models.User) (*models.User, error)
 	CreateGoogleUser(user *models.CreateSocialUserParams) (*models.CreateSocialUserParams, error)
@@ -41,6 +44,10 @@ type AuthRepository interface {
 	GetUserRoleByUserID(userID uint) (*models.Role, error)
 	SoftDeleteUser(userID uint) error
 	UpdateUserPassword(user *models.User, hashedPassword string) error
+	UpdateUserRole(userID uint, role *models.Role) error
+	UpdateEmail(userID uint, email string) (bool, error)
+	DeactivateUser(userID uint, at int64) error
+	ReactivateUser(userID uint) (bool, error)
 }
 
 type authRepo struct {
@@ -100,7 +107,7 @@ func (a *authRepo) CreateUser(user *models.User) (*models.User, error) {
 func (a *authRepo) CreateUserWithMacAddress(user *models.LoginRequestMacAddress) (*models.LoginRequestMacAddress, error) {
 	// Attempt to find an existing user with the same MAC address
 	existingUser := &models.User{}
-	err := a.DB.Where("mac_address = ?", user.MacAddress).First(existingUser).Error
+	err := a.DB.Where("mac_address_index = ?", pii.BlindIndex(user.MacAddress)).First(existingUser).Error
 	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		log.Println("DB error:", err)
 		return nil, fmt.Errorf("could not find existing user: %v", err)
@@ -156,7 +163,7 @@ func (a *authRepo) IsEmailExist(email string) error {
 
 func (a *authRepo) IsPhoneExist(phone string) error {
 	var count int64
-	err := a.DB.Model(&models.User{}).Where("telephone = ?", phone).Count(&count).Error
+	err := a.DB.Model(&models.User{}).Where("telephone_index = ?", pii.BlindIndex(phone)).Count(&count).Error
 	if err != nil {
 		return errors.Wrap(err, "gorm.count error")
 	}
@@ -239,7 +246,7 @@ func (a *authRepo) FindUserByID(id uint) (*models.User, error) {
 
 func (a *authRepo) FindUserByMacAddress(macAddress string) (*models.LoginRequestMacAddress, error) {
 	var user models.LoginRequestMacAddress
-	err := a.DB.Where("mac_address = ?", macAddress).First(&user).Error
+	err := a.DB.Where("mac_address_index = ?", pii.BlindIndex(macAddress)).First(&user).Error
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("user not found")
@@ -274,6 +281,25 @@ func (a *authRepo) UpsertUserImage(userID uint, filepath string) error {
 	return nil
 }
 
+// UpdateEmail sets a new, unverified email on the account, reporting false
+// when another account already has it
+func (a *authRepo) UpdateEmail(userID uint, email string) (bool, error) {
+	updated := false
+	err := a.DB.Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.User{}).Where("LOWER(email) = LOWER(?) AND id <> ?", email, userID).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return nil
+		}
+		updated = true
+		return tx.Model(&models.User{}).Where("id = ?", userID).
+			Updates(map[string]interface{}{"email": email, "is_email_active": false}).Error
+	})
+	return updated, err
+}
+
 // Repository method to update user profile in the database
 func (a *authRepo) EditUserProfile(userID uint, userDetails *models.EditProfileResponse) error {
 	// Fetch the user from the database
@@ -289,6 +315,13 @@ func (a *authRepo) EditUserProfile(userID uint, userDetails *models.EditProfileR
 	if userDetails.Username != "" {
 		user.Username = userDetails.Username
 	}
+	if userDetails.Locale != "" {
+		user.Locale = userDetails.Locale
+	}
+	if userDetails.DateOfBirth != "" {
+		user.DateOfBirth = userDetails.DateOfBirth
+		user.RestrictedUntil = userDetails.RestrictedUntil
+	}
 	// Update other fields as needed (e.g., profile image, email, etc.)
 
 	// Perform the update operation
@@ -341,7 +374,7 @@ func (a *authRepo) SetUserOffline(user *models.User) error {
 
 func (a *authRepo) GetOnlineUserCount() (int64, error) {
 	var count int64
-	result := a.DB.Model(&models.User{}).Where("online = ?", true).Count(&count)
+	result := a.DB.Model(&models.User{}).Scopes(listedUsers).Where("online = ?", true).Count(&count)
 	if result.Error != nil {
 		log.Printf("Error fetching online user count: %v", result.Error)
 		return 0, result.Error
@@ -351,7 +384,7 @@ func (a *authRepo) GetOnlineUserCount() (int64, error) {
 
 func (a *authRepo) GetAllUsers() ([]models.User, error) {
 	var users []models.User
-	result := a.DB.Find(&users)
+	result := a.DB.Scopes(listedUsers).Find(&users)
 	if result.Error != nil {
 		log.Printf("Error fetching all users: %v", result.Error)
 		return nil, result.Error
@@ -439,8 +472,14 @@ func (a *authRepo) SoftDeleteUser(userID uint) error {
 		return err
 	}
 
-	// Perform soft delete
-	return a.DB.Delete(&user).Error
+	// Perform soft delete, dropping the warehouse pseudonym with the account
+	// so the rows exported under it no longer lead back to the user
+	return a.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&user).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.WarehousePseudonym{}).Error
+	})
 }
 
 // UpdateUserPassword updates the password for a given user
@@ -448,3 +487,35 @@ func (a *authRepo) UpdateUserPassword(user *models.User, hashedPassword string)
 	user.Password = hashedPassword
 	return a.DB.Save(user).Error
 }
+
+// UpdateUserRole assigns a role to a user, keeping the admin flag in step with it.
+func (a *authRepo) UpdateUserRole(userID uint, role *models.Role) error {
+	return a.DB.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"role_id":      role.ID,
+		"admin_status": role.Name == models.RoleAdmin,
+	}).Error
+}
+
+// DeactivateUser hides the account until the user next signs in
+func (a *authRepo) DeactivateUser(userID uint, at int64) error {
+	return a.DB.Model(&models.User{}).Where("id = ?", userID).
+		Updates(map[string]interface{}{"deactivated_at": at, "online": false}).Error
+}
+
+// ReactivateUser brings back a deactivated account, reporting false when it
+// was not deactivated
+func (a *authRepo) ReactivateUser(userID uint) (bool, error) {
+	result := a.DB.Model(&models.User{}).Where("id = ? AND deactivated_at IS NOT NULL", userID).Update("deactivated_at", nil)
+	return result.RowsAffected > 0, result.Error
+}
+
+// activeUsers leaves deleted and deactivated accounts out
+func activeUsers(db *gorm.DB) *gorm.DB {
+	return db.Where("COALESCE(users.deleted_at, 0) = 0 AND users.deactivated_at IS NULL")
+}
+
+// listedUsers narrows to the users other users may see listed, leaving out
+// users in restricted mode, who have no public profile
+func listedUsers(db *gorm.DB) *gorm.DB {
+	return activeUsers(db).Where("users.restricted_until <= EXTRACT(EPOCH FROM NOW())")
+}
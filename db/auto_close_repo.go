@@ -0,0 +1,133 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/techagentng/citizenx/events"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AutoCloseRepository stores the auto-close rules and applies them
+type AutoCloseRepository interface {
+	ListRules() ([]models.AutoCloseRule, error)
+	SaveRule(rule *models.AutoCloseRule) error
+	DeleteRule(id uint) error
+	CloseStaleReports(rule models.AutoCloseRule, overridden []string, limit int) (int, error)
+}
+
+type autoCloseRepo struct {
+	DB *gorm.DB
+}
+
+func NewAutoCloseRepo(db *GormDB) AutoCloseRepository {
+	return &autoCloseRepo{db.DB}
+}
+
+func (a *autoCloseRepo) ListRules() ([]models.AutoCloseRule, error) {
+	var rules []models.AutoCloseRule
+	err := a.DB.Order("category ASC, status ASC").Find(&rules).Error
+	return rules, err
+}
+
+// SaveRule creates the rule, or replaces the existing rule for the same
+// category and status
+func (a *autoCloseRepo) SaveRule(rule *models.AutoCloseRule) error {
+	return a.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "category"}, {Name: "status"}},
+		DoUpdates: clause.AssignmentColumns([]string{"after_days", "enabled", "created_by", "updated_at"}),
+	}).Create(rule).Error
+}
+
+// DeleteRule removes a rule, returning gorm.ErrRecordNotFound when there is
+// no such rule
+func (a *autoCloseRepo) DeleteRule(id uint) error {
+	result := a.DB.Delete(&models.AutoCloseRule{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// CloseStaleReports closes up to limit reports the rule matches, those that
+// have been in the rule's status for longer than its age, and returns how
+// many it closed. A rule for every category skips the overridden categories,
+// which have rules of their own. Each closed report gets an audit entry and
+// a ReportClosed event in the same transaction.
+func (a *autoCloseRepo) CloseStaleReports(rule models.AutoCloseRule, overridden []string, limit int) (int, error) {
+	cutoff := time.Now().AddDate(0, 0, -rule.AfterDays).Unix()
+	closed := 0
+	err := a.DB.Transaction(func(tx *gorm.DB) error {
+		query := tx.Model(&models.IncidentReport{}).
+			Select("id, user_id, report_status").
+			Where("COALESCE(NULLIF(status_updated_at, 0), created_at) < ?", cutoff)
+		if rule.Status == "pending" {
+			// Reports submitted before statuses were set have none
+			query = query.Where("report_status = 'pending' OR report_status = ''")
+		} else {
+			query = query.Where("LOWER(report_status) = ?", rule.Status)
+		}
+		if rule.Category != "" {
+			query = query.Where("category ILIKE ?", rule.Category)
+		} else if len(overridden) > 0 {
+			query = query.Where("LOWER(category) NOT IN ?", overridden)
+		}
+
+		var reports []models.IncidentReport
+		if err := query.Order("created_at ASC").
+			Limit(limit).
+			Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Find(&reports).Error; err != nil {
+			return err
+		}
+		if len(reports) == 0 {
+			return nil
+		}
+
+		now := time.Now()
+		ids := make([]string, len(reports))
+		for i, report := range reports {
+			ids[i] = report.ID.String()
+		}
+		if err := tx.Model(&models.IncidentReport{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{
+				"report_status":     models.ReportStatusClosed,
+				"status_updated_at": now.Unix(),
+			}).Error; err != nil {
+			return err
+		}
+
+		for _, report := range reports {
+			if _, err := recordTransition(tx, report.ID, report.ReportStatus, models.ReportStatusClosed, nil, fmt.Sprintf("no change for %d days", rule.AfterDays), now); err != nil {
+				return err
+			}
+			if err := writeAudit(tx, nil, models.AuditReportAutoClosed, "incident_report", report.ID.String(), map[string]interface{}{
+				"rule_id":         rule.ID,
+				"category":        rule.Category,
+				"previous_status": report.ReportStatus,
+				"after_days":      rule.AfterDays,
+			}); err != nil {
+				return err
+			}
+			if err := writeOutbox(tx, events.ReportClosed{
+				ReportID:       report.ID,
+				UserID:         report.UserID,
+				PreviousStatus: report.ReportStatus,
+				RuleID:         rule.ID,
+				AfterDays:      rule.AfterDays,
+				OccurredAt:     now,
+			}); err != nil {
+				return err
+			}
+		}
+		closed = len(reports)
+		return nil
+	})
+	return closed, err
+}
@@ -0,0 +1,96 @@
+package db
+
+import (
+	"time"
+
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+// CalendarRepository stores the calendar of holidays, elections and seasons
+// that analytics are read against
+type CalendarRepository interface {
+	ListCalendarEvents() ([]models.CalendarEvent, error)
+	CalendarEventsBetween(from, to string) ([]models.CalendarEvent, error)
+	SaveCalendarEvent(event *models.CalendarEvent) error
+	DeleteCalendarEvent(id uint) error
+}
+
+type calendarRepo struct {
+	DB *gorm.DB
+}
+
+func NewCalendarRepo(db *GormDB) CalendarRepository {
+	return &calendarRepo{db.DB}
+}
+
+func (r *calendarRepo) ListCalendarEvents() ([]models.CalendarEvent, error) {
+	var calendar []models.CalendarEvent
+	err := r.DB.Order("start_date ASC, id ASC").Find(&calendar).Error
+	return calendar, err
+}
+
+// CalendarEventsBetween returns the events overlapping the days from to to,
+// as YYYY-MM-DD, along with every recurring event, whichever year it was
+// entered for
+func (r *calendarRepo) CalendarEventsBetween(from, to string) ([]models.CalendarEvent, error) {
+	var calendar []models.CalendarEvent
+	err := r.DB.Where("recurring OR (start_date <= ? AND end_date >= ?)", to, from).
+		Order("start_date ASC, id ASC").
+		Find(&calendar).Error
+	return calendar, err
+}
+
+// SaveCalendarEvent creates the event, or replaces it when it has an ID
+func (r *calendarRepo) SaveCalendarEvent(event *models.CalendarEvent) error {
+	if event.ID == 0 {
+		return r.DB.Create(event).Error
+	}
+	result := r.DB.Model(event).Select("*").Omit("created_at", "created_by").Updates(event)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// DeleteCalendarEvent removes an event, returning gorm.ErrRecordNotFound
+// when there is no such event
+func (r *calendarRepo) DeleteCalendarEvent(id uint) error {
+	result := r.DB.Delete(&models.CalendarEvent{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// seedCalendar enters Nigeria's fixed public holidays and the rainy season.
+// Holidays that move with the moon, such as the Eids, and elections are
+// entered by admins as their dates are announced.
+func seedCalendar(db *gorm.DB) error {
+	now := time.Now().Unix()
+	calendar := []models.CalendarEvent{
+		{Kind: models.CalendarHoliday, Name: "New Year's Day", StartDate: "2024-01-01", EndDate: "2024-01-01"},
+		{Kind: models.CalendarHoliday, Name: "Workers' Day", StartDate: "2024-05-01", EndDate: "2024-05-01"},
+		{Kind: models.CalendarHoliday, Name: "Democracy Day", StartDate: "2024-06-12", EndDate: "2024-06-12"},
+		{Kind: models.CalendarHoliday, Name: "Independence Day", StartDate: "2024-10-01", EndDate: "2024-10-01"},
+		{Kind: models.CalendarHoliday, Name: "Christmas Day", StartDate: "2024-12-25", EndDate: "2024-12-25"},
+		{Kind: models.CalendarHoliday, Name: "Boxing Day", StartDate: "2024-12-26", EndDate: "2024-12-26"},
+		// Flooding reports climb through the rains, which peak in the south
+		// from June to September
+		{Kind: models.CalendarSeason, Name: "Rainy season", StartDate: "2024-04-01", EndDate: "2024-10-31", Category: "Environment", ExpectedPercent: 150},
+	}
+	for i := range calendar {
+		calendar[i].Recurring = true
+		calendar[i].CreatedAt, calendar[i].UpdatedAt = now, now
+		if calendar[i].ExpectedPercent == 0 {
+			calendar[i].ExpectedPercent = 100
+		}
+	}
+	return db.Create(&calendar).Error
+}
@@ -0,0 +1,109 @@
+package db
+
+import (
+	"github.com/techagentng/citizenx/events"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+// CollaboratorRepository persists the collaborators on reports and the
+// contributions made to them
+type CollaboratorRepository interface {
+	AddCollaborator(collaborator *models.ReportCollaborator, evts ...events.Event) error
+	RemoveCollaborator(reportID string, userID uint) (bool, error)
+	Collaborators(reportID string) ([]models.ReportCollaborator, error)
+	IsCollaborator(reportID string, userID uint) (bool, error)
+	SetRewardShares(reportID string, shares map[uint]int) error
+	AddContributions(reportID string, contributions []models.ReportContribution, changes map[string]interface{}) error
+	Contributions(reportID string, page int) ([]models.ReportContribution, error)
+}
+
+type collaboratorRepo struct {
+	DB *gorm.DB
+}
+
+func NewCollaboratorRepo(db *GormDB) CollaboratorRepository {
+	return &collaboratorRepo{db.DB}
+}
+
+func (r *collaboratorRepo) AddCollaborator(collaborator *models.ReportCollaborator, evts ...events.Event) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Username").Create(collaborator).Error; err != nil {
+			return err
+		}
+		return writeOutbox(tx, evts...)
+	})
+}
+
+// RemoveCollaborator reports false when the user was not a collaborator
+func (r *collaboratorRepo) RemoveCollaborator(reportID string, userID uint) (bool, error) {
+	result := r.DB.Where("report_id = ? AND user_id = ?", reportID, userID).Delete(&models.ReportCollaborator{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// Collaborators returns a report's collaborators with their usernames, in
+// the order they were added
+func (r *collaboratorRepo) Collaborators(reportID string) ([]models.ReportCollaborator, error) {
+	var collaborators []models.ReportCollaborator
+	err := r.DB.Model(&models.ReportCollaborator{}).
+		Select("report_collaborators.*, COALESCE(users.username, '') AS username").
+		Joins("LEFT JOIN users ON users.id = report_collaborators.user_id").
+		Where("report_collaborators.report_id = ?", reportID).
+		Order("report_collaborators.id").
+		Find(&collaborators).Error
+	return collaborators, err
+}
+
+func (r *collaboratorRepo) IsCollaborator(reportID string, userID uint) (bool, error) {
+	var count int64
+	err := r.DB.Model(&models.ReportCollaborator{}).
+		Where("report_id = ? AND user_id = ?", reportID, userID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// SetRewardShares replaces the reward share of every collaborator on a
+// report; collaborators missing from shares get none
+func (r *collaboratorRepo) SetRewardShares(reportID string, shares map[uint]int) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.ReportCollaborator{}).
+			Where("report_id = ?", reportID).
+			Update("reward_share", 0).Error; err != nil {
+			return err
+		}
+		for userID, share := range shares {
+			if err := tx.Model(&models.ReportCollaborator{}).
+				Where("report_id = ? AND user_id = ?", reportID, userID).
+				Update("reward_share", share).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// AddContributions records contributions and applies changes, keyed by
+// field name, to their report in one transaction
+func (r *collaboratorRepo) AddContributions(reportID string, contributions []models.ReportContribution, changes map[string]interface{}) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&contributions).Error; err != nil {
+			return err
+		}
+		if len(changes) == 0 {
+			return nil
+		}
+		return tx.Model(&models.IncidentReport{}).Where("id = ?", reportID).Updates(changes).Error
+	})
+}
+
+// Contributions returns a page of the contributions to a report, oldest
+// first
+func (r *collaboratorRepo) Contributions(reportID string, page int) ([]models.ReportContribution, error) {
+	var contributions []models.ReportContribution
+	err := r.DB.Where("report_id = ?", reportID).
+		Order("id").
+		Offset((page - 1) * DefaultPageSize).
+		Limit(DefaultPageSize).
+		Find(&contributions).Error
+	return contributions, err
+}
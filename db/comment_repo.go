@@ -0,0 +1,128 @@
+package db
+
+import (
+	"time"
+
+	"github.com/techagentng/citizenx/events"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+// CommentRepository persists the discussion threads under reports
+type CommentRepository interface {
+	CreateComment(comment *models.ReportComment, reportOwnerID uint) error
+	GetComment(reportID string, id uint) (*models.ReportComment, error)
+	ListComments(reportID string, page, pageSize int) ([]models.ReportComment, error)
+	UpdateComment(id uint, body string, editedAt int64) error
+	DeleteComment(id uint) error
+}
+
+type commentRepo struct {
+	DB *gorm.DB
+}
+
+func NewCommentRepo(db *GormDB) CommentRepository {
+	return &commentRepo{db.DB}
+}
+
+// CreateComment saves a comment and publishes CommentAdded, so the
+// reporter hears about it
+func (r *commentRepo) CreateComment(comment *models.ReportComment, reportOwnerID uint) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Username").Create(comment).Error; err != nil {
+			return err
+		}
+		return writeOutbox(tx, events.CommentAdded{
+			CommentID:     comment.ID,
+			ReportID:      comment.ReportID,
+			ReportOwnerID: reportOwnerID,
+			UserID:        comment.UserID,
+			OccurredAt:    time.Unix(comment.CreatedAt, 0),
+		})
+	})
+}
+
+// withUsernames selects comments together with their authors' usernames
+func (r *commentRepo) withUsernames() *gorm.DB {
+	return r.DB.Model(&models.ReportComment{}).
+		Select("report_comments.*, COALESCE(users.username, '') AS username").
+		Joins("LEFT JOIN users ON users.id = report_comments.user_id")
+}
+
+// GetComment returns a comment on the report, or gorm.ErrRecordNotFound
+func (r *commentRepo) GetComment(reportID string, id uint) (*models.ReportComment, error) {
+	var comment models.ReportComment
+	err := r.withUsernames().
+		Where("report_comments.report_id = ? AND report_comments.id = ?", reportID, id).
+		First(&comment).Error
+	if err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// ListComments returns a page of the threads under a report, oldest first,
+// each with all its replies
+func (r *commentRepo) ListComments(reportID string, page, pageSize int) ([]models.ReportComment, error) {
+	var threads []models.ReportComment
+	if err := r.withUsernames().
+		Where("report_comments.report_id = ? AND report_comments.parent_id IS NULL", reportID).
+		Order("report_comments.id").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&threads).Error; err != nil {
+		return nil, err
+	}
+	if len(threads) == 0 {
+		return threads, nil
+	}
+
+	ids := make([]uint, len(threads))
+	byID := make(map[uint]*models.ReportComment, len(threads))
+	for i := range threads {
+		ids[i] = threads[i].ID
+		byID[threads[i].ID] = &threads[i]
+	}
+	var replies []models.ReportComment
+	if err := r.withUsernames().
+		Where("report_comments.parent_id IN ?", ids).
+		Order("report_comments.id").
+		Find(&replies).Error; err != nil {
+		return nil, err
+	}
+	for _, reply := range replies {
+		thread := byID[*reply.ParentID]
+		thread.Replies = append(thread.Replies, reply)
+	}
+	return threads, nil
+}
+
+// UpdateComment replaces the body of a comment that has not been deleted,
+// returning gorm.ErrRecordNotFound when there is none
+func (r *commentRepo) UpdateComment(id uint, body string, editedAt int64) error {
+	result := r.DB.Model(&models.ReportComment{}).
+		Where("id = ? AND NOT deleted", id).
+		Updates(map[string]interface{}{"body": body, "edited_at": editedAt})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// DeleteComment removes a comment's body and marks it deleted, returning
+// gorm.ErrRecordNotFound when there is no such comment left to delete
+func (r *commentRepo) DeleteComment(id uint) error {
+	result := r.DB.Model(&models.ReportComment{}).
+		Where("id = ? AND NOT deleted", id).
+		Updates(map[string]interface{}{"body": "", "deleted": true})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package db
+
+import (
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+// DataShareRepository stores partner data-sharing agreements and reads the
+// reports they cover
+type DataShareRepository interface {
+	CreateShare(share *models.DataShare) error
+	ListShares() ([]models.DataShare, error)
+	FindShareByKeyHash(keyHash string) (*models.DataShare, error)
+	RevokeShare(id uint, at int64) error
+	TouchShare(id uint, at int64) error
+	ExpireShares(now int64) (int64, error)
+	SharedReports(share *models.DataShare, since, until int64, page, pageSize int) ([]models.IncidentReport, error)
+}
+
+type dataShareRepo struct {
+	DB *gorm.DB
+}
+
+func NewDataShareRepo(db *GormDB) DataShareRepository {
+	return &dataShareRepo{db.DB}
+}
+
+func (d *dataShareRepo) CreateShare(share *models.DataShare) error {
+	return d.DB.Create(share).Error
+}
+
+func (d *dataShareRepo) ListShares() ([]models.DataShare, error) {
+	var shares []models.DataShare
+	err := d.DB.Order("id DESC").Find(&shares).Error
+	return shares, err
+}
+
+func (d *dataShareRepo) FindShareByKeyHash(keyHash string) (*models.DataShare, error) {
+	var share models.DataShare
+	if err := d.DB.Where("key_hash = ?", keyHash).First(&share).Error; err != nil {
+		return nil, err
+	}
+	return &share, nil
+}
+
+// RevokeShare ends an agreement, returning gorm.ErrRecordNotFound when
+// there is no such agreement still in force
+func (d *dataShareRepo) RevokeShare(id uint, at int64) error {
+	result := d.DB.Model(&models.DataShare{}).Where("id = ? AND revoked_at IS NULL", id).Update("revoked_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+func (d *dataShareRepo) TouchShare(id uint, at int64) error {
+	return d.DB.Model(&models.DataShare{}).Where("id = ?", id).Update("last_used_at", at).Error
+}
+
+// ExpireShares revokes the agreements that have run out, returning how many
+func (d *dataShareRepo) ExpireShares(now int64) (int64, error) {
+	result := d.DB.Model(&models.DataShare{}).
+		Where("revoked_at IS NULL AND expires_at <= ?", now).
+		Update("revoked_at", gorm.Expr("expires_at"))
+	return result.RowsAffected, result.Error
+}
+
+// SharedReports returns a page of the published reports created between
+// since and until in the agreement's states and categories, oldest first
+func (d *dataShareRepo) SharedReports(share *models.DataShare, since, until int64, page, pageSize int) ([]models.IncidentReport, error) {
+	query := d.DB.Model(&models.IncidentReport{}).
+		Where("report_status IN ? AND created_at >= ? AND created_at < ?", models.PublishedReportStatuses, since, until)
+	if len(share.States) > 0 {
+		query = query.Where("LOWER(state_name) IN ?", share.States)
+	}
+	if len(share.Categories) > 0 {
+		query = query.Where("LOWER(category) IN ?", share.Categories)
+	}
+	var reports []models.IncidentReport
+	err := query.Order("created_at ASC, id ASC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&reports).Error
+	return reports, err
+}
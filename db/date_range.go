@@ -0,0 +1,37 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidDateRange is wrapped by every error that rejects a start_date
+// and end_date filter.
+var ErrInvalidDateRange = errors.New("invalid date range")
+
+// ParseDayRange reads the start_date and end_date filters, inclusive days
+// written YYYY-MM-DD, either of which may be empty. It returns the start of
+// the first day and the start of the day after the last, so reports match
+// when they fall at or after start and before end.
+func ParseDayRange(startDate, endDate string) (start, end *time.Time, err error) {
+	if startDate != "" {
+		day, err := time.Parse("2006-01-02", startDate)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: start_date must be a YYYY-MM-DD date", ErrInvalidDateRange)
+		}
+		start = &day
+	}
+	if endDate != "" {
+		day, err := time.Parse("2006-01-02", endDate)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: end_date must be a YYYY-MM-DD date", ErrInvalidDateRange)
+		}
+		day = day.AddDate(0, 0, 1)
+		end = &day
+	}
+	if start != nil && end != nil && !start.Before(*end) {
+		return nil, nil, fmt.Errorf("%w: end_date is before start_date", ErrInvalidDateRange)
+	}
+	return start, end, nil
+}
@@ -0,0 +1,60 @@
+package db_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/techagentng/citizenx/db"
+)
+
+func FuzzParseDayRange(f *testing.F) {
+	for _, seed := range [][2]string{
+		{"2024-01-01", "2024-01-31"},
+		{"2024-02-29", ""},
+		{"", "2023-02-29"},
+		{"2024-03-01", "2024-02-01"},
+		{"2024-1-1", "2024-01-01"},
+		{"0000-01-01", "9999-12-31"},
+		{"2024-01-01T00:00:00Z", ""},
+		{"2024-01-01 ", ""},
+	} {
+		f.Add(seed[0], seed[1])
+	}
+
+	f.Fuzz(func(t *testing.T, startDate, endDate string) {
+		start, end, err := db.ParseDayRange(startDate, endDate)
+		if err != nil {
+			if !errors.Is(err, db.ErrInvalidDateRange) {
+				t.Fatalf("ParseDayRange(%q, %q): %v does not wrap ErrInvalidDateRange", startDate, endDate, err)
+			}
+			if start != nil || end != nil {
+				t.Fatalf("ParseDayRange(%q, %q) returned bounds with error %v", startDate, endDate, err)
+			}
+			return
+		}
+
+		if (start == nil) != (startDate == "") || (end == nil) != (endDate == "") {
+			t.Fatalf("ParseDayRange(%q, %q) = %v, %v: bounds do not match the filters given", startDate, endDate, start, end)
+		}
+		if start != nil {
+			if !start.Equal(start.Truncate(24*time.Hour)) || start.Location() != time.UTC {
+				t.Fatalf("start %v is not the start of a day in UTC", start)
+			}
+			if got := start.Format("2006-01-02"); got != startDate {
+				t.Fatalf("start_date %q was read as %s", startDate, got)
+			}
+		}
+		if end != nil {
+			if !end.Equal(end.Truncate(24*time.Hour)) || end.Location() != time.UTC {
+				t.Fatalf("end %v is not the start of a day in UTC", end)
+			}
+			if got := end.AddDate(0, 0, -1).Format("2006-01-02"); got != endDate {
+				t.Fatalf("end_date %q was read as the day before %s", endDate, end)
+			}
+		}
+		if start != nil && end != nil && !start.Before(*end) {
+			t.Fatalf("ParseDayRange(%q, %q) accepted an empty range", startDate, endDate)
+		}
+	})
+}
@@ -25,7 +25,7 @@ func GetDB(c *config.Config) *GormDB {
 func (g *GormDB) Init(c *config.Config) {
 	g.DB = getPostgresDB(c)
 
-	if err := migrate(g.DB); err != nil {
+	if err := Migrate(g.DB); err != nil {
 		log.Fatalf("unable to run migrations: %v", err)
 	}
 }
@@ -65,21 +65,77 @@ func SeedRoles(db *gorm.DB) error {
 	return nil
 }
 
-func migrate(db *gorm.DB) error {
+// Migrate creates or updates the tables for every model the application uses.
+func Migrate(db *gorm.DB) error {
+	if err := removeDuplicateEngagements(db); err != nil {
+		return fmt.Errorf("migrations error: %v", err)
+	}
+	carryVotes := !db.Migrator().HasTable(&models.ReportVote{})
+	newCalendar := !db.Migrator().HasTable(&models.CalendarEvent{})
+	backfillUpdatedAt := db.Migrator().HasTable(&models.IncidentReport{}) && !db.Migrator().HasColumn(&models.IncidentReport{}, "UpdatedAt")
+
 	// AutoMigrate all the models
 	err := db.AutoMigrate(
 		&models.User{},
 		&models.Blacklist{},
 		&models.IncidentReport{},
+		&models.ReportDraft{},
+		&models.Upload{},
+		&models.UploadChunk{},
+		&models.StoredObject{},
 		&models.Media{},
+		&models.MediaReuseFlag{},
 		&models.Reward{},
 		&models.Like{},
 		&models.Notification{},
+		&models.NotificationPreference{},
+		&models.QuietHours{},
+		&models.PendingNotification{},
+		&models.Announcement{},
+		&models.ReengagementNudge{},
+		&models.AuditEntry{},
+		&models.AutoCloseRule{},
+		&models.SubmissionWindow{},
+		&models.Incident{},
+		&models.IncidentUpdate{},
+		&models.AggregateSnapshot{},
+		&models.WarehousePartition{},
+		&models.WarehousePseudonym{},
+		&models.ModerationDecision{},
+		&models.Agency{},
+		&models.AgencyScorecardSnapshot{},
+		&models.AmbassadorSummary{},
+		&models.DataShare{},
+		&models.ShortLink{},
+		&models.ShortLinkClick{},
+		&models.HelpArticle{},
+		&models.HelpRevision{},
+		&models.LegalDocument{},
+		&models.LegalAcceptance{},
+		&models.Feedback{},
+		&models.IdentityVerification{},
+		&models.PointDebit{}, &models.BankAccount{}, &models.PayoutBatch{}, &models.Payout{}, &models.AccountMerge{}, &models.ReportTransfer{}, &models.ReportStatusTransition{}, &models.ReportCapExemption{}, &models.ReportSubmission{}, &models.ReportCluster{}, &models.WebhookSubscription{}, &models.WebhookDelivery{}, &models.ReportComment{}, &models.ReportVote{}, &models.ReportRevision{}, &models.ReportingStreak{}, &models.CalendarEvent{}, &models.DigestSubscription{},
+		&models.ReporterReputation{},
+		&models.ReportAudit{},
+		&models.Landmark{},
+		&models.ReportLocation{},
+		&models.RoadSegment{},
+		&models.ReportRoadSegment{},
+		&models.ReportPoint{},
+		&models.InformationRequest{},
+		&models.ReportCollaborator{},
+		&models.ReportContribution{},
+		&models.DisplayNameAppeal{},
+		&models.PhoneOTP{},
+		&models.MagicLink{},
+		&models.Session{},
+		&models.SigningKey{},
 		&models.Comment{},
 		&models.ReportType{},
 		&models.IncidentReportUser{},
 		&models.LGA{},
 		&models.State{},
+		&models.Ward{},
 		&models.Bookmark{},
 		&models.StateReportPercentage{},
 		&models.MediaCount{},
@@ -91,10 +147,31 @@ func migrate(db *gorm.DB) error {
 		&models.UserPoints{},
 		&models.Role{},
 		&models.Post{},
+		&models.OutboxEvent{},
+		&models.Job{},
+		&models.ActivityEvent{},
 	)
 	if err != nil {
 		return fmt.Errorf("migrations error: %v", err)
 	}
+	if err := createReportIndexes(db); err != nil {
+		return fmt.Errorf("migrations error: %v", err)
+	}
+	if carryVotes {
+		if err := carryOverVotes(db); err != nil {
+			return fmt.Errorf("migrations error: %v", err)
+		}
+	}
+	if backfillUpdatedAt {
+		if err := backfillReportUpdatedAt(db); err != nil {
+			return fmt.Errorf("migrations error: %v", err)
+		}
+	}
+	if newCalendar {
+		if err := seedCalendar(db); err != nil {
+			return fmt.Errorf("seeding error: %v", err)
+		}
+	}
 
 	// Seed roles
 	// if err := seedRoles(db); err != nil {
@@ -0,0 +1,107 @@
+package db
+
+import (
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DigestRepository stores digest subscriptions and finds the reports each
+// digest sums up
+type DigestRepository interface {
+	ListSubscriptions(userID uint) ([]models.DigestSubscription, error)
+	CreateSubscription(sub *models.DigestSubscription) (bool, error)
+	DeleteSubscription(id, userID uint) error
+	DueSubscriptions(now int64, afterID uint, limit int) ([]models.DigestSubscription, error)
+	DigestReports(sub *models.DigestSubscription, until int64, limit int) ([]models.IncidentReport, int64, error)
+	MarkSent(id uint, coveredUntil, nextSendAt, sentAt int64) error
+}
+
+type digestRepo struct {
+	DB *gorm.DB
+}
+
+func NewDigestRepo(db *GormDB) DigestRepository {
+	return &digestRepo{db.DB}
+}
+
+func (r *digestRepo) ListSubscriptions(userID uint) ([]models.DigestSubscription, error) {
+	var subs []models.DigestSubscription
+	err := r.DB.Where("user_id = ?", userID).Order("id ASC").Find(&subs).Error
+	return subs, err
+}
+
+// CreateSubscription saves sub, reporting false when the user already has
+// the same subscription
+func (r *digestRepo) CreateSubscription(sub *models.DigestSubscription) (bool, error) {
+	result := r.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(sub)
+	return result.RowsAffected > 0, result.Error
+}
+
+// DeleteSubscription removes one of the user's subscriptions, returning
+// gorm.ErrRecordNotFound when they have no such subscription
+func (r *digestRepo) DeleteSubscription(id, userID uint) error {
+	result := r.DB.Where("id = ? AND user_id = ?", id, userID).Delete(&models.DigestSubscription{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// DueSubscriptions returns the next limit subscriptions after afterID whose
+// digest is due by now, of active users with an email address
+func (r *digestRepo) DueSubscriptions(now int64, afterID uint, limit int) ([]models.DigestSubscription, error) {
+	var subs []models.DigestSubscription
+	err := r.DB.Model(&models.DigestSubscription{}).
+		Joins("JOIN users ON users.id = digest_subscriptions.user_id").
+		Scopes(activeUsers).
+		Where("digest_subscriptions.id > ? AND digest_subscriptions.next_send_at <= ?", afterID, now).
+		Where("COALESCE(users.email, '') <> ''").
+		Order("digest_subscriptions.id ASC").
+		Limit(limit).
+		Find(&subs).Error
+	return subs, err
+}
+
+// DigestReports returns the newest published reports matching sub whose
+// status changed since it was last covered and before until, up to limit,
+// and how many there are in all. Reports wait for moderation before they
+// go out, so they are taken by when they were published rather than made.
+func (r *digestRepo) DigestReports(sub *models.DigestSubscription, until int64, limit int) ([]models.IncidentReport, int64, error) {
+	matching := func() *gorm.DB {
+		query := r.DB.Model(&models.IncidentReport{}).
+			Where("status_updated_at >= ? AND status_updated_at < ?", sub.CoveredUntil, until).
+			Where("LOWER(report_status) IN ?", models.PublishedReportStatuses)
+		for _, filter := range [][2]string{
+			{"state_name", sub.StateName},
+			{"lga_name", sub.LGAName},
+			{"category", sub.Category},
+		} {
+			if filter[1] != "" {
+				query = query.Where(filter[0]+" ILIKE ?", filter[1])
+			}
+		}
+		return query
+	}
+
+	var total int64
+	if err := matching().Count(&total).Error; err != nil || total == 0 {
+		return nil, total, err
+	}
+	var reports []models.IncidentReport
+	err := matching().Order("status_updated_at DESC").Limit(limit).Find(&reports).Error
+	return reports, total, err
+}
+
+func (r *digestRepo) MarkSent(id uint, coveredUntil, nextSendAt, sentAt int64) error {
+	return r.DB.Model(&models.DigestSubscription{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"covered_until": coveredUntil,
+			"next_send_at":  nextSendAt,
+			"last_sent_at":  sentAt,
+		}).Error
+}
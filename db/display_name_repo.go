@@ -0,0 +1,104 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+// DisplayNameRepository persists appeals against the display-name policy
+type DisplayNameRepository interface {
+	CreateAppeal(appeal *models.DisplayNameAppeal) error
+	GetAppeal(id uint) (*models.DisplayNameAppeal, error)
+	HasPendingAppeal(userID uint, field string) (bool, error)
+	ListAppeals(status string, page int) ([]models.DisplayNameAppeal, error)
+	UserAppeals(userID uint) ([]models.DisplayNameAppeal, error)
+	ReviewAppeal(appeal *models.DisplayNameAppeal) (bool, error)
+}
+
+type displayNameRepo struct {
+	DB *gorm.DB
+}
+
+func NewDisplayNameRepo(db *GormDB) DisplayNameRepository {
+	return &displayNameRepo{db.DB}
+}
+
+func (r *displayNameRepo) CreateAppeal(appeal *models.DisplayNameAppeal) error {
+	return r.DB.Create(appeal).Error
+}
+
+func (r *displayNameRepo) GetAppeal(id uint) (*models.DisplayNameAppeal, error) {
+	var appeal models.DisplayNameAppeal
+	if err := r.DB.First(&appeal, id).Error; err != nil {
+		return nil, err
+	}
+	return &appeal, nil
+}
+
+func (r *displayNameRepo) HasPendingAppeal(userID uint, field string) (bool, error) {
+	var count int64
+	err := r.DB.Model(&models.DisplayNameAppeal{}).
+		Where("user_id = ? AND field = ? AND status = ?", userID, field, models.AppealPending).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ListAppeals returns a page of appeals, oldest first so the queue is
+// worked in order, optionally only those with the given status
+func (r *displayNameRepo) ListAppeals(status string, page int) ([]models.DisplayNameAppeal, error) {
+	var appeals []models.DisplayNameAppeal
+	db := r.DB
+	if status != "" {
+		db = db.Where("status = ?", status)
+	}
+	err := db.Order("id").
+		Offset((page - 1) * DefaultPageSize).
+		Limit(DefaultPageSize).
+		Find(&appeals).Error
+	return appeals, err
+}
+
+func (r *displayNameRepo) UserAppeals(userID uint) ([]models.DisplayNameAppeal, error) {
+	var appeals []models.DisplayNameAppeal
+	err := r.DB.Where("user_id = ?", userID).Order("id DESC").Limit(DefaultPageSize).Find(&appeals).Error
+	return appeals, err
+}
+
+// ReviewAppeal records the decision on a pending appeal and, when it is
+// approved, sets the appealed name on the user, with an audit entry for the
+// override. It reports false when the appeal was no longer pending.
+func (r *displayNameRepo) ReviewAppeal(appeal *models.DisplayNameAppeal) (bool, error) {
+	reviewed := false
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.DisplayNameAppeal{}).
+			Where("id = ? AND status = ?", appeal.ID, models.AppealPending).
+			Updates(map[string]interface{}{
+				"status":      appeal.Status,
+				"reviewed_by": appeal.ReviewedBy,
+				"review_note": appeal.ReviewNote,
+				"reviewed_at": appeal.ReviewedAt,
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		reviewed = true
+		if appeal.Status != models.AppealApproved {
+			return nil
+		}
+
+		if err := tx.Model(&models.User{}).
+			Where("id = ?", appeal.UserID).
+			Update(appeal.Field, appeal.Name).Error; err != nil {
+			return err
+		}
+		return writeAudit(tx, appeal.ReviewedBy, models.AuditDisplayNameOverride, "user", fmt.Sprint(appeal.UserID), map[string]interface{}{
+			"appeal_id": appeal.ID,
+			"field":     appeal.Field,
+			"name":      appeal.Name,
+			"rule":      appeal.Rule,
+		})
+	})
+	return reviewed, err
+}
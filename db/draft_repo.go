@@ -0,0 +1,57 @@
+package db
+
+import (
+	"time"
+
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+// ReportDraftRepository stores reports that are still being assembled
+type ReportDraftRepository interface {
+	CreateDraft(draft *models.ReportDraft) error
+	GetDraft(draftID string, userID uint) (*models.ReportDraft, error)
+	PurgeDrafts(before time.Time) (int64, error)
+}
+
+type reportDraftRepo struct {
+	DB *gorm.DB
+}
+
+func NewReportDraftRepo(db *GormDB) ReportDraftRepository {
+	return &reportDraftRepo{db.DB}
+}
+
+func (r *reportDraftRepo) CreateDraft(draft *models.ReportDraft) error {
+	return r.DB.Create(draft).Error
+}
+
+// GetDraft returns the user's draft, or gorm.ErrRecordNotFound when the
+// draft does not exist or belongs to someone else
+func (r *reportDraftRepo) GetDraft(draftID string, userID uint) (*models.ReportDraft, error) {
+	var draft models.ReportDraft
+	if err := r.DB.Where("id = ? AND user_id = ?", draftID, userID).First(&draft).Error; err != nil {
+		return nil, err
+	}
+	return &draft, nil
+}
+
+// PurgeDrafts removes drafts created before the cutoff together with the
+// media uploaded to them, and returns the number of drafts removed. Media of
+// a draft that was finalized belongs to the report and is left alone.
+func (r *reportDraftRepo) PurgeDrafts(before time.Time) (int64, error) {
+	var purged int64
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		expired := tx.Model(&models.ReportDraft{}).Select("id::text").Where("created_at < ?", before.Unix())
+		if err := tx.Where("incident_report_id IN (?)", expired).
+			Where("incident_report_id NOT IN (?)", tx.Model(&models.IncidentReport{}).Select("id::text")).
+			Delete(&models.Media{}).Error; err != nil {
+			return err
+		}
+
+		result := tx.Where("created_at < ?", before.Unix()).Delete(&models.ReportDraft{})
+		purged = result.RowsAffected
+		return result.Error
+	})
+	return purged, err
+}
@@ -0,0 +1,79 @@
+package db
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+// uniqueEngagements lists the records of a user engaging with a report
+// that may be kept once, the unique index that enforces it and the columns
+// it covers
+var uniqueEngagements = []struct {
+	model   interface{}
+	index   string
+	columns []string
+}{
+	{&models.Bookmark{}, "idx_bookmarks_user_report", []string{"user_id", "report_id"}},
+	{&models.IncidentReportUser{}, "idx_incident_report_users_user_report", []string{"user_id", "incident_report_id"}},
+	{&models.Votes{}, "idx_votes_user_report_type", []string{"user_id", "report_id", "vote_type"}},
+}
+
+// removeDuplicateEngagements deletes the repeats recorded before the unique
+// indexes existed, keeping the earliest of each, so the indexes can be
+// built. Tables already indexed are left alone.
+func removeDuplicateEngagements(db *gorm.DB) error {
+	for _, e := range uniqueEngagements {
+		if !db.Migrator().HasTable(e.model) || db.Migrator().HasIndex(e.model, e.index) {
+			continue
+		}
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(e.model); err != nil {
+			return err
+		}
+		table := stmt.Schema.Table
+		same := make([]string, len(e.columns))
+		for i, column := range e.columns {
+			same[i] = fmt.Sprintf("a.%s = b.%s", column, column)
+		}
+		result := db.Exec("DELETE FROM " + table + " a USING " + table + " b WHERE a.id > b.id AND " + strings.Join(same, " AND "))
+		if result.Error != nil {
+			return fmt.Errorf("removing duplicate %s: %w", table, result.Error)
+		}
+		if result.RowsAffected > 0 {
+			log.Printf("removed %d duplicate rows from %s", result.RowsAffected, table)
+		}
+	}
+	return nil
+}
+
+// carryOverVotes copies the votes recorded before report_votes existed into
+// it. A user who both upvoted and downvoted a report keeps the later vote,
+// and votes on reports since deleted are dropped.
+func carryOverVotes(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&models.Votes{}) {
+		return nil
+	}
+	result := db.Exec(`
+        INSERT INTO report_votes (report_id, user_id, value, created_at, updated_at)
+        SELECT DISTINCT ON (votes.user_id, votes.report_id)
+            incident_reports.id, votes.user_id,
+            CASE WHEN votes.vote_type = 'upvote' THEN 1 ELSE -1 END,
+            votes.created_at, votes.created_at
+        FROM votes
+        JOIN incident_reports ON incident_reports.id::text = votes.report_id
+        WHERE votes.user_id > 0
+        ORDER BY votes.user_id, votes.report_id, votes.created_at DESC, votes.id DESC
+        ON CONFLICT (report_id, user_id) DO NOTHING
+    `)
+	if result.Error != nil {
+		return fmt.Errorf("carrying over votes: %w", result.Error)
+	}
+	if result.RowsAffected > 0 {
+		log.Printf("carried %d votes over to report_votes", result.RowsAffected)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package db
+
+import (
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+// FeedbackRepository stores app feedback and its triage
+type FeedbackRepository interface {
+	CreateFeedback(feedback *models.Feedback) error
+	GetFeedback(id uint) (*models.Feedback, error)
+	ListFeedback(status, category string, page int) ([]models.Feedback, error)
+	SetScreenshot(id uint, key string) error
+	TriageFeedback(id uint, status, note string, adminID uint, at int64) error
+	UnforwardedFeedback(maxAttempts, limit int) ([]models.Feedback, error)
+	MarkForwarded(id uint, at int64) error
+	RecordForwardFailure(id uint, reason string) error
+}
+
+type feedbackRepo struct {
+	DB *gorm.DB
+}
+
+func NewFeedbackRepo(db *GormDB) FeedbackRepository {
+	return &feedbackRepo{db.DB}
+}
+
+func (f *feedbackRepo) CreateFeedback(feedback *models.Feedback) error {
+	return f.DB.Create(feedback).Error
+}
+
+func (f *feedbackRepo) GetFeedback(id uint) (*models.Feedback, error) {
+	var feedback models.Feedback
+	if err := f.DB.First(&feedback, id).Error; err != nil {
+		return nil, err
+	}
+	return &feedback, nil
+}
+
+// ListFeedback returns the newest feedback first, optionally only with a
+// status or in a category
+func (f *feedbackRepo) ListFeedback(status, category string, page int) ([]models.Feedback, error) {
+	query := f.DB.Order("created_at DESC, id DESC")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if category != "" {
+		query = query.Where("category = ?", category)
+	}
+	var feedback []models.Feedback
+	err := query.Offset((page - 1) * DefaultPageSize).
+		Limit(DefaultPageSize).
+		Find(&feedback).Error
+	return feedback, err
+}
+
+func (f *feedbackRepo) SetScreenshot(id uint, key string) error {
+	return f.DB.Model(&models.Feedback{}).Where("id = ?", id).Update("screenshot_key", key).Error
+}
+
+// TriageFeedback sets the status of feedback, returning
+// gorm.ErrRecordNotFound when there is no such feedback
+func (f *feedbackRepo) TriageFeedback(id uint, status, note string, adminID uint, at int64) error {
+	result := f.DB.Model(&models.Feedback{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":      status,
+		"triage_note": note,
+		"triaged_by":  adminID,
+		"updated_at":  at,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// UnforwardedFeedback returns the oldest feedback not yet sent to the issue
+// tracker that has failed fewer than maxAttempts times
+func (f *feedbackRepo) UnforwardedFeedback(maxAttempts, limit int) ([]models.Feedback, error) {
+	var feedback []models.Feedback
+	err := f.DB.Where("forwarded_at = 0 AND forward_attempts < ?", maxAttempts).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&feedback).Error
+	return feedback, err
+}
+
+func (f *feedbackRepo) MarkForwarded(id uint, at int64) error {
+	return f.DB.Model(&models.Feedback{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"forwarded_at":  at,
+		"forward_error": "",
+	}).Error
+}
+
+func (f *feedbackRepo) RecordForwardFailure(id uint, reason string) error {
+	return f.DB.Model(&models.Feedback{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"forward_attempts": gorm.Expr("forward_attempts + 1"),
+		"forward_error":    reason,
+	}).Error
+}
@@ -0,0 +1,208 @@
+package db
+
+import (
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TileCluster is a group of reports sharing a grid cell of a map tile, with
+// its position in tile units. ReportID and Category are set only when the
+// cluster holds a single report.
+type TileCluster struct {
+	X        float64
+	Y        float64
+	Count    int64
+	ReportID *string
+	Category *string
+}
+
+// TileQuery selects the reports inside one Web Mercator tile.
+type TileQuery struct {
+	Z, X, Y                  int
+	West, South, East, North float64
+	Extent                   int
+	// CellSize is the edge of the clustering grid in tile units
+	CellSize int
+	Category string
+}
+
+// HeatmapCell is the number of reports in one grid cell, identified by its
+// column and row counted from longitude -180 and latitude -90.
+type HeatmapCell struct {
+	LngIdx int64
+	LatIdx int64
+	Count  int64
+}
+
+// MarkerCluster is a group of reports sharing a grid cell, with their
+// centroid. ReportID and Category are set only when it holds one report.
+type MarkerCluster struct {
+	LngIdx   int64
+	LatIdx   int64
+	Lat      float64
+	Lng      float64
+	Count    int64
+	ReportID *string
+	Category *string
+}
+
+// HeatmapQuery selects the reports counted by a heatmap, or clustered into
+// map markers.
+type HeatmapQuery struct {
+	West, South, East, North float64
+	// CellWidth and CellHeight are the grid cell size in degrees
+	CellWidth  float64
+	CellHeight float64
+	Category   string
+	Start, End *time.Time
+}
+
+// FeatureQuery selects the reports exported as map features.
+type FeatureQuery struct {
+	West, South, East, North float64
+	Category                 string
+	Start, End               *time.Time
+	Limit                    int
+}
+
+// GeoRepository holds the spatial aggregations behind the map endpoints.
+type GeoRepository interface {
+	GetTileClusters(q TileQuery) ([]TileCluster, error)
+	GetHeatmapCells(q HeatmapQuery) ([]HeatmapCell, error)
+	GetMarkerClusters(q HeatmapQuery) ([]MarkerCluster, error)
+	GetReportFeatures(q FeatureQuery) ([]models.IncidentReport, error)
+	SetReportWard(reportID uuid.UUID, ward *models.Ward) error
+}
+
+type geoRepo struct {
+	DB *gorm.DB
+}
+
+func NewGeoRepo(db *GormDB) GeoRepository {
+	return &geoRepo{db.DB}
+}
+
+// GetTileClusters projects the reports inside the tile to tile units and
+// groups them by grid cell, so a tile never carries more points than it has
+// cells however many reports it covers.
+func (g *geoRepo) GetTileClusters(q TileQuery) ([]TileCluster, error) {
+	categoryFilter := ""
+	if q.Category != "" {
+		categoryFilter = "AND category = @category"
+	}
+
+	var clusters []TileCluster
+	err := g.DB.Raw(`
+        SELECT AVG(px) AS x, AVG(py) AS y, COUNT(*) AS count,
+            CASE WHEN COUNT(*) = 1 THEN MIN(id::text) END AS report_id,
+            CASE WHEN COUNT(*) = 1 THEN MIN(category) END AS category
+        FROM (
+            SELECT id, category,
+                ((longitude + 180) / 360 * @n - @x) * @extent AS px,
+                ((1 - ln(tan(radians(latitude)) + 1 / cos(radians(latitude))) / pi()) / 2 * @n - @y) * @extent AS py
+            FROM incident_reports
+            WHERE longitude >= @west AND longitude < @east
+                AND latitude > @south AND latitude <= @north
+                `+categoryFilter+`
+        ) AS projected
+        GROUP BY floor(px / @cell), floor(py / @cell)
+    `, map[string]interface{}{
+		"n":        math.Exp2(float64(q.Z)),
+		"x":        q.X,
+		"y":        q.Y,
+		"extent":   q.Extent,
+		"west":     q.West,
+		"east":     q.East,
+		"south":    q.South,
+		"north":    q.North,
+		"cell":     q.CellSize,
+		"category": q.Category,
+	}).Scan(&clusters).Error
+	return clusters, err
+}
+
+// GetHeatmapCells counts the reports inside the bounding box per grid cell.
+func (g *geoRepo) GetHeatmapCells(q HeatmapQuery) ([]HeatmapCell, error) {
+	query := g.gridQuery(q).
+		Select(`FLOOR((longitude + 180) / ?)::bigint AS lng_idx, FLOOR((latitude + 90) / ?)::bigint AS lat_idx, COUNT(*) AS count`,
+			q.CellWidth, q.CellHeight)
+
+	var cells []HeatmapCell
+	err := query.Group("lng_idx, lat_idx").Scan(&cells).Error
+	return cells, err
+}
+
+// GetMarkerClusters groups the reports inside the bounding box by grid
+// cell, placing each group at the mean position of its reports rather than
+// the cell's centre so markers sit where the reports are.
+func (g *geoRepo) GetMarkerClusters(q HeatmapQuery) ([]MarkerCluster, error) {
+	query := g.gridQuery(q).
+		Select(`FLOOR((longitude + 180) / ?)::bigint AS lng_idx, FLOOR((latitude + 90) / ?)::bigint AS lat_idx,
+            AVG(latitude) AS lat, AVG(longitude) AS lng, COUNT(*) AS count,
+            CASE WHEN COUNT(*) = 1 THEN MIN(id::text) END AS report_id,
+            CASE WHEN COUNT(*) = 1 THEN MIN(category) END AS category`,
+			q.CellWidth, q.CellHeight)
+
+	var clusters []MarkerCluster
+	err := query.Group("lng_idx, lat_idx").Scan(&clusters).Error
+	return clusters, err
+}
+
+// gridQuery selects the reports a heatmap or marker query covers
+func (g *geoRepo) gridQuery(q HeatmapQuery) *gorm.DB {
+	query := g.DB.Table("incident_reports").
+		Where("longitude BETWEEN ? AND ? AND latitude BETWEEN ? AND ?", q.West, q.East, q.South, q.North)
+	if q.Category != "" {
+		query = query.Where("category = ?", q.Category)
+	}
+	if q.Start != nil {
+		query = query.Where("timeof_incidence >= ?", *q.Start)
+	}
+	if q.End != nil {
+		query = query.Where("timeof_incidence < ?", *q.End)
+	}
+	return query
+}
+
+// GetReportFeatures returns the reports inside the bounding box with their
+// points, newest first.
+func (g *geoRepo) GetReportFeatures(q FeatureQuery) ([]models.IncidentReport, error) {
+	query := g.DB.Where("longitude BETWEEN ? AND ? AND latitude BETWEEN ? AND ?", q.West, q.East, q.South, q.North)
+	if q.Category != "" {
+		query = query.Where("category = ?", q.Category)
+	}
+	if q.Start != nil {
+		query = query.Where("timeof_incidence >= ?", *q.Start)
+	}
+	if q.End != nil {
+		query = query.Where("timeof_incidence < ?", *q.End)
+	}
+
+	return findReports(query.Order("created_at DESC").Limit(q.Limit), WithPoints())
+}
+
+// SetReportWard records the ward a report falls in, adding the ward to the
+// geo model if it is new.
+func (g *geoRepo) SetReportWard(reportID uuid.UUID, ward *models.Ward) error {
+	return g.DB.Transaction(func(tx *gorm.DB) error {
+		if ward.ID == uuid.Nil {
+			ward.ID = uuid.New()
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(ward).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.IncidentReport{}).
+			Where("id = ?", reportID).
+			Update("ward_name", ward.Name).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.ReportType{}).
+			Where("incident_report_id = ?", reportID).
+			Update("ward_name", ward.Name).Error
+	})
+}
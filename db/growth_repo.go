@@ -0,0 +1,190 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+// activitySource lists every user action as (user_id, occurred_at), the
+// input to the active user and retention figures. Activity events cover
+// logins and views; the other tables keep the figures complete for the time
+// before activity was tracked.
+const activitySource = `
+    SELECT user_id, occurred_at FROM activity_events
+    UNION ALL SELECT user_id, to_timestamp(created_at) FROM incident_reports WHERE user_id > 0
+    UNION ALL SELECT user_id, to_timestamp(updated_at) FROM report_votes
+    UNION ALL SELECT user_id, to_timestamp(created_at) FROM comments
+    UNION ALL SELECT user_id, created_at FROM bookmarks
+`
+
+// userStateSQL resolves the state a user belongs to: the state of their LGA
+// where it is known, else the state of their first report.
+const userStateSQL = `COALESCE(
+        (SELECT MIN(states.name) FROM lgas JOIN states ON states.id = lgas.state_id WHERE lgas.name = users.lga_name),
+        (SELECT state_name FROM incident_reports WHERE incident_reports.user_id = users.id AND state_name <> '' ORDER BY created_at LIMIT 1),
+        'unknown')`
+
+// periodOffsetSQL returns the number of whole intervals between two
+// truncated timestamps.
+var periodOffsetSQL = map[string]string{
+	"day":   "EXTRACT(DAY FROM (%[1]s - %[2]s))",
+	"week":  "EXTRACT(DAY FROM (%[1]s - %[2]s)) / 7",
+	"month": "EXTRACT(YEAR FROM AGE(%[1]s, %[2]s)) * 12 + EXTRACT(MONTH FROM AGE(%[1]s, %[2]s))",
+}
+
+// GrowthRepository holds the user growth and retention aggregations.
+type GrowthRepository interface {
+	SignupsByPeriod(interval string, start, end time.Time) ([]models.PeriodCount, error)
+	SignupsByState(start, end time.Time) ([]models.GroupCount, error)
+	ActiveUsers(asOf time.Time) (*models.ActiveUsers, error)
+	DailyActiveUsers(start, end time.Time) ([]models.PeriodCount, error)
+	CohortRetention(interval string, since time.Time, periods int) ([]models.RetentionCohort, error)
+}
+
+type growthRepo struct {
+	DB *gorm.DB
+}
+
+func NewGrowthRepo(db *GormDB) GrowthRepository {
+	return &growthRepo{db.DB}
+}
+
+// SignupsByPeriod counts new users per interval between start and end.
+func (g *growthRepo) SignupsByPeriod(interval string, start, end time.Time) ([]models.PeriodCount, error) {
+	if !timeseriesIntervals[interval] {
+		return nil, ErrUnknownInterval
+	}
+	var counts []models.PeriodCount
+	err := g.DB.Model(&models.User{}).
+		Select("DATE_TRUNC(?, to_timestamp(created_at)) AS period, COUNT(*) AS count", interval).
+		Where("created_at >= ? AND created_at < ?", start.Unix(), end.Unix()).
+		Group("period").
+		Order("period").
+		Scan(&counts).Error
+	return counts, err
+}
+
+// SignupsByState counts new users per state between start and end.
+func (g *growthRepo) SignupsByState(start, end time.Time) ([]models.GroupCount, error) {
+	var counts []models.GroupCount
+	err := g.DB.Raw(`
+        SELECT `+userStateSQL+` AS "group", COUNT(*) AS count
+        FROM users
+        WHERE created_at >= ? AND created_at < ?
+        GROUP BY 1
+        ORDER BY count DESC, "group"
+    `, start.Unix(), end.Unix()).Scan(&counts).Error
+	return counts, err
+}
+
+// ActiveUsers counts the distinct users active in the day, 7 days and 30
+// days ending at asOf.
+func (g *growthRepo) ActiveUsers(asOf time.Time) (*models.ActiveUsers, error) {
+	active := &models.ActiveUsers{AsOf: asOf}
+	err := g.DB.Raw(`
+        SELECT
+            COUNT(DISTINCT user_id) FILTER (WHERE occurred_at >= @day) AS dau,
+            COUNT(DISTINCT user_id) FILTER (WHERE occurred_at >= @week) AS wau,
+            COUNT(DISTINCT user_id) AS mau
+        FROM (`+activitySource+`) AS activity
+        WHERE occurred_at >= @month AND occurred_at < @as_of
+    `, map[string]interface{}{
+		"day":   asOf.AddDate(0, 0, -1),
+		"week":  asOf.AddDate(0, 0, -7),
+		"month": asOf.AddDate(0, 0, -30),
+		"as_of": asOf,
+	}).Row().Scan(&active.DAU, &active.WAU, &active.MAU)
+	if err != nil {
+		return nil, err
+	}
+	if active.MAU > 0 {
+		active.Stickiness = float64(active.DAU) / float64(active.MAU)
+	}
+	return active, nil
+}
+
+// DailyActiveUsers counts the distinct users active on each day between
+// start and end.
+func (g *growthRepo) DailyActiveUsers(start, end time.Time) ([]models.PeriodCount, error) {
+	var counts []models.PeriodCount
+	err := g.DB.Raw(`
+        SELECT DATE_TRUNC('day', occurred_at) AS period, COUNT(DISTINCT user_id) AS count
+        FROM (`+activitySource+`) AS activity
+        WHERE occurred_at >= ? AND occurred_at < ?
+        GROUP BY period
+        ORDER BY period
+    `, start, end).Scan(&counts).Error
+	return counts, err
+}
+
+// CohortRetention groups users by the interval they signed up in, from
+// since, and counts how many of each cohort were active in each of the
+// following periods. Retained[0] is the signup period itself.
+func (g *growthRepo) CohortRetention(interval string, since time.Time, periods int) ([]models.RetentionCohort, error) {
+	offsetFormat, ok := periodOffsetSQL[interval]
+	if !ok {
+		return nil, ErrUnknownInterval
+	}
+	offset := "FLOOR(" + fmt.Sprintf(offsetFormat, "DATE_TRUNC(@interval, activity.occurred_at)", "cohorts.cohort") + ")::int"
+
+	var sizes []struct {
+		Cohort time.Time
+		Size   int64
+	}
+	if err := g.DB.Raw(`
+        SELECT DATE_TRUNC(?, to_timestamp(created_at)) AS cohort, COUNT(*) AS size
+        FROM users
+        WHERE created_at >= ?
+        GROUP BY cohort
+        ORDER BY cohort
+    `, interval, since.Unix()).Scan(&sizes).Error; err != nil {
+		return nil, err
+	}
+
+	var cells []struct {
+		Cohort time.Time
+		Offset int
+		Users  int64
+	}
+	if err := g.DB.Raw(`
+        WITH cohorts AS (
+            SELECT id AS user_id, DATE_TRUNC(@interval, to_timestamp(created_at)) AS cohort
+            FROM users
+            WHERE created_at >= @since
+        )
+        SELECT cohorts.cohort, `+offset+` AS "offset", COUNT(DISTINCT cohorts.user_id) AS users
+        FROM cohorts
+        JOIN (`+activitySource+`) AS activity ON activity.user_id = cohorts.user_id
+        WHERE activity.occurred_at >= cohorts.cohort
+        GROUP BY 1, 2
+    `, map[string]interface{}{
+		"interval": interval,
+		"since":    since.Unix(),
+	}).Scan(&cells).Error; err != nil {
+		return nil, err
+	}
+
+	cohorts := make([]models.RetentionCohort, len(sizes))
+	index := make(map[time.Time]int, len(sizes))
+	for i, s := range sizes {
+		cohorts[i] = models.RetentionCohort{
+			Cohort:   s.Cohort,
+			Size:     s.Size,
+			Retained: make([]int64, periods),
+			Rates:    make([]float64, periods),
+		}
+		index[s.Cohort.UTC()] = i
+	}
+	for _, cell := range cells {
+		i, ok := index[cell.Cohort.UTC()]
+		if !ok || cell.Offset < 0 || cell.Offset >= periods {
+			continue
+		}
+		cohorts[i].Retained[cell.Offset] = cell.Users
+		cohorts[i].Rates[cell.Offset] = float64(cell.Users) / float64(cohorts[i].Size)
+	}
+	return cohorts, nil
+}
@@ -0,0 +1,138 @@
+package db
+
+import (
+	"strings"
+
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+// HelpRepository stores help articles and their revisions
+type HelpRepository interface {
+	ListArticles() ([]models.HelpArticle, error)
+	GetArticle(id uint) (*models.HelpArticle, error)
+	CreateArticle(article *models.HelpArticle) error
+	UpdateArticle(article *models.HelpArticle) error
+	DeleteArticle(id uint) error
+	Revisions(articleID uint) ([]models.HelpRevision, error)
+	CreateRevision(revision *models.HelpRevision) error
+	PublishRevision(articleID, revisionID uint, at int64) error
+	PublishedContent(locales []string, kind, category string) ([]models.HelpContent, error)
+}
+
+type helpRepo struct {
+	DB *gorm.DB
+}
+
+func NewHelpRepo(db *GormDB) HelpRepository {
+	return &helpRepo{db.DB}
+}
+
+func (h *helpRepo) ListArticles() ([]models.HelpArticle, error) {
+	var articles []models.HelpArticle
+	err := h.DB.Order("kind, position, id").Find(&articles).Error
+	return articles, err
+}
+
+func (h *helpRepo) GetArticle(id uint) (*models.HelpArticle, error) {
+	var article models.HelpArticle
+	if err := h.DB.First(&article, id).Error; err != nil {
+		return nil, err
+	}
+	return &article, nil
+}
+
+func (h *helpRepo) CreateArticle(article *models.HelpArticle) error {
+	return h.DB.Create(article).Error
+}
+
+func (h *helpRepo) UpdateArticle(article *models.HelpArticle) error {
+	return h.DB.Model(article).Select("slug", "kind", "category", "position", "updated_at").Updates(article).Error
+}
+
+// DeleteArticle removes an article with its revisions, returning
+// gorm.ErrRecordNotFound when there is no such article
+func (h *helpRepo) DeleteArticle(id uint) error {
+	return h.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("article_id = ?", id).Delete(&models.HelpRevision{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&models.HelpArticle{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// Revisions returns an article's revisions, newest first in each locale
+func (h *helpRepo) Revisions(articleID uint) ([]models.HelpRevision, error) {
+	var revisions []models.HelpRevision
+	err := h.DB.Where("article_id = ?", articleID).Order("locale, version DESC").Find(&revisions).Error
+	return revisions, err
+}
+
+// CreateRevision stores a revision as the next version in its locale
+func (h *helpRepo) CreateRevision(revision *models.HelpRevision) error {
+	return h.DB.Transaction(func(tx *gorm.DB) error {
+		// Locking the article keeps two editors from taking one version
+		if err := tx.Exec("SELECT id FROM help_articles WHERE id = ? FOR UPDATE", revision.ArticleID).Error; err != nil {
+			return err
+		}
+		var latest int
+		if err := tx.Model(&models.HelpRevision{}).
+			Where("article_id = ? AND locale = ?", revision.ArticleID, revision.Locale).
+			Select("COALESCE(MAX(version), 0)").Scan(&latest).Error; err != nil {
+			return err
+		}
+		revision.Version = latest + 1
+		return tx.Create(revision).Error
+	})
+}
+
+// PublishRevision makes a revision of an article the published one,
+// returning gorm.ErrRecordNotFound when the article has no such revision
+func (h *helpRepo) PublishRevision(articleID, revisionID uint, at int64) error {
+	result := h.DB.Model(&models.HelpRevision{}).
+		Where("id = ? AND article_id = ?", revisionID, articleID).
+		Update("published_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// PublishedContent returns every article's most recently published
+// revision in the first of locales it has one in, optionally only of one
+// kind or category. Publishing an older revision again rolls back to it.
+func (h *helpRepo) PublishedContent(locales []string, kind, category string) ([]models.HelpContent, error) {
+	query := h.DB.Table("help_articles a").
+		Select(`DISTINCT ON (a.position, a.id) a.slug, a.kind, a.category, a.position,
+			r.locale, r.version, r.title, r.body, r.published_at`).
+		Joins("JOIN help_revisions r ON r.article_id = a.id AND r.published_at IS NOT NULL AND r.locale IN ?", locales)
+	if kind != "" {
+		query = query.Where("a.kind = ?", kind)
+	}
+	if category != "" {
+		query = query.Where("LOWER(a.category) = LOWER(?)", category)
+	}
+	content := []models.HelpContent{}
+	err := query.
+		Order("a.position, a.id").
+		Order(gorm.Expr("array_position(?::text[], r.locale::text)", localeArray(locales))).
+		Order("r.published_at DESC").
+		Scan(&content).Error
+	return content, err
+}
+
+// localeArray writes locales, which are supported locale tags, as a
+// Postgres array literal
+func localeArray(locales []string) string {
+	return "{" + strings.Join(locales, ",") + "}"
+}
@@ -0,0 +1,83 @@
+package db
+
+import (
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IdentityRepository stores the outcome of users' identity checks
+type IdentityRepository interface {
+	GetVerification(userID uint) (*models.IdentityVerification, error)
+	ReferenceInUse(referenceHash string, userID uint) (bool, error)
+	SaveVerification(verification *models.IdentityVerification) error
+	RevokeVerification(userID uint, at int64) error
+}
+
+type identityRepo struct {
+	DB *gorm.DB
+}
+
+func NewIdentityRepo(db *GormDB) IdentityRepository {
+	return &identityRepo{db.DB}
+}
+
+func (i *identityRepo) GetVerification(userID uint) (*models.IdentityVerification, error) {
+	var verification models.IdentityVerification
+	if err := i.DB.First(&verification, "user_id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+	return &verification, nil
+}
+
+// ReferenceInUse reports whether another user is verified with the number
+// hashed as referenceHash
+func (i *identityRepo) ReferenceInUse(referenceHash string, userID uint) (bool, error) {
+	var count int64
+	err := i.DB.Model(&models.IdentityVerification{}).
+		Where("reference_hash = ? AND user_id <> ?", referenceHash, userID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// SaveVerification stores the user's latest check and, in the same
+// transaction, marks the user verified or not
+func (i *identityRepo) SaveVerification(verification *models.IdentityVerification) error {
+	return i.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{
+				"method", "reference_hash", "provider", "status", "attempts", "window_start", "verified_at", "updated_at",
+			}),
+		}).Create(verification).Error; err != nil {
+			return err
+		}
+		var verifiedAt *int64
+		if verification.Status == models.IdentityVerified {
+			verifiedAt = &verification.VerifiedAt
+		}
+		return tx.Model(&models.User{}).Where("id = ?", verification.UserID).
+			Update("identity_verified_at", verifiedAt).Error
+	})
+}
+
+// RevokeVerification withdraws a user's verification, freeing the number,
+// returning gorm.ErrRecordNotFound when the user is not verified
+func (i *identityRepo) RevokeVerification(userID uint, at int64) error {
+	return i.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.IdentityVerification{}).
+			Where("user_id = ? AND status = ?", userID, models.IdentityVerified).
+			Updates(map[string]interface{}{
+				"status":         models.IdentityRevoked,
+				"reference_hash": nil,
+				"updated_at":     at,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Model(&models.User{}).Where("id = ?", userID).Update("identity_verified_at", nil).Error
+	})
+}
@@ -0,0 +1,292 @@
+package db
+
+import (
+	"time"
+
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+// IncidentRepository stores incidents and the grouping of reports under them
+type IncidentRepository interface {
+	CreateIncident(incident *models.Incident, update *models.IncidentUpdate) error
+	GetIncident(id string) (*models.Incident, error)
+	ListIncidents(status string, page int) ([]models.Incident, error)
+	UpdateIncident(incident *models.Incident, update *models.IncidentUpdate) error
+	DeleteIncident(id string) error
+	AddUpdate(update *models.IncidentUpdate) error
+	AttachReports(incidentID string, reportIDs []string) (int64, error)
+	DetachReport(incidentID, reportID string) error
+	IncidentReports(incidentID string, page int) ([]models.IncidentReport, error)
+	IncidentStats(incidentID string) (*models.IncidentStats, error)
+	IncidentTimeline(incidentID string) (*models.IncidentTimeline, error)
+}
+
+type incidentRepo struct {
+	DB *gorm.DB
+}
+
+func NewIncidentRepo(db *GormDB) IncidentRepository {
+	return &incidentRepo{db.DB}
+}
+
+// CreateIncident saves a new incident with the first entry of its timeline
+func (i *incidentRepo) CreateIncident(incident *models.Incident, update *models.IncidentUpdate) error {
+	return i.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(incident).Error; err != nil {
+			return err
+		}
+		return tx.Create(update).Error
+	})
+}
+
+func (i *incidentRepo) GetIncident(id string) (*models.Incident, error) {
+	var incident models.Incident
+	if err := i.DB.Where("id = ?", id).First(&incident).Error; err != nil {
+		return nil, err
+	}
+	return &incident, nil
+}
+
+// ListIncidents returns the most recently started incidents first, with the
+// number of reports grouped under each. An empty status lists them all.
+func (i *incidentRepo) ListIncidents(status string, page int) ([]models.Incident, error) {
+	query := i.DB.Model(&models.Incident{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var incidents []models.Incident
+	if err := query.Order("started_at DESC, created_at DESC").
+		Offset((page - 1) * DefaultPageSize).
+		Limit(DefaultPageSize).
+		Find(&incidents).Error; err != nil {
+		return nil, err
+	}
+	if len(incidents) == 0 {
+		return incidents, nil
+	}
+
+	ids := make([]string, len(incidents))
+	for n, incident := range incidents {
+		ids[n] = incident.ID.String()
+	}
+	var counts []struct {
+		IncidentID string
+		Reports    int64
+	}
+	if err := i.DB.Model(&models.IncidentReport{}).
+		Select("incident_id::text AS incident_id, COUNT(*) AS reports").
+		Where("incident_id IN ?", ids).
+		Group("incident_id").
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	byID := make(map[string]int64, len(counts))
+	for _, count := range counts {
+		byID[count.IncidentID] = count.Reports
+	}
+	for n := range incidents {
+		incidents[n].ReportCount = byID[incidents[n].ID.String()]
+	}
+	return incidents, nil
+}
+
+// UpdateIncident saves the incident's details and, when given, a timeline
+// entry recording the change
+func (i *incidentRepo) UpdateIncident(incident *models.Incident, update *models.IncidentUpdate) error {
+	return i.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Incident{}).Where("id = ?", incident.ID).Updates(map[string]interface{}{
+			"title":       incident.Title,
+			"summary":     incident.Summary,
+			"category":    incident.Category,
+			"status":      incident.Status,
+			"state_name":  incident.StateName,
+			"lga_name":    incident.LGAName,
+			"latitude":    incident.Latitude,
+			"longitude":   incident.Longitude,
+			"started_at":  incident.StartedAt,
+			"resolved_at": incident.ResolvedAt,
+			"updated_at":  incident.UpdatedAt,
+		}).Error; err != nil {
+			return err
+		}
+		if update == nil {
+			return nil
+		}
+		return tx.Create(update).Error
+	})
+}
+
+// DeleteIncident removes an incident and its timeline. Its reports are kept
+// and simply no longer grouped.
+func (i *incidentRepo) DeleteIncident(id string) error {
+	return i.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.IncidentReport{}).
+			Where("incident_id = ?", id).
+			Update("incident_id", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("incident_id = ?", id).Delete(&models.IncidentUpdate{}).Error; err != nil {
+			return err
+		}
+		result := tx.Where("id = ?", id).Delete(&models.Incident{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+func (i *incidentRepo) AddUpdate(update *models.IncidentUpdate) error {
+	return i.DB.Create(update).Error
+}
+
+// AttachReports groups reports under the incident, moving any already
+// grouped under another, and returns how many were found
+func (i *incidentRepo) AttachReports(incidentID string, reportIDs []string) (int64, error) {
+	result := i.DB.Model(&models.IncidentReport{}).
+		Where("id IN ?", reportIDs).
+		Update("incident_id", incidentID)
+	return result.RowsAffected, result.Error
+}
+
+// DetachReport removes a report from the incident, returning
+// gorm.ErrRecordNotFound when it is not grouped under it
+func (i *incidentRepo) DetachReport(incidentID, reportID string) error {
+	result := i.DB.Model(&models.IncidentReport{}).
+		Where("id = ? AND incident_id = ?", reportID, incidentID).
+		Update("incident_id", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// IncidentReports returns a page of the incident's reports, newest first
+func (i *incidentRepo) IncidentReports(incidentID string, page int) ([]models.IncidentReport, error) {
+	var reports []models.IncidentReport
+	err := i.DB.Where("incident_id = ?", incidentID).
+		Order("created_at DESC").
+		Offset((page - 1) * DefaultPageSize).
+		Limit(DefaultPageSize).
+		Find(&reports).Error
+	return reports, err
+}
+
+// IncidentStats aggregates the incident's reports. Reports without
+// coordinates are left out of its geography.
+func (i *incidentRepo) IncidentStats(incidentID string) (*models.IncidentStats, error) {
+	var totals struct {
+		Reports       int64
+		Verified      int64
+		Upvotes       int64
+		Reporters     int64
+		FirstReportAt int64
+		LastReportAt  int64
+		Located       int64
+		CenterLat     float64
+		CenterLng     float64
+		South         float64
+		West          float64
+		North         float64
+		East          float64
+	}
+	if err := i.DB.Model(&models.IncidentReport{}).
+		Select(`COUNT(*) AS reports,
+			COUNT(*) FILTER (WHERE LOWER(report_status) IN `+publishedStatuses+`) AS verified,
+			COALESCE(SUM(upvote_count), 0) AS upvotes,
+			COUNT(DISTINCT user_id) AS reporters,
+			COALESCE(MIN(created_at), 0) AS first_report_at,
+			COALESCE(MAX(created_at), 0) AS last_report_at,
+			COUNT(*) FILTER (WHERE NOT (latitude = 0 AND longitude = 0)) AS located,
+			COALESCE(AVG(latitude) FILTER (WHERE NOT (latitude = 0 AND longitude = 0)), 0) AS center_lat,
+			COALESCE(AVG(longitude) FILTER (WHERE NOT (latitude = 0 AND longitude = 0)), 0) AS center_lng,
+			COALESCE(MIN(latitude) FILTER (WHERE NOT (latitude = 0 AND longitude = 0)), 0) AS south,
+			COALESCE(MIN(longitude) FILTER (WHERE NOT (latitude = 0 AND longitude = 0)), 0) AS west,
+			COALESCE(MAX(latitude) FILTER (WHERE NOT (latitude = 0 AND longitude = 0)), 0) AS north,
+			COALESCE(MAX(longitude) FILTER (WHERE NOT (latitude = 0 AND longitude = 0)), 0) AS east`).
+		Where("incident_id = ?", incidentID).
+		Scan(&totals).Error; err != nil {
+		return nil, err
+	}
+
+	stats := &models.IncidentStats{
+		Reports:       totals.Reports,
+		Verified:      totals.Verified,
+		Upvotes:       totals.Upvotes,
+		Reporters:     totals.Reporters,
+		FirstReportAt: totals.FirstReportAt,
+		LastReportAt:  totals.LastReportAt,
+		ByStatus:      map[string]int64{},
+		Areas:         []models.IncidentArea{},
+	}
+	if totals.Located > 0 {
+		stats.Center = []float64{totals.CenterLat, totals.CenterLng}
+		stats.Bounds = []float64{totals.South, totals.West, totals.North, totals.East}
+	}
+
+	var statuses []struct {
+		Status  string
+		Reports int64
+	}
+	if err := i.DB.Model(&models.IncidentReport{}).
+		Select("COALESCE(NULLIF(report_status, ''), 'pending') AS status, COUNT(*) AS reports").
+		Where("incident_id = ?", incidentID).
+		Group("1").
+		Scan(&statuses).Error; err != nil {
+		return nil, err
+	}
+	for _, status := range statuses {
+		stats.ByStatus[status.Status] = status.Reports
+	}
+
+	if err := i.DB.Model(&models.IncidentReport{}).
+		Select("state_name, lga_name, COUNT(*) AS reports").
+		Where("incident_id = ?", incidentID).
+		Group("state_name, lga_name").
+		Order("reports DESC, lga_name ASC").
+		Scan(&stats.Areas).Error; err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// IncidentTimeline returns the incident's updates, oldest first, and its
+// daily report counts in UTC
+func (i *incidentRepo) IncidentTimeline(incidentID string) (*models.IncidentTimeline, error) {
+	timeline := &models.IncidentTimeline{
+		Updates:      []models.IncidentUpdate{},
+		DailyReports: []models.IncidentDay{},
+	}
+	if err := i.DB.Where("incident_id = ?", incidentID).
+		Order("created_at ASC, id ASC").
+		Find(&timeline.Updates).Error; err != nil {
+		return nil, err
+	}
+
+	var days []struct {
+		Day     time.Time
+		Reports int64
+	}
+	if err := i.DB.Model(&models.IncidentReport{}).
+		Select("DATE(TO_TIMESTAMP(created_at) AT TIME ZONE 'UTC') AS day, COUNT(*) AS reports").
+		Where("incident_id = ?", incidentID).
+		Group("1").
+		Order("1").
+		Scan(&days).Error; err != nil {
+		return nil, err
+	}
+	for _, day := range days {
+		timeline.DailyReports = append(timeline.DailyReports, models.IncidentDay{
+			Date:    day.Day.Format("2006-01-02"),
+			Reports: day.Reports,
+		})
+	}
+	return timeline, nil
+}
@@ -18,8 +18,10 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/google/uuid"
 	"github.com/pkg/errors"
+	"github.com/techagentng/citizenx/events"
 	"github.com/techagentng/citizenx/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 const (
@@ -27,16 +29,18 @@ const (
 	DefaultPage     = 1
 )
 
+//go:generate mockgen -destination=../mocks/incident_report_repository_mock.go -package=mocks github.com/techagentng/citizenx/db IncidentReportRepository
+
 type IncidentReportRepository interface {
-	SaveIncidentReport(report *models.IncidentReport) (*models.IncidentReport, error)
+	SaveIncidentReport(report *models.IncidentReport, reward *models.Reward, evts ...events.Event) (*models.IncidentReport, error)
 	HasPreviousReports(userID uint) (bool, error)
 	UpdateReward(userID uint, reward *models.Reward) error
 	FindUserByID(id uint) (*models.UserResponse, error)
 	GetReportByID(report_id string) (*models.IncidentReport, error)
-	GetAllReports(page int) ([]models.IncidentReport, error)
-	GetAllReportsByState(state string, page int) ([]models.IncidentReport, error)
-	GetAllReportsByLGA(lga string, page int) ([]models.IncidentReport, error)
-	GetAllReportsByReportType(lga string, page int) ([]models.IncidentReport, error)
+	GetAllReports(page int, sort ReportSort, opts ...PreloadOption) ([]models.IncidentReport, error)
+	GetAllReportsByState(state string, page int, sort ReportSort, opts ...PreloadOption) ([]models.IncidentReport, error)
+	GetAllReportsByLGA(lga string, page int, sort ReportSort, opts ...PreloadOption) ([]models.IncidentReport, error)
+	GetAllReportsByReportType(lga string, page int, sort ReportSort, opts ...PreloadOption) ([]models.IncidentReport, error)
 	GetReportPercentageByState() ([]models.StateReportPercentage, error)
 	Save(report *models.IncidentReport) error
 	GetReportStatusByID(reportID string) (string, error)
@@ -44,12 +48,12 @@ type IncidentReportRepository interface {
 	GetReportsPostedTodayCount() (int64, error)
 	GetTotalUserCount() (int64, error)
 	GetRegisteredUsersCountByLGA(lga string) (int64, error)
-	GetAllReportsByStateByTime(state string, startTime, endTime time.Time, page int) ([]models.IncidentReport, error)
+	GetAllReportsByStateByTime(state string, startTime, endTime time.Time, page int, sort ReportSort, opts ...PreloadOption) ([]models.IncidentReport, error)
 	GetReportsByTypeAndLGA(reportType string, lga string) ([]models.SubReport, error)
 	GetReportTypeCounts(state string, lga string, startDate, endDate *string) ([]string, []int, int, int, []models.StateReportCount, error)
 	SaveStateLgaReportType(lga *models.LGA, state *models.State) error
 	GetIncidentMarkers() ([]Marker, error)
-	DeleteByID(id string) error
+	DeleteByID(id string, deletedBy uint) error
 	GetStateReportCounts() ([]models.StateReportCount, error)
 	GetVariadicStateReportCounts(reportTypes []string, states []string, startDate, endDate *time.Time) ([]models.StateReportCount, error)
 	GetAllCategories() ([]string, error)
@@ -64,8 +68,8 @@ type IncidentReportRepository interface {
 	SaveSubReport(subReport *models.SubReport) (*models.SubReport, error)
 	GetSubReportsByCategory(category string) ([]models.SubReport, error)
 	IsBookmarked(userID uint, reportID uuid.UUID, bookmark *models.Bookmark) error
-	SaveBookmark(bookmark *models.Bookmark) error
-	GetBookmarkedReports(userID uint) ([]models.IncidentReport, error)
+	SaveBookmark(bookmark *models.Bookmark, evts ...events.Event) (bool, error)
+	GetBookmarkedReports(userID uint, opts ...PreloadOption) ([]models.IncidentReport, error)
 	GetReportsByUserID(userID uint) ([]models.ReportType, error)
 	GetReportTypeCountsByLGA(lga string) (map[string]interface{}, error)
 	GetReportCountsByState(state string) ([]string, []int, error)
@@ -74,6 +78,7 @@ type IncidentReportRepository interface {
 	GetReportsByCategory(category string) ([]models.ReportType, error)
 	GetFilteredIncidentReports(category, state, lga string) ([]models.IncidentReport, []string, error)
 	GetIncidentReportByID(reportID string) (*models.IncidentReport, error)
+	UserRestrictedUntil(userID uint) (int64, error)
 	UpdateReportTypeWithIncidentReport(report *models.IncidentReport) error
 	FindReportTypeByCategory(category string, reportType *models.ReportType) error
 	GetReportTypeByCategory(category string) (*models.ReportType, error)
@@ -83,8 +88,20 @@ type IncidentReportRepository interface {
 	GetReportIDByUser(ctx context.Context, userID uint) (uuid.UUID, error)
 	GetReportTypeeByID(reportTypeID string) (*models.ReportType, error)
 	GetLastReportIDByUserID(userID uint) (string, error)
-	GetAllIncidentReportsByUser(userID uint) ([]models.IncidentReport, error)
+	GetAllIncidentReportsByUser(userID uint, opts ...PreloadOption) ([]models.IncidentReport, error)
 	ReportExists(reportID uuid.UUID) (bool, error)
+	SearchReports(query string, filters ReportFilter, page int, sort ReportSort, opts ...PreloadOption) ([]models.IncidentReport, error)
+	ListReports(filters ReportFilter, page int, sort ReportSort, opts ...PreloadOption) ([]models.IncidentReport, error)
+	GetReportsWithinRadius(lat, lng, radiusKM float64, page int, opts ...PreloadOption) ([]models.IncidentReport, error)
+	GetReportsByIDs(ids []string, opts ...PreloadOption) ([]models.IncidentReport, error)
+	EachReportBatch(size int, fn func(reports []models.IncidentReport) error) error
+	IncrementViewCount(reportID string) error
+	SetOfficialResponse(reportID string, response string) error
+	ListUserReports(userID uint, filter UserReportFilter, page int, opts ...PreloadOption) ([]models.IncidentReport, error)
+	CountUserReports(userID uint, filter UserReportFilter) (map[string]int64, error)
+	WithdrawReport(reportID uuid.UUID, userID uint, reason string) (bool, error)
+	EditReport(reportID uuid.UUID, editorID uint, changes map[string]interface{}, fields []string) (*models.ReportRevision, error)
+	GetReportRevisions(reportID string) ([]models.ReportRevision, error)
 }
 
 type incidentReportRepo struct {
@@ -97,16 +114,22 @@ func NewIncidentReportRepo(db *GormDB) IncidentReportRepository {
 }
 
 func (i *incidentReportRepo) UpdateReward(userID uint, reward *models.Reward) error {
+	return updateReward(i.DB, userID, reward)
+}
+
+// updateReward records the points of a new report in tx, on the user's
+// existing reward when they have one
+func updateReward(tx *gorm.DB, userID uint, reward *models.Reward) error {
 	// Find the existing reward for the user
 	existingReward := &models.Reward{}
 
 	// Retrieve the existing reward from the database
-	if err := i.DB.Where("user_id = ?", userID).First(existingReward).Error; err != nil {
+	if err := tx.Where("user_id = ?", userID).First(existingReward).Error; err != nil {
 		// Check if the error is due to record not found
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			// If record not found, create a new reward with the provided details
 			// and save it to the database
-			if err := i.DB.Create(reward).Error; err != nil {
+			if err := tx.Create(reward).Error; err != nil {
 				return err
 			}
 			return nil
@@ -117,7 +140,7 @@ func (i *incidentReportRepo) UpdateReward(userID uint, reward *models.Reward) er
 
 	// Use COALESCE to handle NULL sums
 	var totalBalance sql.NullInt64
-	err := i.DB.Table("rewards").Select("COALESCE(SUM(balance), 0)").Where("user_id = ?", userID).Scan(&totalBalance).Error
+	err := tx.Table("rewards").Select("COALESCE(SUM(balance), 0)").Where("user_id = ?", userID).Scan(&totalBalance).Error
 	if err != nil {
 		return fmt.Errorf("failed to retrieve total balance: %w", err)
 	}
@@ -140,16 +163,67 @@ func (i *incidentReportRepo) UpdateReward(userID uint, reward *models.Reward) er
 	}
 
 	// Save the updated reward to the database
-	if err := i.DB.Save(existingReward).Error; err != nil {
+	if err := tx.Save(existingReward).Error; err != nil {
 		return fmt.Errorf("failed to update reward: %w", err)
 	}
 
 	return nil
 }
 
-func (i *incidentReportRepo) SaveIncidentReport(report *models.IncidentReport) (*models.IncidentReport, error) {
+// SaveIncidentReport saves a new report together with its classification,
+// the reward it earns and the events describing it, and removes the draft
+// it was finalized from, so a retried submission never counts twice.
+func (i *incidentReportRepo) SaveIncidentReport(report *models.IncidentReport, reward *models.Reward, evts ...events.Event) (*models.IncidentReport, error) {
 	// Save the new report to the database
-	if err := i.DB.Create(&report).Error; err != nil {
+	err := i.DB.Transaction(func(tx *gorm.DB) error {
+		if c := report.Classification; c != nil {
+			if err := tx.Omit(clause.Associations).Create(c).Error; err != nil {
+				return err
+			}
+			if len(c.SubReports) > 0 {
+				if err := tx.Omit(clause.Associations).Create(&c.SubReports).Error; err != nil {
+					return err
+				}
+			}
+		}
+		if err := tx.Create(&report).Error; err != nil {
+			return err
+		}
+		if len(report.Points) > 0 {
+			if err := tx.Create(&report.Points).Error; err != nil {
+				return err
+			}
+		}
+		if reward != nil {
+			if err := updateReward(tx, report.UserID, reward); err != nil {
+				return fmt.Errorf("error creating reward: %w", err)
+			}
+		}
+		// A finalized draft becomes the report that takes its ID
+		if err := tx.Where("id = ?", report.ID).Delete(&models.ReportDraft{}).Error; err != nil {
+			return err
+		}
+		// Auto-published reports are saved pending and moved on through the
+		// lifecycle, so the move is recorded and published like a moderator's
+		if report.AutoPublished {
+			now := time.Now()
+			transition, err := transitionStatus(tx, report.ID, models.ReportStatusApproved, nil, "auto-published", now, nil)
+			if err != nil {
+				return err
+			}
+			report.ReportStatus = models.ReportStatusApproved
+			report.StatusUpdatedAt = now.Unix()
+			evts = append(evts, events.ReportVerified{
+				ReportID:     report.ID,
+				UserID:       report.UserID,
+				Status:       models.ReportStatusApproved,
+				TransitionID: transition.ID,
+				OccurredAt:   now,
+			})
+		}
+		return writeOutbox(tx, evts...)
+	})
+	if err != nil {
 		return nil, fmt.Errorf("failed to save report: %v", err)
 	}
 
@@ -202,23 +276,8 @@ func (i *incidentReportRepo) GetReportByID(report_id string) (*models.IncidentRe
 	return &report, nil
 }
 
-func (repo *incidentReportRepo) GetAllReports(page int) ([]models.IncidentReport, error) {
-	var reports []models.IncidentReport
-
-	// Ensure page is valid (default to page 1 if invalid)
-	if page < 1 {
-		page = 1
-	}
-
-	// Calculate the offset
-	offset := (page - 1) * 20
-
-	// Fetch reports ordered by 'created_at' in descending order
-	err := repo.DB.
-		Order("created_at DESC"). // Change to descending order
-		Limit(20).
-		Offset(offset).
-		Find(&reports).Error
+func (repo *incidentReportRepo) GetAllReports(page int, sort ReportSort, opts ...PreloadOption) ([]models.IncidentReport, error) {
+	reports, err := findReports(sort.paginate(repo.DB, page), opts...)
 
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -230,31 +289,17 @@ func (repo *incidentReportRepo) GetAllReports(page int) ([]models.IncidentReport
 	return reports, nil
 }
 
-func (repo *incidentReportRepo) GetAllReportsByState(state string, page int) ([]models.IncidentReport, error) {
-	var reports []models.IncidentReport
-	offset := (page - 1) * DefaultPageSize
-
-	err := repo.DB.Where("state = ?", state).
-		Order("timeof_incidence DESC").
-		Limit(DefaultPageSize).
-		Offset(offset).
-		Find(&reports).Error
+func (repo *incidentReportRepo) GetAllReportsByState(state string, page int, sort ReportSort, opts ...PreloadOption) ([]models.IncidentReport, error) {
+	reports, err := findReports(sort.paginate(repo.DB.Where("incident_reports.state_name = ?", state), page), opts...)
 	if err != nil {
 		return nil, err
 	}
 	return reports, nil
 }
 
-// GetAllReportsByState returns incident reports filtered by state and time range, with pagination
-func (repo *incidentReportRepo) GetAllReportsByStateByTime(state string, startTime, endTime time.Time, page int) ([]models.IncidentReport, error) {
-	var reports []models.IncidentReport
-	offset := (page - 1) * DefaultPageSize
-
-	err := repo.DB.Where("state = ? AND timeof_incidence BETWEEN ? AND ?", state, startTime, endTime).
-		Order("timeof_incidence DESC").
-		Limit(DefaultPageSize).
-		Offset(offset).
-		Find(&reports).Error
+// GetAllReportsByStateByTime returns incident reports filtered by state and time range, with pagination
+func (repo *incidentReportRepo) GetAllReportsByStateByTime(state string, startTime, endTime time.Time, page int, sort ReportSort, opts ...PreloadOption) ([]models.IncidentReport, error) {
+	reports, err := findReports(sort.paginate(repo.DB.Where("incident_reports.state_name = ? AND incident_reports.timeof_incidence BETWEEN ? AND ?", state, startTime, endTime), page), opts...)
 
 	if err != nil {
 		return nil, err
@@ -262,30 +307,16 @@ func (repo *incidentReportRepo) GetAllReportsByStateByTime(state string, startTi
 	return reports, nil
 }
 
-func (repo *incidentReportRepo) GetAllReportsByLGA(lga string, page int) ([]models.IncidentReport, error) {
-	var reports []models.IncidentReport
-	offset := (page - 1) * DefaultPageSize
-
-	err := repo.DB.Where("lga = ?", lga).
-		Order("timeof_incidence DESC").
-		Limit(DefaultPageSize).
-		Offset(offset).
-		Find(&reports).Error
+func (repo *incidentReportRepo) GetAllReportsByLGA(lga string, page int, sort ReportSort, opts ...PreloadOption) ([]models.IncidentReport, error) {
+	reports, err := findReports(sort.paginate(repo.DB.Where("incident_reports.lga_name = ?", lga), page), opts...)
 	if err != nil {
 		return nil, err
 	}
 	return reports, nil
 }
 
-func (repo *incidentReportRepo) GetAllReportsByReportType(reportType string, page int) ([]models.IncidentReport, error) {
-	var reports []models.IncidentReport
-	offset := (page - 1) * DefaultPageSize
-
-	err := repo.DB.Where("report_type = ?", reportType).
-		Order("timeof_incidence DESC").
-		Limit(DefaultPageSize).
-		Offset(offset).
-		Find(&reports).Error
+func (repo *incidentReportRepo) GetAllReportsByReportType(reportType string, page int, sort ReportSort, opts ...PreloadOption) ([]models.IncidentReport, error) {
+	reports, err := findReports(sort.paginate(repo.DB.Where("incident_reports.category = ?", reportType), page), opts...)
 	if err != nil {
 		return nil, err
 	}
@@ -413,21 +444,25 @@ func (repo *incidentReportRepo) GetReportTypeCounts(state string, lga string, st
 	var args []interface{}
 	args = append(args, state, lga, state, lga, state, lga)
 
-	// Optional date filter
-	if startDate != nil && endDate != nil && *startDate != "" && *endDate != "" {
-		var err error
-		defaultStartDate, err := time.Parse("2006-01-02", *startDate)
-		if err != nil {
-			return nil, nil, 0, 0, nil, errors.New("failed to parse start date: " + err.Error())
-		}
-
-		defaultEndDate, err := time.Parse("2006-01-02", *endDate)
-		if err != nil {
-			return nil, nil, 0, 0, nil, errors.New("failed to parse end date: " + err.Error())
-		}
-
-		query += ` AND rt.date_of_incidence BETWEEN ? AND ?`
-		args = append(args, defaultStartDate, defaultEndDate)
+	// Optional date filter, over whole days
+	var from, to string
+	if startDate != nil {
+		from = *startDate
+	}
+	if endDate != nil {
+		to = *endDate
+	}
+	start, end, err := ParseDayRange(from, to)
+	if err != nil {
+		return nil, nil, 0, 0, nil, err
+	}
+	if start != nil {
+		query += ` AND rt.date_of_incidence >= ?`
+		args = append(args, *start)
+	}
+	if end != nil {
+		query += ` AND rt.date_of_incidence < ?`
+		args = append(args, *end)
 	}
 
 	query += ` GROUP BY rt.category`
@@ -551,17 +586,26 @@ func (repo *incidentReportRepo) GetIncidentMarkers() ([]Marker, error) {
 	return markers, nil
 }
 
-func (repo *incidentReportRepo) DeleteByID(id string) error {
-	var report models.SubReport
-	if err := repo.DB.Where("id = ?", id).First(&report).Error; err != nil {
-		return err
-	}
+// DeleteByID removes a report for good and records that it is gone, so
+// webhook subscribers hear of it
+func (repo *incidentReportRepo) DeleteByID(id string, deletedBy uint) error {
+	return repo.DB.Transaction(func(tx *gorm.DB) error {
+		var report models.IncidentReport
+		if err := tx.Where("id = ?", id).First(&report).Error; err != nil {
+			return err
+		}
 
-	if err := repo.DB.Delete(&report).Error; err != nil {
-		return err
-	}
+		if err := tx.Delete(&report).Error; err != nil {
+			return err
+		}
 
-	return nil
+		return writeOutbox(tx, events.ReportDeleted{
+			ReportID:   report.ID,
+			UserID:     report.UserID,
+			DeletedBy:  deletedBy,
+			OccurredAt: time.Now(),
+		})
+	})
 }
 
 func (repo *incidentReportRepo) GetStateReportCounts() ([]models.StateReportCount, error) {
@@ -896,14 +940,11 @@ func (repo *incidentReportRepo) GetSubReportsByCategory(category string) ([]mode
 	return subReports, nil
 }
 
-func (repo *incidentReportRepo) GetAllIncidentReportsByUser(userID uint) ([]models.IncidentReport, error) {
-    var reports []models.IncidentReport
-
+func (repo *incidentReportRepo) GetAllIncidentReportsByUser(userID uint, opts ...PreloadOption) ([]models.IncidentReport, error) {
     // Query to get reports ordered by date_of_incidence
-    err := repo.DB.Joins("JOIN report_types ON report_types.id = incident_reports.report_type_id").
+    reports, err := findReports(repo.DB.Joins("JOIN report_types ON report_types.id = incident_reports.report_type_id").
         Where("report_types.user_id = ?", userID).
-        Order("incident_reports.date_of_incidence DESC"). 
-        Find(&reports).Error
+        Order("incident_reports.date_of_incidence DESC"), opts...)
 
     if err != nil {
         if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -921,21 +962,29 @@ func (repo *incidentReportRepo) IsBookmarked(userID uint, reportID uuid.UUID, bo
         First(bookmark).Error
 }
 
-func (repo *incidentReportRepo) SaveBookmark(bookmark *models.Bookmark) error {
-	return repo.DB.Create(bookmark).Error
+// SaveBookmark saves the bookmark and its events unless the user has
+// bookmarked the report already, reporting whether it was saved
+func (repo *incidentReportRepo) SaveBookmark(bookmark *models.Bookmark, evts ...events.Event) (bool, error) {
+	saved := false
+	err := repo.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(bookmark)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		saved = true
+		return writeOutbox(tx, evts...)
+	})
+	return saved && err == nil, err
 }
 
-func (repo *incidentReportRepo) GetBookmarkedReports(userID uint) ([]models.IncidentReport, error) {
-	var reports []models.IncidentReport
-
+func (repo *incidentReportRepo) GetBookmarkedReports(userID uint, opts ...PreloadOption) ([]models.IncidentReport, error) {
 	log.Printf("Retrieving bookmarked reports for userID: %d", userID)
 
 	// Perform the query with a join on bookmarks and preload the associated ReportType
-	err := repo.DB.
-		Joins("JOIN bookmarks ON bookmarks.report_id = incident_reports.id").
+	reports, err := findReports(repo.DB.
+		Joins("JOIN bookmarks ON bookmarks.report_id = incident_reports.id::text").
 		Where("bookmarks.user_id = ?", userID).
-		Preload("ReportType"). // This preloads the related ReportType data
-		Find(&reports).Error
+		Preload("ReportType"), opts...)
 
 	if err != nil {
 		log.Printf("Error retrieving reports: %v", err)
@@ -1181,7 +1230,9 @@ func (i *incidentReportRepo) UpdateIncidentReport(report *models.IncidentReport)
 			return fmt.Errorf("invalid incident report data: %w", err)
 		}
 
-		// Update the existing report's fields with the new data.
+		// Update the existing report's fields with the new data, keeping the
+		// version it replaces when its details change.
+		previous := models.RevisionOf(&existingReport)
 		existingReport.Description = report.Description
 		existingReport.FeedURLs = report.FeedURLs
 		existingReport.ThumbnailURLs = report.ThumbnailURLs
@@ -1203,6 +1254,7 @@ func (i *incidentReportRepo) UpdateIncidentReport(report *models.IncidentReport)
 		existingReport.IsResponse = report.IsResponse
 		existingReport.TimeofIncidence = report.TimeofIncidence
 		existingReport.ReportStatus = report.ReportStatus
+		existingReport.StatusUpdatedAt = report.StatusUpdatedAt
 		existingReport.RewardPoint = report.RewardPoint
 		existingReport.RewardAccountNumber = report.RewardAccountNumber
 		existingReport.ActionTypeName = report.ActionTypeName
@@ -1230,6 +1282,11 @@ func (i *incidentReportRepo) UpdateIncidentReport(report *models.IncidentReport)
 		existingReport.UpvoteCount = report.UpvoteCount
 		existingReport.DownvoteCount = report.DownvoteCount
 		log.Printf("Existing Report before savexxxxxxxxxx: %+v", existingReport)
+		if revised := models.RevisionOf(&existingReport); !revised.Same(previous) {
+			if _, err := saveRevision(tx, previous, 0); err != nil {
+				return err
+			}
+		}
 
 		// Save the updated report to the database.
 		if err := tx.Save(&existingReport).Error; err != nil {
@@ -1413,4 +1470,35 @@ func (repo *incidentReportRepo) ReportExists(reportID uuid.UUID) (bool, error) {
         Where("id = ?", reportID).
         Count(&count).Error
     return count > 0, err
-}
\ No newline at end of file
+}
+
+// IncrementViewCount adds one to the report's view count
+func (repo *incidentReportRepo) IncrementViewCount(reportID string) error {
+	return repo.DB.Model(&models.IncidentReport{}).
+		Where("id = ?", reportID).
+		UpdateColumn("view", gorm.Expr("view + 1")).Error
+}
+
+// SetOfficialResponse records the authorities' response to a report.
+func (repo *incidentReportRepo) SetOfficialResponse(reportID string, response string) error {
+	result := repo.DB.Model(&models.IncidentReport{}).
+		Where("id = ?", reportID).
+		UpdateColumns(map[string]interface{}{
+			"official_response":    response,
+			"official_response_at": time.Now().Unix(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// UserRestrictedUntil returns when the user leaves restricted mode
+func (i *incidentReportRepo) UserRestrictedUntil(userID uint) (int64, error) {
+	var restrictedUntil int64
+	err := i.DB.Model(&models.User{}).Select("restricted_until").Where("id = ?", userID).Scan(&restrictedUntil).Error
+	return restrictedUntil, err
+}
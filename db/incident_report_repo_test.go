@@ -0,0 +1,209 @@
+package db_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/testutil"
+)
+
+// uniqueName returns a value no other test data will share, so aggregates
+// can be asserted on a live database that already holds rows.
+func uniqueName(prefix string) string {
+	return prefix + "-" + uuid.NewString()[:8]
+}
+
+func TestGetReportCountsByState(t *testing.T) {
+	gormDB := testutil.Tx(t)
+	repo := db.NewIncidentReportRepo(gormDB)
+
+	state := uniqueName("state")
+	for _, lga := range []string{"Ikeja", "Ikeja", "Epe"} {
+		lga := lga
+		testutil.CreateIncidentReport(t, gormDB.DB, func(r *models.IncidentReport) {
+			r.StateName = state
+			r.LGAName = lga
+		})
+	}
+
+	lgas, counts, err := repo.GetReportCountsByState(state)
+	if err != nil {
+		t.Fatalf("GetReportCountsByState: %v", err)
+	}
+	if len(lgas) != 2 || lgas[0] != "Ikeja" || counts[0] != 2 || lgas[1] != "Epe" || counts[1] != 1 {
+		t.Fatalf("got lgas %v counts %v, want [Ikeja Epe] [2 1]", lgas, counts)
+	}
+}
+
+func TestGetStateReportCounts(t *testing.T) {
+	gormDB := testutil.Tx(t)
+	repo := db.NewIncidentReportRepo(gormDB)
+
+	state := uniqueName("state")
+	for i := 0; i < 3; i++ {
+		testutil.CreateReportType(t, gormDB.DB, func(rt *models.ReportType) { rt.StateName = state })
+	}
+
+	counts, err := repo.GetStateReportCounts()
+	if err != nil {
+		t.Fatalf("GetStateReportCounts: %v", err)
+	}
+	for _, c := range counts {
+		if c.StateName == state {
+			if c.ReportCount != 3 {
+				t.Fatalf("got %d reports for %s, want 3", c.ReportCount, state)
+			}
+			return
+		}
+	}
+	t.Fatalf("state %s missing from %v", state, counts)
+}
+
+func TestGetReportCountsByStateAndLGA(t *testing.T) {
+	gormDB := testutil.Tx(t)
+	repo := db.NewIncidentReportRepo(gormDB)
+
+	state := uniqueName("state")
+	for _, lga := range []string{"Ikeja", "Ikeja", "Epe"} {
+		lga := lga
+		testutil.CreateReportType(t, gormDB.DB, func(rt *models.ReportType) {
+			rt.StateName = state
+			rt.LGAName = lga
+		})
+	}
+
+	counts, err := repo.GetReportCountsByStateAndLGA()
+	if err != nil {
+		t.Fatalf("GetReportCountsByStateAndLGA: %v", err)
+	}
+	got := map[string]int{}
+	for _, c := range counts {
+		if c.StateName == state {
+			got[c.LGAName] = c.Count
+		}
+	}
+	if got["Ikeja"] != 2 || got["Epe"] != 1 || len(got) != 2 {
+		t.Fatalf("got %v, want map[Epe:1 Ikeja:2]", got)
+	}
+}
+
+func TestGetVariadicStateReportCounts(t *testing.T) {
+	gormDB := testutil.Tx(t)
+	repo := db.NewIncidentReportRepo(gormDB)
+
+	state := uniqueName("state")
+	for _, category := range []string{"Roads", "Roads", "Health"} {
+		category := category
+		testutil.CreateReportType(t, gormDB.DB, func(rt *models.ReportType) {
+			rt.StateName = state
+			rt.Category = category
+		})
+	}
+
+	counts, err := repo.GetVariadicStateReportCounts([]string{"Roads"}, []string{state}, nil, nil)
+	if err != nil {
+		t.Fatalf("GetVariadicStateReportCounts: %v", err)
+	}
+	if len(counts) != 1 || counts[0].Category != "Roads" || counts[0].ReportCount != 2 {
+		t.Fatalf("got %+v, want a single Roads row with 2 reports", counts)
+	}
+}
+
+func TestGetRatingPercentages(t *testing.T) {
+	gormDB := testutil.Tx(t)
+	repo := db.NewIncidentReportRepo(gormDB)
+
+	state := uniqueName("state")
+	for _, rating := range []string{"good", "good", "good", "bad"} {
+		rating := rating
+		testutil.CreateReportType(t, gormDB.DB, func(rt *models.ReportType) {
+			rt.StateName = state
+			rt.IncidentReportRating = rating
+		})
+	}
+
+	percentages, err := repo.GetRatingPercentages("Roads", state)
+	if err != nil {
+		t.Fatalf("GetRatingPercentages: %v", err)
+	}
+	if percentages.GoodPercentage != 75 || percentages.BadPercentage != 25 {
+		t.Fatalf("got %+v, want 75/25", percentages)
+	}
+}
+
+func TestGetTotalReportCount(t *testing.T) {
+	gormDB := testutil.Tx(t)
+	repo := db.NewIncidentReportRepo(gormDB)
+
+	before, err := repo.GetTotalReportCount()
+	if err != nil {
+		t.Fatalf("GetTotalReportCount: %v", err)
+	}
+	testutil.CreateReportType(t, gormDB.DB)
+	testutil.CreateReportType(t, gormDB.DB)
+
+	after, err := repo.GetTotalReportCount()
+	if err != nil {
+		t.Fatalf("GetTotalReportCount: %v", err)
+	}
+	if after-before != 2 {
+		t.Fatalf("count grew by %d, want 2", after-before)
+	}
+}
+
+func TestGetAllReportsByStatePreloads(t *testing.T) {
+	gormDB := testutil.Tx(t)
+	repo := db.NewIncidentReportRepo(gormDB)
+
+	state := uniqueName("state")
+	reporter := testutil.CreateUser(t, gormDB.DB)
+	report := testutil.CreateIncidentReport(t, gormDB.DB, func(r *models.IncidentReport) {
+		r.StateName = state
+		r.UserID = reporter.ID
+	})
+	testutil.CreateIncidentReport(t, gormDB.DB, func(r *models.IncidentReport) {
+		r.StateName = state
+		r.UserIsAnonymous = true
+	})
+	media := models.Media{ID: uuid.NewString(), FileType: "image", FeedURL: "https://example.com/feed.jpg", IncidentReportID: report.ID}
+	if err := gormDB.DB.Create(&media).Error; err != nil {
+		t.Fatalf("creating media: %v", err)
+	}
+
+	reports, err := repo.GetAllReportsByState(state, 1, db.ReportSort{}, db.ReportCard()...)
+	if err != nil {
+		t.Fatalf("GetAllReportsByState: %v", err)
+	}
+	if len(reports) != 2 {
+		t.Fatalf("got %d reports, want 2", len(reports))
+	}
+	for _, r := range reports {
+		if r.Counts == nil {
+			t.Fatalf("report %s has no counts", r.ID)
+		}
+		if r.UserIsAnonymous {
+			if r.Reporter != nil {
+				t.Errorf("anonymous report %s exposes reporter %+v", r.ID, r.Reporter)
+			}
+			continue
+		}
+		if r.Reporter == nil || r.Reporter.ID != reporter.ID {
+			t.Errorf("got reporter %+v, want user %d", r.Reporter, reporter.ID)
+		}
+		if len(r.Media) != 1 || r.Media[0].FeedURL != media.FeedURL || r.Counts.Media != 1 {
+			t.Errorf("got media %+v counts %+v, want the single attached image", r.Media, r.Counts)
+		}
+	}
+
+	bare, err := repo.GetAllReportsByState(state, 1, db.ReportSort{})
+	if err != nil {
+		t.Fatalf("GetAllReportsByState without options: %v", err)
+	}
+	for _, r := range bare {
+		if r.Media != nil || r.Reporter != nil || r.Counts != nil {
+			t.Errorf("report %s loaded associations without options", r.ID)
+		}
+	}
+}
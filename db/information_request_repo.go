@@ -0,0 +1,137 @@
+package db
+
+import (
+	"github.com/techagentng/citizenx/events"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+// InformationRequestRepository persists moderators' requests for more
+// information and applies reporters' answers to their reports
+type InformationRequestRepository interface {
+	CreateRequest(request *models.InformationRequest, evts ...events.Event) error
+	GetRequest(id uint) (*models.InformationRequest, error)
+	HasOpenRequest(reportID string) (bool, error)
+	ReportRequests(reportID string) ([]models.InformationRequest, error)
+	UserRequests(userID uint, openOnly bool) ([]models.InformationRequest, error)
+	AnswerRequest(request *models.InformationRequest, changes map[string]interface{}, evts ...events.Event) (bool, error)
+	ExpireRequests(now int64) (int64, error)
+	DueReminders(remindedBefore int64, limit int) ([]models.InformationRequest, error)
+	MarkReminded(id uint, at int64) error
+}
+
+type informationRequestRepo struct {
+	DB *gorm.DB
+}
+
+func NewInformationRequestRepo(db *GormDB) InformationRequestRepository {
+	return &informationRequestRepo{db.DB}
+}
+
+// CreateRequest saves a request with its events in one transaction
+func (r *informationRequestRepo) CreateRequest(request *models.InformationRequest, evts ...events.Event) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(request).Error; err != nil {
+			return err
+		}
+		// The request's ID is only known once it is saved
+		for i, event := range evts {
+			if e, ok := event.(events.InformationRequested); ok {
+				e.RequestID = request.ID
+				evts[i] = e
+			}
+		}
+		return writeOutbox(tx, evts...)
+	})
+}
+
+func (r *informationRequestRepo) GetRequest(id uint) (*models.InformationRequest, error) {
+	var request models.InformationRequest
+	if err := r.DB.First(&request, id).Error; err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+func (r *informationRequestRepo) HasOpenRequest(reportID string) (bool, error) {
+	var count int64
+	err := r.DB.Model(&models.InformationRequest{}).
+		Where("report_id = ? AND status = ?", reportID, models.InformationRequestOpen).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ReportRequests returns every request made about a report, newest first
+func (r *informationRequestRepo) ReportRequests(reportID string) ([]models.InformationRequest, error) {
+	var requests []models.InformationRequest
+	err := r.DB.Where("report_id = ?", reportID).Order("id DESC").Find(&requests).Error
+	return requests, err
+}
+
+// UserRequests returns the requests made of a reporter, newest first
+func (r *informationRequestRepo) UserRequests(userID uint, openOnly bool) ([]models.InformationRequest, error) {
+	var requests []models.InformationRequest
+	db := r.DB.Where("user_id = ?", userID)
+	if openOnly {
+		db = db.Where("status = ?", models.InformationRequestOpen)
+	}
+	err := db.Order("id DESC").Limit(DefaultPageSize).Find(&requests).Error
+	return requests, err
+}
+
+// AnswerRequest closes an open request and applies changes, keyed by
+// field name, to its report in one transaction. It reports false when the
+// request was no longer open.
+func (r *informationRequestRepo) AnswerRequest(request *models.InformationRequest, changes map[string]interface{}, evts ...events.Event) (bool, error) {
+	answered := false
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.InformationRequest{}).
+			Where("id = ? AND status = ?", request.ID, models.InformationRequestOpen).
+			Updates(map[string]interface{}{
+				"status":      models.InformationRequestAnswered,
+				"answered_at": request.AnsweredAt,
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		answered = true
+
+		if len(changes) > 0 {
+			if err := tx.Model(&models.IncidentReport{}).
+				Where("id = ?", request.ReportID).
+				Updates(changes).Error; err != nil {
+				return err
+			}
+		}
+		return writeOutbox(tx, evts...)
+	})
+	return answered, err
+}
+
+// ExpireRequests closes the open requests past their expiry
+func (r *informationRequestRepo) ExpireRequests(now int64) (int64, error) {
+	result := r.DB.Model(&models.InformationRequest{}).
+		Where("status = ? AND expires_at <= ?", models.InformationRequestOpen, now).
+		Update("status", models.InformationRequestExpired)
+	return result.RowsAffected, result.Error
+}
+
+// DueReminders returns open requests whose reporter was last notified before
+// the given time
+func (r *informationRequestRepo) DueReminders(remindedBefore int64, limit int) ([]models.InformationRequest, error) {
+	var requests []models.InformationRequest
+	err := r.DB.Where("status = ? AND reminded_at < ?", models.InformationRequestOpen, remindedBefore).
+		Order("reminded_at ASC").
+		Limit(limit).
+		Find(&requests).Error
+	return requests, err
+}
+
+func (r *informationRequestRepo) MarkReminded(id uint, at int64) error {
+	return r.DB.Model(&models.InformationRequest{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"reminded_at": at,
+			"reminders":   gorm.Expr("reminders + 1"),
+		}).Error
+}
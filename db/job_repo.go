@@ -0,0 +1,99 @@
+package db
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DefaultJobMaxAttempts is how often a job is tried before it is marked
+// failed.
+const DefaultJobMaxAttempts = 10
+
+// JobRepository stores the background job queue
+type JobRepository interface {
+	Enqueue(jobType string, payload interface{}) error
+	RunPending(limit int, run func(job *models.Job) error) (int, error)
+	CountPending() (int64, error)
+}
+
+type jobRepo struct {
+	DB *gorm.DB
+}
+
+func NewJobRepo(db *GormDB) JobRepository {
+	return &jobRepo{db.DB}
+}
+
+// Enqueue adds a job of jobType to the queue, due immediately.
+func (j *jobRepo) Enqueue(jobType string, payload interface{}) error {
+	return enqueueJob(j.DB, jobType, payload)
+}
+
+// enqueueJob adds a job in tx, for writes that must queue their follow-up
+// work atomically
+func enqueueJob(tx *gorm.DB, jobType string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return tx.Create(&models.Job{
+		Type:        jobType,
+		Payload:     string(body),
+		Status:      models.JobPending,
+		MaxAttempts: DefaultJobMaxAttempts,
+		RunAt:       time.Now(),
+	}).Error
+}
+
+// RunPending locks up to limit due jobs, passes each to run and records the
+// outcome. Failed jobs are retried with the outbox backoff until they run
+// out of attempts. Locked rows are skipped, so several workers can run side
+// by side. It returns the number of jobs that completed.
+func (j *jobRepo) RunPending(limit int, run func(job *models.Job) error) (int, error) {
+	done := 0
+	err := j.DB.Transaction(func(tx *gorm.DB) error {
+		var due []models.Job
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND run_at <= ?", models.JobPending, time.Now()).
+			Order("run_at, id").
+			Limit(limit).
+			Find(&due).Error; err != nil {
+			return err
+		}
+
+		for i := range due {
+			job := &due[i]
+			now := time.Now()
+			job.Attempts++
+			if err := run(job); err != nil {
+				job.LastError = err.Error()
+				job.RunAt = now.Add(outboxBackoff(job.Attempts))
+				if job.Attempts >= job.MaxAttempts {
+					job.Status = models.JobFailed
+					job.FinishedAt = &now
+				}
+			} else {
+				job.Status = models.JobDone
+				job.LastError = ""
+				job.FinishedAt = &now
+				done++
+			}
+			if err := tx.Save(job).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return done, err
+}
+
+// CountPending returns the number of jobs waiting to run.
+func (j *jobRepo) CountPending() (int64, error) {
+	var count int64
+	err := j.DB.Model(&models.Job{}).Where("status = ?", models.JobPending).Count(&count).Error
+	return count, err
+}
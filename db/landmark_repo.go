@@ -0,0 +1,104 @@
+package db
+
+import (
+	"strings"
+
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LandmarkRepository persists known landmarks and the structured locations
+// of reports
+type LandmarkRepository interface {
+	CreateLandmark(landmark *models.Landmark) error
+	GetLandmark(id uint) (*models.Landmark, error)
+	FindLandmarks(normalizedName string) ([]models.Landmark, error)
+	SearchLandmarks(query, stateName string, page int) ([]models.Landmark, error)
+	GetReportAddress(reportID string) (*models.IncidentReport, error)
+	SaveReportLocation(location *models.ReportLocation) error
+	GetReportLocation(reportID string) (*models.ReportLocation, error)
+	LandmarkReports(landmarkID uint, page int) ([]models.IncidentReport, error)
+}
+
+type landmarkRepo struct {
+	DB *gorm.DB
+}
+
+func NewLandmarkRepo(db *GormDB) LandmarkRepository {
+	return &landmarkRepo{db.DB}
+}
+
+func (l *landmarkRepo) CreateLandmark(landmark *models.Landmark) error {
+	return l.DB.Create(landmark).Error
+}
+
+func (l *landmarkRepo) GetLandmark(id uint) (*models.Landmark, error) {
+	var landmark models.Landmark
+	if err := l.DB.First(&landmark, id).Error; err != nil {
+		return nil, err
+	}
+	return &landmark, nil
+}
+
+// FindLandmarks returns every landmark with exactly the normalized name
+func (l *landmarkRepo) FindLandmarks(normalizedName string) ([]models.Landmark, error) {
+	var landmarks []models.Landmark
+	err := l.DB.Where("normalized_name = ?", normalizedName).Order("id").Find(&landmarks).Error
+	return landmarks, err
+}
+
+// SearchLandmarks finds landmarks whose normalized name contains query,
+// those starting with it first
+func (l *landmarkRepo) SearchLandmarks(query, stateName string, page int) ([]models.Landmark, error) {
+	var landmarks []models.Landmark
+	pattern := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(query)
+	db := l.DB.Where("normalized_name LIKE ?", "%"+pattern+"%")
+	if stateName != "" {
+		db = db.Where("LOWER(state_name) = LOWER(?)", stateName)
+	}
+	err := db.Order(clause.Expr{SQL: "normalized_name LIKE ? DESC, name", Vars: []interface{}{pattern + "%"}}).
+		Offset((page - 1) * DefaultPageSize).
+		Limit(DefaultPageSize).
+		Find(&landmarks).Error
+	return landmarks, err
+}
+
+// GetReportAddress loads the fields of a report that locate it
+func (l *landmarkRepo) GetReportAddress(reportID string) (*models.IncidentReport, error) {
+	var report models.IncidentReport
+	err := l.DB.Select("id", "address", "state_name", "lga_name", "latitude", "longitude").
+		Where("id = ?", reportID).
+		First(&report).Error
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// SaveReportLocation stores a report's structured location, replacing any
+// earlier one
+func (l *landmarkRepo) SaveReportLocation(location *models.ReportLocation) error {
+	return l.DB.Omit(clause.Associations).Clauses(clause.OnConflict{UpdateAll: true}).Create(location).Error
+}
+
+func (l *landmarkRepo) GetReportLocation(reportID string) (*models.ReportLocation, error) {
+	var location models.ReportLocation
+	if err := l.DB.Preload("Landmark").First(&location, "report_id = ?", reportID).Error; err != nil {
+		return nil, err
+	}
+	return &location, nil
+}
+
+// LandmarkReports returns the reports located at a landmark, newest first
+func (l *landmarkRepo) LandmarkReports(landmarkID uint, page int) ([]models.IncidentReport, error) {
+	var reports []models.IncidentReport
+	err := l.DB.Model(&models.IncidentReport{}).
+		Joins("JOIN report_locations ON report_locations.report_id = incident_reports.id").
+		Where("report_locations.landmark_id = ?", landmarkID).
+		Order("incident_reports.created_at DESC").
+		Offset((page - 1) * DefaultPageSize).
+		Limit(DefaultPageSize).
+		Find(&reports).Error
+	return reports, err
+}
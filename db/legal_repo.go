@@ -0,0 +1,100 @@
+package db
+
+import (
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LegalRepository stores legal documents and users' acceptances of them
+type LegalRepository interface {
+	ListDocuments(kind string) ([]models.LegalDocument, error)
+	GetDocument(id uint) (*models.LegalDocument, error)
+	CreateDocument(document *models.LegalDocument) error
+	PublishDocument(id uint, at int64) error
+	CurrentDocuments() ([]models.LegalDocument, error)
+	Acceptances(userID uint) ([]models.LegalAcceptance, error)
+	AcceptedDocumentIDs(userID uint, documentIDs []uint) ([]uint, error)
+	CreateAcceptances(acceptances []models.LegalAcceptance) error
+}
+
+type legalRepo struct {
+	DB *gorm.DB
+}
+
+func NewLegalRepo(db *GormDB) LegalRepository {
+	return &legalRepo{db.DB}
+}
+
+// ListDocuments returns every version of the documents of kind, or of all
+// kinds when kind is empty, newest first
+func (l *legalRepo) ListDocuments(kind string) ([]models.LegalDocument, error) {
+	query := l.DB.Order("kind, created_at DESC")
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	var documents []models.LegalDocument
+	err := query.Find(&documents).Error
+	return documents, err
+}
+
+func (l *legalRepo) GetDocument(id uint) (*models.LegalDocument, error) {
+	var document models.LegalDocument
+	if err := l.DB.First(&document, id).Error; err != nil {
+		return nil, err
+	}
+	return &document, nil
+}
+
+func (l *legalRepo) CreateDocument(document *models.LegalDocument) error {
+	return l.DB.Create(document).Error
+}
+
+// PublishDocument publishes a document not yet published, returning
+// gorm.ErrRecordNotFound when there is no such draft
+func (l *legalRepo) PublishDocument(id uint, at int64) error {
+	result := l.DB.Model(&models.LegalDocument{}).
+		Where("id = ? AND published_at IS NULL", id).
+		Update("published_at", at)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// CurrentDocuments returns the latest published version of each kind
+func (l *legalRepo) CurrentDocuments() ([]models.LegalDocument, error) {
+	var documents []models.LegalDocument
+	err := l.DB.Raw(`SELECT DISTINCT ON (kind) * FROM legal_documents
+		WHERE published_at IS NOT NULL
+		ORDER BY kind, published_at DESC, id DESC`).Scan(&documents).Error
+	return documents, err
+}
+
+// Acceptances returns the documents a user has accepted, latest first
+func (l *legalRepo) Acceptances(userID uint) ([]models.LegalAcceptance, error) {
+	var acceptances []models.LegalAcceptance
+	err := l.DB.Where("user_id = ?", userID).Order("accepted_at DESC").Find(&acceptances).Error
+	return acceptances, err
+}
+
+// AcceptedDocumentIDs returns which of documentIDs the user has accepted
+func (l *legalRepo) AcceptedDocumentIDs(userID uint, documentIDs []uint) ([]uint, error) {
+	var ids []uint
+	if len(documentIDs) == 0 {
+		return ids, nil
+	}
+	err := l.DB.Model(&models.LegalAcceptance{}).
+		Where("user_id = ? AND document_id IN ?", userID, documentIDs).
+		Pluck("document_id", &ids).Error
+	return ids, err
+}
+
+// CreateAcceptances records acceptances, keeping the first of any made
+// twice
+func (l *legalRepo) CreateAcceptances(acceptances []models.LegalAcceptance) error {
+	return l.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&acceptances).Error
+}
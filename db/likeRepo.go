@@ -2,22 +2,24 @@ package db
 
 import (
 	"fmt"
-	"log"
+	"time"
 
-	"github.com/pkg/errors"
+	"github.com/google/uuid"
+	"github.com/techagentng/citizenx/events"
 	"github.com/techagentng/citizenx/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // LikeRepository interface
 type LikeRepository interface {
 	GetUserPoints(userID uint) (int, error)
 	UpdateUserPoints(userID uint, points int) error
-	RecordVote(userID uint, reportID string, voteType string) error
 	BeginTransaction() *gorm.DB
-	DownVoteReport(userID uint, reportID string) error
-	UpvoteReport(userID uint, reportID string) error
+	CastVote(userID uint, reportID string, value int, evts ...events.Event) (bool, error)
+	RetractVote(userID uint, reportID string, evts ...events.Event) (bool, error)
 	GetUpvoteAndDownvoteCounts(reportID string) (int, int, error)
+	RefreshCredibility(since time.Time) (int64, error)
 }
 
 // likeRepo struct
@@ -30,47 +32,98 @@ func NewLikeRepo(db *GormDB) LikeRepository {
 	return &likeRepo{db.DB}
 }
 
-func (lk *likeRepo) UpvoteReport(userID uint, reportID string) error {
-	// Start a transaction to ensure consistency
-	tx := lk.DB.Begin()
+// credibilityExpr scores how far a report can be trusted, from 10 to 100:
+// 50, up to 25 either way for the balance of its votes, up to 15 either way
+// for its reporter's reputation and 10 for having media. The vote balance
+// saturates, so a report's first few votes move it most. Unscored
+// reporters count as neutral.
+const credibilityExpr = `ROUND(50
+	+ 25 * TANH((incident_reports.upvote_count - incident_reports.downvote_count) / 10.0)
+	+ 0.3 * (COALESCE((SELECT reporter_reputations.score FROM reporter_reputations
+		WHERE reporter_reputations.user_id = incident_reports.user_id), 50) - 50)
+	+ CASE WHEN incident_reports.feed_urls <> '' OR EXISTS (SELECT 1 FROM media
+		WHERE media.incident_report_id = incident_reports.id::text) THEN 10 ELSE 0 END)`
+
+// CastVote records the user's vote on the report, replacing any vote they
+// had cast, and refreshes the report's vote counts and credibility with
+// the events saved, all only when the vote changed. It reports false when
+// the user had already cast the same vote; the unique index on report_votes
+// settles concurrent repeats.
+func (lk *likeRepo) CastVote(userID uint, reportID string, value int, evts ...events.Event) (bool, error) {
+	id, err := uuid.Parse(reportID)
+	if err != nil {
+		return false, gorm.ErrRecordNotFound
+	}
+	cast := false
+	err = lk.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now().Unix()
+		result := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "report_id"}, {Name: "user_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"value": value, "updated_at": now}),
+			Where:     clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "report_votes.value <> ?", Vars: []interface{}{value}}}},
+		}).Create(&models.ReportVote{ReportID: id, UserID: userID, Value: value, CreatedAt: now, UpdatedAt: now})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		cast = true
+		return refreshVotes(tx, id, evts)
+	})
+	return cast && err == nil, err
+}
 
-	// Check if the user has already upvoted this report
-	var existingVote models.Votes
-	if err := tx.Where("user_id = ? AND report_id = ? AND vote_type = ?", userID, reportID, "upvote").First(&existingVote).Error; err == nil {
-		// User has already upvoted, rollback and return
-		log.Println("User has already upvoted, rolling back transaction")
-		tx.Rollback()
-		return nil
+// RetractVote removes the user's vote on the report, reporting false when
+// they had not voted on it
+func (lk *likeRepo) RetractVote(userID uint, reportID string, evts ...events.Event) (bool, error) {
+	id, err := uuid.Parse(reportID)
+	if err != nil {
+		return false, nil
 	}
+	retracted := false
+	err = lk.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("report_id = ? AND user_id = ?", id, userID).Delete(&models.ReportVote{})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		retracted = true
+		return refreshVotes(tx, id, evts)
+	})
+	return retracted && err == nil, err
+}
 
-	// Record the upvote in the votes table
-	vote := models.Votes{
-		UserID:   userID,
-		ReportID: reportID,
-		VoteType: "upvote",
+// refreshVotes recounts a report's votes, rescores it and saves evts
+func refreshVotes(tx *gorm.DB, reportID uuid.UUID, evts []events.Event) error {
+	var counts struct {
+		Upvotes   int
+		Downvotes int
 	}
-	if err := tx.Create(&vote).Error; err != nil {
-		tx.Rollback() // Rollback transaction on error
-		return err
+	if err := tx.Model(&models.ReportVote{}).
+		Select("COUNT(*) FILTER (WHERE value > 0) AS upvotes, COUNT(*) FILTER (WHERE value < 0) AS downvotes").
+		Where("report_id = ?", reportID).
+		Scan(&counts).Error; err != nil {
+		return fmt.Errorf("failed to count votes: %w", err)
 	}
-
-	// Update the upvote count by querying the Votes table
-	var upvoteCount int64
-	if err := tx.Model(&models.Votes{}).Where("report_id = ? AND vote_type = ?", reportID, "upvote").Count(&upvoteCount).Error; err != nil {
-		log.Println("Failed to count upvotes, rolling back")
-		tx.Rollback()
-		return fmt.Errorf("failed to count upvotes: %w", err)
+	if err := tx.Model(&models.IncidentReport{}).Where("id = ?", reportID).UpdateColumns(map[string]interface{}{
+		"upvote_count":   counts.Upvotes,
+		"downvote_count": counts.Downvotes,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to update vote counts: %w", err)
 	}
-
-	if err := tx.Model(&models.IncidentReport{}).Where("id = ?", reportID).Update("upvote_count", upvoteCount).Error; err != nil {
-		log.Println("Failed to update upvote count, rolling back")
-		tx.Rollback()
-		return fmt.Errorf("failed to update upvote count: %w", err)
+	if err := tx.Model(&models.IncidentReport{}).Where("id = ?", reportID).
+		UpdateColumn("credibility_score", gorm.Expr(credibilityExpr)).Error; err != nil {
+		return fmt.Errorf("failed to update credibility: %w", err)
 	}
+	return writeOutbox(tx, evts...)
+}
 
-	log.Println("Committing transaction")
-	// Commit the transaction
-	return tx.Commit().Error
+// RefreshCredibility rescores the reports submitted since the time given and
+// any never scored, returning how many changed. Votes rescore their report
+// at once; this catches up with media added and reputations recomputed.
+func (lk *likeRepo) RefreshCredibility(since time.Time) (int64, error) {
+	result := lk.DB.Model(&models.IncidentReport{}).
+		Where("created_at >= ? OR credibility_score = 0", since.Unix()).
+		Where("credibility_score <> "+credibilityExpr).
+		UpdateColumn("credibility_score", gorm.Expr(credibilityExpr))
+	return result.RowsAffected, result.Error
 }
 
 func (r *likeRepo) GetUserPoints(userID uint) (int, error) {
@@ -85,61 +138,10 @@ func (r *likeRepo) UpdateUserPoints(userID uint, points int) error {
 	return r.DB.Model(&models.UserPoints{}).Where("user_id = ?", userID).Update("points", points).Error
 }
 
-func (r *likeRepo) RecordVote(userID uint, reportID string, voteType string) error {
-	vote := models.Votes{UserID: userID, ReportID: reportID, VoteType: voteType}
-	return r.DB.Create(&vote).Error
-}
-
 func (r *likeRepo) BeginTransaction() *gorm.DB {
 	return r.DB.Begin()
 }
 
-// DownVoteReport handles the logic for disliking a report with transaction management
-func (r *likeRepo) DownVoteReport(userID uint, reportID string) error {
-	log.Printf("DownVoteReport called: userID = %d, reportID = %s", userID, reportID)
-
-	// Start a transaction to ensure consistency
-	tx := r.DB.Begin()
-
-	// Check if the user has already downvoted this report
-	var existingVote models.Votes
-	if err := tx.Where("user_id = ? AND report_id = ? AND vote_type = ?", userID, reportID, "downvote").First(&existingVote).Error; err == nil {
-		log.Println("User has already downvoted, rolling back transaction")
-		tx.Rollback()
-		return errors.New("user has already downvoted")
-	}
-
-	// Record the downvote in the votes table
-	vote := models.Votes{
-		UserID:   userID,
-		ReportID: reportID,
-		VoteType: "downvote",
-	}
-	if err := tx.Create(&vote).Error; err != nil {
-		log.Println("Failed to record downvote, rolling back")
-		tx.Rollback() // Rollback transaction on error
-		return err
-	}
-
-	// Update the downvote count by querying the Votes table
-	var downvoteCount int64
-	if err := tx.Model(&models.Votes{}).Where("report_id = ? AND vote_type = ?", reportID, "downvote").Count(&downvoteCount).Error; err != nil {
-		log.Println("Failed to count downvotes, rolling back")
-		tx.Rollback()
-		return fmt.Errorf("failed to count downvotes: %w", err)
-	}
-
-	if err := tx.Model(&models.IncidentReport{}).Where("id = ?", reportID).Update("downvote_count", downvoteCount).Error; err != nil {
-		log.Println("Failed to update downvote count, rolling back")
-		tx.Rollback()
-		return fmt.Errorf("failed to update downvote count: %w", err)
-	}
-
-	log.Println("Committing transaction")
-	// Commit the transaction
-	return tx.Commit().Error
-}
-
 // GetUpvoteAndDownvoteCounts retrieves the upvote and downvote counts for a report.
 func (r *likeRepo) GetUpvoteAndDownvoteCounts(reportID string) (int, int, error) {
 	var report models.IncidentReport
@@ -0,0 +1,135 @@
+package db
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/techagentng/citizenx/events"
+	"github.com/techagentng/citizenx/geo"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+// CoarseLocationDecimals is the precision report coordinates keep once they
+// leave the location history: two decimal places, roughly 1km. That is
+// enough for the maps and ward, LGA and state figures but no longer places
+// the reporter at an address.
+const CoarseLocationDecimals = 2
+
+// CoarsePlusCodeLength is the length plus codes are cut to along with the
+// coordinates, an area of about 5.5km
+const CoarsePlusCodeLength = 6
+
+// LocationRepository enforces retention on the location trail users leave
+// behind. The app keeps no separate location pings, online status is a flag
+// only, so the trail is the precise coordinates reports were submitted from
+// and the report.created events that carried them.
+type LocationRepository interface {
+	PurgeLocationHistory(before time.Time) (int64, error)
+	DeleteUserLocationHistory(userID uint) (int64, error)
+}
+
+type locationRepo struct {
+	DB *gorm.DB
+}
+
+func NewLocationRepo(db *GormDB) LocationRepository {
+	return &locationRepo{db.DB}
+}
+
+// PurgeLocationHistory coarsens the coordinates of reports submitted before
+// the cutoff and deletes the delivered events that copied them. It returns
+// the number of reports coarsened.
+func (l *locationRepo) PurgeLocationHistory(before time.Time) (int64, error) {
+	return l.coarsen(func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("created_at < ?", before.Unix())
+	}, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("created_at < ?", before)
+	})
+}
+
+// DeleteUserLocationHistory coarsens the coordinates of every report the user
+// submitted and deletes the delivered events that copied them. It returns
+// the number of reports coarsened.
+func (l *locationRepo) DeleteUserLocationHistory(userID uint) (int64, error) {
+	return l.coarsen(func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("user_id = ?", userID)
+	}, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where("payload->>'user_id' = ?", strconv.FormatUint(uint64(userID), 10))
+	})
+}
+
+func (l *locationRepo) coarsen(reports, outboxEvents func(tx *gorm.DB) *gorm.DB) (int64, error) {
+	var coarsened int64
+	err := l.DB.Transaction(func(tx *gorm.DB) error {
+		// The extra points of multi-point reports go first, while the
+		// reports are still marked precise
+		pending := reports(tx.Model(&models.IncidentReport{})).Where("location_coarsened = false").Select("id")
+		if err := tx.Model(&models.ReportPoint{}).Where("report_id IN (?)", pending).
+			Updates(map[string]interface{}{
+				"latitude":  gorm.Expr("ROUND(latitude::numeric, ?)", CoarseLocationDecimals),
+				"longitude": gorm.Expr("ROUND(longitude::numeric, ?)", CoarseLocationDecimals),
+			}).Error; err != nil {
+			return err
+		}
+
+		// Routes are encoded polylines, so they are coarsened point by point
+		var routes []models.IncidentReport
+		if err := reports(tx.Model(&models.IncidentReport{})).
+			Where("location_coarsened = false AND route <> ''").
+			Select("id", "route").
+			Find(&routes).Error; err != nil {
+			return err
+		}
+		for _, r := range routes {
+			if err := tx.Model(&models.IncidentReport{}).Where("id = ?", r.ID).
+				Update("route", coarsenRoute(r.Route)).Error; err != nil {
+				return err
+			}
+		}
+
+		result := reports(tx.Model(&models.IncidentReport{})).
+			Where("location_coarsened = false").
+			Updates(map[string]interface{}{
+				"latitude":           gorm.Expr("ROUND(latitude::numeric, ?)", CoarseLocationDecimals),
+				"longitude":          gorm.Expr("ROUND(longitude::numeric, ?)", CoarseLocationDecimals),
+				"location_coarsened": true,
+				// Padding the code's first pairs keeps it a valid full code
+				"plus_code": gorm.Expr("CASE WHEN plus_code = '' THEN '' ELSE LEFT(plus_code, ?) || '00+' END", CoarsePlusCodeLength),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		coarsened = result.RowsAffected
+
+		// Undelivered events stay so subscribers still receive them
+		return outboxEvents(tx).
+			Where("event_name = ? AND published_at IS NOT NULL", events.ReportCreatedEvent).
+			Delete(&models.OutboxEvent{}).Error
+	})
+	return coarsened, err
+}
+
+// coarsenRoute rounds every point of an encoded route to
+// CoarseLocationDecimals, dropping points that fall together. A route that
+// does not decode is cleared.
+func coarsenRoute(route string) string {
+	line, err := geo.DecodePolyline(route)
+	if err != nil {
+		return ""
+	}
+	scale := math.Pow(10, CoarseLocationDecimals)
+	coarse := make([]geo.Point, 0, len(line))
+	for _, p := range line {
+		point := geo.Point{
+			Latitude:  math.Round(p.Latitude*scale) / scale,
+			Longitude: math.Round(p.Longitude*scale) / scale,
+		}
+		if n := len(coarse); n > 0 && coarse[n-1] == point {
+			continue
+		}
+		coarse = append(coarse, point)
+	}
+	return geo.EncodePolyline(coarse)
+}
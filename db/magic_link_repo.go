@@ -0,0 +1,62 @@
+package db
+
+import (
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+// MagicLinkRepository persists emailed sign-in links
+type MagicLinkRepository interface {
+	UserByEmail(email string) (*models.User, error)
+	CreateLink(link *models.MagicLink) error
+	CountLinks(userID uint, since int64) (int64, error)
+	FindLink(nonceHash string) (*models.MagicLink, error)
+	UseLink(id uint, at int64) (bool, error)
+	MarkEmailVerified(userID uint) error
+}
+
+type magicLinkRepo struct {
+	DB *gorm.DB
+}
+
+func NewMagicLinkRepo(db *GormDB) MagicLinkRepository {
+	return &magicLinkRepo{db.DB}
+}
+
+// UserByEmail finds the account with the email, ignoring case, returning
+// gorm.ErrRecordNotFound when there is none
+func (r *magicLinkRepo) UserByEmail(email string) (*models.User, error) {
+	var user models.User
+	if err := r.DB.Where("LOWER(email) = LOWER(?)", email).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *magicLinkRepo) CreateLink(link *models.MagicLink) error {
+	return r.DB.Create(link).Error
+}
+
+func (r *magicLinkRepo) CountLinks(userID uint, since int64) (int64, error) {
+	var count int64
+	err := r.DB.Model(&models.MagicLink{}).Where("user_id = ? AND created_at >= ?", userID, since).Count(&count).Error
+	return count, err
+}
+
+func (r *magicLinkRepo) FindLink(nonceHash string) (*models.MagicLink, error) {
+	var link models.MagicLink
+	if err := r.DB.Where("nonce_hash = ?", nonceHash).First(&link).Error; err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// UseLink marks a link used, reporting false when it already was
+func (r *magicLinkRepo) UseLink(id uint, at int64) (bool, error) {
+	result := r.DB.Model(&models.MagicLink{}).Where("id = ? AND used_at = 0", id).Update("used_at", at)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *magicLinkRepo) MarkEmailVerified(userID uint) error {
+	return r.DB.Model(&models.User{}).Where("id = ?", userID).Update("is_email_active", true).Error
+}
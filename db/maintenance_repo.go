@@ -0,0 +1,282 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/techagentng/citizenx/geo"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/pii"
+	"gorm.io/gorm"
+)
+
+// MaintenanceRepository holds the bulk operations run by the admin CLI.
+type MaintenanceRepository interface {
+	ReindexReports() error
+	RecomputeVoteCounts() (int64, error)
+	RecomputeUserPoints() (int64, error)
+	ExpirePoints(before time.Time) (int64, int64, error)
+	PurgeSoftDeleted(before time.Time) (map[string]int64, error)
+	RequeueFailedWebhooks() (int64, error)
+	BackfillPlusCodes() (int64, error)
+	EncryptPII() (map[string]int64, error)
+	BackfillPublicIDs() (map[string]int64, error)
+}
+
+type maintenanceRepo struct {
+	DB *gorm.DB
+}
+
+func NewMaintenanceRepo(db *GormDB) MaintenanceRepository {
+	return &maintenanceRepo{db.DB}
+}
+
+// softDeletable lists the models whose rows carry a deleted_at timestamp.
+var softDeletable = []interface{}{
+	&models.User{},
+	&models.Blacklist{},
+	&models.Comment{},
+	&models.MediaCount{},
+	&models.Notification{},
+	&models.Post{},
+	&models.Reward{},
+	&models.UserPoints{},
+	&models.LoginRequestMacAddress{},
+}
+
+// ReindexReports rebuilds the indexes the report search and listing queries
+// rely on.
+func (m *maintenanceRepo) ReindexReports() error {
+	for _, table := range []string{"incident_reports", "report_types", "sub_reports"} {
+		if err := m.DB.Exec("REINDEX TABLE " + table).Error; err != nil {
+			return fmt.Errorf("reindexing %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// RecomputeVoteCounts rewrites the cached upvote and downvote counts of every
+// report from the report_votes table and returns the number of reports corrected.
+func (m *maintenanceRepo) RecomputeVoteCounts() (int64, error) {
+	result := m.DB.Exec(`
+        WITH counts AS (
+            SELECT incident_reports.id,
+                COUNT(report_votes.id) FILTER (WHERE report_votes.value > 0) AS upvotes,
+                COUNT(report_votes.id) FILTER (WHERE report_votes.value < 0) AS downvotes
+            FROM incident_reports
+            LEFT JOIN report_votes ON report_votes.report_id = incident_reports.id
+            GROUP BY incident_reports.id
+        )
+        UPDATE incident_reports
+        SET upvote_count = counts.upvotes, downvote_count = counts.downvotes
+        FROM counts
+        WHERE incident_reports.id = counts.id
+            AND (incident_reports.upvote_count <> counts.upvotes OR incident_reports.downvote_count <> counts.downvotes)
+    `)
+	return result.RowsAffected, result.Error
+}
+
+// RecomputeUserPoints rewrites each user's cached points from the sum of their
+// reward balances and returns the number of users corrected.
+func (m *maintenanceRepo) RecomputeUserPoints() (int64, error) {
+	result := m.DB.Exec(`
+        UPDATE user_points
+        SET points = totals.points
+        FROM (
+            SELECT user_id::text AS user_id, COALESCE(SUM(balance), 0) AS points
+            FROM rewards
+            GROUP BY user_id
+        ) AS totals
+        WHERE user_points.user_id = totals.user_id AND user_points.points <> totals.points
+    `)
+	return result.RowsAffected, result.Error
+}
+
+// ExpirePoints zeroes the balance of rewards created before the cutoff. The
+// earned points stay on the reward for history. It returns the number of
+// rewards expired and the points removed from balances.
+func (m *maintenanceRepo) ExpirePoints(before time.Time) (int64, int64, error) {
+	var expired, points int64
+	err := m.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Reward{}).
+			Where("created_at < ? AND balance > 0", before.Unix()).
+			Select("COALESCE(SUM(balance), 0)").
+			Scan(&points).Error; err != nil {
+			return err
+		}
+
+		result := tx.Model(&models.Reward{}).
+			Where("created_at < ? AND balance > 0", before.Unix()).
+			Updates(map[string]interface{}{"balance": 0, "updated_at": time.Now().Unix()})
+		expired = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return expired, points, nil
+}
+
+// PurgeSoftDeleted permanently removes rows marked deleted before the cutoff
+// and returns the number removed per table.
+func (m *maintenanceRepo) PurgeSoftDeleted(before time.Time) (map[string]int64, error) {
+	purged := map[string]int64{}
+	for _, model := range softDeletable {
+		stmt := &gorm.Statement{DB: m.DB}
+		if err := stmt.Parse(model); err != nil {
+			return purged, err
+		}
+
+		result := m.DB.Where("deleted_at > 0 AND deleted_at < ?", before.Unix()).Delete(model)
+		if result.Error != nil {
+			return purged, fmt.Errorf("purging %s: %w", stmt.Schema.Table, result.Error)
+		}
+		purged[stmt.Schema.Table] = result.RowsAffected
+	}
+	return purged, nil
+}
+
+// RequeueFailedWebhooks marks failed webhook deliveries as pending so the
+// delivery worker picks them up again.
+func (m *maintenanceRepo) RequeueFailedWebhooks() (int64, error) {
+	if !m.DB.Migrator().HasTable("webhook_deliveries") {
+		return 0, nil
+	}
+
+	result := m.DB.Exec(`
+        UPDATE webhook_deliveries
+        SET status = 'pending', attempts = 0, next_attempt_at = NOW()
+        WHERE status = 'failed'
+    `)
+	return result.RowsAffected, result.Error
+}
+
+// BackfillPlusCodes sets the plus code of reports submitted before codes
+// were stored. Reports whose location was coarsened get a code no more
+// precise than their coordinates.
+func (m *maintenanceRepo) BackfillPlusCodes() (int64, error) {
+	var filled int64
+	var batch []models.IncidentReport
+	err := m.DB.Select("id", "latitude", "longitude", "location_coarsened").
+		Where("plus_code = '' AND (latitude <> 0 OR longitude <> 0)").
+		FindInBatches(&batch, 500, func(tx *gorm.DB, _ int) error {
+			for _, report := range batch {
+				length := geo.PlusCodeLength
+				if report.LocationCoarsened {
+					length = CoarsePlusCodeLength
+				}
+				code := geo.EncodePlusCode(report.Latitude, report.Longitude, length)
+				if err := m.DB.Model(&models.IncidentReport{}).Where("id = ?", report.ID).
+					Update("plus_code", code).Error; err != nil {
+					return err
+				}
+				filled++
+			}
+			return nil
+		}).Error
+	return filled, err
+}
+
+// piiColumns lists the encrypted columns, with the blind index kept beside
+// those that are looked up.
+var piiColumns = []struct {
+	model         interface{}
+	column, index string
+}{
+	{&models.User{}, "telephone", "telephone_index"},
+	{&models.User{}, "mac_address", "mac_address_index"},
+	{&models.LoginRequestMacAddress{}, "mac_address", "mac_address_index"},
+	{&models.PhoneOTP{}, "phone", "phone_index"},
+	{&models.IncidentReport{}, "telephone", ""},
+	{&models.IncidentReport{}, "email", ""},
+	{&models.ReportDraft{}, "telephone", ""},
+	{&models.ReportDraft{}, "email", ""},
+}
+
+// EncryptPII seals every encrypted column not yet sealed with the active
+// key, including plaintext written before encryption was enabled, and
+// fills missing blind indexes. It returns the rows changed per column.
+func (m *maintenanceRepo) EncryptPII() (map[string]int64, error) {
+	if !pii.Enabled() {
+		return nil, errors.New("no pii encryption key is configured")
+	}
+	changed := map[string]int64{}
+	for _, c := range piiColumns {
+		stmt := &gorm.Statement{DB: m.DB}
+		if err := stmt.Parse(c.model); err != nil {
+			return changed, err
+		}
+		table := stmt.Schema.Table
+		stale := c.column + " <> '' AND " + c.column + " NOT LIKE @sealed"
+		if c.index != "" {
+			stale = "(" + stale + ") OR (" + c.column + " <> '' AND " + c.index + " IS NULL)"
+		}
+
+		// Rows leave the stale set as they are rewritten, so each pass
+		// takes the next batch from the start
+		for {
+			var rows []map[string]interface{}
+			err := m.DB.Table(table).Select("id", c.column).
+				Where(stale, map[string]interface{}{"sealed": pii.ActivePrefix() + "%"}).
+				Limit(500).Find(&rows).Error
+			if err != nil {
+				return changed, fmt.Errorf("reading %s.%s: %w", table, c.column, err)
+			}
+			if len(rows) == 0 {
+				break
+			}
+			for _, row := range rows {
+				var value string
+				switch v := row[c.column].(type) {
+				case string:
+					value = v
+				case []byte:
+					value = string(v)
+				}
+				plaintext, err := pii.Decrypt(value)
+				if err != nil {
+					return changed, fmt.Errorf("opening %s.%s of row %v: %w", table, c.column, row["id"], err)
+				}
+				sealed, err := pii.Encrypt(plaintext)
+				if err != nil {
+					return changed, err
+				}
+				updates := map[string]interface{}{c.column: sealed}
+				if c.index != "" {
+					updates[c.index] = pii.BlindIndex(plaintext)
+				}
+				if err := m.DB.Table(table).Where("id = ?", row["id"]).UpdateColumns(updates).Error; err != nil {
+					return changed, fmt.Errorf("writing %s.%s of row %v: %w", table, c.column, row["id"], err)
+				}
+				changed[table+"."+c.column]++
+			}
+		}
+	}
+	return changed, nil
+}
+
+// BackfillPublicIDs gives an external ID to every record created before
+// they were assigned, in batches so no table is locked for long. It returns
+// the rows filled per table.
+func (m *maintenanceRepo) BackfillPublicIDs() (map[string]int64, error) {
+	filled := map[string]int64{}
+	for _, model := range publicIDModels {
+		stmt := &gorm.Statement{DB: m.DB}
+		if err := stmt.Parse(model); err != nil {
+			return filled, err
+		}
+		table := stmt.Schema.Table
+		for {
+			result := m.DB.Exec("UPDATE " + table + " SET public_id = uuid_generate_v4() WHERE id IN (SELECT id FROM " + table + " WHERE public_id IS NULL LIMIT 1000)")
+			if result.Error != nil {
+				return filled, fmt.Errorf("filling %s.public_id: %w", table, result.Error)
+			}
+			if result.RowsAffected == 0 {
+				break
+			}
+			filled[table] += result.RowsAffected
+		}
+	}
+	return filled, nil
+}
@@ -17,6 +17,13 @@ type MediaRepository interface {
 	GetMediaCountByByUserID(userID uint) (int, error)
 	CreateMediaCount(mediaCount *models.MediaCount) error
 	UploadMediaToS3(file multipart.File, fileHeader *multipart.FileHeader, bucketName, folderName string) (string, error)
+	FlagReusedMedia(media models.Media, maxDistance int) ([]models.MediaReuseFlag, error)
+	ListMediaReuseFlags(page int) ([]models.MediaReuseFlag, error)
+	GetMediaByReportID(reportID string) ([]models.Media, error)
+	GetMediaByID(mediaID string) (*models.Media, error)
+	SetMediaGraphic(mediaID string, graphic bool, by string) error
+	MarkCategoryGraphic(reportID string) error
+	DeleteMediaExcept(reportID string, keep []string) error
 }
 
 type mediaRepo struct {
@@ -33,8 +40,9 @@ func NewMediaRepo(db *GormDB) MediaRepository {
 }
 
 func (m *mediaRepo) SaveMedia(media models.Media, reportID string, userID uint) error {
-	ID := uuid.New()
-	media.ID = ID.String()
+	if media.ID == "" {
+		media.ID = uuid.New().String()
+	}
 	media.UserID = userID
 
 	// Call the reward calculation and saving function
@@ -128,3 +136,98 @@ func (repo *mediaRepo) UploadMediaToS3(file multipart.File, fileHeader *multipar
 
 	return fileURL, nil
 }
+
+// hammingSQL counts the bits that differ between the phash column and a hash
+// parameter. It avoids bit_count so it runs on Postgres versions before 14.
+const hammingSQL = "length(replace(((media.phash # ?)::bit(64))::text, '0', ''))"
+
+// FlagReusedMedia records a flag for every image on another report whose
+// perceptual hash is within maxDistance bits of the media's hash.
+func (m *mediaRepo) FlagReusedMedia(media models.Media, maxDistance int) ([]models.MediaReuseFlag, error) {
+	if media.PHash == nil {
+		return nil, nil
+	}
+
+	var matches []struct {
+		ID               string
+		IncidentReportID string
+		Distance         int
+	}
+	err := m.DB.Table("media").
+		Select("media.id, media.incident_report_id, "+hammingSQL+" AS distance", *media.PHash).
+		Where("media.phash IS NOT NULL AND media.incident_report_id NOT IN ?", []string{media.IncidentReportID.String(), uuid.Nil.String()}).
+		Where(hammingSQL+" <= ?", *media.PHash, maxDistance).
+		Order("distance").
+		Limit(DefaultPageSize).
+		Scan(&matches).Error
+	if err != nil {
+		return nil, fmt.Errorf("finding similar media: %w", err)
+	}
+	if len(matches) == 0 {
+		return nil, nil
+	}
+
+	flags := make([]models.MediaReuseFlag, len(matches))
+	for i, match := range matches {
+		flags[i] = models.MediaReuseFlag{
+			IncidentReportID: media.IncidentReportID.String(),
+			MediaID:          media.ID,
+			MatchedReportID:  match.IncidentReportID,
+			MatchedMediaID:   match.ID,
+			Distance:         match.Distance,
+		}
+	}
+	if err := m.DB.Create(&flags).Error; err != nil {
+		return nil, fmt.Errorf("saving media reuse flags: %w", err)
+	}
+	return flags, nil
+}
+
+// ListMediaReuseFlags returns the newest media reuse flags first.
+func (m *mediaRepo) ListMediaReuseFlags(page int) ([]models.MediaReuseFlag, error) {
+	var flags []models.MediaReuseFlag
+	err := m.DB.Order("created_at DESC, id DESC").
+		Offset((page - 1) * DefaultPageSize).
+		Limit(DefaultPageSize).
+		Find(&flags).Error
+	return flags, err
+}
+
+// GetMediaByReportID returns every media record attached to a report.
+func (m *mediaRepo) GetMediaByReportID(reportID string) ([]models.Media, error) {
+	var media []models.Media
+	err := m.DB.Where("incident_report_id = ?", reportID).Order("id").Find(&media).Error
+	return media, err
+}
+
+func (m *mediaRepo) GetMediaByID(mediaID string) (*models.Media, error) {
+	var media models.Media
+	if err := m.DB.Where("id = ?", mediaID).First(&media).Error; err != nil {
+		return nil, err
+	}
+	return &media, nil
+}
+
+// SetMediaGraphic records whether media is graphic and who decided
+func (m *mediaRepo) SetMediaGraphic(mediaID string, graphic bool, by string) error {
+	return m.DB.Model(&models.Media{}).Where("id = ?", mediaID).
+		Updates(map[string]interface{}{"graphic": graphic, "graphic_by": by}).Error
+}
+
+// MarkCategoryGraphic marks a report's media graphic by default, leaving
+// media a reporter or moderator has already decided on
+func (m *mediaRepo) MarkCategoryGraphic(reportID string) error {
+	return m.DB.Model(&models.Media{}).
+		Where("incident_report_id = ? AND COALESCE(graphic_by, '') = ''", reportID).
+		Updates(map[string]interface{}{"graphic": true, "graphic_by": models.MediaGraphicByCategory}).Error
+}
+
+// DeleteMediaExcept removes the records of a report's media other than the
+// media listed. The stored files stay among the report's objects.
+func (m *mediaRepo) DeleteMediaExcept(reportID string, keep []string) error {
+	query := m.DB.Where("incident_report_id = ?", reportID)
+	if len(keep) > 0 {
+		query = query.Where("id NOT IN ?", keep)
+	}
+	return query.Delete(&models.Media{}).Error
+}
@@ -0,0 +1,68 @@
+package db
+
+import (
+	"time"
+
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+// ModerationRepository records moderation decisions and measures the
+// moderators who make them
+type ModerationRepository interface {
+	RecordDecision(decision *models.ModerationDecision) error
+	ModeratorPerformance(start, end time.Time) ([]models.ModeratorPerformance, error)
+}
+
+type moderationRepo struct {
+	DB *gorm.DB
+}
+
+func NewModerationRepo(db *GormDB) ModerationRepository {
+	return &moderationRepo{db.DB}
+}
+
+// RecordDecision saves a decision, marking the report's standing decision
+// as overturned when the new one reaches a different outcome
+func (m *moderationRepo) RecordDecision(decision *models.ModerationDecision) error {
+	return m.DB.Transaction(func(tx *gorm.DB) error {
+		var standing models.ModerationDecision
+		err := tx.Where("report_id = ? AND overturned_at IS NULL", decision.ReportID).
+			Order("decided_at DESC, id DESC").
+			First(&standing).Error
+		switch {
+		case err == nil && standing.Decision != decision.Decision:
+			if err := tx.Model(&standing).Updates(map[string]interface{}{
+				"overturned_at": decision.DecidedAt,
+				"overturned_by": decision.ModeratorID,
+			}).Error; err != nil {
+				return err
+			}
+		case err != nil && err != gorm.ErrRecordNotFound:
+			return err
+		}
+		return tx.Create(decision).Error
+	})
+}
+
+// ModeratorPerformance summarises the decisions made between start and end
+// by each moderator, busiest first. Overturns count against the original
+// decision whenever they happened.
+func (m *moderationRepo) ModeratorPerformance(start, end time.Time) ([]models.ModeratorPerformance, error) {
+	var performance []models.ModeratorPerformance
+	err := m.DB.Table("moderation_decisions").
+		Select(`moderation_decisions.moderator_id,
+			COALESCE(users.fullname, '') AS fullname,
+			COUNT(*) AS decisions,
+			COUNT(*) FILTER (WHERE moderation_decisions.decision = 'approved') AS approved,
+			COUNT(*) FILTER (WHERE moderation_decisions.decision = 'accepted') AS accepted,
+			COUNT(*) FILTER (WHERE moderation_decisions.decision = 'rejected') AS rejected,
+			PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY moderation_decisions.review_seconds) AS median_review_seconds,
+			COUNT(moderation_decisions.overturned_at) AS overturned`).
+		Joins("LEFT JOIN users ON users.id = moderation_decisions.moderator_id").
+		Where("moderation_decisions.decided_at >= ? AND moderation_decisions.decided_at < ?", start.Unix(), end.Unix()).
+		Group("moderation_decisions.moderator_id, users.fullname").
+		Order("decisions DESC").
+		Scan(&performance).Error
+	return performance, err
+}
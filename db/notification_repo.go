@@ -0,0 +1,156 @@
+package db
+
+import (
+	"errors"
+	"time"
+
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NotificationRepository persists the notifications shown to users
+type NotificationRepository interface {
+	CreateNotification(notification *models.Notification) (bool, error)
+	ListNotifications(userID uint, page int) ([]models.Notification, error)
+	MarkRead(userID, notificationID uint) error
+	GetPreferences(userID uint) ([]models.NotificationPreference, error)
+	SavePreferences(prefs []models.NotificationPreference) error
+	GetQuietHours(userID uint) (*models.QuietHours, error)
+	SaveQuietHours(hours *models.QuietHours) error
+	QueueNotification(pending *models.PendingNotification) error
+	PendingBatchTime(userID uint, reportID string) (int64, error)
+	DuePendingNotifications(now time.Time, limit int) ([]models.PendingNotification, error)
+	DeletePendingNotifications(ids []uint) error
+	UserLocale(userID uint) (string, error)
+	Deactivated(userID uint) (bool, error)
+}
+
+type notificationRepo struct {
+	DB *gorm.DB
+}
+
+// NewNotificationRepo creates a new instance of NotificationRepository
+func NewNotificationRepo(db *GormDB) NotificationRepository {
+	return &notificationRepo{db.DB}
+}
+
+// CreateNotification saves a notification, ignoring one whose dedup key has
+// already been used, and reports whether it was saved.
+func (r *notificationRepo) CreateNotification(notification *models.Notification) (bool, error) {
+	result := r.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(notification)
+	return result.RowsAffected > 0, result.Error
+}
+
+// ListNotifications returns the user's newest notifications first
+func (r *notificationRepo) ListNotifications(userID uint, page int) ([]models.Notification, error) {
+	var notifications []models.Notification
+	err := r.DB.Where("user_id = ?", userID).
+		Order("id DESC").
+		Offset((page - 1) * DefaultPageSize).
+		Limit(DefaultPageSize).
+		Find(&notifications).Error
+	return notifications, err
+}
+
+// MarkRead marks one of the user's notifications read, returning
+// gorm.ErrRecordNotFound when the user has no such notification
+func (r *notificationRepo) MarkRead(userID, notificationID uint) error {
+	result := r.DB.Model(&models.Notification{}).
+		Where("id = ? AND user_id = ?", notificationID, userID).
+		Update("is_read", true)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetPreferences returns the notification choices the user has made
+func (r *notificationRepo) GetPreferences(userID uint) ([]models.NotificationPreference, error) {
+	var prefs []models.NotificationPreference
+	err := r.DB.Where("user_id = ?", userID).Find(&prefs).Error
+	return prefs, err
+}
+
+// SavePreferences stores notification choices, replacing earlier ones for
+// the same channel and category
+func (r *notificationRepo) SavePreferences(prefs []models.NotificationPreference) error {
+	if len(prefs) == 0 {
+		return nil
+	}
+	return r.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "channel"}, {Name: "category"}},
+		DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_at"}),
+	}).Create(&prefs).Error
+}
+
+// GetQuietHours returns the user's quiet hours, or nil when they have not set
+// any
+func (r *notificationRepo) GetQuietHours(userID uint) (*models.QuietHours, error) {
+	var hours models.QuietHours
+	err := r.DB.Where("user_id = ?", userID).First(&hours).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &hours, nil
+}
+
+func (r *notificationRepo) SaveQuietHours(hours *models.QuietHours) error {
+	return r.DB.Save(hours).Error
+}
+
+func (r *notificationRepo) QueueNotification(pending *models.PendingNotification) error {
+	return r.DB.Create(pending).Error
+}
+
+// PendingBatchTime returns when the user's queued notifications about a
+// report go out, or 0 when none are queued
+func (r *notificationRepo) PendingBatchTime(userID uint, reportID string) (int64, error) {
+	var deliverAfter int64
+	err := r.DB.Model(&models.PendingNotification{}).
+		Select("COALESCE(MIN(deliver_after), 0)").
+		Where("user_id = ? AND report_id = ?", userID, reportID).
+		Scan(&deliverAfter).Error
+	return deliverAfter, err
+}
+
+// DuePendingNotifications returns queued notifications ready to go out,
+// grouped by user in the order they were raised
+func (r *notificationRepo) DuePendingNotifications(now time.Time, limit int) ([]models.PendingNotification, error) {
+	var pending []models.PendingNotification
+	err := r.DB.Where("deliver_after <= ?", now.Unix()).
+		Order("user_id ASC, id ASC").
+		Limit(limit).
+		Find(&pending).Error
+	return pending, err
+}
+
+func (r *notificationRepo) DeletePendingNotifications(ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.DB.Where("id IN ?", ids).Delete(&models.PendingNotification{}).Error
+}
+
+// UserLocale returns the locale the user chose, or "" for the default
+func (r *notificationRepo) UserLocale(userID uint) (string, error) {
+	var locales []string
+	err := r.DB.Model(&models.User{}).Where("id = ?", userID).Pluck("locale", &locales).Error
+	if err != nil || len(locales) == 0 {
+		return "", err
+	}
+	return locales[0], nil
+}
+
+// Deactivated reports whether the user has deactivated their account
+func (r *notificationRepo) Deactivated(userID uint) (bool, error) {
+	var count int64
+	err := r.DB.Model(&models.User{}).Where("id = ? AND deactivated_at IS NOT NULL", userID).Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,64 @@
+package db
+
+import (
+	"time"
+
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ObjectRepository keeps the ownership record of every stored object
+type ObjectRepository interface {
+	TrackObject(object *models.StoredObject) error
+	OrphanedObjects(before time.Time, limit int) ([]models.StoredObject, error)
+	ForgetObject(key string) error
+}
+
+type objectRepo struct {
+	DB *gorm.DB
+}
+
+func NewObjectRepo(db *GormDB) ObjectRepository {
+	return &objectRepo{db.DB}
+}
+
+// TrackObject records an object, replacing the record of an object
+// previously stored under the same key
+func (o *objectRepo) TrackObject(object *models.StoredObject) error {
+	return o.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{"url", "size", "owner_type", "owner_id", "created_at"}),
+	}).Create(object).Error
+}
+
+// OrphanedObjects returns objects stored before the cutoff whose owner no
+// longer exists or no longer refers to them: media of reports that were
+// purged or drafts that were never submitted, chunks of finished or
+// abandoned uploads, replaced profile and post images, and screenshots no
+// feedback refers to. Reports that are only marked deleted still own their
+// media so they can be restored.
+func (o *objectRepo) OrphanedObjects(before time.Time, limit int) ([]models.StoredObject, error) {
+	var objects []models.StoredObject
+	err := o.DB.Where("created_at < ?", before.Unix()).
+		Where(o.DB.
+			Where("owner_type = ? AND owner_id NOT IN (?) AND owner_id NOT IN (?)", models.ObjectOwnerReport,
+				o.DB.Model(&models.IncidentReport{}).Select("id::text"),
+				o.DB.Model(&models.ReportDraft{}).Select("id::text")).
+			Or("owner_type = ? AND owner_id NOT IN (?)", models.ObjectOwnerUpload,
+				o.DB.Model(&models.Upload{}).Select("id::text").Where("completed_at = 0")).
+			Or("owner_type = ? AND url NOT IN (?)", models.ObjectOwnerUser,
+				o.DB.Model(&models.User{}).Select("thumb_nail_url").Where("thumb_nail_url IS NOT NULL")).
+			Or("owner_type = ? AND url NOT IN (?)", models.ObjectOwnerPost,
+				o.DB.Model(&models.Post{}).Select("image").Where("image IS NOT NULL")).
+			Or("owner_type = ? AND key NOT IN (?)", models.ObjectOwnerFeedback,
+				o.DB.Model(&models.Feedback{}).Select("screenshot_key"))).
+		Order("created_at ASC").
+		Limit(limit).
+		Find(&objects).Error
+	return objects, err
+}
+
+func (o *objectRepo) ForgetObject(key string) error {
+	return o.DB.Where("key = ?", key).Delete(&models.StoredObject{}).Error
+}
@@ -0,0 +1,99 @@
+package db
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/techagentng/citizenx/events"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OutboxRepository hands saved domain events to the relay
+type OutboxRepository interface {
+	Enqueue(evts ...events.Event) error
+	RelayPending(limit int, relay func(event *models.OutboxEvent) error) (int, error)
+}
+
+type outboxRepo struct {
+	DB *gorm.DB
+}
+
+func NewOutboxRepo(db *GormDB) OutboxRepository {
+	return &outboxRepo{db.DB}
+}
+
+// writeOutbox records events in tx. Repositories call it inside the
+// transaction that saves the change the events describe, so either both are
+// stored or neither is. An event already in the outbox is skipped.
+func writeOutbox(tx *gorm.DB, evts ...events.Event) error {
+	for _, event := range evts {
+		payload, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		row := models.OutboxEvent{
+			EventName:     event.EventName(),
+			DedupKey:      event.DedupKey(),
+			Payload:       string(payload),
+			NextAttemptAt: time.Now(),
+		}
+		if err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "dedup_key"}}, DoNothing: true}).
+			Create(&row).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Enqueue records events that are not tied to any other write.
+func (o *outboxRepo) Enqueue(evts ...events.Event) error {
+	return writeOutbox(o.DB, evts...)
+}
+
+// RelayPending locks up to limit events that are due, passes each to relay
+// and records the outcome. Events relay fails on are retried with
+// exponential backoff. Locked rows are skipped, so several relays can run
+// side by side. It returns the number of events relayed successfully.
+func (o *outboxRepo) RelayPending(limit int, relay func(event *models.OutboxEvent) error) (int, error) {
+	relayed := 0
+	err := o.DB.Transaction(func(tx *gorm.DB) error {
+		var pending []models.OutboxEvent
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("published_at IS NULL AND next_attempt_at <= ?", time.Now()).
+			Order("id").
+			Limit(limit).
+			Find(&pending).Error; err != nil {
+			return err
+		}
+
+		for i := range pending {
+			event := &pending[i]
+			now := time.Now()
+			if err := relay(event); err != nil {
+				event.Attempts++
+				event.LastError = err.Error()
+				event.NextAttemptAt = now.Add(outboxBackoff(event.Attempts))
+			} else {
+				event.PublishedAt = &now
+				event.LastError = ""
+				relayed++
+			}
+			if err := tx.Save(event).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return relayed, err
+}
+
+// outboxBackoff doubles the retry delay with each attempt, capped at an hour.
+func outboxBackoff(attempts int) time.Duration {
+	delay := time.Second << uint(attempts)
+	if attempts > 12 || delay > time.Hour {
+		return time.Hour
+	}
+	return delay
+}
@@ -0,0 +1,269 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PayoutRepository stores bank accounts and the payouts sent to them
+type PayoutRepository interface {
+	GetBankAccount(userID uint) (*models.BankAccount, error)
+	SaveBankAccount(account *models.BankAccount) error
+	CreateBatch(batch *models.PayoutBatch, userIDs []uint, minimumPoints, pointValueKobo int64) error
+	ListBatches(page int) ([]models.PayoutBatch, error)
+	GetBatch(id uint) (*models.PayoutBatch, error)
+	BatchPayouts(batchID uint) ([]models.Payout, error)
+	UserPayouts(userID uint, page int) ([]models.Payout, error)
+	ClaimPayouts(maxAttempts, limit int, staleBefore, at int64) ([]models.Payout, error)
+	MarkSent(id uint, providerReference string, at int64) error
+	RecordSendFailure(id uint, reason string, maxAttempts int, at int64) error
+	SettlePayout(reference, status, reason string, at int64) (*models.Payout, bool, error)
+}
+
+type payoutRepo struct {
+	DB *gorm.DB
+}
+
+func NewPayoutRepo(db *GormDB) PayoutRepository {
+	return &payoutRepo{db.DB}
+}
+
+func (p *payoutRepo) GetBankAccount(userID uint) (*models.BankAccount, error) {
+	var account models.BankAccount
+	if err := p.DB.First(&account, "user_id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// SaveBankAccount sets the user's bank account, replacing any before it
+func (p *payoutRepo) SaveBankAccount(account *models.BankAccount) error {
+	return p.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"bank_code", "bank_name", "account_number", "last4", "account_name", "updated_at"}),
+	}).Create(account).Error
+}
+
+// CreateBatch creates the batch with a payout of the whole balance of each
+// user with a bank account and at least minimumPoints, taking the points
+// off in the same transaction. Users are locked while their balance is
+// read, so points cannot be paid out twice.
+func (p *payoutRepo) CreateBatch(batch *models.PayoutBatch, userIDs []uint, minimumPoints, pointValueKobo int64) error {
+	return p.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(batch).Error; err != nil {
+			return err
+		}
+		for _, userID := range userIDs {
+			var locked []uint
+			if err := tx.Model(&models.User{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("id = ?", userID).Pluck("id", &locked).Error; err != nil {
+				return err
+			}
+			if len(locked) == 0 {
+				continue
+			}
+			var account models.BankAccount
+			err := tx.First(&account, "user_id = ?", userID).Error
+			if err == gorm.ErrRecordNotFound {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			var balance int64
+			if err := tx.Raw(`SELECT
+				COALESCE((SELECT SUM(point) FROM rewards WHERE user_id = ? AND deleted_at = 0), 0) -
+				COALESCE((SELECT SUM(points) FROM point_debits WHERE user_id = ?), 0)`,
+				userID, userID).Scan(&balance).Error; err != nil {
+				return err
+			}
+			if balance <= 0 || balance < minimumPoints {
+				continue
+			}
+
+			payout := models.Payout{
+				BatchID:    batch.ID,
+				UserID:     userID,
+				Reference:  fmt.Sprintf("payout-%d-%d", batch.ID, userID),
+				Points:     balance,
+				AmountKobo: balance * pointValueKobo,
+				BankName:   account.BankName,
+				Last4:      account.Last4,
+				Status:     models.PayoutPending,
+				CreatedAt:  batch.CreatedAt,
+				UpdatedAt:  batch.CreatedAt,
+			}
+			if err := tx.Create(&payout).Error; err != nil {
+				return err
+			}
+			if err := tx.Create(&models.PointDebit{
+				UserID:      userID,
+				Kind:        models.PointsRedeemed,
+				Points:      balance,
+				Reference:   payout.Reference,
+				Description: "Paid out: " + batch.Program,
+				CreatedAt:   batch.CreatedAt,
+			}).Error; err != nil {
+				return err
+			}
+			batch.Payouts++
+			batch.TotalKobo += payout.AmountKobo
+		}
+		if batch.Payouts == 0 {
+			batch.Status = models.PayoutBatchCompleted
+			batch.FinishedAt = batch.CreatedAt
+		}
+		return tx.Save(batch).Error
+	})
+}
+
+// ListBatches returns the newest batches first
+func (p *payoutRepo) ListBatches(page int) ([]models.PayoutBatch, error) {
+	var batches []models.PayoutBatch
+	err := p.DB.Order("created_at DESC, id DESC").
+		Offset((page - 1) * DefaultPageSize).
+		Limit(DefaultPageSize).
+		Find(&batches).Error
+	return batches, err
+}
+
+func (p *payoutRepo) GetBatch(id uint) (*models.PayoutBatch, error) {
+	var batch models.PayoutBatch
+	if err := p.DB.First(&batch, id).Error; err != nil {
+		return nil, err
+	}
+	return &batch, nil
+}
+
+func (p *payoutRepo) BatchPayouts(batchID uint) ([]models.Payout, error) {
+	var payouts []models.Payout
+	err := p.DB.Where("batch_id = ?", batchID).Order("id").Find(&payouts).Error
+	return payouts, err
+}
+
+// UserPayouts returns a user's payouts, newest first
+func (p *payoutRepo) UserPayouts(userID uint, page int) ([]models.Payout, error) {
+	var payouts []models.Payout
+	err := p.DB.Where("user_id = ?", userID).
+		Order("created_at DESC, id DESC").
+		Offset((page - 1) * DefaultPageSize).
+		Limit(DefaultPageSize).
+		Find(&payouts).Error
+	return payouts, err
+}
+
+// ClaimPayouts claims the oldest payouts waiting to be sent, marking them
+// sending and counting the attempt, so two senders never send the same
+// payout. Payouts left sending since before staleBefore, by a sender that
+// stopped before learning how its attempt went, are claimed again. Locked
+// rows are skipped, so several senders can run side by side. The payouts
+// are returned as they were before being claimed.
+func (p *payoutRepo) ClaimPayouts(maxAttempts, limit int, staleBefore, at int64) ([]models.Payout, error) {
+	var payouts []models.Payout
+	err := p.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("(status = ? AND attempts < ?) OR (status = ? AND updated_at < ?)",
+				models.PayoutPending, maxAttempts, models.PayoutSending, staleBefore).
+			Order("created_at ASC, id ASC").
+			Limit(limit).
+			Find(&payouts).Error; err != nil {
+			return err
+		}
+		if len(payouts) == 0 {
+			return nil
+		}
+		ids := make([]uint, len(payouts))
+		for i, payout := range payouts {
+			ids[i] = payout.ID
+		}
+		return tx.Model(&models.Payout{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+			"status":     models.PayoutSending,
+			"attempts":   gorm.Expr("attempts + 1"),
+			"updated_at": at,
+		}).Error
+	})
+	return payouts, err
+}
+
+func (p *payoutRepo) MarkSent(id uint, providerReference string, at int64) error {
+	return p.DB.Model(&models.Payout{}).Where("id = ? AND status = ?", id, models.PayoutSending).
+		Updates(map[string]interface{}{
+			"status":             models.PayoutSent,
+			"provider_reference": providerReference,
+			"updated_at":         at,
+		}).Error
+}
+
+// RecordSendFailure puts a claimed payout the provider is known not to have
+// back to be sent again, failing it once maxAttempts have been made
+func (p *payoutRepo) RecordSendFailure(id uint, reason string, maxAttempts int, at int64) error {
+	var payout models.Payout
+	if err := p.DB.First(&payout, id).Error; err != nil {
+		return err
+	}
+	if payout.Status != models.PayoutSending {
+		return nil
+	}
+	if payout.Attempts >= maxAttempts {
+		_, _, err := p.SettlePayout(payout.Reference, models.PayoutFailed, reason, at)
+		return err
+	}
+	return p.DB.Model(&models.Payout{}).Where("id = ? AND status = ?", id, models.PayoutSending).
+		Updates(map[string]interface{}{
+			"status":         models.PayoutPending,
+			"failure_reason": reason,
+			"updated_at":     at,
+		}).Error
+}
+
+// SettlePayout records how a payout ended, reporting whether that changed
+// it. Failed and reversed payouts give the user their points back. The
+// batch is completed with its last payout. Payouts already failed or
+// reversed are left as they are, since providers may notify more than once.
+func (p *payoutRepo) SettlePayout(reference, status, reason string, at int64) (*models.Payout, bool, error) {
+	var payout models.Payout
+	changed := false
+	err := p.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&payout, "reference = ?", reference).Error; err != nil {
+			return err
+		}
+		if payout.Status == status || payout.Status == models.PayoutFailed || payout.Status == models.PayoutReversed {
+			return nil
+		}
+		updates := map[string]interface{}{"status": status, "failure_reason": reason, "updated_at": at}
+		if status == models.PayoutPaid {
+			updates["paid_at"] = at
+		}
+		if err := tx.Model(&payout).Updates(updates).Error; err != nil {
+			return err
+		}
+		payout.Status, payout.FailureReason = status, reason
+		changed = true
+		if status == models.PayoutFailed || status == models.PayoutReversed {
+			if err := tx.Where("reference = ? AND kind = ?", reference, models.PointsRedeemed).
+				Delete(&models.PointDebit{}).Error; err != nil {
+				return err
+			}
+		}
+
+		var open int64
+		if err := tx.Model(&models.Payout{}).
+			Where("batch_id = ? AND status IN ?", payout.BatchID, []string{models.PayoutPending, models.PayoutSending, models.PayoutSent, models.PayoutNeedsOTP}).
+			Count(&open).Error; err != nil {
+			return err
+		}
+		if open == 0 {
+			return tx.Model(&models.PayoutBatch{}).Where("id = ? AND status = ?", payout.BatchID, models.PayoutBatchProcessing).
+				Updates(map[string]interface{}{"status": models.PayoutBatchCompleted, "finished_at": at}).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return &payout, changed, nil
+}
@@ -0,0 +1,110 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/pii"
+	"gorm.io/gorm"
+)
+
+// PhoneLoginRepository persists the one-time codes of phone sign-in and the
+// accounts it creates
+type PhoneLoginRepository interface {
+	CreateOTP(otp *models.PhoneOTP) error
+	LatestOTP(phone string) (*models.PhoneOTP, error)
+	CountOTPs(phone string, since int64) (int64, error)
+	CountIPOTPs(ip string, since int64) (int64, error)
+	ClaimAttempt(id uint, maxAttempts int) (bool, error)
+	UseOTP(id uint, at int64) (bool, error)
+	FindUserByPhone(numbers []string) (*models.User, error)
+	CreatePhoneUser(user *models.User) error
+	EmailInUse(email string) (bool, error)
+	CompleteAccount(userID uint, changes map[string]interface{}) (bool, error)
+}
+
+type phoneLoginRepo struct {
+	DB *gorm.DB
+}
+
+func NewPhoneLoginRepo(db *GormDB) PhoneLoginRepository {
+	return &phoneLoginRepo{db.DB}
+}
+
+func (r *phoneLoginRepo) CreateOTP(otp *models.PhoneOTP) error {
+	return r.DB.Create(otp).Error
+}
+
+// LatestOTP returns the code most recently sent to phone
+func (r *phoneLoginRepo) LatestOTP(phone string) (*models.PhoneOTP, error) {
+	var otp models.PhoneOTP
+	if err := r.DB.Where("phone_index = ?", pii.BlindIndex(phone)).Order("id DESC").First(&otp).Error; err != nil {
+		return nil, err
+	}
+	return &otp, nil
+}
+
+func (r *phoneLoginRepo) CountOTPs(phone string, since int64) (int64, error) {
+	var count int64
+	err := r.DB.Model(&models.PhoneOTP{}).Where("phone_index = ? AND created_at >= ?", pii.BlindIndex(phone), since).Count(&count).Error
+	return count, err
+}
+
+func (r *phoneLoginRepo) CountIPOTPs(ip string, since int64) (int64, error) {
+	var count int64
+	err := r.DB.Model(&models.PhoneOTP{}).Where("ip = ? AND created_at >= ?", ip, since).Count(&count).Error
+	return count, err
+}
+
+// ClaimAttempt counts a guess at an unused code, reporting false when the
+// code has no attempts left. The check and the count are one statement, so
+// parallel guesses cannot all slip under the limit.
+func (r *phoneLoginRepo) ClaimAttempt(id uint, maxAttempts int) (bool, error) {
+	result := r.DB.Model(&models.PhoneOTP{}).
+		Where("id = ? AND attempts < ? AND used_at = 0", id, maxAttempts).
+		Update("attempts", gorm.Expr("attempts + 1"))
+	return result.RowsAffected > 0, result.Error
+}
+
+// UseOTP marks a code used, reporting false when it already was so a code
+// cannot sign in twice
+func (r *phoneLoginRepo) UseOTP(id uint, at int64) (bool, error) {
+	result := r.DB.Model(&models.PhoneOTP{}).Where("id = ? AND used_at = 0", id).Update("used_at", at)
+	return result.RowsAffected > 0, result.Error
+}
+
+// FindUserByPhone returns the user whose telephone is any of numbers, the
+// ways one number may have been written
+func (r *phoneLoginRepo) FindUserByPhone(numbers []string) (*models.User, error) {
+	var user models.User
+	if err := r.DB.Where("telephone_index IN ?", pii.BlindIndexes(numbers)).Order("id").First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// CreatePhoneUser creates a phone-only account and names it after its ID,
+// which no other generated name can share
+func (r *phoneLoginRepo) CreatePhoneUser(user *models.User) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		user.Username = fmt.Sprintf("citizen%06d", user.ID)
+		return tx.Model(&models.User{}).Where("id = ?", user.ID).Update("username", user.Username).Error
+	})
+}
+
+func (r *phoneLoginRepo) EmailInUse(email string) (bool, error) {
+	var count int64
+	err := r.DB.Model(&models.User{}).Where("LOWER(email) = LOWER(?)", email).Count(&count).Error
+	return count > 0, err
+}
+
+// CompleteAccount applies changes to a phone-only account and marks it
+// complete, reporting false when it was not phone-only
+func (r *phoneLoginRepo) CompleteAccount(userID uint, changes map[string]interface{}) (bool, error) {
+	changes["phone_only"] = false
+	result := r.DB.Model(&models.User{}).Where("id = ? AND phone_only = ?", userID, true).Updates(changes)
+	return result.RowsAffected > 0, result.Error
+}
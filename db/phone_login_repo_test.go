@@ -0,0 +1,52 @@
+package db_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/testutil"
+)
+
+func TestClaimAttemptConcurrent(t *testing.T) {
+	// The guesses race on separate connections, so the code is committed
+	// rather than written in a test transaction
+	gormDB := testutil.DB(t)
+	repo := db.NewPhoneLoginRepo(&db.GormDB{DB: gormDB})
+
+	otp := &models.PhoneOTP{Phone: "+2348000000000", CodeHash: uniqueName("hash"), ExpiresAt: time.Now().Add(time.Minute).Unix()}
+	if err := repo.CreateOTP(otp); err != nil {
+		t.Fatalf("creating code: %v", err)
+	}
+	t.Cleanup(func() { gormDB.Delete(&models.PhoneOTP{}, otp.ID) })
+
+	var claimed int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ok, err := repo.ClaimAttempt(otp.ID, 5)
+			if err != nil {
+				t.Errorf("claiming attempt: %v", err)
+			}
+			if ok {
+				atomic.AddInt32(&claimed, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	if claimed != 5 {
+		t.Fatalf("%d of 20 parallel guesses were claimed, want 5", claimed)
+	}
+
+	if _, err := repo.UseOTP(otp.ID, time.Now().Unix()); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := repo.ClaimAttempt(otp.ID, 10); ok {
+		t.Fatal("claimed an attempt at a used code")
+	}
+}
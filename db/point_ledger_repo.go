@@ -0,0 +1,70 @@
+package db
+
+import (
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+// PointLedgerRepository reads users' points as a ledger: rewards earned
+// against points redeemed or expired
+type PointLedgerRepository interface {
+	BalanceBefore(userID uint, before int64) (int64, error)
+	Entries(userID uint, from, to int64) ([]models.RewardStatementLine, error)
+	CreateDebit(debit *models.PointDebit) error
+	ExpireEarnedBefore(cutoff, at int64, description string) (int64, error)
+}
+
+type pointLedgerRepo struct {
+	DB *gorm.DB
+}
+
+func NewPointLedgerRepo(db *GormDB) PointLedgerRepository {
+	return &pointLedgerRepo{db.DB}
+}
+
+// BalanceBefore returns the points a user had before the time given
+func (p *pointLedgerRepo) BalanceBefore(userID uint, before int64) (int64, error) {
+	var balance int64
+	err := p.DB.Raw(`SELECT
+		COALESCE((SELECT SUM(point) FROM rewards WHERE user_id = ? AND deleted_at = 0 AND created_at < ?), 0) -
+		COALESCE((SELECT SUM(points) FROM point_debits WHERE user_id = ? AND created_at < ?), 0)`,
+		userID, before, userID, before).Scan(&balance).Error
+	return balance, err
+}
+
+// Entries returns a user's rewards and debits in [from, to), oldest first,
+// without running balances
+func (p *pointLedgerRepo) Entries(userID uint, from, to int64) ([]models.RewardStatementLine, error) {
+	var lines []models.RewardStatementLine
+	err := p.DB.Raw(`SELECT created_at AS at, ? AS kind, reward_type AS description,
+			incident_report_id AS reference, point AS points
+		FROM rewards
+		WHERE user_id = ? AND deleted_at = 0 AND point <> 0 AND created_at >= ? AND created_at < ?
+		UNION ALL
+		SELECT created_at, kind, description, reference, -points
+		FROM point_debits
+		WHERE user_id = ? AND created_at >= ? AND created_at < ?
+		ORDER BY at, kind`,
+		models.PointsEarned, userID, from, to, userID, from, to).Scan(&lines).Error
+	return lines, err
+}
+
+func (p *pointLedgerRepo) CreateDebit(debit *models.PointDebit) error {
+	return p.DB.Create(debit).Error
+}
+
+// ExpireEarnedBefore expires, for every user, the points earned before
+// cutoff that nothing has taken off yet. Debits use up the oldest points
+// first, so those are the points earned before cutoff beyond everything
+// debited so far. It returns the number of users whose points expired.
+func (p *pointLedgerRepo) ExpireEarnedBefore(cutoff, at int64, description string) (int64, error) {
+	result := p.DB.Exec(`INSERT INTO point_debits (user_id, kind, points, description, created_at)
+		SELECT earned.user_id, ?, earned.points - COALESCE(debited.points, 0), ?, ?
+		FROM (SELECT user_id, SUM(point) AS points FROM rewards
+			WHERE deleted_at = 0 AND created_at < ? GROUP BY user_id) earned
+		LEFT JOIN (SELECT user_id, SUM(points) AS points FROM point_debits GROUP BY user_id) debited
+			ON debited.user_id = earned.user_id
+		WHERE earned.points > COALESCE(debited.points, 0)`,
+		models.PointsExpired, description, at, cutoff)
+	return result.RowsAffected, result.Error
+}
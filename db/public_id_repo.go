@@ -0,0 +1,42 @@
+package db
+
+import (
+	"github.com/google/uuid"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+// publicIDModels lists the models that carry an external ID
+var publicIDModels = []interface{}{
+	&models.User{},
+	&models.Post{},
+	&models.Agency{},
+	&models.Landmark{},
+	&models.RoadSegment{},
+}
+
+// PublicIDRepository looks records up by their external IDs
+type PublicIDRepository interface {
+	ResolveID(model interface{}, publicID uuid.UUID) (uint, error)
+}
+
+type publicIDRepo struct {
+	DB *gorm.DB
+}
+
+func NewPublicIDRepo(db *GormDB) PublicIDRepository {
+	return &publicIDRepo{db.DB}
+}
+
+// ResolveID returns the numeric ID of the record of model's type with the
+// external ID given, or gorm.ErrRecordNotFound
+func (p *publicIDRepo) ResolveID(model interface{}, publicID uuid.UUID) (uint, error) {
+	var ids []uint
+	if err := p.DB.Model(model).Where("public_id = ?", publicID).Limit(1).Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, gorm.ErrRecordNotFound
+	}
+	return ids[0], nil
+}
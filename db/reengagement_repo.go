@@ -0,0 +1,77 @@
+package db
+
+import (
+	"time"
+
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+// ReengagementRepository finds inactive users and the news that might bring
+// them back
+type ReengagementRepository interface {
+	InactiveUsers(inactiveSince, nudgedSince time.Time, afterID uint, limit int) ([]models.User, error)
+	RecentVerifiedReports(lgaName string, since time.Time, limit int) ([]models.IncidentReport, int64, error)
+	ExpiringPoints(userID uint, earnedBefore time.Time) (int64, error)
+	RecordNudge(nudge *models.ReengagementNudge) error
+}
+
+type reengagementRepo struct {
+	DB *gorm.DB
+}
+
+func NewReengagementRepo(db *GormDB) ReengagementRepository {
+	return &reengagementRepo{db.DB}
+}
+
+// InactiveUsers returns the next limit users after afterID who joined before
+// inactiveSince and have had no activity since, leaving out anyone nudged
+// since nudgedSince. Activity is read from the same sources as the growth
+// figures, so reports, votes, comments and bookmarks count even where no
+// activity event was recorded for them.
+func (r *reengagementRepo) InactiveUsers(inactiveSince, nudgedSince time.Time, afterID uint, limit int) ([]models.User, error) {
+	var users []models.User
+	err := r.DB.Scopes(activeUsers).
+		Where("users.id > ? AND users.created_at < ?", afterID, inactiveSince.Unix()).
+		Where("users.id NOT IN (SELECT DISTINCT user_id FROM ("+activitySource+") AS activity WHERE user_id IS NOT NULL AND occurred_at >= ?)", inactiveSince).
+		Where("users.id NOT IN (?)", r.DB.Model(&models.ReengagementNudge{}).
+			Select("user_id").
+			Where("sent_at >= ?", nudgedSince.Unix())).
+		Order("users.id ASC").
+		Limit(limit).
+		Find(&users).Error
+	return users, err
+}
+
+// RecentVerifiedReports returns the newest reports verified in an LGA since
+// the given time, up to limit, and how many there are in all
+func (r *reengagementRepo) RecentVerifiedReports(lgaName string, since time.Time, limit int) ([]models.IncidentReport, int64, error) {
+	verified := func() *gorm.DB {
+		return r.DB.Model(&models.IncidentReport{}).
+			Where("lga_name ILIKE ? AND LOWER(report_status) IN ? AND created_at >= ?",
+				lgaName, models.PublishedReportStatuses, since.Unix())
+	}
+
+	var total int64
+	if err := verified().Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	var reports []models.IncidentReport
+	err := verified().Order("created_at DESC").Limit(limit).Find(&reports).Error
+	return reports, total, err
+}
+
+// ExpiringPoints sums the unspent balance of the user's rewards earned
+// before the cutoff
+func (r *reengagementRepo) ExpiringPoints(userID uint, earnedBefore time.Time) (int64, error) {
+	var points int64
+	err := r.DB.Model(&models.Reward{}).
+		Where("user_id = ? AND created_at < ? AND balance > 0", userID, earnedBefore.Unix()).
+		Select("COALESCE(SUM(balance), 0)").
+		Scan(&points).Error
+	return points, err
+}
+
+func (r *reengagementRepo) RecordNudge(nudge *models.ReengagementNudge) error {
+	return r.DB.Create(nudge).Error
+}
@@ -0,0 +1,119 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReportAuditRepository persists the post-publication audits of
+// auto-published reports
+type ReportAuditRepository interface {
+	CreateAudit(audit *models.ReportAudit) error
+	ListAudits(outcome string, page int) ([]models.ReportAudit, error)
+	GetAudit(id uint) (*models.ReportAudit, error)
+	FindReportAudit(reportID string) (*models.ReportAudit, error)
+	CompleteAudit(audit *models.ReportAudit, suspendUntil int64) error
+}
+
+type reportAuditRepo struct {
+	DB *gorm.DB
+}
+
+func NewReportAuditRepo(db *GormDB) ReportAuditRepository {
+	return &reportAuditRepo{db.DB}
+}
+
+func (r *reportAuditRepo) CreateAudit(audit *models.ReportAudit) error {
+	return r.DB.Create(audit).Error
+}
+
+// ListAudits returns audits with the given outcome, oldest sampled first
+// so the queue is worked in order
+func (r *reportAuditRepo) ListAudits(outcome string, page int) ([]models.ReportAudit, error) {
+	var audits []models.ReportAudit
+	err := r.DB.Where("outcome = ?", outcome).
+		Order("sampled_at ASC").
+		Offset((page - 1) * DefaultPageSize).
+		Limit(DefaultPageSize).
+		Find(&audits).Error
+	return audits, err
+}
+
+func (r *reportAuditRepo) GetAudit(id uint) (*models.ReportAudit, error) {
+	var audit models.ReportAudit
+	if err := r.DB.First(&audit, id).Error; err != nil {
+		return nil, err
+	}
+	return &audit, nil
+}
+
+// FindReportAudit returns the audit a report was sampled for, or nil when
+// it was never sampled
+func (r *reportAuditRepo) FindReportAudit(reportID string) (*models.ReportAudit, error) {
+	var audit models.ReportAudit
+	err := r.DB.Where("report_id = ?", reportID).First(&audit).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &audit, nil
+}
+
+// CompleteAudit saves the audit's outcome. A failed audit also rejects the
+// report and suspends the reporter's privileges until suspendUntil, which
+// is recorded in the audit log.
+func (r *reportAuditRepo) CompleteAudit(audit *models.ReportAudit, suspendUntil int64) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(audit).Updates(map[string]interface{}{
+			"outcome":    audit.Outcome,
+			"note":       audit.Note,
+			"audited_by": audit.AuditedBy,
+			"audited_at": audit.AuditedAt,
+		}).Error; err != nil {
+			return err
+		}
+		if audit.Outcome != models.AuditOutcomeFailed {
+			return nil
+		}
+
+		var report models.IncidentReport
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "report_status").
+			First(&report, "id = ?", audit.ReportID).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.IncidentReport{}).Where("id = ?", audit.ReportID).Updates(map[string]interface{}{
+			"report_status":     models.ReportStatusRejected,
+			"status_updated_at": audit.AuditedAt,
+		}).Error; err != nil {
+			return err
+		}
+		if _, err := recordTransition(tx, report.ID, report.ReportStatus, models.ReportStatusRejected, audit.AuditedBy, "failed audit", time.Unix(audit.AuditedAt, 0)); err != nil {
+			return err
+		}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"privileges_suspended_until"}),
+		}).Create(&models.ReporterReputation{
+			UserID:                   audit.UserID,
+			Score:                    50,
+			Tier:                     models.ReputationNew,
+			ComputedAt:               time.Now().Unix(),
+			PrivilegesSuspendedUntil: suspendUntil,
+		}).Error; err != nil {
+			return err
+		}
+		return writeAudit(tx, audit.AuditedBy, models.AuditPrivilegesSuspended, "user", fmt.Sprint(audit.UserID), map[string]interface{}{
+			"report_id": audit.ReportID,
+			"audit_id":  audit.ID,
+			"until":     suspendUntil,
+		})
+	})
+}
@@ -0,0 +1,77 @@
+package db
+
+import (
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReportCapRepository counts the reports users file and keeps the accounts
+// exempt from the daily caps
+type ReportCapRepository interface {
+	CountReportsSince(userID uint, category string, since int64) (int64, error)
+	IsExempt(userID uint) (bool, error)
+	ListExemptions() ([]models.ReportCapExemption, error)
+	AddExemption(exemption *models.ReportCapExemption) error
+	RemoveExemption(userID uint) error
+}
+
+type reportCapRepo struct {
+	DB *gorm.DB
+}
+
+func NewReportCapRepo(db *GormDB) ReportCapRepository {
+	return &reportCapRepo{db.DB}
+}
+
+// CountReportsSince counts the reports filed since since by the user or by
+// any account signed in from the same device, so a second account does not
+// get a second allowance. An empty category counts every category.
+func (r *reportCapRepo) CountReportsSince(userID uint, category string, since int64) (int64, error) {
+	sameDevice := r.DB.Model(&models.User{}).
+		Select("others.id").
+		Joins("JOIN users others ON others.mac_address_index = users.mac_address_index").
+		Where("users.id = ? AND users.mac_address_index IS NOT NULL", userID)
+	query := r.DB.Model(&models.IncidentReport{}).
+		Where("(user_id = ? OR user_id IN (?)) AND created_at >= ?", userID, sameDevice, since)
+	if category != "" {
+		query = query.Where("LOWER(category) = ?", category)
+	}
+	var count int64
+	err := query.Count(&count).Error
+	return count, err
+}
+
+func (r *reportCapRepo) IsExempt(userID uint) (bool, error) {
+	var count int64
+	err := r.DB.Model(&models.ReportCapExemption{}).Where("user_id = ?", userID).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *reportCapRepo) ListExemptions() ([]models.ReportCapExemption, error) {
+	var exemptions []models.ReportCapExemption
+	err := r.DB.Order("created_at DESC").Find(&exemptions).Error
+	return exemptions, err
+}
+
+// AddExemption exempts the user, replacing the reason of an existing
+// exemption
+func (r *reportCapRepo) AddExemption(exemption *models.ReportCapExemption) error {
+	return r.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"reason", "created_by", "created_at"}),
+	}).Create(exemption).Error
+}
+
+// RemoveExemption ends the user's exemption, returning
+// gorm.ErrRecordNotFound when they have none
+func (r *reportCapRepo) RemoveExemption(userID uint) error {
+	result := r.DB.Delete(&models.ReportCapExemption{}, "user_id = ?", userID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
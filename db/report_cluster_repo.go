@@ -0,0 +1,237 @@
+package db
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techagentng/citizenx/geo"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// maxDuplicateCandidates bounds how many earlier reports a new one is
+// compared with
+const maxDuplicateCandidates = 200
+
+// DuplicateRule says which reports may be duplicates of each other: the same
+// category in the same LGA, made within Window of each other and, when both
+// are located, within RadiusM metres
+type DuplicateRule struct {
+	Window  time.Duration
+	RadiusM float64
+}
+
+// ReportHistory sums up the reports made before a report of the same
+// category at the same place. Incidents counts a cluster of duplicates once.
+type ReportHistory struct {
+	Reports       int64
+	Incidents     int64
+	Resolved      int64
+	FirstReportAt int64
+	LastReportAt  int64
+}
+
+// ReportClusterRepository finds reports that look like duplicates and keeps
+// the clusters they are grouped in
+type ReportClusterRepository interface {
+	LinkDuplicates(report *models.IncidentReport, rule DuplicateRule) (*models.ReportCluster, error)
+	FindDuplicates(report *models.IncidentReport, rule DuplicateRule) ([]models.IncidentReport, error)
+	GetCluster(id uuid.UUID) (*models.ReportCluster, error)
+	ClusterReports(clusterID uuid.UUID) ([]models.IncidentReport, error)
+	History(report *models.IncidentReport, radiusM float64, limit int, opts ...PreloadOption) (*ReportHistory, []models.IncidentReport, error)
+}
+
+type reportClusterRepo struct {
+	DB *gorm.DB
+}
+
+func NewReportClusterRepo(db *GormDB) ReportClusterRepository {
+	return &reportClusterRepo{db.DB}
+}
+
+// LinkDuplicates compares a newly saved report with the reports made before
+// it. The nearest one within the radius puts the report in its cluster,
+// which is started when the match has none. When the only candidates cannot
+// be compared by distance, because one of them has no location, the report
+// is just flagged as a possible duplicate. The cluster is returned when the
+// report joined one.
+func (r *reportClusterRepo) LinkDuplicates(report *models.IncidentReport, rule DuplicateRule) (*models.ReportCluster, error) {
+	var cluster *models.ReportCluster
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		candidates, err := duplicateCandidates(tx, report, rule, report.CreatedAt-int64(rule.Window.Seconds()), report.CreatedAt)
+		if err != nil || len(candidates) == 0 {
+			return err
+		}
+		var nearest *models.IncidentReport
+		closest := rule.RadiusM
+		for i := range candidates {
+			candidate := &candidates[i]
+			if !reportLocated(report) || !reportLocated(candidate) {
+				continue
+			}
+			distance := geo.Distance(
+				geo.Point{Latitude: report.Latitude, Longitude: report.Longitude},
+				geo.Point{Latitude: candidate.Latitude, Longitude: candidate.Longitude},
+			)
+			if distance <= closest {
+				nearest, closest = candidate, distance
+			}
+		}
+
+		updates := map[string]interface{}{"possible_duplicate": true}
+		if nearest == nil {
+			if reportLocated(report) && allLocated(candidates) {
+				return nil
+			}
+			report.PossibleDuplicate = true
+			return tx.Model(&models.IncidentReport{}).Where("id = ?", report.ID).Updates(updates).Error
+		}
+
+		if cluster, err = joinCluster(tx, nearest, report); err != nil {
+			return err
+		}
+		updates["cluster_id"] = cluster.ID
+		report.ClusterID = &cluster.ID
+		report.PossibleDuplicate = true
+		return tx.Model(&models.IncidentReport{}).Where("id = ?", report.ID).Updates(updates).Error
+	})
+	return cluster, err
+}
+
+// FindDuplicates returns the reports made within the window either side of
+// report that the rule would compare it with
+func (r *reportClusterRepo) FindDuplicates(report *models.IncidentReport, rule DuplicateRule) ([]models.IncidentReport, error) {
+	window := int64(rule.Window.Seconds())
+	return duplicateCandidates(r.DB, report, rule, report.CreatedAt-window, report.CreatedAt+window)
+}
+
+func (r *reportClusterRepo) GetCluster(id uuid.UUID) (*models.ReportCluster, error) {
+	var cluster models.ReportCluster
+	if err := r.DB.Where("id = ?", id).First(&cluster).Error; err != nil {
+		return nil, err
+	}
+	return &cluster, nil
+}
+
+// ClusterReports returns the reports in the cluster, the first made first
+func (r *reportClusterRepo) ClusterReports(clusterID uuid.UUID) ([]models.IncidentReport, error) {
+	var reports []models.IncidentReport
+	err := r.DB.Where("cluster_id = ?", clusterID).Order("created_at ASC, id ASC").Find(&reports).Error
+	return reports, err
+}
+
+// History sums up the live reports of report's category made before it
+// within radiusM metres, or in its LGA when it has no location, and returns
+// the latest limit of them. Reports in its own cluster describe the same
+// incident and are left out.
+func (r *reportClusterRepo) History(report *models.IncidentReport, radiusM float64, limit int, opts ...PreloadOption) (*ReportHistory, []models.IncidentReport, error) {
+	history := &ReportHistory{}
+	if report.Category == "" || (!reportLocated(report) && report.LGAName == "") {
+		return history, []models.IncidentReport{}, nil
+	}
+
+	similar := func() *gorm.DB {
+		query := r.DB.Table("incident_reports").
+			Where("incident_reports.id <> ? AND LOWER(incident_reports.category) = LOWER(?)", report.ID, report.Category).
+			Where("incident_reports.created_at < ?", report.CreatedAt).
+			Where("COALESCE(incident_reports.report_status, '') NOT IN ?", []string{models.ReportStatusWithdrawn, models.ReportStatusRejected})
+		if report.ClusterID != nil {
+			query = query.Where("incident_reports.cluster_id IS NULL OR incident_reports.cluster_id <> ?", *report.ClusterID)
+		}
+		if reportLocated(report) {
+			query, _ = withinRadius(query, report.Latitude, report.Longitude, radiusM/1000)
+			return query
+		}
+		return query.Where("LOWER(incident_reports.lga_name) = LOWER(?)", report.LGAName)
+	}
+
+	err := similar().Select(`COUNT(*) AS reports,
+            COUNT(DISTINCT COALESCE(incident_reports.cluster_id::text, incident_reports.id::text)) AS incidents,
+            COUNT(*) FILTER (WHERE incident_reports.report_status = ?) AS resolved,
+            COALESCE(MIN(incident_reports.created_at), 0) AS first_report_at,
+            COALESCE(MAX(incident_reports.created_at), 0) AS last_report_at`, models.ReportStatusResolved).
+		Scan(history).Error
+	if err != nil {
+		return nil, nil, err
+	}
+	if history.Reports == 0 {
+		return history, []models.IncidentReport{}, nil
+	}
+
+	examples, err := findReports(similar().Order("incident_reports.created_at DESC, incident_reports.id").Limit(limit), opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	return history, examples, nil
+}
+
+// duplicateCandidates loads the live reports of report's category and LGA
+// made between from and to, nearest in time first. Reports without either
+// are never compared.
+func duplicateCandidates(tx *gorm.DB, report *models.IncidentReport, rule DuplicateRule, from, to int64) ([]models.IncidentReport, error) {
+	if rule.Window <= 0 || report.Category == "" || report.LGAName == "" {
+		return nil, nil
+	}
+	var candidates []models.IncidentReport
+	err := tx.Model(&models.IncidentReport{}).
+		Where("id <> ? AND LOWER(category) = LOWER(?) AND LOWER(lga_name) = LOWER(?)", report.ID, report.Category, report.LGAName).
+		Where("created_at BETWEEN ? AND ?", from, to).
+		Where("COALESCE(report_status, '') NOT IN ?", []string{models.ReportStatusWithdrawn, models.ReportStatusRejected}).
+		Order("created_at DESC").
+		Limit(maxDuplicateCandidates).
+		Find(&candidates).Error
+	return candidates, err
+}
+
+// joinCluster adds report to match's cluster, starting one with match in it
+// when it has none. The cluster is locked while its count is raised.
+func joinCluster(tx *gorm.DB, match, report *models.IncidentReport) (*models.ReportCluster, error) {
+	cluster := &models.ReportCluster{}
+	if match.ClusterID != nil {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(cluster, "id = ?", *match.ClusterID).Error; err != nil {
+			return nil, err
+		}
+	} else {
+		cluster = &models.ReportCluster{
+			ID:            uuid.New(),
+			Category:      match.Category,
+			StateName:     match.StateName,
+			LGAName:       match.LGAName,
+			Latitude:      match.Latitude,
+			Longitude:     match.Longitude,
+			ReportCount:   1,
+			FirstReportAt: match.CreatedAt,
+			LastReportAt:  match.CreatedAt,
+		}
+		if err := tx.Create(cluster).Error; err != nil {
+			return nil, err
+		}
+		if err := tx.Model(&models.IncidentReport{}).Where("id = ?", match.ID).Update("cluster_id", cluster.ID).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	cluster.ReportCount++
+	if report.CreatedAt > cluster.LastReportAt {
+		cluster.LastReportAt = report.CreatedAt
+	}
+	err := tx.Model(&models.ReportCluster{}).Where("id = ?", cluster.ID).Updates(map[string]interface{}{
+		"report_count":   cluster.ReportCount,
+		"last_report_at": cluster.LastReportAt,
+	}).Error
+	return cluster, err
+}
+
+func reportLocated(report *models.IncidentReport) bool {
+	return report.Latitude != 0 || report.Longitude != 0
+}
+
+func allLocated(reports []models.IncidentReport) bool {
+	for i := range reports {
+		if !reportLocated(&reports[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,88 @@
+package db
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidCursor is returned for a cursor that was not issued by a
+// listing, or was issued for another sort.
+var ErrInvalidCursor = errors.New("cursor is invalid for this listing")
+
+// ReportCursor marks the last report of a page. The next page starts after
+// it by its sort key rather than by an offset, so it stays cheap deep into a
+// listing and reports arriving meanwhile do not shift it.
+type ReportCursor struct {
+	Order string `json:"o"`
+	// Time is the time of incidence of the report
+	Time time.Time `json:"t"`
+	// Value is the endorsements, the last update or the credibility score,
+	// for the sorts keyed on them
+	Value int64     `json:"v,omitempty"`
+	ID    uuid.UUID `json:"id"`
+}
+
+// Encode returns the cursor as the opaque string handed to clients
+func (c ReportCursor) Encode() string {
+	body, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(body)
+}
+
+// decodeReportCursor reads a cursor issued for order
+func decodeReportCursor(s, order string) (*ReportCursor, error) {
+	body, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var cursor ReportCursor
+	if err := json.Unmarshal(body, &cursor); err != nil || cursor.Order != order || cursor.ID == uuid.Nil {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// NextCursor returns the cursor of the page after reports, or "" when
+// reports is the last page or the sort is paged by offset only
+func (s ReportSort) NextCursor(reports []models.IncidentReport) string {
+	order := s.order()
+	if len(reports) < DefaultPageSize || order == SortNearest {
+		return ""
+	}
+	last := reports[len(reports)-1]
+	cursor := ReportCursor{Order: order, Time: last.TimeofIncidence, ID: last.ID}
+	switch order {
+	case SortMostEndorsed:
+		cursor.Value = int64(last.UpvoteCount + last.LikeCount)
+	case SortTrending:
+		cursor.Value = int64(last.CredibilityScore)
+	case SortRecentlyUpdated:
+		cursor.Value = last.StatusUpdatedAt
+		if last.CreatedAt > cursor.Value {
+			cursor.Value = last.CreatedAt
+		}
+	}
+	return cursor.Encode()
+}
+
+// after keeps the reports that sort after the cursor. The row comparisons
+// match the ORDER BY of apply column for column.
+func (c *ReportCursor) after(query *gorm.DB) *gorm.DB {
+	switch c.Order {
+	case SortOldest:
+		return query.Where("(incident_reports.timeof_incidence, incident_reports.id) > (?, ?)", c.Time, c.ID)
+	case SortMostEndorsed:
+		return query.Where("("+endorsementsExpr+", incident_reports.timeof_incidence, incident_reports.id) < (?, ?, ?)", c.Value, c.Time, c.ID)
+	case SortRecentlyUpdated:
+		return query.Where("("+updatedExpr+", incident_reports.id) < (?, ?)", c.Value, c.ID)
+	case SortTrending:
+		return query.Where("(incident_reports.credibility_score, incident_reports.timeof_incidence, incident_reports.id) < (?, ?, ?)", c.Value, c.Time, c.ID)
+	default:
+		return query.Where("(incident_reports.timeof_incidence, incident_reports.id) < (?, ?)", c.Time, c.ID)
+	}
+}
@@ -0,0 +1,30 @@
+package db
+
+import (
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+// ReportExportRepository reads reports out in bulk for export
+type ReportExportRepository interface {
+	EachReportBatch(filters ReportFilter, size int, each func(reports []models.IncidentReport) error) error
+}
+
+type reportExportRepo struct {
+	DB *gorm.DB
+}
+
+func NewReportExportRepo(db *GormDB) ReportExportRepository {
+	return &reportExportRepo{db.DB}
+}
+
+// EachReportBatch passes the reports matching filters to each, size at a
+// time, so an export never holds more than one batch. An error from each
+// stops the export.
+func (r *reportExportRepo) EachReportBatch(filters ReportFilter, size int, each func(reports []models.IncidentReport) error) error {
+	var batch []models.IncidentReport
+	return filters.apply(r.DB.Model(&models.IncidentReport{})).
+		FindInBatches(&batch, size, func(tx *gorm.DB, _ int) error {
+			return each(batch)
+		}).Error
+}
@@ -0,0 +1,53 @@
+package db
+
+import (
+	"math"
+
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// kmPerDegree is the length of a degree of latitude, and of longitude at the
+// equator
+const kmPerDegree = 111.195
+
+// haversineKM is the great-circle distance in kilometres from a report to
+// the point bound to its three placeholders: latitude, latitude again and
+// longitude. It needs no PostGIS, which the database does not have.
+const haversineKM = `(2 * 6371 * ASIN(LEAST(1, SQRT(
+	POWER(SIN(RADIANS(incident_reports.latitude - ?) / 2), 2) +
+	COS(RADIANS(?)) * COS(RADIANS(incident_reports.latitude)) *
+	POWER(SIN(RADIANS(incident_reports.longitude - ?) / 2), 2)))))`
+
+// GetReportsWithinRadius returns a page of the located reports within
+// radiusKM of a point, nearest first.
+func (repo *incidentReportRepo) GetReportsWithinRadius(lat, lng, radiusKM float64, page int, opts ...PreloadOption) ([]models.IncidentReport, error) {
+	query, distance := withinRadius(repo.DB.Table("incident_reports"), lat, lng, radiusKM)
+	query = query.Order(clause.OrderBy{Expression: clause.Expr{SQL: "? ASC, incident_reports.id", Vars: []interface{}{distance}, WithoutParentheses: true}})
+
+	if page < 1 {
+		page = 1
+	}
+	return findReports(query.Limit(DefaultPageSize).Offset((page-1)*DefaultPageSize), opts...)
+}
+
+// withinRadius narrows query to the located reports within radiusKM of a
+// point, returning the distance to each for ordering. A box around the
+// circle narrows the reports on the location index before their distances
+// are worked out.
+func withinRadius(query *gorm.DB, lat, lng, radiusKM float64) (*gorm.DB, clause.Expr) {
+	latSpan := radiusKM / kmPerDegree
+	query = query.
+		Where("NOT (incident_reports.latitude = 0 AND incident_reports.longitude = 0)").
+		Where("incident_reports.latitude BETWEEN ? AND ?", lat-latSpan, lat+latSpan)
+	// Near the poles, or across the antimeridian, the box would wrap, and
+	// only the latitude band is used
+	if scale := math.Cos(lat * math.Pi / 180); scale > 0.01 {
+		if lngSpan := latSpan / scale; math.Abs(lng)+lngSpan <= 180 {
+			query = query.Where("incident_reports.longitude BETWEEN ? AND ?", lng-lngSpan, lng+lngSpan)
+		}
+	}
+	distance := clause.Expr{SQL: haversineKM, Vars: []interface{}{lat, lat, lng}}
+	return query.Where("? <= ?", distance, radiusKM), distance
+}
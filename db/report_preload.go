@@ -0,0 +1,142 @@
+package db
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+// ReportPreload describes which associations are loaded alongside incident
+// reports. Every association is fetched through an aggregated subquery so a
+// page of reports is always a single round trip.
+type ReportPreload struct {
+	Media    bool
+	Reporter bool
+	Counts   bool
+	Points   bool
+}
+
+// PreloadOption switches on one association of a ReportPreload.
+type PreloadOption func(*ReportPreload)
+
+// WithMedia loads the media attached to each report.
+func WithMedia() PreloadOption {
+	return func(p *ReportPreload) { p.Media = true }
+}
+
+// WithReporter loads the public profile of the reporting user. Anonymous
+// reports never carry a reporter.
+func WithReporter() PreloadOption {
+	return func(p *ReportPreload) { p.Reporter = true }
+}
+
+// WithCounts loads media, bookmark, vote and comment counts for each report.
+func WithCounts() PreloadOption {
+	return func(p *ReportPreload) { p.Counts = true }
+}
+
+// WithPoints loads the ordered points of reports spanning several places.
+func WithPoints() PreloadOption {
+	return func(p *ReportPreload) { p.Points = true }
+}
+
+// ReportCard is the set of options used by the report listing endpoints.
+func ReportCard() []PreloadOption {
+	return []PreloadOption{WithMedia(), WithReporter(), WithCounts(), WithPoints()}
+}
+
+const (
+	mediaColumn = `COALESCE((SELECT json_agg(json_build_object(
+		'id', media.id, 'file_type', media.file_type, 'width', media.width, 'height', media.height,
+		'feed_url', media.feed_url, 'thumbnail_url', media.thumbnail_url, 'full_size_url', media.full_size_url,
+		'graphic', media.graphic, 'blurred_url', media.blurred_url))
+		FROM media WHERE media.incident_report_id = incident_reports.id::text), '[]')::text AS media_json`
+
+	reporterColumn = `COALESCE((SELECT json_build_object(
+		'id', users.id, 'fullname', users.fullname, 'username', users.username, 'profile_image', users.thumb_nail_url)
+		FROM users WHERE users.id = incident_reports.user_id AND NOT incident_reports.user_is_anonymous
+		AND users.restricted_until <= EXTRACT(EPOCH FROM NOW())), 'null')::text AS reporter_json`
+
+	countsColumn = `json_build_object(
+		'media', (SELECT COUNT(*) FROM media WHERE media.incident_report_id = incident_reports.id::text),
+		'bookmarks', (SELECT COUNT(*) FROM bookmarks WHERE bookmarks.report_id = incident_reports.id::text),
+		'votes', (SELECT COUNT(*) FROM report_votes WHERE report_votes.report_id = incident_reports.id),
+		'comments', (SELECT COUNT(*) FROM report_comments WHERE report_comments.report_id = incident_reports.id
+			AND NOT report_comments.deleted))::text AS counts_json`
+
+	pointsColumn = `COALESCE((SELECT json_agg(json_build_object(
+		'position', report_points.position, 'latitude', report_points.latitude, 'longitude', report_points.longitude,
+		'label', report_points.label, 'observed_at', report_points.observed_at) ORDER BY report_points.position)
+		FROM report_points WHERE report_points.report_id = incident_reports.id), '[]')::text AS points_json`
+)
+
+// reportRow is an incident report together with its aggregated associations,
+// each encoded as JSON by the database.
+type reportRow struct {
+	models.IncidentReport
+	MediaJSON    string
+	ReporterJSON string
+	CountsJSON   string
+	PointsJSON   string
+}
+
+func (reportRow) TableName() string {
+	return "incident_reports"
+}
+
+// findReports executes query against incident_reports, selecting the
+// associations requested in opts, and returns the hydrated reports.
+func findReports(query *gorm.DB, opts ...PreloadOption) ([]models.IncidentReport, error) {
+	var preload ReportPreload
+	for _, opt := range opts {
+		opt(&preload)
+	}
+
+	columns := []string{"incident_reports.*"}
+	if preload.Media {
+		columns = append(columns, mediaColumn)
+	}
+	if preload.Reporter {
+		columns = append(columns, reporterColumn)
+	}
+	if preload.Counts {
+		columns = append(columns, countsColumn)
+	}
+	if preload.Points {
+		columns = append(columns, pointsColumn)
+	}
+
+	var rows []reportRow
+	if err := query.Select(strings.Join(columns, ", ")).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	reports := make([]models.IncidentReport, 0, len(rows))
+	for _, row := range rows {
+		report := row.IncidentReport
+		if preload.Media {
+			if err := json.Unmarshal([]byte(row.MediaJSON), &report.Media); err != nil {
+				return nil, err
+			}
+		}
+		if preload.Reporter {
+			if err := json.Unmarshal([]byte(row.ReporterJSON), &report.Reporter); err != nil {
+				return nil, err
+			}
+		}
+		if preload.Counts {
+			if err := json.Unmarshal([]byte(row.CountsJSON), &report.Counts); err != nil {
+				return nil, err
+			}
+		}
+		if preload.Points {
+			if err := json.Unmarshal([]byte(row.PointsJSON), &report.Points); err != nil {
+				return nil, err
+			}
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
@@ -0,0 +1,278 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidQuery is wrapped by every error that rejects a report query, so
+// callers can tell a bad request from a failed one.
+var ErrInvalidQuery = errors.New("invalid report query")
+
+// Limits on a report query, keeping ad-hoc questions cheap to answer
+const (
+	maxQueryFilters   = 20
+	maxQueryValues    = 100
+	maxQueryGroups    = 3
+	maxQueryRows      = 1000
+	defaultQueryRows  = 100
+	queryTimeoutMilli = 10000
+)
+
+// Kinds of value a query field holds
+const (
+	fieldText = iota
+	fieldUnixTime
+	fieldTime
+	fieldUUID
+)
+
+// queryField is a report field that may be filtered and grouped on. Only
+// indexed columns are exposed.
+type queryField struct {
+	expr string
+	kind int
+}
+
+// ReportQueryFields maps the field names of the report query language to
+// the indexed columns behind them. Reports without a status are pending.
+var ReportQueryFields = map[string]queryField{
+	"state":         {"incident_reports.state_name", fieldText},
+	"lga":           {"incident_reports.lga_name", fieldText},
+	"ward":          {"incident_reports.ward_name", fieldText},
+	"category":      {"incident_reports.category", fieldText},
+	"sub_category":  {"incident_reports.sub_report_type", fieldText},
+	"status":        {"COALESCE(NULLIF(incident_reports.report_status, ''), 'pending')", fieldText},
+	"submitted_at":  {"incident_reports.created_at", fieldUnixTime},
+	"incident_date": {"incident_reports.timeof_incidence", fieldTime},
+	"incident_id":   {"incident_reports.incident_id", fieldUUID},
+}
+
+// reportQueryPeriods are the time buckets a query may group by, over the
+// time reports were submitted
+var reportQueryPeriods = map[string]string{
+	"day":   "DATE_TRUNC('day', TO_TIMESTAMP(incident_reports.created_at))",
+	"week":  "DATE_TRUNC('week', TO_TIMESTAMP(incident_reports.created_at))",
+	"month": "DATE_TRUNC('month', TO_TIMESTAMP(incident_reports.created_at))",
+}
+
+// ReportQueryMetrics maps the metric names of the query language to the
+// aggregates they compute
+var ReportQueryMetrics = map[string]string{
+	"count":     "COUNT(*)",
+	"reporters": "COUNT(DISTINCT incident_reports.user_id)",
+	"verified":  "COUNT(*) FILTER (WHERE LOWER(incident_reports.report_status) IN " + publishedStatuses + ")",
+	"upvotes":   "COALESCE(SUM(incident_reports.upvote_count), 0)",
+	"downvotes": "COALESCE(SUM(incident_reports.downvote_count), 0)",
+	"views":     "COALESCE(SUM(incident_reports.view), 0)",
+}
+
+// reportQueryOps maps filter operators to SQL. in and not_in take a list;
+// the comparisons only apply to time fields.
+var reportQueryOps = map[string]string{
+	"eq":     "=",
+	"neq":    "<>",
+	"in":     "IN",
+	"not_in": "NOT IN",
+	"gt":     ">",
+	"gte":    ">=",
+	"lt":     "<",
+	"lte":    "<=",
+}
+
+// QueryFilter restricts a report query to reports whose field compares to
+// Value, or for in and not_in to one of Values.
+type QueryFilter struct {
+	Field  string   `json:"field"`
+	Op     string   `json:"op"`
+	Value  string   `json:"value"`
+	Values []string `json:"values"`
+}
+
+// ReportQuery is an analyst's question about reports: which reports to
+// count, how to group them and what to measure. For example
+//
+//	{"filters": [{"field": "state", "op": "eq", "value": "Lagos"}],
+//	 "group_by": ["lga", "month"], "metrics": ["count", "verified"],
+//	 "order_by": "count", "desc": true, "limit": 50}
+type ReportQuery struct {
+	Filters []QueryFilter `json:"filters"`
+	GroupBy []string      `json:"group_by"`
+	Metrics []string      `json:"metrics"`
+	OrderBy string        `json:"order_by"`
+	Desc    bool          `json:"desc"`
+	Limit   int           `json:"limit"`
+}
+
+// invalidQuery returns an error wrapping ErrInvalidQuery
+func invalidQuery(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", ErrInvalidQuery, fmt.Sprintf(format, args...))
+}
+
+// build checks the query against the language and translates it onto
+// query. Field, metric and operator names only ever select from the fixed
+// SQL fragments above; values are always bound as parameters.
+func (q ReportQuery) build(query *gorm.DB) (*gorm.DB, error) {
+	if len(q.Filters) > maxQueryFilters {
+		return nil, invalidQuery("at most %d filters are allowed", maxQueryFilters)
+	}
+	for _, filter := range q.Filters {
+		condition, args, err := filter.condition()
+		if err != nil {
+			return nil, err
+		}
+		query = query.Where(condition, args...)
+	}
+
+	if len(q.GroupBy) > maxQueryGroups {
+		return nil, invalidQuery("at most %d groupings are allowed", maxQueryGroups)
+	}
+	metrics := q.Metrics
+	if len(metrics) == 0 {
+		metrics = []string{"count"}
+	}
+
+	var selects, groups []string
+	selected := map[string]bool{}
+	periods := 0
+	for _, name := range q.GroupBy {
+		if selected[name] {
+			return nil, invalidQuery("%q is grouped by twice", name)
+		}
+		expr, ok := reportQueryPeriods[name]
+		if ok {
+			periods++
+		} else {
+			field, known := ReportQueryFields[name]
+			if !known {
+				return nil, invalidQuery("cannot group by %q", name)
+			}
+			expr = field.expr
+		}
+		selects = append(selects, expr+" AS "+name)
+		groups = append(groups, expr)
+		selected[name] = true
+	}
+	if periods > 1 {
+		return nil, invalidQuery("group by at most one of day, week and month")
+	}
+	for _, name := range metrics {
+		expr, ok := ReportQueryMetrics[name]
+		if !ok {
+			return nil, invalidQuery("unknown metric %q", name)
+		}
+		if selected[name] {
+			return nil, invalidQuery("%q is requested twice", name)
+		}
+		selects = append(selects, expr+" AS "+name)
+		selected[name] = true
+	}
+	query = query.Select(strings.Join(selects, ", "))
+	if len(groups) > 0 {
+		query = query.Group(strings.Join(groups, ", "))
+	}
+
+	orderBy := q.OrderBy
+	if orderBy == "" {
+		orderBy = metrics[0]
+	}
+	if !selected[orderBy] {
+		return nil, invalidQuery("order_by must name a grouping or metric of the query")
+	}
+	direction := " ASC"
+	if q.Desc {
+		direction = " DESC"
+	}
+	query = query.Order(orderBy + direction)
+
+	limit := q.Limit
+	if limit <= 0 {
+		limit = defaultQueryRows
+	}
+	if limit > maxQueryRows {
+		return nil, invalidQuery("limit may not exceed %d", maxQueryRows)
+	}
+	return query.Limit(limit), nil
+}
+
+// condition translates one filter to a parameterised SQL condition
+func (f QueryFilter) condition() (string, []interface{}, error) {
+	field, ok := ReportQueryFields[f.Field]
+	if !ok {
+		return "", nil, invalidQuery("cannot filter on %q", f.Field)
+	}
+	op, ok := reportQueryOps[f.Op]
+	if !ok {
+		return "", nil, invalidQuery("unknown operator %q", f.Op)
+	}
+	comparison := op != "=" && op != "<>" && op != "IN" && op != "NOT IN"
+	if comparison && field.kind != fieldUnixTime && field.kind != fieldTime {
+		return "", nil, invalidQuery("%s only applies to time fields", f.Op)
+	}
+
+	if op == "IN" || op == "NOT IN" {
+		if len(f.Values) == 0 || len(f.Values) > maxQueryValues {
+			return "", nil, invalidQuery("%s on %q needs between 1 and %d values", f.Op, f.Field, maxQueryValues)
+		}
+		values := make([]interface{}, len(f.Values))
+		for i, raw := range f.Values {
+			value, err := field.parse(f.Field, raw)
+			if err != nil {
+				return "", nil, err
+			}
+			values[i] = value
+		}
+		return field.expr + " " + op + " ?", []interface{}{values}, nil
+	}
+
+	value, err := field.parse(f.Field, f.Value)
+	if err != nil {
+		return "", nil, err
+	}
+	return field.expr + " " + op + " ?", []interface{}{value}, nil
+}
+
+// parse converts a filter value to the type of the field. Times are dates
+// (YYYY-MM-DD) or RFC 3339 timestamps.
+func (f queryField) parse(name, raw string) (interface{}, error) {
+	switch f.kind {
+	case fieldUnixTime, fieldTime:
+		t, err := time.Parse("2006-01-02", raw)
+		if err != nil {
+			if t, err = time.Parse(time.RFC3339, raw); err != nil {
+				return nil, invalidQuery("%q needs a YYYY-MM-DD date or RFC 3339 time", name)
+			}
+		}
+		if f.kind == fieldUnixTime {
+			return t.Unix(), nil
+		}
+		return t, nil
+	case fieldUUID:
+		if _, err := uuid.Parse(raw); err != nil {
+			return nil, invalidQuery("%q needs a UUID", name)
+		}
+	}
+	return raw, nil
+}
+
+// QueryReports answers a report query, one row per group with a column per
+// grouping and metric. The query is bounded by a statement timeout.
+func (a *analyticsRepo) QueryReports(q ReportQuery) ([]map[string]interface{}, error) {
+	rows := []map[string]interface{}{}
+	err := a.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec(fmt.Sprintf("SET LOCAL statement_timeout = %d", queryTimeoutMilli)).Error; err != nil {
+			return err
+		}
+		query, err := q.build(tx.Table("incident_reports"))
+		if err != nil {
+			return err
+		}
+		return query.Find(&rows).Error
+	})
+	return rows, err
+}
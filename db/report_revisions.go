@@ -0,0 +1,96 @@
+package db
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techagentng/citizenx/events"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrReportReviewed is returned when editing a report that is no longer
+	// pending.
+	ErrReportReviewed = errors.New("report has been reviewed")
+	// ErrLocationNotEditable is returned when moving a report drawn along a
+	// route or through several points, or one whose location was coarsened:
+	// those would be left describing the old place, or made precise again.
+	ErrLocationNotEditable = errors.New("report location cannot be edited")
+)
+
+// saveRevision keeps revision as the next version of its report. The report
+// row must be locked, so concurrent edits number their versions in turn.
+func saveRevision(tx *gorm.DB, revision models.ReportRevision, editorID uint) (*models.ReportRevision, error) {
+	var latest int
+	if err := tx.Model(&models.ReportRevision{}).
+		Where("report_id = ?", revision.ReportID).
+		Select("COALESCE(MAX(version), 0)").
+		Scan(&latest).Error; err != nil {
+		return nil, err
+	}
+	revision.Version = latest + 1
+	revision.EditorID = editorID
+	revision.CreatedAt = time.Now().Unix()
+	if err := tx.Create(&revision).Error; err != nil {
+		return nil, err
+	}
+	return &revision, nil
+}
+
+// EditReport applies changes to a report, keeping the version they replace
+// as a revision, with a ReportEdited event naming fields in the same
+// transaction. It returns gorm.ErrRecordNotFound when the report does not
+// exist, ErrReportReviewed once it is no longer pending and
+// ErrLocationNotEditable when a location change can't be applied. The checks
+// are made with the report locked, so a review can't slip in between.
+func (repo *incidentReportRepo) EditReport(reportID uuid.UUID, editorID uint, changes map[string]interface{}, fields []string) (*models.ReportRevision, error) {
+	var revision *models.ReportRevision
+	err := repo.DB.Transaction(func(tx *gorm.DB) error {
+		var report models.IncidentReport
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ?", reportID).
+			First(&report).Error; err != nil {
+			return err
+		}
+		if models.NormalizeReportStatus(report.ReportStatus) != models.ReportStatusPending {
+			return ErrReportReviewed
+		}
+		if _, moved := changes["Latitude"]; moved {
+			if report.Route != "" || report.LocationCoarsened {
+				return ErrLocationNotEditable
+			}
+			var points int64
+			if err := tx.Model(&models.ReportPoint{}).Where("report_id = ?", reportID).Count(&points).Error; err != nil {
+				return err
+			}
+			if points > 0 {
+				return ErrLocationNotEditable
+			}
+		}
+		var err error
+		if revision, err = saveRevision(tx, models.RevisionOf(&report), editorID); err != nil {
+			return err
+		}
+		if err := tx.Model(&models.IncidentReport{}).Where("id = ?", reportID).Updates(changes).Error; err != nil {
+			return err
+		}
+		return writeOutbox(tx, events.ReportEdited{
+			ReportID:   reportID,
+			UserID:     editorID,
+			Version:    revision.Version,
+			Fields:     fields,
+			OccurredAt: time.Unix(revision.CreatedAt, 0),
+		})
+	})
+	return revision, err
+}
+
+// GetReportRevisions returns a report's earlier versions, oldest first
+func (repo *incidentReportRepo) GetReportRevisions(reportID string) ([]models.ReportRevision, error) {
+	var revisions []models.ReportRevision
+	err := repo.DB.Where("report_id = ?", reportID).Order("version ASC").Find(&revisions).Error
+	return revisions, err
+}
@@ -0,0 +1,138 @@
+package db
+
+import (
+	"strings"
+	"time"
+
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReportFilter narrows a report listing or search. Empty fields match
+// everything.
+type ReportFilter struct {
+	StateName string
+	LGAName   string
+	Category  string
+	Status    string
+	// PlusCode is the significant prefix of a full plus code; reports whose
+	// code starts with it lie inside the code's area
+	PlusCode string
+	// Start and End bound the time of incidence; End is exclusive
+	Start, End *time.Time
+	BBox       *BBox
+}
+
+// BBox is an area on the map, in degrees
+type BBox struct {
+	West, South, East, North float64
+}
+
+func (f ReportFilter) apply(query *gorm.DB) *gorm.DB {
+	if f.StateName != "" {
+		query = query.Where("incident_reports.state_name = ?", f.StateName)
+	}
+	if f.LGAName != "" {
+		query = query.Where("incident_reports.lga_name = ?", f.LGAName)
+	}
+	if f.Category != "" {
+		query = query.Where("incident_reports.category = ?", f.Category)
+	}
+	if f.Status != "" {
+		query = query.Where("incident_reports.report_status = ?", f.Status)
+	}
+	if f.PlusCode != "" {
+		query = query.Where("incident_reports.plus_code LIKE ?", escapeLike(f.PlusCode)+"%")
+	}
+	if f.Start != nil {
+		query = query.Where("incident_reports.timeof_incidence >= ?", *f.Start)
+	}
+	if f.End != nil {
+		query = query.Where("incident_reports.timeof_incidence < ?", *f.End)
+	}
+	if f.BBox != nil {
+		query = query.Where("incident_reports.longitude BETWEEN ? AND ? AND incident_reports.latitude BETWEEN ? AND ?",
+			f.BBox.West, f.BBox.East, f.BBox.South, f.BBox.North)
+	}
+	return query
+}
+
+// ListReports returns the reports matching every filter, in the order sort
+// gives
+func (repo *incidentReportRepo) ListReports(filters ReportFilter, page int, sort ReportSort, opts ...PreloadOption) ([]models.IncidentReport, error) {
+	return findReports(sort.paginate(filters.apply(repo.DB), page), opts...)
+}
+
+// reportSearchVector is the document a report is searched by: the
+// description weighs most, then the sub report type, then where it is. The
+// GIN index in reportSearchIndexes is built on the same expression.
+const reportSearchVector = `(setweight(to_tsvector('english', coalesce(incident_reports.description, '')), 'A') || ` +
+	`setweight(to_tsvector('english', coalesce(incident_reports.sub_report_type, '')), 'B') || ` +
+	`setweight(to_tsvector('english', coalesce(incident_reports.state_name, '') || ' ' || coalesce(incident_reports.lga_name, '')), 'C') || ` +
+	`setweight(to_tsvector('english', coalesce(incident_reports.address, '')), 'D'))`
+
+// reportSearchIndexes back the full-text search
+var reportSearchIndexes = []string{
+	"CREATE INDEX IF NOT EXISTS idx_incident_reports_search ON incident_reports USING GIN (" + reportSearchVector + ")",
+}
+
+// SearchReports returns reports matching query, written the way people type
+// into a search box: words, "quoted phrases", or and -excluded words. Words
+// are stemmed, so "flooding" finds "flooded". Without a sort the best
+// matches come first, and the results are paged by page only. It is the
+// search used when no OpenSearch cluster is configured.
+func (repo *incidentReportRepo) SearchReports(query string, filters ReportFilter, page int, sort ReportSort, opts ...PreloadOption) ([]models.IncidentReport, error) {
+	q := filters.apply(repo.DB)
+	if query = strings.TrimSpace(query); query != "" {
+		tsquery := clause.Expr{SQL: "websearch_to_tsquery('english', ?)", Vars: []interface{}{query}}
+		q = q.Where(reportSearchVector+" @@ ?", tsquery)
+		if sort.Ranked(query) {
+			q = q.Order(clause.OrderBy{Expression: clause.Expr{
+				SQL:                "ts_rank(" + reportSearchVector + ", ?) DESC",
+				Vars:               []interface{}{tsquery},
+				WithoutParentheses: true,
+			}})
+		}
+	}
+
+	return findReports(sort.paginate(q, page), opts...)
+}
+
+// GetReportsByIDs loads the given reports in the order of ids, skipping any
+// that no longer exist.
+func (repo *incidentReportRepo) GetReportsByIDs(ids []string, opts ...PreloadOption) ([]models.IncidentReport, error) {
+	if len(ids) == 0 {
+		return []models.IncidentReport{}, nil
+	}
+
+	found, err := findReports(repo.DB.Where("incident_reports.id IN ?", ids), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]models.IncidentReport, len(found))
+	for _, report := range found {
+		byID[report.ID.String()] = report
+	}
+	reports := make([]models.IncidentReport, 0, len(found))
+	for _, id := range ids {
+		if report, ok := byID[id]; ok {
+			reports = append(reports, report)
+		}
+	}
+	return reports, nil
+}
+
+// EachReportBatch walks every report in batches of size, for bulk indexing.
+func (repo *incidentReportRepo) EachReportBatch(size int, fn func(reports []models.IncidentReport) error) error {
+	var batch []models.IncidentReport
+	return repo.DB.Order("id").FindInBatches(&batch, size, func(tx *gorm.DB, _ int) error {
+		return fn(batch)
+	}).Error
+}
+
+// escapeLike escapes the LIKE wildcards in s so it matches literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
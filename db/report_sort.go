@@ -0,0 +1,150 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/techagentng/citizenx/geo"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// The orders report listings can be sorted in
+const (
+	SortNewest          = "newest"
+	SortOldest          = "oldest"
+	SortMostEndorsed    = "most-endorsed"
+	SortRecentlyUpdated = "recently-updated"
+	SortNearest         = "nearest"
+	SortTrending        = "trending"
+)
+
+// ErrInvalidSort is returned for a sort that is not one of the listing
+// orders, or nearest without a location.
+var ErrInvalidSort = errors.New("sort must be one of newest, oldest, most-endorsed, recently-updated, trending or nearest (with lat and lng)")
+
+// ReportSort is the order a report listing is returned in. The zero value
+// lists the newest incidents first.
+type ReportSort struct {
+	Order string
+	// Latitude and Longitude are the point nearest sorts from
+	Latitude  float64
+	Longitude float64
+	// After continues the listing past a cursor instead of by page
+	After *ReportCursor
+}
+
+// ParseReportSort validates the sort, lat, lng and cursor query parameters.
+// An empty sort is kept empty, so callers can tell a default from a choice;
+// nearest needs lat and lng and is paged by page only.
+func ParseReportSort(order, lat, lng, cursor string) (ReportSort, error) {
+	sort := ReportSort{Order: strings.ToLower(strings.TrimSpace(order))}
+	switch sort.Order {
+	case "", SortNewest, SortOldest, SortMostEndorsed, SortRecentlyUpdated, SortTrending:
+	case SortNearest:
+		point, err := geo.ParseCoordinates(lat, lng)
+		if err != nil {
+			return ReportSort{}, fmt.Errorf("%w: nearest needs a valid lat and lng", ErrInvalidSort)
+		}
+		sort.Latitude, sort.Longitude = point.Latitude, point.Longitude
+	default:
+		return ReportSort{}, ErrInvalidSort
+	}
+	if cursor != "" {
+		after, err := decodeReportCursor(cursor, sort.order())
+		if err != nil || sort.Order == SortNearest {
+			return ReportSort{}, ErrInvalidCursor
+		}
+		sort.After = after
+	}
+	return sort, nil
+}
+
+// order is the sort applied, newest unless another was chosen
+func (s ReportSort) order() string {
+	if s.Order == "" {
+		return SortNewest
+	}
+	return s.Order
+}
+
+// endorsementsExpr and updatedExpr are the sort keys the expression indexes
+// in reportSortIndexes are built on; the ORDER BY must match them exactly
+// for Postgres to use the indexes.
+const (
+	endorsementsExpr = "(incident_reports.upvote_count + incident_reports.like_count)"
+	updatedExpr      = "GREATEST(incident_reports.status_updated_at, incident_reports.created_at)"
+)
+
+// reportSortIndexes back the sorts the column indexes on incident_reports
+// do not cover
+var reportSortIndexes = []string{
+	"CREATE INDEX IF NOT EXISTS idx_incident_reports_incidence_id ON incident_reports (timeof_incidence DESC, id DESC)",
+	"CREATE INDEX IF NOT EXISTS idx_incident_reports_endorsements ON incident_reports ((upvote_count + like_count) DESC, timeof_incidence DESC, id DESC)",
+	"CREATE INDEX IF NOT EXISTS idx_incident_reports_updated ON incident_reports (GREATEST(status_updated_at, created_at) DESC, id DESC)",
+	"CREATE INDEX IF NOT EXISTS idx_incident_reports_credibility ON incident_reports (credibility_score DESC, timeof_incidence DESC, id DESC)",
+}
+
+// createReportIndexes builds the expression indexes AutoMigrate cannot
+// declare from struct tags
+func createReportIndexes(db *gorm.DB) error {
+	for _, stmt := range append(reportSortIndexes, reportSearchIndexes...) {
+		if err := db.Exec(stmt).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Ranked reports whether a search for query is ordered by relevance, which
+// it is when no sort or cursor was given
+func (s ReportSort) Ranked(query string) bool {
+	return s.Order == "" && s.After == nil && strings.TrimSpace(query) != ""
+}
+
+// paginate orders query and limits it to one page: the page after the
+// cursor when there is one, otherwise the page numbered page
+func (s ReportSort) paginate(query *gorm.DB, page int) *gorm.DB {
+	query = s.apply(query).Limit(DefaultPageSize)
+	if s.After != nil {
+		return query
+	}
+	if page < 1 {
+		page = 1
+	}
+	return query.Offset((page - 1) * DefaultPageSize)
+}
+
+// apply orders query, ending every order with the report ID so pages never
+// overlap. Nearest ranks by the equirectangular distance, which keeps the
+// order of true distances at the scale of a listing, and leaves out reports
+// without a location.
+func (s ReportSort) apply(query *gorm.DB) *gorm.DB {
+	if s.After != nil {
+		query = s.After.after(query)
+	}
+	switch s.Order {
+	case SortOldest:
+		return query.Order("incident_reports.timeof_incidence ASC, incident_reports.id ASC")
+	case SortMostEndorsed:
+		return query.Order(endorsementsExpr + " DESC, incident_reports.timeof_incidence DESC, incident_reports.id DESC")
+	case SortRecentlyUpdated:
+		return query.Order(updatedExpr + " DESC, incident_reports.id DESC")
+	case SortTrending:
+		return query.Order("incident_reports.credibility_score DESC, incident_reports.timeof_incidence DESC, incident_reports.id DESC")
+	case SortNearest:
+		scale := math.Cos(s.Latitude * math.Pi / 180)
+		return query.
+			Where("NOT (incident_reports.latitude = 0 AND incident_reports.longitude = 0)").
+			Order(clause.OrderBy{Expression: clause.Expr{
+				SQL:                "POWER(incident_reports.latitude - ?, 2) + POWER((incident_reports.longitude - ?) * ?, 2)",
+				Vars:               []interface{}{s.Latitude, s.Longitude, scale},
+				WithoutParentheses: true,
+			}}).
+			Order("incident_reports.id")
+	default:
+		return query.Order("incident_reports.timeof_incidence DESC, incident_reports.id DESC")
+	}
+}
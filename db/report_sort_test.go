@@ -0,0 +1,54 @@
+package db_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techagentng/citizenx/db"
+)
+
+func FuzzParseReportSort(f *testing.F) {
+	cursor := db.ReportCursor{Order: db.SortNewest, Time: time.Unix(1700000000, 0).UTC(), ID: uuid.MustParse("6ba7b810-9dad-11d1-80b4-00c04fd430c8")}
+	f.Add("", "", "", "")
+	f.Add("newest", "", "", cursor.Encode())
+	f.Add("OLDEST", "", "", cursor.Encode())
+	f.Add("nearest", "6.6018", "3.3515", "")
+	f.Add("nearest", "NaN", "NaN", "")
+	f.Add("nearest", "91", "0", "")
+	f.Add("nearest", "6.6018", "3.3515", cursor.Encode())
+	f.Add("most-endorsed", "", "", "eyJvIjoibW9zdC1lbmRvcnNlZCJ9")
+	f.Add("trending", "", "", db.ReportCursor{Order: db.SortTrending, Time: cursor.Time, Value: 72, ID: cursor.ID}.Encode())
+	f.Add("newest", "", "", "not base64!")
+
+	f.Fuzz(func(t *testing.T, order, lat, lng, after string) {
+		sort, err := db.ParseReportSort(order, lat, lng, after)
+		if err != nil {
+			if !errors.Is(err, db.ErrInvalidSort) && !errors.Is(err, db.ErrInvalidCursor) {
+				t.Fatalf("ParseReportSort(%q, %q, %q, %q): unexpected error %v", order, lat, lng, after, err)
+			}
+			return
+		}
+
+		if sort.Order == db.SortNearest {
+			if math.IsNaN(sort.Latitude) || math.IsNaN(sort.Longitude) ||
+				math.Abs(sort.Latitude) > 90 || math.Abs(sort.Longitude) > 180 {
+				t.Fatalf("nearest accepted lat %q and lng %q as %v, %v", lat, lng, sort.Latitude, sort.Longitude)
+			}
+			if sort.After != nil {
+				t.Fatalf("nearest accepted cursor %q", after)
+			}
+		}
+		if sort.After != nil {
+			if sort.After.ID == uuid.Nil {
+				t.Fatalf("cursor %q was accepted without a report ID", after)
+			}
+			// Cursors handed back to clients must be accepted again
+			if _, err := db.ParseReportSort(order, lat, lng, sort.After.Encode()); err != nil {
+				t.Fatalf("cursor %q re-encoded as %q: %v", after, sort.After.Encode(), err)
+			}
+		}
+	})
+}
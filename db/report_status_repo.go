@@ -0,0 +1,127 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techagentng/citizenx/events"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// publishedStatuses is models.PublishedReportStatuses as an SQL list, for
+// aggregates that count published reports in a FILTER clause
+var publishedStatuses = "('" + strings.Join(models.PublishedReportStatuses, "', '") + "')"
+
+// ErrInvalidTransition is returned when a report cannot move from its
+// status to the one asked for.
+var ErrInvalidTransition = errors.New("report cannot move to that status")
+
+// ReportStatusRepository moves reports through their lifecycle and keeps
+// the history of each move
+type ReportStatusRepository interface {
+	TransitionStatus(reportID uuid.UUID, to string, changedBy *uint, reason string) (*models.ReportStatusTransition, error)
+	ListTransitions(reportID string) ([]models.ReportStatusTransition, error)
+}
+
+type reportStatusRepo struct {
+	DB *gorm.DB
+}
+
+func NewReportStatusRepo(db *GormDB) ReportStatusRepository {
+	return &reportStatusRepo{db.DB}
+}
+
+// TransitionStatus moves the report to status to, returning
+// ErrInvalidTransition unless the lifecycle allows the move from its
+// current status. The report is locked while it is checked, so two
+// moderators cannot both move it from the same status. The move is
+// recorded, audited and announced in the same transaction.
+func (r *reportStatusRepo) TransitionStatus(reportID uuid.UUID, to string, changedBy *uint, reason string) (*models.ReportStatusTransition, error) {
+	var transition *models.ReportStatusTransition
+	err := r.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		transition, err = transitionStatus(tx, reportID, to, changedBy, reason, time.Now(), nil)
+		return err
+	})
+	return transition, err
+}
+
+// transitionStatus does the work of TransitionStatus in tx, for changes that
+// move a report as part of something larger. updates are saved with the
+// new status.
+func transitionStatus(tx *gorm.DB, reportID uuid.UUID, to string, changedBy *uint, reason string, now time.Time, updates map[string]interface{}) (*models.ReportStatusTransition, error) {
+	to = models.NormalizeReportStatus(to)
+	var report models.IncidentReport
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Select("id", "user_id", "report_status", "resolved_at").
+		First(&report, "id = ?", reportID).Error; err != nil {
+		return nil, err
+	}
+	from := models.NormalizeReportStatus(report.ReportStatus)
+	if !models.CanTransitionReport(from, to) {
+		return nil, fmt.Errorf("%w: %s to %s", ErrInvalidTransition, from, to)
+	}
+
+	if updates == nil {
+		updates = map[string]interface{}{}
+	}
+	updates["report_status"] = to
+	updates["status_updated_at"] = now.Unix()
+	if to == models.ReportStatusResolved && report.ResolvedAt == 0 {
+		updates["resolved_at"] = now.Unix()
+	}
+	if err := tx.Model(&models.IncidentReport{}).Where("id = ?", report.ID).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+
+	transition, err := recordTransition(tx, report.ID, from, to, changedBy, reason, now)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeAudit(tx, changedBy, models.AuditReportStatusChanged, "incident_report", report.ID.String(), map[string]interface{}{
+		"transition_id": transition.ID,
+		"from":          from,
+		"to":            to,
+		"reason":        reason,
+	}); err != nil {
+		return nil, err
+	}
+	return transition, writeOutbox(tx, events.ReportStatusChanged{
+		TransitionID: transition.ID,
+		ReportID:     report.ID,
+		UserID:       report.UserID,
+		FromStatus:   from,
+		ToStatus:     to,
+		Reason:       reason,
+		OccurredAt:   now,
+	})
+}
+
+func (r *reportStatusRepo) ListTransitions(reportID string) ([]models.ReportStatusTransition, error) {
+	var transitions []models.ReportStatusTransition
+	err := r.DB.Where("report_id = ?", reportID).Order("created_at ASC, id ASC").Find(&transitions).Error
+	return transitions, err
+}
+
+// recordTransition adds a move to the report's status history. Code that
+// changes a report's status in bulk, or as part of a larger change, calls it
+// in its own transaction.
+func recordTransition(tx *gorm.DB, reportID uuid.UUID, from, to string, changedBy *uint, reason string, at time.Time) (*models.ReportStatusTransition, error) {
+	transition := &models.ReportStatusTransition{
+		ReportID:   reportID,
+		FromStatus: models.NormalizeReportStatus(from),
+		ToStatus:   to,
+		ChangedBy:  changedBy,
+		Reason:     reason,
+		CreatedAt:  at.Unix(),
+	}
+	if err := tx.Create(transition).Error; err != nil {
+		return nil, err
+	}
+	return transition, nil
+}
@@ -0,0 +1,59 @@
+package db
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+// ReportSubmissionRepository keeps the report submissions waiting in the
+// ingestion queue
+type ReportSubmissionRepository interface {
+	QueueSubmission(submission *models.ReportSubmission, jobType string) error
+	GetSubmission(id string) (*models.ReportSubmission, error)
+	UpdateSubmission(id uuid.UUID, status, reason string) error
+}
+
+type reportSubmissionRepo struct {
+	DB *gorm.DB
+}
+
+func NewReportSubmissionRepo(db *GormDB) ReportSubmissionRepository {
+	return &reportSubmissionRepo{db.DB}
+}
+
+// QueueSubmission stores the submission and queues a job of jobType to save
+// it, together, so an acknowledged submission is never lost
+func (r *reportSubmissionRepo) QueueSubmission(submission *models.ReportSubmission, jobType string) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(submission).Error; err != nil {
+			return err
+		}
+		return enqueueJob(tx, jobType, map[string]string{"submission_id": submission.ID.String()})
+	})
+}
+
+func (r *reportSubmissionRepo) GetSubmission(id string) (*models.ReportSubmission, error) {
+	var submission models.ReportSubmission
+	if err := r.DB.Where("id = ?", id).First(&submission).Error; err != nil {
+		return nil, err
+	}
+	return &submission, nil
+}
+
+// UpdateSubmission records an attempt to save the submission and its
+// outcome. The submitted report is dropped once it is saved or rejected.
+func (r *reportSubmissionRepo) UpdateSubmission(id uuid.UUID, status, reason string) error {
+	updates := map[string]interface{}{
+		"status":     status,
+		"error":      reason,
+		"attempts":   gorm.Expr("attempts + 1"),
+		"updated_at": time.Now().Unix(),
+	}
+	if status == models.SubmissionSaved || status == models.SubmissionRejected {
+		updates["payload"] = ""
+	}
+	return r.DB.Model(&models.ReportSubmission{}).Where("id = ?", id).Updates(updates).Error
+}
@@ -0,0 +1,148 @@
+package db
+
+import (
+	"errors"
+	"time"
+
+	"github.com/techagentng/citizenx/events"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrAlreadyOwner is returned when transferring a report to the account
+	// that owns it.
+	ErrAlreadyOwner = errors.New("report already belongs to the recipient")
+	// ErrRewardsSpent is returned when a report's points are to move with it
+	// but its reporter has already redeemed or lost them.
+	ErrRewardsSpent = errors.New("the reporter has already spent the report's points")
+)
+
+// ReportTransferRepository hands reports from one account to another and
+// keeps the record of each hand-over
+type ReportTransferRepository interface {
+	FindRecipient(userID uint, email string, telephoneIndexes []string) (*models.User, error)
+	TransferReport(transfer *models.ReportTransfer, moveRewards bool) error
+	ListTransfers(reportID string) ([]models.ReportTransfer, error)
+}
+
+type reportTransferRepo struct {
+	DB *gorm.DB
+}
+
+func NewReportTransferRepo(db *GormDB) ReportTransferRepository {
+	return &reportTransferRepo{db.DB}
+}
+
+// FindRecipient finds the active account with the ID, email address or
+// phone number given, trying them in that order
+func (r *reportTransferRepo) FindRecipient(userID uint, email string, telephoneIndexes []string) (*models.User, error) {
+	query := r.DB.Scopes(activeUsers)
+	switch {
+	case userID != 0:
+		query = query.Where("users.id = ?", userID)
+	case email != "":
+		query = query.Where("LOWER(users.email) = LOWER(?)", email)
+	case len(telephoneIndexes) > 0:
+		query = query.Where("users.telephone_index IN ?", telephoneIndexes)
+	default:
+		return nil, gorm.ErrRecordNotFound
+	}
+	var user models.User
+	if err := query.Order("users.id").First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// TransferReport moves the report to transfer.ToUserID along with the media
+// its reporter added and, when moveRewards is set, the points the reporter
+// earned for it. Points move only while the reporter's balance still holds
+// them: debits stay with the reporter, so moving points already paid out or
+// expired would pay them twice, and ErrRewardsSpent is returned instead.
+// Collaborators keep their media and shares, except the
+// recipient, who stops being a collaborator. It returns ErrAlreadyOwner
+// when the recipient owns the report already. FromUserID is filled in with
+// the reporter the report was taken from; the transfer is recorded, audited
+// and announced in the same transaction.
+func (r *reportTransferRepo) TransferReport(transfer *models.ReportTransfer, moveRewards bool) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		var report models.IncidentReport
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "user_id").
+			First(&report, "id = ?", transfer.ReportID).Error; err != nil {
+			return err
+		}
+		if report.UserID == transfer.ToUserID {
+			return ErrAlreadyOwner
+		}
+		transfer.FromUserID = report.UserID
+		from, to := report.UserID, transfer.ToUserID
+
+		if err := tx.Model(&models.IncidentReport{}).Where("id = ?", report.ID).Update("user_id", to).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.ReportType{}).Where("incident_report_id = ? AND user_id = ?", report.ID, from).Update("user_id", to).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Media{}).Where("incident_report_id = ? AND user_id = ?", report.ID, from).Update("user_id", to).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("report_id = ? AND user_id = ?", report.ID, to).Delete(&models.ReportCollaborator{}).Error; err != nil {
+			return err
+		}
+		if moveRewards {
+			if err := tx.Exec("SELECT id FROM users WHERE id = ? FOR UPDATE", from).Error; err != nil {
+				return err
+			}
+			var points, balance int64
+			if err := tx.Raw(`SELECT COALESCE(SUM(point), 0) FROM rewards
+				WHERE incident_report_id = ? AND user_id = ? AND deleted_at = 0`, report.ID.String(), from).Scan(&points).Error; err != nil {
+				return err
+			}
+			if err := tx.Raw(`SELECT
+				COALESCE((SELECT SUM(point) FROM rewards WHERE user_id = ? AND deleted_at = 0), 0) -
+				COALESCE((SELECT SUM(points) FROM point_debits WHERE user_id = ?), 0)`,
+				from, from).Scan(&balance).Error; err != nil {
+				return err
+			}
+			if points > balance {
+				return ErrRewardsSpent
+			}
+			result := tx.Model(&models.Reward{}).
+				Where("incident_report_id = ? AND user_id = ?", report.ID.String(), from).
+				Update("user_id", to)
+			if result.Error != nil {
+				return result.Error
+			}
+			transfer.RewardsMoved = result.RowsAffected
+		}
+
+		if err := tx.Create(transfer).Error; err != nil {
+			return err
+		}
+		if err := writeAudit(tx, &transfer.TransferredBy, models.AuditReportTransferred, "incident_report", report.ID.String(), map[string]interface{}{
+			"transfer_id":   transfer.ID,
+			"from_user_id":  from,
+			"to_user_id":    to,
+			"reason":        transfer.Reason,
+			"rewards_moved": transfer.RewardsMoved,
+		}); err != nil {
+			return err
+		}
+		return writeOutbox(tx, events.ReportTransferred{
+			TransferID: transfer.ID,
+			ReportID:   report.ID,
+			FromUserID: from,
+			ToUserID:   to,
+			OccurredAt: time.Unix(transfer.CreatedAt, 0),
+		})
+	})
+}
+
+func (r *reportTransferRepo) ListTransfers(reportID string) ([]models.ReportTransfer, error) {
+	var transfers []models.ReportTransfer
+	err := r.DB.Where("report_id = ?", reportID).Order("created_at DESC, id DESC").Find(&transfers).Error
+	return transfers, err
+}
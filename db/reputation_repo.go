@@ -0,0 +1,200 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReputationEvidence is the decayed history a reporter's reputation is
+// computed from
+type ReputationEvidence struct {
+	UserID          uint
+	VerifiedReports float64
+	RejectedReports float64
+	Flags           float64
+	AccurateVotes   float64
+	InaccurateVotes float64
+	// IdentityVerified is set for reporters whose NIN or BVN is verified
+	IdentityVerified bool
+}
+
+// ReputationRepository gathers the history behind reporter reputations and
+// stores the scores
+type ReputationRepository interface {
+	ReputationEvidence(now time.Time, halfLife time.Duration) ([]ReputationEvidence, error)
+	SaveReputations(reputations []models.ReporterReputation) error
+	GetReputation(userID uint) (*models.ReporterReputation, error)
+	GetReputations(userIDs []uint) ([]models.ReporterReputation, error)
+	PendingReportsByReputation(page int, flaggedOnly bool) ([]models.IncidentReport, error)
+}
+
+type reputationRepo struct {
+	DB *gorm.DB
+}
+
+func NewReputationRepo(db *GormDB) ReputationRepository {
+	return &reputationRepo{db.DB}
+}
+
+// ReputationEvidence weighs every moderated report, media reuse flag,
+// failed audit and vote on a moderated report by its age, halving the weight every
+// halfLife. Reports are aged from their last status change. Reporters with
+// a verified identity are included even without any history.
+func (r *reputationRepo) ReputationEvidence(now time.Time, halfLife time.Duration) ([]ReputationEvidence, error) {
+	decay := func(column string) string {
+		return fmt.Sprintf("POWER(0.5, GREATEST(%d - %s, 0)::float / %d)", now.Unix(), column, int64(halfLife.Seconds()))
+	}
+	reportAge := decay("CASE WHEN incident_reports.status_updated_at > 0 THEN incident_reports.status_updated_at ELSE incident_reports.created_at END")
+	// Moderated reports are the published ones and those rejected
+	reviewedStatuses := append([]string{models.ReportStatusRejected}, models.PublishedReportStatuses...)
+
+	evidence := map[uint]*ReputationEvidence{}
+	get := func(userID uint) *ReputationEvidence {
+		if evidence[userID] == nil {
+			evidence[userID] = &ReputationEvidence{UserID: userID}
+		}
+		return evidence[userID]
+	}
+
+	var reports []struct {
+		UserID   uint
+		Verified float64
+		Rejected float64
+	}
+	if err := r.DB.Table("incident_reports").
+		Select("user_id, "+
+			"COALESCE(SUM("+reportAge+") FILTER (WHERE LOWER(report_status) IN "+publishedStatuses+"), 0) AS verified, "+
+			"COALESCE(SUM("+reportAge+") FILTER (WHERE LOWER(report_status) = 'rejected'), 0) AS rejected").
+		Where("user_id > 0 AND LOWER(report_status) IN ?", reviewedStatuses).
+		Group("user_id").
+		Scan(&reports).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range reports {
+		e := get(row.UserID)
+		e.VerifiedReports, e.RejectedReports = row.Verified, row.Rejected
+	}
+
+	var flags []struct {
+		UserID uint
+		Flags  float64
+	}
+	if err := r.DB.Table("media_reuse_flags").
+		Select("incident_reports.user_id, SUM(" + decay("media_reuse_flags.created_at") + ") AS flags").
+		Joins("JOIN incident_reports ON incident_reports.id::text = media_reuse_flags.incident_report_id").
+		Where("incident_reports.user_id > 0").
+		Group("incident_reports.user_id").
+		Scan(&flags).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range flags {
+		get(row.UserID).Flags = row.Flags
+	}
+
+	// A failed audit of an auto-published report weighs like a flag
+	var failedAudits []struct {
+		UserID uint
+		Flags  float64
+	}
+	if err := r.DB.Table("report_audits").
+		Select("user_id, SUM("+decay("audited_at")+") AS flags").
+		Where("outcome = ?", models.AuditOutcomeFailed).
+		Group("user_id").
+		Scan(&failedAudits).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range failedAudits {
+		get(row.UserID).Flags += row.Flags
+	}
+
+	var votes []struct {
+		UserID     uint
+		Accurate   float64
+		Inaccurate float64
+	}
+	if err := r.DB.Table("report_votes").
+		Select("report_votes.user_id, "+
+			"COALESCE(SUM("+decay("report_votes.updated_at")+") FILTER (WHERE (report_votes.value > 0) = (LOWER(incident_reports.report_status) IN "+publishedStatuses+")), 0) AS accurate, "+
+			"COALESCE(SUM("+decay("report_votes.updated_at")+") FILTER (WHERE (report_votes.value > 0) <> (LOWER(incident_reports.report_status) IN "+publishedStatuses+")), 0) AS inaccurate").
+		Joins("JOIN incident_reports ON incident_reports.id = report_votes.report_id").
+		Where("report_votes.user_id > 0 AND LOWER(incident_reports.report_status) IN ?", reviewedStatuses).
+		Group("report_votes.user_id").
+		Scan(&votes).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range votes {
+		e := get(row.UserID)
+		e.AccurateVotes, e.InaccurateVotes = row.Accurate, row.Inaccurate
+	}
+
+	var verified []uint
+	if err := r.DB.Model(&models.User{}).
+		Where("identity_verified_at IS NOT NULL").
+		Pluck("id", &verified).Error; err != nil {
+		return nil, err
+	}
+	for _, userID := range verified {
+		get(userID).IdentityVerified = true
+	}
+
+	all := make([]ReputationEvidence, 0, len(evidence))
+	for _, e := range evidence {
+		all = append(all, *e)
+	}
+	return all, nil
+}
+
+// SaveReputations stores reputations, replacing each reporter's previous
+// score. Suspensions are left in place.
+func (r *reputationRepo) SaveReputations(reputations []models.ReporterReputation) error {
+	if len(reputations) == 0 {
+		return nil
+	}
+	return r.DB.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"score", "tier", "verified_reports", "rejected_reports", "flags",
+			"accurate_votes", "inaccurate_votes", "identity_verified", "computed_at",
+		}),
+	}).CreateInBatches(reputations, 500).Error
+}
+
+func (r *reputationRepo) GetReputation(userID uint) (*models.ReporterReputation, error) {
+	var reputation models.ReporterReputation
+	if err := r.DB.First(&reputation, "user_id = ?", userID).Error; err != nil {
+		return nil, err
+	}
+	return &reputation, nil
+}
+
+func (r *reputationRepo) GetReputations(userIDs []uint) ([]models.ReporterReputation, error) {
+	var reputations []models.ReporterReputation
+	if len(userIDs) == 0 {
+		return reputations, nil
+	}
+	err := r.DB.Where("user_id IN ?", userIDs).Find(&reputations).Error
+	return reputations, err
+}
+
+// PendingReportsByReputation returns reports awaiting moderation, those of
+// the most reputable reporters first and otherwise oldest first. Reporters
+// without a score rank as neutral.
+func (r *reputationRepo) PendingReportsByReputation(page int, flaggedOnly bool) ([]models.IncidentReport, error) {
+	var reports []models.IncidentReport
+	db := r.DB.Model(&models.IncidentReport{}).
+		Select("incident_reports.*").
+		Joins("LEFT JOIN reporter_reputations ON reporter_reputations.user_id = incident_reports.user_id").
+		Where("incident_reports.report_status = 'pending' OR incident_reports.report_status = ''")
+	if flaggedOnly {
+		db = db.Where("incident_reports.network_flag <> ''")
+	}
+	err := db.Order("COALESCE(reporter_reputations.score, 50) DESC, incident_reports.created_at ASC").
+		Offset((page - 1) * DefaultPageSize).
+		Limit(DefaultPageSize).
+		Find(&reports).Error
+	return reports, err
+}
@@ -0,0 +1,51 @@
+package db
+
+import (
+	"time"
+
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+// RewardBonusRepository finds the areas whose reports earn bonus points
+type RewardBonusRepository interface {
+	UnderReportedAreas(since time.Time, share float64) ([]models.BonusArea, error)
+}
+
+type rewardBonusRepo struct {
+	DB *gorm.DB
+}
+
+func NewRewardBonusRepo(db *GormDB) RewardBonusRepository {
+	return &rewardBonusRepo{db.DB}
+}
+
+// UnderReportedAreas returns the LGAs whose reports since the time given
+// number at most share of the median LGA of their state. LGAs are those
+// known to the lgas table, so LGAs nobody has reported from yet are
+// included and names made up in reports are not.
+func (r *rewardBonusRepo) UnderReportedAreas(since time.Time, share float64) ([]models.BonusArea, error) {
+	var areas []models.BonusArea
+	err := r.DB.Raw(`
+        WITH areas AS (
+            SELECT DISTINCT states.name AS state_name, lgas.name AS lga_name
+            FROM lgas JOIN states ON states.id = lgas.state_id
+        ), counts AS (
+            SELECT areas.state_name, areas.lga_name, COUNT(incident_reports.id) AS recent_reports
+            FROM areas
+            LEFT JOIN incident_reports ON LOWER(incident_reports.state_name) = LOWER(areas.state_name)
+                AND LOWER(incident_reports.lga_name) = LOWER(areas.lga_name)
+                AND incident_reports.created_at >= ?
+            GROUP BY areas.state_name, areas.lga_name
+        ), medians AS (
+            SELECT state_name, PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY recent_reports) AS median
+            FROM counts
+            GROUP BY state_name
+        )
+        SELECT counts.state_name, counts.lga_name, counts.recent_reports
+        FROM counts JOIN medians ON medians.state_name = counts.state_name
+        WHERE counts.recent_reports <= medians.median * ?
+        ORDER BY counts.state_name, counts.lga_name
+    `, since.Unix(), share).Scan(&areas).Error
+	return areas, err
+}
@@ -2,8 +2,11 @@ package db
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/pkg/errors"
+	"github.com/techagentng/citizenx/events"
 	"github.com/techagentng/citizenx/models"
 	"gorm.io/gorm"
 )
@@ -13,7 +16,8 @@ type RewardRepository interface {
 	// BuyPoints(userID uint, amount float64) error
 	// RewardForReport(report models.IncidentReport) error
 	GetRewardsByUserID(userID uint) (*models.Reward, error)
-	SaveReward(reward *models.Reward) error
+	SaveReward(reward *models.Reward, evts ...events.Event) error
+	ApproveReport(reportID uuid.UUID, userID, moderatorID uint, rewards []models.Reward, evts ...events.Event) (*models.ReportStatusTransition, error)
 	GetReportByID(reportID string) (*models.IncidentReport, error)
 	GetCurrentRewardByUserID(userID uint) (int, error)
 	GetRewardPointByReportID(reportID string) (int, error)
@@ -40,21 +44,62 @@ func (r *rewardRepo) GetRewardsByUserID(userID uint) (*models.Reward, error) {
 	return &reward, nil
 }
 
-func (repo *rewardRepo) SaveReward(reward *models.Reward) error {
+// SaveReward creates or tops up the user's reward for a report, recording
+// any events about it in the same transaction.
+func (repo *rewardRepo) SaveReward(reward *models.Reward, evts ...events.Event) error {
+	return repo.DB.Transaction(func(tx *gorm.DB) error {
+		if err := saveReward(tx, reward); err != nil {
+			return err
+		}
+		return writeOutbox(tx, evts...)
+	})
+}
+
+// ApproveReport approves a report and pays out its rewards in one
+// transaction, so an approval never stands without its points or its
+// events. The ReportVerified event for userID, the reporter, is built
+// here from the transition.
+func (repo *rewardRepo) ApproveReport(reportID uuid.UUID, userID, moderatorID uint, rewards []models.Reward, evts ...events.Event) (*models.ReportStatusTransition, error) {
+	var transition *models.ReportStatusTransition
+	err := repo.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		var err error
+		transition, err = transitionStatus(tx, reportID, models.ReportStatusApproved, &moderatorID, "", now, nil)
+		if err != nil {
+			return err
+		}
+		for i := range rewards {
+			if err := saveReward(tx, &rewards[i]); err != nil {
+				return err
+			}
+		}
+		evts = append([]events.Event{events.ReportVerified{
+			ReportID:     reportID,
+			UserID:       userID,
+			Status:       models.ReportStatusApproved,
+			TransitionID: transition.ID,
+			OccurredAt:   now,
+		}}, evts...)
+		return writeOutbox(tx, evts...)
+	})
+	return transition, err
+}
+
+// saveReward does the work of SaveReward in tx
+func saveReward(tx *gorm.DB, reward *models.Reward) error {
 	var existingReward models.Reward
-	err := repo.DB.Where("user_id = ? AND incident_report_id = ?", reward.UserID, reward.IncidentReportID).First(&existingReward).Error
+	err := tx.Where("user_id = ? AND incident_report_id = ?", reward.UserID, reward.IncidentReportID).First(&existingReward).Error
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			// Create new reward entry
-			return repo.DB.Create(reward).Error
+		if err != gorm.ErrRecordNotFound {
+			return err
 		}
-		return err
+		// Create new reward entry
+		return tx.Create(reward).Error
 	}
-
 	// Update existing reward
 	existingReward.Point += reward.Point
 	existingReward.Balance = reward.Balance
-	return repo.DB.Save(&existingReward).Error
+	return tx.Save(&existingReward).Error
 }
 
 func (r *rewardRepo) GetReportByID(reportID string) (*models.IncidentReport, error) {
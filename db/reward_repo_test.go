@@ -0,0 +1,44 @@
+package db_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/testutil"
+)
+
+func TestApproveReport(t *testing.T) {
+	gormDB := testutil.Tx(t)
+	repo := db.NewRewardRepo(gormDB)
+	report := testutil.CreateIncidentReport(t, gormDB.DB)
+	reward := func() []models.Reward {
+		return []models.Reward{{IncidentReportID: report.ID.String(), UserID: report.UserID, RewardType: "Another Entry", Point: 10, Balance: 10}}
+	}
+
+	transition, err := repo.ApproveReport(report.ID, report.UserID, 1, reward())
+	if err != nil {
+		t.Fatalf("approving report: %v", err)
+	}
+	if transition.ToStatus != models.ReportStatusApproved {
+		t.Fatalf("got transition to %q, want approved", transition.ToStatus)
+	}
+	var outbox int64
+	gormDB.DB.Model(&models.OutboxEvent{}).Where("dedup_key LIKE ?", "%"+report.ID.String()+"%").Count(&outbox)
+	if outbox != 1 {
+		t.Fatalf("approval wrote %d outbox events, want the ReportVerified event", outbox)
+	}
+
+	// A second approval is refused as a whole, so no points are paid twice
+	if _, err := repo.ApproveReport(report.ID, report.UserID, 1, reward()); !errors.Is(err, db.ErrInvalidTransition) {
+		t.Fatalf("approving twice returned %v, want ErrInvalidTransition", err)
+	}
+	var saved models.Reward
+	if err := gormDB.DB.Where("incident_report_id = ?", report.ID.String()).First(&saved).Error; err != nil {
+		t.Fatalf("fetching reward: %v", err)
+	}
+	if saved.Point != 10 {
+		t.Fatalf("reward has %d points after a refused approval, want 10", saved.Point)
+	}
+}
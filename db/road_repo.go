@@ -0,0 +1,143 @@
+package db
+
+import (
+	"github.com/google/uuid"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RoadRepository persists road segments and the road-condition reports
+// lying on them
+type RoadRepository interface {
+	CreateSegment(segment *models.RoadSegment) error
+	GetSegment(id uint) (*models.RoadSegment, error)
+	ListSegments(stateName, road string, page int) ([]models.RoadSegment, error)
+	SegmentsInBox(south, west, north, east float64) ([]models.RoadSegment, error)
+	GetReportRoute(reportID string) (*models.IncidentReport, error)
+	RoadReportsInBox(categories []string, south, west, north, east float64) ([]models.IncidentReport, error)
+	LinkReportSegments(reportID uuid.UUID, segmentIDs []uint, linkedAt int64) error
+	SegmentConditions(stateName, road string, since int64) ([]models.RoadSegmentCondition, error)
+	SegmentReports(segmentID uint, page int) ([]models.IncidentReport, error)
+}
+
+type roadRepo struct {
+	DB *gorm.DB
+}
+
+func NewRoadRepo(db *GormDB) RoadRepository {
+	return &roadRepo{db.DB}
+}
+
+func (r *roadRepo) CreateSegment(segment *models.RoadSegment) error {
+	return r.DB.Create(segment).Error
+}
+
+func (r *roadRepo) GetSegment(id uint) (*models.RoadSegment, error) {
+	var segment models.RoadSegment
+	if err := r.DB.First(&segment, id).Error; err != nil {
+		return nil, err
+	}
+	return &segment, nil
+}
+
+func (r *roadRepo) ListSegments(stateName, road string, page int) ([]models.RoadSegment, error) {
+	var segments []models.RoadSegment
+	db := r.DB
+	if stateName != "" {
+		db = db.Where("LOWER(state_name) = LOWER(?)", stateName)
+	}
+	if road != "" {
+		db = db.Where("LOWER(road) = LOWER(?)", road)
+	}
+	err := db.Order("road, name").
+		Offset((page - 1) * DefaultPageSize).
+		Limit(DefaultPageSize).
+		Find(&segments).Error
+	return segments, err
+}
+
+// SegmentsInBox returns the segments whose bounding box overlaps the given one
+func (r *roadRepo) SegmentsInBox(south, west, north, east float64) ([]models.RoadSegment, error) {
+	var segments []models.RoadSegment
+	err := r.DB.Where("south <= ? AND north >= ? AND west <= ? AND east >= ?", north, south, east, west).
+		Find(&segments).Error
+	return segments, err
+}
+
+// GetReportRoute loads the fields of a report that place it on a road
+func (r *roadRepo) GetReportRoute(reportID string) (*models.IncidentReport, error) {
+	var report models.IncidentReport
+	err := r.DB.Select("id", "category", "latitude", "longitude", "route").
+		Where("id = ?", reportID).
+		First(&report).Error
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// RoadReportsInBox returns the reports in the categories located inside the
+// bounding box, for linking reports submitted before a segment was added
+func (r *roadRepo) RoadReportsInBox(categories []string, south, west, north, east float64) ([]models.IncidentReport, error) {
+	var reports []models.IncidentReport
+	err := r.DB.Select("id", "category", "latitude", "longitude", "route").
+		Where("LOWER(category) IN ?", categories).
+		Where("latitude BETWEEN ? AND ? AND longitude BETWEEN ? AND ?", south, north, west, east).
+		Find(&reports).Error
+	return reports, err
+}
+
+func (r *roadRepo) LinkReportSegments(reportID uuid.UUID, segmentIDs []uint, linkedAt int64) error {
+	if len(segmentIDs) == 0 {
+		return nil
+	}
+	links := make([]models.ReportRoadSegment, len(segmentIDs))
+	for i, id := range segmentIDs {
+		links[i] = models.ReportRoadSegment{ReportID: reportID, SegmentID: id, CreatedAt: linkedAt}
+	}
+	return r.DB.Clauses(clause.OnConflict{DoNothing: true}).Create(&links).Error
+}
+
+// SegmentConditions aggregates the reports on each segment submitted since
+// the given time, segments with the most unresolved reports first. Rejected
+// reports are left out.
+func (r *roadRepo) SegmentConditions(stateName, road string, since int64) ([]models.RoadSegmentCondition, error) {
+	var conditions []models.RoadSegmentCondition
+	db := r.DB.Table("road_segments").
+		Select(`road_segments.id AS segment_id, road_segments.road, road_segments.name,
+            road_segments.state_name, road_segments.length_m,
+            COUNT(incident_reports.id) AS reports,
+            COUNT(incident_reports.id) FILTER (WHERE LOWER(incident_reports.report_status) IN `+publishedStatuses+`) AS verified,
+            COUNT(incident_reports.id) FILTER (WHERE incident_reports.resolved_at = 0) AS unresolved,
+            COALESCE(COUNT(incident_reports.id) * 1000.0 / NULLIF(road_segments.length_m, 0), 0) AS reports_per_km,
+            COALESCE(MODE() WITHIN GROUP (ORDER BY NULLIF(incident_reports.sub_report_type, '')), '') AS top_issue,
+            COALESCE(MAX(incident_reports.created_at), 0) AS last_reported_at`).
+		Joins("LEFT JOIN report_road_segments ON report_road_segments.segment_id = road_segments.id").
+		Joins(`LEFT JOIN incident_reports ON incident_reports.id = report_road_segments.report_id
+            AND incident_reports.created_at >= ?
+            AND LOWER(COALESCE(incident_reports.report_status, '')) <> 'rejected'`, since)
+	if stateName != "" {
+		db = db.Where("LOWER(road_segments.state_name) = LOWER(?)", stateName)
+	}
+	if road != "" {
+		db = db.Where("LOWER(road_segments.road) = LOWER(?)", road)
+	}
+	err := db.Group("road_segments.id").
+		Order("unresolved DESC, reports DESC, road_segments.id").
+		Scan(&conditions).Error
+	return conditions, err
+}
+
+// SegmentReports returns the reports on a segment, newest first
+func (r *roadRepo) SegmentReports(segmentID uint, page int) ([]models.IncidentReport, error) {
+	var reports []models.IncidentReport
+	err := r.DB.Model(&models.IncidentReport{}).
+		Joins("JOIN report_road_segments ON report_road_segments.report_id = incident_reports.id").
+		Where("report_road_segments.segment_id = ?", segmentID).
+		Order("incident_reports.created_at DESC").
+		Offset((page - 1) * DefaultPageSize).
+		Limit(DefaultPageSize).
+		Find(&reports).Error
+	return reports, err
+}
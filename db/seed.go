@@ -0,0 +1,248 @@
+package db
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techagentng/citizenx/models"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// DemoPassword is the password every seeded demo user logs in with.
+const DemoPassword = "citizenx-demo"
+
+type seedLGA struct {
+	Name     string
+	Lat, Lng float64
+}
+
+// seedStates lists the states and a handful of their LGAs, each with an
+// approximate centre used to scatter seeded reports.
+var seedStates = map[string][]seedLGA{
+	"Abia":        {{"Umuahia North", 5.5320, 7.4860}, {"Aba South", 5.1066, 7.3667}},
+	"Abuja":       {{"Abuja Municipal", 9.0579, 7.4951}, {"Bwari", 9.2833, 7.3833}, {"Gwagwalada", 8.9430, 7.0810}},
+	"Adamawa":     {{"Yola North", 9.2035, 12.4954}, {"Mubi North", 10.2676, 13.2644}},
+	"Akwa Ibom":   {{"Uyo", 5.0377, 7.9128}, {"Eket", 4.6423, 7.9244}},
+	"Anambra":     {{"Awka South", 6.2100, 7.0700}, {"Onitsha North", 6.1667, 6.7833}},
+	"Bauchi":      {{"Bauchi", 10.3158, 9.8442}, {"Azare", 11.6765, 10.1948}},
+	"Bayelsa":     {{"Yenagoa", 4.9247, 6.2676}},
+	"Benue":       {{"Makurdi", 7.7337, 8.5214}, {"Gboko", 7.3167, 9.0000}},
+	"Borno":       {{"Maiduguri", 11.8333, 13.1500}, {"Biu", 10.6111, 12.1950}},
+	"Cross River": {{"Calabar Municipal", 4.9757, 8.3417}, {"Ikom", 5.9667, 8.7167}},
+	"Delta":       {{"Warri South", 5.5167, 5.7500}, {"Oshimili South", 6.2000, 6.7333}},
+	"Ebonyi":      {{"Abakaliki", 6.3249, 8.1137}},
+	"Edo":         {{"Oredo", 6.3350, 5.6037}, {"Etsako West", 7.0667, 6.2667}},
+	"Ekiti":       {{"Ado Ekiti", 7.6211, 5.2214}},
+	"Enugu":       {{"Enugu North", 6.4584, 7.5464}, {"Nsukka", 6.8567, 7.3958}},
+	"Gombe":       {{"Gombe", 10.2897, 11.1673}},
+	"Imo":         {{"Owerri Municipal", 5.4836, 7.0333}, {"Orlu", 5.7957, 7.0351}},
+	"Jigawa":      {{"Dutse", 11.7564, 9.3389}},
+	"Kaduna":      {{"Kaduna North", 10.5222, 7.4383}, {"Zaria", 11.0855, 7.7199}},
+	"Kano":        {{"Kano Municipal", 12.0022, 8.5920}, {"Nassarawa", 11.9833, 8.5500}, {"Fagge", 12.0000, 8.5167}},
+	"Katsina":     {{"Katsina", 12.9908, 7.6018}, {"Funtua", 11.5233, 7.3081}},
+	"Kebbi":       {{"Birnin Kebbi", 12.4539, 4.1975}},
+	"Kogi":        {{"Lokoja", 7.8023, 6.7333}},
+	"Kwara":       {{"Ilorin West", 8.4966, 4.5421}},
+	"Lagos":       {{"Ikeja", 6.6018, 3.3515}, {"Eti-Osa", 6.4589, 3.6015}, {"Alimosho", 6.6100, 3.2960}, {"Surulere", 6.5000, 3.3500}, {"Epe", 6.5841, 3.9834}},
+	"Nasarawa":    {{"Lafia", 8.4939, 8.5153}, {"Keffi", 8.8486, 7.8736}},
+	"Niger":       {{"Chanchaga", 9.6139, 6.5569}, {"Bida", 9.0804, 6.0100}},
+	"Ogun":        {{"Abeokuta South", 7.1475, 3.3619}, {"Ijebu Ode", 6.8200, 3.9200}},
+	"Ondo":        {{"Akure South", 7.2571, 5.2058}},
+	"Osun":        {{"Osogbo", 7.7827, 4.5418}, {"Ife Central", 7.4824, 4.5603}},
+	"Oyo":         {{"Ibadan North", 7.3986, 3.9170}, {"Ogbomosho North", 8.1333, 4.2500}},
+	"Plateau":     {{"Jos North", 9.8965, 8.8583}},
+	"Rivers":      {{"Port Harcourt", 4.8156, 7.0498}, {"Obio/Akpor", 4.8500, 7.0167}},
+	"Sokoto":      {{"Sokoto North", 13.0609, 5.2476}},
+	"Taraba":      {{"Jalingo", 8.8833, 11.3667}},
+	"Yobe":        {{"Damaturu", 11.7470, 11.9608}},
+	"Zamfara":     {{"Gusau", 12.1628, 6.6614}},
+}
+
+// seedCategories maps each report category to the sub report types shown
+// for it in the app.
+var seedCategories = map[string][]string{
+	"Roads":       {"Potholes", "Road accident", "Traffic congestion", "Broken bridge"},
+	"Electricity": {"Power outage", "Fallen pole", "Estimated billing"},
+	"Water":       {"No water supply", "Burst pipe", "Contaminated water"},
+	"Health":      {"No doctors on duty", "Drug shortage", "Poor sanitation"},
+	"Education":   {"Teacher absence", "Collapsed classroom", "Illegal levies"},
+	"Security":    {"Robbery", "Kidnapping", "Police extortion"},
+	"Environment": {"Flooding", "Blocked drainage", "Illegal dumping"},
+	"Election":    {"Vote buying", "Ballot snatching", "Late materials"},
+}
+
+var seedDescriptions = []string{
+	"Residents have complained about this for weeks with no response.",
+	"Happened again this morning, people are stranded.",
+	"The situation is getting worse every day.",
+	"Officials came around but nothing has been done.",
+	"This is affecting businesses in the area.",
+	"Children and the elderly are the most affected.",
+	"Reported to the local office already, still waiting.",
+}
+
+var seedNames = []string{
+	"Adaeze Okafor", "Babatunde Adeyemi", "Chinedu Eze", "Fatima Bello", "Ibrahim Musa",
+	"Kemi Adebayo", "Ngozi Nwosu", "Olumide Bakare", "Tunde Ogunleye", "Zainab Abubakar",
+}
+
+var seedStatuses = []string{"pending", "pending", "approved", "accepted", "rejected"}
+
+// SeedDemoData fills an empty database with states, LGAs, categories, demo
+// users, reportCount incident reports scattered across Nigeria, and the
+// rewards earned on approved reports. States, LGAs and users are matched by
+// name or email, so running it twice only adds more reports.
+func SeedDemoData(db *gorm.DB, reportCount int, rng *rand.Rand) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := SeedRoles(tx); err != nil {
+			return err
+		}
+		var role models.Role
+		if err := tx.Where("name = ?", models.RoleUser).First(&role).Error; err != nil {
+			return fmt.Errorf("loading %s role: %w", models.RoleUser, err)
+		}
+
+		// Seed states and their LGAs in a stable order so a seeded rng
+		// always produces the same data
+		stateNames := make([]string, 0, len(seedStates))
+		for stateName := range seedStates {
+			stateNames = append(stateNames, stateName)
+		}
+		sort.Strings(stateNames)
+
+		var lgas []seedLGA
+		lgaStates := map[string]string{}
+		for _, stateName := range stateNames {
+			stateLGAs := seedStates[stateName]
+			state := models.State{ID: uuid.New(), Name: stateName}
+			if err := tx.Where(models.State{Name: stateName}).FirstOrCreate(&state).Error; err != nil {
+				return fmt.Errorf("seeding state %s: %w", stateName, err)
+			}
+			for _, l := range stateLGAs {
+				lga := models.LGA{ID: uuid.New(), Name: l.Name, StateID: state.ID}
+				if err := tx.Where(models.LGA{Name: l.Name, StateID: state.ID}).FirstOrCreate(&lga).Error; err != nil {
+					return fmt.Errorf("seeding lga %s: %w", l.Name, err)
+				}
+				lgas = append(lgas, l)
+				lgaStates[l.Name] = stateName
+			}
+		}
+
+		// Seed demo users
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(DemoPassword), bcrypt.DefaultCost)
+		if err != nil {
+			return err
+		}
+		users := make([]models.User, len(seedNames))
+		for i, name := range seedNames {
+			users[i] = models.User{
+				Fullname:       name,
+				Username:       fmt.Sprintf("demo%d", i+1),
+				Telephone:      fmt.Sprintf("+2348000000%03d", i+1),
+				Email:          fmt.Sprintf("demo%d@citizenx.ng", i+1),
+				HashedPassword: string(hashedPassword),
+				IsEmailActive:  true,
+				IsVerified:     true,
+				LGAName:        lgas[i%len(lgas)].Name,
+				RoleID:         role.ID,
+			}
+			if err := tx.Where(models.User{Email: users[i].Email}).FirstOrCreate(&users[i]).Error; err != nil {
+				return fmt.Errorf("seeding user %s: %w", users[i].Email, err)
+			}
+		}
+
+		categories := make([]string, 0, len(seedCategories))
+		for category := range seedCategories {
+			categories = append(categories, category)
+		}
+		sort.Strings(categories)
+
+		// Seed incident reports over the last ninety days
+		now := time.Now()
+		for i := 0; i < reportCount; i++ {
+			user := users[rng.Intn(len(users))]
+			lga := lgas[rng.Intn(len(lgas))]
+			category := categories[rng.Intn(len(categories))]
+			subTypes := seedCategories[category]
+			subType := subTypes[rng.Intn(len(subTypes))]
+			occurredAt := now.Add(-time.Duration(rng.Int63n(int64(90 * 24 * time.Hour))))
+			status := seedStatuses[rng.Intn(len(seedStatuses))]
+			rating := "bad"
+			if rng.Intn(4) == 0 {
+				rating = "good"
+			}
+
+			reportType := models.ReportType{
+				ID:                   uuid.New(),
+				UserID:               user.ID,
+				Category:             category,
+				StateName:            lgaStates[lga.Name],
+				LGAName:              lga.Name,
+				IncidentReportRating: rating,
+				DateOfIncidence:      occurredAt,
+			}
+			report := models.IncidentReport{
+				ID:              uuid.New(),
+				CreatedAt:       occurredAt.Unix(),
+				UserFullname:    user.Fullname,
+				UserUsername:    user.Username,
+				DateOfIncidence: occurredAt.Format("2006-01-02"),
+				TimeofIncidence: occurredAt,
+				Description:     fmt.Sprintf("%s in %s. %s", subType, lga.Name, seedDescriptions[rng.Intn(len(seedDescriptions))]),
+				StateName:       lgaStates[lga.Name],
+				LGAName:         lga.Name,
+				Latitude:        lga.Lat + (rng.Float64()-0.5)*0.1,
+				Longitude:       lga.Lng + (rng.Float64()-0.5)*0.1,
+				UserIsAnonymous: rng.Intn(10) == 0,
+				UserID:          user.ID,
+				Category:        category,
+				SubReportType:   subType,
+				Rating:          rating,
+				ReportStatus:    status,
+				RewardPoint:     10,
+				IsVerified:      status == "approved",
+				UpvoteCount:     rng.Intn(40),
+				DownvoteCount:   rng.Intn(5),
+				ReportTypeID:    reportType.ID,
+			}
+			reportType.IncidentReportID = report.ID
+
+			if err := tx.Create(&reportType).Error; err != nil {
+				return fmt.Errorf("seeding report type: %w", err)
+			}
+			if err := tx.Create(&report).Error; err != nil {
+				return fmt.Errorf("seeding incident report: %w", err)
+			}
+			subReport := models.SubReport{
+				ID:               uuid.New(),
+				ReportTypeID:     reportType.ID,
+				SubReportType:    subType,
+				Description:      report.Description,
+				IncidentReportID: report.ID,
+			}
+			if err := tx.Create(&subReport).Error; err != nil {
+				return fmt.Errorf("seeding sub report: %w", err)
+			}
+
+			// Approved reports earn their reporter points
+			if status == "approved" {
+				reward := models.Reward{
+					IncidentReportID: report.ID.String(),
+					UserID:           user.ID,
+					RewardType:       "incident_report",
+					Point:            report.RewardPoint,
+					Balance:          report.RewardPoint,
+				}
+				if err := tx.Create(&reward).Error; err != nil {
+					return fmt.Errorf("seeding reward: %w", err)
+				}
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,62 @@
+package db
+
+import (
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+//go:generate mockgen -destination=../mocks/session_repository_mock.go -package=mocks github.com/techagentng/citizenx/db SessionRepository
+
+// SessionRepository persists sign-in sessions
+type SessionRepository interface {
+	CreateSession(session *models.Session) error
+	FindSession(id string) (*models.Session, error)
+	TouchSession(id string, at int64) error
+	RotateRefresh(id, oldRefreshID, newRefreshID string, at int64) (bool, error)
+	RevokeSession(id string, at int64) error
+	RevokeUserSessions(userID uint, exceptID string, at int64) error
+}
+
+type sessionRepo struct {
+	DB *gorm.DB
+}
+
+func NewSessionRepo(db *GormDB) SessionRepository {
+	return &sessionRepo{db.DB}
+}
+
+func (r *sessionRepo) CreateSession(session *models.Session) error {
+	return r.DB.Create(session).Error
+}
+
+func (r *sessionRepo) FindSession(id string) (*models.Session, error) {
+	var session models.Session
+	if err := r.DB.Where("id = ?", id).First(&session).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *sessionRepo) TouchSession(id string, at int64) error {
+	return r.DB.Model(&models.Session{}).Where("id = ?", id).Update("last_seen_at", at).Error
+}
+
+// RotateRefresh swaps the session's refresh token, reporting false when
+// oldRefreshID is no longer current
+func (r *sessionRepo) RotateRefresh(id, oldRefreshID, newRefreshID string, at int64) (bool, error) {
+	result := r.DB.Model(&models.Session{}).
+		Where("id = ? AND refresh_id = ? AND revoked_at = 0", id, oldRefreshID).
+		Updates(map[string]interface{}{"refresh_id": newRefreshID, "last_seen_at": at})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *sessionRepo) RevokeSession(id string, at int64) error {
+	return r.DB.Model(&models.Session{}).Where("id = ? AND revoked_at = 0", id).Update("revoked_at", at).Error
+}
+
+// RevokeUserSessions ends every live session of the user but exceptID
+func (r *sessionRepo) RevokeUserSessions(userID uint, exceptID string, at int64) error {
+	return r.DB.Model(&models.Session{}).
+		Where("user_id = ? AND id <> ? AND revoked_at = 0", userID, exceptID).
+		Update("revoked_at", at).Error
+}
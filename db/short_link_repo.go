@@ -0,0 +1,76 @@
+package db
+
+import (
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ShortLinkRepository stores short links and their clicks
+type ShortLinkRepository interface {
+	CreateLink(link *models.ShortLink) (bool, error)
+	FindLinkByCode(code string) (*models.ShortLink, error)
+	FindLiveLink(targetURL, purpose string, now int64) (*models.ShortLink, error)
+	RecordClick(click *models.ShortLinkClick) error
+	DailyClicks(linkID uint, days int) ([]models.ShortLinkDayRow, error)
+}
+
+type shortLinkRepo struct {
+	DB *gorm.DB
+}
+
+func NewShortLinkRepo(db *GormDB) ShortLinkRepository {
+	return &shortLinkRepo{db.DB}
+}
+
+// CreateLink stores a link, reporting false when its code is taken
+func (s *shortLinkRepo) CreateLink(link *models.ShortLink) (bool, error) {
+	result := s.DB.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "code"}}, DoNothing: true}).Create(link)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (s *shortLinkRepo) FindLinkByCode(code string) (*models.ShortLink, error) {
+	var link models.ShortLink
+	if err := s.DB.Where("code = ?", code).First(&link).Error; err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// FindLiveLink returns a link for the same target and purpose that has not
+// expired, so sharing a report twice gives the same link
+func (s *shortLinkRepo) FindLiveLink(targetURL, purpose string, now int64) (*models.ShortLink, error) {
+	var link models.ShortLink
+	err := s.DB.Where("target_url = ? AND purpose = ? AND (expires_at = 0 OR expires_at > ?)", targetURL, purpose, now).
+		Order("expires_at = 0 DESC, expires_at DESC").
+		First(&link).Error
+	if err != nil {
+		return nil, err
+	}
+	return &link, nil
+}
+
+// RecordClick stores a click and counts it on the link
+func (s *shortLinkRepo) RecordClick(click *models.ShortLinkClick) error {
+	return s.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(click).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.ShortLink{}).Where("id = ?", click.LinkID).Updates(map[string]interface{}{
+			"clicks":          gorm.Expr("clicks + 1"),
+			"last_clicked_at": click.ClickedAt,
+		}).Error
+	})
+}
+
+// DailyClicks counts a link's clicks per UTC day over the last days days
+func (s *shortLinkRepo) DailyClicks(linkID uint, days int) ([]models.ShortLinkDayRow, error) {
+	rows := []models.ShortLinkDayRow{}
+	err := s.DB.Model(&models.ShortLinkClick{}).
+		Select("TO_CHAR(TO_TIMESTAMP(clicked_at) AT TIME ZONE 'UTC', 'YYYY-MM-DD') AS day, COUNT(*) AS clicks").
+		Where("link_id = ? AND clicked_at >= EXTRACT(EPOCH FROM NOW()) - ? * 86400", linkID, days).
+		Group("day").
+		Order("day DESC").
+		Scan(&rows).Error
+	return rows, err
+}
@@ -0,0 +1,39 @@
+package db
+
+import (
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+// SigningKeyRepository persists the key pairs tokens are signed with
+type SigningKeyRepository interface {
+	CreateKey(key *models.SigningKey) error
+	ListKeys() ([]models.SigningKey, error)
+	DeleteKeys(ids []string) error
+}
+
+type signingKeyRepo struct {
+	DB *gorm.DB
+}
+
+func NewSigningKeyRepo(db *GormDB) SigningKeyRepository {
+	return &signingKeyRepo{db.DB}
+}
+
+func (r *signingKeyRepo) CreateKey(key *models.SigningKey) error {
+	return r.DB.Create(key).Error
+}
+
+// ListKeys returns every key, the latest to become active first
+func (r *signingKeyRepo) ListKeys() ([]models.SigningKey, error) {
+	var keys []models.SigningKey
+	err := r.DB.Order("active_from DESC").Find(&keys).Error
+	return keys, err
+}
+
+func (r *signingKeyRepo) DeleteKeys(ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.DB.Where("id IN ?", ids).Delete(&models.SigningKey{}).Error
+}
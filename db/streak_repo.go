@@ -0,0 +1,145 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/techagentng/citizenx/events"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StreakRepository keeps users' reporting streaks and the report counts
+// they are worked out from
+type StreakRepository interface {
+	GetStreak(userID uint) (*models.ReportingStreak, error)
+	ReportDays(userID uint, since time.Time) ([]models.ReportDay, error)
+	SaveStreak(streak *models.ReportingStreak, bonuses []models.StreakBonus) error
+	SetDailyGoal(userID uint, goal int) error
+	StreaksAtRisk(lastDay, today string, minDays int, afterID uint, limit int) ([]models.ReportingStreak, error)
+	MarkReminded(userID uint, day string) error
+}
+
+type streakRepo struct {
+	DB *gorm.DB
+}
+
+func NewStreakRepo(db *GormDB) StreakRepository {
+	return &streakRepo{db.DB}
+}
+
+// GetStreak returns the user's streak, or an empty one when they have
+// never had one
+func (r *streakRepo) GetStreak(userID uint) (*models.ReportingStreak, error) {
+	streak := models.ReportingStreak{UserID: userID}
+	err := r.DB.Where("user_id = ?", userID).Limit(1).Find(&streak).Error
+	return &streak, err
+}
+
+// ReportDays counts the user's published reports by the day, in Lagos time,
+// they were submitted since the given time, newest day first
+func (r *streakRepo) ReportDays(userID uint, since time.Time) ([]models.ReportDay, error) {
+	var days []models.ReportDay
+	err := r.DB.Model(&models.IncidentReport{}).
+		Select("to_char(to_timestamp(created_at) AT TIME ZONE 'Africa/Lagos', 'YYYY-MM-DD') AS day, COUNT(*) AS reports").
+		Where("user_id = ? AND created_at >= ?", userID, since.Unix()).
+		Where("LOWER(report_status) IN ?", models.PublishedReportStatuses).
+		Group("1").
+		Order("1 DESC").
+		Scan(&days).Error
+	return days, err
+}
+
+// SaveStreak stores the user's current streak, keeping the longest they
+// have had, and pays each bonus whose days have not earned its badge's
+// bonus before. Bonuses are known by IncidentReportIDs of the form
+// streak:<badge>:<first day>:<last day>, so a streak moved by a new daily
+// goal is not rewarded again for the same days.
+func (r *streakRepo) SaveStreak(streak *models.ReportingStreak, bonuses []models.StreakBonus) error {
+	return r.DB.Transaction(func(tx *gorm.DB) error {
+		streak.UpdatedAt = time.Now().Unix()
+		if err := tx.Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.Set{
+				{Column: clause.Column{Name: "current"}, Value: streak.Current},
+				{Column: clause.Column{Name: "longest"}, Value: gorm.Expr("GREATEST(reporting_streaks.longest, ?)", streak.Longest)},
+				{Column: clause.Column{Name: "start_day"}, Value: streak.StartDay},
+				{Column: clause.Column{Name: "last_day"}, Value: streak.LastDay},
+				{Column: clause.Column{Name: "updated_at"}, Value: streak.UpdatedAt},
+			},
+		}).Create(streak).Error; err != nil {
+			return err
+		}
+		if len(bonuses) == 0 {
+			return nil
+		}
+
+		// Saves of the same user's streak take turns, so a bonus is only
+		// paid once
+		var locked models.ReportingStreak
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ?", streak.UserID).
+			First(&locked).Error; err != nil {
+			return err
+		}
+		for i := range bonuses {
+			bonus := &bonuses[i].Reward
+			var paid int64
+			if err := tx.Model(&models.Reward{}).
+				Where("user_id = ? AND incident_report_id LIKE ?", bonus.UserID, "streak:"+bonuses[i].Badge+":%").
+				Where("split_part(incident_report_id, ':', 3) <= ? AND COALESCE(NULLIF(split_part(incident_report_id, ':', 4), ''), split_part(incident_report_id, ':', 3)) >= ?",
+					bonuses[i].LastDay, bonuses[i].FirstDay).
+				Count(&paid).Error; err != nil {
+				return err
+			}
+			if paid > 0 {
+				continue
+			}
+			bonus.IncidentReportID = fmt.Sprintf("streak:%s:%s:%s", bonuses[i].Badge, bonuses[i].FirstDay, bonuses[i].LastDay)
+			if err := tx.Create(bonus).Error; err != nil {
+				return err
+			}
+			if err := writeOutbox(tx, events.RewardEarned{
+				UserID:     bonus.UserID,
+				ReportID:   bonus.IncidentReportID,
+				RewardType: bonus.RewardType,
+				Points:     bonus.Point,
+				OccurredAt: time.Now(),
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// SetDailyGoal sets how many reports a day the user aims for
+func (r *streakRepo) SetDailyGoal(userID uint, goal int) error {
+	return r.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"daily_goal", "updated_at"}),
+	}).Create(&models.ReportingStreak{UserID: userID, DailyGoal: goal, UpdatedAt: time.Now().Unix()}).Error
+}
+
+// StreaksAtRisk returns the next limit streaks after afterID, of active
+// users, that last met their goal on lastDay and run at least minDays,
+// leaving out users already reminded today
+func (r *streakRepo) StreaksAtRisk(lastDay, today string, minDays int, afterID uint, limit int) ([]models.ReportingStreak, error) {
+	var streaks []models.ReportingStreak
+	err := r.DB.Model(&models.ReportingStreak{}).
+		Joins("JOIN users ON users.id = reporting_streaks.user_id").
+		Scopes(activeUsers).
+		Where("reporting_streaks.user_id > ? AND reporting_streaks.last_day = ? AND reporting_streaks.current >= ?", afterID, lastDay, minDays).
+		Where("COALESCE(reporting_streaks.reminded_day, '') <> ?", today).
+		Order("reporting_streaks.user_id ASC").
+		Limit(limit).
+		Find(&streaks).Error
+	return streaks, err
+}
+
+func (r *streakRepo) MarkReminded(userID uint, day string) error {
+	return r.DB.Model(&models.ReportingStreak{}).
+		Where("user_id = ?", userID).
+		Update("reminded_day", day).Error
+}
@@ -0,0 +1,52 @@
+package db
+
+import (
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+// SubmissionWindowRepository stores the windows categories accept reports in
+type SubmissionWindowRepository interface {
+	ListWindows() ([]models.SubmissionWindow, error)
+	CategoryWindows(category string) ([]models.SubmissionWindow, error)
+	CreateWindow(window *models.SubmissionWindow) error
+	DeleteWindow(id uint) error
+}
+
+type submissionWindowRepo struct {
+	DB *gorm.DB
+}
+
+func NewSubmissionWindowRepo(db *GormDB) SubmissionWindowRepository {
+	return &submissionWindowRepo{db.DB}
+}
+
+func (s *submissionWindowRepo) ListWindows() ([]models.SubmissionWindow, error) {
+	var windows []models.SubmissionWindow
+	err := s.DB.Order("category ASC, opens_at ASC").Find(&windows).Error
+	return windows, err
+}
+
+// CategoryWindows returns the windows of one category, earliest first
+func (s *submissionWindowRepo) CategoryWindows(category string) ([]models.SubmissionWindow, error) {
+	var windows []models.SubmissionWindow
+	err := s.DB.Where("category = ?", category).Order("opens_at ASC").Find(&windows).Error
+	return windows, err
+}
+
+func (s *submissionWindowRepo) CreateWindow(window *models.SubmissionWindow) error {
+	return s.DB.Create(window).Error
+}
+
+// DeleteWindow removes a window, returning gorm.ErrRecordNotFound when there
+// is no such window
+func (s *submissionWindowRepo) DeleteWindow(id uint) error {
+	result := s.DB.Delete(&models.SubmissionWindow{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package db
+
+import (
+	"time"
+
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+// UploadRepository tracks resumable uploads and their stored chunks
+type UploadRepository interface {
+	CreateUpload(upload *models.Upload) error
+	GetUpload(uploadID string, userID uint) (*models.Upload, error)
+	AddChunk(upload *models.Upload, chunk *models.UploadChunk) error
+	ListChunks(uploadID string) ([]models.UploadChunk, error)
+	CompleteUpload(uploadID, mediaID string) error
+	DeleteUpload(uploadID string) error
+	StaleUploads(before time.Time) ([]models.Upload, error)
+}
+
+type uploadRepo struct {
+	DB *gorm.DB
+}
+
+func NewUploadRepo(db *GormDB) UploadRepository {
+	return &uploadRepo{db.DB}
+}
+
+func (u *uploadRepo) CreateUpload(upload *models.Upload) error {
+	return u.DB.Create(upload).Error
+}
+
+// GetUpload returns the user's upload, or gorm.ErrRecordNotFound when it does
+// not exist or belongs to someone else
+func (u *uploadRepo) GetUpload(uploadID string, userID uint) (*models.Upload, error) {
+	var upload models.Upload
+	if err := u.DB.Where("id = ? AND user_id = ?", uploadID, userID).First(&upload).Error; err != nil {
+		return nil, err
+	}
+	return &upload, nil
+}
+
+// AddChunk records a stored chunk and advances the upload's offset past it.
+// The offset only moves if it still matches the chunk's start, so two
+// clients racing on the same upload cannot both append at one offset.
+func (u *uploadRepo) AddChunk(upload *models.Upload, chunk *models.UploadChunk) error {
+	return u.DB.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Upload{}).
+			Where("id = ? AND \"offset\" = ?", upload.ID, chunk.Offset).
+			Updates(map[string]interface{}{
+				"offset":     chunk.Offset + chunk.Size,
+				"updated_at": time.Now().Unix(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		if err := tx.Create(chunk).Error; err != nil {
+			return err
+		}
+		upload.Offset = chunk.Offset + chunk.Size
+		return nil
+	})
+}
+
+func (u *uploadRepo) ListChunks(uploadID string) ([]models.UploadChunk, error) {
+	var chunks []models.UploadChunk
+	err := u.DB.Where("upload_id = ?", uploadID).Order("\"offset\" ASC").Find(&chunks).Error
+	return chunks, err
+}
+
+// CompleteUpload marks the upload finished and drops its chunk records
+func (u *uploadRepo) CompleteUpload(uploadID, mediaID string) error {
+	return u.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Upload{}).Where("id = ?", uploadID).Updates(map[string]interface{}{
+			"media_id":     mediaID,
+			"completed_at": time.Now().Unix(),
+		}).Error; err != nil {
+			return err
+		}
+		return tx.Where("upload_id = ?", uploadID).Delete(&models.UploadChunk{}).Error
+	})
+}
+
+func (u *uploadRepo) DeleteUpload(uploadID string) error {
+	return u.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("upload_id = ?", uploadID).Delete(&models.UploadChunk{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", uploadID).Delete(&models.Upload{}).Error
+	})
+}
+
+// StaleUploads returns uploads created before the cutoff that never completed
+func (u *uploadRepo) StaleUploads(before time.Time) ([]models.Upload, error) {
+	var uploads []models.Upload
+	err := u.DB.Where("created_at < ? AND completed_at = 0", before.Unix()).Find(&uploads).Error
+	return uploads, err
+}
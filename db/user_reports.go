@@ -0,0 +1,106 @@
+package db
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techagentng/citizenx/events"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+// UserReportFilter narrows a user's own reports. Empty fields match
+// everything; From and To bound the submission time in Unix seconds.
+type UserReportFilter struct {
+	Status   string
+	Category string
+	From     int64
+	To       int64
+}
+
+// apply narrows query to the user's reports matching the filter. The status
+// is left out when withStatus is false, for counting every status at once.
+func (f UserReportFilter) apply(query *gorm.DB, userID uint, withStatus bool) *gorm.DB {
+	query = query.Where("incident_reports.user_id = ?", userID)
+	if f.Category != "" {
+		query = query.Where("LOWER(incident_reports.category) = LOWER(?)", f.Category)
+	}
+	if f.From > 0 {
+		query = query.Where("incident_reports.created_at >= ?", f.From)
+	}
+	if f.To > 0 {
+		query = query.Where("incident_reports.created_at < ?", f.To)
+	}
+	if withStatus && f.Status != "" {
+		query = query.Where("COALESCE(NULLIF(LOWER(incident_reports.report_status), ''), 'pending') = LOWER(?)", f.Status)
+	}
+	return query
+}
+
+// ListUserReports returns the user's reports matching filter, newest first
+func (repo *incidentReportRepo) ListUserReports(userID uint, filter UserReportFilter, page int, opts ...PreloadOption) ([]models.IncidentReport, error) {
+	return findReports(filter.apply(repo.DB, userID, true).
+		Order("incident_reports.created_at DESC").
+		Offset((page-1)*DefaultPageSize).
+		Limit(DefaultPageSize), opts...)
+}
+
+// CountUserReports counts the user's reports matching filter by status,
+// ignoring the filter's own status, with unmoderated reports counted as
+// pending
+func (repo *incidentReportRepo) CountUserReports(userID uint, filter UserReportFilter) (map[string]int64, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	err := filter.apply(repo.DB.Model(&models.IncidentReport{}), userID, false).
+		Select("COALESCE(NULLIF(LOWER(incident_reports.report_status), ''), 'pending') AS status, COUNT(*) AS count").
+		Group("1").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+// WithdrawReport marks the user's report withdrawn if it is still pending,
+// with an audit entry and a ReportWithdrawn event in the same transaction.
+// It reports whether the report was withdrawn.
+func (repo *incidentReportRepo) WithdrawReport(reportID uuid.UUID, userID uint, reason string) (bool, error) {
+	withdrawn := false
+	err := repo.DB.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&models.IncidentReport{}).
+			Where("id = ? AND user_id = ?", reportID, userID).
+			Where("report_status = 'pending' OR report_status = ''").
+			Updates(map[string]interface{}{
+				"report_status":     models.ReportStatusWithdrawn,
+				"withdrawn_reason":  reason,
+				"status_updated_at": now.Unix(),
+			})
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		withdrawn = true
+
+		if _, err := recordTransition(tx, reportID, models.ReportStatusPending, models.ReportStatusWithdrawn, &userID, reason, now); err != nil {
+			return err
+		}
+		if err := writeAudit(tx, &userID, models.AuditReportWithdrawn, "incident_report", reportID.String(), map[string]interface{}{
+			"reason": reason,
+		}); err != nil {
+			return err
+		}
+		return writeOutbox(tx, events.ReportWithdrawn{
+			ReportID:   reportID,
+			UserID:     userID,
+			Reason:     reason,
+			OccurredAt: now,
+		})
+	})
+	return withdrawn, err
+}
@@ -0,0 +1,104 @@
+package db
+
+import (
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+// ReportTotals sums what a user's reports have drawn
+type ReportTotals struct {
+	Reports      int64
+	Views        int64
+	Endorsements int64
+}
+
+// UserStatsRepository aggregates a user's reports and rewards for their
+// profile
+type UserStatsRepository interface {
+	ReportStatusCounts(userID uint) (map[string]int64, error)
+	ReportTotals(userID uint) (ReportTotals, error)
+	PointsByMonth(userID uint, since int64) ([]models.MonthlyPoints, error)
+	TotalPoints(userID uint) (int64, error)
+	LGARank(lgaName string, points int64) (rank, users int64, err error)
+}
+
+type userStatsRepo struct {
+	DB *gorm.DB
+}
+
+func NewUserStatsRepo(db *GormDB) UserStatsRepository {
+	return &userStatsRepo{db.DB}
+}
+
+// ReportStatusCounts counts the user's reports by lower-cased status, with
+// unmoderated reports counted as pending
+func (r *userStatsRepo) ReportStatusCounts(userID uint) (map[string]int64, error) {
+	var rows []struct {
+		Status string
+		Count  int64
+	}
+	err := r.DB.Model(&models.IncidentReport{}).
+		Select("COALESCE(NULLIF(LOWER(report_status), ''), 'pending') AS status, COUNT(*) AS count").
+		Where("user_id = ?", userID).
+		Group("1").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
+
+func (r *userStatsRepo) ReportTotals(userID uint) (ReportTotals, error) {
+	var totals ReportTotals
+	err := r.DB.Model(&models.IncidentReport{}).
+		Select(`COUNT(*) AS reports, COALESCE(SUM(view), 0) AS views,
+            COALESCE(SUM(upvote_count + like_count), 0) AS endorsements`).
+		Where("user_id = ?", userID).
+		Scan(&totals).Error
+	return totals, err
+}
+
+// PointsByMonth sums the points of the user's rewards by the month, in Lagos
+// time, they were first earned
+func (r *userStatsRepo) PointsByMonth(userID uint, since int64) ([]models.MonthlyPoints, error) {
+	var months []models.MonthlyPoints
+	err := r.DB.Model(&models.Reward{}).
+		Select("to_char(to_timestamp(created_at) AT TIME ZONE 'Africa/Lagos', 'YYYY-MM') AS month, SUM(point) AS points").
+		Where("user_id = ? AND created_at >= ?", userID, since).
+		Group("1").
+		Order("1").
+		Scan(&months).Error
+	return months, err
+}
+
+func (r *userStatsRepo) TotalPoints(userID uint) (int64, error) {
+	var points int64
+	err := r.DB.Model(&models.Reward{}).
+		Select("COALESCE(SUM(point), 0)").
+		Where("user_id = ?", userID).
+		Scan(&points).Error
+	return points, err
+}
+
+// LGARank returns where a user with points places among the active users of
+// an LGA, and how many users there are. Users tied on points share a rank.
+func (r *userStatsRepo) LGARank(lgaName string, points int64) (rank, users int64, err error) {
+	totals := r.DB.Table("users").
+		Select("users.id, COALESCE(SUM(rewards.point), 0) AS points").
+		Joins("LEFT JOIN rewards ON rewards.user_id = users.id").
+		Scopes(activeUsers).
+		Where("LOWER(users.lga_name) = LOWER(?)", lgaName).
+		Group("users.id")
+	var result struct {
+		Ahead int64
+		Users int64
+	}
+	err = r.DB.Table("(?) AS totals", totals).
+		Select("COUNT(*) FILTER (WHERE points > ?) AS ahead, COUNT(*) AS users", points).
+		Scan(&result).Error
+	return result.Ahead + 1, result.Users, err
+}
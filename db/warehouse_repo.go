@@ -0,0 +1,144 @@
+package db
+
+import (
+	"time"
+
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WarehouseRepository reads the data exported to the warehouse and tracks
+// which days have been exported
+type WarehouseRepository interface {
+	LastPartition(dataset string, schemaVersion int) (*time.Time, error)
+	SavePartition(partition *models.WarehousePartition) error
+	ListPartitions(dataset string, schemaVersion int) ([]models.WarehousePartition, error)
+	SupersededPartitions(dataset string, schemaVersion int) ([]models.WarehousePartition, error)
+	DeletePartition(id uint) error
+	FirstReportAt() (*time.Time, error)
+	ReportsUpdatedBetween(start, end time.Time) ([]models.IncidentReport, error)
+	FirstActivityAt() (*time.Time, error)
+	ActivityBetween(start, end time.Time) ([]models.ActivityEvent, error)
+	Pseudonyms(userIDs []uint) (map[uint]string, error)
+}
+
+type warehouseRepo struct {
+	DB *gorm.DB
+}
+
+func NewWarehouseRepo(db *GormDB) WarehouseRepository {
+	return &warehouseRepo{db.DB}
+}
+
+// LastPartition returns the latest day exported for a dataset's schema
+// version, or nil when none has been
+func (w *warehouseRepo) LastPartition(dataset string, schemaVersion int) (*time.Time, error) {
+	var last *time.Time
+	err := w.DB.Model(&models.WarehousePartition{}).
+		Select("MAX(partition_date)").
+		Where("dataset = ? AND schema_version = ?", dataset, schemaVersion).
+		Scan(&last).Error
+	return last, err
+}
+
+// SavePartition records an exported day, replacing any earlier record of it
+func (w *warehouseRepo) SavePartition(partition *models.WarehousePartition) error {
+	return w.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "dataset"}, {Name: "schema_version"}, {Name: "partition_date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"key", "rows", "bytes", "exported_at"}),
+	}).Create(partition).Error
+}
+
+// ListPartitions returns the days of a dataset that have a file, oldest
+// first
+func (w *warehouseRepo) ListPartitions(dataset string, schemaVersion int) ([]models.WarehousePartition, error) {
+	var partitions []models.WarehousePartition
+	err := w.DB.Where("dataset = ? AND schema_version = ? AND rows > 0", dataset, schemaVersion).
+		Order("partition_date ASC").
+		Find(&partitions).Error
+	return partitions, err
+}
+
+// SupersededPartitions returns every partition of a dataset recorded under
+// a schema version older than schemaVersion
+func (w *warehouseRepo) SupersededPartitions(dataset string, schemaVersion int) ([]models.WarehousePartition, error) {
+	var partitions []models.WarehousePartition
+	err := w.DB.Where("dataset = ? AND schema_version < ?", dataset, schemaVersion).
+		Order("schema_version ASC, partition_date ASC").
+		Find(&partitions).Error
+	return partitions, err
+}
+
+func (w *warehouseRepo) DeletePartition(id uint) error {
+	return w.DB.Delete(&models.WarehousePartition{}, id).Error
+}
+
+func (w *warehouseRepo) FirstReportAt() (*time.Time, error) {
+	var first *int64
+	if err := w.DB.Model(&models.IncidentReport{}).Select("MIN(updated_at)").Scan(&first).Error; err != nil || first == nil {
+		return nil, err
+	}
+	t := time.Unix(*first, 0)
+	return &t, nil
+}
+
+// ReportsUpdatedBetween returns the reports last changed in [start, end)
+func (w *warehouseRepo) ReportsUpdatedBetween(start, end time.Time) ([]models.IncidentReport, error) {
+	var reports []models.IncidentReport
+	err := w.DB.Where("updated_at >= ? AND updated_at < ?", start.Unix(), end.Unix()).
+		Order("updated_at ASC, id ASC").
+		Find(&reports).Error
+	return reports, err
+}
+
+func (w *warehouseRepo) FirstActivityAt() (*time.Time, error) {
+	var first *time.Time
+	err := w.DB.Model(&models.ActivityEvent{}).Select("MIN(occurred_at)").Scan(&first).Error
+	return first, err
+}
+
+// ActivityBetween returns the activity events that occurred in [start, end)
+func (w *warehouseRepo) ActivityBetween(start, end time.Time) ([]models.ActivityEvent, error) {
+	var activity []models.ActivityEvent
+	err := w.DB.Where("occurred_at >= ? AND occurred_at < ?", start, end).
+		Order("occurred_at ASC, id ASC").
+		Find(&activity).Error
+	return activity, err
+}
+
+// Pseudonyms returns the warehouse pseudonym of each user, creating those
+// not yet assigned. Deleted users get none, so their later rows are
+// exported without one.
+func (w *warehouseRepo) Pseudonyms(userIDs []uint) (map[uint]string, error) {
+	pseudonyms := make(map[uint]string, len(userIDs))
+	if len(userIDs) == 0 {
+		return pseudonyms, nil
+	}
+	if err := w.DB.Exec(`
+        INSERT INTO warehouse_pseudonyms (user_id)
+        SELECT id FROM users WHERE id IN ? AND deleted_at IS NULL
+        ON CONFLICT (user_id) DO NOTHING
+    `, userIDs).Error; err != nil {
+		return nil, err
+	}
+
+	var rows []models.WarehousePseudonym
+	if err := w.DB.Where("user_id IN ?", userIDs).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		pseudonyms[row.UserID] = row.Pseudonym.String()
+	}
+	return pseudonyms, nil
+}
+
+// backfillReportUpdatedAt dates each existing report's last change from
+// what it recorded, so the warehouse export can resume from updated_at
+func backfillReportUpdatedAt(db *gorm.DB) error {
+	return db.Exec(`
+        UPDATE incident_reports
+        SET updated_at = GREATEST(created_at, status_updated_at, edited_at)
+        WHERE updated_at IS NULL OR updated_at = 0
+    `).Error
+}
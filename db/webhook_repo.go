@@ -0,0 +1,178 @@
+package db
+
+import (
+	"time"
+
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WebhookMaxAttempts is how many times a delivery is tried before it is
+// marked failed
+const WebhookMaxAttempts = 8
+
+// WebhookRepository stores webhook subscriptions and the deliveries made to
+// them
+type WebhookRepository interface {
+	CreateSubscription(subscription *models.WebhookSubscription) error
+	ListSubscriptions() ([]models.WebhookSubscription, error)
+	DeleteSubscription(id uint) error
+	QueueDeliveries(deliveries []models.WebhookDelivery) error
+	DeliverPending(limit int, deliver func(delivery *models.WebhookDelivery, subscription *models.WebhookSubscription) (int, error)) (int, error)
+	ListDeliveries(status string, subscriptionID uint, page, pageSize int) ([]models.WebhookDelivery, error)
+	ReplayDelivery(id uint) error
+}
+
+type webhookRepo struct {
+	DB *gorm.DB
+}
+
+func NewWebhookRepo(db *GormDB) WebhookRepository {
+	return &webhookRepo{db.DB}
+}
+
+func (w *webhookRepo) CreateSubscription(subscription *models.WebhookSubscription) error {
+	return w.DB.Create(subscription).Error
+}
+
+func (w *webhookRepo) ListSubscriptions() ([]models.WebhookSubscription, error) {
+	var subscriptions []models.WebhookSubscription
+	err := w.DB.Order("id").Find(&subscriptions).Error
+	return subscriptions, err
+}
+
+// DeleteSubscription removes a subscription, returning
+// gorm.ErrRecordNotFound when there is none. Its deliveries are kept for the
+// log; those still pending fail when they come due.
+func (w *webhookRepo) DeleteSubscription(id uint) error {
+	result := w.DB.Delete(&models.WebhookSubscription{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// QueueDeliveries saves deliveries to be sent at once. A delivery of an
+// event already queued for the subscriber is skipped.
+func (w *webhookRepo) QueueDeliveries(deliveries []models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return w.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "subscription_id"}, {Name: "dedup_key"}},
+		DoNothing: true,
+	}).Create(&deliveries).Error
+}
+
+// DeliverPending locks up to limit deliveries that are due, passes each with
+// its subscription to deliver and records the response status and outcome.
+// Failed deliveries are retried with exponential backoff until they run out
+// of attempts. Locked rows are skipped, so several dispatchers can run side
+// by side. It returns how many deliveries succeeded.
+func (w *webhookRepo) DeliverPending(limit int, deliver func(delivery *models.WebhookDelivery, subscription *models.WebhookSubscription) (int, error)) (int, error) {
+	delivered := 0
+	err := w.DB.Transaction(func(tx *gorm.DB) error {
+		var pending []models.WebhookDelivery
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.WebhookPending, time.Now()).
+			Order("id").
+			Limit(limit).
+			Find(&pending).Error; err != nil {
+			return err
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, 0, len(pending))
+		for _, delivery := range pending {
+			ids = append(ids, delivery.SubscriptionID)
+		}
+		var subscriptions []models.WebhookSubscription
+		if err := tx.Where("id IN ?", ids).Find(&subscriptions).Error; err != nil {
+			return err
+		}
+		byID := make(map[uint]*models.WebhookSubscription, len(subscriptions))
+		for i := range subscriptions {
+			byID[subscriptions[i].ID] = &subscriptions[i]
+		}
+
+		for i := range pending {
+			delivery := &pending[i]
+			now := time.Now()
+			subscription, ok := byID[delivery.SubscriptionID]
+			if !ok {
+				delivery.Status = models.WebhookFailed
+				delivery.LastError = "the subscription was removed"
+			} else if status, err := deliver(delivery, subscription); err != nil {
+				delivery.Attempts++
+				delivery.ResponseStatus = status
+				delivery.LastError = err.Error()
+				delivery.NextAttemptAt = now.Add(webhookBackoff(delivery.Attempts))
+				if delivery.Attempts >= WebhookMaxAttempts {
+					delivery.Status = models.WebhookFailed
+				}
+			} else {
+				delivery.Attempts++
+				delivery.ResponseStatus = status
+				delivery.LastError = ""
+				delivery.Status = models.WebhookDelivered
+				delivery.DeliveredAt = &now
+				delivered++
+			}
+			if err := tx.Save(delivery).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return delivered, err
+}
+
+// webhookBackoff doubles the wait from 30 seconds with each failed attempt,
+// so the last of them comes about an hour after the first
+func webhookBackoff(attempts int) time.Duration {
+	if attempts > 12 {
+		return 6 * time.Hour
+	}
+	return 30 * time.Second << uint(attempts-1)
+}
+
+// ListDeliveries returns the newest deliveries first, optionally only those
+// with status or made to one subscription
+func (w *webhookRepo) ListDeliveries(status string, subscriptionID uint, page, pageSize int) ([]models.WebhookDelivery, error) {
+	query := w.DB.Model(&models.WebhookDelivery{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if subscriptionID != 0 {
+		query = query.Where("subscription_id = ?", subscriptionID)
+	}
+	var deliveries []models.WebhookDelivery
+	err := query.Order("id DESC").Offset((page - 1) * pageSize).Limit(pageSize).Find(&deliveries).Error
+	return deliveries, err
+}
+
+// ReplayDelivery queues a failed delivery to be sent again with a fresh set
+// of attempts, returning gorm.ErrRecordNotFound when there is no such failed
+// delivery
+func (w *webhookRepo) ReplayDelivery(id uint) error {
+	result := w.DB.Model(&models.WebhookDelivery{}).
+		Where("id = ? AND status = ?", id, models.WebhookFailed).
+		Updates(map[string]interface{}{
+			"status":          models.WebhookPending,
+			"attempts":        0,
+			"next_attempt_at": time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
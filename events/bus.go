@@ -0,0 +1,97 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// AllEvents subscribes a handler to every event published on the bus.
+const AllEvents = "*"
+
+// deliveryTimeout bounds how long a single subscriber may take with an event.
+const deliveryTimeout = 30 * time.Second
+
+// Handler reacts to a published event.
+type Handler func(ctx context.Context, event Event) error
+
+// Bus publishes domain events to the handlers subscribed to them.
+type Bus interface {
+	Publish(event Event)
+	Subscribe(name string, handler Handler)
+}
+
+// InProcessBus delivers events to subscribers running in the same process.
+// Each delivery runs on its own goroutine, so a slow or failing subscriber
+// never holds up the request that published the event.
+type InProcessBus struct {
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+	inflight sync.WaitGroup
+}
+
+// NewBus returns an empty in-process bus.
+func NewBus() *InProcessBus {
+	return &InProcessBus{handlers: map[string][]Handler{}}
+}
+
+// Subscribe registers handler for events with the given name, or for every
+// event when name is AllEvents.
+func (b *InProcessBus) Subscribe(name string, handler Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[name] = append(b.handlers[name], handler)
+}
+
+func (b *InProcessBus) subscribers(event Event) []Handler {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return append(append([]Handler{}, b.handlers[event.EventName()]...), b.handlers[AllEvents]...)
+}
+
+// Publish hands event to its subscribers. Subscriber errors are logged.
+func (b *InProcessBus) Publish(event Event) {
+	for _, handler := range b.subscribers(event) {
+		b.inflight.Add(1)
+		go b.deliver(handler, event)
+	}
+}
+
+func (b *InProcessBus) deliver(handler Handler, event Event) {
+	defer b.inflight.Done()
+
+	ctx, cancel := context.WithTimeout(context.Background(), deliveryTimeout)
+	defer cancel()
+	if err := dispatch(ctx, handler, event); err != nil {
+		log.Printf("event subscriber failed handling %s: %v", event.EventName(), err)
+	}
+}
+
+// Dispatch runs every subscriber of event in turn and reports their
+// failures, for callers that retry undelivered events.
+func (b *InProcessBus) Dispatch(ctx context.Context, event Event) error {
+	var errs []error
+	for _, handler := range b.subscribers(event) {
+		if err := dispatch(ctx, handler, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func dispatch(ctx context.Context, handler Handler, event Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("subscriber panicked: %v", r)
+		}
+	}()
+	return handler(ctx, event)
+}
+
+// Wait blocks until every delivery started so far has finished.
+func (b *InProcessBus) Wait() {
+	b.inflight.Wait()
+}
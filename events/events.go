@@ -0,0 +1,334 @@
+// Package events defines the domain events published by the services and the
+// bus that delivers them to subscribers.
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Event names, also used as the routing key when events leave the process.
+const (
+	ReportCreatedEvent      = "report.created"
+	ReportVerifiedEvent     = "report.verified"
+	CommentAddedEvent       = "comment.added"
+	RewardEarnedEvent       = "reward.earned"
+	ReportVotedEvent        = "report.voted"
+	ReportBookmarkedEvent   = "report.bookmarked"
+	ReportClosedEvent       = "report.closed"
+	ReportResolvedEvent     = "report.resolved"
+	ReportWithdrawnEvent    = "report.withdrawn"
+	InfoRequestedEvent      = "report.information_requested"
+	InfoProvidedEvent       = "report.information_provided"
+	CollaboratorAddedEvent  = "report.collaborator_added"
+	ReportTransferredEvent  = "report.transferred"
+	StatusChangedEvent      = "report.status_changed"
+	ReportDeletedEvent      = "report.deleted"
+	ReportEditedEvent       = "report.edited"
+	ReportAcknowledgedEvent = "report.acknowledged"
+)
+
+// Event is a domain fact published after the change it describes is saved.
+// DedupKey identifies the fact itself, so subscribers handed the same event
+// twice can recognise the repeat.
+type Event interface {
+	EventName() string
+	DedupKey() string
+}
+
+// ReportCreated is published when a citizen submits an incident report.
+type ReportCreated struct {
+	ReportID   uuid.UUID `json:"report_id"`
+	UserID     uint      `json:"user_id"`
+	Category   string    `json:"category"`
+	StateName  string    `json:"state_name"`
+	LGAName    string    `json:"lga_name"`
+	Latitude   float64   `json:"latitude"`
+	Longitude  float64   `json:"longitude"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+func (ReportCreated) EventName() string  { return ReportCreatedEvent }
+func (e ReportCreated) DedupKey() string { return ReportCreatedEvent + ":" + e.ReportID.String() }
+
+// ReportVerified is published when an admin approves a report.
+// TransitionID is the status transition that approved it, so a report
+// approved again after being reopened publishes again.
+type ReportVerified struct {
+	ReportID     uuid.UUID `json:"report_id"`
+	UserID       uint      `json:"user_id"`
+	Status       string    `json:"status"`
+	TransitionID uint      `json:"transition_id"`
+	OccurredAt   time.Time `json:"occurred_at"`
+}
+
+func (ReportVerified) EventName() string { return ReportVerifiedEvent }
+func (e ReportVerified) DedupKey() string {
+	return fmt.Sprintf("%s:%s:%d", ReportVerifiedEvent, e.ReportID, e.TransitionID)
+}
+
+// CommentAdded is published when a user comments on a report.
+type CommentAdded struct {
+	CommentID     uint      `json:"comment_id"`
+	ReportID      uuid.UUID `json:"report_id"`
+	ReportOwnerID uint      `json:"report_owner_id"`
+	UserID        uint      `json:"user_id"`
+	OccurredAt    time.Time `json:"occurred_at"`
+}
+
+func (CommentAdded) EventName() string  { return CommentAddedEvent }
+func (e CommentAdded) DedupKey() string { return fmt.Sprintf("%s:%d", CommentAddedEvent, e.CommentID) }
+
+// RewardEarned is published when points are credited to a user. A report
+// can earn the same reward type again when it is approved again, so the
+// time the points were credited is part of its key.
+type RewardEarned struct {
+	UserID     uint      `json:"user_id"`
+	ReportID   string    `json:"report_id"`
+	RewardType string    `json:"reward_type"`
+	Points     int       `json:"points"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+func (RewardEarned) EventName() string { return RewardEarnedEvent }
+func (e RewardEarned) DedupKey() string {
+	return fmt.Sprintf("%s:%d:%s:%s:%d", RewardEarnedEvent, e.UserID, e.ReportID, e.RewardType, e.OccurredAt.UnixNano())
+}
+
+// ReportVoted is published when a user upvotes, downvotes or retracts their
+// vote on a report. VoteType is "upvote", "downvote" or "retracted".
+type ReportVoted struct {
+	ReportID   string    `json:"report_id"`
+	UserID     uint      `json:"user_id"`
+	VoteType   string    `json:"vote_type"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+func (ReportVoted) EventName() string { return ReportVotedEvent }
+func (e ReportVoted) DedupKey() string {
+	return fmt.Sprintf("%s:%s:%d:%s:%d", ReportVotedEvent, e.ReportID, e.UserID, e.VoteType, e.OccurredAt.UnixNano())
+}
+
+// ReportBookmarked is published when a user bookmarks a report.
+type ReportBookmarked struct {
+	ReportID   uuid.UUID `json:"report_id"`
+	UserID     uint      `json:"user_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+func (ReportBookmarked) EventName() string { return ReportBookmarkedEvent }
+func (e ReportBookmarked) DedupKey() string {
+	return fmt.Sprintf("%s:%s:%d", ReportBookmarkedEvent, e.ReportID, e.UserID)
+}
+
+// ReportClosed is published when a stale report is closed automatically.
+type ReportClosed struct {
+	ReportID       uuid.UUID `json:"report_id"`
+	UserID         uint      `json:"user_id"`
+	PreviousStatus string    `json:"previous_status"`
+	RuleID         uint      `json:"rule_id"`
+	AfterDays      int       `json:"after_days"`
+	OccurredAt     time.Time `json:"occurred_at"`
+}
+
+func (ReportClosed) EventName() string  { return ReportClosedEvent }
+func (e ReportClosed) DedupKey() string { return ReportClosedEvent + ":" + e.ReportID.String() }
+
+// ReportAcknowledged is published when the agency a report was assigned to
+// first says it has seen the report.
+type ReportAcknowledged struct {
+	ReportID   uuid.UUID `json:"report_id"`
+	UserID     uint      `json:"user_id"`
+	AgencyID   uint      `json:"agency_id"`
+	Note       string    `json:"note"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+func (ReportAcknowledged) EventName() string { return ReportAcknowledgedEvent }
+func (e ReportAcknowledged) DedupKey() string {
+	return ReportAcknowledgedEvent + ":" + e.ReportID.String()
+}
+
+// ReportResolved is published when the agency a report was assigned to
+// marks it resolved. The reporter is asked to confirm. Evidence holds the
+// links to photos or documents the agency sent with the resolution.
+type ReportResolved struct {
+	ReportID   uuid.UUID `json:"report_id"`
+	UserID     uint      `json:"user_id"`
+	AgencyID   uint      `json:"agency_id"`
+	Note       string    `json:"note"`
+	Evidence   []string  `json:"evidence,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+func (ReportResolved) EventName() string  { return ReportResolvedEvent }
+func (e ReportResolved) DedupKey() string { return ReportResolvedEvent + ":" + e.ReportID.String() }
+
+// ReportWithdrawn is published when a reporter retracts a report before it
+// was moderated.
+type ReportWithdrawn struct {
+	ReportID   uuid.UUID `json:"report_id"`
+	UserID     uint      `json:"user_id"`
+	Reason     string    `json:"reason"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+func (ReportWithdrawn) EventName() string  { return ReportWithdrawnEvent }
+func (e ReportWithdrawn) DedupKey() string { return ReportWithdrawnEvent + ":" + e.ReportID.String() }
+
+// ReportDeleted is published when a report is removed for good, by its
+// reporter or an admin.
+type ReportDeleted struct {
+	ReportID   uuid.UUID `json:"report_id"`
+	UserID     uint      `json:"user_id"`
+	DeletedBy  uint      `json:"deleted_by"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+func (ReportDeleted) EventName() string  { return ReportDeletedEvent }
+func (e ReportDeleted) DedupKey() string { return ReportDeletedEvent + ":" + e.ReportID.String() }
+
+// InformationRequested is published when a moderator asks a reporter for
+// more on their report.
+type InformationRequested struct {
+	RequestID   uint      `json:"request_id"`
+	ReportID    uuid.UUID `json:"report_id"`
+	UserID      uint      `json:"user_id"`
+	ModeratorID uint      `json:"moderator_id"`
+	Fields      []string  `json:"fields"`
+	OccurredAt  time.Time `json:"occurred_at"`
+}
+
+func (InformationRequested) EventName() string { return InfoRequestedEvent }
+func (e InformationRequested) DedupKey() string {
+	return fmt.Sprintf("%s:%d", InfoRequestedEvent, e.RequestID)
+}
+
+// InformationProvided is published when a reporter answers an information
+// request, changing the fields listed.
+type InformationProvided struct {
+	RequestID  uint      `json:"request_id"`
+	ReportID   uuid.UUID `json:"report_id"`
+	UserID     uint      `json:"user_id"`
+	Fields     []string  `json:"fields"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+func (InformationProvided) EventName() string { return InfoProvidedEvent }
+func (e InformationProvided) DedupKey() string {
+	return fmt.Sprintf("%s:%d", InfoProvidedEvent, e.RequestID)
+}
+
+// ReportEdited is published when a reporter edits their report. Version is
+// the version the edit replaced, kept as a ReportRevision, and Fields are
+// the fields they changed.
+type ReportEdited struct {
+	ReportID   uuid.UUID `json:"report_id"`
+	UserID     uint      `json:"user_id"`
+	Version    int       `json:"version"`
+	Fields     []string  `json:"fields"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+func (ReportEdited) EventName() string { return ReportEditedEvent }
+func (e ReportEdited) DedupKey() string {
+	return fmt.Sprintf("%s:%s:%d", ReportEditedEvent, e.ReportID, e.Version)
+}
+
+// CollaboratorAdded is published when a reporter adds another user as a
+// collaborator on their report.
+type CollaboratorAdded struct {
+	ReportID   uuid.UUID `json:"report_id"`
+	UserID     uint      `json:"user_id"` // the collaborator
+	OwnerID    uint      `json:"owner_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+func (CollaboratorAdded) EventName() string { return CollaboratorAddedEvent }
+func (e CollaboratorAdded) DedupKey() string {
+	return fmt.Sprintf("%s:%s:%d:%d", CollaboratorAddedEvent, e.ReportID, e.UserID, e.OccurredAt.Unix())
+}
+
+// ReportTransferred is published when a report is handed to another
+// account.
+type ReportTransferred struct {
+	TransferID uint      `json:"transfer_id"`
+	ReportID   uuid.UUID `json:"report_id"`
+	FromUserID uint      `json:"from_user_id"`
+	ToUserID   uint      `json:"to_user_id"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+func (ReportTransferred) EventName() string { return ReportTransferredEvent }
+func (e ReportTransferred) DedupKey() string {
+	return fmt.Sprintf("%s:%d", ReportTransferredEvent, e.TransferID)
+}
+
+// ReportStatusChanged is published when a report moves to another status
+// in its lifecycle.
+type ReportStatusChanged struct {
+	TransitionID uint      `json:"transition_id"`
+	ReportID     uuid.UUID `json:"report_id"`
+	UserID       uint      `json:"user_id"`
+	FromStatus   string    `json:"from_status"`
+	ToStatus     string    `json:"to_status"`
+	Reason       string    `json:"reason"`
+	OccurredAt   time.Time `json:"occurred_at"`
+}
+
+func (ReportStatusChanged) EventName() string { return StatusChangedEvent }
+func (e ReportStatusChanged) DedupKey() string {
+	return fmt.Sprintf("%s:%d", StatusChangedEvent, e.TransitionID)
+}
+
+// Decode rebuilds an event from its name and JSON encoding.
+func Decode(name string, payload []byte) (Event, error) {
+	switch name {
+	case ReportCreatedEvent:
+		return decode[ReportCreated](payload)
+	case ReportVerifiedEvent:
+		return decode[ReportVerified](payload)
+	case CommentAddedEvent:
+		return decode[CommentAdded](payload)
+	case RewardEarnedEvent:
+		return decode[RewardEarned](payload)
+	case ReportVotedEvent:
+		return decode[ReportVoted](payload)
+	case ReportBookmarkedEvent:
+		return decode[ReportBookmarked](payload)
+	case ReportClosedEvent:
+		return decode[ReportClosed](payload)
+	case ReportResolvedEvent:
+		return decode[ReportResolved](payload)
+	case ReportWithdrawnEvent:
+		return decode[ReportWithdrawn](payload)
+	case InfoRequestedEvent:
+		return decode[InformationRequested](payload)
+	case InfoProvidedEvent:
+		return decode[InformationProvided](payload)
+	case CollaboratorAddedEvent:
+		return decode[CollaboratorAdded](payload)
+	case ReportTransferredEvent:
+		return decode[ReportTransferred](payload)
+	case StatusChangedEvent:
+		return decode[ReportStatusChanged](payload)
+	case ReportDeletedEvent:
+		return decode[ReportDeleted](payload)
+	case ReportEditedEvent:
+		return decode[ReportEdited](payload)
+	case ReportAcknowledgedEvent:
+		return decode[ReportAcknowledged](payload)
+	}
+	return nil, fmt.Errorf("unknown event %q", name)
+}
+
+func decode[E Event](payload []byte) (Event, error) {
+	var event E
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
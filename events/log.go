@@ -0,0 +1,18 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+)
+
+// LogEvents records every event it receives as a JSON line in the
+// application log, where the analytics log pipeline picks it up.
+func LogEvents(ctx context.Context, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	log.Printf("event %s %s", event.EventName(), payload)
+	return nil
+}
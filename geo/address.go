@@ -0,0 +1,108 @@
+package geo
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Address is a textual location broken into its parts. "opposite First
+// Bank, Ojota" has the relation "opposite", the landmark "first bank" and
+// the area "ojota".
+type Address struct {
+	Relation string `json:"relation,omitempty"`
+	Landmark string `json:"landmark"`
+	Area     string `json:"area,omitempty"`
+	// Normalized is the whole text in normalized form
+	Normalized string `json:"normalized"`
+}
+
+// relations are the phrases that place an incident relative to a
+// landmark, longest first so "in front of" wins over "front of"
+var relations = []string{
+	"in front of", "opposite", "beside", "behind", "inside", "across from",
+	"close to", "next to", "after", "before", "along", "under", "near",
+	"front of", "off", "at", "by",
+}
+
+// abbreviations expands the shorthand common in Nigerian addresses
+var abbreviations = map[string]string{
+	"opp":    "opposite",
+	"opps":   "opposite",
+	"st":     "street",
+	"str":    "street",
+	"rd":     "road",
+	"ave":    "avenue",
+	"cres":   "crescent",
+	"cl":     "close",
+	"jn":     "junction",
+	"jnc":    "junction",
+	"jct":    "junction",
+	"junc":   "junction",
+	"bstop":  "bus stop",
+	"b/s":    "bus stop",
+	"b/stop": "bus stop",
+	"expy":   "expressway",
+	"exp":    "expressway",
+	"est":    "estate",
+	"mkt":    "market",
+	"sch":    "school",
+	"hosp":   "hospital",
+	"govt":   "government",
+	"nr":     "near",
+	"beh":    "behind",
+}
+
+var (
+	// separators split an address into its landmark and area parts
+	separators = regexp.MustCompile(`\s*[,;]\s*|\s+-\s+`)
+	spaces     = regexp.MustCompile(`\s+`)
+)
+
+// NormalizeText lowercases text, expands abbreviations and drops
+// punctuation, so that differently written forms of a place compare equal
+func NormalizeText(text string) string {
+	words := strings.Fields(strings.ToLower(text))
+	for i, word := range words {
+		if expanded, ok := abbreviations[strings.TrimRight(word, ".")]; ok {
+			words[i] = expanded
+			continue
+		}
+		words[i] = strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return r
+			}
+			return ' '
+		}, word)
+	}
+	return strings.TrimSpace(spaces.ReplaceAllString(strings.Join(words, " "), " "))
+}
+
+// ParseAddress splits a textual location into its relation, landmark and
+// area. The first part of the text names the landmark and the last part,
+// when there is more than one, the area.
+func ParseAddress(text string) Address {
+	var parts []string
+	for _, part := range separators.Split(strings.TrimSpace(text), -1) {
+		if part = NormalizeText(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	address := Address{Normalized: strings.Join(parts, ", ")}
+	if len(parts) == 0 {
+		return address
+	}
+
+	landmark := parts[0]
+	for _, relation := range relations {
+		if rest, ok := strings.CutPrefix(landmark, relation+" "); ok {
+			address.Relation, landmark = relation, rest
+			break
+		}
+	}
+	address.Landmark = strings.TrimPrefix(landmark, "the ")
+	if len(parts) > 1 {
+		address.Area = parts[len(parts)-1]
+	}
+	return address
+}
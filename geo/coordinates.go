@@ -0,0 +1,42 @@
+package geo
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidCoordinates is returned for coordinates that are not finite
+// decimal degrees on the globe.
+var ErrInvalidCoordinates = errors.New("latitude must be between -90 and 90 and longitude between -180 and 180")
+
+// ParseCoordinates reads a latitude and longitude written in decimal
+// degrees. strconv accepts NaN, infinities and hexadecimal floats, none of
+// which any client means to send, so only plain decimals on the globe are
+// let through.
+func ParseCoordinates(lat, lng string) (Point, error) {
+	latitude, err := parseDegrees(lat, 90)
+	if err != nil {
+		return Point{}, err
+	}
+	longitude, err := parseDegrees(lng, 180)
+	if err != nil {
+		return Point{}, err
+	}
+	return Point{Latitude: latitude, Longitude: longitude}, nil
+}
+
+func parseDegrees(raw string, limit float64) (float64, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || strings.IndexFunc(raw, func(r rune) bool {
+		return !strings.ContainsRune("0123456789+-.eE", r)
+	}) >= 0 {
+		return 0, ErrInvalidCoordinates
+	}
+	degrees, err := strconv.ParseFloat(raw, 64)
+	if err != nil || math.IsNaN(degrees) || math.Abs(degrees) > limit {
+		return 0, ErrInvalidCoordinates
+	}
+	return degrees, nil
+}
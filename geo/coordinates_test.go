@@ -0,0 +1,48 @@
+package geo_test
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/techagentng/citizenx/geo"
+)
+
+func FuzzParseCoordinates(f *testing.F) {
+	for _, seed := range [][2]string{
+		{"6.6018", "3.3515"},
+		{" -90 ", "180"},
+		{"90.0000001", "0"},
+		{"1e2", "0"},
+		{"NaN", "0"},
+		{"0", "-Inf"},
+		{"0x1p-2", "0"},
+		{"", "3.3515"},
+		{"+6.", "-.5"},
+	} {
+		f.Add(seed[0], seed[1])
+	}
+
+	f.Fuzz(func(t *testing.T, lat, lng string) {
+		point, err := geo.ParseCoordinates(lat, lng)
+		if err != nil {
+			if point != (geo.Point{}) {
+				t.Fatalf("ParseCoordinates(%q, %q) returned %v with error %v", lat, lng, point, err)
+			}
+			return
+		}
+		if math.IsNaN(point.Latitude) || math.IsNaN(point.Longitude) ||
+			math.Abs(point.Latitude) > 90 || math.Abs(point.Longitude) > 180 {
+			t.Fatalf("ParseCoordinates(%q, %q) accepted %v, which is off the globe", lat, lng, point)
+		}
+
+		// Coordinates the API writes out must read back the same
+		again, err := geo.ParseCoordinates(
+			strconv.FormatFloat(point.Latitude, 'f', -1, 64),
+			strconv.FormatFloat(point.Longitude, 'f', -1, 64),
+		)
+		if err != nil || again != point {
+			t.Fatalf("%v read back as %v, %v", point, again, err)
+		}
+	})
+}
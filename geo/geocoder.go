@@ -0,0 +1,143 @@
+package geo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/techagentng/citizenx/httpclient"
+)
+
+// Place is the administrative hierarchy a point falls in.
+type Place struct {
+	State string
+	LGA   string
+	Ward  string
+}
+
+// Geocoder resolves coordinates to the areas containing them.
+type Geocoder interface {
+	ReverseGeocode(ctx context.Context, lat, lng float64) (*Place, error)
+}
+
+// Point is a pair of coordinates.
+type Point struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// AddressGeocoder resolves written addresses to coordinates.
+type AddressGeocoder interface {
+	// Geocode returns where address is, or nil when it cannot be found
+	Geocode(ctx context.Context, address string) (*Point, error)
+}
+
+// wardTypes are the Google address component types that carry a ward, most
+// specific first. Coverage varies by state, so several are tried.
+var wardTypes = []string{"administrative_area_level_3", "sublocality_level_1", "sublocality", "neighborhood"}
+
+// GoogleGeocoder reverse geocodes with the Google Maps Geocoding API.
+type GoogleGeocoder struct {
+	apiKey string
+	http   *http.Client
+}
+
+// NewGoogleGeocoder returns a geocoder using apiKey, or nil when no key is
+// configured.
+func NewGoogleGeocoder(apiKey string) *GoogleGeocoder {
+	if apiKey == "" {
+		return nil
+	}
+	return &GoogleGeocoder{apiKey: apiKey, http: httpclient.New("google-geocoding", httpclient.Options{Timeout: 10 * time.Second})}
+}
+
+// geocodeResponse is the part of a Geocoding API response the geocoder
+// reads
+type geocodeResponse struct {
+	Results []struct {
+		AddressComponents []struct {
+			LongName string   `json:"long_name"`
+			Types    []string `json:"types"`
+		} `json:"address_components"`
+		Geometry struct {
+			Location struct {
+				Lat float64 `json:"lat"`
+				Lng float64 `json:"lng"`
+			} `json:"location"`
+		} `json:"geometry"`
+	} `json:"results"`
+	Status string `json:"status"`
+}
+
+func (g *GoogleGeocoder) fetch(ctx context.Context, query url.Values) (*geocodeResponse, error) {
+	query.Set("key", g.apiKey)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://maps.googleapis.com/maps/api/geocode/json?"+query.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := g.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetching geocoding data: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("geocoding: unexpected status %s", resp.Status)
+	}
+
+	var body geocodeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decoding geocoding response: %w", err)
+	}
+	if body.Status != "OK" && body.Status != "ZERO_RESULTS" {
+		return nil, fmt.Errorf("geocoding: %s", body.Status)
+	}
+	return &body, nil
+}
+
+// Geocode looks address up within Nigeria
+func (g *GoogleGeocoder) Geocode(ctx context.Context, address string) (*Point, error) {
+	body, err := g.fetch(ctx, url.Values{
+		"address":    {address},
+		"components": {"country:NG"},
+	})
+	if err != nil || len(body.Results) == 0 {
+		return nil, err
+	}
+	location := body.Results[0].Geometry.Location
+	return &Point{Latitude: location.Lat, Longitude: location.Lng}, nil
+}
+
+func (g *GoogleGeocoder) ReverseGeocode(ctx context.Context, lat, lng float64) (*Place, error) {
+	body, err := g.fetch(ctx, url.Values{"latlng": {fmt.Sprintf("%f,%f", lat, lng)}})
+	if err != nil {
+		return nil, err
+	}
+
+	// Results run from most to least specific; take the first value found
+	// for each level
+	found := map[string]string{}
+	for _, result := range body.Results {
+		for _, component := range result.AddressComponents {
+			for _, t := range component.Types {
+				if _, ok := found[t]; !ok {
+					found[t] = component.LongName
+				}
+			}
+		}
+	}
+
+	place := &Place{
+		State: found["administrative_area_level_1"],
+		LGA:   found["administrative_area_level_2"],
+	}
+	for _, t := range wardTypes {
+		if ward := found[t]; ward != "" {
+			place.Ward = ward
+			break
+		}
+	}
+	return place, nil
+}
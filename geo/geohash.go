@@ -0,0 +1,86 @@
+// Package geo holds coordinate helpers shared by the map and analytics
+// endpoints.
+package geo
+
+const geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
+// MaxGeohashPrecision is the longest geohash the analytics endpoints accept,
+// roughly 38m by 19m per cell.
+const MaxGeohashPrecision = 8
+
+// GeohashGrid describes the grid of geohash cells at one precision. Geohash
+// cells are a regular longitude/latitude grid, so a point's cell can be
+// computed with plain arithmetic and grouped on in SQL.
+type GeohashGrid struct {
+	Precision int
+	LngBits   int
+	LatBits   int
+	// CellWidth and CellHeight are the cell size in degrees
+	CellWidth  float64
+	CellHeight float64
+}
+
+// NewGeohashGrid returns the grid for geohashes of the given length.
+func NewGeohashGrid(precision int) GeohashGrid {
+	bits := 5 * precision
+	lngBits := (bits + 1) / 2
+	latBits := bits / 2
+	return GeohashGrid{
+		Precision:  precision,
+		LngBits:    lngBits,
+		LatBits:    latBits,
+		CellWidth:  360 / float64(uint64(1)<<lngBits),
+		CellHeight: 180 / float64(uint64(1)<<latBits),
+	}
+}
+
+// clamp keeps an index computed from a coordinate on the grid's far edge
+// (longitude 180 or latitude 90) inside the grid.
+func clamp(idx int64, bits int) uint64 {
+	if idx < 0 {
+		return 0
+	}
+	if max := int64(1)<<bits - 1; idx > max {
+		return uint64(max)
+	}
+	return uint64(idx)
+}
+
+// Hash returns the geohash of the cell at column lngIdx and row latIdx,
+// counted from longitude -180 and latitude -90.
+func (g GeohashGrid) Hash(lngIdx, latIdx int64) string {
+	lng := clamp(lngIdx, g.LngBits)
+	lat := clamp(latIdx, g.LatBits)
+
+	hash := make([]byte, g.Precision)
+	lngBit, latBit := g.LngBits, g.LatBits
+	for i := range hash {
+		var c byte
+		for b := 0; b < 5; b++ {
+			// Bits alternate, longitude first, most significant first
+			var bit uint64
+			if (i*5+b)%2 == 0 {
+				lngBit--
+				bit = lng >> lngBit & 1
+			} else {
+				latBit--
+				bit = lat >> latBit & 1
+			}
+			c = c<<1 | byte(bit)
+		}
+		hash[i] = geohashAlphabet[c]
+	}
+	return string(hash)
+}
+
+// Center returns the centre of the cell at column lngIdx and row latIdx.
+func (g GeohashGrid) Center(lngIdx, latIdx int64) (lat, lng float64) {
+	lat = -90 + (float64(clamp(latIdx, g.LatBits))+0.5)*g.CellHeight
+	lng = -180 + (float64(clamp(lngIdx, g.LngBits))+0.5)*g.CellWidth
+	return lat, lng
+}
+
+// Encode returns the geohash of a point.
+func (g GeohashGrid) Encode(lat, lng float64) string {
+	return g.Hash(int64((lng+180)/g.CellWidth), int64((lat+90)/g.CellHeight))
+}
@@ -0,0 +1,126 @@
+package geo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/techagentng/citizenx/httpclient"
+)
+
+// IPLocation is what a client's IP address tells about where a request was
+// made from. IP locations are city level at best, so they only stand in
+// for a GPS fix.
+type IPLocation struct {
+	Latitude  float64
+	Longitude float64
+	// AccuracyRadiusKm is how far from the point the client may be
+	AccuracyRadiusKm int
+	CountryCode      string
+	State            string
+	City             string
+	// Anonymous is set for VPNs, public proxies and Tor exit nodes
+	Anonymous bool
+	// Hosting is set for addresses of datacenters and cloud providers
+	Hosting bool
+}
+
+// HasPoint reports whether the address could be placed
+func (l IPLocation) HasPoint() bool {
+	return l.Latitude != 0 || l.Longitude != 0
+}
+
+// IPLocator looks up client IP addresses.
+type IPLocator interface {
+	// LocateIP returns what is known of ip, or nil for private and unknown
+	// addresses
+	LocateIP(ctx context.Context, ip net.IP) (*IPLocation, error)
+}
+
+// MaxMindLocator looks addresses up with the MaxMind GeoIP2 Insights web
+// service, which also reports anonymizers and hosting providers.
+type MaxMindLocator struct {
+	accountID  string
+	licenseKey string
+	http       *http.Client
+}
+
+// NewMaxMindLocator returns a locator for the MaxMind account, or nil when
+// no account is configured.
+func NewMaxMindLocator(accountID, licenseKey string) *MaxMindLocator {
+	if accountID == "" || licenseKey == "" {
+		return nil
+	}
+	return &MaxMindLocator{accountID: accountID, licenseKey: licenseKey, http: httpclient.New("maxmind", httpclient.Options{Timeout: 5 * time.Second, Retries: -1})}
+}
+
+// insightsResponse is the part of an Insights response the locator reads
+type insightsResponse struct {
+	Country struct {
+		ISOCode string `json:"iso_code"`
+	} `json:"country"`
+	Subdivisions []struct {
+		Names map[string]string `json:"names"`
+	} `json:"subdivisions"`
+	City struct {
+		Names map[string]string `json:"names"`
+	} `json:"city"`
+	Location struct {
+		Latitude       float64 `json:"latitude"`
+		Longitude      float64 `json:"longitude"`
+		AccuracyRadius int     `json:"accuracy_radius"`
+	} `json:"location"`
+	Traits struct {
+		IsAnonymous       bool   `json:"is_anonymous"`
+		IsAnonymousVPN    bool   `json:"is_anonymous_vpn"`
+		IsHostingProvider bool   `json:"is_hosting_provider"`
+		IsPublicProxy     bool   `json:"is_public_proxy"`
+		IsTorExitNode     bool   `json:"is_tor_exit_node"`
+		UserType          string `json:"user_type"`
+	} `json:"traits"`
+}
+
+func (m *MaxMindLocator) LocateIP(ctx context.Context, ip net.IP) (*IPLocation, error) {
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() {
+		return nil, nil
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://geoip.maxmind.com/geoip/v2.1/insights/"+ip.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(m.accountID, m.licenseKey)
+	resp, err := m.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("looking up ip: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		// Addresses MaxMind has no record of
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ip lookup: unexpected status %s", resp.Status)
+	}
+
+	var body insightsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decoding ip lookup: %w", err)
+	}
+	location := &IPLocation{
+		Latitude:         body.Location.Latitude,
+		Longitude:        body.Location.Longitude,
+		AccuracyRadiusKm: body.Location.AccuracyRadius,
+		CountryCode:      body.Country.ISOCode,
+		City:             body.City.Names["en"],
+		Anonymous: body.Traits.IsAnonymous || body.Traits.IsAnonymousVPN ||
+			body.Traits.IsPublicProxy || body.Traits.IsTorExitNode,
+		Hosting: body.Traits.IsHostingProvider || body.Traits.UserType == "hosting",
+	}
+	if len(body.Subdivisions) > 0 {
+		location.State = body.Subdivisions[0].Names["en"]
+	}
+	return location, nil
+}
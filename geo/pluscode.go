@@ -0,0 +1,248 @@
+package geo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Plus codes (Open Location Code) name a small area in a few characters
+// that can be read out over the phone, such as 6FR5J9PW+QX. The first ten
+// digits are five latitude/longitude pairs, each narrowing the area twenty
+// fold; any further digits refine a 5 by 4 grid inside the last pair.
+const (
+	plusCodeAlphabet  = "23456789CFGHJMPQRVWX"
+	plusCodeSeparator = '+'
+	plusCodePadding   = '0'
+	// plusCodeSeparatorAt is the number of digits before the separator of a
+	// full code
+	plusCodeSeparatorAt = 8
+	plusCodePairLength  = 10
+	plusCodeMaxLength   = 15
+	// Units per degree at the finest precision, so encoding and decoding
+	// can be done in integers without rounding drift
+	plusCodeLatUnits = 25000000 // 8000 * 5^5
+	plusCodeLngUnits = 8192000  // 8000 * 4^5
+)
+
+// PlusCodeLength is the length of the codes stored for reports, an area of
+// about 14m by 14m.
+const PlusCodeLength = 10
+
+// ErrInvalidPlusCode is returned for text that is not a plus code
+var ErrInvalidPlusCode = errors.New("invalid plus code")
+
+var errShortPlusCode = fmt.Errorf("%w: short plus codes need a reference location", ErrInvalidPlusCode)
+
+// PlusCodeArea is the area a plus code names
+type PlusCodeArea struct {
+	LatLo, LngLo float64
+	LatHi, LngHi float64
+	Length       int
+}
+
+// Center returns the centre of the area
+func (a PlusCodeArea) Center() (lat, lng float64) {
+	return math.Min((a.LatLo+a.LatHi)/2, 90), (a.LngLo + a.LngHi) / 2
+}
+
+// EncodePlusCode returns the plus code of length digits for a point. Codes
+// shorter than eight digits are padded, and lengths outside 2 to 15 or odd
+// lengths below ten are rounded to the nearest valid length.
+func EncodePlusCode(lat, lng float64, length int) string {
+	switch {
+	case length < 2:
+		length = 2
+	case length > plusCodeMaxLength:
+		length = plusCodeMaxLength
+	case length < plusCodePairLength && length%2 == 1:
+		length++
+	}
+
+	latVal := int64(math.Floor((math.Max(-90, math.Min(90, lat)) + 90) * plusCodeLatUnits))
+	if max := int64(180*plusCodeLatUnits) - 1; latVal > max {
+		latVal = max
+	}
+	lngVal := int64(math.Floor((lng + 180) * plusCodeLngUnits))
+	lngVal %= 360 * plusCodeLngUnits
+	if lngVal < 0 {
+		lngVal += 360 * plusCodeLngUnits
+	}
+
+	// Digits are worked out from the finest upwards
+	digits := make([]byte, plusCodeMaxLength)
+	for i := plusCodeMaxLength - 1; i >= plusCodePairLength; i-- {
+		digits[i] = plusCodeAlphabet[latVal%5*4+lngVal%4]
+		latVal /= 5
+		lngVal /= 4
+	}
+	for i := plusCodePairLength - 2; i >= 0; i -= 2 {
+		digits[i] = plusCodeAlphabet[latVal%20]
+		digits[i+1] = plusCodeAlphabet[lngVal%20]
+		latVal /= 20
+		lngVal /= 20
+	}
+
+	code := string(digits[:length])
+	if length < plusCodeSeparatorAt {
+		code += strings.Repeat(string(plusCodePadding), plusCodeSeparatorAt-length)
+	}
+	return code[:plusCodeSeparatorAt] + string(plusCodeSeparator) + code[plusCodeSeparatorAt:]
+}
+
+// NormalizePlusCode upper-cases code and checks that it is a full or short
+// plus code.
+func NormalizePlusCode(code string) (string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	sep := strings.IndexByte(code, plusCodeSeparator)
+	if sep < 2 || sep != strings.LastIndexByte(code, plusCodeSeparator) || sep > plusCodeSeparatorAt || sep%2 == 1 {
+		return "", ErrInvalidPlusCode
+	}
+	if len(code)-sep == 2 {
+		return "", ErrInvalidPlusCode
+	}
+
+	if pad := strings.IndexByte(code, plusCodePadding); pad >= 0 {
+		// Padding is only allowed in full codes, in whole pairs, directly
+		// before a final separator
+		if sep < plusCodeSeparatorAt || pad == 0 || pad%2 == 1 || sep != len(code)-1 {
+			return "", ErrInvalidPlusCode
+		}
+		if strings.Trim(code[pad:sep], string(plusCodePadding)) != "" {
+			return "", ErrInvalidPlusCode
+		}
+	}
+	for _, r := range strings.TrimRight(strings.ReplaceAll(code, string(plusCodeSeparator), ""), string(plusCodePadding)) {
+		if !strings.ContainsRune(plusCodeAlphabet, r) {
+			return "", ErrInvalidPlusCode
+		}
+	}
+	if len(code)-1 > plusCodeMaxLength {
+		return "", ErrInvalidPlusCode
+	}
+
+	if sep == plusCodeSeparatorAt {
+		// The first pair of a full code is limited to latitudes below 90
+		// and longitudes below 180
+		if strings.IndexByte(plusCodeAlphabet, code[0]) > 8 || strings.IndexByte(plusCodeAlphabet, code[1]) > 17 {
+			return "", ErrInvalidPlusCode
+		}
+	}
+	return code, nil
+}
+
+// IsFullPlusCode reports whether code is a valid plus code that needs no
+// reference location.
+func IsFullPlusCode(code string) bool {
+	code, err := NormalizePlusCode(code)
+	return err == nil && strings.IndexByte(code, plusCodeSeparator) == plusCodeSeparatorAt
+}
+
+// DecodePlusCode returns the area named by a full plus code.
+func DecodePlusCode(code string) (PlusCodeArea, error) {
+	code, err := NormalizePlusCode(code)
+	if err != nil {
+		return PlusCodeArea{}, err
+	}
+	if strings.IndexByte(code, plusCodeSeparator) != plusCodeSeparatorAt {
+		return PlusCodeArea{}, errShortPlusCode
+	}
+	digits := strings.TrimRight(strings.ReplaceAll(code, string(plusCodeSeparator), ""), string(plusCodePadding))
+
+	var lat, lng int64
+	latPlace, lngPlace := int64(20*20*plusCodeLatUnits), int64(20*20*plusCodeLngUnits)
+	for i := 0; i < len(digits) && i < plusCodePairLength; i += 2 {
+		latPlace /= 20
+		lngPlace /= 20
+		lat += int64(strings.IndexByte(plusCodeAlphabet, digits[i])) * latPlace
+		lng += int64(strings.IndexByte(plusCodeAlphabet, digits[i+1])) * lngPlace
+	}
+	for i := plusCodePairLength; i < len(digits); i++ {
+		latPlace /= 5
+		lngPlace /= 4
+		v := int64(strings.IndexByte(plusCodeAlphabet, digits[i]))
+		lat += v / 4 * latPlace
+		lng += v % 4 * lngPlace
+	}
+
+	latLo := float64(lat)/plusCodeLatUnits - 90
+	lngLo := float64(lng)/plusCodeLngUnits - 180
+	return PlusCodeArea{
+		LatLo:  latLo,
+		LngLo:  lngLo,
+		LatHi:  latLo + float64(latPlace)/plusCodeLatUnits,
+		LngHi:  lngLo + float64(lngPlace)/plusCodeLngUnits,
+		Length: len(digits),
+	}, nil
+}
+
+// RecoverPlusCode returns the full code of a short code such as J9PW+QX,
+// taking the missing leading digits from the nearest match to a reference
+// location in the same town or LGA. Full codes are returned as they are.
+func RecoverPlusCode(code string, refLat, refLng float64) (string, error) {
+	code, err := NormalizePlusCode(code)
+	if err != nil {
+		return "", err
+	}
+	sep := strings.IndexByte(code, plusCodeSeparator)
+	if sep == plusCodeSeparatorAt {
+		return code, nil
+	}
+
+	missing := plusCodeSeparatorAt - sep
+	resolution := math.Pow(20, 2-float64(missing)/2)
+	full := EncodePlusCode(refLat, refLng, plusCodePairLength)[:missing] + code
+	area, err := DecodePlusCode(full)
+	if err != nil {
+		return "", err
+	}
+
+	// The reference's own prefix may name the neighbouring cell of the one
+	// meant; move to whichever cell puts the code closest to the reference
+	lat, lng := area.Center()
+	half := resolution / 2
+	switch {
+	case refLat+half < lat && lat-resolution >= -90:
+		lat -= resolution
+	case refLat-half > lat && lat+resolution <= 90:
+		lat += resolution
+	}
+	switch {
+	case refLng+half < lng:
+		lng -= resolution
+	case refLng-half > lng:
+		lng += resolution
+	}
+	return EncodePlusCode(lat, lng, area.Length), nil
+}
+
+// LocalPlusCode drops the first four digits of a full code, leaving a short
+// code to be read out with a town or LGA name that is enough to recover it
+// anywhere within about 50km.
+func LocalPlusCode(code string) string {
+	if len(code) < plusCodeSeparatorAt+3 || strings.IndexByte(code, plusCodePadding) >= 0 {
+		return code
+	}
+	return code[4:]
+}
+
+// PlusCodePrefix returns the part of a full code shared by every longer code
+// inside its area, for prefix searches. Codes longer than maxLength are cut
+// to it.
+func PlusCodePrefix(code string, maxLength int) (string, error) {
+	code, err := NormalizePlusCode(code)
+	if err != nil {
+		return "", err
+	}
+	if strings.IndexByte(code, plusCodeSeparator) != plusCodeSeparatorAt {
+		return "", errShortPlusCode
+	}
+	if pad := strings.IndexByte(code, plusCodePadding); pad >= 0 {
+		return code[:pad], nil
+	}
+	if maxLength >= plusCodeSeparatorAt && len(code) > maxLength+1 {
+		code = code[:maxLength+1]
+	}
+	return strings.TrimSuffix(code, string(plusCodeSeparator)), nil
+}
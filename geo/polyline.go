@@ -0,0 +1,161 @@
+package geo
+
+import (
+	"errors"
+	"math"
+	"strings"
+)
+
+// earthRadius is the mean radius of the earth in metres
+const earthRadius = 6371000
+
+// ErrInvalidPolyline is returned for text that is not an encoded polyline
+var ErrInvalidPolyline = errors.New("invalid encoded polyline")
+
+// DecodePolyline reads a line in Google's encoded polyline format, the form
+// the map SDKs hand routes over in, at five decimal places.
+func DecodePolyline(encoded string) ([]Point, error) {
+	var points []Point
+	var lat, lng int64
+	for i := 0; i < len(encoded); {
+		var deltas [2]int64
+		for d := range deltas {
+			var result int64
+			shift := uint(0)
+			for {
+				if i >= len(encoded) || shift > 30 {
+					return nil, ErrInvalidPolyline
+				}
+				b := int64(encoded[i]) - 63
+				i++
+				if b < 0 || b > 63 {
+					return nil, ErrInvalidPolyline
+				}
+				result |= (b & 0x1f) << shift
+				shift += 5
+				if b < 0x20 {
+					break
+				}
+			}
+			if result&1 != 0 {
+				deltas[d] = ^(result >> 1)
+			} else {
+				deltas[d] = result >> 1
+			}
+		}
+		lat += deltas[0]
+		lng += deltas[1]
+		point := Point{Latitude: float64(lat) / 1e5, Longitude: float64(lng) / 1e5}
+		if math.Abs(point.Latitude) > 90 || math.Abs(point.Longitude) > 180 {
+			return nil, ErrInvalidPolyline
+		}
+		points = append(points, point)
+	}
+	return points, nil
+}
+
+// EncodePolyline writes points in Google's encoded polyline format.
+func EncodePolyline(points []Point) string {
+	var b strings.Builder
+	var prevLat, prevLng int64
+	for _, p := range points {
+		lat := int64(math.Round(p.Latitude * 1e5))
+		lng := int64(math.Round(p.Longitude * 1e5))
+		for _, delta := range []int64{lat - prevLat, lng - prevLng} {
+			v := delta << 1
+			if delta < 0 {
+				v = ^v
+			}
+			for v >= 0x20 {
+				b.WriteByte(byte(0x20|v&0x1f) + 63)
+				v >>= 5
+			}
+			b.WriteByte(byte(v) + 63)
+		}
+		prevLat, prevLng = lat, lng
+	}
+	return b.String()
+}
+
+// Distance returns the great-circle distance between two points in metres.
+func Distance(a, b Point) float64 {
+	lat1, lat2 := a.Latitude*math.Pi/180, b.Latitude*math.Pi/180
+	dLat := lat2 - lat1
+	dLng := (b.Longitude - a.Longitude) * math.Pi / 180
+	h := math.Sin(dLat/2)*math.Sin(dLat/2) + math.Cos(lat1)*math.Cos(lat2)*math.Sin(dLng/2)*math.Sin(dLng/2)
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+// PolylineLength returns the length of a line in metres.
+func PolylineLength(line []Point) float64 {
+	var length float64
+	for i := 1; i < len(line); i++ {
+		length += Distance(line[i-1], line[i])
+	}
+	return length
+}
+
+// DistanceToPolyline returns how far p is from the nearest point of line in
+// metres. Each segment is measured on a flat projection around p, which is
+// accurate over the few kilometres a road segment spans.
+func DistanceToPolyline(p Point, line []Point) float64 {
+	if len(line) == 0 {
+		return math.Inf(1)
+	}
+	if len(line) == 1 {
+		return Distance(p, line[0])
+	}
+
+	metresPerLat := earthRadius * math.Pi / 180
+	metresPerLng := metresPerLat * math.Cos(p.Latitude*math.Pi/180)
+	project := func(q Point) (float64, float64) {
+		return (q.Longitude - p.Longitude) * metresPerLng, (q.Latitude - p.Latitude) * metresPerLat
+	}
+
+	nearest := math.Inf(1)
+	for i := 1; i < len(line); i++ {
+		ax, ay := project(line[i-1])
+		bx, by := project(line[i])
+		dx, dy := bx-ax, by-ay
+		// The closest point of the segment to p, which sits at the origin
+		t := 0.0
+		if lengthSq := dx*dx + dy*dy; lengthSq > 0 {
+			t = math.Max(0, math.Min(1, -(ax*dx+ay*dy)/lengthSq))
+		}
+		nearest = math.Min(nearest, math.Hypot(ax+t*dx, ay+t*dy))
+	}
+	return nearest
+}
+
+// SamplePolyline returns points along line no more than every metres apart,
+// including every vertex.
+func SamplePolyline(line []Point, every float64) []Point {
+	if len(line) < 2 || every <= 0 {
+		return line
+	}
+	samples := []Point{line[0]}
+	for i := 1; i < len(line); i++ {
+		a, b := line[i-1], line[i]
+		steps := int(math.Ceil(Distance(a, b) / every))
+		for s := 1; s <= steps; s++ {
+			t := float64(s) / float64(steps)
+			samples = append(samples, Point{
+				Latitude:  a.Latitude + t*(b.Latitude-a.Latitude),
+				Longitude: a.Longitude + t*(b.Longitude-a.Longitude),
+			})
+		}
+	}
+	return samples
+}
+
+// Bounds returns the bounding box of points as south, west, north, east.
+func Bounds(points []Point) (south, west, north, east float64) {
+	south, west, north, east = 90, 180, -90, -180
+	for _, p := range points {
+		south = math.Min(south, p.Latitude)
+		north = math.Max(north, p.Latitude)
+		west = math.Min(west, p.Longitude)
+		east = math.Max(east, p.Longitude)
+	}
+	return south, west, north, east
+}
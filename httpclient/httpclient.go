@@ -0,0 +1,211 @@
+// Package httpclient builds the clients used to call other services. Every
+// attempt has a timeout, requests that are safe to repeat are retried with
+// jittered backoff when the other side fails or asks us to slow down, and
+// each client's calls are counted under "http_clients" in expvar.
+package httpclient
+
+import (
+	"context"
+	"expvar"
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultTimeout   = 10 * time.Second
+	defaultRetries   = 2
+	defaultBaseDelay = 200 * time.Millisecond
+	defaultMaxDelay  = 2 * time.Second
+)
+
+// Options tune a client. Zero values take the defaults; use a negative
+// Retries to turn retries off.
+type Options struct {
+	// Timeout bounds each attempt, reading the response body included
+	Timeout time.Duration
+	// Retries is how many times a failed request is tried again
+	Retries int
+	// BaseDelay is the wait before the first retry, doubling for each one
+	// after up to MaxDelay
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+var (
+	stats   = expvar.NewMap("http_clients")
+	statsMu sync.Mutex
+)
+
+// New returns a client whose calls are counted under name, such as
+// "paystack"
+func New(name string, opts Options) *http.Client {
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultTimeout
+	}
+	if opts.Retries == 0 {
+		opts.Retries = defaultRetries
+	}
+	if opts.Retries < 0 {
+		opts.Retries = 0
+	}
+	if opts.BaseDelay <= 0 {
+		opts.BaseDelay = defaultBaseDelay
+	}
+	if opts.MaxDelay <= 0 {
+		opts.MaxDelay = defaultMaxDelay
+	}
+	return &http.Client{
+		Transport: &transport{
+			base:    http.DefaultTransport,
+			opts:    opts,
+			metrics: metricsFor(name),
+		},
+	}
+}
+
+// metricsFor returns the counters of the clients called name, shared by
+// every client built with it
+func metricsFor(name string) *expvar.Map {
+	statsMu.Lock()
+	defer statsMu.Unlock()
+	if m, ok := stats.Get(name).(*expvar.Map); ok {
+		return m
+	}
+	m := new(expvar.Map).Init()
+	stats.Set(name, m)
+	return m
+}
+
+// transport retries failed attempts of repeatable requests
+type transport struct {
+	base    http.RoundTripper
+	opts    Options
+	metrics *expvar.Map
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithTimeout(req.Context(), t.opts.Timeout)
+		try := req.Clone(ctx)
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			try.Body = body
+		}
+
+		start := time.Now()
+		resp, err := t.base.RoundTrip(try)
+		t.observe(resp, err, time.Since(start))
+
+		if attempt >= t.opts.Retries || !retryable(req, resp, err) {
+			if err != nil {
+				cancel()
+				return nil, err
+			}
+			// The attempt's timeout runs until the caller has read the body
+			resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+			return resp, nil
+		}
+
+		delay := t.backoff(attempt)
+		if resp != nil {
+			if after := retryAfter(resp); after > 0 && after <= t.opts.MaxDelay {
+				delay = after
+			}
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+			resp.Body.Close()
+		}
+		cancel()
+		t.metrics.Add("retries", 1)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (t *transport) observe(resp *http.Response, err error, took time.Duration) {
+	t.metrics.Add("requests", 1)
+	t.metrics.Add("latency_ms_total", took.Milliseconds())
+	switch {
+	case err != nil:
+		t.metrics.Add("errors", 1)
+	case resp.StatusCode >= 500:
+		t.metrics.Add("responses_5xx", 1)
+	case resp.StatusCode >= 400:
+		t.metrics.Add("responses_4xx", 1)
+	}
+}
+
+// backoff is the full-jitter wait before retry attempt+1
+func (t *transport) backoff(attempt int) time.Duration {
+	ceiling := t.opts.BaseDelay << attempt
+	if ceiling <= 0 || ceiling > t.opts.MaxDelay {
+		ceiling = t.opts.MaxDelay
+	}
+	return time.Duration(rand.Int63n(int64(ceiling)) + 1)
+}
+
+// retryable reports whether another attempt at req could succeed where this
+// one did not. Only requests that are safe to repeat, and whose body can be
+// sent again, are retried: POSTs are retried only with an Idempotency-Key.
+func retryable(req *http.Request, resp *http.Response, err error) bool {
+	if req.Context().Err() != nil {
+		return false
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+	default:
+		if req.Header.Get("Idempotency-Key") == "" {
+			return false
+		}
+	}
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter reads a Retry-After header given in seconds
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	// Longer than a Duration holds, and so longer than any client waits
+	if int64(seconds) > int64(math.MaxInt64/time.Second) {
+		return math.MaxInt64
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// cancelOnClose ends an attempt's context once its body is closed
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,92 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// FuzzRetryable checks the rules that keep retries from repeating a
+// request with side effects: unsafe methods are only retried with an
+// Idempotency-Key, and bodies only when they can be sent again.
+func FuzzRetryable(f *testing.F) {
+	f.Add(http.MethodGet, "", 503, false, false, false, false)
+	f.Add(http.MethodPost, "", 503, true, true, false, false)
+	f.Add(http.MethodPost, "payout-42", 502, true, true, false, false)
+	f.Add(http.MethodPost, "payout-42", 0, true, false, true, false)
+	f.Add(http.MethodPatch, " ", 429, false, false, false, false)
+	f.Add(http.MethodPut, "", 504, true, false, false, true)
+	f.Add("post", "", 503, false, false, false, false)
+	f.Add(http.MethodDelete, "", 500, false, false, false, false)
+
+	f.Fuzz(func(t *testing.T, method, key string, status int, hasBody, replayable, failed, cancelled bool) {
+		req := &http.Request{Method: method, Header: http.Header{}, Body: http.NoBody}
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		if hasBody {
+			req.Body = io.NopCloser(strings.NewReader("{}"))
+			if replayable {
+				req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(strings.NewReader("{}")), nil }
+			}
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		if cancelled {
+			cancel()
+		}
+		req = req.WithContext(ctx)
+
+		var resp *http.Response
+		var err error
+		if failed {
+			err = errors.New("connection reset")
+		} else {
+			resp = &http.Response{StatusCode: status, Header: http.Header{}}
+		}
+
+		if !retryable(req, resp, err) {
+			return
+		}
+		switch {
+		case cancelled:
+			t.Fatal("retried a cancelled request")
+		case hasBody && !replayable:
+			t.Fatal("retried a request whose body cannot be sent again")
+		case req.Header.Get("Idempotency-Key") == "" && !safeToRepeat(method):
+			t.Fatalf("retried a %q request without an Idempotency-Key", method)
+		case !failed && status != http.StatusTooManyRequests && status < 500:
+			t.Fatalf("retried a request answered %d", status)
+		}
+	})
+}
+
+func safeToRepeat(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func FuzzRetryAfter(f *testing.F) {
+	for _, seed := range []string{"", "0", "120", "-1", "1e3", "99999999999999999999", " 5"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, header string) {
+		resp := &http.Response{Header: http.Header{"Retry-After": []string{header}}}
+		wait := retryAfter(resp)
+		if wait < 0 {
+			t.Fatalf("Retry-After %q gave a negative wait %s", header, wait)
+		}
+		// A long wait must not wrap around to a short one
+		if seconds, err := strconv.Atoi(header); err == nil && seconds > 0 && wait < time.Second {
+			t.Fatalf("Retry-After %q gave a wait of %s", header, wait)
+		}
+	})
+}
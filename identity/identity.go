@@ -0,0 +1,139 @@
+// Package identity checks national identity numbers with a verification
+// vendor: the NIN against the NIMC register and the BVN against the banks'.
+package identity
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/techagentng/citizenx/httpclient"
+)
+
+// Kinds of identity number
+const (
+	NIN = "nin"
+	BVN = "bvn"
+)
+
+// ErrNotFound is returned for numbers the register has no record of.
+var ErrNotFound = errors.New("identity: no record of this number")
+
+// Record is what the register holds about the person a number belongs to
+type Record struct {
+	FirstName   string
+	MiddleName  string
+	LastName    string
+	DateOfBirth string // YYYY-MM-DD, empty when the register does not say
+}
+
+// Verifier looks identity numbers up.
+type Verifier interface {
+	// Lookup returns the record of number, a NIN or BVN
+	Lookup(ctx context.Context, kind, number string) (*Record, error)
+	// Name identifies the vendor in stored verifications
+	Name() string
+}
+
+// Dojah looks numbers up with the Dojah KYC API, which queries NIMC for
+// NINs and NIBSS for BVNs.
+type Dojah struct {
+	appID     string
+	secretKey string
+	http      *http.Client
+}
+
+// NewDojah returns a verifier for the Dojah app, or nil when no app is
+// configured.
+func NewDojah(appID, secretKey string) *Dojah {
+	if appID == "" || secretKey == "" {
+		return nil
+	}
+	return &Dojah{appID: appID, secretKey: secretKey, http: httpclient.New("dojah", httpclient.Options{Timeout: 15 * time.Second})}
+}
+
+func (d *Dojah) Name() string { return "dojah" }
+
+// dojahResponse is the part of a lookup response the verifier reads
+type dojahResponse struct {
+	Entity struct {
+		FirstName   string `json:"first_name"`
+		MiddleName  string `json:"middle_name"`
+		LastName    string `json:"last_name"`
+		DateOfBirth string `json:"date_of_birth"`
+	} `json:"entity"`
+}
+
+func (d *Dojah) Lookup(ctx context.Context, kind, number string) (*Record, error) {
+	var endpoint string
+	switch kind {
+	case NIN:
+		endpoint = "https://api.dojah.io/api/v1/kyc/nin?" + url.Values{"nin": {number}}.Encode()
+	case BVN:
+		endpoint = "https://api.dojah.io/api/v1/kyc/bvn/full?" + url.Values{"bvn": {number}}.Encode()
+	default:
+		return nil, fmt.Errorf("identity: unsupported kind %q", kind)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("AppId", d.appID)
+	req.Header.Set("Authorization", d.secretKey)
+	resp, err := d.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("looking up %s: %w", kind, err)
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusNotFound, resp.StatusCode == http.StatusBadRequest:
+		return nil, ErrNotFound
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("%s lookup: unexpected status %s", kind, resp.Status)
+	}
+
+	var body dojahResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("decoding %s lookup: %w", kind, err)
+	}
+	if body.Entity.FirstName == "" && body.Entity.LastName == "" {
+		return nil, ErrNotFound
+	}
+	return &Record{
+		FirstName:   body.Entity.FirstName,
+		MiddleName:  body.Entity.MiddleName,
+		LastName:    body.Entity.LastName,
+		DateOfBirth: normalizeDate(body.Entity.DateOfBirth),
+	}, nil
+}
+
+// normalizeDate rewrites the date layouts registers use as YYYY-MM-DD
+func normalizeDate(date string) string {
+	date = strings.TrimSpace(date)
+	for _, layout := range []string{"2006-01-02", "02-01-2006", "02-Jan-2006", "02/01/2006"} {
+		if t, err := time.Parse(layout, date); err == nil {
+			return t.Format("2006-01-02")
+		}
+	}
+	return ""
+}
+
+// Matches reports whether the record is of the person with fullname, and,
+// when both are known, born on dateOfBirth. Names match when the record's
+// first and last names both appear in fullname, in any order and case.
+func (r *Record) Matches(fullname, dateOfBirth string) bool {
+	names := map[string]bool{}
+	for _, name := range strings.Fields(strings.ToLower(fullname)) {
+		names[name] = true
+	}
+	first, last := strings.ToLower(strings.TrimSpace(r.FirstName)), strings.ToLower(strings.TrimSpace(r.LastName))
+	if first == "" || last == "" || !names[first] || !names[last] {
+		return false
+	}
+	return r.DateOfBirth == "" || dateOfBirth == "" || r.DateOfBirth == dateOfBirth
+}
@@ -0,0 +1,94 @@
+// Package imagehash computes perceptual hashes used to spot the same picture
+// being uploaded again after resizing or recompression.
+package imagehash
+
+import (
+	"image"
+	"math"
+	"math/bits"
+	"sort"
+
+	"github.com/disintegration/imaging"
+)
+
+const (
+	// sampleSize is the side of the grayscale thumbnail the DCT runs over
+	sampleSize = 32
+	// hashSize is the side of the low frequency block kept from the DCT,
+	// giving a 64 bit hash
+	hashSize = 8
+)
+
+// PHash returns the DCT perceptual hash of img. Each bit records whether one
+// of the 64 lowest frequency coefficients is above their median, so scaling,
+// recompression and small colour changes leave most bits untouched.
+func PHash(img image.Image) uint64 {
+	gray := imaging.Grayscale(imaging.Resize(img, sampleSize, sampleSize, imaging.Lanczos))
+
+	var pixels [sampleSize][sampleSize]float64
+	for y := 0; y < sampleSize; y++ {
+		for x := 0; x < sampleSize; x++ {
+			pixels[y][x] = float64(gray.Pix[y*gray.Stride+x*4])
+		}
+	}
+
+	coeffs := dct2D(pixels)
+
+	var block [hashSize * hashSize]float64
+	for y := 0; y < hashSize; y++ {
+		for x := 0; x < hashSize; x++ {
+			block[y*hashSize+x] = coeffs[y][x]
+		}
+	}
+
+	// The DC term is the image's mean brightness, which says nothing about
+	// its structure, so it is left out of the median
+	sorted := append([]float64(nil), block[1:]...)
+	sort.Float64s(sorted)
+	median := (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+
+	var hash uint64
+	for i, c := range block {
+		if c > median {
+			hash |= 1 << uint(len(block)-1-i)
+		}
+	}
+	return hash
+}
+
+// Distance returns the number of bits that differ between two hashes. Copies
+// of one picture are usually within a few bits of each other.
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// dct2D returns the type-II discrete cosine transform of a square block.
+func dct2D(in [sampleSize][sampleSize]float64) [sampleSize][sampleSize]float64 {
+	var cosines [sampleSize][sampleSize]float64
+	for k := 0; k < sampleSize; k++ {
+		for n := 0; n < sampleSize; n++ {
+			cosines[k][n] = math.Cos(math.Pi / sampleSize * (float64(n) + 0.5) * float64(k))
+		}
+	}
+
+	var rows, out [sampleSize][sampleSize]float64
+	for y := 0; y < sampleSize; y++ {
+		for k := 0; k < sampleSize; k++ {
+			var sum float64
+			for n := 0; n < sampleSize; n++ {
+				sum += in[y][n] * cosines[k][n]
+			}
+			rows[y][k] = sum
+		}
+	}
+	for x := 0; x < sampleSize; x++ {
+		for k := 0; k < sampleSize; k++ {
+			var sum float64
+			for n := 0; n < sampleSize; n++ {
+				sum += rows[n][x] * cosines[k][n]
+			}
+			out[k][x] = sum
+		}
+	}
+	return out
+}
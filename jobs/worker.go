@@ -0,0 +1,91 @@
+// Package jobs runs the background jobs queued in the database.
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/models"
+)
+
+const (
+	defaultInterval  = time.Second
+	defaultBatchSize = 50
+)
+
+// Handler runs one job given its JSON payload.
+type Handler func(ctx context.Context, payload []byte) error
+
+// Worker polls the job queue and runs due jobs with the handler registered
+// for their type.
+type Worker struct {
+	repo      db.JobRepository
+	handlers  map[string]Handler
+	interval  time.Duration
+	batchSize int
+}
+
+// NewWorker creates a worker for the queue in repo.
+func NewWorker(repo db.JobRepository) *Worker {
+	return &Worker{
+		repo:      repo,
+		handlers:  map[string]Handler{},
+		interval:  defaultInterval,
+		batchSize: defaultBatchSize,
+	}
+}
+
+// Handle registers the handler for jobs of jobType. It must be called before
+// Run.
+func (w *Worker) Handle(jobType string, handler Handler) {
+	w.handlers[jobType] = handler
+}
+
+// Run processes jobs until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		w.drain(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// drain runs batches until no due job is left.
+func (w *Worker) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		done, err := w.repo.RunPending(w.batchSize, func(job *models.Job) error {
+			handler, ok := w.handlers[job.Type]
+			if !ok {
+				return fmt.Errorf("no handler for job type %q", job.Type)
+			}
+			return run(ctx, handler, job)
+		})
+		if err != nil {
+			log.Printf("job worker failed: %v", err)
+			return
+		}
+		if done < w.batchSize {
+			return
+		}
+	}
+}
+
+// run calls handler, turning a panic into an error so one bad job cannot
+// stop the worker.
+func run(ctx context.Context, handler Handler, job *models.Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job %d panicked: %v", job.ID, r)
+		}
+	}()
+	return handler(ctx, []byte(job.Payload))
+}
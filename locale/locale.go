@@ -0,0 +1,196 @@
+// Package locale formats dates, numbers and naira amounts for the
+// human-readable text the API produces, such as notifications, digests and
+// printed reports. Machine-readable fields keep their RFC 3339 timestamps
+// and plain numbers.
+package locale
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Default is the locale used for users who have not chosen one and for
+// requests that name no supported locale
+const Default = "en-NG"
+
+// TimeZone is the zone every date is shown in. Reports are Nigerian, so a
+// date means the day in Lagos wherever the reader is.
+var TimeZone = loadLagos()
+
+// loadLagos falls back to a fixed West Africa Time zone on hosts without
+// zone data; Nigeria does not observe daylight saving
+func loadLagos() *time.Location {
+	if loc, err := time.LoadLocation("Africa/Lagos"); err == nil {
+		return loc
+	}
+	return time.FixedZone("WAT", 60*60)
+}
+
+// format is how one locale writes dates and numbers
+type format struct {
+	date, dateTime string
+	// months replaces Go's English month abbreviations, when set
+	months         []string
+	group, decimal string
+	currencyPrefix string
+	currencySuffix string
+}
+
+var formats = map[string]format{
+	"en-NG": {date: "2 Jan 2006", dateTime: "2 Jan 2006, 15:04", group: ",", decimal: ".", currencyPrefix: "₦"},
+	"en-GB": {date: "2 Jan 2006", dateTime: "2 Jan 2006, 15:04", group: ",", decimal: ".", currencyPrefix: "₦"},
+	"en-US": {date: "Jan 2, 2006", dateTime: "Jan 2, 2006, 3:04 PM", group: ",", decimal: ".", currencyPrefix: "₦"},
+	"fr-FR": {
+		date:     "2 Jan 2006",
+		dateTime: "2 Jan 2006 15:04",
+		months: []string{"janv.", "févr.", "mars", "avr.", "mai", "juin",
+			"juil.", "août", "sept.", "oct.", "nov.", "déc."},
+		group:          " ",
+		decimal:        ",",
+		currencySuffix: " ₦",
+	},
+}
+
+// Supported lists the locales users can choose, the default first
+var Supported = []string{"en-NG", "en-GB", "en-US", "fr-FR"}
+
+// Normalize returns the supported locale tag matches, accepting any case,
+// underscores and bare languages ("fr" is fr-FR, "en" is en-NG). It reports
+// false when no supported locale matches.
+func Normalize(tag string) (string, bool) {
+	tag = strings.ReplaceAll(strings.TrimSpace(tag), "_", "-")
+	if tag == "" {
+		return "", false
+	}
+	language, region, _ := strings.Cut(tag, "-")
+	language = strings.ToLower(language)
+	if region != "" {
+		candidate := language + "-" + strings.ToUpper(region)
+		if _, ok := formats[candidate]; ok {
+			return candidate, true
+		}
+	}
+	// The first supported locale of the language stands in for its other
+	// regions
+	for _, supported := range Supported {
+		if strings.HasPrefix(supported, language+"-") {
+			return supported, true
+		}
+	}
+	return "", false
+}
+
+// FromAcceptLanguage returns the first supported locale in an
+// Accept-Language header, or "" when none is.
+func FromAcceptLanguage(header string) string {
+	for _, part := range strings.Split(header, ",") {
+		tag, _, _ := strings.Cut(part, ";")
+		if normalized, ok := Normalize(tag); ok {
+			return normalized
+		}
+	}
+	return ""
+}
+
+// Formatter writes values the way one locale reads them
+type Formatter struct {
+	tag string
+	f   format
+}
+
+// For returns the formatter of a locale, falling back to Default for
+// unsupported tags.
+func For(tag string) Formatter {
+	normalized, ok := Normalize(tag)
+	if !ok {
+		normalized = Default
+	}
+	return Formatter{tag: normalized, f: formats[normalized]}
+}
+
+// Tag returns the locale the formatter writes for
+func (f Formatter) Tag() string {
+	return f.tag
+}
+
+// Date writes the day of t in Lagos, such as 2 Jan 2006
+func (f Formatter) Date(t time.Time) string {
+	return f.layout(t, f.f.date)
+}
+
+// DateTime writes t to the minute in Lagos time
+func (f Formatter) DateTime(t time.Time) string {
+	return f.layout(t, f.f.dateTime)
+}
+
+// Unix writes a Unix timestamp as a date, or "" for zero
+func (f Formatter) Unix(sec int64) string {
+	if sec == 0 {
+		return ""
+	}
+	return f.Date(time.Unix(sec, 0))
+}
+
+func (f Formatter) layout(t time.Time, layout string) string {
+	t = t.In(TimeZone)
+	if f.f.months == nil {
+		return t.Format(layout)
+	}
+	// Go only knows English month names, so the month is written separately
+	before, after, _ := strings.Cut(layout, "Jan")
+	return t.Format(before) + f.f.months[t.Month()-1] + t.Format(after)
+}
+
+// Number writes n with the locale's digit grouping, such as 12,500
+func (f Formatter) Number(n int64) string {
+	sign := ""
+	if n < 0 {
+		sign, n = "-", -n
+	}
+	return sign + f.group(strconv.FormatInt(n, 10))
+}
+
+// Decimal writes v rounded to places decimals
+func (f Formatter) Decimal(v float64, places int) string {
+	s := strconv.FormatFloat(math.Abs(v), 'f', places, 64)
+	whole, fraction, _ := strings.Cut(s, ".")
+	sign := ""
+	if v < 0 && strings.Trim(s, "0.") != "" {
+		sign = "-"
+	}
+	if fraction == "" {
+		return sign + f.group(whole)
+	}
+	return sign + f.group(whole) + f.f.decimal + fraction
+}
+
+// Currency writes an amount in kobo as naira, such as ₦1,250.00
+func (f Formatter) Currency(kobo int64) string {
+	sign := ""
+	if kobo < 0 {
+		sign, kobo = "-", -kobo
+	}
+	amount := f.group(strconv.FormatInt(kobo/100, 10)) + f.f.decimal + strconv.FormatInt(kobo%100+100, 10)[1:]
+	return sign + f.f.currencyPrefix + amount + f.f.currencySuffix
+}
+
+// group inserts the group separator every three digits
+func (f Formatter) group(digits string) string {
+	if len(digits) <= 3 {
+		return digits
+	}
+	var b strings.Builder
+	head := len(digits) % 3
+	if head > 0 {
+		b.WriteString(digits[:head])
+	}
+	for i := head; i < len(digits); i += 3 {
+		if b.Len() > 0 {
+			b.WriteString(f.f.group)
+		}
+		b.WriteString(digits[i : i+3])
+	}
+	return b.String()
+}
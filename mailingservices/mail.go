@@ -13,6 +13,7 @@ type Mailgun struct {
 
 type Mailer interface {
 	SendSimpleMessage(UserEmail, EmailSubject, EmailBody string) (string, error)
+	SendHTMLMessage(userEmail, subject, text, html string, headers map[string]string) (string, error)
 	SendVerifyAccount(userEmail, link string) (string, error)
 	SendResetPassword(userEmail, link string) (string, error)
 }
@@ -38,6 +39,27 @@ func (mail Mailgun) SendSimpleMessage(UserEmail, EmailSubject, EmailBody string)
 	return res, nil
 }
 
+// SendHTMLMessage sends an HTML email with a plain text alternative and any
+// extra headers, such as List-Unsubscribe
+func (mail Mailgun) SendHTMLMessage(userEmail, subject, text, html string, headers map[string]string) (string, error) {
+	EmailFrom := os.Getenv("MG_EMAIL_FROM")
+
+	m := mail.Client.NewMessage(EmailFrom, subject, text, userEmail)
+	m.SetHtml(html)
+	for name, value := range headers {
+		m.AddHeader(name, value)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*30)
+	defer cancel()
+
+	res, _, err := mail.Client.Send(ctx, m)
+	if err != nil {
+		return "", err
+	}
+	return res, nil
+}
+
 func (mail *Mailgun) SendVerifyAccount(userEmail, link string) (string, error) {
 	EmailFrom := os.Getenv("MG_EMAIL_FROM")
 
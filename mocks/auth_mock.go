@@ -0,0 +1,215 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/techagentng/citizenx/services (interfaces: AuthService)
+//
+// Generated by this command:
+//
+//	mockgen -destination=../mocks/auth_mock.go -package=mocks github.com/techagentng/citizenx/services AuthService
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	errors "github.com/techagentng/citizenx/errors"
+	models "github.com/techagentng/citizenx/models"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAuthService is a mock of AuthService interface.
+type MockAuthService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuthServiceMockRecorder
+}
+
+// MockAuthServiceMockRecorder is the mock recorder for MockAuthService.
+type MockAuthServiceMockRecorder struct {
+	mock *MockAuthService
+}
+
+// NewMockAuthService creates a new mock instance.
+func NewMockAuthService(ctrl *gomock.Controller) *MockAuthService {
+	mock := &MockAuthService{ctrl: ctrl}
+	mock.recorder = &MockAuthServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuthService) EXPECT() *MockAuthServiceMockRecorder {
+	return m.recorder
+}
+
+// ChangeEmail mocks base method.
+func (m *MockAuthService) ChangeEmail(arg0 uint, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeEmail", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ChangeEmail indicates an expected call of ChangeEmail.
+func (mr *MockAuthServiceMockRecorder) ChangeEmail(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeEmail", reflect.TypeOf((*MockAuthService)(nil).ChangeEmail), arg0, arg1)
+}
+
+// DeactivateAccount mocks base method.
+func (m *MockAuthService) DeactivateAccount(arg0 uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeactivateAccount", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeactivateAccount indicates an expected call of DeactivateAccount.
+func (mr *MockAuthServiceMockRecorder) DeactivateAccount(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeactivateAccount", reflect.TypeOf((*MockAuthService)(nil).DeactivateAccount), arg0)
+}
+
+// DeleteUser mocks base method.
+func (m *MockAuthService) DeleteUser(arg0 uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteUser", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteUser indicates an expected call of DeleteUser.
+func (mr *MockAuthServiceMockRecorder) DeleteUser(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUser", reflect.TypeOf((*MockAuthService)(nil).DeleteUser), arg0)
+}
+
+// EditUserProfile mocks base method.
+func (m *MockAuthService) EditUserProfile(arg0 uint, arg1 *models.EditProfileResponse) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EditUserProfile", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EditUserProfile indicates an expected call of EditUserProfile.
+func (mr *MockAuthServiceMockRecorder) EditUserProfile(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EditUserProfile", reflect.TypeOf((*MockAuthService)(nil).EditUserProfile), arg0, arg1)
+}
+
+// GetAllUsers mocks base method.
+func (m *MockAuthService) GetAllUsers() ([]models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllUsers")
+	ret0, _ := ret[0].([]models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllUsers indicates an expected call of GetAllUsers.
+func (mr *MockAuthServiceMockRecorder) GetAllUsers() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllUsers", reflect.TypeOf((*MockAuthService)(nil).GetAllUsers))
+}
+
+// GetRoleByName mocks base method.
+func (m *MockAuthService) GetRoleByName(arg0 string) (*models.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRoleByName", arg0)
+	ret0, _ := ret[0].(*models.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRoleByName indicates an expected call of GetRoleByName.
+func (mr *MockAuthServiceMockRecorder) GetRoleByName(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoleByName", reflect.TypeOf((*MockAuthService)(nil).GetRoleByName), arg0)
+}
+
+// GetUserProfile mocks base method.
+func (m *MockAuthService) GetUserProfile(arg0 uint) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserProfile", arg0)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserProfile indicates an expected call of GetUserProfile.
+func (mr *MockAuthServiceMockRecorder) GetUserProfile(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserProfile", reflect.TypeOf((*MockAuthService)(nil).GetUserProfile), arg0)
+}
+
+// LoginMacAddressUser mocks base method.
+func (m *MockAuthService) LoginMacAddressUser(arg0 *models.LoginRequestMacAddress) (*models.LoginRequestMacAddress, *errors.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoginMacAddressUser", arg0)
+	ret0, _ := ret[0].(*models.LoginRequestMacAddress)
+	ret1, _ := ret[1].(*errors.Error)
+	return ret0, ret1
+}
+
+// LoginMacAddressUser indicates an expected call of LoginMacAddressUser.
+func (mr *MockAuthServiceMockRecorder) LoginMacAddressUser(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginMacAddressUser", reflect.TypeOf((*MockAuthService)(nil).LoginMacAddressUser), arg0)
+}
+
+// LoginUser mocks base method.
+func (m *MockAuthService) LoginUser(arg0 *models.LoginRequest) (*models.LoginResponse, *errors.Error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoginUser", arg0)
+	ret0, _ := ret[0].(*models.LoginResponse)
+	ret1, _ := ret[1].(*errors.Error)
+	return ret0, ret1
+}
+
+// LoginUser indicates an expected call of LoginUser.
+func (mr *MockAuthServiceMockRecorder) LoginUser(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginUser", reflect.TypeOf((*MockAuthService)(nil).LoginUser), arg0)
+}
+
+// ResetPassword mocks base method.
+func (m *MockAuthService) ResetPassword(arg0 *models.ResetPassword, arg1 string) *errors.Error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", arg0, arg1)
+	ret0, _ := ret[0].(*errors.Error)
+	return ret0
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockAuthServiceMockRecorder) ResetPassword(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockAuthService)(nil).ResetPassword), arg0, arg1)
+}
+
+// SendEmailForPasswordReset mocks base method.
+func (m *MockAuthService) SendEmailForPasswordReset(arg0 *models.ForgotPassword) *errors.Error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendEmailForPasswordReset", arg0)
+	ret0, _ := ret[0].(*errors.Error)
+	return ret0
+}
+
+// SendEmailForPasswordReset indicates an expected call of SendEmailForPasswordReset.
+func (mr *MockAuthServiceMockRecorder) SendEmailForPasswordReset(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendEmailForPasswordReset", reflect.TypeOf((*MockAuthService)(nil).SendEmailForPasswordReset), arg0)
+}
+
+// SignupUser mocks base method.
+func (m *MockAuthService) SignupUser(arg0 *models.User) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SignupUser", arg0)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SignupUser indicates an expected call of SignupUser.
+func (mr *MockAuthServiceMockRecorder) SignupUser(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SignupUser", reflect.TypeOf((*MockAuthService)(nil).SignupUser), arg0)
+}
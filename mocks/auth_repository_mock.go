@@ -0,0 +1,518 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/techagentng/citizenx/db (interfaces: AuthRepository)
+//
+// Generated by this command:
+//
+//	mockgen -destination=../mocks/auth_repository_mock.go -package=mocks github.com/techagentng/citizenx/db AuthRepository
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	uuid "github.com/google/uuid"
+	models "github.com/techagentng/citizenx/models"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockAuthRepository is a mock of AuthRepository interface.
+type MockAuthRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuthRepositoryMockRecorder
+}
+
+// MockAuthRepositoryMockRecorder is the mock recorder for MockAuthRepository.
+type MockAuthRepositoryMockRecorder struct {
+	mock *MockAuthRepository
+}
+
+// NewMockAuthRepository creates a new mock instance.
+func NewMockAuthRepository(ctrl *gomock.Controller) *MockAuthRepository {
+	mock := &MockAuthRepository{ctrl: ctrl}
+	mock.recorder = &MockAuthRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuthRepository) EXPECT() *MockAuthRepositoryMockRecorder {
+	return m.recorder
+}
+
+// AddToBlackList mocks base method.
+func (m *MockAuthRepository) AddToBlackList(arg0 *models.Blacklist) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddToBlackList", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddToBlackList indicates an expected call of AddToBlackList.
+func (mr *MockAuthRepositoryMockRecorder) AddToBlackList(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddToBlackList", reflect.TypeOf((*MockAuthRepository)(nil).AddToBlackList), arg0)
+}
+
+// CreateGoogleUser mocks base method.
+func (m *MockAuthRepository) CreateGoogleUser(arg0 *models.CreateSocialUserParams) (*models.CreateSocialUserParams, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateGoogleUser", arg0)
+	ret0, _ := ret[0].(*models.CreateSocialUserParams)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateGoogleUser indicates an expected call of CreateGoogleUser.
+func (mr *MockAuthRepositoryMockRecorder) CreateGoogleUser(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateGoogleUser", reflect.TypeOf((*MockAuthRepository)(nil).CreateGoogleUser), arg0)
+}
+
+// CreateUser mocks base method.
+func (m *MockAuthRepository) CreateUser(arg0 *models.User) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUser", arg0)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUser indicates an expected call of CreateUser.
+func (mr *MockAuthRepositoryMockRecorder) CreateUser(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockAuthRepository)(nil).CreateUser), arg0)
+}
+
+// CreateUserWithMacAddress mocks base method.
+func (m *MockAuthRepository) CreateUserWithMacAddress(arg0 *models.LoginRequestMacAddress) (*models.LoginRequestMacAddress, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUserWithMacAddress", arg0)
+	ret0, _ := ret[0].(*models.LoginRequestMacAddress)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUserWithMacAddress indicates an expected call of CreateUserWithMacAddress.
+func (mr *MockAuthRepositoryMockRecorder) CreateUserWithMacAddress(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUserWithMacAddress", reflect.TypeOf((*MockAuthRepository)(nil).CreateUserWithMacAddress), arg0)
+}
+
+// DeactivateUser mocks base method.
+func (m *MockAuthRepository) DeactivateUser(arg0 uint, arg1 int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeactivateUser", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeactivateUser indicates an expected call of DeactivateUser.
+func (mr *MockAuthRepositoryMockRecorder) DeactivateUser(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeactivateUser", reflect.TypeOf((*MockAuthRepository)(nil).DeactivateUser), arg0, arg1)
+}
+
+// EditUserProfile mocks base method.
+func (m *MockAuthRepository) EditUserProfile(arg0 uint, arg1 *models.EditProfileResponse) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EditUserProfile", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EditUserProfile indicates an expected call of EditUserProfile.
+func (mr *MockAuthRepositoryMockRecorder) EditUserProfile(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EditUserProfile", reflect.TypeOf((*MockAuthRepository)(nil).EditUserProfile), arg0, arg1)
+}
+
+// FindRoleByID mocks base method.
+func (m *MockAuthRepository) FindRoleByID(arg0 uuid.UUID) (*models.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRoleByID", arg0)
+	ret0, _ := ret[0].(*models.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindRoleByID indicates an expected call of FindRoleByID.
+func (mr *MockAuthRepositoryMockRecorder) FindRoleByID(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRoleByID", reflect.TypeOf((*MockAuthRepository)(nil).FindRoleByID), arg0)
+}
+
+// FindRoleByName mocks base method.
+func (m *MockAuthRepository) FindRoleByName(arg0 string) (*models.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRoleByName", arg0)
+	ret0, _ := ret[0].(*models.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindRoleByName indicates an expected call of FindRoleByName.
+func (mr *MockAuthRepositoryMockRecorder) FindRoleByName(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRoleByName", reflect.TypeOf((*MockAuthRepository)(nil).FindRoleByName), arg0)
+}
+
+// FindRoleByUserEmail mocks base method.
+func (m *MockAuthRepository) FindRoleByUserEmail(arg0 string) (*models.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindRoleByUserEmail", arg0)
+	ret0, _ := ret[0].(*models.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindRoleByUserEmail indicates an expected call of FindRoleByUserEmail.
+func (mr *MockAuthRepositoryMockRecorder) FindRoleByUserEmail(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindRoleByUserEmail", reflect.TypeOf((*MockAuthRepository)(nil).FindRoleByUserEmail), arg0)
+}
+
+// FindUserByEmail mocks base method.
+func (m *MockAuthRepository) FindUserByEmail(arg0 string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUserByEmail", arg0)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUserByEmail indicates an expected call of FindUserByEmail.
+func (mr *MockAuthRepositoryMockRecorder) FindUserByEmail(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUserByEmail", reflect.TypeOf((*MockAuthRepository)(nil).FindUserByEmail), arg0)
+}
+
+// FindUserByID mocks base method.
+func (m *MockAuthRepository) FindUserByID(arg0 uint) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUserByID", arg0)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUserByID indicates an expected call of FindUserByID.
+func (mr *MockAuthRepositoryMockRecorder) FindUserByID(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUserByID", reflect.TypeOf((*MockAuthRepository)(nil).FindUserByID), arg0)
+}
+
+// FindUserByMacAddress mocks base method.
+func (m *MockAuthRepository) FindUserByMacAddress(arg0 string) (*models.LoginRequestMacAddress, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUserByMacAddress", arg0)
+	ret0, _ := ret[0].(*models.LoginRequestMacAddress)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUserByMacAddress indicates an expected call of FindUserByMacAddress.
+func (mr *MockAuthRepositoryMockRecorder) FindUserByMacAddress(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUserByMacAddress", reflect.TypeOf((*MockAuthRepository)(nil).FindUserByMacAddress), arg0)
+}
+
+// FindUserByUsername mocks base method.
+func (m *MockAuthRepository) FindUserByUsername(arg0 string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUserByUsername", arg0)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUserByUsername indicates an expected call of FindUserByUsername.
+func (mr *MockAuthRepositoryMockRecorder) FindUserByUsername(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUserByUsername", reflect.TypeOf((*MockAuthRepository)(nil).FindUserByUsername), arg0)
+}
+
+// GetAllUsers mocks base method.
+func (m *MockAuthRepository) GetAllUsers() ([]models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllUsers")
+	ret0, _ := ret[0].([]models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllUsers indicates an expected call of GetAllUsers.
+func (mr *MockAuthRepositoryMockRecorder) GetAllUsers() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllUsers", reflect.TypeOf((*MockAuthRepository)(nil).GetAllUsers))
+}
+
+// GetOnlineUserCount mocks base method.
+func (m *MockAuthRepository) GetOnlineUserCount() (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOnlineUserCount")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOnlineUserCount indicates an expected call of GetOnlineUserCount.
+func (mr *MockAuthRepositoryMockRecorder) GetOnlineUserCount() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOnlineUserCount", reflect.TypeOf((*MockAuthRepository)(nil).GetOnlineUserCount))
+}
+
+// GetUserRoleByUserID mocks base method.
+func (m *MockAuthRepository) GetUserRoleByUserID(arg0 uint) (*models.Role, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserRoleByUserID", arg0)
+	ret0, _ := ret[0].(*models.Role)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserRoleByUserID indicates an expected call of GetUserRoleByUserID.
+func (mr *MockAuthRepositoryMockRecorder) GetUserRoleByUserID(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserRoleByUserID", reflect.TypeOf((*MockAuthRepository)(nil).GetUserRoleByUserID), arg0)
+}
+
+// IsEmailExist mocks base method.
+func (m *MockAuthRepository) IsEmailExist(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsEmailExist", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IsEmailExist indicates an expected call of IsEmailExist.
+func (mr *MockAuthRepositoryMockRecorder) IsEmailExist(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsEmailExist", reflect.TypeOf((*MockAuthRepository)(nil).IsEmailExist), arg0)
+}
+
+// IsPhoneExist mocks base method.
+func (m *MockAuthRepository) IsPhoneExist(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsPhoneExist", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IsPhoneExist indicates an expected call of IsPhoneExist.
+func (mr *MockAuthRepositoryMockRecorder) IsPhoneExist(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsPhoneExist", reflect.TypeOf((*MockAuthRepository)(nil).IsPhoneExist), arg0)
+}
+
+// IsTokenInBlacklist mocks base method.
+func (m *MockAuthRepository) IsTokenInBlacklist(arg0 string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsTokenInBlacklist", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsTokenInBlacklist indicates an expected call of IsTokenInBlacklist.
+func (mr *MockAuthRepositoryMockRecorder) IsTokenInBlacklist(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsTokenInBlacklist", reflect.TypeOf((*MockAuthRepository)(nil).IsTokenInBlacklist), arg0)
+}
+
+// ReactivateUser mocks base method.
+func (m *MockAuthRepository) ReactivateUser(arg0 uint) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReactivateUser", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReactivateUser indicates an expected call of ReactivateUser.
+func (mr *MockAuthRepositoryMockRecorder) ReactivateUser(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReactivateUser", reflect.TypeOf((*MockAuthRepository)(nil).ReactivateUser), arg0)
+}
+
+// ResetPassword mocks base method.
+func (m *MockAuthRepository) ResetPassword(arg0, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResetPassword", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResetPassword indicates an expected call of ResetPassword.
+func (mr *MockAuthRepositoryMockRecorder) ResetPassword(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResetPassword", reflect.TypeOf((*MockAuthRepository)(nil).ResetPassword), arg0, arg1)
+}
+
+// SetUserOffline mocks base method.
+func (m *MockAuthRepository) SetUserOffline(arg0 *models.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserOffline", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUserOffline indicates an expected call of SetUserOffline.
+func (mr *MockAuthRepositoryMockRecorder) SetUserOffline(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserOffline", reflect.TypeOf((*MockAuthRepository)(nil).SetUserOffline), arg0)
+}
+
+// SoftDeleteUser mocks base method.
+func (m *MockAuthRepository) SoftDeleteUser(arg0 uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SoftDeleteUser", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SoftDeleteUser indicates an expected call of SoftDeleteUser.
+func (mr *MockAuthRepositoryMockRecorder) SoftDeleteUser(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SoftDeleteUser", reflect.TypeOf((*MockAuthRepository)(nil).SoftDeleteUser), arg0)
+}
+
+// TokenInBlacklist mocks base method.
+func (m *MockAuthRepository) TokenInBlacklist(arg0 string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TokenInBlacklist", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// TokenInBlacklist indicates an expected call of TokenInBlacklist.
+func (mr *MockAuthRepositoryMockRecorder) TokenInBlacklist(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TokenInBlacklist", reflect.TypeOf((*MockAuthRepository)(nil).TokenInBlacklist), arg0)
+}
+
+// UpdateEmail mocks base method.
+func (m *MockAuthRepository) UpdateEmail(arg0 uint, arg1 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateEmail", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateEmail indicates an expected call of UpdateEmail.
+func (mr *MockAuthRepositoryMockRecorder) UpdateEmail(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateEmail", reflect.TypeOf((*MockAuthRepository)(nil).UpdateEmail), arg0, arg1)
+}
+
+// UpdatePassword mocks base method.
+func (m *MockAuthRepository) UpdatePassword(arg0, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePassword", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePassword indicates an expected call of UpdatePassword.
+func (mr *MockAuthRepositoryMockRecorder) UpdatePassword(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockAuthRepository)(nil).UpdatePassword), arg0, arg1)
+}
+
+// UpdateUser mocks base method.
+func (m *MockAuthRepository) UpdateUser(arg0 *models.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUser", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUser indicates an expected call of UpdateUser.
+func (mr *MockAuthRepositoryMockRecorder) UpdateUser(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUser", reflect.TypeOf((*MockAuthRepository)(nil).UpdateUser), arg0)
+}
+
+// UpdateUserOnlineStatus mocks base method.
+func (m *MockAuthRepository) UpdateUserOnlineStatus(arg0 *models.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserOnlineStatus", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserOnlineStatus indicates an expected call of UpdateUserOnlineStatus.
+func (mr *MockAuthRepositoryMockRecorder) UpdateUserOnlineStatus(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserOnlineStatus", reflect.TypeOf((*MockAuthRepository)(nil).UpdateUserOnlineStatus), arg0)
+}
+
+// UpdateUserPassword mocks base method.
+func (m *MockAuthRepository) UpdateUserPassword(arg0 *models.User, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserPassword", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserPassword indicates an expected call of UpdateUserPassword.
+func (mr *MockAuthRepositoryMockRecorder) UpdateUserPassword(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserPassword", reflect.TypeOf((*MockAuthRepository)(nil).UpdateUserPassword), arg0, arg1)
+}
+
+// UpdateUserRole mocks base method.
+func (m *MockAuthRepository) UpdateUserRole(arg0 uint, arg1 *models.Role) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserRole", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserRole indicates an expected call of UpdateUserRole.
+func (mr *MockAuthRepositoryMockRecorder) UpdateUserRole(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserRole", reflect.TypeOf((*MockAuthRepository)(nil).UpdateUserRole), arg0, arg1)
+}
+
+// UpdateUserStatus mocks base method.
+func (m *MockAuthRepository) UpdateUserStatus(arg0 *models.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateUserStatus", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateUserStatus indicates an expected call of UpdateUserStatus.
+func (mr *MockAuthRepositoryMockRecorder) UpdateUserStatus(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserStatus", reflect.TypeOf((*MockAuthRepository)(nil).UpdateUserStatus), arg0)
+}
+
+// UpsertUserImage mocks base method.
+func (m *MockAuthRepository) UpsertUserImage(arg0 uint, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpsertUserImage", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpsertUserImage indicates an expected call of UpsertUserImage.
+func (mr *MockAuthRepositoryMockRecorder) UpsertUserImage(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertUserImage", reflect.TypeOf((*MockAuthRepository)(nil).UpsertUserImage), arg0, arg1)
+}
+
+// VerifyEmail mocks base method.
+func (m *MockAuthRepository) VerifyEmail(arg0, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmail", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail.
+func (mr *MockAuthRepositoryMockRecorder) VerifyEmail(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockAuthRepository)(nil).VerifyEmail), arg0, arg1)
+}
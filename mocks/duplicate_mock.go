@@ -0,0 +1,86 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/techagentng/citizenx/services (interfaces: DuplicateService)
+//
+// Generated by this command:
+//
+//	mockgen -destination=../mocks/duplicate_mock.go -package=mocks github.com/techagentng/citizenx/services DuplicateService
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	models "github.com/techagentng/citizenx/models"
+	services "github.com/techagentng/citizenx/services"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDuplicateService is a mock of DuplicateService interface.
+type MockDuplicateService struct {
+	ctrl     *gomock.Controller
+	recorder *MockDuplicateServiceMockRecorder
+}
+
+// MockDuplicateServiceMockRecorder is the mock recorder for MockDuplicateService.
+type MockDuplicateServiceMockRecorder struct {
+	mock *MockDuplicateService
+}
+
+// NewMockDuplicateService creates a new mock instance.
+func NewMockDuplicateService(ctrl *gomock.Controller) *MockDuplicateService {
+	mock := &MockDuplicateService{ctrl: ctrl}
+	mock.recorder = &MockDuplicateServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDuplicateService) EXPECT() *MockDuplicateServiceMockRecorder {
+	return m.recorder
+}
+
+// Detect mocks base method.
+func (m *MockDuplicateService) Detect(arg0 *models.IncidentReport) (*models.ReportCluster, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Detect", arg0)
+	ret0, _ := ret[0].(*models.ReportCluster)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Detect indicates an expected call of Detect.
+func (mr *MockDuplicateServiceMockRecorder) Detect(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Detect", reflect.TypeOf((*MockDuplicateService)(nil).Detect), arg0)
+}
+
+// Duplicates mocks base method.
+func (m *MockDuplicateService) Duplicates(arg0 string) (*services.ReportDuplicates, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Duplicates", arg0)
+	ret0, _ := ret[0].(*services.ReportDuplicates)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Duplicates indicates an expected call of Duplicates.
+func (mr *MockDuplicateServiceMockRecorder) Duplicates(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Duplicates", reflect.TypeOf((*MockDuplicateService)(nil).Duplicates), arg0)
+}
+
+// HistoryContext mocks base method.
+func (m *MockDuplicateService) HistoryContext(arg0 string) (*services.ReportHistoryContext, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HistoryContext", arg0)
+	ret0, _ := ret[0].(*services.ReportHistoryContext)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HistoryContext indicates an expected call of HistoryContext.
+func (mr *MockDuplicateServiceMockRecorder) HistoryContext(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HistoryContext", reflect.TypeOf((*MockDuplicateService)(nil).HistoryContext), arg0)
+}
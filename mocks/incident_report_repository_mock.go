@@ -0,0 +1,1161 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/techagentng/citizenx/db (interfaces: IncidentReportRepository)
+//
+// Generated by this command:
+//
+//	mockgen -destination=../mocks/incident_report_repository_mock.go -package=mocks github.com/techagentng/citizenx/db IncidentReportRepository
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	context "context"
+	multipart "mime/multipart"
+	reflect "reflect"
+	time "time"
+
+	uuid "github.com/google/uuid"
+	db "github.com/techagentng/citizenx/db"
+	events "github.com/techagentng/citizenx/events"
+	models "github.com/techagentng/citizenx/models"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockIncidentReportRepository is a mock of IncidentReportRepository interface.
+type MockIncidentReportRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockIncidentReportRepositoryMockRecorder
+}
+
+// MockIncidentReportRepositoryMockRecorder is the mock recorder for MockIncidentReportRepository.
+type MockIncidentReportRepositoryMockRecorder struct {
+	mock *MockIncidentReportRepository
+}
+
+// NewMockIncidentReportRepository creates a new mock instance.
+func NewMockIncidentReportRepository(ctrl *gomock.Controller) *MockIncidentReportRepository {
+	mock := &MockIncidentReportRepository{ctrl: ctrl}
+	mock.recorder = &MockIncidentReportRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockIncidentReportRepository) EXPECT() *MockIncidentReportRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CountUserReports mocks base method.
+func (m *MockIncidentReportRepository) CountUserReports(arg0 uint, arg1 db.UserReportFilter) (map[string]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountUserReports", arg0, arg1)
+	ret0, _ := ret[0].(map[string]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountUserReports indicates an expected call of CountUserReports.
+func (mr *MockIncidentReportRepositoryMockRecorder) CountUserReports(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountUserReports", reflect.TypeOf((*MockIncidentReportRepository)(nil).CountUserReports), arg0, arg1)
+}
+
+// DeleteByID mocks base method.
+func (m *MockIncidentReportRepository) DeleteByID(arg0 string, arg1 uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteByID", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteByID indicates an expected call of DeleteByID.
+func (mr *MockIncidentReportRepositoryMockRecorder) DeleteByID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteByID", reflect.TypeOf((*MockIncidentReportRepository)(nil).DeleteByID), arg0, arg1)
+}
+
+// EachReportBatch mocks base method.
+func (m *MockIncidentReportRepository) EachReportBatch(arg0 int, arg1 func([]models.IncidentReport) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EachReportBatch", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EachReportBatch indicates an expected call of EachReportBatch.
+func (mr *MockIncidentReportRepositoryMockRecorder) EachReportBatch(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EachReportBatch", reflect.TypeOf((*MockIncidentReportRepository)(nil).EachReportBatch), arg0, arg1)
+}
+
+// EditReport mocks base method.
+func (m *MockIncidentReportRepository) EditReport(arg0 uuid.UUID, arg1 uint, arg2 map[string]any, arg3 []string) (*models.ReportRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EditReport", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.ReportRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EditReport indicates an expected call of EditReport.
+func (mr *MockIncidentReportRepositoryMockRecorder) EditReport(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EditReport", reflect.TypeOf((*MockIncidentReportRepository)(nil).EditReport), arg0, arg1, arg2, arg3)
+}
+
+// FindIncidentReportByReportTypeID mocks base method.
+func (m *MockIncidentReportRepository) FindIncidentReportByReportTypeID(arg0 string) (*models.IncidentReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindIncidentReportByReportTypeID", arg0)
+	ret0, _ := ret[0].(*models.IncidentReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindIncidentReportByReportTypeID indicates an expected call of FindIncidentReportByReportTypeID.
+func (mr *MockIncidentReportRepositoryMockRecorder) FindIncidentReportByReportTypeID(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindIncidentReportByReportTypeID", reflect.TypeOf((*MockIncidentReportRepository)(nil).FindIncidentReportByReportTypeID), arg0)
+}
+
+// FindReportTypeByCategory mocks base method.
+func (m *MockIncidentReportRepository) FindReportTypeByCategory(arg0 string, arg1 *models.ReportType) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindReportTypeByCategory", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FindReportTypeByCategory indicates an expected call of FindReportTypeByCategory.
+func (mr *MockIncidentReportRepositoryMockRecorder) FindReportTypeByCategory(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindReportTypeByCategory", reflect.TypeOf((*MockIncidentReportRepository)(nil).FindReportTypeByCategory), arg0, arg1)
+}
+
+// FindUserByID mocks base method.
+func (m *MockIncidentReportRepository) FindUserByID(arg0 uint) (*models.UserResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindUserByID", arg0)
+	ret0, _ := ret[0].(*models.UserResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindUserByID indicates an expected call of FindUserByID.
+func (mr *MockIncidentReportRepositoryMockRecorder) FindUserByID(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindUserByID", reflect.TypeOf((*MockIncidentReportRepository)(nil).FindUserByID), arg0)
+}
+
+// GetAllCategories mocks base method.
+func (m *MockIncidentReportRepository) GetAllCategories() ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllCategories")
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllCategories indicates an expected call of GetAllCategories.
+func (mr *MockIncidentReportRepositoryMockRecorder) GetAllCategories() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllCategories", reflect.TypeOf((*MockIncidentReportRepository)(nil).GetAllCategories))
+}
+
+// GetAllIncidentReportsByUser mocks base method.
+func (m *MockIncidentReportRepository) GetAllIncidentReportsByUser(arg0 uint, arg1 ...db.PreloadOption) ([]models.IncidentReport, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0}
+	for _, a := range arg1 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetAllIncidentReportsByUser", varargs...)
+	ret0, _ := ret[0].([]models.IncidentReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllIncidentReportsByUser indicates an expected call of GetAllIncidentReportsByUser.
+func (mr *MockIncidentReportRepositoryMockRecorder) GetAllIncidentReportsByUser(arg0 any, arg1 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0}, arg1...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllIncidentReportsByUser", reflect.TypeOf((*MockIncidentReportRepository)(nil).GetAllIncidentReportsByUser), varargs...)
+}
+
+// GetAllReports mocks base method.
+func (m *MockIncidentReportRepository) GetAllReports(arg0 int, arg1 db.ReportSort, arg2 ...db.PreloadOption) ([]models.IncidentReport, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetAllReports", varargs...)
+	ret0, _ := ret[0].([]models.IncidentReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllReports indicates an expected call of GetAllReports.
+func (mr *MockIncidentReportRepositoryMockRecorder) GetAllReports(arg0, arg1 any, arg2 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllReports", reflect.TypeOf((*MockIncidentReportRepository)(nil).GetAllReports), varargs...)
+}
+
+// GetAllReportsByLGA mocks base method.
+func (m *MockIncidentReportRepository) GetAllReportsByLGA(arg0 string, arg1 int, arg2 db.ReportSort, arg3 ...db.PreloadOption) ([]models.IncidentReport, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1, arg2}
+	for _, a := range arg3 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetAllReportsByLGA", varargs...)
+	ret0, _ := ret[0].([]models.IncidentReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllReportsByLGA indicates an expected call of GetAllReportsByLGA.
+func (mr *MockIncidentReportRepositoryMockRecorder) GetAllReportsByLGA(arg0, arg1, arg2 any, arg3 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1, arg2}, arg3...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllReportsByLGA", reflect.TypeOf((*MockIncidentReportRepository)(nil).GetAllReportsByLGA), varargs...)
+}
+
+// GetAllReportsByReportType mocks base method.
+func (m *MockIncidentReportRepository) GetAllReportsByReportType(arg0 string, arg1 int, arg2 db.ReportSort, arg3 ...db.PreloadOption) ([]models.IncidentReport, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1, arg2}
+	for _, a := range arg3 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetAllReportsByReportType", varargs...)
+	ret0, _ := ret[0].([]models.IncidentReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllReportsByReportType indicates an expected call of GetAllReportsByReportType.
+func (mr *MockIncidentReportRepositoryMockRecorder) GetAllReportsByReportType(arg0, arg1, arg2 any, arg3 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1, arg2}, arg3...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllReportsByReportType", reflect.TypeOf((*MockIncidentReportRepository)(nil).GetAllReportsByReportType), varargs...)
+}
+
+// GetAllReportsByState mocks base method.
+func (m *MockIncidentReportRepository) GetAllReportsByState(arg0 string, arg1 int, arg2 db.ReportSort, arg3 ...db.PreloadOption) ([]models.IncidentReport, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1, arg2}
+	for _, a := range arg3 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetAllReportsByState", varargs...)
+	ret0, _ := ret[0].([]models.IncidentReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllReportsByState indicates an expected call of GetAllReportsByState.
+func (mr *MockIncidentReportRepositoryMockRecorder) GetAllReportsByState(arg0, arg1, arg2 any, arg3 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1, arg2}, arg3...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllReportsByState", reflect.TypeOf((*MockIncidentReportRepository)(nil).GetAllReportsByState), varargs...)
+}
+
+// GetAllReportsByStateByTime mocks base method.
+func (m *MockIncidentReportRepository) GetAllReportsByStateByTime(arg0 string, arg1, arg2 time.Time, arg3 int, arg4 db.ReportSort, arg5 ...db.PreloadOption) ([]models.IncidentReport, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1, arg2, arg3, arg4}
+	for _, a := range arg5 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetAllReportsByStateByTime", varargs...)
+	ret0, _ := ret[0].([]models.IncidentReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllReportsByStateByTime indicates an expected call of GetAllReportsByStateByTime.
+func (mr *MockIncidentReportRepositoryMockRecorder) GetAllReportsByStateByTime(arg0, arg1, arg2, arg3, arg4 any, arg5 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1, arg2, arg3, arg4}, arg5...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllReportsByStateByTime", reflect.TypeOf((*MockIncidentReportRepository)(nil).GetAllReportsByStateByTime), varargs...)
+}
+
+// GetAllStates mocks base method.
+func (m *MockIncidentReportRepository) GetAllStates() ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllStates")
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllStates indicates an expected call of GetAllStates.
+func (mr *MockIncidentReportRepositoryMockRecorder) GetAllStates() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllStates", reflect.TypeOf((*MockIncidentReportRepository)(nil).GetAllStates))
+}
+
+// GetBookmarkedReports mocks base method.
+func (m *MockIncidentReportRepository) GetBookmarkedReports(arg0 uint, arg1 ...db.PreloadOption) ([]models.IncidentReport, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0}
+	for _, a := range arg1 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetBookmarkedReports", varargs...)
+	ret0, _ := ret[0].([]models.IncidentReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBookmarkedReports indicates an expected call of GetBookmarkedReports.
+func (mr *MockIncidentReportRepositoryMockRecorder) GetBookmarkedReports(arg0 any, arg1 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0}, arg1...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBookmarkedReports", reflect.TypeOf((*MockIncidentReportRepository)(nil).GetBookmarkedReports), varargs...)
+}
+
+// GetFilteredIncidentReports mocks base method.
+func (m *MockIncidentReportRepository) GetFilteredIncidentReports(arg0, arg1, arg2 string) ([]models.IncidentReport, []string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFilteredIncidentReports", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.IncidentReport)
+	ret1, _ := ret[1].([]string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetFilteredIncidentReports indicates an expected call of GetFilteredIncidentReports.
+func (mr *MockIncidentReportRepositoryMockRecorder) GetFilteredIncidentReports(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilteredIncidentReports", reflect.TypeOf((*MockIncidentReportRepository)(nil).GetFilteredIncidentReports), arg0, arg1, arg2)
+}
+
+// GetIncidentMarkers mocks base method.
+func (m *MockIncidentReportRepository) GetIncidentMarkers() ([]db.Marker, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIncidentMarkers")
+	ret0, _ := ret[0].([]db.Marker)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIncidentMarkers indicates an expected call of GetIncidentMarkers.
+func (mr *MockIncidentReportRepositoryMockRecorder) GetIncidentMarkers() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIncidentMarkers", reflect.TypeOf((*MockIncidentReportRepository)(nil).GetIncidentMarkers))
+}
+
+// GetIncidentReportByID mocks base method.
+func (m *MockIncidentReportRepository) GetIncidentReportByID(arg0 string) (*models.IncidentReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIncidentReportByID", arg0)
+	ret0, _ := ret[0].(*models.IncidentReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIncidentReportByID indicates an expected call of GetIncidentReportByID.
+func (mr *MockIncidentReportRepositoryMockRecorder) GetIncidentReportByID(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIncidentReportByID", reflect.TypeOf((*MockIncidentReportRepository)(nil).GetIncidentReportByID), arg0)
+}
+
+// GetIncidentReportByReportTypeID mocks base method.
+func (m *MockIncidentReportRepository) GetIncidentReportByReportTypeID(arg0 string) (*models.IncidentReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetIncidentReportByReportTypeID", arg0)
+	ret0, _ := ret[0].(*models.IncidentReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetIncidentReportByReportTypeID indicates an expected call of GetIncidentReportByReportTypeID.
+func (mr *MockIncidentReportRepositoryMockRecorder) GetIncidentReportByReportTypeID(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIncidentReportByReportTypeID", reflect.TypeOf((*MockIncidentReportRepository)(nil).GetIncidentReportByReportTypeID), arg0)
+}
+
+// GetLastReportIDByUserID mocks base method.
+func (m *MockIncidentReportRepository) GetLastReportIDByUserID(arg0 uint) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastReportIDByUserID", arg0)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastReportIDByUserID indicates an expected call of GetLastReportIDByUserID.
+func (mr *MockIncidentReportRepositoryMockRecorder) GetLastReportIDByUserID(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastReportIDByUserID", reflect.TypeOf((*MockIncidentReportRepository)(nil).GetLastReportIDByUserID), arg0)
+}
+
+// GetNamesByCategory mocks base method.
+func (m *MockIncidentReportRepository) GetNamesByCategory(arg0, arg1, arg2 string) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetNamesByCategory", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNamesByCategory indicates an expected call of GetNamesByCategory.
+func (mr *MockIncidentReportRepositoryMockRecorder) GetNamesByCategory(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNamesByCategory", reflect.TypeOf((*MockIncidentReportRepository)(nil).GetNamesByCategory), arg0, arg1, arg2)
+}
+
+// GetRatingPercentages mocks base method.
+func (m *MockIncidentReportRepository) GetRatingPercentages(arg0, arg1 string) (*models.RatingPercentage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRatingPercentages", arg0, arg1)
+	ret0, _ := ret[0].(*models.RatingPercentage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRatingPercentages indicates an expected call of GetRatingPercentages.
+func (mr *MockIncidentReportRepositoryMockRecorder) GetRatingPercentages(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRatingPercentages", reflect.TypeOf((*MockIncidentReportRepository)(nil).GetRatingPercentages), arg0, arg1)
+}
+
+// GetRegisteredUsersCountByLGA mocks base method.
+func (m *MockIncidentReportRepository) GetRegisteredUsersCountByLGA(arg0 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRegisteredUsersCountByLGA", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRegisteredUsersCountByLGA indicates an expected call of GetRegisteredUsersCountByLGA.
+func (mr *MockIncidentReportRepositoryMockRecorder) GetRegisteredUsersCountByLGA(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRegisteredUsersCountByLGA", reflect.TypeOf((*MockIncidentReportRepository)(nil).GetRegisteredUsersCountByLGA), arg0)
+}
+
+// GetReportByID mocks base method.
+func (m *MockIncidentReportRepository) GetReportByID(arg0 string) (*models.IncidentReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReportByID", arg0)
+	ret0, _ := ret[0].(*models.IncidentReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReportByID indicates an expected call of GetReportByID.
+func (mr *MockIncidentReportRepositoryMockRecorder) GetReportByID(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReportByID", reflect.TypeOf((*MockIncidentReportRepository)(nil).GetReportByID), arg0)
+}
+
+// GetReportCountsByState mocks base method.
+func (m *MockIncidentReportRepository) GetReportCountsByState(arg0 string) ([]string, []int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReportCountsByState", arg0)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].([]int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetReportCountsByState indicates an expected call of GetReportCountsByState.
+func (mr *MockIncidentReportRepositoryMockRecorder) GetReportCountsByState(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReportCountsByState", reflect.TypeOf((*MockIncidentReportRepository)(nil).GetReportCountsByState), arg0)
+}
+
+// GetReportCountsByStateAndLGA mocks base method.
+func (m *MockIncidentReportRepository) GetReportCountsByStateAndLGA() ([]models.ReportCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReportCountsByStateAndLGA")
+	ret0, _ := ret[0].([]models.ReportCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReportCountsByStateAndLGA indicates an expected call of GetReportCountsByStateAndLGA.
+func (mr *MockIncidentReportRepositoryMockRecorder) GetReportCountsByStateAndLGA() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReportCountsByStateAndLGA", reflect.TypeOf((*MockIncidentReportRepository)(nil).GetReportCountsByStateAndLGA))
+}
+
+// GetReportIDByUser mocks base method.
+func (m *MockIncidentReportRepository) GetReportIDByUser(arg0 context.Context, arg1 uint) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReportIDByUser", arg0, arg1)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReportIDByUser indicates an expected call of GetReportIDByUser.
+func (mr *MockIncidentReportRepositoryMockRecorder) GetReportIDByUser(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReportIDByUser", reflect.TypeOf((*MockIncidentReportRepository)(nil).GetReportIDByUser), arg0, arg1)
+}
+
+// GetReportPercentageByState mocks base method.
+func (m *MockIncidentReportRepository) GetReportPercentageByState() ([]models.StateReportPercentage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReportPercentageByState")
+	ret0, _ := ret[0].([]models.StateReportPercentage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReportPercentageByState indicates an expected call of GetReportPercentageByState.
+func (mr *MockIncidentReportRepositoryMockRecorder) GetReportPercentageByState() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReportPercentageByState", reflect.TypeOf((*MockIncidentReportRepository)(nil).GetReportPercentageByState))
+}
+
+// GetReportRevisions mocks base method.
+func (m *MockIncidentReportRepository) GetReportRevisions(arg0 string) ([]models.ReportRevision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReportRevisions", arg0)
+	ret0, _ := ret[0].([]models.ReportRevision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReportRevisions indicates an expected call of GetReportRevisions.
+func (mr *MockIncidentReportRepositoryMockRecorder) GetReportRevisions(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReportRevisions", reflect.TypeOf((*MockIncidentReportRepository)(nil).GetReportRevisions), arg0)
+}
+
+// GetReportStatusByID mocks base method.
+func (m *MockIncidentReportRepository) GetReportStatusByID(arg0 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReportStatusByID", arg0)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReportStatusByID indicates an expected call of GetReportStatusByID.
+func (mr *MockIncidentReportRepositoryMockRecorder) GetReportStatusByID(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReportStatusByID", reflect.TypeOf((*MockIncidentReportRepository)(nil).GetReportStatusByID), arg0)
+}
+
+// GetReportTypeByCategory mocks base method.
+func (m *MockIncidentReportRepository) GetReportTypeByCategory(arg0 string) (*models.ReportType, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReportTypeByCategory", arg0)
+	ret0, _ := ret[0].(*models.ReportType)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReportTypeByCategory indicates an expected call of GetReportTypeByCategory.
+func (mr *MockIncidentReportRepositoryMockRecorder) GetReportTypeByCategory(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReportTypeByCategory", reflect.TypeOf((*MockIncidentReportRepository)(nil).GetReportTypeByCategory), arg0)
+}
+
+// GetReportTypeCounts mocks base method.
+func (m *MockIncidentReportRepository) GetReportTypeCounts(arg0, arg1 string, arg2, arg3 *string) ([]string, []int, int, int, []models.StateReportCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReportTypeCounts", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].([]int)
+	ret2, _ := ret[2].(int)
+	ret3, _ := ret[3].(int)
+	ret4, _ := ret[4].([]models.StateReportCount)
+	ret5, _ := ret[5].(error)
+	return ret0, ret1, ret2, ret3, ret4, ret5
+}
+
+// GetReportTypeCounts indicates an expected call of GetReportTypeCounts.
+func (mr *MockIncidentReportRepositoryMockRecorder) GetReportTypeCounts(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReportTypeCounts", reflect.TypeOf((*MockIncidentReportRepository)(nil).GetReportTypeCounts), arg0, arg1, arg2, arg3)
+}
+
+// GetReportTypeCountsByLGA mocks base method.
+func (m *MockIncidentReportRepository) GetReportTypeCountsByLGA(arg0 string) (map[string]any, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReportTypeCountsByLGA", arg0)
+	ret0, _ := ret[0].(map[string]any)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReportTypeCountsByLGA indicates an expected call of GetReportTypeCountsByLGA.
+func (mr *MockIncidentReportRepositoryMockRecorder) GetReportTypeCountsByLGA(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReportTypeCountsByLGA", reflect.TypeOf((*MockIncidentReportRepository)(nil).GetReportTypeCountsByLGA), arg0)
+}
+
+// GetReportTypeeByID mocks base method.
+func (m *MockIncidentReportRepository) GetReportTypeeByID(arg0 string) (*models.ReportType, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReportTypeeByID", arg0)
+	ret0, _ := ret[0].(*models.ReportType)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReportTypeeByID indicates an expected call of GetReportTypeeByID.
+func (mr *MockIncidentReportRepositoryMockRecorder) GetReportTypeeByID(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReportTypeeByID", reflect.TypeOf((*MockIncidentReportRepository)(nil).GetReportTypeeByID), arg0)
+}
+
+// GetReportsByCategory mocks base method.
+func (m *MockIncidentReportRepository) GetReportsByCategory(arg0 string) ([]models.ReportType, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReportsByCategory", arg0)
+	ret0, _ := ret[0].([]models.ReportType)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReportsByCategory indicates an expected call of GetReportsByCategory.
+func (mr *MockIncidentReportRepositoryMockRecorder) GetReportsByCategory(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReportsByCategory", reflect.TypeOf((*MockIncidentReportRepository)(nil).GetReportsByCategory), arg0)
+}
+
+// GetReportsByCategoryAndReportID mocks base method.
+func (m *MockIncidentReportRepository) GetReportsByCategoryAndReportID(arg0, arg1 string) ([]models.ReportType, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReportsByCategoryAndReportID", arg0, arg1)
+	ret0, _ := ret[0].([]models.ReportType)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReportsByCategoryAndReportID indicates an expected call of GetReportsByCategoryAndReportID.
+func (mr *MockIncidentReportRepositoryMockRecorder) GetReportsByCategoryAndReportID(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReportsByCategoryAndReportID", reflect.TypeOf((*MockIncidentReportRepository)(nil).GetReportsByCategoryAndReportID), arg0, arg1)
+}
+
+// GetReportsByIDs mocks base method.
+func (m *MockIncidentReportRepository) GetReportsByIDs(arg0 []string, arg1 ...db.PreloadOption) ([]models.IncidentReport, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0}
+	for _, a := range arg1 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetReportsByIDs", varargs...)
+	ret0, _ := ret[0].([]models.IncidentReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReportsByIDs indicates an expected call of GetReportsByIDs.
+func (mr *MockIncidentReportRepositoryMockRecorder) GetReportsByIDs(arg0 any, arg1 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0}, arg1...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReportsByIDs", reflect.TypeOf((*MockIncidentReportRepository)(nil).GetReportsByIDs), varargs...)
+}
+
+// GetReportsByTypeAndLGA mocks base method.
+func (m *MockIncidentReportRepository) GetReportsByTypeAndLGA(arg0, arg1 string) ([]models.SubReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReportsByTypeAndLGA", arg0, arg1)
+	ret0, _ := ret[0].([]models.SubReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReportsByTypeAndLGA indicates an expected call of GetReportsByTypeAndLGA.
+func (mr *MockIncidentReportRepositoryMockRecorder) GetReportsByTypeAndLGA(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReportsByTypeAndLGA", reflect.TypeOf((*MockIncidentReportRepository)(nil).GetReportsByTypeAndLGA), arg0, arg1)
+}
+
+// GetReportsByUserID mocks base method.
+func (m *MockIncidentReportRepository) GetReportsByUserID(arg0 uint) ([]models.ReportType, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReportsByUserID", arg0)
+	ret0, _ := ret[0].([]models.ReportType)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReportsByUserID indicates an expected call of GetReportsByUserID.
+func (mr *MockIncidentReportRepositoryMockRecorder) GetReportsByUserID(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReportsByUserID", reflect.TypeOf((*MockIncidentReportRepository)(nil).GetReportsByUserID), arg0)
+}
+
+// GetReportsPostedTodayCount mocks base method.
+func (m *MockIncidentReportRepository) GetReportsPostedTodayCount() (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetReportsPostedTodayCount")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReportsPostedTodayCount indicates an expected call of GetReportsPostedTodayCount.
+func (mr *MockIncidentReportRepositoryMockRecorder) GetReportsPostedTodayCount() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReportsPostedTodayCount", reflect.TypeOf((*MockIncidentReportRepository)(nil).GetReportsPostedTodayCount))
+}
+
+// GetReportsWithinRadius mocks base method.
+func (m *MockIncidentReportRepository) GetReportsWithinRadius(arg0, arg1, arg2 float64, arg3 int, arg4 ...db.PreloadOption) ([]models.IncidentReport, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1, arg2, arg3}
+	for _, a := range arg4 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "GetReportsWithinRadius", varargs...)
+	ret0, _ := ret[0].([]models.IncidentReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetReportsWithinRadius indicates an expected call of GetReportsWithinRadius.
+func (mr *MockIncidentReportRepositoryMockRecorder) GetReportsWithinRadius(arg0, arg1, arg2, arg3 any, arg4 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1, arg2, arg3}, arg4...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetReportsWithinRadius", reflect.TypeOf((*MockIncidentReportRepository)(nil).GetReportsWithinRadius), varargs...)
+}
+
+// GetStateReportCounts mocks base method.
+func (m *MockIncidentReportRepository) GetStateReportCounts() ([]models.StateReportCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStateReportCounts")
+	ret0, _ := ret[0].([]models.StateReportCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStateReportCounts indicates an expected call of GetStateReportCounts.
+func (mr *MockIncidentReportRepositoryMockRecorder) GetStateReportCounts() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStateReportCounts", reflect.TypeOf((*MockIncidentReportRepository)(nil).GetStateReportCounts))
+}
+
+// GetSubReportsByCategory mocks base method.
+func (m *MockIncidentReportRepository) GetSubReportsByCategory(arg0 string) ([]models.SubReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubReportsByCategory", arg0)
+	ret0, _ := ret[0].([]models.SubReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubReportsByCategory indicates an expected call of GetSubReportsByCategory.
+func (mr *MockIncidentReportRepositoryMockRecorder) GetSubReportsByCategory(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubReportsByCategory", reflect.TypeOf((*MockIncidentReportRepository)(nil).GetSubReportsByCategory), arg0)
+}
+
+// GetTopCategories mocks base method.
+func (m *MockIncidentReportRepository) GetTopCategories() ([]string, []int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTopCategories")
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].([]int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetTopCategories indicates an expected call of GetTopCategories.
+func (mr *MockIncidentReportRepositoryMockRecorder) GetTopCategories() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTopCategories", reflect.TypeOf((*MockIncidentReportRepository)(nil).GetTopCategories))
+}
+
+// GetTotalReportCount mocks base method.
+func (m *MockIncidentReportRepository) GetTotalReportCount() (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTotalReportCount")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTotalReportCount indicates an expected call of GetTotalReportCount.
+func (mr *MockIncidentReportRepositoryMockRecorder) GetTotalReportCount() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTotalReportCount", reflect.TypeOf((*MockIncidentReportRepository)(nil).GetTotalReportCount))
+}
+
+// GetTotalUserCount mocks base method.
+func (m *MockIncidentReportRepository) GetTotalUserCount() (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTotalUserCount")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTotalUserCount indicates an expected call of GetTotalUserCount.
+func (mr *MockIncidentReportRepositoryMockRecorder) GetTotalUserCount() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTotalUserCount", reflect.TypeOf((*MockIncidentReportRepository)(nil).GetTotalUserCount))
+}
+
+// GetVariadicStateReportCounts mocks base method.
+func (m *MockIncidentReportRepository) GetVariadicStateReportCounts(arg0, arg1 []string, arg2, arg3 *time.Time) ([]models.StateReportCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetVariadicStateReportCounts", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]models.StateReportCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetVariadicStateReportCounts indicates an expected call of GetVariadicStateReportCounts.
+func (mr *MockIncidentReportRepositoryMockRecorder) GetVariadicStateReportCounts(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetVariadicStateReportCounts", reflect.TypeOf((*MockIncidentReportRepository)(nil).GetVariadicStateReportCounts), arg0, arg1, arg2, arg3)
+}
+
+// HasPreviousReports mocks base method.
+func (m *MockIncidentReportRepository) HasPreviousReports(arg0 uint) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasPreviousReports", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasPreviousReports indicates an expected call of HasPreviousReports.
+func (mr *MockIncidentReportRepositoryMockRecorder) HasPreviousReports(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasPreviousReports", reflect.TypeOf((*MockIncidentReportRepository)(nil).HasPreviousReports), arg0)
+}
+
+// IncrementViewCount mocks base method.
+func (m *MockIncidentReportRepository) IncrementViewCount(arg0 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IncrementViewCount", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IncrementViewCount indicates an expected call of IncrementViewCount.
+func (mr *MockIncidentReportRepositoryMockRecorder) IncrementViewCount(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IncrementViewCount", reflect.TypeOf((*MockIncidentReportRepository)(nil).IncrementViewCount), arg0)
+}
+
+// IsBookmarked mocks base method.
+func (m *MockIncidentReportRepository) IsBookmarked(arg0 uint, arg1 uuid.UUID, arg2 *models.Bookmark) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsBookmarked", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// IsBookmarked indicates an expected call of IsBookmarked.
+func (mr *MockIncidentReportRepositoryMockRecorder) IsBookmarked(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsBookmarked", reflect.TypeOf((*MockIncidentReportRepository)(nil).IsBookmarked), arg0, arg1, arg2)
+}
+
+// ListAllStatesWithReportCounts mocks base method.
+func (m *MockIncidentReportRepository) ListAllStatesWithReportCounts() ([]models.StateReportCount, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAllStatesWithReportCounts")
+	ret0, _ := ret[0].([]models.StateReportCount)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAllStatesWithReportCounts indicates an expected call of ListAllStatesWithReportCounts.
+func (mr *MockIncidentReportRepositoryMockRecorder) ListAllStatesWithReportCounts() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAllStatesWithReportCounts", reflect.TypeOf((*MockIncidentReportRepository)(nil).ListAllStatesWithReportCounts))
+}
+
+// ListReports mocks base method.
+func (m *MockIncidentReportRepository) ListReports(arg0 db.ReportFilter, arg1 int, arg2 db.ReportSort, arg3 ...db.PreloadOption) ([]models.IncidentReport, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1, arg2}
+	for _, a := range arg3 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListReports", varargs...)
+	ret0, _ := ret[0].([]models.IncidentReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListReports indicates an expected call of ListReports.
+func (mr *MockIncidentReportRepositoryMockRecorder) ListReports(arg0, arg1, arg2 any, arg3 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1, arg2}, arg3...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListReports", reflect.TypeOf((*MockIncidentReportRepository)(nil).ListReports), varargs...)
+}
+
+// ListUserReports mocks base method.
+func (m *MockIncidentReportRepository) ListUserReports(arg0 uint, arg1 db.UserReportFilter, arg2 int, arg3 ...db.PreloadOption) ([]models.IncidentReport, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1, arg2}
+	for _, a := range arg3 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ListUserReports", varargs...)
+	ret0, _ := ret[0].([]models.IncidentReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUserReports indicates an expected call of ListUserReports.
+func (mr *MockIncidentReportRepositoryMockRecorder) ListUserReports(arg0, arg1, arg2 any, arg3 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1, arg2}, arg3...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUserReports", reflect.TypeOf((*MockIncidentReportRepository)(nil).ListUserReports), varargs...)
+}
+
+// ReportExists mocks base method.
+func (m *MockIncidentReportRepository) ReportExists(arg0 uuid.UUID) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReportExists", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReportExists indicates an expected call of ReportExists.
+func (mr *MockIncidentReportRepositoryMockRecorder) ReportExists(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReportExists", reflect.TypeOf((*MockIncidentReportRepository)(nil).ReportExists), arg0)
+}
+
+// Save mocks base method.
+func (m *MockIncidentReportRepository) Save(arg0 *models.IncidentReport) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockIncidentReportRepositoryMockRecorder) Save(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockIncidentReportRepository)(nil).Save), arg0)
+}
+
+// SaveBookmark mocks base method.
+func (m *MockIncidentReportRepository) SaveBookmark(arg0 *models.Bookmark, arg1 ...events.Event) (bool, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0}
+	for _, a := range arg1 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "SaveBookmark", varargs...)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveBookmark indicates an expected call of SaveBookmark.
+func (mr *MockIncidentReportRepositoryMockRecorder) SaveBookmark(arg0 any, arg1 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0}, arg1...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveBookmark", reflect.TypeOf((*MockIncidentReportRepository)(nil).SaveBookmark), varargs...)
+}
+
+// SaveIncidentReport mocks base method.
+func (m *MockIncidentReportRepository) SaveIncidentReport(arg0 *models.IncidentReport, arg1 *models.Reward, arg2 ...events.Event) (*models.IncidentReport, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "SaveIncidentReport", varargs...)
+	ret0, _ := ret[0].(*models.IncidentReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveIncidentReport indicates an expected call of SaveIncidentReport.
+func (mr *MockIncidentReportRepositoryMockRecorder) SaveIncidentReport(arg0, arg1 any, arg2 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveIncidentReport", reflect.TypeOf((*MockIncidentReportRepository)(nil).SaveIncidentReport), varargs...)
+}
+
+// SaveMedia mocks base method.
+func (m *MockIncidentReportRepository) SaveMedia(arg0 *models.Media) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveMedia", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveMedia indicates an expected call of SaveMedia.
+func (mr *MockIncidentReportRepositoryMockRecorder) SaveMedia(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveMedia", reflect.TypeOf((*MockIncidentReportRepository)(nil).SaveMedia), arg0)
+}
+
+// SaveReportType mocks base method.
+func (m *MockIncidentReportRepository) SaveReportType(arg0 *models.ReportType) (*models.ReportType, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveReportType", arg0)
+	ret0, _ := ret[0].(*models.ReportType)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveReportType indicates an expected call of SaveReportType.
+func (mr *MockIncidentReportRepositoryMockRecorder) SaveReportType(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveReportType", reflect.TypeOf((*MockIncidentReportRepository)(nil).SaveReportType), arg0)
+}
+
+// SaveStateLgaReportType mocks base method.
+func (m *MockIncidentReportRepository) SaveStateLgaReportType(arg0 *models.LGA, arg1 *models.State) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveStateLgaReportType", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveStateLgaReportType indicates an expected call of SaveStateLgaReportType.
+func (mr *MockIncidentReportRepositoryMockRecorder) SaveStateLgaReportType(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveStateLgaReportType", reflect.TypeOf((*MockIncidentReportRepository)(nil).SaveStateLgaReportType), arg0, arg1)
+}
+
+// SaveSubReport mocks base method.
+func (m *MockIncidentReportRepository) SaveSubReport(arg0 *models.SubReport) (*models.SubReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveSubReport", arg0)
+	ret0, _ := ret[0].(*models.SubReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SaveSubReport indicates an expected call of SaveSubReport.
+func (mr *MockIncidentReportRepositoryMockRecorder) SaveSubReport(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSubReport", reflect.TypeOf((*MockIncidentReportRepository)(nil).SaveSubReport), arg0)
+}
+
+// SearchReports mocks base method.
+func (m *MockIncidentReportRepository) SearchReports(arg0 string, arg1 db.ReportFilter, arg2 int, arg3 db.ReportSort, arg4 ...db.PreloadOption) ([]models.IncidentReport, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{arg0, arg1, arg2, arg3}
+	for _, a := range arg4 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "SearchReports", varargs...)
+	ret0, _ := ret[0].([]models.IncidentReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchReports indicates an expected call of SearchReports.
+func (mr *MockIncidentReportRepositoryMockRecorder) SearchReports(arg0, arg1, arg2, arg3 any, arg4 ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{arg0, arg1, arg2, arg3}, arg4...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchReports", reflect.TypeOf((*MockIncidentReportRepository)(nil).SearchReports), varargs...)
+}
+
+// SetOfficialResponse mocks base method.
+func (m *MockIncidentReportRepository) SetOfficialResponse(arg0, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetOfficialResponse", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetOfficialResponse indicates an expected call of SetOfficialResponse.
+func (mr *MockIncidentReportRepositoryMockRecorder) SetOfficialResponse(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetOfficialResponse", reflect.TypeOf((*MockIncidentReportRepository)(nil).SetOfficialResponse), arg0, arg1)
+}
+
+// UpdateIncidentReport mocks base method.
+func (m *MockIncidentReportRepository) UpdateIncidentReport(arg0 *models.IncidentReport) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateIncidentReport", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateIncidentReport indicates an expected call of UpdateIncidentReport.
+func (mr *MockIncidentReportRepositoryMockRecorder) UpdateIncidentReport(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateIncidentReport", reflect.TypeOf((*MockIncidentReportRepository)(nil).UpdateIncidentReport), arg0)
+}
+
+// UpdateReportTypeWithIncidentReport mocks base method.
+func (m *MockIncidentReportRepository) UpdateReportTypeWithIncidentReport(arg0 *models.IncidentReport) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateReportTypeWithIncidentReport", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateReportTypeWithIncidentReport indicates an expected call of UpdateReportTypeWithIncidentReport.
+func (mr *MockIncidentReportRepositoryMockRecorder) UpdateReportTypeWithIncidentReport(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateReportTypeWithIncidentReport", reflect.TypeOf((*MockIncidentReportRepository)(nil).UpdateReportTypeWithIncidentReport), arg0)
+}
+
+// UpdateReward mocks base method.
+func (m *MockIncidentReportRepository) UpdateReward(arg0 uint, arg1 *models.Reward) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateReward", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateReward indicates an expected call of UpdateReward.
+func (mr *MockIncidentReportRepositoryMockRecorder) UpdateReward(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateReward", reflect.TypeOf((*MockIncidentReportRepository)(nil).UpdateReward), arg0, arg1)
+}
+
+// UploadMediaToS3 mocks base method.
+func (m *MockIncidentReportRepository) UploadMediaToS3(arg0 multipart.File, arg1 *multipart.FileHeader, arg2, arg3 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UploadMediaToS3", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UploadMediaToS3 indicates an expected call of UploadMediaToS3.
+func (mr *MockIncidentReportRepositoryMockRecorder) UploadMediaToS3(arg0, arg1, arg2, arg3 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadMediaToS3", reflect.TypeOf((*MockIncidentReportRepository)(nil).UploadMediaToS3), arg0, arg1, arg2, arg3)
+}
+
+// UserRestrictedUntil mocks base method.
+func (m *MockIncidentReportRepository) UserRestrictedUntil(arg0 uint) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserRestrictedUntil", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserRestrictedUntil indicates an expected call of UserRestrictedUntil.
+func (mr *MockIncidentReportRepositoryMockRecorder) UserRestrictedUntil(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserRestrictedUntil", reflect.TypeOf((*MockIncidentReportRepository)(nil).UserRestrictedUntil), arg0)
+}
+
+// WithdrawReport mocks base method.
+func (m *MockIncidentReportRepository) WithdrawReport(arg0 uuid.UUID, arg1 uint, arg2 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithdrawReport", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WithdrawReport indicates an expected call of WithdrawReport.
+func (mr *MockIncidentReportRepositoryMockRecorder) WithdrawReport(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithdrawReport", reflect.TypeOf((*MockIncidentReportRepository)(nil).WithdrawReport), arg0, arg1, arg2)
+}
@@ -0,0 +1,98 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/techagentng/citizenx/services (interfaces: ReportCapService)
+//
+// Generated by this command:
+//
+//	mockgen -destination=../mocks/report_cap_mock.go -package=mocks github.com/techagentng/citizenx/services ReportCapService
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+	time "time"
+
+	models "github.com/techagentng/citizenx/models"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockReportCapService is a mock of ReportCapService interface.
+type MockReportCapService struct {
+	ctrl     *gomock.Controller
+	recorder *MockReportCapServiceMockRecorder
+}
+
+// MockReportCapServiceMockRecorder is the mock recorder for MockReportCapService.
+type MockReportCapServiceMockRecorder struct {
+	mock *MockReportCapService
+}
+
+// NewMockReportCapService creates a new mock instance.
+func NewMockReportCapService(ctrl *gomock.Controller) *MockReportCapService {
+	mock := &MockReportCapService{ctrl: ctrl}
+	mock.recorder = &MockReportCapServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReportCapService) EXPECT() *MockReportCapServiceMockRecorder {
+	return m.recorder
+}
+
+// Check mocks base method.
+func (m *MockReportCapService) Check(arg0 uint, arg1 string, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Check", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Check indicates an expected call of Check.
+func (mr *MockReportCapServiceMockRecorder) Check(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockReportCapService)(nil).Check), arg0, arg1, arg2)
+}
+
+// Exempt mocks base method.
+func (m *MockReportCapService) Exempt(arg0 *models.ReportCapExemption, arg1 uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Exempt", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Exempt indicates an expected call of Exempt.
+func (mr *MockReportCapServiceMockRecorder) Exempt(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Exempt", reflect.TypeOf((*MockReportCapService)(nil).Exempt), arg0, arg1)
+}
+
+// ListExemptions mocks base method.
+func (m *MockReportCapService) ListExemptions() ([]models.ReportCapExemption, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExemptions")
+	ret0, _ := ret[0].([]models.ReportCapExemption)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListExemptions indicates an expected call of ListExemptions.
+func (mr *MockReportCapServiceMockRecorder) ListExemptions() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExemptions", reflect.TypeOf((*MockReportCapService)(nil).ListExemptions))
+}
+
+// RemoveExemption mocks base method.
+func (m *MockReportCapService) RemoveExemption(arg0 uint) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveExemption", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveExemption indicates an expected call of RemoveExemption.
+func (mr *MockReportCapServiceMockRecorder) RemoveExemption(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveExemption", reflect.TypeOf((*MockReportCapService)(nil).RemoveExemption), arg0)
+}
@@ -0,0 +1,70 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/techagentng/citizenx/services (interfaces: ReportExportService)
+//
+// Generated by this command:
+//
+//	mockgen -destination=../mocks/report_export_mock.go -package=mocks github.com/techagentng/citizenx/services ReportExportService
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	io "io"
+	reflect "reflect"
+
+	db "github.com/techagentng/citizenx/db"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockReportExportService is a mock of ReportExportService interface.
+type MockReportExportService struct {
+	ctrl     *gomock.Controller
+	recorder *MockReportExportServiceMockRecorder
+}
+
+// MockReportExportServiceMockRecorder is the mock recorder for MockReportExportService.
+type MockReportExportServiceMockRecorder struct {
+	mock *MockReportExportService
+}
+
+// NewMockReportExportService creates a new mock instance.
+func NewMockReportExportService(ctrl *gomock.Controller) *MockReportExportService {
+	mock := &MockReportExportService{ctrl: ctrl}
+	mock.recorder = &MockReportExportServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReportExportService) EXPECT() *MockReportExportServiceMockRecorder {
+	return m.recorder
+}
+
+// ContentType mocks base method.
+func (m *MockReportExportService) ContentType(arg0 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ContentType", arg0)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ContentType indicates an expected call of ContentType.
+func (mr *MockReportExportServiceMockRecorder) ContentType(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ContentType", reflect.TypeOf((*MockReportExportService)(nil).ContentType), arg0)
+}
+
+// Export mocks base method.
+func (m *MockReportExportService) Export(arg0 io.Writer, arg1 string, arg2 db.ReportFilter) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Export", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Export indicates an expected call of Export.
+func (mr *MockReportExportServiceMockRecorder) Export(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Export", reflect.TypeOf((*MockReportExportService)(nil).Export), arg0, arg1, arg2)
+}
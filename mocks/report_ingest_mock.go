@@ -0,0 +1,83 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: github.com/techagentng/citizenx/services (interfaces: ReportIngestService)
+//
+// Generated by this command:
+//
+//	mockgen -destination=../mocks/report_ingest_mock.go -package=mocks github.com/techagentng/citizenx/services ReportIngestService
+//
+
+// Package mocks is a generated GoMock package.
+package mocks
+
+import (
+	reflect "reflect"
+
+	jobs "github.com/techagentng/citizenx/jobs"
+	models "github.com/techagentng/citizenx/models"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockReportIngestService is a mock of ReportIngestService interface.
+type MockReportIngestService struct {
+	ctrl     *gomock.Controller
+	recorder *MockReportIngestServiceMockRecorder
+}
+
+// MockReportIngestServiceMockRecorder is the mock recorder for MockReportIngestService.
+type MockReportIngestServiceMockRecorder struct {
+	mock *MockReportIngestService
+}
+
+// NewMockReportIngestService creates a new mock instance.
+func NewMockReportIngestService(ctrl *gomock.Controller) *MockReportIngestService {
+	mock := &MockReportIngestService{ctrl: ctrl}
+	mock.recorder = &MockReportIngestServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockReportIngestService) EXPECT() *MockReportIngestServiceMockRecorder {
+	return m.recorder
+}
+
+// GetSubmission mocks base method.
+func (m *MockReportIngestService) GetSubmission(arg0 string, arg1 uint) (*models.ReportSubmission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetSubmission", arg0, arg1)
+	ret0, _ := ret[0].(*models.ReportSubmission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSubmission indicates an expected call of GetSubmission.
+func (mr *MockReportIngestServiceMockRecorder) GetSubmission(arg0, arg1 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubmission", reflect.TypeOf((*MockReportIngestService)(nil).GetSubmission), arg0, arg1)
+}
+
+// Queue mocks base method.
+func (m *MockReportIngestService) Queue(arg0 uint, arg1 *models.IncidentReport, arg2 string) (*models.ReportSubmission, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Queue", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.ReportSubmission)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Queue indicates an expected call of Queue.
+func (mr *MockReportIngestServiceMockRecorder) Queue(arg0, arg1, arg2 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Queue", reflect.TypeOf((*MockReportIngestService)(nil).Queue), arg0, arg1, arg2)
+}
+
+// RegisterJobs mocks base method.
+func (m *MockReportIngestService) RegisterJobs(arg0 *jobs.Worker) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RegisterJobs", arg0)
+}
+
+// RegisterJobs indicates an expected call of RegisterJobs.
+func (mr *MockReportIngestServiceMockRecorder) RegisterJobs(arg0 any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterJobs", reflect.TypeOf((*MockReportIngestService)(nil).RegisterJobs), arg0)
+}
@@ -7,23 +7,32 @@ import (
 )
 type IncidentReport struct {
 	ID                   uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"` // Update to UUID type
-	CreatedAt            int64      `json:"created_at"`
+	CreatedAt            int64      `json:"created_at" gorm:"index"`
+	UpdatedAt            int64      `json:"updated_at" gorm:"autoUpdateTime;index"` // last change to the report itself; vote and view counts do not move it
 	UserFullname         string     `json:"fullname"`
 	DateOfIncidence      string     `json:"date_of_incidence"`
 	Description          string     `json:"description" gorm:"type:varchar(1000)"`
+	DescriptionRaw       string     `json:"-" gorm:"type:varchar(1000)"` // unfiltered text, shown to moderators only
 	FeedURLs             string     `json:"feed_urls"`
 	ThumbnailURLs        string     `json:"thumbnail_urls"`
 	FullSizeURLs         string     `json:"full_size_urls"`
 	ProductName          string     `json:"product_name"`
-	StateName            string     `json:"state_name"`
-	LGAName              string     `json:"lga_name"`
-	Latitude             float64    `json:"latitude"`
-	Longitude            float64    `json:"longitude"`
+	StateName            string     `json:"state_name" gorm:"index"`
+	LGAName              string     `json:"lga_name" gorm:"index"`
+	WardName             string     `json:"ward_name" gorm:"index"`
+	Latitude             float64    `json:"latitude" gorm:"index:idx_incident_reports_location,priority:1"`
+	Longitude            float64    `json:"longitude" gorm:"index:idx_incident_reports_location,priority:2"`
+	LocationCoarsened    bool       `json:"location_coarsened" gorm:"not null;default:false"`
+	PlusCode             string     `json:"plus_code" gorm:"index"` // Open Location Code of the coordinates
+	Route                string     `json:"route,omitempty" gorm:"type:text"` // encoded polyline of the stretch a road report describes
+	RouteLengthM         int        `json:"route_length_m,omitempty"`
+	LocationApproximate  bool       `json:"location_approximate"` // placed from the client's IP address, not GPS
+	NetworkFlag          string     `json:"-" gorm:"index"`           // NetworkVPN or NetworkHosting when submitted through one
 	UserIsAnonymous      bool       `json:"user_is_anonymous"`
 	Address              string     `json:"address"`
 	UserUsername         string     `json:"username"`
-	Telephone            string     `json:"telephone"`
-	Email                string     `json:"email"`
+	Telephone            string     `json:"telephone" gorm:"serializer:encrypted"` // reporter contact, kept encrypted
+	Email                string     `json:"email" gorm:"serializer:encrypted"`
 	View                 int        `json:"view"`
 	IsVerified           bool       `json:"is_verified"`
 	UserID               uint       `json:"user_id"`
@@ -32,8 +41,15 @@ type IncidentReport struct {
 	LikeCount            int        `json:"like_count"`
 	BookmarkedReports    []*User    `gorm:"many2many:incident_report_user;" json:"bookmarked_reports"`
 	IsResponse           bool       `json:"is_response"`
-	TimeofIncidence      time.Time  `json:"time_of_incidence"`
-	ReportStatus         string     `json:"report_status"`
+	TimeofIncidence      time.Time  `json:"time_of_incidence" gorm:"index"`
+	ReportStatus         string     `json:"report_status" gorm:"index"`
+	StatusUpdatedAt      int64      `json:"status_updated_at"`
+	WithdrawnReason      string     `json:"withdrawn_reason,omitempty"` // why the reporter retracted the report
+	EditedAt             int64      `json:"edited_at,omitempty"` // when the reporter last edited the report; its earlier versions are ReportRevisions
+	AutoPublished        bool       `json:"auto_published"` // published on the reporter's reputation, without review
+	IncidentID           *uuid.UUID `json:"incident_id,omitempty" gorm:"type:uuid;index"`
+	ClusterID            *uuid.UUID `json:"cluster_id,omitempty" gorm:"type:uuid;index"` // the cluster of likely duplicates it belongs to
+	PossibleDuplicate    bool       `json:"possible_duplicate" gorm:"not null;default:false;index"` // looks like an earlier report
 	RewardPoint          int        `json:"reward_point"`
 	RewardAccountNumber  string     `json:"reward_account_number"`
 	ActionTypeName       string     `json:"action_type_name"`
@@ -54,14 +70,44 @@ type IncidentReport struct {
 	HospitalAddress      string     `json:"hospital_address"`
 	RoadName             string     `json:"road_name"`
 	AirlineName          string     `json:"airline_name"`
-	Category             string     `json:"category"`
+	Category             string     `json:"category" gorm:"index"`
 	Terminal             string     `json:"terminal"`
 	QueueTime            string     `json:"queue_time"`
-	SubReportType        string     `json:"sub_report_type"`
+	SubReportType        string     `json:"sub_report_type" gorm:"index"`
 	UpvoteCount          int        `json:"upvote_count" gorm:"default:0"`
 	DownvoteCount        int        `json:"downvote_count" gorm:"default:0"`
+	CredibilityScore     int        `json:"credibility_score" gorm:"not null;default:0"` // 0 until the report is first scored
+	OfficialResponse     string     `json:"official_response" gorm:"type:text"`
+	OfficialResponseAt   int64      `json:"official_response_at"`
+	AgencyID             *uint      `json:"agency_id" gorm:"index"`
+	AssignedAt           int64      `json:"assigned_at,omitempty"`
+	AcknowledgedAt       int64      `json:"acknowledged_at,omitempty"`
+	ResolvedAt           int64      `json:"resolved_at,omitempty"`
+	ResolutionConfirmed  *bool      `json:"resolution_confirmed"` // the reporter's verdict on the agency's resolution
+	ResolutionEvidence   []string   `json:"resolution_evidence,omitempty" gorm:"type:text;serializer:json"` // links to the photos or documents the agency resolved it with
 	ReportTypeID      uuid.UUID   `json:"report_type_id" gorm:"not null"` 
 	ReportType        ReportType  `gorm:"foreignKey:ReportTypeID;constraint:OnUpdate:CASCADE,OnDelete:SET NULL"` 
+	Media             []Media         `json:"media,omitempty" gorm:"-"`
+	Points            []ReportPoint   `json:"points,omitempty" gorm:"-"`
+	Classification    *ReportType     `json:"-" gorm:"-"` // the report type and its sub reports, saved along with a new report
+	Reporter          *ReportReporter `json:"reporter,omitempty" gorm:"-"`
+	Counts            *ReportCounts   `json:"counts,omitempty" gorm:"-"`
+}
+
+// ReportReporter is the public profile of the user who filed a report
+type ReportReporter struct {
+	ID           uint   `json:"id"`
+	Fullname     string `json:"fullname"`
+	Username     string `json:"username"`
+	ProfileImage string `json:"profile_image"`
+}
+
+// ReportCounts holds the association counts shown on a report card
+type ReportCounts struct {
+	Media     int64 `json:"media"`
+	Bookmarks int64 `json:"bookmarks"`
+	Votes     int64 `json:"votes"`
+	Comments  int64 `json:"comments"`
 }
 type ReportCount struct {
 	StateName string
@@ -73,8 +119,8 @@ type ReportCount struct {
 
 type IncidentReportUser struct {
 	ID               uint      `gorm:"primaryKey;autoIncrement"`
-	UserID           uint      `gorm:"not null"`
-	IncidentReportID string    `gorm:"not null;type:varchar(36);index"`
+	UserID           uint      `gorm:"not null;uniqueIndex:idx_incident_report_users_user_report,priority:1"`
+	IncidentReportID string    `gorm:"not null;type:varchar(36);index;uniqueIndex:idx_incident_report_users_user_report,priority:2"`
 	CreatedAt        time.Time `gorm:"autoCreateTime"`
 	UpdatedAt        time.Time `gorm:"autoUpdateTime"`
 	User             User      `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE"`
@@ -106,3 +152,14 @@ type IncidentReportResponseData struct {
 	Landmark        string `json:"landmark"`
 	ImageURL        string `json:"image_url"`
 }
+
+// Networks a report may be flagged as submitted through, for closer
+// moderation
+const (
+	NetworkVPN     = "vpn"
+	NetworkHosting = "hosting"
+)
+
+// ReportStatusWithdrawn is the status of reports their reporter retracted
+// before moderation
+const ReportStatusWithdrawn = "withdrawn"
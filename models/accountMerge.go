@@ -0,0 +1,46 @@
+package models
+
+// Signals that two accounts may belong to the same person
+const (
+	DuplicateByTelephone = "telephone"
+	DuplicateByDevice    = "device"
+	DuplicateByEmail     = "email"
+)
+
+// DuplicateUser is one account of a duplicate group
+type DuplicateUser struct {
+	ID        uint   `json:"id"`
+	Fullname  string `json:"fullname"`
+	Username  string `json:"username"`
+	Email     string `json:"email"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// DuplicateGroup lists accounts sharing a phone number, a device or an
+// email address once aliases are taken away
+type DuplicateGroup struct {
+	Signal string          `json:"signal"`
+	Users  []DuplicateUser `json:"users"`
+}
+
+// AccountMergeRequest folds the source account into the target
+type AccountMergeRequest struct {
+	SourceID uint `json:"source_id" binding:"required"`
+	TargetID uint `json:"target_id" binding:"required"`
+}
+
+// AccountMerge records an admin folding one account into another: what was
+// moved to the target and what became of the source, which is closed
+type AccountMerge struct {
+	ID        uint  `gorm:"primaryKey" json:"id"`
+	SourceID  uint  `gorm:"not null;index" json:"source_id"`
+	TargetID  uint  `gorm:"not null;index" json:"target_id"`
+	MergedBy  uint  `gorm:"not null" json:"merged_by"`
+	Reports   int64 `gorm:"not null;default:0" json:"reports"`
+	Rewards   int64 `gorm:"not null;default:0" json:"rewards"`
+	Bookmarks int64 `gorm:"not null;default:0" json:"bookmarks"`
+	// MovedTelephone is set when the target had no phone number and took
+	// the source's
+	MovedTelephone bool  `gorm:"not null;default:false" json:"moved_telephone"`
+	CreatedAt      int64 `gorm:"index" json:"created_at"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Activity event types
+const (
+	ActivityLogin           = "login"
+	ActivityReportSubmitted = "report_submitted"
+	ActivityReportViewed    = "report_viewed"
+	ActivityComment         = "comment"
+)
+
+// ActivityEvent records a key action taken by a user, the raw data behind
+// the engagement and retention analytics.
+type ActivityEvent struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	UserID     uint      `gorm:"not null;index" json:"user_id"`
+	EventType  string    `gorm:"not null;index" json:"event_type"`
+	Metadata   string    `gorm:"type:jsonb;not null;default:'{}'" json:"metadata"`
+	OccurredAt time.Time `gorm:"not null;index" json:"occurred_at"`
+	// DedupKey is set for activity derived from a domain event, so the event
+	// being delivered twice records it once
+	DedupKey *string `gorm:"uniqueIndex" json:"-"`
+}
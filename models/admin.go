@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// AdminOverview is the admin dashboard's summary of today's operations
+type AdminOverview struct {
+	// ReportsToday counts the reports submitted since midnight by status
+	ReportsToday      map[string]int64 `json:"reports_today"`
+	PendingModeration int64            `json:"pending_moderation"`
+	// OldestPendingAt and OldestPendingAgeSeconds describe the report that
+	// has waited longest for moderation; both are empty when none is pending
+	OldestPendingAt         *time.Time `json:"oldest_pending_at"`
+	OldestPendingAgeSeconds int64      `json:"oldest_pending_age_seconds"`
+	ActiveUsers             int64      `json:"active_users"`
+	WebhookFailures         int64      `json:"webhook_failures"`
+	JobQueueDepth           int64      `json:"job_queue_depth"`
+	// RewardLiability is the total of unredeemed reward points
+	RewardLiability int64     `json:"reward_liability"`
+	GeneratedAt     time.Time `json:"generated_at"`
+}
+
+// ModeratedText is user written text in its public, masked form alongside
+// the original, for moderators
+type ModeratedText struct {
+	ID     string `json:"id"`
+	Public string `json:"public"`
+	Raw    string `json:"raw"`
+}
@@ -0,0 +1,73 @@
+package models
+
+import "time"
+
+// Agency is a government body or utility that responds to reports.
+// Reports are assigned to it through IncidentReport.AgencyID and its staff
+// are users with AgencyID set.
+type Agency struct {
+	ID uint `gorm:"primaryKey" json:"id"`
+	PublicRef
+	Name        string `gorm:"not null;unique" json:"name" binding:"required"`
+	Description string `gorm:"type:text" json:"description"`
+	Website     string `json:"website"`
+	CreatedAt   int64  `json:"created_at"`
+}
+
+// AgencyScorecard measures how an agency handled the reports assigned to
+// it over a period. Times run from assignment; medians are null when there
+// is nothing to measure.
+type AgencyScorecard struct {
+	AgencyID                 uint     `gorm:"not null;uniqueIndex:idx_agency_scorecard_month,priority:1" json:"agency_id"`
+	AgencyName               string   `json:"agency_name"`
+	Assigned                 int64    `json:"assigned"`
+	Acknowledged             int64    `json:"acknowledged"`
+	MedianAcknowledgeSeconds *float64 `json:"median_acknowledge_seconds"`
+	Resolved                 int64    `json:"resolved"`
+	MedianResolutionSeconds  *float64 `json:"median_resolution_seconds"`
+	// Confirmed and Disputed count resolutions the reporter agreed with or
+	// rejected; ConfirmedResolutionRate is Confirmed over Resolved
+	Confirmed               int64    `json:"confirmed"`
+	Disputed                int64    `json:"disputed"`
+	ConfirmedResolutionRate *float64 `json:"confirmed_resolution_rate"`
+}
+
+// AgencyScorecardSnapshot freezes an agency's scorecard for the reports
+// assigned in one calendar month
+type AgencyScorecardSnapshot struct {
+	ID              uint      `gorm:"primaryKey" json:"-"`
+	Month           time.Time `gorm:"type:date;not null;uniqueIndex:idx_agency_scorecard_month,priority:2" json:"month"`
+	AgencyScorecard `gorm:"embedded"`
+	TakenAt         int64 `json:"taken_at"`
+}
+
+// AgencyScorecardHistory is an agency's current scorecard with its monthly
+// snapshots, most recent first
+type AgencyScorecardHistory struct {
+	Current AgencyScorecard           `json:"current"`
+	Monthly []AgencyScorecardSnapshot `json:"monthly"`
+}
+
+// The statuses an agency can set on the reports assigned to it
+const (
+	AgencyStatusAcknowledged = "acknowledged"
+	AgencyStatusResolved     = "resolved"
+)
+
+// AgencyStatusUpdate sets the same status on a batch of reports, as an
+// agency's own ticketing system sends them. Evidence is links to photos or
+// documents backing a resolution.
+type AgencyStatusUpdate struct {
+	ReportIDs    []string `json:"report_ids" binding:"required"`
+	Status       string   `json:"status" binding:"required"`
+	Note         string   `json:"note" binding:"max=2000"`
+	EvidenceURLs []string `json:"evidence_urls"`
+}
+
+// AgencyStatusResult is the outcome of one report of an AgencyStatusUpdate.
+// Error says why the report was left as it was.
+type AgencyStatusResult struct {
+	ReportID string `json:"report_id"`
+	Updated  bool   `json:"updated"`
+	Error    string `json:"error,omitempty"`
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Ambassadors are volunteers who look after the reports of one LGA, named
+// by User.AmbassadorLGA. They can verify low-risk reports there without a
+// moderator.
+
+// AmbassadorDashboard summarises an ambassador's LGA. New reports are those
+// of the last seven days and active reporters those who reported in the
+// last thirty.
+type AmbassadorDashboard struct {
+	LGA             string                  `json:"lga"`
+	NewReports      int64                   `json:"new_reports"`
+	Unresolved      int64                   `json:"unresolved"`
+	ActiveReporters int64                   `json:"active_reporters"`
+	ToVerify        []AmbassadorReportBrief `json:"to_verify"`
+}
+
+// AmbassadorReportBrief is a report awaiting verification on the dashboard
+type AmbassadorReportBrief struct {
+	ID          string `json:"id"`
+	Category    string `json:"category"`
+	Description string `json:"description"`
+	CreatedAt   int64  `json:"created_at"`
+}
+
+// AmbassadorSummary records an ambassador's month for the ambassador
+// programme: activity in their LGA and the reports they verified
+type AmbassadorSummary struct {
+	ID              uint      `gorm:"primaryKey" json:"-"`
+	UserID          uint      `gorm:"not null;uniqueIndex:idx_ambassador_summary_month,priority:1" json:"user_id"`
+	LGA             string    `gorm:"not null" json:"lga"`
+	Month           time.Time `gorm:"type:date;not null;uniqueIndex:idx_ambassador_summary_month,priority:2;index" json:"month"`
+	NewReports      int64     `json:"new_reports"`
+	Resolved        int64     `json:"resolved"`
+	ActiveReporters int64     `json:"active_reporters"`
+	Verified        int64     `json:"verified"`
+	TakenAt         int64     `json:"taken_at"`
+}
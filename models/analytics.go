@@ -0,0 +1,93 @@
+package models
+
+import "time"
+
+// GroupCount is the number of reports in one group of an aggregation
+type GroupCount struct {
+	Group string `json:"group"`
+	Count int64  `json:"count"`
+}
+
+// TimeseriesPoint is the number of reports in one group during one period
+type TimeseriesPoint struct {
+	Period time.Time `json:"period"`
+	Group  string    `json:"group"`
+	Count  int64     `json:"count"`
+	// Events names the calendar events that fall in the period
+	Events []string `json:"events,omitempty"`
+	// Expected is the count the group's recent periods and the calendar
+	// predict, once there are enough earlier periods to go by; Anomaly is
+	// set when Count is well above it
+	Expected float64 `json:"expected,omitempty"`
+	Anomaly  bool    `json:"anomaly,omitempty"`
+}
+
+// PeriodCount is a count for one period of a daily, weekly or monthly series
+type PeriodCount struct {
+	Period time.Time `json:"period"`
+	Count  int64     `json:"count"`
+}
+
+// ActiveUsers counts distinct active users over the day, week and month
+// ending on AsOf
+type ActiveUsers struct {
+	AsOf time.Time `json:"as_of"`
+	DAU  int64     `json:"dau"`
+	WAU  int64     `json:"wau"`
+	MAU  int64     `json:"mau"`
+	// Stickiness is DAU/MAU, the share of monthly users active on the day
+	Stickiness float64 `json:"stickiness"`
+}
+
+// RetentionCohort is one row of a cohort retention table: the users who
+// signed up in Cohort and how many were active Retained[n] periods later
+type RetentionCohort struct {
+	Cohort   time.Time `json:"cohort"`
+	Size     int64     `json:"size"`
+	Retained []int64   `json:"retained"`
+	Rates    []float64 `json:"rates"`
+}
+
+// Snapshot scopes, the groupings aggregates are recorded under
+const (
+	SnapshotNational = "national"
+	SnapshotState    = "state"
+	SnapshotCategory = "category"
+)
+
+// AggregateSnapshot records the report totals of one group as they stood
+// when the nightly snapshot for SnapshotDate was taken, so past dashboard
+// figures can be reproduced after the underlying reports change. The
+// national scope has a single row with an empty GroupName.
+type AggregateSnapshot struct {
+	ID           uint      `gorm:"primaryKey" json:"-"`
+	SnapshotDate time.Time `gorm:"type:date;not null;uniqueIndex:idx_aggregate_snapshots_group,priority:1" json:"snapshot_date"`
+	Scope        string    `gorm:"not null;uniqueIndex:idx_aggregate_snapshots_group,priority:2" json:"scope"`
+	GroupName    string    `gorm:"not null;uniqueIndex:idx_aggregate_snapshots_group,priority:3" json:"group"`
+	Reports      int64     `json:"reports"`
+	Pending      int64     `json:"pending"`
+	Verified     int64     `json:"verified"`
+	Rejected     int64     `json:"rejected"`
+	Closed       int64     `json:"closed"`
+	// ResolutionRate is the share of reports no longer pending
+	ResolutionRate float64   `json:"resolution_rate"`
+	TakenAt        time.Time `json:"taken_at"`
+}
+
+// SnapshotChange compares one group's snapshot on two dates
+type SnapshotChange struct {
+	Group                string             `json:"group"`
+	Current              *AggregateSnapshot `json:"current"`
+	Previous             *AggregateSnapshot `json:"previous"`
+	ReportsChange        int64              `json:"reports_change"`
+	ResolutionRateChange float64            `json:"resolution_rate_change"`
+}
+
+// SnapshotComparison sets the snapshots of a scope on two dates side by
+// side, for period over period reporting
+type SnapshotComparison struct {
+	Scope        string           `json:"scope"`
+	Date         time.Time        `json:"date"`
+	PreviousDate time.Time        `json:"previous_date"`
+	Changes      []SnapshotChange `json:"changes"`
+}
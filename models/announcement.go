@@ -0,0 +1,45 @@
+package models
+
+// Announcement statuses
+const (
+	AnnouncementScheduled = "scheduled"
+	AnnouncementSending   = "sending"
+	AnnouncementSent      = "sent"
+)
+
+// AnnouncementSegment selects the users an announcement goes to. Empty
+// fields do not restrict the audience.
+type AnnouncementSegment struct {
+	StateName string `json:"state_name,omitempty"`
+	LGAName   string `json:"lga_name,omitempty"`
+	Role      string `json:"role,omitempty"`
+	// InactiveDays limits the audience to users with no recorded activity
+	// in that many days
+	InactiveDays int `json:"inactive_days,omitempty"`
+}
+
+// Announcement is a message from the admins broadcast to a segment of users
+// through the notification pipeline
+type Announcement struct {
+	ID          uint                `gorm:"primaryKey" json:"id"`
+	Title       string              `gorm:"not null" json:"title" binding:"required"`
+	Message     string              `gorm:"type:text;not null" json:"message" binding:"required"`
+	Segment     AnnouncementSegment `gorm:"embedded;embeddedPrefix:segment_" json:"segment"`
+	CreatedBy   uint                `json:"created_by"`
+	Status      string              `gorm:"not null;index" json:"status"`
+	ScheduledAt int64               `gorm:"index" json:"scheduled_at"`
+	SentAt      int64               `json:"sent_at,omitempty"`
+	Recipients  int64               `json:"recipients"`
+	CreatedAt   int64               `json:"created_at"`
+	UpdatedAt   int64               `json:"updated_at"`
+}
+
+// AnnouncementStats reports how far an announcement reached
+type AnnouncementStats struct {
+	AnnouncementID uint    `json:"announcement_id"`
+	Status         string  `json:"status"`
+	Recipients     int64   `json:"recipients"`
+	Delivered      int64   `json:"delivered"`
+	Opened         int64   `json:"opened"`
+	OpenRate       float64 `json:"open_rate"`
+}
@@ -0,0 +1,26 @@
+package models
+
+// Actions recorded in the audit log
+const (
+	AuditReportAutoClosed    = "report.auto_closed"
+	AuditPrivilegesSuspended = "reporter.privileges_suspended"
+	AuditReportWithdrawn     = "report.withdrawn"
+	AuditDisplayNameOverride = "user.display_name_override"
+	AuditAccountsMerged      = "user.accounts_merged"
+	AuditReportTransferred   = "report.transferred"
+	AuditReportStatusChanged = "report.status_changed"
+)
+
+// AuditEntry records a change made to a record, by an admin or by the
+// system itself, for later review
+type AuditEntry struct {
+	ID uint `gorm:"primaryKey" json:"id"`
+	// ActorID is the admin, or the user acting on their own record, who
+	// made the change, or nil when the system made it on its own
+	ActorID    *uint  `gorm:"index" json:"actor_id"`
+	Action     string `gorm:"not null;index" json:"action"`
+	TargetType string `gorm:"not null;index:idx_audit_entries_target,priority:1" json:"target_type"`
+	TargetID   string `gorm:"not null;index:idx_audit_entries_target,priority:2" json:"target_id"`
+	Details    string `gorm:"type:jsonb;not null;default:'{}'" json:"details"`
+	CreatedAt  int64  `gorm:"index" json:"created_at"`
+}
@@ -0,0 +1,18 @@
+package models
+
+// ReportStatusClosed is the status given to reports closed for going stale
+const ReportStatusClosed = "closed"
+
+// AutoCloseRule closes reports that have sat in one status for too long. A
+// rule with an empty Category applies to every category that has no rule of
+// its own for the same status, so disabling a category's rule exempts it.
+type AutoCloseRule struct {
+	ID        uint   `gorm:"primaryKey" json:"id"`
+	Category  string `gorm:"not null;default:'';uniqueIndex:idx_auto_close_rules_scope,priority:1" json:"category"`
+	Status    string `gorm:"not null;uniqueIndex:idx_auto_close_rules_scope,priority:2" json:"status" binding:"required"`
+	AfterDays int    `gorm:"not null" json:"after_days" binding:"required,min=1"`
+	Enabled   bool   `gorm:"not null;default:true" json:"enabled"`
+	CreatedBy uint   `json:"created_by"`
+	CreatedAt int64  `json:"created_at"`
+	UpdatedAt int64  `json:"updated_at"`
+}
@@ -5,9 +5,10 @@ import (
 	"github.com/google/uuid"
 )
 
+// Bookmark saves a report to a user's list, once per user and report
 type Bookmark struct {
 	ID        uint   `gorm:"primaryKey"`
-	UserID    uint   `gorm:"not null"`
-	ReportID  uuid.UUID `gorm:"not null"`
+	UserID    uint   `gorm:"not null;uniqueIndex:idx_bookmarks_user_report,priority:1"`
+	ReportID  uuid.UUID `gorm:"not null;uniqueIndex:idx_bookmarks_user_report,priority:2"`
 	CreatedAt time.Time
 }
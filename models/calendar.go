@@ -0,0 +1,45 @@
+package models
+
+// The kinds of calendar event
+const (
+	CalendarHoliday  = "holiday"
+	CalendarElection = "election"
+	CalendarSeason   = "season"
+)
+
+// CalendarEventKinds lists the kinds of calendar event
+var CalendarEventKinds = []string{CalendarHoliday, CalendarElection, CalendarSeason}
+
+// CalendarEvent is a day or season that changes how many reports are made,
+// such as a public holiday, an election or the rainy season. Analytics mark
+// the periods it covers and expect their counts to move by ExpectedPercent,
+// so a spike the calendar explains is not taken for an anomaly.
+type CalendarEvent struct {
+	ID   uint   `gorm:"primaryKey" json:"id"`
+	Kind string `gorm:"not null" json:"kind" binding:"required"`
+	Name string `gorm:"not null" json:"name" binding:"required"`
+	// StartDate and EndDate are the first and last days, as YYYY-MM-DD
+	StartDate string `gorm:"type:varchar(10);not null;index" json:"start_date" binding:"required"`
+	EndDate   string `gorm:"type:varchar(10);not null" json:"end_date" binding:"required"`
+	// Recurring events fall on the same dates every year
+	Recurring bool `gorm:"not null;default:false" json:"recurring"`
+	// StateName and Category narrow the event to one state or category of
+	// report; empty applies everywhere
+	StateName string `gorm:"not null;default:''" json:"state_name"`
+	Category  string `gorm:"not null;default:''" json:"category"`
+	// ExpectedPercent is how many reports the event brings, as a percentage
+	// of an ordinary day's; 100 marks the event without expecting a change
+	ExpectedPercent int   `gorm:"not null;default:100" json:"expected_percent"`
+	CreatedBy       uint  `json:"created_by,omitempty"`
+	CreatedAt       int64 `json:"created_at"`
+	UpdatedAt       int64 `json:"updated_at"`
+}
+
+// Timeseries is the report counts of several groups over time, with the
+// calendar events falling in it
+type Timeseries struct {
+	GroupBy  string            `json:"group_by"`
+	Interval string            `json:"interval"`
+	Points   []TimeseriesPoint `json:"timeseries"`
+	Calendar []CalendarEvent   `json:"calendar"`
+}
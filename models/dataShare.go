@@ -0,0 +1,66 @@
+package models
+
+// How much of the reporter and location a partner sees
+const (
+	// PIILevelNone hides the reporter and rounds locations to about a
+	// kilometre
+	PIILevelNone = "none"
+	// PIILevelPseudonymous identifies reporters by a pseudonym stable for
+	// the partner, with exact locations
+	PIILevelPseudonymous = "pseudonymous"
+	// PIILevelFull adds the reporter's name and username, except on
+	// anonymous reports
+	PIILevelFull = "full"
+)
+
+// DataShare is a data-sharing agreement with a partner: the states and
+// categories of published reports it may export, how much personal data it
+// sees and until when. Empty States or Categories mean all of them. The
+// partner authenticates with an API key of which only a hash is kept.
+type DataShare struct {
+	ID         uint     `gorm:"primaryKey" json:"id"`
+	Partner    string   `gorm:"not null" json:"partner"`
+	Contact    string   `json:"contact"`
+	KeyHash    string   `gorm:"not null;uniqueIndex" json:"-"`
+	KeyPrefix  string   `gorm:"not null" json:"key_prefix"` // the start of the key, to tell keys apart
+	States     []string `gorm:"type:text;serializer:json" json:"states"`
+	Categories []string `gorm:"type:text;serializer:json" json:"categories"`
+	PIILevel   string   `gorm:"not null;default:'none'" json:"pii_level"`
+	ExpiresAt  int64    `gorm:"not null;index" json:"expires_at"`
+	RevokedAt  *int64   `json:"revoked_at,omitempty"`
+	LastUsedAt int64    `json:"last_used_at,omitempty"`
+	CreatedBy  uint     `json:"created_by"`
+	CreatedAt  int64    `json:"created_at"`
+}
+
+// Active reports whether the partner may still use the agreement at now
+func (d *DataShare) Active(now int64) bool {
+	return d.RevokedAt == nil && d.ExpiresAt > now
+}
+
+// DataShareRequest creates an agreement
+type DataShareRequest struct {
+	Partner    string   `json:"partner" binding:"required"`
+	Contact    string   `json:"contact"`
+	States     []string `json:"states"`
+	Categories []string `json:"categories"`
+	PIILevel   string   `json:"pii_level" binding:"required,oneof=none pseudonymous full"`
+	ExpiresAt  int64    `json:"expires_at" binding:"required"`
+}
+
+// SharedReport is a published report as a partner exports it
+type SharedReport struct {
+	ID               string  `json:"id"`
+	Category         string  `json:"category"`
+	SubReportType    string  `json:"sub_report_type"`
+	Description      string  `json:"description"`
+	StateName        string  `json:"state_name"`
+	LGAName          string  `json:"lga_name"`
+	Latitude         float64 `json:"latitude"`
+	Longitude        float64 `json:"longitude"`
+	Status           string  `json:"status"`
+	CreatedAt        int64   `json:"created_at"`
+	ResolvedAt       int64   `json:"resolved_at,omitempty"`
+	Reporter         string  `json:"reporter,omitempty"` // pseudonym, or name with full PII
+	ReporterUsername string  `json:"reporter_username,omitempty"`
+}
@@ -0,0 +1,25 @@
+package models
+
+// How often a digest is sent
+const (
+	DigestDaily  = "daily"
+	DigestWeekly = "weekly"
+)
+
+// DigestSubscription is a user's request for an email summing up the new
+// reports in an area or category every day or week. Empty filters match
+// every report.
+type DigestSubscription struct {
+	ID        uint   `gorm:"primaryKey" json:"id"`
+	UserID    uint   `gorm:"not null;uniqueIndex:idx_digest_subscriptions_scope,priority:1" json:"user_id"`
+	Frequency string `gorm:"not null;uniqueIndex:idx_digest_subscriptions_scope,priority:2" json:"frequency" binding:"required,oneof=daily weekly"`
+	StateName string `gorm:"not null;default:'';uniqueIndex:idx_digest_subscriptions_scope,priority:3" json:"state_name"`
+	LGAName   string `gorm:"not null;default:'';uniqueIndex:idx_digest_subscriptions_scope,priority:4" json:"lga_name"`
+	Category  string `gorm:"not null;default:'';uniqueIndex:idx_digest_subscriptions_scope,priority:5" json:"category"`
+	// CoveredUntil is the end of the last period sent, where the next
+	// digest starts
+	CoveredUntil int64 `gorm:"not null" json:"covered_until"`
+	NextSendAt   int64 `gorm:"not null;index" json:"next_send_at"`
+	LastSentAt   int64 `json:"last_sent_at,omitempty"`
+	CreatedAt    int64 `json:"created_at"`
+}
@@ -0,0 +1,31 @@
+package models
+
+// Display name appeal statuses
+const (
+	AppealPending  = "pending"
+	AppealApproved = "approved"
+	AppealRejected = "rejected"
+)
+
+// Public name fields of a user the display-name policy applies to
+const (
+	DisplayNameUsername = "username"
+	DisplayNameFullname = "fullname"
+)
+
+// DisplayNameAppeal asks an admin to allow a name the display-name policy
+// rejected. Approving it sets the name on the user's profile.
+type DisplayNameAppeal struct {
+	ID     uint   `gorm:"primaryKey" json:"id"`
+	UserID uint   `gorm:"not null;index" json:"user_id"`
+	Field  string `gorm:"not null" json:"field"`
+	Name   string `gorm:"not null" json:"name"`
+	// Rule is the policy rule that rejected the name
+	Rule       string `json:"rule"`
+	Message    string `gorm:"type:text" json:"message"`
+	Status     string `gorm:"not null;index" json:"status"`
+	ReviewedBy *uint  `json:"reviewed_by,omitempty"`
+	ReviewNote string `gorm:"type:text" json:"review_note,omitempty"`
+	CreatedAt  int64  `json:"created_at"`
+	ReviewedAt int64  `json:"reviewed_at,omitempty"`
+}
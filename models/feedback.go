@@ -0,0 +1,56 @@
+package models
+
+// Feedback categories
+const (
+	FeedbackBug        = "bug"
+	FeedbackSuggestion = "suggestion"
+	FeedbackContent    = "content" // wrong or missing help, translations, categories
+	FeedbackOther      = "other"
+)
+
+// Feedback triage statuses
+const (
+	FeedbackNew     = "new"
+	FeedbackTriaged = "triaged"
+	FeedbackClosed  = "closed"
+)
+
+// Feedback is a bug report or suggestion about the app itself, sent from
+// inside it
+type Feedback struct {
+	ID              uint   `gorm:"primaryKey" json:"id"`
+	UserID          uint   `gorm:"not null;index" json:"user_id"`
+	Category        string `gorm:"not null;index" json:"category"`
+	Text            string `gorm:"type:text;not null" json:"text"`
+	AppVersion      string `json:"app_version"`
+	Platform        string `json:"platform"`
+	OSVersion       string `json:"os_version"`
+	DeviceModel     string `json:"device_model"`
+	ScreenshotKey   string `json:"-"`
+	ScreenshotURL   string `gorm:"-" json:"screenshot_url,omitempty"` // signed, for admins
+	Status          string `gorm:"not null;default:'new';index" json:"status"`
+	TriageNote      string `json:"triage_note,omitempty"`
+	TriagedBy       *uint  `json:"triaged_by,omitempty"`
+	ForwardedAt     int64  `gorm:"index" json:"forwarded_at,omitempty"` // sent to the issue tracker
+	ForwardAttempts int    `gorm:"not null;default:0" json:"-"`
+	ForwardError    string `json:"forward_error,omitempty"`
+	CreatedAt       int64  `gorm:"index" json:"created_at"`
+	UpdatedAt       int64  `json:"updated_at"`
+}
+
+// FeedbackRequest is the multipart form feedback is sent as, optionally with
+// a screenshot file
+type FeedbackRequest struct {
+	Category    string `form:"category" binding:"required,oneof=bug suggestion content other"`
+	Text        string `form:"text" binding:"required,max=5000"`
+	AppVersion  string `form:"app_version" binding:"max=64"`
+	Platform    string `form:"platform" binding:"max=32"`
+	OSVersion   string `form:"os_version" binding:"max=64"`
+	DeviceModel string `form:"device_model" binding:"max=128"`
+}
+
+// FeedbackTriage sets the triage status of feedback
+type FeedbackTriage struct {
+	Status string `json:"status" binding:"required,oneof=new triaged closed"`
+	Note   string `json:"note"`
+}
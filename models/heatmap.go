@@ -0,0 +1,36 @@
+package models
+
+// Heatmap is the report density over a geohash grid
+type Heatmap struct {
+	Precision int           `json:"precision"`
+	MaxCount  int64         `json:"max_count"`
+	Cells     []HeatmapCell `json:"cells"`
+}
+
+// HeatmapCell is the report count of one geohash cell and the cell's centre
+type HeatmapCell struct {
+	Geohash string  `json:"geohash"`
+	Lat     float64 `json:"lat"`
+	Lng     float64 `json:"lng"`
+	Count   int64   `json:"count"`
+}
+
+// MarkerClusters are the report markers of a map view, grouped into one
+// marker per geohash cell of a size chosen for the zoom level
+type MarkerClusters struct {
+	Zoom      int             `json:"zoom"`
+	Precision int             `json:"precision"`
+	Total     int64           `json:"total"`
+	Clusters  []MarkerCluster `json:"clusters"`
+}
+
+// MarkerCluster is a group of reports drawn as one marker at their
+// centroid. ReportID and Category are set when it holds a single report.
+type MarkerCluster struct {
+	Geohash  string  `json:"geohash"`
+	Lat      float64 `json:"lat"`
+	Lng      float64 `json:"lng"`
+	Count    int64   `json:"count"`
+	ReportID *string `json:"report_id,omitempty"`
+	Category *string `json:"category,omitempty"`
+}
@@ -0,0 +1,57 @@
+package models
+
+// Kinds of help article
+const (
+	HelpKindFAQ   = "faq"
+	HelpKindGuide = "guide" // how to report in one category
+	HelpKindTip   = "tip"   // shown while onboarding
+)
+
+// HelpArticle is a piece of help content, written as revisions in each
+// locale. Clients see the latest published revision in their locale, or in
+// the default locale when there is none in theirs.
+type HelpArticle struct {
+	ID        uint   `gorm:"primaryKey" json:"id"`
+	Slug      string `gorm:"not null;uniqueIndex" json:"slug" binding:"required"`
+	Kind      string `gorm:"not null;index" json:"kind" binding:"required,oneof=faq guide tip"`
+	Category  string `gorm:"not null;default:''" json:"category"` // the report category a guide is for
+	Position  int    `gorm:"not null;default:0" json:"position"`  // lower comes first
+	CreatedBy uint   `json:"created_by"`
+	CreatedAt int64  `json:"created_at"`
+	UpdatedAt int64  `json:"updated_at"`
+}
+
+// HelpRevision is one version of an article in one locale. Versions count
+// up per locale; publishing a revision makes it the one clients see.
+type HelpRevision struct {
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	ArticleID   uint   `gorm:"not null;uniqueIndex:idx_help_revisions_version,priority:1" json:"article_id"`
+	Locale      string `gorm:"not null;uniqueIndex:idx_help_revisions_version,priority:2" json:"locale"`
+	Version     int    `gorm:"not null;uniqueIndex:idx_help_revisions_version,priority:3" json:"version"`
+	Title       string `gorm:"not null" json:"title"`
+	Body        string `gorm:"type:text;not null" json:"body"`
+	PublishedAt *int64 `gorm:"index" json:"published_at,omitempty"`
+	CreatedBy   uint   `json:"created_by"`
+	CreatedAt   int64  `json:"created_at"`
+}
+
+// HelpRevisionRequest drafts a new revision of an article
+type HelpRevisionRequest struct {
+	Locale string `json:"locale" binding:"required"`
+	Title  string `json:"title" binding:"required"`
+	Body   string `json:"body" binding:"required"`
+}
+
+// HelpContent is an article as clients see it: its published revision in
+// the reader's locale
+type HelpContent struct {
+	Slug        string `json:"slug"`
+	Kind        string `json:"kind"`
+	Category    string `json:"category,omitempty"`
+	Position    int    `json:"position"`
+	Locale      string `json:"locale"`
+	Version     int    `json:"version"`
+	Title       string `json:"title"`
+	Body        string `json:"body"`
+	PublishedAt int64  `json:"published_at"`
+}
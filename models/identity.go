@@ -0,0 +1,34 @@
+package models
+
+// Identity verification statuses
+const (
+	IdentityVerified = "verified"
+	IdentityFailed   = "failed"
+	IdentityRevoked  = "revoked"
+)
+
+// IdentityVerification is a user's check of their NIN or BVN. Neither the
+// number nor what the register holds is kept: only whether it matched and
+// a keyed hash of the number, so one number cannot verify two accounts.
+type IdentityVerification struct {
+	ID            uint    `gorm:"primaryKey" json:"id"`
+	UserID        uint    `gorm:"not null;uniqueIndex" json:"user_id"`
+	Method        string  `gorm:"not null" json:"method"` // nin or bvn
+	ReferenceHash *string `gorm:"uniqueIndex" json:"-"`   // set while verified
+	Provider      string  `json:"provider"`
+	Status        string  `gorm:"not null;index" json:"status"`
+	Attempts      int     `gorm:"not null;default:0" json:"attempts"` // failed attempts since the window began
+	WindowStart   int64   `gorm:"not null;default:0" json:"-"`        // when the current attempt window began
+	VerifiedAt    int64   `json:"verified_at,omitempty"`
+	UpdatedAt     int64   `json:"updated_at"`
+}
+
+// IdentityVerificationRequest asks to verify the user's identity with a NIN
+// or BVN, matched against their name and date of birth
+type IdentityVerificationRequest struct {
+	Method string `json:"method" binding:"required,oneof=nin bvn"`
+	Number string `json:"number" binding:"required,len=11,numeric"`
+	// DateOfBirth is matched instead of the profile's when the profile has
+	// none
+	DateOfBirth string `json:"date_of_birth"`
+}
@@ -0,0 +1,82 @@
+package models
+
+import "github.com/google/uuid"
+
+// Incident statuses
+const (
+	IncidentOpen       = "open"
+	IncidentMonitoring = "monitoring"
+	IncidentResolved   = "resolved"
+)
+
+// IncidentStatuses lists the statuses an incident may be given
+var IncidentStatuses = []string{IncidentOpen, IncidentMonitoring, IncidentResolved}
+
+// Incident is a real-world event, such as one flood, that many reports are
+// about. Reports are grouped under it through IncidentReport.IncidentID.
+type Incident struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	Title      string    `gorm:"not null" json:"title" binding:"required"`
+	Summary    string    `gorm:"type:text" json:"summary"`
+	Category   string    `gorm:"index" json:"category"`
+	Status     string    `gorm:"not null;index" json:"status"`
+	StateName  string    `json:"state_name"`
+	LGAName    string    `json:"lga_name"`
+	Latitude   float64   `json:"latitude"`
+	Longitude  float64   `json:"longitude"`
+	StartedAt  int64     `json:"started_at"`
+	ResolvedAt int64     `json:"resolved_at,omitempty"`
+	CreatedBy  uint      `json:"created_by"`
+	CreatedAt  int64     `json:"created_at"`
+	UpdatedAt  int64     `json:"updated_at"`
+
+	ReportCount int64          `gorm:"-" json:"report_count"`
+	Stats       *IncidentStats `gorm:"-" json:"stats,omitempty"`
+}
+
+// IncidentUpdate is an entry on an incident's timeline: a note from the
+// team following it, or a change of status
+type IncidentUpdate struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	IncidentID uuid.UUID `gorm:"type:uuid;not null;index" json:"incident_id"`
+	Status     string    `json:"status,omitempty"`
+	Message    string    `gorm:"type:text;not null" json:"message" binding:"required"`
+	CreatedBy  uint      `json:"created_by"`
+	CreatedAt  int64     `gorm:"index" json:"created_at"`
+}
+
+// IncidentStats aggregates the reports grouped under an incident
+type IncidentStats struct {
+	Reports       int64            `json:"reports"`
+	Verified      int64            `json:"verified"`
+	Upvotes       int64            `json:"upvotes"`
+	Reporters     int64            `json:"reporters"`
+	FirstReportAt int64            `json:"first_report_at"`
+	LastReportAt  int64            `json:"last_report_at"`
+	ByStatus      map[string]int64 `json:"by_status"`
+	Areas         []IncidentArea   `json:"areas"`
+	// Center and Bounds describe where the reports were made from, as
+	// [lat, lng] and [south, west, north, east]
+	Center []float64 `json:"center,omitempty"`
+	Bounds []float64 `json:"bounds,omitempty"`
+}
+
+// IncidentArea counts an incident's reports in one LGA
+type IncidentArea struct {
+	StateName string `json:"state_name"`
+	LGAName   string `json:"lga_name"`
+	Reports   int64  `json:"reports"`
+}
+
+// IncidentDay counts the reports about an incident made on one day
+type IncidentDay struct {
+	Date    string `json:"date"`
+	Reports int64  `json:"reports"`
+}
+
+// IncidentTimeline is the history of an incident: its updates and how
+// many reports came in each day
+type IncidentTimeline struct {
+	Updates      []IncidentUpdate `json:"updates"`
+	DailyReports []IncidentDay    `json:"daily_reports"`
+}
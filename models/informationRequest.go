@@ -0,0 +1,69 @@
+package models
+
+import "github.com/google/uuid"
+
+// Information request statuses
+const (
+	InformationRequestOpen     = "open"
+	InformationRequestAnswered = "answered"
+	InformationRequestExpired  = "expired"
+)
+
+// Parts of a report a moderator can ask the reporter to add to or correct
+const (
+	InformationDescription = "description"
+	InformationDate        = "date_of_incidence"
+	InformationAddress     = "address"
+	InformationLocation    = "location" // latitude and longitude
+	InformationPhotos      = "photos"
+)
+
+// InformationFields lists every part of a report that can be requested
+var InformationFields = []string{
+	InformationDescription,
+	InformationDate,
+	InformationAddress,
+	InformationLocation,
+	InformationPhotos,
+}
+
+// InformationRequest asks a reporter for more on their report. While it is
+// open the reporter may edit the requested fields, and only those.
+type InformationRequest struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	ReportID    uuid.UUID `gorm:"type:uuid;not null;index" json:"report_id"`
+	UserID      uint      `gorm:"not null;index" json:"user_id"` // the reporter
+	ModeratorID uint      `gorm:"not null" json:"moderator_id"`
+	Fields      []string  `gorm:"type:text;serializer:json" json:"fields"`
+	Message     string    `gorm:"type:text" json:"message"`
+	Status      string    `gorm:"not null;index" json:"status"`
+	ExpiresAt   int64     `gorm:"index" json:"expires_at"`
+	Reminders   int       `gorm:"not null;default:0" json:"reminders"`
+	// RemindedAt is when the reporter was last notified, first on creation
+	RemindedAt int64 `json:"-"`
+	CreatedAt  int64 `json:"created_at"`
+	AnsweredAt int64 `json:"answered_at,omitempty"`
+}
+
+// Requested reports whether the request asks for field
+func (r *InformationRequest) Requested(field string) bool {
+	for _, f := range r.Fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// InformationAnswer is a reporter's reply to an information request. Fields
+// left nil are unchanged.
+type InformationAnswer struct {
+	Description     *string  `json:"description"`
+	DateOfIncidence *string  `json:"date_of_incidence"`
+	Address         *string  `json:"address"`
+	Latitude        *float64 `json:"latitude"`
+	Longitude       *float64 `json:"longitude"`
+	// Media is what was uploaded with the answer, already stored under the
+	// report
+	Media []Media `json:"-"`
+}
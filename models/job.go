@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Job statuses
+const (
+	JobPending = "pending"
+	JobDone    = "done"
+	JobFailed  = "failed"
+)
+
+// Job is a unit of background work waiting in the job queue.
+type Job struct {
+	ID          uint   `gorm:"primaryKey"`
+	Type        string `gorm:"not null;index"`
+	Payload     string `gorm:"type:jsonb;not null"`
+	Status      string `gorm:"not null;default:pending;index:idx_jobs_due,priority:1"`
+	Attempts    int    `gorm:"not null;default:0"`
+	MaxAttempts int    `gorm:"not null;default:10"`
+	LastError   string
+	RunAt       time.Time `gorm:"not null;index:idx_jobs_due,priority:2"`
+	FinishedAt  *time.Time
+	CreatedAt   time.Time
+}
@@ -0,0 +1,46 @@
+package models
+
+import "github.com/google/uuid"
+
+// Sources of a report's structured location
+const (
+	LocationFromLandmark = "landmark"
+	LocationFromGeocoder = "geocoder"
+	LocationUnresolved   = "unresolved"
+)
+
+// Landmark is a well-known place people describe locations by, such as a
+// bank branch, bus stop or market
+type Landmark struct {
+	ID uint `gorm:"primaryKey" json:"id"`
+	PublicRef
+	Name string `gorm:"not null" json:"name" binding:"required"`
+	// NormalizedName is the name as geo.NormalizeText writes it, which is
+	// what written locations are matched against
+	NormalizedName string  `gorm:"not null;index" json:"normalized_name"`
+	Kind           string  `json:"kind"`
+	Area           string  `json:"area"`
+	LGAName        string  `gorm:"index" json:"lga_name"`
+	StateName      string  `gorm:"index" json:"state_name"`
+	Latitude       float64 `json:"latitude" binding:"required"`
+	Longitude      float64 `json:"longitude" binding:"required"`
+	CreatedBy      uint    `json:"created_by"`
+	CreatedAt      int64   `json:"created_at"`
+}
+
+// ReportLocation is a report's written address in structured form: parsed
+// into its parts, matched to a landmark where one is known and geocoded
+type ReportLocation struct {
+	ReportID       uuid.UUID `gorm:"type:uuid;primaryKey" json:"report_id"`
+	RawText        string    `gorm:"type:text" json:"raw_text"`
+	NormalizedText string    `gorm:"index" json:"normalized_text"`
+	Relation       string    `json:"relation,omitempty"`
+	LandmarkText   string    `gorm:"index" json:"landmark_text"`
+	Area           string    `gorm:"index" json:"area,omitempty"`
+	LandmarkID     *uint     `gorm:"index" json:"landmark_id"`
+	Landmark       *Landmark `gorm:"foreignKey:LandmarkID" json:"landmark,omitempty"`
+	Latitude       float64   `json:"latitude"`
+	Longitude      float64   `json:"longitude"`
+	Source         string    `json:"source"`
+	CreatedAt      int64     `json:"created_at"`
+}
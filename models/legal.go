@@ -0,0 +1,44 @@
+package models
+
+// Kinds of legal document
+const (
+	LegalTerms   = "terms"
+	LegalPrivacy = "privacy"
+)
+
+// LegalDocument is one version of the Terms of Service or Privacy Policy.
+// Once published it is the current version of its kind until a newer one
+// is, and every user has to accept it before carrying on.
+type LegalDocument struct {
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	Kind        string `gorm:"not null;uniqueIndex:idx_legal_documents_version,priority:1" json:"kind" binding:"required,oneof=terms privacy"`
+	Version     string `gorm:"not null;uniqueIndex:idx_legal_documents_version,priority:2" json:"version" binding:"required"`
+	Title       string `gorm:"not null" json:"title" binding:"required"`
+	Body        string `gorm:"type:text;not null" json:"body" binding:"required"`
+	PublishedAt *int64 `gorm:"index" json:"published_at,omitempty"`
+	CreatedBy   uint   `json:"created_by"`
+	CreatedAt   int64  `json:"created_at"`
+}
+
+// LegalAcceptance records a user accepting a version of a legal document
+type LegalAcceptance struct {
+	ID         uint   `gorm:"primaryKey" json:"id"`
+	UserID     uint   `gorm:"not null;uniqueIndex:idx_legal_acceptances_document,priority:1" json:"user_id"`
+	DocumentID uint   `gorm:"not null;uniqueIndex:idx_legal_acceptances_document,priority:2" json:"document_id"`
+	Kind       string `gorm:"not null" json:"kind"`
+	Version    string `gorm:"not null" json:"version"`
+	IP         string `json:"ip"`
+	AcceptedAt int64  `gorm:"not null" json:"accepted_at"`
+}
+
+// LegalAcceptanceRequest accepts the documents listed, which must be the
+// current versions
+type LegalAcceptanceRequest struct {
+	DocumentIDs []uint `json:"document_ids" binding:"required,min=1"`
+}
+
+// ConsentStatus is what a user has accepted and what they still need to
+type ConsentStatus struct {
+	Accepted []LegalAcceptance `json:"accepted"`
+	Pending  []LegalDocument   `json:"pending"`
+}
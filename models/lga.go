@@ -10,6 +10,15 @@ type LGA struct {
 	State   State     `gorm:"foreignKey:StateID" json:"state"`
 }
 
+// Ward is a political ward within an LGA, recorded as reports are
+// reverse geocoded into it
+type Ward struct {
+	ID        uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
+	Name      string    `gorm:"not null;uniqueIndex:idx_wards_state_lga_name" json:"name"`
+	LGAName   string    `gorm:"not null;uniqueIndex:idx_wards_state_lga_name" json:"lga_name"`
+	StateName string    `gorm:"not null;uniqueIndex:idx_wards_state_lga_name" json:"state_name"`
+}
+
 type State struct {
 	ID   uuid.UUID `gorm:"type:uuid;primary_key" json:"id"`
 	Name string    `gorm:"not null"`
@@ -0,0 +1,29 @@
+package models
+
+// MagicLink is a single-use sign-in link sent by email. The link's token is
+// bound to the device that asked for it; only a hash of its nonce and of the
+// device are kept.
+type MagicLink struct {
+	ID         uint   `gorm:"primaryKey"`
+	UserID     uint   `gorm:"not null;index"`
+	NonceHash  string `gorm:"not null;uniqueIndex"`
+	DeviceHash string `gorm:"not null"`
+	IP         string
+	ExpiresAt  int64 `gorm:"not null"`
+	UsedAt     int64
+	CreatedAt  int64 `gorm:"index"`
+}
+
+// MagicLinkRequest asks for a sign-in link. DeviceID is a random value the
+// client generates once and keeps; the link only works when verified with
+// the same value.
+type MagicLinkRequest struct {
+	Email    string `json:"email" binding:"required,email"`
+	DeviceID string `json:"device_id" binding:"required,min=16,max=256"`
+}
+
+// MagicLinkVerification signs in with the token from a link
+type MagicLinkVerification struct {
+	Token    string `json:"token" binding:"required"`
+	DeviceID string `json:"device_id" binding:"required"`
+}
@@ -16,6 +16,37 @@ type Media struct {
 	Count            int       `json:"count"`
 	Points           int       `json:"points"`
 	IncidentReportID uuid.UUID `json:"incident_report_id"`
+	// PHash is the perceptual hash of an image, stored as the signed bit
+	// pattern of the 64 bit hash. It is nil for video and audio.
+	PHash *int64 `gorm:"column:phash;index" json:"-"`
+	// SHA256 is the hex digest of the original upload, kept for chain of
+	// custody when a report is used as evidence
+	SHA256 string `gorm:"column:sha256;index" json:"sha256"`
+	// WatermarkCode and WatermarkedAt record the provenance mark stamped on
+	// the public renditions, empty when watermarking was off
+	WatermarkCode string `json:"watermark_code,omitempty"`
+	WatermarkedAt int64  `json:"watermarked_at,omitempty"`
+	// OriginalKey is the storage key of the unmodified upload when it is
+	// kept private; moderators read it through a signed URL
+	OriginalKey string `json:"-"`
+	// Graphic marks media that may distress viewers. Clients show
+	// BlurredURL behind a warning until the viewer chooses to see it.
+	Graphic    bool   `gorm:"not null;default:false" json:"graphic"`
+	GraphicBy  string `json:"-"` // one of the MediaGraphicBy values
+	BlurredURL string `json:"blurred_url,omitempty"`
+}
+
+// Who marked media graphic. A reporter or moderator's choice outlasts the
+// default of the report's category.
+const (
+	MediaGraphicByCategory  = "category"
+	MediaGraphicByReporter  = "reporter"
+	MediaGraphicByModerator = "moderator"
+)
+
+// MediaGraphicRequest marks or unmarks media as graphic
+type MediaGraphicRequest struct {
+	Graphic *bool `json:"graphic" binding:"required"`
 }
 
 type MediaCount struct {
@@ -26,3 +57,16 @@ type MediaCount struct {
 	UserID           uint
 	IncidentReportID string `gorm:"not null;type:varchar(36);index"`
 }
+
+// MediaReuseFlag marks an uploaded image that looks like media already
+// attached to another report, so moderators can check for a recycled photo
+// before the report earns rewards.
+type MediaReuseFlag struct {
+	Model
+	IncidentReportID string `gorm:"not null;type:varchar(36);index" json:"incident_report_id"`
+	MediaID          string `gorm:"not null;index" json:"media_id"`
+	MatchedReportID  string `gorm:"not null;type:varchar(36);index" json:"matched_report_id"`
+	MatchedMediaID   string `gorm:"not null" json:"matched_media_id"`
+	// Distance is the number of hash bits that differ, 0 for an exact copy
+	Distance int `json:"distance"`
+}
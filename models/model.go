@@ -1,8 +1,27 @@
 package models
 
+import (
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
 type Model struct {
 	ID        uint  `gorm:"primaryKey"`
 	CreatedAt int64 `json:"created_at"`
 	UpdatedAt int64 `json:"updated_at"`
 	DeletedAt int64 `json:"deleted_at"`
 }
+
+// PublicRef gives a record a random external ID that APIs use in place of
+// its numeric ID, which can be counted through. Records created before it
+// get one from the backfill-public-ids command.
+type PublicRef struct {
+	PublicID *uuid.UUID `gorm:"type:uuid;uniqueIndex" json:"public_id,omitempty"`
+}
+
+// BeforeCreate assigns the external ID, ignoring any the client sent
+func (r *PublicRef) BeforeCreate(tx *gorm.DB) error {
+	id := uuid.New()
+	r.PublicID = &id
+	return nil
+}
@@ -0,0 +1,35 @@
+package models
+
+import "github.com/google/uuid"
+
+// ModerationDecision records a moderator approving, accepting or rejecting
+// a report. A decision is overturned when a later one on the same report
+// reaches a different outcome.
+type ModerationDecision struct {
+	ID             uint      `gorm:"primaryKey" json:"id"`
+	ReportID       uuid.UUID `gorm:"type:uuid;not null;index" json:"report_id"`
+	ModeratorID    uint      `gorm:"not null;index" json:"moderator_id"`
+	Decision       string    `gorm:"not null" json:"decision"`
+	PreviousStatus string    `json:"previous_status"`
+	// ReviewSeconds is how long the report waited, from submission until
+	// this decision
+	ReviewSeconds int64  `json:"review_seconds"`
+	DecidedAt     int64  `gorm:"not null;index" json:"decided_at"`
+	OverturnedAt  *int64 `json:"overturned_at"`
+	OverturnedBy  *uint  `json:"overturned_by"`
+}
+
+// ModeratorPerformance summarises one moderator's decisions over a window.
+// OverturnRate is the share of those decisions later reversed.
+type ModeratorPerformance struct {
+	ModeratorID         uint    `json:"moderator_id"`
+	Fullname            string  `json:"fullname"`
+	Decisions           int64   `json:"decisions"`
+	Approved            int64   `json:"approved"`
+	Accepted            int64   `json:"accepted"`
+	Rejected            int64   `json:"rejected"`
+	PerDay              float64 `json:"per_day"`
+	MedianReviewSeconds float64 `json:"median_review_seconds"`
+	Overturned          int64   `json:"overturned"`
+	OverturnRate        float64 `json:"overturn_rate"`
+}
@@ -6,4 +6,9 @@ type Notification struct {
 	UserID  uint   `json:"user_id" gorm:"foreignKey:UserID"`
 	Message string `json:"message"`
 	IsRead  bool   `json:"is_read"`
+	// DedupKey is the key of the event the notification was raised for,
+	// so an event delivered twice notifies once
+	DedupKey *string `json:"-" gorm:"uniqueIndex"`
+	// AnnouncementID is set on notifications delivering an announcement
+	AnnouncementID *uint `json:"announcement_id,omitempty" gorm:"index"`
 }
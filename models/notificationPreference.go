@@ -0,0 +1,106 @@
+package models
+
+// Channels a notification can be delivered over, besides the in-app list
+// every notification is kept in
+const (
+	ChannelPush  = "push"
+	ChannelEmail = "email"
+	ChannelSMS   = "sms"
+)
+
+// Categories of notification a user can opt in or out of
+const (
+	NotifyComments = "comments"
+	NotifyStatus   = "status"
+	NotifyRewards  = "rewards"
+	NotifyAlerts   = "alerts"
+	// NotifyReminders covers nudges to come back after a period of
+	// inactivity; turning it off on every channel opts the user out
+	NotifyReminders = "reminders"
+)
+
+var (
+	NotificationChannels   = []string{ChannelPush, ChannelEmail, ChannelSMS}
+	NotificationCategories = []string{NotifyComments, NotifyStatus, NotifyRewards, NotifyAlerts, NotifyReminders}
+)
+
+// NotificationPreference is a user's choice for one channel and category.
+// Only choices the user has made are stored; everything else falls back to
+// DefaultNotificationPreferences.
+type NotificationPreference struct {
+	UserID    uint   `gorm:"primaryKey" json:"-"`
+	Channel   string `gorm:"primaryKey" json:"channel"`
+	Category  string `gorm:"primaryKey" json:"category"`
+	Enabled   bool   `json:"enabled"`
+	UpdatedAt int64  `json:"updated_at"`
+}
+
+// NotificationPreferences maps channel to category to whether the user
+// receives that category over that channel
+type NotificationPreferences map[string]map[string]bool
+
+// DefaultNotificationPreferences returns the settings of a user who has not
+// changed any: everything by push, status changes, rewards, alerts and
+// reminders by email, and only alerts by SMS, which costs money to send.
+func DefaultNotificationPreferences() NotificationPreferences {
+	return NotificationPreferences{
+		ChannelPush: {
+			NotifyComments:  true,
+			NotifyStatus:    true,
+			NotifyRewards:   true,
+			NotifyAlerts:    true,
+			NotifyReminders: true,
+		},
+		ChannelEmail: {
+			NotifyComments:  false,
+			NotifyStatus:    true,
+			NotifyRewards:   true,
+			NotifyAlerts:    true,
+			NotifyReminders: true,
+		},
+		ChannelSMS: {
+			NotifyComments:  false,
+			NotifyStatus:    false,
+			NotifyRewards:   false,
+			NotifyAlerts:    true,
+			NotifyReminders: false,
+		},
+	}
+}
+
+// QuietHours is the nightly window in which a user's non-critical
+// notifications are held back and delivered together when it ends. Start
+// and End are "HH:MM" in TimeZone; a window may run past midnight.
+type QuietHours struct {
+	UserID    uint   `gorm:"primaryKey" json:"-"`
+	Enabled   bool   `json:"enabled"`
+	Start     string `json:"start" binding:"required"`
+	End       string `json:"end" binding:"required"`
+	TimeZone  string `json:"time_zone" binding:"required"`
+	UpdatedAt int64  `json:"updated_at"`
+}
+
+// DefaultQuietHours returns the quiet hours of a user who has not set any:
+// ten at night to seven in the morning, Lagos time
+func DefaultQuietHours() QuietHours {
+	return QuietHours{
+		Enabled:  true,
+		Start:    "22:00",
+		End:      "07:00",
+		TimeZone: "Africa/Lagos",
+	}
+}
+
+// PendingNotification is a notification waiting to go out over the user's
+// channels, either until their quiet hours end or until the batching window
+// for its report closes
+type PendingNotification struct {
+	ID           uint   `gorm:"primaryKey"`
+	UserID       uint   `gorm:"not null;index"`
+	ReportID     string `gorm:"index"`
+	Category     string `gorm:"not null"`
+	Subject      string
+	Message      string
+	DeliverAfter int64 `gorm:"not null;index"`
+	CreatedAt    int64
+}
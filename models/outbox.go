@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// OutboxEvent is a domain event saved in the same transaction as the change
+// it describes, waiting to be relayed to subscribers.
+type OutboxEvent struct {
+	ID            uint   `gorm:"primaryKey"`
+	EventName     string `gorm:"not null;index"`
+	DedupKey      string `gorm:"not null;uniqueIndex"`
+	Payload       string `gorm:"type:jsonb;not null"`
+	Attempts      int    `gorm:"not null;default:0"`
+	LastError     string
+	NextAttemptAt time.Time  `gorm:"not null;index"`
+	PublishedAt   *time.Time `gorm:"index"`
+	CreatedAt     time.Time
+}
@@ -0,0 +1,79 @@
+package models
+
+// BankAccount is where a user's payouts are sent. The account number is
+// encrypted; the name is the one the bank gave when it was resolved.
+type BankAccount struct {
+	ID            uint   `gorm:"primaryKey" json:"id"`
+	UserID        uint   `gorm:"not null;uniqueIndex" json:"user_id"`
+	BankCode      string `gorm:"not null" json:"bank_code"`
+	BankName      string `json:"bank_name"`
+	AccountNumber string `gorm:"serializer:encrypted;not null" json:"-"`
+	Last4         string `gorm:"not null" json:"last4"`
+	AccountName   string `gorm:"not null" json:"account_name"`
+	CreatedAt     int64  `json:"created_at"`
+	UpdatedAt     int64  `json:"updated_at"`
+}
+
+// BankAccountRequest sets the user's bank account
+type BankAccountRequest struct {
+	BankCode      string `json:"bank_code" binding:"required"`
+	AccountNumber string `json:"account_number" binding:"required,len=10,numeric"` // NUBAN
+}
+
+// Payout batch statuses
+const (
+	PayoutBatchProcessing = "processing"
+	PayoutBatchCompleted  = "completed"
+)
+
+// PayoutBatch pays a set of reporters in a monitoring program at once
+type PayoutBatch struct {
+	ID         uint   `gorm:"primaryKey" json:"id"`
+	Program    string `gorm:"not null;index" json:"program"`
+	Status     string `gorm:"not null;index" json:"status"`
+	Payouts    int    `json:"payouts"`
+	TotalKobo  int64  `json:"total_kobo"`
+	CreatedBy  uint   `json:"created_by"`
+	CreatedAt  int64  `gorm:"index" json:"created_at"`
+	FinishedAt int64  `json:"finished_at,omitempty"`
+}
+
+// PayoutBatchRequest pays out the whole balance of each user listed with a
+// bank account and at least MinimumPoints
+type PayoutBatchRequest struct {
+	Program       string `json:"program" binding:"required"`
+	UserIDs       []uint `json:"user_ids" binding:"required,min=1"`
+	MinimumPoints int64  `json:"minimum_points"`
+}
+
+// Payout statuses
+const (
+	PayoutPending  = "pending"   // waiting to be sent
+	PayoutSending  = "sending"   // claimed by a sender; whether the provider has it is not known yet
+	PayoutSent     = "sent"      // with the provider
+	PayoutNeedsOTP = "needs_otp" // held by the provider until an admin finalises it with an OTP
+	PayoutPaid     = "paid"      // confirmed by the provider
+	PayoutFailed   = "failed"    // the points are given back
+	PayoutReversed = "reversed"  // paid, then returned by the bank; the points are given back
+)
+
+// Payout is one user's points paid out as money. The points are taken off
+// when the payout is created and given back if it fails.
+type Payout struct {
+	ID                uint   `gorm:"primaryKey" json:"id"`
+	BatchID           uint   `gorm:"not null;index" json:"batch_id"`
+	UserID            uint   `gorm:"not null;index" json:"user_id"`
+	Reference         string `gorm:"not null;uniqueIndex" json:"reference"`
+	Points            int64  `gorm:"not null" json:"points"`
+	AmountKobo        int64  `gorm:"not null" json:"amount_kobo"`
+	Amount            string `gorm:"-" json:"amount,omitempty"` // AmountKobo written for the reader
+	BankName          string `json:"bank_name"`
+	Last4             string `json:"last4"`
+	Status            string `gorm:"not null;index" json:"status"`
+	ProviderReference string `json:"provider_reference,omitempty"`
+	FailureReason     string `json:"failure_reason,omitempty"`
+	Attempts          int    `gorm:"not null;default:0" json:"-"`
+	CreatedAt         int64  `gorm:"index" json:"created_at"`
+	UpdatedAt         int64  `json:"updated_at"`
+	PaidAt            int64  `json:"paid_at,omitempty"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"github.com/techagentng/citizenx/pii"
+	"gorm.io/gorm"
+)
+
+// PhoneOTP is a one-time code sent by SMS to sign in with a phone number.
+// Only a keyed hash of the code is kept.
+type PhoneOTP struct {
+	ID         uint    `gorm:"primaryKey"`
+	Phone      string  `gorm:"not null;serializer:encrypted"`
+	PhoneIndex *string `gorm:"index"` // blind index of Phone for lookups
+	CodeHash   string  `gorm:"not null"`
+	IP         string  `gorm:"index"`
+	Attempts   int     `gorm:"not null;default:0"`
+	ExpiresAt  int64   `gorm:"not null"`
+	UsedAt     int64
+	CreatedAt  int64 `gorm:"index"`
+}
+
+// BeforeSave keeps the phone's blind index in step with it
+func (o *PhoneOTP) BeforeSave(tx *gorm.DB) error {
+	o.PhoneIndex = pii.BlindIndex(o.Phone)
+	return nil
+}
+
+// OTPChallenge tells the client a code is on its way
+type OTPChallenge struct {
+	Phone string `json:"phone"`
+	// ExpiresIn and ResendAfter are in seconds
+	ExpiresIn   int `json:"expires_in"`
+	ResendAfter int `json:"resend_after"`
+}
+
+// PhoneLoginRequest asks for a code, or signs in with one
+type PhoneLoginRequest struct {
+	Phone string `json:"phone" binding:"required"`
+	Code  string `json:"code"`
+}
+
+// CompleteAccountRequest turns an account created by phone sign-in into a
+// full account that can also sign in with an email and password
+type CompleteAccountRequest struct {
+	Fullname    string `json:"fullname" binding:"required,min=2"`
+	Username    string `json:"username" binding:"required,min=2"`
+	Email       string `json:"email" binding:"required,email"`
+	Password    string `json:"password" binding:"required,min=8"`
+	DateOfBirth string `json:"date_of_birth" binding:"required"` // YYYY-MM-DD
+}
@@ -0,0 +1,47 @@
+package models
+
+// Kinds of entry on a reward statement
+const (
+	PointsEarned   = "earned"
+	PointsRedeemed = "redeemed"
+	PointsExpired  = "expired"
+)
+
+// PointDebit takes points off a user's balance: points redeemed for a
+// payout, or points that expired unspent. Points are earned as rewards.
+type PointDebit struct {
+	ID          uint   `gorm:"primaryKey" json:"id"`
+	UserID      uint   `gorm:"not null;index:idx_point_debits_user,priority:1" json:"user_id"`
+	Kind        string `gorm:"not null" json:"kind"`
+	Points      int64  `gorm:"not null" json:"points"` // positive; the amount taken off
+	Reference   string `json:"reference,omitempty"`
+	Description string `json:"description"`
+	CreatedAt   int64  `gorm:"not null;index:idx_point_debits_user,priority:2" json:"created_at"`
+}
+
+// RewardStatementLine is one entry on a statement. Points are negative for
+// redemptions and expiries; Balance is the running balance after it.
+type RewardStatementLine struct {
+	At          int64  `json:"at"`
+	Kind        string `json:"kind"`
+	Description string `json:"description"`
+	Reference   string `json:"reference,omitempty"`
+	Points      int64  `json:"points"`
+	Balance     int64  `json:"balance"`
+}
+
+// RewardStatement is a user's points for one calendar month, in Lagos time
+type RewardStatement struct {
+	UserID         uint   `json:"user_id"`
+	Fullname       string `json:"fullname"`
+	Month          string `json:"month"` // YYYY-MM
+	OpeningBalance int64  `json:"opening_balance"`
+	Earned         int64  `json:"earned"`
+	Redeemed       int64  `json:"redeemed"`
+	Expired        int64  `json:"expired"`
+	ClosingBalance int64  `json:"closing_balance"`
+	// ClosingValueKobo is what the closing balance is worth at the current
+	// point value
+	ClosingValueKobo int64                 `json:"closing_value_kobo"`
+	Lines            []RewardStatementLine `json:"lines"`
+}
@@ -3,10 +3,13 @@ package models
 // Reward represents rewards earned by users
 type Post struct {
 	Model
+	PublicRef
 	UserID          uint   `json:"user_id" gorm:"foreignKey:ID"`
 	Title           string `json:"post"`
 	PostCategory    string `json:"post_category"`
 	Image           string `json:"post_image"`
 	PostDescription string `json:"post_description"`
+	// PostDescriptionRaw is the description as written, before masking
+	PostDescriptionRaw string `json:"-"`
 	UserFullname         string     `json:"fullname"`
 }
@@ -0,0 +1,11 @@
+package models
+
+// ReengagementNudge records a re-engagement notification sent to an
+// inactive user, so that users are not nudged more often than the
+// configured cooldown allows
+type ReengagementNudge struct {
+	ID     uint   `gorm:"primaryKey" json:"id"`
+	UserID uint   `gorm:"not null;index" json:"user_id"`
+	Reason string `json:"reason"`
+	SentAt int64  `gorm:"not null;index" json:"sent_at"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "github.com/google/uuid"
+
+// Outcomes of a report audit
+const (
+	AuditOutcomePending = ""
+	AuditOutcomePassed  = "passed"
+	AuditOutcomeFailed  = "failed"
+)
+
+// ReportAudit puts an auto-published report in front of a moderator after
+// the fact. Only a sample of auto-published reports is audited.
+type ReportAudit struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	ReportID  uuid.UUID `gorm:"type:uuid;not null;uniqueIndex" json:"report_id"`
+	UserID    uint      `gorm:"not null;index" json:"user_id"`
+	Category  string    `json:"category"`
+	SampledAt int64     `gorm:"not null;index" json:"sampled_at"`
+	Outcome   string    `gorm:"index" json:"outcome"`
+	Note      string    `gorm:"type:text" json:"note"`
+	AuditedBy *uint     `json:"audited_by"`
+	AuditedAt int64     `json:"audited_at"`
+}
@@ -0,0 +1,10 @@
+package models
+
+// ReportCapExemption lets an account file any number of reports a day, for
+// organisations that report on behalf of many people
+type ReportCapExemption struct {
+	UserID    uint   `gorm:"primaryKey;autoIncrement:false" json:"user_id" binding:"required"`
+	Reason    string `gorm:"type:text;not null" json:"reason" binding:"required,max=500"`
+	CreatedBy uint   `json:"created_by"`
+	CreatedAt int64  `json:"created_at"`
+}
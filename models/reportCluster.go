@@ -0,0 +1,20 @@
+package models
+
+import "github.com/google/uuid"
+
+// ReportCluster groups reports that look like duplicates of each other: the
+// same category in the same LGA, made close together in place and time.
+// Clusters form on their own as reports come in, unlike incidents, which
+// moderators put together.
+type ReportCluster struct {
+	ID            uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	Category      string    `gorm:"index" json:"category"`
+	StateName     string    `json:"state_name"`
+	LGAName       string    `json:"lga_name"`
+	Latitude      float64   `json:"latitude"` // where the first report was made
+	Longitude     float64   `json:"longitude"`
+	ReportCount   int64     `gorm:"not null;default:0" json:"report_count"`
+	FirstReportAt int64     `json:"first_report_at"`
+	LastReportAt  int64     `gorm:"index" json:"last_report_at"`
+	CreatedAt     int64     `json:"created_at"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import "github.com/google/uuid"
+
+// Kinds of contribution made to a report
+const (
+	ContributionMedia  = "media"
+	ContributionUpdate = "update"
+)
+
+// ReportCollaborator is a user the reporter has let add media and updates
+// to their report. RewardShare is the percentage of the report's points the
+// collaborator receives when the report is approved; the reporter keeps the
+// rest.
+type ReportCollaborator struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	ReportID    uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_report_collaborator" json:"report_id"`
+	UserID      uint      `gorm:"not null;uniqueIndex:idx_report_collaborator;index" json:"user_id"`
+	Username    string    `gorm:"->;-:migration" json:"username,omitempty"`
+	AddedBy     uint      `gorm:"not null" json:"added_by"`
+	RewardShare int       `gorm:"not null;default:0" json:"reward_share"`
+	CreatedAt   int64     `json:"created_at"`
+}
+
+// ReportContribution attributes one piece of media or one update on a
+// report to the user who added it, the reporter or a collaborator.
+type ReportContribution struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	ReportID     uuid.UUID `gorm:"type:uuid;not null;index" json:"report_id"`
+	UserID       uint      `gorm:"not null;index" json:"user_id"`
+	Kind         string    `gorm:"not null" json:"kind"`
+	Text         string    `gorm:"type:text" json:"text,omitempty"`
+	FeedURL      string    `json:"feed_url,omitempty"`
+	ThumbnailURL string    `json:"thumbnail_url,omitempty"`
+	FullSizeURL  string    `json:"full_size_url,omitempty"`
+	FileType     string    `json:"file_type,omitempty"`
+	CreatedAt    int64     `gorm:"index" json:"created_at"`
+}
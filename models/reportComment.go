@@ -0,0 +1,28 @@
+package models
+
+import "github.com/google/uuid"
+
+// ReportComment is a comment in the discussion under an incident report.
+// Comments without a ParentID start a thread and replies point at the
+// comment that started it, so threads are one level deep. A deleted comment
+// keeps its place in the thread with its body removed, so the replies to it
+// still make sense.
+type ReportComment struct {
+	ID        uint            `gorm:"primaryKey" json:"id"`
+	ReportID  uuid.UUID       `gorm:"type:uuid;not null;index" json:"report_id"`
+	ParentID  *uint           `gorm:"index" json:"parent_id"`
+	UserID    uint            `gorm:"not null;index" json:"user_id"`
+	Username  string          `gorm:"->;-:migration" json:"username,omitempty"`
+	Body      string          `gorm:"type:text" json:"body"`
+	Deleted   bool            `gorm:"not null;default:false" json:"deleted"`
+	EditedAt  int64           `json:"edited_at,omitempty"`
+	CreatedAt int64           `gorm:"index" json:"created_at"`
+	Replies   []ReportComment `gorm:"-" json:"replies,omitempty"`
+}
+
+// ReportCommentRequest is the body of a new or edited comment. ParentID is
+// only read for new comments.
+type ReportCommentRequest struct {
+	Body     string `json:"body" binding:"required"`
+	ParentID *uint  `json:"parent_id"`
+}
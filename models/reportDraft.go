@@ -0,0 +1,39 @@
+package models
+
+import "github.com/google/uuid"
+
+// ReportDraft holds the details of a report while its media is uploaded in
+// separate requests. Media is attached under the draft's ID, which becomes
+// the report's ID when the draft is finalized.
+type ReportDraft struct {
+	ID              uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID          uint      `gorm:"not null;index" json:"user_id"`
+	Category        string    `gorm:"not null" json:"category" binding:"required"`
+	SubReportType   string    `json:"sub_report_type"`
+	Description     string    `gorm:"type:varchar(1000)" json:"description"`
+	StateName       string    `json:"state_name"`
+	LGAName         string    `json:"lga_name"`
+	Address         string    `json:"address"`
+	Latitude        float64   `json:"latitude"`
+	Longitude       float64   `json:"longitude"`
+	PlusCode        string    `json:"plus_code"` // accepted in place of coordinates
+	Route           string    `gorm:"type:text" json:"route"`
+	Telephone       string    `json:"telephone" gorm:"serializer:encrypted"`
+	Email           string    `json:"email" gorm:"serializer:encrypted"`
+	Rating          string    `json:"rating"`
+	DateOfIncidence string    `json:"date_of_incidence"`
+	UserIsAnonymous bool      `json:"user_is_anonymous"`
+	CreatedAt       int64     `gorm:"index" json:"created_at"`
+	UpdatedAt       int64     `json:"updated_at"`
+
+	// Points are the places of an incident spanning several, in order
+	Points []ReportPoint `gorm:"type:text;serializer:json" json:"points"`
+}
+
+// ReportDraftFinalizeRequest is the optional JSON body of a draft's
+// finalization. MediaIDs lists the media uploaded to the draft that the
+// report keeps, in the order it shows them; without it every upload is
+// kept.
+type ReportDraftFinalizeRequest struct {
+	MediaIDs []string `json:"media_ids"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "github.com/google/uuid"
+
+// MaxReportPoints bounds the points a single report may carry
+const MaxReportPoints = 50
+
+// ReportPoint is one of the ordered points of an incident that spans several
+// places, such as a convoy's route or the spread of a flood. The report's
+// own latitude and longitude stay its primary point, the first of these,
+// for markers and nearby queries.
+type ReportPoint struct {
+	ID        uint      `gorm:"primaryKey" json:"-"`
+	ReportID  uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_report_points_position,priority:1" json:"-"`
+	Position  int       `gorm:"not null;uniqueIndex:idx_report_points_position,priority:2" json:"position"`
+	Latitude  float64   `gorm:"not null" json:"latitude"`
+	Longitude float64   `gorm:"not null" json:"longitude"`
+	Label     string    `gorm:"type:varchar(200)" json:"label,omitempty"`
+	// ObservedAt is when the reporter saw the incident at this point, for
+	// incidents that moved
+	ObservedAt int64 `json:"observed_at,omitempty"`
+}
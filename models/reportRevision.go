@@ -0,0 +1,105 @@
+package models
+
+import (
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+// ReportRevision is a report as it stood before one of its edits. The report
+// itself always holds the latest version; version 1 is the report as first
+// submitted.
+type ReportRevision struct {
+	ID       uint      `gorm:"primaryKey" json:"id"`
+	ReportID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_report_revisions_report_version,priority:1" json:"report_id"`
+	Version  int       `gorm:"not null;uniqueIndex:idx_report_revisions_report_version,priority:2" json:"version"`
+	// EditorID is who made the edit that replaced this version, 0 when the
+	// system did
+	EditorID        uint    `json:"editor_id"`
+	Description     string  `gorm:"type:varchar(1000)" json:"description"` // as written, before the text filter
+	DateOfIncidence string  `json:"date_of_incidence"`
+	Address         string  `json:"address"`
+	Latitude        float64 `json:"latitude"`
+	Longitude       float64 `json:"longitude"`
+	Rating          string  `json:"rating"`
+	Category        string  `json:"category"`
+	SubReportType   string  `json:"sub_report_type"`
+	StateName       string  `json:"state_name"`
+	LGAName         string  `json:"lga_name"`
+	CreatedAt       int64   `json:"created_at"` // when the version was replaced
+}
+
+// RevisionOf returns the version of a report a revision keeps
+func RevisionOf(report *IncidentReport) ReportRevision {
+	description := report.DescriptionRaw
+	if description == "" {
+		description = report.Description
+	}
+	return ReportRevision{
+		ReportID:        report.ID,
+		Description:     description,
+		DateOfIncidence: report.DateOfIncidence,
+		Address:         report.Address,
+		Latitude:        report.Latitude,
+		Longitude:       report.Longitude,
+		Rating:          report.Rating,
+		Category:        report.Category,
+		SubReportType:   report.SubReportType,
+		StateName:       report.StateName,
+		LGAName:         report.LGAName,
+	}
+}
+
+// Fields returns the revised fields of a version by their JSON names
+func (r ReportRevision) Fields() map[string]string {
+	return map[string]string{
+		"description":       r.Description,
+		"date_of_incidence": r.DateOfIncidence,
+		"address":           r.Address,
+		"latitude":          strconv.FormatFloat(r.Latitude, 'f', -1, 64),
+		"longitude":         strconv.FormatFloat(r.Longitude, 'f', -1, 64),
+		"rating":            r.Rating,
+		"category":          r.Category,
+		"sub_report_type":   r.SubReportType,
+		"state_name":        r.StateName,
+		"lga_name":          r.LGAName,
+	}
+}
+
+// Same reports whether two revisions keep the same version of a report
+func (r ReportRevision) Same(other ReportRevision) bool {
+	theirs := other.Fields()
+	for field, value := range r.Fields() {
+		if theirs[field] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// ReportEditRequest changes a report's details. Fields left out are kept;
+// a location needs both coordinates.
+type ReportEditRequest struct {
+	Description     *string  `json:"description"`
+	DateOfIncidence *string  `json:"date_of_incidence"`
+	Address         *string  `json:"address"`
+	Latitude        *float64 `json:"latitude"`
+	Longitude       *float64 `json:"longitude"`
+	Rating          *string  `json:"rating"`
+}
+
+// ReportFieldChange is one field that differs between two versions
+type ReportFieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// ReportRevisionDiff is what changed in a report from one version to a later
+// one
+type ReportRevisionDiff struct {
+	ReportID    uuid.UUID           `json:"report_id"`
+	FromVersion int                 `json:"from_version"`
+	ToVersion   int                 `json:"to_version"`
+	Changes     []ReportFieldChange `json:"changes"`
+}
@@ -0,0 +1,13 @@
+package models
+
+// ReportShare is what the app puts in a message when a report is shared
+type ReportShare struct {
+	ReportID string `json:"report_id"`
+	URL      string `json:"url"`
+	PlusCode string `json:"plus_code,omitempty"`
+	// LocalPlusCode and Locality are the short form of the plus code, read
+	// out together over the phone, e.g. "G9FH+QM Ikeja, Lagos"
+	LocalPlusCode string `json:"local_plus_code,omitempty"`
+	Locality      string `json:"locality,omitempty"`
+	Text          string `json:"text"`
+}
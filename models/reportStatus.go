@@ -0,0 +1,97 @@
+package models
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// The statuses of a report's lifecycle. A report is a ReportDraft until it
+// is submitted, when it becomes pending; reports submitted before statuses
+// were set have none, which means pending. Approved and verified are both
+// kept for the reports already moderated: approved reports were reviewed
+// by a moderator, verified ones confirmed by an ambassador.
+const (
+	ReportStatusPending     = "pending"
+	ReportStatusUnderReview = "under_review"
+	ReportStatusApproved    = "approved"
+	ReportStatusVerified    = "verified"
+	ReportStatusAccepted    = "accepted" // accepted for reward points
+	ReportStatusResolved    = "resolved"
+	ReportStatusRejected    = "rejected"
+)
+
+// PublishedReportStatuses are the statuses of reports that passed review:
+// they are shown to the public, shared with partners, exported as evidence
+// and counted as verified. Resolved and accepted reports were approved or
+// verified first.
+var PublishedReportStatuses = []string{ReportStatusApproved, ReportStatusVerified, ReportStatusAccepted, ReportStatusResolved}
+
+// IsPublishedReportStatus reports whether a report in status passed review
+func IsPublishedReportStatus(status string) bool {
+	status = NormalizeReportStatus(status)
+	for _, published := range PublishedReportStatuses {
+		if status == published {
+			return true
+		}
+	}
+	return false
+}
+
+// reportTransitions lists the statuses each status may move to. Withdrawn
+// and closed reports are final.
+var reportTransitions = map[string][]string{
+	ReportStatusPending:     {ReportStatusUnderReview, ReportStatusApproved, ReportStatusVerified, ReportStatusRejected, ReportStatusWithdrawn, ReportStatusClosed},
+	ReportStatusUnderReview: {ReportStatusPending, ReportStatusApproved, ReportStatusVerified, ReportStatusRejected, ReportStatusClosed},
+	ReportStatusApproved:    {ReportStatusAccepted, ReportStatusResolved, ReportStatusRejected, ReportStatusClosed},
+	ReportStatusVerified:    {ReportStatusAccepted, ReportStatusResolved, ReportStatusRejected, ReportStatusClosed},
+	ReportStatusAccepted:    {ReportStatusResolved, ReportStatusClosed},
+	ReportStatusResolved:    {ReportStatusUnderReview, ReportStatusClosed},
+	ReportStatusRejected:    {ReportStatusUnderReview},
+}
+
+// NormalizeReportStatus returns status as stored by the lifecycle, with no
+// status meaning pending
+func NormalizeReportStatus(status string) string {
+	status = strings.ToLower(strings.TrimSpace(status))
+	if status == "" {
+		return ReportStatusPending
+	}
+	return status
+}
+
+// CanTransitionReport reports whether a report in status from may move to
+// status to
+func CanTransitionReport(from, to string) bool {
+	from, to = NormalizeReportStatus(from), NormalizeReportStatus(to)
+	for _, next := range reportTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// ReportStatusTransitions returns the statuses a report in status may move to
+func ReportStatusTransitions(status string) []string {
+	return append([]string{}, reportTransitions[NormalizeReportStatus(status)]...)
+}
+
+// ReportStatusChange moves a report to another status
+type ReportStatusChange struct {
+	Status string `json:"status" binding:"required"`
+	Reason string `json:"reason" binding:"max=500"`
+}
+
+// ReportStatusTransition records a report moving from one status to
+// another, who moved it and why. ChangedBy is nil for changes the system
+// made, such as closing stale reports.
+type ReportStatusTransition struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	ReportID   uuid.UUID `gorm:"type:uuid;not null;index" json:"report_id"`
+	FromStatus string    `gorm:"not null" json:"from_status"`
+	ToStatus   string    `gorm:"not null;index" json:"to_status"`
+	ChangedBy  *uint     `json:"changed_by"`
+	Reason     string    `gorm:"type:text" json:"reason"`
+	CreatedAt  int64     `gorm:"index" json:"created_at"`
+}
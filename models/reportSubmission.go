@@ -0,0 +1,27 @@
+package models
+
+import "github.com/google/uuid"
+
+// The statuses of a report submission accepted while ingestion is queued
+const (
+	SubmissionQueued   = "queued"
+	SubmissionSaved    = "saved"
+	SubmissionRejected = "rejected" // failed a check when it was saved, and is not retried
+	SubmissionRetrying = "retrying" // saving failed and will be tried again
+	SubmissionFailed   = "failed"   // saving failed on every attempt; the report is kept for support to replay
+)
+
+// ReportSubmission follows a report accepted in queued ingestion until a
+// worker saves it. ID is the ID the report is saved under. Payload holds the
+// report as submitted until it is saved or rejected; it carries the
+// reporter's contact details, so it is kept encrypted.
+type ReportSubmission struct {
+	ID        uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID    uint      `gorm:"not null;index" json:"user_id"`
+	Status    string    `gorm:"not null;index" json:"status"`
+	Error     string    `gorm:"type:text" json:"error,omitempty"`
+	Attempts  int       `gorm:"not null;default:0" json:"attempts"`
+	Payload   string    `gorm:"type:text;serializer:encrypted" json:"-"`
+	CreatedAt int64     `json:"created_at"`
+	UpdatedAt int64     `json:"updated_at"`
+}
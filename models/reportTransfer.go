@@ -0,0 +1,33 @@
+package models
+
+import "github.com/google/uuid"
+
+// Who keeps the points a report earned before it was transferred
+const (
+	TransferRewardsToRecipient = "recipient"
+	TransferRewardsToReporter  = "reporter"
+)
+
+// ReportTransferRequest names the account a report is handed to, by ID,
+// email address or phone number
+type ReportTransferRequest struct {
+	UserID    uint   `json:"user_id"`
+	Email     string `json:"email"`
+	Telephone string `json:"telephone"`
+	Reason    string `json:"reason" binding:"required,max=500"`
+}
+
+// ReportTransfer records a report changing hands, such as a report filed
+// by call-centre staff on behalf of someone who later signed up
+type ReportTransfer struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	ReportID      uuid.UUID `gorm:"type:uuid;not null;index" json:"report_id"`
+	FromUserID    uint      `gorm:"not null;index" json:"from_user_id"`
+	ToUserID      uint      `gorm:"not null;index" json:"to_user_id"`
+	TransferredBy uint      `gorm:"not null" json:"transferred_by"`
+	Reason        string    `gorm:"type:text;not null" json:"reason"`
+	// RewardsMoved counts the rewards for the report that went to the new
+	// owner with it
+	RewardsMoved int64 `gorm:"not null;default:0" json:"rewards_moved"`
+	CreatedAt    int64 `gorm:"index" json:"created_at"`
+}
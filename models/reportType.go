@@ -14,6 +14,7 @@ type ReportType struct {
     Category             string           `json:"category" binding:"required"`
     StateName            string           `json:"state_name"`
     LGAName              string           `json:"lga_name"`
+    WardName             string           `json:"ward_name"`
     IncidentReportRating string           `json:"incident_report_rating"`
     DateOfIncidence      time.Time        `json:"date_of_incidence"`
     SubReports           []SubReport      `gorm:"foreignKey:ReportTypeID"`
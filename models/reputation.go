@@ -0,0 +1,44 @@
+package models
+
+// Reputation tiers, from least to most trusted. New reporters have too few
+// moderated reports to judge.
+const (
+	ReputationNew      = "new"
+	ReputationLow      = "low"
+	ReputationStandard = "standard"
+	ReputationTrusted  = "trusted"
+)
+
+// ReporterReputation scores how reliable a reporter has been, from 0 to
+// 100. Every count is weighted by age, so old history fades and the score
+// drifts back towards neutral without new activity.
+type ReporterReputation struct {
+	UserID uint    `gorm:"primaryKey" json:"user_id"`
+	Score  float64 `gorm:"not null;index" json:"score"`
+	Tier   string  `gorm:"not null;index" json:"tier"`
+	// VerifiedReports and RejectedReports weigh the reporter's moderated
+	// reports; Flags their reports flagged for recycled media
+	VerifiedReports float64 `json:"verified_reports"`
+	RejectedReports float64 `json:"rejected_reports"`
+	Flags           float64 `json:"flags"`
+	// AccurateVotes counts upvotes on reports later verified and downvotes
+	// on reports later rejected; InaccurateVotes the reverse
+	AccurateVotes   float64 `json:"accurate_votes"`
+	InaccurateVotes float64 `json:"inaccurate_votes"`
+	// IdentityVerified raises the score and tier of reporters whose NIN or
+	// BVN is verified
+	IdentityVerified bool  `json:"identity_verified"`
+	ComputedAt       int64 `json:"computed_at"`
+	// PrivilegesSuspendedUntil withholds the tier's privileges after a
+	// failed audit, whatever the score
+	PrivilegesSuspendedUntil int64 `json:"privileges_suspended_until,omitempty"`
+}
+
+// TriageReport is a report awaiting moderation with what is known of its
+// reporter
+type TriageReport struct {
+	Report     IncidentReport      `json:"report"`
+	Reputation *ReporterReputation `json:"reputation"`
+	// NetworkFlag is set when the report came through a VPN or datacenter
+	NetworkFlag string `json:"network_flag,omitempty"`
+}
@@ -0,0 +1,33 @@
+package models
+
+// Why an area's reports earn bonus points
+const (
+	BonusReasonConfigured    = "configured"
+	BonusReasonUnderReported = "under_reported"
+)
+
+// BonusCategory is a priority category whose reports earn bonus points
+type BonusCategory struct {
+	Category   string  `json:"category"`
+	Multiplier float64 `json:"multiplier"`
+}
+
+// BonusArea is an LGA whose reports earn bonus points, either set by the
+// operators or because few reports have come from it lately
+type BonusArea struct {
+	StateName string `json:"state_name"`
+	LGAName   string `json:"lga_name"`
+	// RecentReports is how many reports the LGA had within the scarcity
+	// window, for under-reported areas
+	RecentReports int64   `json:"recent_reports"`
+	Multiplier    float64 `json:"multiplier"`
+	Reason        string  `json:"reason"` // one of the BonusReason values
+}
+
+// RewardBonuses lists what earns bonus points at approval. A report's
+// category and area multipliers combine, up to MaxMultiplier.
+type RewardBonuses struct {
+	Categories    []BonusCategory `json:"categories"`
+	Areas         []BonusArea     `json:"areas"`
+	MaxMultiplier float64         `json:"max_multiplier"`
+}
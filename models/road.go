@@ -0,0 +1,46 @@
+package models
+
+import "github.com/google/uuid"
+
+// RoadSegment is a stretch of road, as the works ministry divides its
+// network, that road-condition reports are aggregated by
+type RoadSegment struct {
+	ID uint `gorm:"primaryKey" json:"id"`
+	PublicRef
+	Road      string `gorm:"not null;index" json:"road" binding:"required"`
+	Name      string `gorm:"not null" json:"name" binding:"required"`
+	StateName string `gorm:"index" json:"state_name"`
+	// Polyline is the segment's centre line in encoded polyline format
+	Polyline string `gorm:"type:text;not null" json:"polyline" binding:"required"`
+	LengthM  int    `json:"length_m"`
+	// The bounding box, for finding the segments a report may lie on
+	South     float64 `json:"-"`
+	West      float64 `json:"-"`
+	North     float64 `json:"-"`
+	East      float64 `json:"-"`
+	CreatedBy uint    `json:"created_by"`
+	CreatedAt int64   `json:"created_at"`
+}
+
+// ReportRoadSegment records that a road-condition report lies on a segment.
+// A report whose route runs along several segments is linked to each.
+type ReportRoadSegment struct {
+	ReportID  uuid.UUID `gorm:"type:uuid;primaryKey" json:"report_id"`
+	SegmentID uint      `gorm:"primaryKey;index" json:"segment_id"`
+	CreatedAt int64     `json:"created_at"`
+}
+
+// RoadSegmentCondition is a segment's road-condition reports in aggregate
+type RoadSegmentCondition struct {
+	SegmentID      uint    `json:"segment_id"`
+	Road           string  `json:"road"`
+	Name           string  `json:"name"`
+	StateName      string  `json:"state_name"`
+	LengthM        int     `json:"length_m"`
+	Reports        int64   `json:"reports"`
+	Verified       int64   `json:"verified"`
+	Unresolved     int64   `json:"unresolved"`
+	ReportsPerKm   float64 `json:"reports_per_km"`
+	TopIssue       string  `json:"top_issue"`
+	LastReportedAt int64   `json:"last_reported_at"`
+}
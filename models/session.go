@@ -0,0 +1,38 @@
+package models
+
+// Session is one sign-in, shared by the access and refresh tokens issued
+// for it through their sid claim. It ends after the idle timeout passes
+// without activity, at ExpiresAt, or when revoked.
+type Session struct {
+	ID     string `gorm:"primaryKey;type:varchar(36)"`
+	UserID uint   `gorm:"not null;index"`
+	// RefreshID names the one refresh token that may renew the session
+	RefreshID  string `gorm:"not null"`
+	CreatedAt  int64
+	LastSeenAt int64 `gorm:"not null"`
+	ExpiresAt  int64 `gorm:"not null"`
+	RevokedAt  int64
+}
+
+// RefreshRequest renews a session's tokens
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// StepUpRequest confirms the signed-in user's password before a sensitive
+// operation
+type StepUpRequest struct {
+	Password string `json:"password" binding:"required"`
+}
+
+// StepUpResponse carries a step-up token, sent back in the X-Step-Up-Token
+// header of sensitive requests
+type StepUpResponse struct {
+	StepUpToken string `json:"step_up_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// ChangeEmailRequest moves an account to a new email address
+type ChangeEmailRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
@@ -0,0 +1,47 @@
+package models
+
+// Purposes a short link is made for
+const (
+	ShortLinkShare        = "share"
+	ShortLinkNotification = "notification"
+	ShortLinkPoster       = "poster" // printed in QR codes, so never expires
+)
+
+// ShortLink redirects a short code, such as cx.ng/abc123, to a longer URL.
+// Links that expire stop redirecting at ExpiresAt; zero means never.
+type ShortLink struct {
+	ID            uint   `gorm:"primaryKey" json:"id"`
+	Code          string `gorm:"not null;uniqueIndex" json:"code"`
+	TargetURL     string `gorm:"not null;index:idx_short_links_target,priority:1" json:"target_url"`
+	Purpose       string `gorm:"not null;index:idx_short_links_target,priority:2" json:"purpose"`
+	ExpiresAt     int64  `gorm:"not null;default:0" json:"expires_at,omitempty"`
+	Clicks        int64  `gorm:"not null;default:0" json:"clicks"`
+	LastClickedAt int64  `json:"last_clicked_at,omitempty"`
+	CreatedAt     int64  `json:"created_at"`
+	URL           string `gorm:"-" json:"url"` // the short URL
+}
+
+// Expired reports whether the link has stopped redirecting at now
+func (l *ShortLink) Expired(now int64) bool {
+	return l.ExpiresAt != 0 && l.ExpiresAt <= now
+}
+
+// ShortLinkClick records one use of a short link
+type ShortLinkClick struct {
+	ID        uint   `gorm:"primaryKey"`
+	LinkID    uint   `gorm:"not null;index"`
+	ClickedAt int64  `gorm:"not null;index"`
+	Referrer  string `gorm:"type:varchar(255)"`
+}
+
+// ShortLinkStats is a link with its clicks per day, most recent first
+type ShortLinkStats struct {
+	Link  ShortLink         `json:"link"`
+	Daily []ShortLinkDayRow `json:"daily"`
+}
+
+// ShortLinkDayRow counts a link's clicks on one day, UTC
+type ShortLinkDayRow struct {
+	Day    string `json:"day"`
+	Clicks int64  `json:"clicks"`
+}
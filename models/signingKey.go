@@ -0,0 +1,14 @@
+package models
+
+// SigningKey is a key pair access and refresh tokens are signed with. The
+// newest key whose ActiveFrom has passed signs; newer keys are published
+// ahead of use so every server can validate their tokens, and older keys
+// keep validating the tokens they signed until those have expired.
+type SigningKey struct {
+	ID         string `gorm:"primaryKey;type:varchar(36)"` // kid header of the tokens it signs
+	Algorithm  string `gorm:"not null"`
+	PrivateKey string `gorm:"type:text;not null;serializer:encrypted" json:"-"`
+	PublicKey  string `gorm:"type:text;not null"`
+	ActiveFrom int64  `gorm:"not null;index"`
+	CreatedAt  int64
+}
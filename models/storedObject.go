@@ -0,0 +1,32 @@
+package models
+
+// Owners a stored object can belong to
+const (
+	// ObjectOwnerReport objects are media of the report or draft named by
+	// OwnerID
+	ObjectOwnerReport = "report"
+	// ObjectOwnerUpload objects are chunks of the resumable upload named by
+	// OwnerID
+	ObjectOwnerUpload = "upload"
+	// ObjectOwnerUser objects are profile images, owned for as long as a user
+	// shows them
+	ObjectOwnerUser = "user"
+	// ObjectOwnerPost objects are publication images, owned for as long as a
+	// post shows them
+	ObjectOwnerPost = "post"
+	// ObjectOwnerFeedback objects are screenshots sent with the feedback
+	// named by OwnerID
+	ObjectOwnerFeedback = "feedback"
+)
+
+// StoredObject records an object put in storage and what it belongs to, so
+// objects whose owner is gone can be found and removed
+type StoredObject struct {
+	ID        uint   `gorm:"primaryKey" json:"id"`
+	Key       string `gorm:"not null;uniqueIndex" json:"key"`
+	URL       string `gorm:"index" json:"url"`
+	Size      int64  `json:"size"`
+	OwnerType string `gorm:"not null;index:idx_stored_object_owner" json:"owner_type"`
+	OwnerID   string `gorm:"index:idx_stored_object_owner" json:"owner_id"`
+	CreatedAt int64  `gorm:"index" json:"created_at"`
+}
@@ -0,0 +1,47 @@
+package models
+
+// ReportingStreak is a user's run of consecutive days, in Lagos time, on
+// which they met their daily reporting goal
+type ReportingStreak struct {
+	UserID uint `gorm:"primaryKey;autoIncrement:false" json:"user_id"`
+	// DailyGoal is how many reports make a day count, or 0 for the default
+	DailyGoal int `gorm:"not null;default:0" json:"daily_goal"`
+	Current   int `gorm:"not null;default:0" json:"current"`
+	Longest   int `gorm:"not null;default:0" json:"longest"`
+	// StartDay and LastDay bound the current streak, as YYYY-MM-DD
+	StartDay string `json:"start_day"`
+	LastDay  string `gorm:"index" json:"last_day"`
+	// RemindedDay is the last day the user was warned their streak was at
+	// risk
+	RemindedDay string `json:"-"`
+	UpdatedAt   int64  `json:"updated_at"`
+}
+
+// StreakBonus is the bonus for a streak reaching a badge, earned over the
+// streak's days from FirstDay to LastDay. A badge's bonus is paid once for
+// any day, however the streak around it is later worked out.
+type StreakBonus struct {
+	Badge    string
+	FirstDay string
+	LastDay  string
+	Reward   Reward
+}
+
+// StreakProgress is a user's streak and today's progress towards their
+// daily goal
+type StreakProgress struct {
+	Current      int   `json:"current"`
+	Longest      int   `json:"longest"`
+	DailyGoal    int   `json:"daily_goal"`
+	TodayReports int64 `json:"today_reports"`
+	GoalMet      bool  `json:"goal_met"`
+	// AtRisk is set while the streak ends at midnight unless the user meets
+	// today's goal
+	AtRisk bool `json:"at_risk"`
+}
+
+// ReportDay counts a user's reports on one day, in Lagos time
+type ReportDay struct {
+	Day     string `json:"day"`
+	Reports int64  `json:"reports"`
+}
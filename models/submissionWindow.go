@@ -0,0 +1,25 @@
+package models
+
+// SubmissionWindow opens a category to new reports for a period, such as an
+// election-day category on polling day. A category with windows accepts
+// reports only while one of them is open; categories without any are always
+// open.
+type SubmissionWindow struct {
+	ID        uint   `gorm:"primaryKey" json:"id"`
+	Campaign  string `gorm:"not null;default:''" json:"campaign"`
+	Category  string `gorm:"not null;index" json:"category" binding:"required"`
+	OpensAt   int64  `gorm:"not null" json:"opens_at" binding:"required"`
+	ClosesAt  int64  `gorm:"not null" json:"closes_at" binding:"required"`
+	CreatedBy uint   `json:"created_by"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// CategoryAvailability tells clients whether a windowed category accepts
+// reports now, and the window that is open or opens next
+type CategoryAvailability struct {
+	Category string `json:"category"`
+	Open     bool   `json:"open"`
+	Campaign string `json:"campaign,omitempty"`
+	OpensAt  int64  `json:"opens_at,omitempty"`
+	ClosesAt int64  `json:"closes_at,omitempty"`
+}
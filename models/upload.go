@@ -0,0 +1,31 @@
+package models
+
+import "github.com/google/uuid"
+
+// Upload is a resumable media upload for a report draft. Bytes arrive in any
+// number of chunks; Offset is how many have been stored so far, and the
+// client resumes an interrupted upload from there.
+type Upload struct {
+	ID       uuid.UUID `gorm:"type:uuid;primaryKey" json:"id"`
+	UserID   uint      `gorm:"not null;index" json:"user_id"`
+	DraftID  uuid.UUID `gorm:"type:uuid;not null;index" json:"draft_id"`
+	Filename string    `json:"filename"`
+	FileType string    `json:"file_type"`
+	Length   int64     `gorm:"not null" json:"length"`
+	Offset   int64     `gorm:"not null;default:0" json:"offset"`
+	// MediaID is set once the last chunk arrives and the assembled file has
+	// been processed
+	MediaID     string `json:"media_id,omitempty"`
+	CompletedAt int64  `json:"completed_at,omitempty"`
+	CreatedAt   int64  `gorm:"index" json:"created_at"`
+	UpdatedAt   int64  `json:"updated_at"`
+}
+
+// UploadChunk is one stored piece of an upload, starting at Offset
+type UploadChunk struct {
+	ID       uint      `gorm:"primaryKey"`
+	UploadID uuid.UUID `gorm:"type:uuid;not null;index"`
+	Offset   int64     `gorm:"not null"`
+	Size     int64     `gorm:"not null"`
+	Key      string    `gorm:"not null"`
+}
@@ -8,7 +8,9 @@ import (
 
 	goval "github.com/go-passwd/validator"
 	"github.com/google/uuid"
+	"github.com/techagentng/citizenx/pii"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 
 	// "github.com/go-playground/locales/en"
 	ut "github.com/go-playground/universal-translator"
@@ -22,29 +24,54 @@ import (
 // User represents a user of the application
 type User struct {
 	Model
-	Fullname          string            `json:"fullname" binding:"required,min=2"`
-	Username          string            `json:"username" binding:"required,min=2"`
-	Telephone         string            `json:"telephone" gorm:"unique;default:null" binding:"required"`
-	Email             string            `json:"email" gorm:"unique;not null" binding:"required,email"`
-	Password          string            `json:"password,omitempty" gorm:"-"`
-	HashedPassword    string            `json:"-"`
-	IsEmailActive     bool              `json:"-"`
-	IsSocial          bool              `json:"-"`
-	AccessToken       string            `json:"-"`
-	IsVerified        bool              `json:"is_verified"`
-	IsAnonymous       bool              `json:"is_anonymous"`
-	IsJournalist      bool              `json:"is_journalist"`
-	AdminStatus       bool              `json:"is_admin" gorm:"foreignKey:Status"` // admin
-	Notifications     []Notification    `gorm:"foreignKey:UserID"`
-	ThumbNailURL      string            `json:"thumbnail_url,omitempty"`
-	MacAddress        string            `json:"mac_address"`
-	LGAName           string            `gorm:"foreignKey:Name"`
-	Online            bool              `json:"online"`
-	Upvotes           int               `json:"up_vote"`
-	Downvotes         int               `json:"down_vote"`
-	RoleID            uuid.UUID         `gorm:"type:uuid" json:"role_id"`
-	Role              Role              `gorm:"foreignKey:RoleID" json:"role"`
-	BookmarkedReports []*IncidentReport `gorm:"many2many:incident_report_user;" json:"bookmarked_reports"`
+	PublicRef
+	Fullname           string            `json:"fullname" binding:"required,min=2"`
+	Username           string            `json:"username" binding:"required,min=2"`
+	Telephone          string            `json:"telephone" gorm:"serializer:encrypted;default:null" binding:"required"`
+	TelephoneIndex     *string           `json:"-" gorm:"uniqueIndex"`                                      // blind index of Telephone for lookups
+	Email              string            `json:"email" gorm:"unique;default:null" binding:"required,email"` // empty for accounts created by phone sign-in
+	Password           string            `json:"password,omitempty" gorm:"-"`
+	HashedPassword     string            `json:"-"`
+	IsEmailActive      bool              `json:"-"`
+	IsSocial           bool              `json:"-"`
+	AccessToken        string            `json:"-"`
+	IsVerified         bool              `json:"is_verified"`
+	IsAnonymous        bool              `json:"is_anonymous"`
+	IsJournalist       bool              `json:"is_journalist"`
+	AdminStatus        bool              `json:"is_admin" gorm:"foreignKey:Status"` // admin
+	Notifications      []Notification    `gorm:"foreignKey:UserID"`
+	ThumbNailURL       string            `json:"thumbnail_url,omitempty"`
+	MacAddress         string            `json:"mac_address" gorm:"serializer:encrypted"`
+	MacAddressIndex    *string           `json:"-" gorm:"index"` // blind index of MacAddress for lookups
+	LGAName            string            `gorm:"foreignKey:Name"`
+	Online             bool              `json:"online"`
+	Upvotes            int               `json:"up_vote"`
+	Downvotes          int               `json:"down_vote"`
+	RoleID             uuid.UUID         `gorm:"type:uuid" json:"role_id"`
+	Role               Role              `gorm:"foreignKey:RoleID" json:"role"`
+	BookmarkedReports  []*IncidentReport `gorm:"many2many:incident_report_user;" json:"bookmarked_reports"`
+	AgencyID           *uint             `gorm:"index" json:"agency_id,omitempty"`                          // set for staff of a responding agency
+	AmbassadorLGA      string            `gorm:"index;not null;default:''" json:"ambassador_lga,omitempty"` // set for the ambassador of an LGA
+	Locale             string            `json:"locale"`                                                    // how dates and amounts are written for the user; empty for the default
+	PhoneOnly          bool              `json:"phone_only"`                                                // created by phone sign-in and not yet completed with an email and password
+	DeactivatedAt      *int64            `json:"deactivated_at,omitempty" gorm:"index"`                     // set while the user has put the account to sleep; signing in clears it
+	DateOfBirth        string            `json:"date_of_birth,omitempty" gorm:"type:varchar(10)"`           // YYYY-MM-DD
+	RestrictedUntil    int64             `json:"restricted_until,omitempty" gorm:"not null;default:0"`      // the user is in restricted mode, for minors, until then
+	IdentityVerifiedAt *int64            `json:"identity_verified_at,omitempty" gorm:"index"`               // set while the user's NIN or BVN is verified: the verified citizen badge
+}
+
+// Restricted reports whether the user is in restricted mode: no public
+// profile, anonymous reports and no graphic categories
+func (u *User) Restricted(now time.Time) bool {
+	return u.RestrictedUntil > now.Unix()
+}
+
+// BeforeSave keeps the blind indexes of the telephone and MAC address in
+// step with them
+func (u *User) BeforeSave(tx *gorm.DB) error {
+	u.TelephoneIndex = pii.BlindIndex(u.Telephone)
+	u.MacAddressIndex = pii.BlindIndex(u.MacAddress)
+	return nil
 }
 
 type Admin struct {
@@ -124,6 +151,10 @@ type EditProfileResponse struct {
 	Phone    string `json:"phone"`
 	State    string `json:"state"`
 	Lga      string `json:"lga"`
+	Locale   string `json:"locale"`
+	// DateOfBirth can be set once, by accounts created without one
+	DateOfBirth     string `json:"date_of_birth"`
+	RestrictedUntil int64  `json:"-"`
 }
 type LoginRequest struct {
 	Email    string `json:"email" binding:"required,email"`
@@ -132,9 +163,17 @@ type LoginRequest struct {
 
 type LoginRequestMacAddress struct {
 	Model
-	MacAddress string `json:"mac_address"`
-	Token      string `json:"token"`
+	MacAddress      string  `json:"mac_address" gorm:"serializer:encrypted"`
+	MacAddressIndex *string `json:"-" gorm:"index"` // blind index of MacAddress for lookups
+	Token           string  `json:"token"`
 }
+
+// BeforeSave keeps the MAC address's blind index in step with it
+func (m *LoginRequestMacAddress) BeforeSave(tx *gorm.DB) error {
+	m.MacAddressIndex = pii.BlindIndex(m.MacAddress)
+	return nil
+}
+
 type ForgotPassword struct {
 	Email string `json:"email" binding:"required,email"`
 }
@@ -166,6 +205,12 @@ type LoginResponse struct {
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
 	RoleID       string `json:"role_id"`
+	// StepUpToken lets a fresh sign-in perform sensitive operations without
+	// confirming the password again
+	StepUpToken      string `json:"step_up_token,omitempty"`
+	SessionExpiresAt int64  `json:"session_expires_at,omitempty"`
+	// Reactivated is set when signing in woke a deactivated account
+	Reactivated bool `json:"reactivated,omitempty"`
 }
 
 // VerifyPassword verifies the collected password with the user's hashed password
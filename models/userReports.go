@@ -0,0 +1,9 @@
+package models
+
+// UserReportPage is a page of a user's own reports, with how many of their
+// reports matching the same filters are in each status
+type UserReportPage struct {
+	Reports []IncidentReport `json:"reports"`
+	Counts  map[string]int64 `json:"counts"`
+	Page    int              `json:"page"`
+}
@@ -0,0 +1,39 @@
+package models
+
+// UserStats is the summary of a user's reporting shown on their profile
+type UserStats struct {
+	// ReportsByStatus counts the user's reports by moderation status, with
+	// reports never moderated counted as pending
+	ReportsByStatus map[string]int64 `json:"reports_by_status"`
+	TotalReports    int64            `json:"total_reports"`
+	TotalViews      int64            `json:"total_views"`
+	// Endorsements counts the upvotes and likes the user's reports received
+	Endorsements  int64           `json:"endorsements"`
+	TotalPoints   int64           `json:"total_points"`
+	PointsByMonth []MonthlyPoints `json:"points_by_month"`
+	LGAName       string          `json:"lga_name,omitempty"`
+	// LGARank is the user's place by points among the users of their LGA,
+	// or 0 when they have not set one
+	LGARank  int64 `json:"lga_rank,omitempty"`
+	LGAUsers int64 `json:"lga_users,omitempty"`
+	// IdentityVerified is set while the user's NIN or BVN is verified
+	IdentityVerified bool            `json:"identity_verified"`
+	Streak           StreakProgress  `json:"streak"`
+	Badges           []BadgeProgress `json:"badges"`
+	ComputedAt       int64           `json:"computed_at"`
+}
+
+// MonthlyPoints is the reward points a user earned in one month
+type MonthlyPoints struct {
+	Month  string `json:"month"` // YYYY-MM, in Lagos time
+	Points int64  `json:"points"`
+}
+
+// BadgeProgress is how far a user is towards a badge
+type BadgeProgress struct {
+	Badge    string `json:"badge"`
+	Name     string `json:"name"`
+	Progress int64  `json:"progress"`
+	Target   int64  `json:"target"`
+	Earned   bool   `json:"earned"`
+}
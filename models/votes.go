@@ -1,9 +1,30 @@
 package models
 
-// Reward represents rewards earned by users
+import "github.com/google/uuid"
+
+// The values of a vote on a report
+const (
+	VoteUp   = 1
+	VoteDown = -1
+)
+
+// ReportVote is a user's upvote or downvote of a report. A user has at
+// most one vote on a report, which they may change or retract.
+type ReportVote struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	ReportID  uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_report_votes_report_user,priority:1" json:"report_id"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_report_votes_report_user,priority:2;index" json:"user_id"`
+	Value     int       `gorm:"not null" json:"value"`
+	CreatedAt int64     `json:"created_at"`
+	UpdatedAt int64     `json:"updated_at"`
+}
+
+// Votes records a user's upvote or downvote of a report, at most one of
+// each per user and report. It held votes before ReportVote and is only
+// read to carry them over.
 type Votes struct {
 	Model
-	UserID   uint   `json:"user_id" gorm:"foreignKey:UserID"`
-	ReportID string `json:"report_type_id"`
-	VoteType string `json:"vote_type"`
+	UserID   uint   `json:"user_id" gorm:"foreignKey:UserID;uniqueIndex:idx_votes_user_report_type,priority:1"`
+	ReportID string `json:"report_type_id" gorm:"uniqueIndex:idx_votes_user_report_type,priority:2"`
+	VoteType string `json:"vote_type" gorm:"uniqueIndex:idx_votes_user_report_type,priority:3"`
 }
@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WarehousePartition records one day of a dataset exported to the data
+// warehouse prefix. Days without data are recorded with no file so the
+// export does not revisit them.
+type WarehousePartition struct {
+	ID            uint      `gorm:"primaryKey" json:"-"`
+	Dataset       string    `gorm:"not null;uniqueIndex:idx_warehouse_partitions_day,priority:1" json:"dataset"`
+	SchemaVersion int       `gorm:"not null;uniqueIndex:idx_warehouse_partitions_day,priority:2" json:"schema_version"`
+	PartitionDate time.Time `gorm:"type:date;not null;uniqueIndex:idx_warehouse_partitions_day,priority:3" json:"partition_date"`
+	Key           string    `json:"key,omitempty"`
+	Rows          int64     `json:"rows"`
+	Bytes         int64     `json:"bytes"`
+	ExportedAt    time.Time `json:"exported_at"`
+}
+
+// WarehouseDataset describes an exported dataset for warehouse loaders:
+// where its files are, the columns of its current schema and the
+// partitions written so far
+type WarehouseDataset struct {
+	Name          string               `json:"name"`
+	SchemaVersion int                  `json:"schema_version"`
+	Prefix        string               `json:"prefix"`
+	Columns       []WarehouseColumn    `json:"columns"`
+	Partitions    []WarehousePartition `json:"partitions"`
+}
+
+// WarehouseColumn is one column of a dataset's Parquet schema
+type WarehouseColumn struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	Optional bool   `json:"optional"`
+}
+
+// WarehousePseudonym stands in for a user in the warehouse exports.
+// Deleting the account deletes the pseudonym, which leaves the rows already
+// exported under it with no way back to the user.
+type WarehousePseudonym struct {
+	UserID    uint      `gorm:"primaryKey;autoIncrement:false"`
+	Pseudonym uuid.UUID `gorm:"type:uuid;not null;uniqueIndex;default:uuid_generate_v4()"`
+}
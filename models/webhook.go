@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// Webhook delivery statuses
+const (
+	WebhookPending   = "pending"
+	WebhookDelivered = "delivered"
+	// WebhookFailed deliveries ran out of attempts and wait for an admin to
+	// replay them
+	WebhookFailed = "failed"
+)
+
+// WebhookSubscription sends report lifecycle events to a partner's URL.
+// Empty Events means every event offered. Payloads are signed with Secret,
+// which the partner is shown once, when the subscription is created.
+type WebhookSubscription struct {
+	ID        uint     `gorm:"primaryKey" json:"id"`
+	Name      string   `gorm:"not null" json:"name"`
+	URL       string   `gorm:"not null" json:"url"`
+	Secret    string   `gorm:"not null;serializer:encrypted" json:"-"`
+	Events    []string `gorm:"type:text;serializer:json" json:"events"`
+	CreatedBy uint     `json:"created_by"`
+	CreatedAt int64    `json:"created_at"`
+}
+
+// Wants reports whether the subscriber asked for events called name
+func (w *WebhookSubscription) Wants(name string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, event := range w.Events {
+		if event == name {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookSubscriptionRequest creates a subscription
+type WebhookSubscriptionRequest struct {
+	Name   string   `json:"name" binding:"required"`
+	URL    string   `json:"url" binding:"required,url"`
+	Events []string `json:"events"`
+}
+
+// WebhookDelivery is one event sent, or to be sent, to one subscriber, and
+// the log of how sending it went
+type WebhookDelivery struct {
+	ID             uint   `gorm:"primaryKey" json:"id"`
+	SubscriptionID uint   `gorm:"not null;uniqueIndex:idx_webhook_deliveries_event" json:"subscription_id"`
+	EventName      string `gorm:"not null" json:"event"`
+	// DedupKey is the key of the event delivered, so an event handed to the
+	// subscriber twice is sent once
+	DedupKey       string     `gorm:"not null;uniqueIndex:idx_webhook_deliveries_event" json:"-"`
+	Payload        string     `gorm:"type:jsonb;not null" json:"payload"`
+	Status         string     `gorm:"not null;index" json:"status"`
+	Attempts       int        `gorm:"not null;default:0" json:"attempts"`
+	ResponseStatus int        `json:"response_status,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	NextAttemptAt  time.Time  `gorm:"not null;index" json:"next_attempt_at"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
@@ -0,0 +1,79 @@
+// Package outbox relays domain events saved in the outbox table to the event
+// bus subscribers, giving them at-least-once delivery.
+package outbox
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/events"
+	"github.com/techagentng/citizenx/models"
+)
+
+const (
+	defaultInterval  = 2 * time.Second
+	defaultBatchSize = 100
+)
+
+// Dispatcher delivers an event to its subscribers, failing if any of them did.
+type Dispatcher interface {
+	Dispatch(ctx context.Context, event events.Event) error
+}
+
+// Relay polls the outbox and dispatches due events. An event is marked
+// published only once every subscriber has handled it, so a crash between
+// the two means it is delivered again; subscribers use the event's dedup key
+// to ignore repeats.
+type Relay struct {
+	repo       db.OutboxRepository
+	dispatcher Dispatcher
+	interval   time.Duration
+	batchSize  int
+}
+
+// NewRelay creates a relay from the outbox to dispatcher.
+func NewRelay(repo db.OutboxRepository, dispatcher Dispatcher) *Relay {
+	return &Relay{
+		repo:       repo,
+		dispatcher: dispatcher,
+		interval:   defaultInterval,
+		batchSize:  defaultBatchSize,
+	}
+}
+
+// Run relays events until ctx is cancelled.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		r.drain(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// drain relays batches until the outbox has nothing more that is due.
+func (r *Relay) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		relayed, err := r.repo.RelayPending(r.batchSize, func(row *models.OutboxEvent) error {
+			event, err := events.Decode(row.EventName, []byte(row.Payload))
+			if err != nil {
+				return err
+			}
+			return r.dispatcher.Dispatch(ctx, event)
+		})
+		if err != nil {
+			log.Printf("outbox relay failed: %v", err)
+			return
+		}
+		if relayed < r.batchSize {
+			return
+		}
+	}
+}
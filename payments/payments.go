@@ -0,0 +1,253 @@
+// Package payments pays reporters in monitoring programs through a payment
+// provider: resolving bank accounts, sending transfers and reading the
+// provider's notifications of how they ended.
+package payments
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/techagentng/citizenx/httpclient"
+)
+
+// Transfer outcomes reported by the provider
+const (
+	TransferPending  = "pending"
+	TransferSuccess  = "success"
+	TransferFailed   = "failed"
+	TransferReversed = "reversed"
+	// TransferNeedsOTP is a transfer the provider holds until someone
+	// finalises it with an OTP, which it does while OTPs are enabled for
+	// transfers on the account
+	TransferNeedsOTP = "otp"
+)
+
+var (
+	// ErrAccountNotFound is returned when the bank has no account with the
+	// number given.
+	ErrAccountNotFound = errors.New("payments: account not found")
+	// ErrTransferNotFound is returned when the provider has no transfer
+	// with the reference given.
+	ErrTransferNotFound = errors.New("payments: transfer not found")
+)
+
+// Bank is a bank transfers can be sent to
+type Bank struct {
+	Code string `json:"code"`
+	Name string `json:"name"`
+}
+
+// Transfer is money sent to a bank account
+type Transfer struct {
+	Reference     string // ours, unique per payout, so retries are not paid twice
+	AmountKobo    int64
+	AccountName   string
+	AccountNumber string
+	BankCode      string
+	Narration     string
+}
+
+// TransferUpdate is the provider telling us how a transfer ended
+type TransferUpdate struct {
+	Reference string
+	Status    string
+	Reason    string
+}
+
+// Provider sends transfers.
+type Provider interface {
+	Banks(ctx context.Context) ([]Bank, error)
+	// ResolveAccount returns the name on the account, which the user
+	// confirms before it is saved
+	ResolveAccount(ctx context.Context, accountNumber, bankCode string) (string, error)
+	// Send starts a transfer, returning the provider's reference and its
+	// status so far
+	Send(ctx context.Context, transfer Transfer) (string, string, error)
+	// FindTransfer looks up the transfer with our reference, returning the
+	// provider's reference and its status, or ErrTransferNotFound. A Send
+	// that errs may still have started the transfer.
+	FindTransfer(ctx context.Context, reference string) (string, string, error)
+	// ParseWebhook checks a notification's signature and reads it, returning
+	// nil for notifications not about transfers
+	ParseWebhook(body []byte, signature string) (*TransferUpdate, error)
+}
+
+// Paystack pays through the Paystack Transfers API.
+type Paystack struct {
+	secretKey string
+	http      *http.Client
+}
+
+// NewPaystack returns a provider for the Paystack account, or nil when no
+// key is configured.
+func NewPaystack(secretKey string) *Paystack {
+	if secretKey == "" {
+		return nil
+	}
+	return &Paystack{secretKey: secretKey, http: httpclient.New("paystack", httpclient.Options{Timeout: 15 * time.Second})}
+}
+
+// call sends a request to the Paystack API and decodes the data of its
+// response into out
+func (p *Paystack) call(ctx context.Context, method, path string, body interface{}, out interface{}) (int, error) {
+	var reader io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return 0, err
+		}
+		reader = bytes.NewReader(encoded)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, "https://api.paystack.co"+path, reader)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.secretKey)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := p.http.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("calling paystack: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var envelope struct {
+		Status  bool            `json:"status"`
+		Message string          `json:"message"`
+		Data    json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return resp.StatusCode, fmt.Errorf("decoding paystack response: %w", err)
+	}
+	if !envelope.Status || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("paystack %s %s: %s", method, path, envelope.Message)
+	}
+	if out != nil {
+		return resp.StatusCode, json.Unmarshal(envelope.Data, out)
+	}
+	return resp.StatusCode, nil
+}
+
+func (p *Paystack) Banks(ctx context.Context) ([]Bank, error) {
+	var banks []Bank
+	_, err := p.call(ctx, http.MethodGet, "/bank?country=nigeria&currency=NGN&perPage=100", nil, &banks)
+	return banks, err
+}
+
+func (p *Paystack) ResolveAccount(ctx context.Context, accountNumber, bankCode string) (string, error) {
+	var account struct {
+		AccountName string `json:"account_name"`
+	}
+	query := url.Values{"account_number": {accountNumber}, "bank_code": {bankCode}}
+	status, err := p.call(ctx, http.MethodGet, "/bank/resolve?"+query.Encode(), nil, &account)
+	if status == http.StatusUnprocessableEntity || status == http.StatusBadRequest || status == http.StatusNotFound {
+		return "", ErrAccountNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return account.AccountName, nil
+}
+
+// Send creates a transfer recipient for the account, then the transfer
+func (p *Paystack) Send(ctx context.Context, transfer Transfer) (string, string, error) {
+	var recipient struct {
+		RecipientCode string `json:"recipient_code"`
+	}
+	if _, err := p.call(ctx, http.MethodPost, "/transferrecipient", map[string]string{
+		"type":           "nuban",
+		"name":           transfer.AccountName,
+		"account_number": transfer.AccountNumber,
+		"bank_code":      transfer.BankCode,
+		"currency":       "NGN",
+	}, &recipient); err != nil {
+		return "", "", err
+	}
+	var sent struct {
+		TransferCode string `json:"transfer_code"`
+		Status       string `json:"status"`
+	}
+	if _, err := p.call(ctx, http.MethodPost, "/transfer", map[string]interface{}{
+		"source":    "balance",
+		"amount":    transfer.AmountKobo,
+		"recipient": recipient.RecipientCode,
+		"reference": transfer.Reference,
+		"reason":    transfer.Narration,
+	}, &sent); err != nil {
+		return "", "", err
+	}
+	return sent.TransferCode, transferStatus(sent.Status), nil
+}
+
+func (p *Paystack) FindTransfer(ctx context.Context, reference string) (string, string, error) {
+	var found struct {
+		TransferCode string `json:"transfer_code"`
+		Status       string `json:"status"`
+	}
+	status, err := p.call(ctx, http.MethodGet, "/transfer/verify/"+url.PathEscape(reference), nil, &found)
+	if status == http.StatusNotFound {
+		return "", "", ErrTransferNotFound
+	}
+	if err != nil {
+		return "", "", err
+	}
+	return found.TransferCode, transferStatus(found.Status), nil
+}
+
+// ParseWebhook checks the x-paystack-signature header, an HMAC-SHA512 of
+// the body keyed with the secret key
+func (p *Paystack) ParseWebhook(body []byte, signature string) (*TransferUpdate, error) {
+	mac := hmac.New(sha512.New, []byte(p.secretKey))
+	mac.Write(body)
+	if !hmac.Equal([]byte(hex.EncodeToString(mac.Sum(nil))), []byte(signature)) {
+		return nil, errors.New("payments: invalid webhook signature")
+	}
+	var event struct {
+		Event string `json:"event"`
+		Data  struct {
+			Reference string `json:"reference"`
+			Status    string `json:"status"`
+			Reason    string `json:"reason"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("decoding paystack webhook: %w", err)
+	}
+	var status string
+	switch event.Event {
+	case "transfer.success":
+		status = TransferSuccess
+	case "transfer.failed":
+		status = TransferFailed
+	case "transfer.reversed":
+		status = TransferReversed
+	default:
+		return nil, nil
+	}
+	return &TransferUpdate{Reference: event.Data.Reference, Status: status, Reason: event.Data.Reason}, nil
+}
+
+// transferStatus maps Paystack's transfer statuses to ours
+func transferStatus(status string) string {
+	switch status {
+	case "success":
+		return TransferSuccess
+	case "failed", "abandoned", "blocked", "rejected":
+		return TransferFailed
+	case "reversed":
+		return TransferReversed
+	case "otp":
+		return TransferNeedsOTP
+	default:
+		return TransferPending
+	}
+}
@@ -0,0 +1,25 @@
+package payments
+
+import "testing"
+
+func TestTransferStatus(t *testing.T) {
+	for _, tc := range []struct {
+		paystack string
+		want     string
+	}{
+		{"success", TransferSuccess},
+		{"failed", TransferFailed},
+		{"abandoned", TransferFailed},
+		{"blocked", TransferFailed},
+		{"rejected", TransferFailed},
+		{"reversed", TransferReversed},
+		{"otp", TransferNeedsOTP},
+		{"pending", TransferPending},
+		{"received", TransferPending},
+		{"", TransferPending},
+	} {
+		if got := transferStatus(tc.paystack); got != tc.want {
+			t.Errorf("transferStatus(%q) = %q, want %q", tc.paystack, got, tc.want)
+		}
+	}
+}
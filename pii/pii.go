@@ -0,0 +1,210 @@
+// Package pii encrypts personal data stored in the database. Columns tagged
+// gorm:"serializer:encrypted" are sealed with AES-GCM under the active key
+// and opened with whichever configured key sealed them, so keys can be
+// rotated by adding a new active key and re-running the encrypt-pii
+// command. Columns that need equality lookups keep a blind index beside
+// them, an HMAC of the plaintext under a separate, fixed key.
+package pii
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm/schema"
+)
+
+// prefix marks sealed values; anything else is plaintext written before
+// encryption was enabled
+const prefix = "enc:"
+
+// MinIndexKeyLength is the shortest blind index key accepted alongside
+// encryption keys. Phone numbers are few enough to brute force through an
+// HMAC under a short key.
+const MinIndexKeyLength = 32
+
+// ErrUnknownKey is returned when a value was sealed with a key that is no
+// longer configured.
+var ErrUnknownKey = errors.New("pii: value sealed with an unknown key")
+
+type keyring struct {
+	active   string
+	keys     map[string]cipher.AEAD
+	indexKey []byte
+}
+
+var (
+	mu   sync.RWMutex
+	ring = &keyring{}
+)
+
+func init() {
+	schema.RegisterSerializer("encrypted", Serializer{})
+}
+
+// Configure sets the encryption keys, written "id:base64key" and separated
+// by commas with the active key first, and the blind index key. Keys are
+// 16, 24 or 32 bytes, and the index key at least MinIndexKeyLength. Without
+// keys values are stored as plaintext.
+func Configure(keys, indexKey string) error {
+	next := &keyring{keys: map[string]cipher.AEAD{}, indexKey: []byte(indexKey)}
+	for _, entry := range strings.Split(keys, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, encoded, ok := strings.Cut(entry, ":")
+		if !ok || id == "" {
+			return fmt.Errorf("pii: key %q is not written id:base64key", entry)
+		}
+		raw, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return fmt.Errorf("pii: key %s: %w", id, err)
+		}
+		block, err := aes.NewCipher(raw)
+		if err != nil {
+			return fmt.Errorf("pii: key %s: %w", id, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return fmt.Errorf("pii: key %s: %w", id, err)
+		}
+		if next.active == "" {
+			next.active = id
+		}
+		next.keys[id] = aead
+	}
+	if next.active != "" && len(next.indexKey) < MinIndexKeyLength {
+		return fmt.Errorf("pii: the blind index key must be at least %d bytes when encryption keys are set", MinIndexKeyLength)
+	}
+
+	mu.Lock()
+	ring = next
+	mu.Unlock()
+	return nil
+}
+
+func current() *keyring {
+	mu.RLock()
+	defer mu.RUnlock()
+	return ring
+}
+
+// Enabled reports whether an encryption key is configured
+func Enabled() bool {
+	return current().active != ""
+}
+
+// ActivePrefix is how values sealed with the active key begin
+func ActivePrefix() string {
+	return prefix + current().active + ":"
+}
+
+// Encrypt seals plaintext with the active key. Empty values, and every
+// value while no key is configured, are returned as they are.
+func Encrypt(plaintext string) (string, error) {
+	r := current()
+	if plaintext == "" || r.active == "" {
+		return plaintext, nil
+	}
+	aead := r.keys[r.active]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(r.active))
+	return prefix + r.active + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value sealed by Encrypt. Values without the sealed
+// prefix are returned as they are.
+func Decrypt(value string) (string, error) {
+	if !strings.HasPrefix(value, prefix) {
+		return value, nil
+	}
+	id, encoded, ok := strings.Cut(strings.TrimPrefix(value, prefix), ":")
+	if !ok {
+		return "", errors.New("pii: malformed sealed value")
+	}
+	aead, ok := current().keys[id]
+	if !ok {
+		return "", ErrUnknownKey
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(encoded)
+	if err != nil || len(sealed) < aead.NonceSize() {
+		return "", errors.New("pii: malformed sealed value")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, []byte(id))
+	if err != nil {
+		return "", fmt.Errorf("pii: opening value: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// BlindIndex returns the lookup value of plaintext, or nil for empty
+// values so unique indexes ignore them
+func BlindIndex(plaintext string) *string {
+	if plaintext == "" {
+		return nil
+	}
+	mac := hmac.New(sha256.New, current().indexKey)
+	mac.Write([]byte(plaintext))
+	index := hex.EncodeToString(mac.Sum(nil))
+	return &index
+}
+
+// BlindIndexes returns the lookup values of several plaintexts
+func BlindIndexes(plaintexts []string) []string {
+	indexes := make([]string, 0, len(plaintexts))
+	for _, plaintext := range plaintexts {
+		if index := BlindIndex(plaintext); index != nil {
+			indexes = append(indexes, *index)
+		}
+	}
+	return indexes
+}
+
+// Serializer seals string fields on write and opens them on read
+type Serializer struct{}
+
+// Scan implements schema.SerializerInterface
+func (Serializer) Scan(ctx context.Context, field *schema.Field, dst reflect.Value, dbValue interface{}) error {
+	var value string
+	switch v := dbValue.(type) {
+	case nil:
+	case []byte:
+		value = string(v)
+	case string:
+		value = v
+	default:
+		return fmt.Errorf("pii: cannot scan %T into %s", dbValue, field.Name)
+	}
+	plaintext, err := Decrypt(value)
+	if err != nil {
+		return err
+	}
+	return field.Set(ctx, dst, plaintext)
+}
+
+// Value implements schema.SerializerValuerInterface
+func (Serializer) Value(ctx context.Context, field *schema.Field, dst reflect.Value, fieldValue interface{}) (interface{}, error) {
+	plaintext, ok := fieldValue.(string)
+	if !ok {
+		return nil, fmt.Errorf("pii: %s is %T, only strings can be encrypted", field.Name, fieldValue)
+	}
+	if plaintext == "" && field.HasDefaultValue && field.DefaultValueInterface == nil {
+		return nil, nil
+	}
+	return Encrypt(plaintext)
+}
@@ -0,0 +1,126 @@
+package pii
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+)
+
+var (
+	oldKey   = "k1:" + base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+	newKey   = "k2:" + base64.StdEncoding.EncodeToString([]byte("fedcba9876543210fedcba9876543210"))
+	indexKey = strings.Repeat("i", MinIndexKeyLength)
+)
+
+// configure sets the keys for the rest of the test, back to plaintext after
+func configure(t *testing.T, keys, indexKey string) {
+	t.Helper()
+	if err := Configure(keys, indexKey); err != nil {
+		t.Fatalf("configuring keys: %v", err)
+	}
+	t.Cleanup(func() { Configure("", "") })
+}
+
+func TestEncryptRoundTrip(t *testing.T) {
+	configure(t, oldKey, indexKey)
+
+	sealed, err := Encrypt("+2348012345678")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(sealed, ActivePrefix()) || strings.Contains(sealed, "8012345678") {
+		t.Fatalf("got sealed value %q", sealed)
+	}
+	if plaintext, err := Decrypt(sealed); err != nil || plaintext != "+2348012345678" {
+		t.Fatalf("got %q, %v opening the sealed value", plaintext, err)
+	}
+	// Values written before encryption was enabled read as they are
+	if plaintext, err := Decrypt("08012345678"); err != nil || plaintext != "08012345678" {
+		t.Fatalf("got %q, %v reading plaintext", plaintext, err)
+	}
+}
+
+func TestDecryptWithRotatedKey(t *testing.T) {
+	configure(t, oldKey, indexKey)
+	sealed, err := Encrypt("ada@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A new active key still opens values sealed with the old one
+	configure(t, newKey+","+oldKey, indexKey)
+	if plaintext, err := Decrypt(sealed); err != nil || plaintext != "ada@example.com" {
+		t.Fatalf("got %q, %v opening a value sealed with the old key", plaintext, err)
+	}
+	resealed, err := Encrypt("ada@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(resealed, "enc:k2:") {
+		t.Fatalf("got %q, want a value sealed with the active key", resealed)
+	}
+
+	// Once the old key is dropped its values cannot be opened
+	configure(t, newKey, indexKey)
+	if _, err := Decrypt(sealed); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("got %v opening a value sealed with a dropped key, want ErrUnknownKey", err)
+	}
+}
+
+func TestDecryptTampered(t *testing.T) {
+	configure(t, oldKey, indexKey)
+	sealed, err := Encrypt("ada@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := base64.RawStdEncoding.DecodeString(strings.TrimPrefix(sealed, ActivePrefix()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw[len(raw)-1] ^= 1
+	tampered := ActivePrefix() + base64.RawStdEncoding.EncodeToString(raw)
+	if _, err := Decrypt(tampered); err == nil {
+		t.Fatal("opened a tampered value")
+	}
+
+	// The key ID is authenticated too, so a value cannot be relabelled
+	configure(t, newKey+","+oldKey, indexKey)
+	relabelled := "enc:k2:" + strings.TrimPrefix(sealed, "enc:k1:")
+	if _, err := Decrypt(relabelled); err == nil {
+		t.Fatal("opened a value relabelled with another key")
+	}
+}
+
+func TestBlindIndex(t *testing.T) {
+	configure(t, oldKey, indexKey)
+	first := BlindIndex("+2348012345678")
+	if first == nil || *first != *BlindIndex("+2348012345678") {
+		t.Fatal("blind index is not stable")
+	}
+	if *first == *BlindIndex("+2348012345679") {
+		t.Fatal("different values share a blind index")
+	}
+	if BlindIndex("") != nil {
+		t.Fatal("empty value has a blind index")
+	}
+
+	// The index depends on the key, not only on the value
+	configure(t, oldKey, strings.Repeat("j", MinIndexKeyLength))
+	if *first == *BlindIndex("+2348012345678") {
+		t.Fatal("blind index is the same under another key")
+	}
+}
+
+func TestConfigureRejectsWeakIndexKey(t *testing.T) {
+	t.Cleanup(func() { Configure("", "") })
+	for _, key := range []string{"", "short"} {
+		if err := Configure(oldKey, key); err == nil {
+			t.Errorf("accepted encryption keys with index key %q", key)
+		}
+	}
+	// Without encryption keys values stay plaintext and need no index key
+	if err := Configure("", ""); err != nil {
+		t.Fatalf("rejected plaintext configuration: %v", err)
+	}
+}
@@ -0,0 +1,143 @@
+// Package policy decides who may do what to which resource. Each action is
+// declared once, with the rule that allows it, and checked the same way by
+// the HTTP middleware and the services.
+package policy
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/techagentng/citizenx/models"
+)
+
+// ErrForbidden is returned when the rule for an action does not allow the
+// subject to take it.
+var ErrForbidden = errors.New("you are not allowed to do this")
+
+// Subject is the signed-in user acting
+type Subject struct {
+	UserID   uint
+	Role     string
+	AgencyID *uint // set for staff of a responding agency
+	// AmbassadorLGA is the LGA the subject is ambassador for, if any
+	AmbassadorLGA string
+}
+
+// IsAdmin reports whether the subject has the admin role
+func (s Subject) IsAdmin() bool {
+	return strings.EqualFold(s.Role, models.RoleAdmin)
+}
+
+// Resource is the thing acted on, described by who it belongs to
+type Resource struct {
+	Kind     string
+	ID       string
+	OwnerID  uint
+	AgencyID *uint // the agency a report is assigned to
+	LGA      string
+}
+
+// Action names something a subject can do to a resource
+type Action string
+
+const (
+	// EditReport covers the reporter's own changes to a report: withdrawing
+	// it, managing collaborators and confirming a resolution
+	EditReport Action = "report:edit"
+	// DeleteReport removes a report
+	DeleteReport Action = "report:delete"
+	// RespondToReport covers an agency acknowledging and resolving a report
+	// assigned to it
+	RespondToReport Action = "report:respond"
+	// ReviewReport covers approving, rejecting and accepting reports for
+	// reward points
+	ReviewReport Action = "report:review"
+	// MarkMediaGraphic marks a report's media as graphic or not
+	MarkMediaGraphic Action = "report:mark-graphic"
+	// VerifyReport approves a low-risk report without full review
+	VerifyReport Action = "report:verify"
+	// TransferReport hands a report to another account
+	TransferReport Action = "report:transfer"
+	// ViewReportHistory reads the record of a report's status changes
+	ViewReportHistory Action = "report:view-history"
+	// PrintReport renders a report that is not published as a PDF
+	PrintReport Action = "report:print"
+	// ViewDuplicates lists the reports that look like duplicates of a report
+	ViewDuplicates Action = "report:view-duplicates"
+	// EditComment changes the text of a comment on a report
+	EditComment Action = "comment:edit"
+	// DeleteComment removes a comment on a report
+	DeleteComment Action = "comment:delete"
+)
+
+// Rule decides whether subject may act on resource
+type Rule func(subject Subject, resource Resource) bool
+
+// Owner allows the user the resource belongs to
+func Owner(subject Subject, resource Resource) bool {
+	return subject.UserID != 0 && subject.UserID == resource.OwnerID
+}
+
+// Admin allows users with the admin role
+func Admin(subject Subject, _ Resource) bool {
+	return subject.IsAdmin()
+}
+
+// AgencyMember allows staff of the agency the resource is assigned to
+func AgencyMember(subject Subject, resource Resource) bool {
+	return subject.AgencyID != nil && resource.AgencyID != nil && *subject.AgencyID == *resource.AgencyID
+}
+
+// Ambassador allows the ambassador of the LGA the resource is in
+func Ambassador(subject Subject, resource Resource) bool {
+	return subject.AmbassadorLGA != "" && strings.EqualFold(subject.AmbassadorLGA, resource.LGA)
+}
+
+// AnyOf allows a subject any of rules allows
+func AnyOf(rules ...Rule) Rule {
+	return func(subject Subject, resource Resource) bool {
+		for _, rule := range rules {
+			if rule(subject, resource) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// rules is the policy: the one place that says who may take each action
+var rules = map[Action]Rule{
+	EditReport:        Owner,
+	DeleteReport:      AnyOf(Owner, Admin),
+	RespondToReport:   AgencyMember,
+	ReviewReport:      Admin,
+	MarkMediaGraphic:  AnyOf(Owner, Admin),
+	VerifyReport:      AnyOf(Admin, Ambassador),
+	TransferReport:    AnyOf(Owner, Admin),
+	ViewReportHistory: AnyOf(Owner, Admin, AgencyMember),
+	PrintReport:       AnyOf(Owner, Admin),
+	ViewDuplicates:    AnyOf(Admin, AgencyMember, Ambassador),
+	EditComment:       Owner,
+	DeleteComment:     AnyOf(Owner, Admin),
+}
+
+// Authorize returns ErrForbidden unless the rule for action allows subject
+// to take it on resource. Actions without a rule are denied.
+func Authorize(subject Subject, action Action, resource Resource) error {
+	rule, ok := rules[action]
+	if !ok || !rule(subject, resource) {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// Report describes an incident report as a resource
+func Report(report *models.IncidentReport) Resource {
+	return Resource{Kind: "report", ID: report.ID.String(), OwnerID: report.UserID, AgencyID: report.AgencyID, LGA: report.LGAName}
+}
+
+// Comment describes a comment on a report as a resource
+func Comment(comment *models.ReportComment) Resource {
+	return Resource{Kind: "comment", ID: strconv.FormatUint(uint64(comment.ID), 10), OwnerID: comment.UserID}
+}
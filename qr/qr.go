@@ -0,0 +1,74 @@
+// Package qr draws QR codes for printing on posters and flyers.
+package qr
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// Formats a code can be drawn in
+const (
+	FormatPNG = "png"
+	FormatSVG = "svg"
+)
+
+// Sizes of PNG codes, in pixels
+const (
+	DefaultSize = 512
+	MinSize     = 128
+	MaxSize     = 2048
+)
+
+// ErrUnsupportedFormat is returned for formats other than png and svg
+var ErrUnsupportedFormat = errors.New("format must be png or svg")
+
+// Encode draws content as a QR code, returning the image and its content
+// type. size only applies to PNG; SVG scales to fit wherever it is printed.
+// Codes use medium error correction, which survives a scuffed or partly
+// covered poster.
+func Encode(content, format string, size int) ([]byte, string, error) {
+	code, err := qrcode.New(content, qrcode.Medium)
+	if err != nil {
+		return nil, "", err
+	}
+	switch format {
+	case FormatPNG:
+		png, err := code.PNG(clamp(size))
+		return png, "image/png", err
+	case FormatSVG:
+		return svg(code.Bitmap()), "image/svg+xml", nil
+	default:
+		return nil, "", ErrUnsupportedFormat
+	}
+}
+
+func clamp(size int) int {
+	switch {
+	case size == 0:
+		return DefaultSize
+	case size < MinSize:
+		return MinSize
+	case size > MaxSize:
+		return MaxSize
+	}
+	return size
+}
+
+// svg draws the modules of a bitmap, quiet zone included, one unit each
+func svg(bitmap [][]bool) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, len(bitmap), len(bitmap))
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="#fff"/><path fill="#000" d="`, len(bitmap), len(bitmap))
+	for y, row := range bitmap {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(&b, "M%d %dh1v1h-1z", x, y)
+			}
+		}
+	}
+	b.WriteString(`"/></svg>`)
+	return []byte(b.String())
+}
@@ -0,0 +1,55 @@
+package search
+
+import (
+	"context"
+
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/events"
+)
+
+// Subscribe keeps the index in step with the database by re-indexing a
+// report whenever an event changes something the index holds.
+func (c *Client) Subscribe(bus events.Bus, reports db.IncidentReportRepository) {
+	handler := c.indexer(reports)
+	bus.Subscribe(events.ReportCreatedEvent, handler)
+	bus.Subscribe(events.ReportVerifiedEvent, handler)
+	bus.Subscribe(events.ReportVotedEvent, handler)
+	bus.Subscribe(events.ReportClosedEvent, handler)
+	bus.Subscribe(events.ReportResolvedEvent, handler)
+	bus.Subscribe(events.ReportWithdrawnEvent, handler)
+	bus.Subscribe(events.InfoProvidedEvent, handler)
+	bus.Subscribe(events.ReportEditedEvent, handler)
+}
+
+func (c *Client) indexer(reports db.IncidentReportRepository) events.Handler {
+	return func(ctx context.Context, event events.Event) error {
+		var reportID string
+		switch e := event.(type) {
+		case events.ReportCreated:
+			reportID = e.ReportID.String()
+		case events.ReportVerified:
+			reportID = e.ReportID.String()
+		case events.ReportVoted:
+			reportID = e.ReportID
+		case events.ReportClosed:
+			reportID = e.ReportID.String()
+		case events.ReportResolved:
+			reportID = e.ReportID.String()
+		case events.ReportWithdrawn:
+			reportID = e.ReportID.String()
+		case events.InformationProvided:
+			reportID = e.ReportID.String()
+		case events.ReportEdited:
+			reportID = e.ReportID.String()
+		default:
+			return nil
+		}
+
+		// A report deleted since the event was saved has nothing to index
+		found, err := reports.GetReportsByIDs([]string{reportID})
+		if err != nil || len(found) == 0 {
+			return err
+		}
+		return c.Index(ctx, &found[0])
+	}
+}
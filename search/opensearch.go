@@ -0,0 +1,282 @@
+// Package search mirrors incident reports into OpenSearch (or Elasticsearch)
+// for deployments whose search load outgrows Postgres.
+package search
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/httpclient"
+	"github.com/techagentng/citizenx/models"
+)
+
+// mapping is the index definition. Free text is analysed for relevance,
+// locations are keyword fields for exact filtering and coordinates are a
+// geo_point for distance queries.
+const mapping = `{
+  "mappings": {
+    "properties": {
+      "description":       {"type": "text"},
+      "sub_report_type":   {"type": "text"},
+      "address":           {"type": "text"},
+      "category":          {"type": "keyword"},
+      "state_name":        {"type": "keyword"},
+      "lga_name":          {"type": "keyword"},
+      "plus_code":         {"type": "keyword"},
+      "report_status":     {"type": "keyword"},
+      "location":          {"type": "geo_point"},
+      "time_of_incidence": {"type": "date"},
+      "upvote_count":      {"type": "integer"}
+    }
+  }
+}`
+
+// Document is the indexed form of an incident report.
+type Document struct {
+	Description     string    `json:"description"`
+	SubReportType   string    `json:"sub_report_type"`
+	Address         string    `json:"address"`
+	Category        string    `json:"category"`
+	StateName       string    `json:"state_name"`
+	LGAName         string    `json:"lga_name"`
+	PlusCode        string    `json:"plus_code"`
+	ReportStatus    string    `json:"report_status"`
+	Location        GeoPoint  `json:"location"`
+	TimeOfIncidence time.Time `json:"time_of_incidence"`
+	UpvoteCount     int       `json:"upvote_count"`
+}
+
+// GeoPoint is an OpenSearch geo_point in object form.
+type GeoPoint struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+// NewDocument converts a report to its indexed form.
+func NewDocument(report *models.IncidentReport) Document {
+	return Document{
+		Description:     report.Description,
+		SubReportType:   report.SubReportType,
+		Address:         report.Address,
+		Category:        report.Category,
+		StateName:       report.StateName,
+		LGAName:         report.LGAName,
+		PlusCode:        report.PlusCode,
+		ReportStatus:    report.ReportStatus,
+		Location:        GeoPoint{Lat: report.Latitude, Lon: report.Longitude},
+		TimeOfIncidence: report.TimeofIncidence,
+		UpvoteCount:     report.UpvoteCount,
+	}
+}
+
+// Client talks to the OpenSearch REST API.
+type Client struct {
+	url      string
+	index    string
+	username string
+	password string
+	http     *http.Client
+}
+
+// New returns a client for the configured cluster, or nil when search_url is
+// not set and searches should stay on Postgres.
+func New(conf *config.Config) *Client {
+	if conf.SearchURL == "" {
+		return nil
+	}
+	return &Client{
+		url:      strings.TrimRight(conf.SearchURL, "/"),
+		index:    conf.SearchIndex,
+		username: conf.SearchUsername,
+		password: conf.SearchPassword,
+		http:     httpclient.New("opensearch", httpclient.Options{Timeout: 10 * time.Second}),
+	}
+}
+
+// EnsureIndex creates the index with its mapping if it does not exist yet.
+func (c *Client) EnsureIndex(ctx context.Context) error {
+	resp, err := c.do(ctx, http.MethodHead, "/"+c.index, nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	resp, err = c.do(ctx, http.MethodPut, "/"+c.index, strings.NewReader(mapping))
+	if err != nil {
+		return err
+	}
+	return checkResponse(resp, nil)
+}
+
+// Index writes report to the index, replacing any previous version.
+func (c *Client) Index(ctx context.Context, report *models.IncidentReport) error {
+	body, err := json.Marshal(NewDocument(report))
+	if err != nil {
+		return err
+	}
+	resp, err := c.do(ctx, http.MethodPut, "/"+c.index+"/_doc/"+report.ID.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	return checkResponse(resp, nil)
+}
+
+// Bulk indexes reports in a single request.
+func (c *Client) Bulk(ctx context.Context, reports []models.IncidentReport) error {
+	if len(reports) == 0 {
+		return nil
+	}
+
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for i := range reports {
+		action := map[string]interface{}{"index": map[string]string{"_index": c.index, "_id": reports[i].ID.String()}}
+		if err := enc.Encode(action); err != nil {
+			return err
+		}
+		if err := enc.Encode(NewDocument(&reports[i])); err != nil {
+			return err
+		}
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, "/_bulk", &body)
+	if err != nil {
+		return err
+	}
+	var result struct {
+		Errors bool `json:"errors"`
+	}
+	if err := checkResponse(resp, &result); err != nil {
+		return err
+	}
+	if result.Errors {
+		return fmt.Errorf("bulk indexing %d reports: some documents were rejected", len(reports))
+	}
+	return nil
+}
+
+// Search returns the IDs of the reports matching query and filters, best
+// match first.
+func (c *Client) Search(ctx context.Context, query string, filters db.ReportFilter, page, size int) ([]string, error) {
+	if page < 1 {
+		page = 1
+	}
+
+	must := []interface{}{map[string]interface{}{"match_all": map[string]interface{}{}}}
+	if strings.TrimSpace(query) != "" {
+		must = []interface{}{map[string]interface{}{
+			"multi_match": map[string]interface{}{
+				"query":    query,
+				"fields":   []string{"description^2", "sub_report_type", "address"},
+				"operator": "and",
+			},
+		}}
+	}
+	var filter []interface{}
+	for _, term := range [][2]string{
+		{"state_name", filters.StateName},
+		{"lga_name", filters.LGAName},
+		{"category", filters.Category},
+		{"report_status", filters.Status},
+	} {
+		if term[1] != "" {
+			filter = append(filter, map[string]interface{}{"term": map[string]string{term[0]: term[1]}})
+		}
+	}
+
+	if filters.PlusCode != "" {
+		filter = append(filter, map[string]interface{}{"prefix": map[string]string{"plus_code": filters.PlusCode}})
+	}
+	if filters.Start != nil || filters.End != nil {
+		bounds := map[string]interface{}{}
+		if filters.Start != nil {
+			bounds["gte"] = filters.Start.Format(time.RFC3339)
+		}
+		if filters.End != nil {
+			bounds["lt"] = filters.End.Format(time.RFC3339)
+		}
+		filter = append(filter, map[string]interface{}{"range": map[string]interface{}{"time_of_incidence": bounds}})
+	}
+	if box := filters.BBox; box != nil {
+		filter = append(filter, map[string]interface{}{"geo_bounding_box": map[string]interface{}{
+			"location": map[string]interface{}{
+				"top_left":     GeoPoint{Lat: box.North, Lon: box.West},
+				"bottom_right": GeoPoint{Lat: box.South, Lon: box.East},
+			},
+		}})
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"from":    (page - 1) * size,
+		"size":    size,
+		"_source": false,
+		"query":   map[string]interface{}{"bool": map[string]interface{}{"must": must, "filter": filter}},
+		"sort":    []interface{}{"_score", map[string]string{"time_of_incidence": "desc"}},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.do(ctx, http.MethodPost, "/"+c.index+"/_search", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	var result struct {
+		Hits struct {
+			Hits []struct {
+				ID string `json:"_id"`
+			} `json:"hits"`
+		} `json:"hits"`
+	}
+	if err := checkResponse(resp, &result); err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(result.Hits.Hits))
+	for _, hit := range result.Hits.Hits {
+		ids = append(ids, hit.ID)
+	}
+	return ids, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.url+path, body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		contentType := "application/json"
+		if strings.HasSuffix(path, "/_bulk") {
+			contentType = "application/x-ndjson"
+		}
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	return c.http.Do(req)
+}
+
+// checkResponse closes resp, decoding its body into v on success.
+func checkResponse(resp *http.Response, v interface{}) error {
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("opensearch: %s: %s", resp.Status, msg)
+	}
+	if v == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
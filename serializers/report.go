@@ -0,0 +1,336 @@
+// Package serializers shapes models into the responses each audience is
+// shown. Every field a client sees is listed here on purpose, so a column
+// added to a model stays private until someone decides who may read it.
+package serializers
+
+import (
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techagentng/citizenx/geo"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/policy"
+)
+
+// Audience is who a report is being shown to
+type Audience string
+
+const (
+	// AudiencePublic is anyone, signed in or not
+	AudiencePublic Audience = "public"
+	// AudienceOwner is the user who filed the report
+	AudienceOwner Audience = "owner"
+	// AudienceAgency is staff of the agency the report is assigned to
+	AudienceAgency Audience = "agency"
+	// AudienceModerator is an admin reviewing reports
+	AudienceModerator Audience = "moderator"
+)
+
+// AudienceFor picks the audience subject belongs to for report, using the
+// same rules the policy checks access with. A moderator who filed the
+// report still sees it as a moderator.
+func AudienceFor(subject policy.Subject, report *models.IncidentReport) Audience {
+	resource := policy.Report(report)
+	switch {
+	case policy.Admin(subject, resource):
+		return AudienceModerator
+	case policy.Owner(subject, resource):
+		return AudienceOwner
+	case policy.AgencyMember(subject, resource):
+		return AudienceAgency
+	default:
+		return AudiencePublic
+	}
+}
+
+// ReportDetails are the answers to the questions asked for particular
+// categories of report
+type ReportDetails struct {
+	ProductName          string `json:"product_name"`
+	ActionTypeName       string `json:"action_type_name"`
+	IsState              bool   `json:"is_state"`
+	IsResponse           bool   `json:"is_response"`
+	Rating               string `json:"rating"`
+	HospitalName         string `json:"hospital_name"`
+	HospitalAddress      string `json:"hospital_address"`
+	Department           string `json:"department"`
+	DepartmentHeadName   string `json:"department_head_name"`
+	AccidentCause        string `json:"accident_cause"`
+	SchoolName           string `json:"school_name"`
+	VicePrincipal        string `json:"vice_principal"`
+	OutageLength         string `json:"outage_length"`
+	NoWater              bool   `json:"no_water"`
+	AirportName          string `json:"airport_name"`
+	AirlineName          string `json:"airline_name"`
+	Terminal             string `json:"terminal"`
+	QueueTime            string `json:"queue_time"`
+	Country              string `json:"country"`
+	StateEmbassyLocation string `json:"state_embassy_location"`
+	AmbassedorsName      string `json:"ambassedors_name"`
+	RoadName             string `json:"road_name"`
+}
+
+// PublicReport is a report as anyone may see it. The reporter is named only
+// when they did not report anonymously.
+type PublicReport struct {
+	ID                  uuid.UUID `json:"id"`
+	ReportTypeID        uuid.UUID `json:"report_type_id"`
+	Category            string    `json:"category"`
+	SubReportType       string    `json:"sub_report_type"`
+	Description         string    `json:"description"`
+	DateOfIncidence     string    `json:"date_of_incidence"`
+	TimeofIncidence     time.Time `json:"time_of_incidence"`
+	CreatedAt           int64     `json:"created_at"`
+	StateName           string    `json:"state_name"`
+	LGAName             string    `json:"lga_name"`
+	WardName            string    `json:"ward_name"`
+	Address             string    `json:"address"`
+	Landmark            string    `json:"landmark"`
+	Latitude            float64   `json:"latitude"`
+	Longitude           float64   `json:"longitude"`
+	LocationCoarsened   bool      `json:"location_coarsened"`
+	LocationApproximate bool      `json:"location_approximate"`
+	PlusCode            string    `json:"plus_code"`
+	Route               string    `json:"route,omitempty"`
+	RouteLengthM        int       `json:"route_length_m,omitempty"`
+	FeedURLs            string    `json:"feed_urls"`
+	ThumbnailURLs       string    `json:"thumbnail_urls"`
+	FullSizeURLs        string    `json:"full_size_urls"`
+	UserIsAnonymous     bool      `json:"user_is_anonymous"`
+	UserFullname        string    `json:"fullname,omitempty"`
+	UserUsername        string    `json:"username,omitempty"`
+	ReportStatus        string    `json:"report_status"`
+	StatusUpdatedAt     int64     `json:"status_updated_at"`
+	IsVerified          bool      `json:"is_verified"`
+	View                int       `json:"view"`
+	LikeCount           int       `json:"like_count"`
+	UpvoteCount         int       `json:"upvote_count"`
+	DownvoteCount       int       `json:"downvote_count"`
+	CredibilityScore    int       `json:"credibility_score"`
+	AgencyID            *uint     `json:"agency_id"`
+	AcknowledgedAt      int64     `json:"acknowledged_at,omitempty"`
+	ResolvedAt          int64     `json:"resolved_at,omitempty"`
+	OfficialResponse    string    `json:"official_response"`
+	OfficialResponseAt  int64     `json:"official_response_at"`
+	ResolutionEvidence  []string  `json:"resolution_evidence,omitempty"`
+	ReportDetails
+	Media    []models.Media         `json:"media,omitempty"`
+	Points   []models.ReportPoint   `json:"points,omitempty"`
+	Reporter *models.ReportReporter `json:"reporter,omitempty"`
+	Counts   *models.ReportCounts   `json:"counts,omitempty"`
+}
+
+// OwnerReport is a report as the user who filed it sees it: with the
+// contact details and reward they gave and what became of the report
+type OwnerReport struct {
+	PublicReport
+	UserID              uint   `json:"user_id"`
+	Telephone           string `json:"telephone"`
+	Email               string `json:"email"`
+	RewardPoint         int    `json:"reward_point"`
+	RewardAccountNumber string `json:"reward_account_number"`
+	AutoPublished       bool   `json:"auto_published"`
+	WithdrawnReason     string `json:"withdrawn_reason,omitempty"`
+	ResolutionConfirmed *bool  `json:"resolution_confirmed"`
+}
+
+// AgencyReport is a report as staff of the agency handling it see it
+type AgencyReport struct {
+	PublicReport
+	AssignedAt          int64      `json:"assigned_at,omitempty"`
+	IncidentID          *uuid.UUID `json:"incident_id,omitempty"`
+	ClusterID           *uuid.UUID `json:"cluster_id,omitempty"`
+	PossibleDuplicate   bool       `json:"possible_duplicate"`
+	ResolutionConfirmed *bool      `json:"resolution_confirmed"`
+}
+
+// ModeratorReport is everything about a report a moderator reviews,
+// including the text as written before filtering and how it was submitted
+type ModeratorReport struct {
+	OwnerReport
+	DescriptionRaw    string     `json:"description_raw"`
+	NetworkFlag       string     `json:"network_flag,omitempty"`
+	AdminID           uint       `json:"admin_id"`
+	AssignedAt        int64      `json:"assigned_at,omitempty"`
+	IncidentID        *uuid.UUID `json:"incident_id,omitempty"`
+	ClusterID         *uuid.UUID `json:"cluster_id,omitempty"`
+	PossibleDuplicate bool       `json:"possible_duplicate"`
+}
+
+// NewPublicReport shapes report for the public
+func NewPublicReport(report *models.IncidentReport) PublicReport {
+	p := PublicReport{
+		ID:                  report.ID,
+		ReportTypeID:        report.ReportTypeID,
+		Category:            report.Category,
+		SubReportType:       report.SubReportType,
+		Description:         report.Description,
+		DateOfIncidence:     report.DateOfIncidence,
+		TimeofIncidence:     report.TimeofIncidence,
+		CreatedAt:           report.CreatedAt,
+		StateName:           report.StateName,
+		LGAName:             report.LGAName,
+		WardName:            report.WardName,
+		Address:             report.Address,
+		Landmark:            report.Landmark,
+		Latitude:            report.Latitude,
+		Longitude:           report.Longitude,
+		LocationCoarsened:   report.LocationCoarsened,
+		LocationApproximate: report.LocationApproximate,
+		PlusCode:            report.PlusCode,
+		Route:               report.Route,
+		RouteLengthM:        report.RouteLengthM,
+		FeedURLs:            report.FeedURLs,
+		ThumbnailURLs:       report.ThumbnailURLs,
+		FullSizeURLs:        report.FullSizeURLs,
+		UserIsAnonymous:     report.UserIsAnonymous,
+		ReportStatus:        report.ReportStatus,
+		StatusUpdatedAt:     report.StatusUpdatedAt,
+		IsVerified:          report.IsVerified,
+		View:                report.View,
+		LikeCount:           report.LikeCount,
+		UpvoteCount:         report.UpvoteCount,
+		DownvoteCount:       report.DownvoteCount,
+		CredibilityScore:    report.CredibilityScore,
+		AgencyID:            report.AgencyID,
+		AcknowledgedAt:      report.AcknowledgedAt,
+		ResolvedAt:          report.ResolvedAt,
+		OfficialResponse:    report.OfficialResponse,
+		OfficialResponseAt:  report.OfficialResponseAt,
+		ResolutionEvidence:  report.ResolutionEvidence,
+		ReportDetails: ReportDetails{
+			ProductName:          report.ProductName,
+			ActionTypeName:       report.ActionTypeName,
+			IsState:              report.IsState,
+			IsResponse:           report.IsResponse,
+			Rating:               report.Rating,
+			HospitalName:         report.HospitalName,
+			HospitalAddress:      report.HospitalAddress,
+			Department:           report.Department,
+			DepartmentHeadName:   report.DepartmentHeadName,
+			AccidentCause:        report.AccidentCause,
+			SchoolName:           report.SchoolName,
+			VicePrincipal:        report.VicePrincipal,
+			OutageLength:         report.OutageLength,
+			NoWater:              report.NoWater,
+			AirportName:          report.AirportName,
+			AirlineName:          report.AirlineName,
+			Terminal:             report.Terminal,
+			QueueTime:            report.QueueTime,
+			Country:              report.Country,
+			StateEmbassyLocation: report.StateEmbassyLocation,
+			AmbassedorsName:      report.AmbassedorsName,
+			RoadName:             report.RoadName,
+		},
+		Media:  report.Media,
+		Points: report.Points,
+		Counts: report.Counts,
+	}
+	if !report.UserIsAnonymous {
+		p.UserFullname = report.UserFullname
+		p.UserUsername = report.UserUsername
+		p.Reporter = report.Reporter
+	}
+	return p
+}
+
+// NewOwnerReport shapes report for the user who filed it
+func NewOwnerReport(report *models.IncidentReport) OwnerReport {
+	p := NewPublicReport(report)
+	// Reporters always see their own name, anonymous or not
+	p.UserFullname = report.UserFullname
+	p.UserUsername = report.UserUsername
+	return OwnerReport{
+		PublicReport:        p,
+		UserID:              report.UserID,
+		Telephone:           report.Telephone,
+		Email:               report.Email,
+		RewardPoint:         report.RewardPoint,
+		RewardAccountNumber: report.RewardAccountNumber,
+		AutoPublished:       report.AutoPublished,
+		WithdrawnReason:     report.WithdrawnReason,
+		ResolutionConfirmed: report.ResolutionConfirmed,
+	}
+}
+
+// NewAgencyReport shapes report for the agency handling it
+func NewAgencyReport(report *models.IncidentReport) AgencyReport {
+	return AgencyReport{
+		PublicReport:        NewPublicReport(report),
+		AssignedAt:          report.AssignedAt,
+		IncidentID:          report.IncidentID,
+		ClusterID:           report.ClusterID,
+		PossibleDuplicate:   report.PossibleDuplicate,
+		ResolutionConfirmed: report.ResolutionConfirmed,
+	}
+}
+
+// NewModeratorReport shapes report for moderators
+func NewModeratorReport(report *models.IncidentReport) ModeratorReport {
+	owner := NewOwnerReport(report)
+	owner.Reporter = report.Reporter
+	return ModeratorReport{
+		OwnerReport:       owner,
+		DescriptionRaw:    report.DescriptionRaw,
+		NetworkFlag:       report.NetworkFlag,
+		AdminID:           report.AdminID,
+		AssignedAt:        report.AssignedAt,
+		IncidentID:        report.IncidentID,
+		ClusterID:         report.ClusterID,
+		PossibleDuplicate: report.PossibleDuplicate,
+	}
+}
+
+// Report shapes report for audience
+func Report(report *models.IncidentReport, audience Audience) interface{} {
+	switch audience {
+	case AudienceModerator:
+		return NewModeratorReport(report)
+	case AudienceOwner:
+		return NewOwnerReport(report)
+	case AudienceAgency:
+		return NewAgencyReport(report)
+	default:
+		return NewPublicReport(report)
+	}
+}
+
+// PublicReports shapes a listing for the public
+func PublicReports(reports []models.IncidentReport) []PublicReport {
+	shaped := make([]PublicReport, len(reports))
+	for i := range reports {
+		shaped[i] = NewPublicReport(&reports[i])
+	}
+	return shaped
+}
+
+// OwnerReports shapes a listing of the signed-in user's own reports
+func OwnerReports(reports []models.IncidentReport) []OwnerReport {
+	shaped := make([]OwnerReport, len(reports))
+	for i := range reports {
+		shaped[i] = NewOwnerReport(&reports[i])
+	}
+	return shaped
+}
+
+// NearbyReport is a public report with how far it is from where the
+// listing was asked for
+type NearbyReport struct {
+	PublicReport
+	DistanceKM float64 `json:"distance_km"`
+}
+
+// NearbyReports shapes a listing of reports near origin, rounding each
+// distance to 10 metres
+func NearbyReports(reports []models.IncidentReport, origin geo.Point) []NearbyReport {
+	shaped := make([]NearbyReport, len(reports))
+	for i := range reports {
+		distance := geo.Distance(origin, geo.Point{Latitude: reports[i].Latitude, Longitude: reports[i].Longitude})
+		shaped[i] = NearbyReport{
+			PublicReport: NewPublicReport(&reports[i]),
+			DistanceKM:   math.Round(distance/10) / 100,
+		}
+	}
+	return shaped
+}
@@ -0,0 +1,167 @@
+package serializers_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/policy"
+	"github.com/techagentng/citizenx/serializers"
+)
+
+// fields marshals v and returns its top-level JSON fields
+func fields(t *testing.T, v interface{}) map[string]interface{} {
+	t.Helper()
+	body, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var out map[string]interface{}
+	if err := json.Unmarshal(body, &out); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	return out
+}
+
+func sampleReport() *models.IncidentReport {
+	agencyID := uint(7)
+	return &models.IncidentReport{
+		ID:                  uuid.New(),
+		UserID:              42,
+		UserFullname:        "Ada Obi",
+		UserUsername:        "adaobi",
+		Telephone:           "+2348012345678",
+		Email:               "ada@example.com",
+		Description:         "Burst pipe on **** street",
+		DescriptionRaw:      "Burst pipe on damn street",
+		NetworkFlag:         models.NetworkVPN,
+		RewardAccountNumber: "0123456789",
+		RewardPoint:         10,
+		AdminID:             3,
+		AgencyID:            &agencyID,
+		AssignedAt:          1700000000,
+		WithdrawnReason:     "posted twice",
+		Reporter:            &models.ReportReporter{ID: 42, Fullname: "Ada Obi"},
+	}
+}
+
+// private are the fields no one but the reporter and moderators may see
+var private = []string{"user_id", "telephone", "email", "reward_account_number", "reward_point", "withdrawn_reason"}
+
+// moderation are the fields only moderators may see
+var moderation = []string{"description_raw", "network_flag", "admin_id"}
+
+func TestPublicReportHidesPrivateFields(t *testing.T) {
+	got := fields(t, serializers.NewPublicReport(sampleReport()))
+	for _, key := range append(append(append([]string{}, private...), moderation...), "assigned_at", "incident_id", "bookmarked_reports", "ReportType") {
+		if _, ok := got[key]; ok {
+			t.Errorf("public report exposes %q", key)
+		}
+	}
+	for _, key := range []string{"id", "description", "fullname", "username", "reporter", "agency_id"} {
+		if _, ok := got[key]; !ok {
+			t.Errorf("public report is missing %q", key)
+		}
+	}
+	if got["description"] != "Burst pipe on **** street" {
+		t.Errorf("public description = %v, want the filtered text", got["description"])
+	}
+}
+
+func TestPublicReportHidesAnonymousReporter(t *testing.T) {
+	report := sampleReport()
+	report.UserIsAnonymous = true
+	got := fields(t, serializers.NewPublicReport(report))
+	for _, key := range []string{"fullname", "username", "reporter"} {
+		if _, ok := got[key]; ok {
+			t.Errorf("anonymous public report exposes %q", key)
+		}
+	}
+
+	owner := fields(t, serializers.NewOwnerReport(report))
+	if owner["fullname"] != "Ada Obi" {
+		t.Errorf("owner fullname = %v, want the reporter's own name", owner["fullname"])
+	}
+	if _, ok := owner["reporter"]; ok {
+		t.Error("anonymous owner report exposes the public reporter profile")
+	}
+}
+
+func TestOwnerReportFields(t *testing.T) {
+	got := fields(t, serializers.NewOwnerReport(sampleReport()))
+	for _, key := range private {
+		if _, ok := got[key]; !ok {
+			t.Errorf("owner report is missing %q", key)
+		}
+	}
+	for _, key := range moderation {
+		if _, ok := got[key]; ok {
+			t.Errorf("owner report exposes %q", key)
+		}
+	}
+}
+
+func TestAgencyReportFields(t *testing.T) {
+	got := fields(t, serializers.NewAgencyReport(sampleReport()))
+	if _, ok := got["assigned_at"]; !ok {
+		t.Error("agency report is missing assigned_at")
+	}
+	for _, key := range append(append([]string{}, private...), moderation...) {
+		if _, ok := got[key]; ok {
+			t.Errorf("agency report exposes %q", key)
+		}
+	}
+}
+
+func TestModeratorReportFields(t *testing.T) {
+	report := sampleReport()
+	report.UserIsAnonymous = true
+	got := fields(t, serializers.NewModeratorReport(report))
+	for _, key := range append(append(append([]string{}, private...), moderation...), "assigned_at", "reporter") {
+		if _, ok := got[key]; !ok {
+			t.Errorf("moderator report is missing %q", key)
+		}
+	}
+	if got["description_raw"] != "Burst pipe on damn street" {
+		t.Errorf("description_raw = %v, want the unfiltered text", got["description_raw"])
+	}
+}
+
+func TestAudienceFor(t *testing.T) {
+	report := sampleReport()
+	otherAgency := uint(8)
+	for _, tc := range []struct {
+		name    string
+		subject policy.Subject
+		want    serializers.Audience
+	}{
+		{"anonymous visitor", policy.Subject{}, serializers.AudiencePublic},
+		{"another user", policy.Subject{UserID: 9, Role: models.RoleUser}, serializers.AudiencePublic},
+		{"reporter", policy.Subject{UserID: 42, Role: models.RoleUser}, serializers.AudienceOwner},
+		{"assigned agency", policy.Subject{UserID: 9, AgencyID: report.AgencyID}, serializers.AudienceAgency},
+		{"other agency", policy.Subject{UserID: 9, AgencyID: &otherAgency}, serializers.AudiencePublic},
+		{"admin", policy.Subject{UserID: 1, Role: models.RoleAdmin}, serializers.AudienceModerator},
+		{"admin reporter", policy.Subject{UserID: 42, Role: models.RoleAdmin}, serializers.AudienceModerator},
+	} {
+		if got := serializers.AudienceFor(tc.subject, report); got != tc.want {
+			t.Errorf("%s: audience = %s, want %s", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestReportPicksShapeForAudience(t *testing.T) {
+	report := sampleReport()
+	if _, ok := serializers.Report(report, serializers.AudiencePublic).(serializers.PublicReport); !ok {
+		t.Error("public audience did not get a PublicReport")
+	}
+	if _, ok := serializers.Report(report, serializers.AudienceOwner).(serializers.OwnerReport); !ok {
+		t.Error("owner audience did not get an OwnerReport")
+	}
+	if _, ok := serializers.Report(report, serializers.AudienceAgency).(serializers.AgencyReport); !ok {
+		t.Error("agency audience did not get an AgencyReport")
+	}
+	if _, ok := serializers.Report(report, serializers.AudienceModerator).(serializers.ModeratorReport); !ok {
+		t.Error("moderator audience did not get a ModeratorReport")
+	}
+}
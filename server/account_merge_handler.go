@@ -0,0 +1,68 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+)
+
+// respondAccountMergeError maps account merge errors to responses
+func respondAccountMergeError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, services.ErrMergeSameAccount):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrMergeAccountNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		response.JSON(c, message, http.StatusInternalServerError, nil, err)
+	}
+}
+
+func (s *Server) handleListDuplicateAccounts() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		groups, err := s.AccountMergeService.Duplicates()
+		if err != nil {
+			respondAccountMergeError(c, "Failed to find duplicate accounts", err)
+			return
+		}
+		response.JSON(c, "Duplicate accounts retrieved", http.StatusOK, groups, nil)
+	}
+}
+
+// handleMergeAccounts folds one account into another, e.g. {"source_id": 41,
+// "target_id": 12}. The source's reports, rewards and bookmarks move to the
+// target and the source is closed.
+func (s *Server) handleMergeAccounts() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request models.AccountMergeRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "source_id and target_id are required"})
+			return
+		}
+		merge, err := s.AccountMergeService.Merge(&request, c.GetUint("userID"))
+		if err != nil {
+			respondAccountMergeError(c, "Failed to merge accounts", err)
+			return
+		}
+		response.JSON(c, "Accounts merged", http.StatusOK, merge, nil)
+	}
+}
+
+func (s *Server) handleListAccountMerges() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, ok := incidentPage(c)
+		if !ok {
+			return
+		}
+		merges, err := s.AccountMergeService.Merges(page)
+		if err != nil {
+			respondAccountMergeError(c, "Failed to load account merges", err)
+			return
+		}
+		response.JSON(c, "Account merges retrieved", http.StatusOK, merges, nil)
+	}
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/techagentng/citizenx/server/response"
+	"gorm.io/gorm"
+)
+
+func (s *Server) handleGetAdminOverview() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		overview, err := s.AdminService.Overview()
+		if err != nil {
+			response.JSON(c, "Failed to load admin overview", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Admin overview retrieved successfully", http.StatusOK, overview, nil)
+	}
+}
+
+// handleGetModeratorPerformance serves per-moderator throughput, median
+// review time and overturn rate, over the last 30 days by default
+func (s *Server) handleGetModeratorPerformance() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start, end, err := parseDateRange(c, 30)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		performance, err := s.AdminService.ModeratorPerformance(start, end)
+		if err != nil {
+			response.JSON(c, "Failed to load moderator performance", http.StatusInternalServerError, nil, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"start": start, "end": end, "moderators": performance})
+	}
+}
+
+func (s *Server) handleGetMediaReuseFlags() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+		if err != nil || page < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page number"})
+			return
+		}
+
+		flags, err := s.MediaService.ListMediaReuseFlags(page)
+		if err != nil {
+			response.JSON(c, "Failed to load media reuse flags", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Media reuse flags retrieved successfully", http.StatusOK, flags, nil)
+	}
+}
+
+func (s *Server) handleSetOfficialResponse() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			Response string `json:"response" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A response is required"})
+			return
+		}
+
+		err := s.IncidentReportRepository.SetOfficialResponse(c.Param("id"), body.Response)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Incident report not found"})
+			return
+		}
+		if err != nil {
+			response.JSON(c, "Failed to save official response", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Official response saved successfully", http.StatusOK, nil, nil)
+	}
+}
+
+func (s *Server) handleGetReportText() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, err := uuid.Parse(c.Param("id")); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid report ID"})
+			return
+		}
+
+		text, err := s.IncidentReportService.GetReportText(c.Param("id"))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Incident report not found"})
+			return
+		}
+		if err != nil {
+			response.JSON(c, "Failed to load report text", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Report text retrieved successfully", http.StatusOK, text, nil)
+	}
+}
+
+func (s *Server) handleGetPostText() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		text, err := s.PostService.GetPostText(c.Param("id"))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
+			return
+		}
+		if err != nil {
+			response.JSON(c, "Failed to load post text", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Post text retrieved successfully", http.StatusOK, text, nil)
+	}
+}
@@ -0,0 +1,203 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+	"gorm.io/gorm"
+)
+
+// respondAgencyError writes the response for an agency service error
+func respondAgencyError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, services.ErrAgencyNotFound), errors.Is(err, services.ErrReportNotFound), errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrNotAgencyReport), errors.Is(err, services.ErrNotReportOwner):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrReportAlreadyResolved), errors.Is(err, services.ErrReportNotResolved), errors.Is(err, db.ErrInvalidTransition):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidStatusUpdate):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		response.JSON(c, message, http.StatusInternalServerError, nil, err)
+	}
+}
+
+func (s *Server) handleListAgencies() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		agencies, err := s.AgencyService.ListAgencies()
+		if err != nil {
+			respondAgencyError(c, "Failed to load agencies", err)
+			return
+		}
+		response.JSON(c, "Agencies retrieved", http.StatusOK, agencies, nil)
+	}
+}
+
+// handleGetAgencyScorecards serves every agency's current scorecard. The
+// figures are cached, so clients and proxies may cache them too.
+func (s *Server) handleGetAgencyScorecards() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scorecards, err := s.AgencyService.Scorecards()
+		if err != nil {
+			respondAgencyError(c, "Failed to load agency scorecards", err)
+			return
+		}
+		c.Header("Cache-Control", "public, max-age=600")
+		c.JSON(http.StatusOK, gin.H{"window_days": services.ScorecardWindowDays, "scorecards": scorecards})
+	}
+}
+
+// handleGetAgencyScorecard serves one agency's current scorecard with its
+// monthly snapshots
+func (s *Server) handleGetAgencyScorecard() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := s.idParam(c, "id", "agency", &models.Agency{})
+		if !ok {
+			return
+		}
+		history, err := s.AgencyService.ScorecardHistory(id)
+		if err != nil {
+			respondAgencyError(c, "Failed to load agency scorecard", err)
+			return
+		}
+		c.Header("Cache-Control", "public, max-age=600")
+		response.JSON(c, "Agency scorecard retrieved", http.StatusOK, history, nil)
+	}
+}
+
+func (s *Server) handleCreateAgency() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var agency models.Agency
+		if err := c.ShouldBindJSON(&agency); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "An agency name is required"})
+			return
+		}
+		if err := s.AgencyService.CreateAgency(&agency); err != nil {
+			respondAgencyError(c, "Failed to create agency", err)
+			return
+		}
+		response.JSON(c, "Agency created", http.StatusCreated, agency, nil)
+	}
+}
+
+// handleSetAgencyMember makes a user staff of an agency, e.g.
+// {"agency_id": 3}, or removes them with {"agency_id": null}
+func (s *Server) handleSetAgencyMember() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := s.idParam(c, "id", "user", &models.User{})
+		if !ok {
+			return
+		}
+		var body struct {
+			AgencyID *uint `json:"agency_id"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+		if err := s.AgencyService.SetMemberAgency(userID, body.AgencyID); err != nil {
+			respondAgencyError(c, "Failed to update agency membership", err)
+			return
+		}
+		response.JSON(c, "Agency membership updated", http.StatusOK, nil, nil)
+	}
+}
+
+func (s *Server) handleAssignReportAgency() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			AgencyID uint `json:"agency_id" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "An agency_id is required"})
+			return
+		}
+		if err := s.AgencyService.AssignReport(c.Param("id"), body.AgencyID); err != nil {
+			respondAgencyError(c, "Failed to assign report", err)
+			return
+		}
+		response.JSON(c, "Report assigned", http.StatusOK, nil, nil)
+	}
+}
+
+func (s *Server) handleAcknowledgeAgencyReport() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := s.AgencyService.AcknowledgeReport(c.GetUint("userID"), c.Param("reportID")); err != nil {
+			respondAgencyError(c, "Failed to acknowledge report", err)
+			return
+		}
+		response.JSON(c, "Report acknowledged", http.StatusOK, nil, nil)
+	}
+}
+
+func (s *Server) handleResolveAgencyReport() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			Note string `json:"note"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+		if err := s.AgencyService.ResolveReport(c.GetUint("userID"), c.Param("reportID"), body.Note); err != nil {
+			respondAgencyError(c, "Failed to resolve report", err)
+			return
+		}
+		response.JSON(c, "Report resolved", http.StatusOK, nil, nil)
+	}
+}
+
+// handleConfirmResolution lets the reporter say whether the agency really
+// resolved the issue, with {"confirmed": true} or {"confirmed": false}
+func (s *Server) handleConfirmResolution() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			Confirmed *bool `json:"confirmed" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "confirmed must be true or false"})
+			return
+		}
+		if err := s.AgencyService.ConfirmResolution(c.GetUint("userID"), c.Param("reportID"), *body.Confirmed); err != nil {
+			respondAgencyError(c, "Failed to record your answer", err)
+			return
+		}
+		response.JSON(c, "Thank you for confirming", http.StatusOK, nil, nil)
+	}
+}
+
+// handleUpdateAgencyStatuses acknowledges or resolves a batch of the
+// agency's reports, e.g. {"report_ids": [...], "status": "resolved",
+// "note": "Transformer replaced", "evidence_urls": [...]}, answering with
+// the outcome for each report
+func (s *Server) handleUpdateAgencyStatuses() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var update models.AgencyStatusUpdate
+		if err := c.ShouldBindJSON(&update); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "report_ids and a status are required"})
+			return
+		}
+		results, err := s.AgencyService.UpdateStatuses(c.GetUint("userID"), update)
+		if err != nil {
+			respondAgencyError(c, "Failed to update report statuses", err)
+			return
+		}
+		updated := 0
+		for _, result := range results {
+			if result.Updated {
+				updated++
+			}
+		}
+		response.JSON(c, "Report statuses updated", http.StatusOK, gin.H{
+			"updated": updated,
+			"failed":  len(results) - updated,
+			"results": results,
+		}, nil)
+	}
+}
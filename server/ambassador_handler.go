@@ -0,0 +1,101 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+	"gorm.io/gorm"
+)
+
+// respondAmbassadorError writes the response for an ambassador service error
+func respondAmbassadorError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, services.ErrLGANotFound), errors.Is(err, services.ErrReportNotFound), errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrNotAmbassador):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrNotVerifiable):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidMonth):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		response.JSON(c, message, http.StatusInternalServerError, nil, err)
+	}
+}
+
+// handleSetAmbassador makes a user ambassador of an LGA, e.g.
+// {"lga": "Ikeja"}, or ends their role with {"lga": ""}
+func (s *Server) handleSetAmbassador() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := s.idParam(c, "id", "user", &models.User{})
+		if !ok {
+			return
+		}
+		var body struct {
+			LGA string `json:"lga"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+		if err := s.AmbassadorService.SetAmbassador(userID, strings.TrimSpace(body.LGA)); err != nil {
+			respondAmbassadorError(c, "Failed to update ambassador", err)
+			return
+		}
+		response.JSON(c, "Ambassador updated", http.StatusOK, nil, nil)
+	}
+}
+
+func (s *Server) handleGetAmbassadorDashboard() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		dashboard, err := s.AmbassadorService.Dashboard(c.MustGet("user").(*models.User))
+		if err != nil {
+			respondAmbassadorError(c, "Failed to load dashboard", err)
+			return
+		}
+		response.JSON(c, "Dashboard retrieved", http.StatusOK, dashboard, nil)
+	}
+}
+
+// handleVerifyReport lets an ambassador approve a low-risk report in their
+// LGA
+func (s *Server) handleVerifyReport() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := s.AmbassadorService.Verify(c.GetUint("userID"), c.Param("reportID")); err != nil {
+			respondAmbassadorError(c, "Failed to verify report", err)
+			return
+		}
+		response.JSON(c, "Report verified", http.StatusOK, nil, nil)
+	}
+}
+
+// handleGetAmbassadorSummaries serves the signed-in ambassador's monthly
+// summaries, most recent first
+func (s *Server) handleGetAmbassadorSummaries() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		summaries, err := s.AmbassadorService.UserSummaries(c.GetUint("userID"))
+		if err != nil {
+			respondAmbassadorError(c, "Failed to load summaries", err)
+			return
+		}
+		response.JSON(c, "Summaries retrieved", http.StatusOK, summaries, nil)
+	}
+}
+
+// handleListAmbassadorSummaries serves every ambassador's summary of
+// ?month=YYYY-MM, by default last month
+func (s *Server) handleListAmbassadorSummaries() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		summaries, err := s.AmbassadorService.ListSummaries(c.Query("month"))
+		if err != nil {
+			respondAmbassadorError(c, "Failed to load summaries", err)
+			return
+		}
+		response.JSON(c, "Summaries retrieved", http.StatusOK, summaries, nil)
+	}
+}
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/services"
+)
+
+// parseAggregateQuery reads the grouping and filters shared by the report
+// aggregation endpoints. start_date and end_date are inclusive days.
+func parseAggregateQuery(c *gin.Context) (db.AggregateQuery, error) {
+	q := db.AggregateQuery{
+		GroupBy:   c.DefaultQuery("group_by", "state"),
+		StateName: c.Query("state"),
+		LGAName:   c.Query("lga"),
+		WardName:  c.Query("ward"),
+		Category:  c.Query("category"),
+	}
+	var err error
+	q.Start, q.End, err = db.ParseDayRange(c.Query("start_date"), c.Query("end_date"))
+	return q, err
+}
+
+// respondAggregateError maps repository validation errors to 400s
+func respondAggregateError(c *gin.Context, err error) {
+	if errors.Is(err, db.ErrUnknownDimension) || errors.Is(err, db.ErrUnknownInterval) ||
+		errors.Is(err, db.ErrUnknownSnapshotScope) || errors.Is(err, db.ErrInvalidQuery) ||
+		errors.Is(err, db.ErrInvalidDateRange) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
+
+// handleGetReportTimeseries returns report totals per group per interval.
+// Groups are compared by naming them in the comma separated values
+// parameter, e.g. ?group_by=ward&values=Ikoyi,Obalende
+func (s *Server) handleGetReportTimeseries() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		q, err := parseAggregateQuery(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		for _, v := range strings.Split(c.Query("values"), ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				q.Values = append(q.Values, v)
+			}
+		}
+
+		interval := c.DefaultQuery("interval", "day")
+		timeseries, err := s.AnalyticsService.ReportTimeseries(q, interval)
+		if err != nil {
+			respondAggregateError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, timeseries)
+	}
+}
+
+// handleGetReportSnapshot returns the report figures as they stood on a
+// past day, e.g. ?date=2024-05-31&scope=state. Adding compare_to=2024-04-30
+// returns each group's change between the two days instead.
+func (s *Server) handleGetReportSnapshot() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		date, err := time.Parse("2006-01-02", c.Query("date"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date format, expected YYYY-MM-DD"})
+			return
+		}
+		scope := c.DefaultQuery("scope", models.SnapshotNational)
+
+		if v := c.Query("compare_to"); v != "" {
+			previous, err := time.Parse("2006-01-02", v)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid compare_to format, expected YYYY-MM-DD"})
+				return
+			}
+			comparison, err := s.AnalyticsService.CompareSnapshots(scope, date, previous)
+			if errors.Is(err, services.ErrSnapshotNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			if err != nil {
+				respondAggregateError(c, err)
+				return
+			}
+			c.JSON(http.StatusOK, comparison)
+			return
+		}
+
+		day, snapshots, err := s.AnalyticsService.Snapshot(scope, date)
+		if errors.Is(err, services.ErrSnapshotNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			respondAggregateError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"scope": scope, "requested_date": date.Format("2006-01-02"), "snapshot_date": day.Format("2006-01-02"), "groups": snapshots})
+	}
+}
+
+// handleQueryReports answers a report query posted as JSON; see
+// db.ReportQuery for the language
+func (s *Server) handleQueryReports() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var q db.ReportQuery
+		if err := c.ShouldBindJSON(&q); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid report query"})
+			return
+		}
+		rows, err := s.AnalyticsService.QueryReports(q)
+		if err != nil {
+			respondAggregateError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"rows": rows})
+	}
+}
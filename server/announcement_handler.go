@@ -0,0 +1,81 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+)
+
+// handlePreviewAnnouncementAudience counts the users a segment selects,
+// e.g. {"state_name": "Lagos", "inactive_days": 30}
+func (s *Server) handlePreviewAnnouncementAudience() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var segment models.AnnouncementSegment
+		if err := c.ShouldBindJSON(&segment); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid segment"})
+			return
+		}
+		count, err := s.AnnouncementService.PreviewAudience(segment)
+		if err != nil {
+			response.JSON(c, "Failed to count audience", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Audience counted", http.StatusOK, gin.H{"segment": segment, "audience": count}, nil)
+	}
+}
+
+func (s *Server) handleCreateAnnouncement() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var announcement models.Announcement
+		if err := c.ShouldBindJSON(&announcement); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A title and message are required"})
+			return
+		}
+		if err := s.AnnouncementService.CreateAnnouncement(&announcement, c.GetUint("userID")); err != nil {
+			response.JSON(c, "Failed to schedule announcement", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Announcement scheduled", http.StatusCreated, announcement, nil)
+	}
+}
+
+func (s *Server) handleListAnnouncements() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+		if err != nil || page < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page number"})
+			return
+		}
+		announcements, err := s.AnnouncementService.ListAnnouncements(page)
+		if err != nil {
+			response.JSON(c, "Failed to load announcements", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Announcements retrieved", http.StatusOK, announcements, nil)
+	}
+}
+
+func (s *Server) handleGetAnnouncementStats() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid announcement ID"})
+			return
+		}
+		stats, err := s.AnnouncementService.GetAnnouncementStats(uint(id))
+		if errors.Is(err, services.ErrAnnouncementNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			response.JSON(c, "Failed to load announcement stats", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Announcement stats retrieved", http.StatusOK, stats, nil)
+	}
+}
@@ -2,7 +2,6 @@ package server
 
 import (
 	// "bytes"
-	"bytes"
 	"context"
 	"crypto/rand"
 	"encoding/base64"
@@ -16,7 +15,6 @@ import (
 	"strconv"
 
 	// "strconv"
-	"strings"
 	"time"
 
 	"github.com/go-playground/validator/v10"
@@ -24,99 +22,39 @@ import (
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
-	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/credentials"
-	"github.com/aws/aws-sdk-go-v2/service/s3"
-	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	"github.com/gin-gonic/gin"
 	"github.com/techagentng/citizenx/errors"
 	errs "github.com/techagentng/citizenx/errors"
+	"github.com/techagentng/citizenx/locale"
 	"github.com/techagentng/citizenx/models"
 	"github.com/techagentng/citizenx/server/response"
-	jwtPackage "github.com/techagentng/citizenx/services/jwt"
+	"github.com/techagentng/citizenx/storage"
 )
 
-func createS3Client() (*s3.Client, error) {
-	cfg, err := config.LoadDefaultConfig(context.Background(),
-		config.WithRegion(os.Getenv("AWS_REGION")),
-		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
-			os.Getenv("AWS_ACCESS_KEY_ID"),
-			os.Getenv("AWS_SECRET_ACCESS_KEY"),
-			"",
-		)),
-	)
-	if err != nil {
-		return nil, fmt.Errorf("unable to load SDK config, %v", err)
-	}
-
-	return s3.NewFromConfig(cfg), nil
-}
-
-func uploadFileToS3(client *s3.Client, file multipart.File, bucketName, key string) (string, error) {
-	defer file.Close()
+// MaxFileSize caps profile and post images
+const MaxFileSize = 5 * 1024 * 1024 // 5 MB
 
-	// Read the file content
-	fileContent, err := io.ReadAll(file)
-	if err != nil {
-		fmt.Printf("Error reading file content: %v\n", err) // Log the error
-		return "", fmt.Errorf("failed to read file content: %v", err)
+// validateFile checks a profile or post image: its content must be a JPEG,
+// PNG or GIF agreeing with the declared type, within MaxFileSize and the
+// configured dimension limits
+func (s *Server) validateFile(file *multipart.FileHeader) error {
+	limits := storage.LimitsFromConfig(s.Config)
+	limits.MaxImageBytes = MaxFileSize
+	if err := limits.CheckSize(storage.KindImage, file.Size); err != nil {
+		return err
 	}
 
-	//     // Log bucket and key information
-	fmt.Printf("Uploading to bucket: %s\n", bucketName)
-	fmt.Printf("Uploading with key: %s\n", key)
-
-	// Upload the file to S3
-	_, err = client.PutObject(context.TODO(), &s3.PutObjectInput{
-		Bucket: aws.String(bucketName),
-		Key:    aws.String(key),
-		Body:   bytes.NewReader(fileContent),
-		ACL:    types.ObjectCannedACLPublicRead,
-	})
+	f, err := file.Open()
 	if err != nil {
-		fmt.Printf("Error uploading file to S3: %v\n", err) // Log the error
-		return "", fmt.Errorf("failed to upload file to S3x: %v", err)
-	}
-
-	// Log successful upload
-	fileURL := fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", bucketName, os.Getenv("AWS_REGION"), key)
-	fmt.Printf("File uploaded successfully, URL: %s\n", fileURL) // Log the URL
-
-	return fileURL, nil
-}
-
-// Define allowed MIME types and max file size
-const (
-	MaxFileSize      = 5 * 1024 * 1024 // 5 MB
-	AllowedMimeTypes = "image/jpeg,image/png,image/gif"
-)
-
-// validateFile checks the file type and size
-func validateFile(file *multipart.FileHeader) error {
-	// Check file size
-	if file.Size > MaxFileSize {
-		return fmt.Errorf("file size exceeds limit of %d bytes", MaxFileSize)
-	}
-
-	// Check file MIME type
-	mimeType := file.Header.Get("Content-Type")
-	if !isValidMimeType(mimeType) {
-		return fmt.Errorf("invalid file type: %s", mimeType)
+		return err
 	}
-
-	return nil
-}
-
-// isValidMimeType checks if the MIME type is allowed
-func isValidMimeType(mimeType string) bool {
-	allowedTypes := strings.Split(AllowedMimeTypes, ",")
-	for _, allowedType := range allowedTypes {
-		if mimeType == allowedType {
-			return true
-		}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return err
 	}
-	return false
+	_, err = limits.Validate(data, file.Header.Get("Content-Type"), storage.KindImage)
+	return err
 }
 
 func (s *Server) handleUpdateUserImageUrl() gin.HandlerFunc {
@@ -129,49 +67,23 @@ func (s *Server) handleUpdateUserImageUrl() gin.HandlerFunc {
 		}
 
 		// Validate file type and size
-		if err := validateFile(fileHeader); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-			return
-		}
-
-		// Get the access token from the authorization header
-		accessToken := getTokenFromHeader(c)
-		if accessToken == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-			return
-		}
-
-		// Validate and decode the access token to get the userID
-		secret := s.Config.JWTSecret
-		accessClaims, err := jwtPackage.ValidateAndGetClaims(accessToken, secret)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-			return
-		}
-
-		var userID uint
-		switch userIDValue := accessClaims["id"].(type) {
-		case float64:
-			userID = uint(userIDValue)
-		default:
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid userID format"})
+		if err := s.validateFile(fileHeader); err != nil {
+			if !respondValidationError(c, err) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			}
 			return
 		}
 
-		// Create S3 client
-		s3Client, err := createS3Client()
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create S3 client"})
-			return
-		}
+		userID := c.GetUint("userID")
 
 		userIDString := strconv.FormatUint(uint64(userID), 10)
 
 		// Generate unique filename
 		filename := userIDString + "_" + fileHeader.Filename
 
-		// Upload file to S3
-		filepath, err := uploadFileToS3(s3Client, file, os.Getenv("AWS_BUCKET"), filename)
+		// Upload file to storage
+		defer file.Close()
+		filepath, err := s.ObjectService.Put(filename, file, fileHeader.Size, fileHeader.Header.Get("Content-Type"), models.ObjectOwnerUser, userIDString)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload file to S3xx"})
 			return
@@ -223,10 +135,10 @@ func (s *Server) handleSignup() gin.HandlerFunc {
 		if err == nil {
 			defer file.Close()
 
-			// Create S3 client
-			s3Client, err := createS3Client()
-			if err != nil {
-				response.JSON(c, "", http.StatusInternalServerError, nil, err)
+			if err := s.validateFile(handler); err != nil {
+				if !respondValidationError(c, err) {
+					response.JSON(c, "", http.StatusBadRequest, nil, err)
+				}
 				return
 			}
 
@@ -234,8 +146,9 @@ func (s *Server) handleSignup() gin.HandlerFunc {
 			userID := c.PostForm("user_id")
 			filename := fmt.Sprintf("%s_%s", userID, handler.Filename)
 
-			// Upload file to S3
-			filePath, err = uploadFileToS3(s3Client, file, os.Getenv("AWS_BUCKET"), filename)
+			// Upload file to storage. The user does not exist yet, so the
+			// image is owned through the URL saved on the new account.
+			filePath, err = s.ObjectService.Put(filename, file, handler.Size, handler.Header.Get("Content-Type"), models.ObjectOwnerUser, "")
 			if err != nil {
 				response.JSON(c, "", http.StatusInternalServerError, nil, err)
 				return
@@ -254,6 +167,7 @@ func (s *Server) handleSignup() gin.HandlerFunc {
 		user.Telephone = c.PostForm("telephone")
 		user.Email = c.PostForm("email")
 		user.Password = c.PostForm("password")
+		user.DateOfBirth = c.PostForm("date_of_birth")
 		user.ThumbNailURL = filePath // Set the S3 URL in the user struct
 
 		// Fetch the UUID for the role
@@ -276,6 +190,9 @@ func (s *Server) handleSignup() gin.HandlerFunc {
 
 		// Signup the user using the service
 		userResponse, err := s.AuthService.SignupUser(&user)
+		if respondDisplayNameRejected(c, err) || respondAgeRejected(c, err) {
+			return
+		}
 		if err != nil {
 			response.HandleErrors(c, err) // Use HandleErrors to handle different error types
 			return
@@ -339,6 +256,9 @@ func (s *Server) handleLogin() gin.HandlerFunc {
 			response.JSON(c, "", err.Status, nil, err)
 			return
 		}
+		if err := s.ActivityService.Track(userResponse.ID, models.ActivityLogin, map[string]interface{}{"method": "password"}); err != nil {
+			log.Printf("tracking login of user %d: %v", userResponse.ID, err)
+		}
 		response.JSON(c, "login successful", http.StatusOK, userResponse, nil)
 	}
 }
@@ -552,7 +472,7 @@ func (srv *Server) getUserInfoFromGoogle(token string) (*GoogleUser, error) {
 		return nil, fmt.Errorf("error occurred while getting information from Google: %+v", err)
 	}
 
-	googleUserDetailsResponse, googleDetailsResponseError := http.DefaultClient.Do(googleUserDetailsRequest)
+	googleUserDetailsResponse, googleDetailsResponseError := socialLoginClient.Do(googleUserDetailsRequest)
 	if googleDetailsResponseError != nil {
 		return nil, fmt.Errorf("error occurred while getting information from Google: %+v", googleDetailsResponseError)
 	}
@@ -597,36 +517,23 @@ func (s *Server) GetGoogleSignInToken(c *gin.Context, googleUserDetails *GoogleU
 		log.Printf("Existing user found: %+v", user)
 	}
 
-	// Fetch the role by ID
-	role, err := s.AuthRepository.FindRoleByID(user.RoleID)
-	if err != nil {
-		log.Printf("Error fetching role for user: %v", err)
-		return nil, fmt.Errorf("unable to fetch role for user: %v", err)
-	}
-
-	log.Printf("Generating token pair for user: %s", googleUserDetails.Email)
-
-	// Generate the token pair
-	accessToken, refreshToken, err := jwtPackage.GenerateTokenPair(
-		user.Email,         // Use the user's email
-		s.Config.JWTSecret, // JWT secret from the server config
-		user.AdminStatus,   // Admin status from the user model
-		user.ID,            // Use the correct user ID
-		role.Name,          // Pass the role name
-	)
-
+	// Google sign-in opens a session like every other sign-in, so its
+	// tokens carry a session ID and are signed with the active key
+	log.Printf("Starting session for user: %s", googleUserDetails.Email)
+	login, err := s.SessionService.Start(user)
 	if err != nil {
-		log.Printf("Error generating token pair for email %s: %v", googleUserDetails.Email, err)
-		return nil, fmt.Errorf("error generating token pair: %v", err)
+		log.Printf("Error starting session for email %s: %v", googleUserDetails.Email, err)
+		return nil, fmt.Errorf("error starting session: %v", err)
 	}
 
 	payload := &AuthPayload{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
+		AccessToken:  login.AccessToken,
+		RefreshToken: login.RefreshToken,
 		TokenType:    "Bearer",
-		ExpiresIn:    int(AccessTokenDuration.Seconds()),
+		ExpiresIn:    s.Config.AccessTokenMinutes * 60,
+		Data:         login,
 	}
-	log.Printf("Auth payload generated for user %s: %+v", googleUserDetails.Email, payload)
+	log.Printf("Auth payload generated for user %s", googleUserDetails.Email)
 	return payload, nil
 }
 
@@ -673,30 +580,22 @@ func (s *Server) SocialAuthenticate(authRequest *AuthRequest, authPayloadOption
 		return nil, fmt.Errorf("userID is not a valid uint")
 	}
 
-	// Get email from authRequest
-	email := authRequest.email
-
-	// Fetch the role from the repository based on userID
-	userRole, err := s.AuthRepository.GetUserRoleByUserID(userIDUint)
+	user, err := s.AuthRepository.FindUserByID(userIDUint)
 	if err != nil {
-		return nil, fmt.Errorf("failed to retrieve role for user: %v", err)
+		return nil, fmt.Errorf("failed to retrieve user: %v", err)
 	}
-
-	// Determine if the user is an admin
-	isAdmin := userRole.Name == "admin"
-
-	// Pass the role name to GenerateTokenPair
-	accessToken, refreshToken, err := jwtPackage.GenerateTokenPair(email, s.Config.GoogleClientSecret, isAdmin, userIDUint, userRole.Name)
+	login, err := s.SessionService.Start(user)
 	if err != nil {
 		return nil, err
 	}
 
 	// Construct AuthPayload and return
 	payload := &AuthPayload{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
+		AccessToken:  login.AccessToken,
+		RefreshToken: login.RefreshToken,
 		TokenType:    "Bearer",
-		ExpiresIn:    int(AccessTokenDuration.Seconds()),
+		ExpiresIn:    s.Config.AccessTokenMinutes * 60,
+		Data:         login,
 	}
 
 	authPayloadOption(payload)
@@ -772,6 +671,12 @@ func (s *Server) handleLogout() gin.HandlerFunc {
 			return
 		}
 
+		if err := s.SessionService.End(c.GetString("session_id")); err != nil {
+			log.Printf("Error ending session: %v", err)
+			respondAndAbort(c, "Logout failed", http.StatusInternalServerError, nil, errs.New("Internal server error", http.StatusInternalServerError))
+			return
+		}
+
 		// Retrieve the user from the context
 		user, exists := c.Get("user")
 		if !exists {
@@ -803,37 +708,7 @@ func (s *Server) handleLogout() gin.HandlerFunc {
 // Handler for updating user profile
 func (s *Server) handleEditUserProfile() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Get the access token from the authorization header
-		accessToken := getTokenFromHeader(c)
-		if accessToken == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-			return
-		}
-
-		// Validate and decode the access token to get the userID
-		secret := s.Config.JWTSecret
-		accessClaims, err := jwtPackage.ValidateAndGetClaims(accessToken, secret)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-			return
-		}
-
-		// Extract userID from accessClaims
-		userIDValue, ok := accessClaims["id"]
-		if !ok {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "UserID not found in claims"})
-			return
-		}
-
-		// Convert userIDValue to uint
-		var userID uint
-		switch v := userIDValue.(type) {
-		case float64:
-			userID = uint(v)
-		default:
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid userID format"})
-			return
-		}
+		userID := c.GetUint("userID")
 
 		// Parse request body into userDetails
 		var userDetails models.EditProfileResponse
@@ -844,6 +719,13 @@ func (s *Server) handleEditUserProfile() gin.HandlerFunc {
 
 		// Call service method to update user details
 		if err := s.AuthService.EditUserProfile(userID, &userDetails); err != nil {
+			if unsupportedLocale(err) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "supported": locale.Supported})
+				return
+			}
+			if respondDisplayNameRejected(c, err) || respondAgeRejected(c, err) {
+				return
+			}
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user details"})
 			return
 		}
@@ -880,6 +762,7 @@ func (s *Server) handleShowProfile() gin.HandlerFunc {
 			"name":         user.Fullname,
 			"profileImage": user.ThumbNailURL,
 			"username":     user.Username,
+			"locale":       locale.For(user.Locale).Tag(),
 		}
 
 		// Return the response with the user's profile data
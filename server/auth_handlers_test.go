@@ -0,0 +1,95 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/mocks"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/services"
+	"go.uber.org/mock/gomock"
+)
+
+func TestShowProfile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	authRepo := mocks.NewMockAuthRepository(ctrl)
+	s := &Server{AuthRepository: authRepo}
+	r := testRouter(http.MethodGet, "/me", models.RoleUser, s.handleShowProfile())
+
+	authRepo.EXPECT().FindUserByID(testUser.ID).Return(testUser, nil)
+	w := serve(t, r, http.MethodGet, "/me", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200: %s", w.Code, w.Body)
+	}
+	var body struct {
+		Data struct {
+			Email    string `json:"email"`
+			Username string `json:"username"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if body.Data.Email != testUser.Email || body.Data.Username != testUser.Username {
+		t.Fatalf("got profile %+v, want the signed-in user's", body.Data)
+	}
+
+	authRepo.EXPECT().FindUserByID(testUser.ID).Return(nil, errors.New("connection refused"))
+	if w := serve(t, r, http.MethodGet, "/me", nil); w.Code != http.StatusInternalServerError {
+		t.Fatalf("got status %d when the lookup fails, want 500", w.Code)
+	}
+}
+
+func TestGoogleSignInTokenAuthorizes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	authRepo := mocks.NewMockAuthRepository(ctrl)
+	sessionRepo := mocks.NewMockSessionRepository(ctrl)
+	conf := &config.Config{JWTSecret: "test-secret", AccessTokenMinutes: 30, SessionIdleMinutes: 60, SessionMaxHours: 24, StepUpMinutes: 5}
+	s := &Server{Config: conf, AuthRepository: authRepo, SessionService: services.NewSessionService(sessionRepo, authRepo, conf)}
+
+	user := &models.User{Model: models.Model{ID: testUser.ID}, Fullname: testUser.Fullname, Email: testUser.Email, RoleID: uuid.New()}
+	var session models.Session
+	authRepo.EXPECT().FindUserByEmail(user.Email).Return(user, nil)
+	authRepo.EXPECT().FindRoleByID(user.RoleID).Return(&models.Role{ID: user.RoleID, Name: models.RoleUser}, nil)
+	sessionRepo.EXPECT().CreateSession(gomock.Any()).DoAndReturn(func(created *models.Session) error {
+		session = *created
+		return nil
+	})
+	payload, err := s.GetGoogleSignInToken(nil, &GoogleUser{Email: user.Email, Name: user.Fullname})
+	if err != nil {
+		t.Fatalf("signing in with Google: %v", err)
+	}
+
+	// The token is checked against the session it was issued for
+	authRepo.EXPECT().IsTokenInBlacklist(payload.AccessToken).Return(false)
+	sessionRepo.EXPECT().FindSession(session.ID).Return(&session, nil)
+	authRepo.EXPECT().FindUserByID(user.ID).Return(user, nil)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/me", s.Authorize(), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"session_id": c.GetString("session_id")})
+	})
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+payload.AccessToken)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d for a Google-issued token, want 200: %s", w.Code, w.Body)
+	}
+	var body struct {
+		SessionID string `json:"session_id"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if body.SessionID != session.ID {
+		t.Fatalf("got session %q, want the one Google sign-in started, %q", body.SessionID, session.ID)
+	}
+}
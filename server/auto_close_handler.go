@@ -0,0 +1,65 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+)
+
+func (s *Server) handleListAutoCloseRules() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rules, err := s.AutoCloseService.ListRules()
+		if err != nil {
+			response.JSON(c, "Failed to load auto-close rules", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Auto-close rules retrieved", http.StatusOK, rules, nil)
+	}
+}
+
+// handleSaveAutoCloseRule creates or replaces the rule for a category and
+// status, e.g. {"status": "pending", "after_days": 90} for every category
+func (s *Server) handleSaveAutoCloseRule() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var rule models.AutoCloseRule
+		if err := c.ShouldBindJSON(&rule); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A status and after_days of at least 1 are required"})
+			return
+		}
+		err := s.AutoCloseService.SaveRule(&rule, c.GetUint("userID"))
+		if errors.Is(err, services.ErrInvalidAutoCloseRule) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			response.JSON(c, "Failed to save auto-close rule", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Auto-close rule saved", http.StatusOK, rule, nil)
+	}
+}
+
+func (s *Server) handleDeleteAutoCloseRule() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid rule ID"})
+			return
+		}
+		err = s.AutoCloseService.DeleteRule(uint(id))
+		if errors.Is(err, services.ErrAutoCloseRuleNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			response.JSON(c, "Failed to delete auto-close rule", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Auto-close rule deleted", http.StatusOK, nil, nil)
+	}
+}
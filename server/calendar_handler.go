@@ -0,0 +1,105 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+)
+
+// handleGetCalendar returns the holidays, elections and seasons falling
+// between ?start_date= and ?end_date=, by default those of this year
+func (s *Server) handleGetCalendar() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start, end, err := db.ParseDayRange(c.Query("start_date"), c.Query("end_date"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		now := time.Now().UTC()
+		from := time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+		to := from.AddDate(1, 0, 0)
+		if start != nil {
+			from = *start
+		}
+		if end != nil {
+			to = *end
+		}
+		calendar, err := s.CalendarService.Between(from, to.AddDate(0, 0, -1))
+		if err != nil {
+			response.JSON(c, "Failed to load calendar", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Calendar retrieved", http.StatusOK, calendar, nil)
+	}
+}
+
+func (s *Server) handleListCalendarEvents() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		calendar, err := s.CalendarService.ListEvents()
+		if err != nil {
+			response.JSON(c, "Failed to load calendar events", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Calendar events retrieved", http.StatusOK, calendar, nil)
+	}
+}
+
+// handleSaveCalendarEvent creates a calendar event, or replaces the one
+// named by :id, e.g. {"kind": "election", "name": "Governorship election",
+// "start_date": "2027-03-06", "end_date": "2027-03-06", "expected_percent": 300}
+func (s *Server) handleSaveCalendarEvent() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var event models.CalendarEvent
+		if err := c.ShouldBindJSON(&event); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A kind, name, start_date and end_date are required"})
+			return
+		}
+		event.ID = 0
+		if param := c.Param("id"); param != "" {
+			id, err := strconv.ParseUint(param, 10, 64)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid calendar event ID"})
+				return
+			}
+			event.ID = uint(id)
+		}
+		err := s.CalendarService.SaveEvent(&event, c.GetUint("userID"))
+		switch {
+		case errors.Is(err, services.ErrInvalidCalendarEvent):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrCalendarEventNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case err != nil:
+			response.JSON(c, "Failed to save calendar event", http.StatusInternalServerError, nil, err)
+		default:
+			response.JSON(c, "Calendar event saved", http.StatusOK, event, nil)
+		}
+	}
+}
+
+func (s *Server) handleDeleteCalendarEvent() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid calendar event ID"})
+			return
+		}
+		err = s.CalendarService.DeleteEvent(uint(id))
+		if errors.Is(err, services.ErrCalendarEventNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			response.JSON(c, "Failed to delete calendar event", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Calendar event deleted", http.StatusOK, nil, nil)
+	}
+}
@@ -0,0 +1,16 @@
+package server
+
+import (
+	"time"
+
+	"github.com/techagentng/citizenx/httpclient"
+)
+
+// The clients handlers call third-party APIs with
+var (
+	// socialLoginClient reads the profile behind a Google or Facebook token
+	socialLoginClient = httpclient.New("social-login", httpclient.Options{Timeout: 10 * time.Second})
+	// googleMapsClient looks up places and coordinates for the location
+	// handlers
+	googleMapsClient = httpclient.New("google-maps", httpclient.Options{Timeout: 10 * time.Second})
+)
@@ -0,0 +1,169 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+)
+
+// handleListCollaborators lists a report's collaborators and their reward
+// shares to the reporter and its collaborators
+func (s *Server) handleListCollaborators() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		collaborators, err := s.CollaboratorService.Collaborators(c.GetUint("userID"), c.Param("id"))
+		if err != nil {
+			respondCollaboratorError(c, err)
+			return
+		}
+		response.JSON(c, "Collaborators retrieved", http.StatusOK, collaborators, nil)
+	}
+}
+
+// handleAddCollaborator adds the user named in the body to the signed-in
+// user's report
+func (s *Server) handleAddCollaborator() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			Username string `json:"username" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A username is required"})
+			return
+		}
+
+		collaborator, err := s.CollaboratorService.AddCollaborator(c.GetUint("userID"), c.Param("id"), body.Username)
+		if err != nil {
+			respondCollaboratorError(c, err)
+			return
+		}
+		response.JSON(c, "Collaborator added", http.StatusCreated, collaborator, nil)
+	}
+}
+
+func (s *Server) handleRemoveCollaborator() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := s.idParam(c, "userID", "user", &models.User{})
+		if !ok {
+			return
+		}
+
+		if err := s.CollaboratorService.RemoveCollaborator(c.GetUint("userID"), c.Param("id"), userID); err != nil {
+			respondCollaboratorError(c, err)
+			return
+		}
+		response.JSON(c, "Collaborator removed", http.StatusOK, nil, nil)
+	}
+}
+
+// handleSetRewardShares replaces the reward shares of a report's
+// collaborators with those in the body
+func (s *Server) handleSetRewardShares() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			Shares []struct {
+				UserID uint `json:"user_id"`
+				Share  int  `json:"share"`
+			} `json:"shares"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+		shares := make(map[uint]int, len(body.Shares))
+		for _, share := range body.Shares {
+			shares[share.UserID] = share.Share
+		}
+
+		if err := s.CollaboratorService.SetRewardShares(c.GetUint("userID"), c.Param("reportID"), shares); err != nil {
+			respondCollaboratorError(c, err)
+			return
+		}
+		response.JSON(c, "Reward shares updated", http.StatusOK, nil, nil)
+	}
+}
+
+// handleAddReportUpdate adds a text update to a report by its reporter or a
+// collaborator
+func (s *Server) handleAddReportUpdate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			Text string `json:"text"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		update, err := s.CollaboratorService.AddUpdate(c.GetUint("userID"), c.Param("id"), body.Text)
+		if err != nil {
+			respondCollaboratorError(c, err)
+			return
+		}
+		response.JSON(c, "Update added", http.StatusCreated, update, nil)
+	}
+}
+
+// handleAddReportMedia appends the mediaFiles of a multipart form to a
+// report, attributed to the reporter or collaborator who sent them
+func (s *Server) handleAddReportMedia() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reportID := c.Param("id")
+		userID := c.GetUint("userID")
+		if err := s.CollaboratorService.CanContribute(userID, reportID); err != nil {
+			respondCollaboratorError(c, err)
+			return
+		}
+		form, err := c.MultipartForm()
+		if err != nil || len(form.File["mediaFiles"]) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "No media files found in the request"})
+			return
+		}
+		media, ok := s.processReportMedia(c, form.File["mediaFiles"], userID, reportID)
+		if !ok {
+			return
+		}
+
+		contributions, err := s.CollaboratorService.AddMedia(userID, reportID, media)
+		if err != nil {
+			respondCollaboratorError(c, err)
+			return
+		}
+		response.JSON(c, "Media added to report", http.StatusCreated, contributions, nil)
+	}
+}
+
+// handleListContributions lists the media and updates added to a report
+// and who added each
+func (s *Server) handleListContributions() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, ok := incidentPage(c)
+		if !ok {
+			return
+		}
+		contributions, err := s.CollaboratorService.Contributions(c.Param("id"), page)
+		if err != nil {
+			respondCollaboratorError(c, err)
+			return
+		}
+		response.JSON(c, "Contributions retrieved", http.StatusOK, contributions, nil)
+	}
+}
+
+func respondCollaboratorError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidRewardShare), errors.Is(err, services.ErrInvalidContribution):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrReportNotFound), errors.Is(err, services.ErrCollaboratorNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrNotReportOwner), errors.Is(err, services.ErrNotContributor):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrAlreadyCollaborator), errors.Is(err, services.ErrTooManyCollaborators):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		response.JSON(c, "Failed to update collaborators", http.StatusInternalServerError, nil, err)
+	}
+}
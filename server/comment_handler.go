@@ -0,0 +1,105 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/policy"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+)
+
+// handleListComments pages through the threads under a report, oldest
+// first, each with its replies
+func (s *Server) handleListComments() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, ok := incidentPage(c)
+		if !ok {
+			return
+		}
+		comments, err := s.CommentService.ListComments(c.Param("id"), page)
+		if err != nil {
+			respondCommentError(c, err)
+			return
+		}
+		response.JSON(c, "Comments retrieved", http.StatusOK, comments, nil)
+	}
+}
+
+// handleAddComment comments on a report, e.g. {"body": "Still there this
+// morning"}, or replies to a comment when the body has a parent_id
+func (s *Server) handleAddComment() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request models.ReportCommentRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A comment body is required"})
+			return
+		}
+		comment, err := s.CommentService.AddComment(c.GetUint("userID"), c.Param("id"), &request)
+		if err != nil {
+			respondCommentError(c, err)
+			return
+		}
+		response.JSON(c, "Comment added", http.StatusCreated, comment, nil)
+	}
+}
+
+func (s *Server) handleEditComment() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		commentID, ok := commentParam(c)
+		if !ok {
+			return
+		}
+		var request models.ReportCommentRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A comment body is required"})
+			return
+		}
+		comment, err := s.CommentService.EditComment(c.GetUint("userID"), c.Param("reportID"), commentID, request.Body)
+		if err != nil {
+			respondCommentError(c, err)
+			return
+		}
+		response.JSON(c, "Comment updated", http.StatusOK, comment, nil)
+	}
+}
+
+// handleDeleteComment removes a comment by its author or an admin
+func (s *Server) handleDeleteComment() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		commentID, ok := commentParam(c)
+		if !ok {
+			return
+		}
+		if err := s.CommentService.DeleteComment(subject(c), c.Param("id"), commentID); err != nil {
+			respondCommentError(c, err)
+			return
+		}
+		response.JSON(c, "Comment deleted", http.StatusOK, nil, nil)
+	}
+}
+
+func commentParam(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("commentID"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid comment ID"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func respondCommentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidComment):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrReportNotFound), errors.Is(err, services.ErrCommentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, policy.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		response.JSON(c, "Failed to update comments", http.StatusInternalServerError, nil, err)
+	}
+}
@@ -0,0 +1,26 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/server/response"
+)
+
+// handleGetClientConfig tells clients what they need to know before the
+// user reports: which categories are restricted to submission windows and
+// whether they are open now
+func (s *Server) handleGetClientConfig() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		windows, err := s.SubmissionWindowService.Availability(time.Now())
+		if err != nil {
+			response.JSON(c, "Failed to load client config", http.StatusInternalServerError, nil, err)
+			return
+		}
+		c.Header("Cache-Control", "public, max-age=60")
+		response.JSON(c, "Client config retrieved", http.StatusOK, gin.H{
+			"submission_windows": windows,
+		}, nil)
+	}
+}
@@ -0,0 +1,159 @@
+package server
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+)
+
+// partnerPageSize is how many reports a page of the partner API holds, and
+// partnerExportBatch how many an export reads at a time
+const (
+	partnerPageSize    = 100
+	partnerExportBatch = 500
+)
+
+func (s *Server) handleListDataShares() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		shares, err := s.DataShareService.ListShares()
+		if err != nil {
+			response.JSON(c, "Failed to load data-sharing agreements", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Data-sharing agreements retrieved", http.StatusOK, shares, nil)
+	}
+}
+
+// handleCreateDataShare records an agreement with a partner, e.g.
+// {"partner": "Lagos Roads Trust", "states": ["Lagos"], "categories":
+// ["Road"], "pii_level": "none", "expires_at": 1798761600}. The API key in
+// the response is shown only once.
+func (s *Server) handleCreateDataShare() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request models.DataShareRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A partner, expires_at and a pii_level of none, pseudonymous or full are required"})
+			return
+		}
+		share, key, err := s.DataShareService.CreateShare(&request, c.GetUint("userID"))
+		if errors.Is(err, services.ErrInvalidDataShare) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			response.JSON(c, "Failed to create data-sharing agreement", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Data-sharing agreement created", http.StatusCreated, gin.H{
+			"data_share": share,
+			"api_key":    key,
+		}, nil)
+	}
+}
+
+func (s *Server) handleRevokeDataShare() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid agreement ID"})
+			return
+		}
+		err = s.DataShareService.RevokeShare(uint(id))
+		if errors.Is(err, services.ErrDataShareNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			response.JSON(c, "Failed to revoke data-sharing agreement", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Data-sharing agreement revoked", http.StatusOK, nil, nil)
+	}
+}
+
+// partnerPeriod reads the since and until parameters, Unix times that
+// default to every report so far, answering 400 when they are invalid
+func partnerPeriod(c *gin.Context) (int64, int64, bool) {
+	since, err := strconv.ParseInt(c.DefaultQuery("since", "0"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "since must be a Unix time"})
+		return 0, 0, false
+	}
+	until, err := strconv.ParseInt(c.DefaultQuery("until", strconv.FormatInt(time.Now().Unix(), 10)), 10, 64)
+	if err != nil || until < since {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "until must be a Unix time after since"})
+		return 0, 0, false
+	}
+	return since, until, true
+}
+
+// handleGetPartnerReports serves a page of the reports the partner's
+// agreement covers, oldest first
+func (s *Server) handleGetPartnerReports() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		since, until, ok := partnerPeriod(c)
+		if !ok {
+			return
+		}
+		page, ok := incidentPage(c)
+		if !ok {
+			return
+		}
+		share := c.MustGet("data_share").(*models.DataShare)
+		reports, err := s.DataShareService.Reports(share, since, until, page, partnerPageSize)
+		if err != nil {
+			response.JSON(c, "Failed to load reports", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Reports retrieved", http.StatusOK, gin.H{
+			"reports":   reports,
+			"page":      page,
+			"page_size": partnerPageSize,
+		}, nil)
+	}
+}
+
+// handleExportPartnerReports streams every report the partner's agreement
+// covers in the period as CSV
+func (s *Server) handleExportPartnerReports() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		since, until, ok := partnerPeriod(c)
+		if !ok {
+			return
+		}
+		share := c.MustGet("data_share").(*models.DataShare)
+		c.Header("Content-Type", "text/csv")
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="citizenx-reports-%d-%d.csv"`, since, until))
+		w := csv.NewWriter(c.Writer)
+		w.Write([]string{"id", "category", "sub_report_type", "description", "state_name", "lga_name", "latitude", "longitude", "status", "created_at", "resolved_at", "reporter", "reporter_username"})
+		for page := 1; ; page++ {
+			reports, err := s.DataShareService.Reports(share, since, until, page, partnerExportBatch)
+			if err != nil {
+				// The header has gone out, so the export just ends short
+				log.Printf("exporting reports for data share %d: %v", share.ID, err)
+				break
+			}
+			for _, r := range reports {
+				w.Write([]string{
+					r.ID, r.Category, r.SubReportType, r.Description, r.StateName, r.LGAName,
+					strconv.FormatFloat(r.Latitude, 'f', -1, 64), strconv.FormatFloat(r.Longitude, 'f', -1, 64),
+					r.Status, strconv.FormatInt(r.CreatedAt, 10), strconv.FormatInt(r.ResolvedAt, 10),
+					r.Reporter, r.ReporterUsername,
+				})
+			}
+			w.Flush()
+			if len(reports) < partnerExportBatch {
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"errors"
+	"html/template"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+)
+
+func (s *Server) handleListDigests() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		subs, err := s.DigestService.ListSubscriptions(c.GetUint("userID"))
+		if err != nil {
+			response.JSON(c, "Failed to load digests", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Digests retrieved", http.StatusOK, subs, nil)
+	}
+}
+
+// handleSubscribeDigest subscribes the signed-in user to an email digest,
+// e.g. {"frequency": "weekly", "state_name": "Lagos", "category": "Roads"}
+func (s *Server) handleSubscribeDigest() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var sub models.DigestSubscription
+		if err := c.ShouldBindJSON(&sub); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "frequency must be daily or weekly"})
+			return
+		}
+		err := s.DigestService.Subscribe(c.GetUint("userID"), &sub)
+		switch {
+		case errors.Is(err, services.ErrDigestExists), errors.Is(err, services.ErrTooManyDigests):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case err != nil:
+			response.JSON(c, "Failed to subscribe to digest", http.StatusInternalServerError, nil, err)
+		default:
+			response.JSON(c, "Subscribed to digest", http.StatusCreated, sub, nil)
+		}
+	}
+}
+
+func (s *Server) handleUnsubscribeDigest() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid digest ID"})
+			return
+		}
+		err = s.DigestService.Unsubscribe(c.GetUint("userID"), uint(id))
+		if errors.Is(err, services.ErrDigestNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			response.JSON(c, "Failed to unsubscribe from digest", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Unsubscribed from digest", http.StatusOK, nil, nil)
+	}
+}
+
+// unsubscribePage is what the unsubscribe link of a digest email opens.
+// With Confirm set it asks before unsubscribing, since mail scanners follow
+// links in emails and must not unsubscribe anyone by doing so.
+var unsubscribePage = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><meta name="viewport" content="width=device-width, initial-scale=1"><title>CitizenX digest</title></head>
+<body style="font-family: sans-serif; max-width: 480px; margin: 40px auto; padding: 0 16px;">
+{{if .Confirm}}<p>Stop receiving this CitizenX report digest?</p>
+<form method="post" action="?token={{.Token}}"><button type="submit">Unsubscribe</button></form>
+{{else}}<p>{{.Message}}</p>{{end}}
+</body>
+</html>
+`))
+
+type unsubscribePageData struct {
+	Confirm bool
+	Token   string
+	Message string
+}
+
+func renderUnsubscribePage(c *gin.Context, status int, data unsubscribePageData) {
+	c.Status(status)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	if err := unsubscribePage.Execute(c.Writer, data); err != nil {
+		c.Error(err)
+	}
+}
+
+// handleConfirmDigestUnsubscribe opens the unsubscribe link of a digest
+// email, asking the reader to confirm
+func (s *Server) handleConfirmDigestUnsubscribe() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := c.Query("token")
+		if err := s.DigestService.CheckUnsubscribeToken(token); err != nil {
+			renderUnsubscribePage(c, http.StatusBadRequest, unsubscribePageData{Message: err.Error()})
+			return
+		}
+		renderUnsubscribePage(c, http.StatusOK, unsubscribePageData{Confirm: true, Token: token})
+	}
+}
+
+// handleUnsubscribeDigestLink ends a digest from the link in its email,
+// without signing in, once the reader confirms. Mail clients' one-click
+// unsubscribe POSTs to the same link.
+func (s *Server) handleUnsubscribeDigestLink() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := s.DigestService.UnsubscribeByToken(c.Query("token"))
+		if errors.Is(err, services.ErrInvalidUnsubscribeToken) {
+			renderUnsubscribePage(c, http.StatusBadRequest, unsubscribePageData{Message: err.Error()})
+			return
+		}
+		if err != nil {
+			renderUnsubscribePage(c, http.StatusInternalServerError, unsubscribePageData{Message: "We could not unsubscribe you just now. Please try again."})
+			return
+		}
+		renderUnsubscribePage(c, http.StatusOK, unsubscribePageData{Message: "You will no longer receive this digest."})
+	}
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+)
+
+// respondDisplayNameRejected answers 422 with the rule a name broke when err
+// is a display-name policy rejection, and reports whether it did
+func respondDisplayNameRejected(c *gin.Context, err error) bool {
+	var rejected *services.DisplayNameError
+	if !errors.As(err, &rejected) {
+		return false
+	}
+	c.JSON(http.StatusUnprocessableEntity, gin.H{
+		"error":     rejected.Error(),
+		"rejection": rejected,
+		"appeal":    "Choose another name, or appeal at POST /me/display-name-appeals once signed in",
+	})
+	return true
+}
+
+// handleAppealDisplayName asks admins to allow a name the display-name
+// policy rejected
+func (s *Server) handleAppealDisplayName() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			Field   string `json:"field" binding:"required"`
+			Name    string `json:"name" binding:"required"`
+			Message string `json:"message"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A field and name are required"})
+			return
+		}
+
+		appeal, err := s.DisplayNameService.Appeal(c.GetUint("userID"), body.Field, body.Name, body.Message)
+		if err != nil {
+			respondAppealError(c, err)
+			return
+		}
+		response.JSON(c, "Appeal submitted", http.StatusCreated, appeal, nil)
+	}
+}
+
+func (s *Server) handleListMyDisplayNameAppeals() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		appeals, err := s.DisplayNameService.UserAppeals(c.GetUint("userID"))
+		if err != nil {
+			response.JSON(c, "Failed to load appeals", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Appeals retrieved", http.StatusOK, appeals, nil)
+	}
+}
+
+// handleListDisplayNameAppeals lists the appeal queue, only appeals with
+// ?status= when given
+func (s *Server) handleListDisplayNameAppeals() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, ok := incidentPage(c)
+		if !ok {
+			return
+		}
+		appeals, err := s.DisplayNameService.ListAppeals(c.Query("status"), page)
+		if err != nil {
+			response.JSON(c, "Failed to load appeals", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Appeals retrieved", http.StatusOK, appeals, nil)
+	}
+}
+
+// handleReviewDisplayNameAppeal approves or rejects an appeal; approving it
+// sets the name on the user's profile
+func (s *Server) handleReviewDisplayNameAppeal() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		appealID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid appeal ID"})
+			return
+		}
+		var body struct {
+			Approve *bool  `json:"approve" binding:"required"`
+			Note    string `json:"note"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "approve must be true or false"})
+			return
+		}
+
+		if err := s.DisplayNameService.ReviewAppeal(c.GetUint("userID"), uint(appealID), *body.Approve, body.Note); err != nil {
+			respondAppealError(c, err)
+			return
+		}
+		response.JSON(c, "Appeal reviewed", http.StatusOK, nil, nil)
+	}
+}
+
+func respondAppealError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidAppeal), errors.Is(err, services.ErrDisplayNameAllowed):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrAppealNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrAppealPending), errors.Is(err, services.ErrAppealReviewed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		response.JSON(c, "Failed to process appeal", http.StatusInternalServerError, nil, err)
+	}
+}
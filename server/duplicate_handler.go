@@ -0,0 +1,66 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/serializers"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+)
+
+// handleGetReportDuplicates lists the reports that look like duplicates of
+// a report, with the cluster they were grouped in
+func (s *Server) handleGetReportDuplicates() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		duplicates, err := s.DuplicateService.Duplicates(c.Param("id"))
+		if errors.Is(err, services.ErrReportNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			response.JSON(c, "Failed to load duplicate reports", http.StatusInternalServerError, nil, err)
+			return
+		}
+		sub := subject(c)
+		reports := make([]interface{}, len(duplicates.Reports))
+		for i := range duplicates.Reports {
+			report := &duplicates.Reports[i]
+			reports[i] = serializers.Report(report, serializers.AudienceFor(sub, report))
+		}
+		response.JSON(c, "Duplicate reports retrieved", http.StatusOK, gin.H{
+			"possible_duplicate": duplicates.Report.PossibleDuplicate,
+			"cluster":            duplicates.Cluster,
+			"reports":            reports,
+		}, nil)
+	}
+}
+
+// handleGetReportHistoryContext tells how often the thing a report
+// describes was reported before at the same place, with examples
+func (s *Server) handleGetReportHistoryContext() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		history, err := s.DuplicateService.HistoryContext(c.Param("id"))
+		if errors.Is(err, services.ErrReportNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			response.JSON(c, "Failed to load report history", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Report history retrieved", http.StatusOK, gin.H{
+			"summary":           history.Summary,
+			"category":          history.Report.Category,
+			"radius_m":          history.RadiusM,
+			"lga_name":          history.Report.LGAName,
+			"reports":           history.History.Reports,
+			"incidents":         history.History.Incidents,
+			"resolved":          history.History.Resolved,
+			"first_reported_at": history.History.FirstReportAt,
+			"last_reported_at":  history.History.LastReportAt,
+			"examples":          serializers.PublicReports(history.Examples),
+		}, nil)
+	}
+}
@@ -0,0 +1,55 @@
+package server
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/techagentng/citizenx/services"
+)
+
+func (s *Server) handleExportEvidence() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reportID := c.Param("id")
+		if _, err := uuid.Parse(reportID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid report ID"})
+			return
+		}
+
+		bundle, err := s.EvidenceService.ExportBundle(reportID, c.GetUint("userID"))
+		switch {
+		case errors.Is(err, services.ErrReportNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		case errors.Is(err, services.ErrReportNotVerified):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		case errors.Is(err, services.ErrEvidenceDisabled):
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+			return
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=report-%s-evidence.zip", reportID))
+		c.Data(http.StatusOK, "application/zip", bundle)
+	}
+}
+
+func (s *Server) handleGetEvidencePublicKey() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key, err := s.EvidenceService.PublicKey()
+		if err != nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"algorithm":  "ed25519",
+			"public_key": base64.StdEncoding.EncodeToString(key),
+		})
+	}
+}
@@ -120,7 +120,7 @@ func SignInUser(facebookUserDetails FacebookUser) (string, error) {
 func GetUserInfoFromFacebook(token string) (FacebookUser, error) {
 	var fbUserDetails FacebookUser
 	facebookUserDetailsRequest, _ := http.NewRequest("GET", "https://graph.facebook.com/me?fields=id,name,email&access_token="+token, nil)
-	facebookUserDetailsResponse, facebookUserDetailsResponseError := http.DefaultClient.Do(facebookUserDetailsRequest)
+	facebookUserDetailsResponse, facebookUserDetailsResponseError := socialLoginClient.Do(facebookUserDetailsRequest)
 
 	if facebookUserDetailsResponseError != nil {
 		return FacebookUser{}, fmt.Errorf("Error occurred while getting information from Facebook")
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+)
+
+// handleSubmitFeedback takes a bug report or suggestion about the app as a
+// multipart form: category, text, app_version, platform, os_version,
+// device_model and an optional screenshot image
+func (s *Server) handleSubmitFeedback() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request models.FeedbackRequest
+		if err := c.ShouldBind(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A category of bug, suggestion, content or other and text of up to 5000 characters are required"})
+			return
+		}
+
+		var screenshot *services.FeedbackScreenshot
+		if file, fileHeader, err := c.Request.FormFile("screenshot"); err == nil {
+			defer file.Close()
+			if err := s.validateFile(fileHeader); err != nil {
+				if !respondValidationError(c, err) {
+					c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				}
+				return
+			}
+			screenshot = &services.FeedbackScreenshot{
+				Body:        file,
+				Size:        fileHeader.Size,
+				ContentType: fileHeader.Header.Get("Content-Type"),
+				Filename:    fileHeader.Filename,
+			}
+		} else if !errors.Is(err, http.ErrMissingFile) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid screenshot"})
+			return
+		}
+
+		feedback, err := s.FeedbackService.Submit(c.GetUint("userID"), &request, screenshot)
+		if err != nil {
+			response.JSON(c, "Failed to save feedback", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Thank you for your feedback", http.StatusCreated, gin.H{"id": feedback.ID}, nil)
+	}
+}
+
+// handleListFeedback lists feedback for triage, newest first, optionally
+// filtered by ?status= and ?category=
+func (s *Server) handleListFeedback() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, ok := incidentPage(c)
+		if !ok {
+			return
+		}
+		feedback, err := s.FeedbackService.List(c.Query("status"), c.Query("category"), page)
+		if err != nil {
+			response.JSON(c, "Failed to load feedback", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Feedback retrieved", http.StatusOK, feedback, nil)
+	}
+}
+
+func (s *Server) handleGetFeedback() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid feedback ID"})
+			return
+		}
+		feedback, err := s.FeedbackService.Get(uint(id))
+		if errors.Is(err, services.ErrFeedbackNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			response.JSON(c, "Failed to load feedback", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Feedback retrieved", http.StatusOK, feedback, nil)
+	}
+}
+
+// handleTriageFeedback sets the status of feedback, e.g. {"status":
+// "triaged", "note": "Duplicate of the map crash on Android 10"}
+func (s *Server) handleTriageFeedback() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid feedback ID"})
+			return
+		}
+		var triage models.FeedbackTriage
+		if err := c.ShouldBindJSON(&triage); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "status must be new, triaged or closed"})
+			return
+		}
+		err = s.FeedbackService.Triage(uint(id), &triage, c.GetUint("userID"))
+		if errors.Is(err, services.ErrFeedbackNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			response.JSON(c, "Failed to triage feedback", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Feedback triaged", http.StatusOK, nil, nil)
+	}
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/db"
+)
+
+// parseDateRange reads the inclusive start_date and end_date days. Missing
+// bounds default to a range ending now and starting the given number of days
+// earlier
+func parseDateRange(c *gin.Context, days int) (time.Time, time.Time, error) {
+	from, to, err := db.ParseDayRange(c.Query("start_date"), c.Query("end_date"))
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	end := time.Now()
+	if to != nil {
+		end = *to
+	}
+	start := end.AddDate(0, 0, -days)
+	if from != nil {
+		start = *from
+	}
+	return start, end, nil
+}
+
+// handleGetSignups serves signups grouped by day, week, month or state
+func (s *Server) handleGetSignups() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start, end, err := parseDateRange(c, 30)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		groupBy := c.DefaultQuery("group_by", "day")
+		signups, err := s.GrowthService.Signups(groupBy, start, end)
+		if err != nil {
+			respondAggregateError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"group_by": groupBy, "signups": signups})
+	}
+}
+
+// handleGetActiveUsers serves DAU/WAU/MAU as of the end of date, today by
+// default
+func (s *Server) handleGetActiveUsers() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		asOf := time.Now()
+		if v := c.Query("date"); v != "" {
+			t, err := time.Parse("2006-01-02", v)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date format, expected YYYY-MM-DD"})
+				return
+			}
+			asOf = t.AddDate(0, 0, 1)
+		}
+
+		active, daily, err := s.GrowthService.ActiveUsers(asOf)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"active_users": active, "daily": daily})
+	}
+}
+
+// handleGetRetention serves the cohort retention table
+func (s *Server) handleGetRetention() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		periods, err := strconv.Atoi(c.DefaultQuery("periods", "8"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid periods"})
+			return
+		}
+
+		interval := c.DefaultQuery("interval", "week")
+		cohorts, err := s.GrowthService.Retention(interval, periods)
+		if err != nil {
+			if errors.Is(err, db.ErrUnknownInterval) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"interval": interval, "cohorts": cohorts})
+	}
+}
@@ -0,0 +1,182 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+)
+
+// respondHelpError maps help centre errors to responses
+func respondHelpError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, services.ErrHelpArticleNotFound), errors.Is(err, services.ErrHelpRevisionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case unsupportedLocale(err):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		response.JSON(c, message, http.StatusInternalServerError, nil, err)
+	}
+}
+
+// helpArticleID parses the :id route parameter
+func helpArticleID(c *gin.Context) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid article ID"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// handleGetHelp returns the published help in the request's locale,
+// optionally only of one ?kind= (faq, guide or tip) or ?category=
+func (s *Server) handleGetHelp() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		content, err := s.HelpService.Content(requestLocale(c).Tag(), c.Query("kind"), c.Query("category"))
+		if err != nil {
+			respondHelpError(c, "Failed to load help", err)
+			return
+		}
+		c.Header("Cache-Control", "public, max-age=300")
+		c.Header("Vary", "Accept-Language")
+		response.JSON(c, "Help retrieved", http.StatusOK, content, nil)
+	}
+}
+
+func (s *Server) handleGetHelpArticle() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		article, err := s.HelpService.Article(requestLocale(c).Tag(), c.Param("slug"))
+		if err != nil {
+			respondHelpError(c, "Failed to load help article", err)
+			return
+		}
+		c.Header("Cache-Control", "public, max-age=300")
+		c.Header("Vary", "Accept-Language")
+		response.JSON(c, "Help article retrieved", http.StatusOK, article, nil)
+	}
+}
+
+func (s *Server) handleListHelpArticles() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		articles, err := s.HelpService.ListArticles()
+		if err != nil {
+			respondHelpError(c, "Failed to load help articles", err)
+			return
+		}
+		response.JSON(c, "Help articles retrieved", http.StatusOK, articles, nil)
+	}
+}
+
+// handleCreateHelpArticle creates an article with no text, e.g. {"slug":
+// "how-to-report-flooding", "kind": "guide", "category": "Flood"}. Its text
+// is added as revisions per locale.
+func (s *Server) handleCreateHelpArticle() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var article models.HelpArticle
+		if err := c.ShouldBindJSON(&article); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A slug and a kind of faq, guide or tip are required"})
+			return
+		}
+		if err := s.HelpService.CreateArticle(&article, c.GetUint("userID")); err != nil {
+			respondHelpError(c, "Failed to save help article", err)
+			return
+		}
+		response.JSON(c, "Help article saved", http.StatusCreated, article, nil)
+	}
+}
+
+func (s *Server) handleUpdateHelpArticle() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := helpArticleID(c)
+		if !ok {
+			return
+		}
+		var article models.HelpArticle
+		if err := c.ShouldBindJSON(&article); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A slug and a kind of faq, guide or tip are required"})
+			return
+		}
+		if err := s.HelpService.UpdateArticle(id, &article); err != nil {
+			respondHelpError(c, "Failed to update help article", err)
+			return
+		}
+		response.JSON(c, "Help article updated", http.StatusOK, article, nil)
+	}
+}
+
+func (s *Server) handleDeleteHelpArticle() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := helpArticleID(c)
+		if !ok {
+			return
+		}
+		if err := s.HelpService.DeleteArticle(id); err != nil {
+			respondHelpError(c, "Failed to delete help article", err)
+			return
+		}
+		response.JSON(c, "Help article deleted", http.StatusOK, nil, nil)
+	}
+}
+
+func (s *Server) handleListHelpRevisions() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := helpArticleID(c)
+		if !ok {
+			return
+		}
+		revisions, err := s.HelpService.Revisions(id)
+		if err != nil {
+			respondHelpError(c, "Failed to load help revisions", err)
+			return
+		}
+		response.JSON(c, "Help revisions retrieved", http.StatusOK, revisions, nil)
+	}
+}
+
+// handleAddHelpRevision drafts the next version of an article's text in a
+// locale, e.g. {"locale": "yo", "title": "...", "body": "..."}
+func (s *Server) handleAddHelpRevision() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := helpArticleID(c)
+		if !ok {
+			return
+		}
+		var request models.HelpRevisionRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A locale, title and body are required"})
+			return
+		}
+		revision, err := s.HelpService.AddRevision(id, &request, c.GetUint("userID"))
+		if err != nil {
+			respondHelpError(c, "Failed to save help revision", err)
+			return
+		}
+		response.JSON(c, "Help revision saved", http.StatusCreated, revision, nil)
+	}
+}
+
+// handlePublishHelpRevision makes a revision the one clients see. Publishing
+// an earlier revision rolls the article back to it.
+func (s *Server) handlePublishHelpRevision() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := helpArticleID(c)
+		if !ok {
+			return
+		}
+		revisionID, err := strconv.ParseUint(c.Param("revisionID"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid revision ID"})
+			return
+		}
+		if err := s.HelpService.Publish(id, uint(revisionID)); err != nil {
+			respondHelpError(c, "Failed to publish help revision", err)
+			return
+		}
+		response.JSON(c, "Help revision published", http.StatusOK, nil, nil)
+	}
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+)
+
+// respondIdentityError maps identity verification errors to responses
+func respondIdentityError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, services.ErrIdentityUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrIdentityAlreadyVerified), errors.Is(err, services.ErrIdentityInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrIdentityMismatch):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrIdentityAttempts):
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrIdentityNotVerified):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		response.JSON(c, message, http.StatusInternalServerError, nil, err)
+	}
+}
+
+func (s *Server) handleGetIdentityStatus() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		verification, err := s.IdentityService.Status(c.GetUint("userID"))
+		if err != nil {
+			respondIdentityError(c, "Failed to load identity verification", err)
+			return
+		}
+		response.JSON(c, "Identity verification retrieved", http.StatusOK, verification, nil)
+	}
+}
+
+// handleVerifyIdentity verifies the user with their NIN or BVN, e.g.
+// {"method": "nin", "number": "12345678901"}. The number itself is not
+// kept.
+func (s *Server) handleVerifyIdentity() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request models.IdentityVerificationRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "method must be nin or bvn and number its 11 digits"})
+			return
+		}
+		verification, err := s.IdentityService.Verify(c.GetUint("userID"), &request)
+		if err != nil {
+			respondIdentityError(c, "Failed to verify identity", err)
+			return
+		}
+		response.JSON(c, "Identity verified", http.StatusOK, verification, nil)
+	}
+}
+
+// handleRevokeIdentity withdraws a user's identity verification
+func (s *Server) handleRevokeIdentity() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := s.idParam(c, "id", "user", &models.User{})
+		if !ok {
+			return
+		}
+		if err := s.IdentityService.Revoke(userID); err != nil {
+			respondIdentityError(c, "Failed to revoke identity verification", err)
+			return
+		}
+		response.JSON(c, "Identity verification revoked", http.StatusOK, nil, nil)
+	}
+}
@@ -0,0 +1,177 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/serializers"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+)
+
+// respondIncidentError writes the response for an incident service error
+func respondIncidentError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, services.ErrIncidentNotFound), errors.Is(err, services.ErrReportNotInIncident):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidIncidentStatus), errors.Is(err, services.ErrInvalidReportIDs):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		response.JSON(c, message, http.StatusInternalServerError, nil, err)
+	}
+}
+
+// incidentPage reads the page query parameter, answering 400 when it is
+// invalid
+func incidentPage(c *gin.Context) (int, bool) {
+	page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+	if err != nil || page < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page number"})
+		return 0, false
+	}
+	return page, true
+}
+
+// handleListIncidents lists incidents, optionally only those with ?status=
+func (s *Server) handleListIncidents() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, ok := incidentPage(c)
+		if !ok {
+			return
+		}
+		incidents, err := s.IncidentGroupService.ListIncidents(c.Query("status"), page)
+		if err != nil {
+			respondIncidentError(c, "Failed to load incidents", err)
+			return
+		}
+		response.JSON(c, "Incidents retrieved", http.StatusOK, incidents, nil)
+	}
+}
+
+func (s *Server) handleGetIncident() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		incident, err := s.IncidentGroupService.GetIncident(c.Param("id"))
+		if err != nil {
+			respondIncidentError(c, "Failed to load incident", err)
+			return
+		}
+		response.JSON(c, "Incident retrieved", http.StatusOK, incident, nil)
+	}
+}
+
+func (s *Server) handleGetIncidentReports() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, ok := incidentPage(c)
+		if !ok {
+			return
+		}
+		reports, err := s.IncidentGroupService.IncidentReports(c.Param("id"), page)
+		if err != nil {
+			respondIncidentError(c, "Failed to load incident reports", err)
+			return
+		}
+		response.JSON(c, "Incident reports retrieved", http.StatusOK, serializers.PublicReports(reports), nil)
+	}
+}
+
+func (s *Server) handleGetIncidentTimeline() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		timeline, err := s.IncidentGroupService.IncidentTimeline(c.Param("id"))
+		if err != nil {
+			respondIncidentError(c, "Failed to load incident timeline", err)
+			return
+		}
+		response.JSON(c, "Incident timeline retrieved", http.StatusOK, timeline, nil)
+	}
+}
+
+func (s *Server) handleCreateIncident() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var incident models.Incident
+		if err := c.ShouldBindJSON(&incident); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "An incident title is required"})
+			return
+		}
+		if err := s.IncidentGroupService.CreateIncident(&incident, c.GetUint("userID")); err != nil {
+			respondIncidentError(c, "Failed to create incident", err)
+			return
+		}
+		response.JSON(c, "Incident created", http.StatusCreated, incident, nil)
+	}
+}
+
+func (s *Server) handleUpdateIncident() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var changes models.Incident
+		if err := c.ShouldBindJSON(&changes); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "An incident title is required"})
+			return
+		}
+		incident, err := s.IncidentGroupService.UpdateIncident(c.Param("id"), &changes, c.GetUint("userID"))
+		if err != nil {
+			respondIncidentError(c, "Failed to update incident", err)
+			return
+		}
+		response.JSON(c, "Incident updated", http.StatusOK, incident, nil)
+	}
+}
+
+// handleDeleteIncident removes an incident; its reports are ungrouped, not
+// deleted
+func (s *Server) handleDeleteIncident() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := s.IncidentGroupService.DeleteIncident(c.Param("id")); err != nil {
+			respondIncidentError(c, "Failed to delete incident", err)
+			return
+		}
+		response.JSON(c, "Incident deleted", http.StatusOK, nil, nil)
+	}
+}
+
+func (s *Server) handleAddIncidentUpdate() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var update models.IncidentUpdate
+		if err := c.ShouldBindJSON(&update); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A message is required"})
+			return
+		}
+		if err := s.IncidentGroupService.AddUpdate(c.Param("id"), &update, c.GetUint("userID")); err != nil {
+			respondIncidentError(c, "Failed to add incident update", err)
+			return
+		}
+		response.JSON(c, "Incident update added", http.StatusCreated, update, nil)
+	}
+}
+
+// handleAttachIncidentReports groups reports under an incident, taking
+// {"report_ids": [...]}
+func (s *Server) handleAttachIncidentReports() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			ReportIDs []string `json:"report_ids" binding:"required,min=1"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "report_ids is required"})
+			return
+		}
+		attached, err := s.IncidentGroupService.AttachReports(c.Param("id"), body.ReportIDs)
+		if err != nil {
+			respondIncidentError(c, "Failed to attach reports", err)
+			return
+		}
+		response.JSON(c, "Reports attached", http.StatusOK, gin.H{"attached": attached}, nil)
+	}
+}
+
+func (s *Server) handleDetachIncidentReport() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := s.IncidentGroupService.DetachReport(c.Param("id"), c.Param("reportID")); err != nil {
+			respondIncidentError(c, "Failed to detach report", err)
+			return
+		}
+		response.JSON(c, "Report detached", http.StatusOK, nil, nil)
+	}
+}
@@ -19,9 +19,13 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
+	"github.com/techagentng/citizenx/db"
 	"github.com/techagentng/citizenx/errors"
+	"github.com/techagentng/citizenx/geo"
 	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/serializers"
 	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
 	"gorm.io/gorm"
 )
 
@@ -204,7 +208,7 @@ func generateIDx() uuid.UUID {
 func fetchGeocodingData(lat, lng float64, c *gin.Context, reportID string) (*models.LGA, *models.State, *models.ReportType, string, string, error) {
 	apiKey := os.Getenv("GOOGLE_MAPS_API_KEY")
 	url := fmt.Sprintf("https://maps.googleapis.com/maps/api/geocode/json?latlng=%f,%f&key=%s", lat, lng, apiKey)
-	response, err := http.Get(url)
+	response, err := googleMapsClient.Get(url)
 	if err != nil {
 		return nil, nil, nil, "", "", fmt.Errorf("error fetching geocoding data: %v", err)
 	}
@@ -316,6 +320,32 @@ func (s *Server) handleIncidentReport() gin.HandlerFunc {
             return
         }
 
+        // Road reports may trace the stretch they describe; without a GPS
+        // fix the report is placed at the middle of it
+        route := strings.TrimSpace(c.PostForm("route"))
+        if route != "" {
+            points, err := services.ParseRoute(route)
+            if err != nil {
+                response.JSON(c, "Invalid route", http.StatusBadRequest, nil, err)
+                return
+            }
+            if lat == 0 && lng == 0 {
+                lat, lng = points[len(points)/2].Latitude, points[len(points)/2].Longitude
+            }
+        }
+
+        // Incidents spanning several places list them in order as JSON
+        var points []models.ReportPoint
+        if raw := strings.TrimSpace(c.PostForm("points")); raw != "" {
+            if err := json.Unmarshal([]byte(raw), &points); err != nil {
+                response.JSON(c, "Invalid points", http.StatusBadRequest, nil, err)
+                return
+            }
+            if len(points) > 0 && lat == 0 && lng == 0 {
+                lat, lng = points[0].Latitude, points[0].Longitude
+            }
+        }
+
         // Retrieve full name and profile image from context
         fullNameInterface, exists := c.Get("fullName")
         if !exists {
@@ -368,6 +398,19 @@ func (s *Server) handleIncidentReport() gin.HandlerFunc {
             Rating:          c.PostForm("rating"),
             Category:        c.PostForm("category"),
             ThumbnailURLs:   profileImage,
+            Route:           route,
+            Points:          points,
+        }
+
+        // Without GPS the report is placed by the client's address, and
+        // submissions through VPNs and datacenters are flagged
+        s.IPLocationService.Apply(c.Request.Context(), incidentReport, c.ClientIP())
+        lat, lng = incidentReport.Latitude, incidentReport.Longitude
+
+        // During surges the report is queued and the job workers save it
+        if s.Config.ReportIngestQueue {
+            s.queueIncidentReport(c, user.ID, incidentReport, c.PostForm("sub_report_type"))
+            return
         }
 
         // Create and populate the ReportType model
@@ -382,30 +425,26 @@ func (s *Server) handleIncidentReport() gin.HandlerFunc {
             DateOfIncidence:      time.Now(),
         }
 
-        // Save ReportType
-        if _, err := s.IncidentReportRepository.SaveReportType(reportType); err != nil {
-            log.Printf("Error saving report type: %v\n", err)
-            response.JSON(c, "Unable to save report type", http.StatusInternalServerError, nil, err)
-            return
-        }
-
         // Create and populate the SubReport model
-        subReport := &models.SubReport{
+        subReport := models.SubReport{
             ID:            uuid.New(),
             ReportTypeID:  reportType.ID,
             SubReportType: c.PostForm("sub_report_type"),
         }
 
-        // Save SubReport
-        savedSubReport, err := s.IncidentReportRepository.SaveSubReport(subReport)
-        if err != nil {
-            log.Printf("Error saving sub-report: %v\n", err)
-            response.JSON(c, "Unable to save sub-report", http.StatusInternalServerError, nil, err)
-            return
-        }
+        // Both are saved with the report, in the same transaction
+        reportType.SubReports = []models.SubReport{subReport}
+        incidentReport.Classification = reportType
 
         // Save the incident report to the database
         savedIncidentReport, err := s.IncidentReportService.SaveReport(user.ID, lat, lng, incidentReport, reportID.String(), 0)
+        if invalidReportLocation(err) {
+            response.JSON(c, "Invalid report location", http.StatusBadRequest, nil, err)
+            return
+        }
+        if respondAgeRejected(c, err) || respondCategoryClosed(c, err) || respondDailyCapReached(c, err) {
+            return
+        }
         if err != nil {
             log.Printf("Error saving incident report: %v\n", err)
             response.JSON(c, "Unable to save incident report", http.StatusInternalServerError, nil, err)
@@ -416,7 +455,7 @@ func (s *Server) handleIncidentReport() gin.HandlerFunc {
         response.JSON(c, "Incident Report Submitted Successfully", http.StatusCreated, gin.H{
             "reportID":            reportID.String(),
             "reportTypeID":        reportType.ID.String(),
-            "subReportID":         savedSubReport.ID.String(),
+            "subReportID":         subReport.ID.String(),
             "savedIncidentReport": savedIncidentReport,
         }, nil)
     }
@@ -426,20 +465,24 @@ func (s *Server) handleIncidentReport() gin.HandlerFunc {
 // Helper function to parse coordinates from the request form
 func parseCoordinates(c *gin.Context) (float64, float64, error) {
 	lat, lng := 0.0, 0.0
-	var err error
 
-	if latStr := strings.TrimSpace(c.PostForm("latitude")); latStr != "" {
-		lat, err = strconv.ParseFloat(latStr, 64)
+	latStr, lngStr := c.PostForm("latitude"), c.PostForm("longitude")
+	if strings.TrimSpace(latStr) != "" || strings.TrimSpace(lngStr) != "" {
+		point, err := geo.ParseCoordinates(latStr, lngStr)
 		if err != nil {
-			return 0, 0, fmt.Errorf("invalid latitude: %v", err)
+			return 0, 0, err
 		}
+		lat, lng = point.Latitude, point.Longitude
 	}
 
-	if lngStr := strings.TrimSpace(c.PostForm("longitude")); lngStr != "" {
-		lng, err = strconv.ParseFloat(lngStr, 64)
+	// A full plus code stands in for coordinates the reporter could not
+	// read off a GPS fix
+	if code := strings.TrimSpace(c.PostForm("plus_code")); code != "" && lat == 0 && lng == 0 {
+		area, err := geo.DecodePlusCode(code)
 		if err != nil {
-			return 0, 0, fmt.Errorf("invalid longitude: %v", err)
+			return 0, 0, fmt.Errorf("invalid plus code: %v", err)
 		}
+		lat, lng = area.Center()
 	}
 
 	return lat, lng, nil
@@ -458,6 +501,9 @@ func (s *Server) handleUploadMedia() gin.HandlerFunc {
         feedURLs, thumbnailURLs, fullsizeURLs, fileTypes, err := s.processAndSaveMedia(c)
         if err != nil {
             log.Printf("Error processing media: %v", err)
+            if respondValidationError(c, err) {
+                return
+            }
             response.JSON(c, "Unable to process media files", http.StatusInternalServerError, nil, err)
             return
         }
@@ -498,10 +544,10 @@ func (s *Server) processAndSaveMedia(c *gin.Context) ([]string, []string, []stri
         return nil, nil, nil, nil, fmt.Errorf("error fetching last report ID: %v", err)
     }
 
-    processedFeedURLs, processedThumbnailURLs, processedFullsizeURLs, processedFileTypes, err := s.MediaService.ProcessMedia(c, formMedia, userIDUint, reportIDStr)
+    processedFeedURLs, processedThumbnailURLs, processedFullsizeURLs, processedFileTypes, processedFingerprints, err := s.MediaService.ProcessMedia(c, formMedia, userIDUint, reportIDStr)
     if err != nil {
         log.Printf("Error processing media: %v\n", err)
-        return nil, nil, nil, nil, fmt.Errorf("error processing media: %v", err)
+        return nil, nil, nil, nil, fmt.Errorf("error processing media: %w", err)
     }
 
     // Append the processed URLs and types to the respective slices
@@ -558,10 +604,18 @@ func (s *Server) processAndSaveMedia(c *gin.Context) ([]string, []string, []stri
             ThumbnailURL: processedThumbnailURLs[i],
             FullSizeURL:  processedFullsizeURLs[i],
             FileType:     processedFileTypes[i],
+            SHA256:       processedFingerprints[i].SHA256,
+            PHash:        processedFingerprints[i].PHash,
+            OriginalKey:  processedFingerprints[i].OriginalKey,
+            BlurredURL:   processedFingerprints[i].BlurredURL,
+        }
+        if mark := processedFingerprints[i].Watermark; mark != nil {
+            mediaModel.WatermarkCode = mark.Code
+            mediaModel.WatermarkedAt = mark.Timestamp.Unix()
         }
 
         // Calculate total points (example logic, adjust as needed)
-        totalPoints := (imageCount * 5) + (videoCount * 10) + (audioCount * 8)
+        totalPoints := services.MediaPoints(imageCount, videoCount, audioCount)
 
         // Save the processed media with the correct parameters
         if err := s.MediaService.SaveMedia(mediaModel, reportIDStr, userIDUint, imageCount, videoCount, audioCount, totalPoints); err != nil {
@@ -574,6 +628,17 @@ func (s *Server) processAndSaveMedia(c *gin.Context) ([]string, []string, []stri
 }
 
 
+// reportSort parses the sort, lat, lng and cursor query parameters of a
+// report listing, answering 400 when they are invalid
+func reportSort(c *gin.Context) (db.ReportSort, bool) {
+	sort, err := db.ParseReportSort(c.Query("sort"), c.Query("lat"), c.Query("lng"), c.Query("cursor"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return db.ReportSort{}, false
+	}
+	return sort, true
+}
+
 func (s *Server) handleGetAllReport() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		pageStr := c.Query("page")
@@ -586,13 +651,17 @@ func (s *Server) handleGetAllReport() gin.HandlerFunc {
 			return
 		}
 
-		reports, err := s.IncidentReportService.GetAllReports(page)
+		sort, ok := reportSort(c)
+		if !ok {
+			return
+		}
+		reports, err := s.IncidentReportService.GetAllReports(page, sort)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
-		c.JSON(http.StatusOK, gin.H{"incident_reports": reports})
+		c.JSON(http.StatusOK, gin.H{"incident_reports": serializers.PublicReports(reports), "next_cursor": sort.NextCursor(reports)})
 	}
 }
 
@@ -610,13 +679,17 @@ func (s *Server) handleGetAllReportsByState() gin.HandlerFunc {
 			return
 		}
 
-		reports, err := s.IncidentReportService.GetAllReportsByState(state, page)
+		sort, ok := reportSort(c)
+		if !ok {
+			return
+		}
+		reports, err := s.IncidentReportService.GetAllReportsByState(state, page, sort)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
-		c.JSON(http.StatusOK, gin.H{"incident_reports": reports})
+		c.JSON(http.StatusOK, gin.H{"incident_reports": serializers.PublicReports(reports), "next_cursor": sort.NextCursor(reports)})
 	}
 }
 
@@ -634,13 +707,17 @@ func (s *Server) handleGetAllReportsByLGA() gin.HandlerFunc {
 			return
 		}
 
-		reports, err := s.IncidentReportService.GetAllReportsByLGA(lga, page)
+		sort, ok := reportSort(c)
+		if !ok {
+			return
+		}
+		reports, err := s.IncidentReportService.GetAllReportsByLGA(lga, page, sort)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
-		c.JSON(http.StatusOK, gin.H{"incident_reports": reports})
+		c.JSON(http.StatusOK, gin.H{"incident_reports": serializers.PublicReports(reports), "next_cursor": sort.NextCursor(reports)})
 	}
 }
 
@@ -658,13 +735,17 @@ func (s *Server) handleGetAllReportsByReportType() gin.HandlerFunc {
 			return
 		}
 
-		reports, err := s.IncidentReportService.GetAllReportsByReportType(report_type, page)
+		sort, ok := reportSort(c)
+		if !ok {
+			return
+		}
+		reports, err := s.IncidentReportService.GetAllReportsByReportType(report_type, page, sort)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
-		c.JSON(http.StatusOK, gin.H{"incident_reports": reports})
+		c.JSON(http.StatusOK, gin.H{"incident_reports": serializers.PublicReports(reports), "next_cursor": sort.NextCursor(reports)})
 	}
 }
 
@@ -718,8 +799,8 @@ func (s *Server) handleApproveReportPoints() gin.HandlerFunc {
 		}
 
 		// Reward points to the user for the approved report
-		if err := s.RewardService.ApproveReportPoints(reportID, uint(userID64)); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		if err := s.RewardService.ApproveReportPoints(reportID, uint(userID64), c.GetUint("userID")); err != nil {
+			c.JSON(moderationErrorStatus(err), gin.H{"error": err.Error()})
 			return
 		}
 
@@ -755,8 +836,8 @@ func (s *Server) handleRejectReportPoints() gin.HandlerFunc {
 		}
 
 		// Reward points to the user for the approved report
-		if err := s.RewardService.RejectReportPoints(reportID, uint(userID64)); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		if err := s.RewardService.RejectReportPoints(reportID, uint(userID64), c.GetUint("userID")); err != nil {
+			c.JSON(moderationErrorStatus(err), gin.H{"error": err.Error()})
 			return
 		}
 
@@ -792,8 +873,8 @@ func (s *Server) handleAcceptReportPoints() gin.HandlerFunc {
 		}
 
 		// Reward points to the user for the approved report
-		if err := s.RewardService.AcceptReportPoints(reportID, uint(userID64)); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		if err := s.RewardService.AcceptReportPoints(reportID, uint(userID64), c.GetUint("userID")); err != nil {
+			c.JSON(moderationErrorStatus(err), gin.H{"error": err.Error()})
 			return
 		}
 
@@ -913,14 +994,23 @@ func (s *Server) handleGetAllReportsByStateByTime() gin.HandlerFunc {
 			return
 		}
 
+		sort, ok := reportSort(c)
+		if !ok {
+			return
+		}
+
 		// Fetch the reports from the repository
-		reports, err := s.IncidentReportRepository.GetAllReportsByStateByTime(state, startTime, endTime, page)
+		reports, err := s.IncidentReportRepository.GetAllReportsByStateByTime(state, startTime, endTime, page, sort, db.ReportCard()...)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
-		c.JSON(http.StatusOK, reports)
+		// The body is a bare array, so the cursor travels in a header
+		if next := sort.NextCursor(reports); next != "" {
+			c.Header("X-Next-Cursor", next)
+		}
+		c.JSON(http.StatusOK, serializers.PublicReports(reports))
 	}
 }
 
@@ -984,7 +1074,7 @@ func (s *Server) handleGetReportTypeCounts() gin.HandlerFunc {
 
 		reportTypes, reportCounts, totalUsers, totalReports, topStates, err := s.IncidentReportService.GetReportTypeCounts(state, lga, &startDate, &endDate)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			respondAggregateError(c, err)
 			return
 		}
 
@@ -1039,7 +1129,7 @@ func getLGAsInState(northeast, southwest [2]float64, apiKey string) ([]string, e
 	lat := (northeast[0] + southwest[0]) / 2
 	lng := (northeast[1] + southwest[1]) / 2
 	url := fmt.Sprintf("https://maps.googleapis.com/maps/api/place/textsearch/json?query=local+government+area&location=%f,%f&radius=50000&key=%s", lat, lng, apiKey)
-	resp, err := http.Get(url)
+	resp, err := googleMapsClient.Get(url)
 	if err != nil {
 		return nil, err
 	}
@@ -1064,7 +1154,7 @@ func getLGAsInState(northeast, southwest [2]float64, apiKey string) ([]string, e
 
 func getStateBounds(stateName, apiKey string) (northeast, southwest [2]float64, err error) {
 	url := fmt.Sprintf("https://maps.googleapis.com/maps/api/geocode/json?address=%s&key=%s", stateName, apiKey)
-	resp, err := http.Get(url)
+	resp, err := googleMapsClient.Get(url)
 	if err != nil {
 		return [2]float64{}, [2]float64{}, err
 	}
@@ -1125,11 +1215,41 @@ func (s *Server) IncidentMarkersHandler() gin.HandlerFunc {
 	}
 }
 
+func (s *Server) handleGetIncidentReport() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.Param("id")
+		if _, err := uuid.Parse(id); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid report ID"})
+			return
+		}
+
+		reports, err := s.IncidentReportRepository.GetReportsByIDs([]string{id}, db.ReportCard()...)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if len(reports) == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Incident report not found"})
+			return
+		}
+
+		if err := s.IncidentReportRepository.IncrementViewCount(id); err != nil {
+			log.Printf("counting view of report %s: %v", id, err)
+		}
+		if err := s.ActivityService.Track(c.GetUint("userID"), models.ActivityReportViewed, map[string]interface{}{"report_id": id}); err != nil {
+			log.Printf("tracking view of report %s: %v", id, err)
+		}
+
+		report := &reports[0]
+		c.JSON(http.StatusOK, gin.H{"incident_report": serializers.Report(report, serializers.AudienceFor(subject(c), report))})
+	}
+}
+
 func (s *Server) DeleteIncidentReportHandler() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		id := c.Param("id")
 
-		err := s.IncidentReportRepository.DeleteByID(id)
+		err := s.IncidentReportRepository.DeleteByID(id, c.GetUint("userID"))
 		if err != nil {
 			if err == gorm.ErrRecordNotFound {
 				c.JSON(http.StatusNotFound, gin.H{"error": "Incident report not found"})
@@ -1327,14 +1447,14 @@ func (s *Server) HandleGetAllReportsByUser() gin.HandlerFunc {
 		}
 
 		// Fetch reports for the user
-		reports, err := s.IncidentReportRepository.GetAllIncidentReportsByUser(userID)
+		reports, err := s.IncidentReportRepository.GetAllIncidentReportsByUser(userID, db.ReportCard()...)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
 
 		// Return the reports as a JSON response
-		c.JSON(http.StatusOK, gin.H{"reports": reports})
+		c.JSON(http.StatusOK, gin.H{"reports": serializers.OwnerReports(reports)})
 	}
 }
 
@@ -1384,16 +1504,13 @@ func (s *Server) HandleBookmarkReport() gin.HandlerFunc {
         }
 
         // Call the bookmark service
-        err = s.IncidentReportService.BookmarkReport(userID, reportID)
+        saved, err := s.IncidentReportService.BookmarkReport(userID, reportID)
         if err != nil {
             status := http.StatusInternalServerError
             
             // Handle specific error cases
-            switch err.Error() {
-            case "report not found":
+            if err.Error() == "report not found" {
                 status = http.StatusNotFound
-            case "report already bookmarked":
-                status = http.StatusConflict
             }
             
             c.JSON(status, gin.H{
@@ -1401,6 +1518,13 @@ func (s *Server) HandleBookmarkReport() gin.HandlerFunc {
             })
             return
         }
+        // Bookmarking twice is not an error
+        if !saved {
+            c.JSON(http.StatusOK, gin.H{
+                "message": "Report already bookmarked",
+            })
+            return
+        }
 
         // Success response
         c.JSON(http.StatusOK, gin.H{
@@ -1456,10 +1580,30 @@ func (s *Server) GetReportTypeCountsByLGA() gin.HandlerFunc {
 	}
 }
 
+// GetReportCountsByStateAndLGA counts a state's reports by LGA, or by ward
+// with ?group_by=ward, optionally within one ?lga=
 func (s *Server) GetReportCountsByStateAndLGA() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		state := c.Param("state")
 
+		if c.Query("group_by") == "ward" {
+			counts, err := s.AnalyticsService.ReportCounts(db.AggregateQuery{GroupBy: "ward", StateName: state, LGAName: c.Query("lga")})
+			if err != nil {
+				respondAggregateError(c, err)
+				return
+			}
+			wards := make([]string, len(counts))
+			reportCounts := make([]int64, len(counts))
+			for i, count := range counts {
+				wards[i], reportCounts[i] = count.Group, count.Count
+			}
+			c.JSON(http.StatusOK, gin.H{
+				"wards":         wards,
+				"report_counts": reportCounts,
+			})
+			return
+		}
+
 		lgas, reportCounts, err := s.IncidentReportRepository.GetReportCountsByState(state)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -1541,3 +1685,138 @@ func (s *Server) handleGetReportsByFilters() gin.HandlerFunc {
 		})
 	}
 }
+
+// reportFilterQuery reads the state, lga, category, status, start_date,
+// end_date, bbox and plus_code filters of a report listing, answering 400
+// when one is invalid
+func reportFilterQuery(c *gin.Context) (db.ReportFilter, bool) {
+	filters := db.ReportFilter{
+		StateName: c.Query("state"),
+		LGAName:   c.Query("lga"),
+		Category:  c.Query("category"),
+		Status:    c.Query("status"),
+	}
+	for _, bound := range []struct {
+		param string
+		dst   **time.Time
+		days  int
+	}{{"start_date", &filters.Start, 0}, {"end_date", &filters.End, 1}} {
+		if v := c.Query(bound.param); v != "" {
+			day, err := time.Parse("2006-01-02", v)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + bound.param + " format, expected YYYY-MM-DD"})
+				return filters, false
+			}
+			day = day.AddDate(0, 0, bound.days)
+			*bound.dst = &day
+		}
+	}
+	if v := c.Query("bbox"); v != "" {
+		corners := strings.Split(v, ",")
+		if len(corners) != 4 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bbox must be west,south,east,north"})
+			return filters, false
+		}
+		box := &db.BBox{}
+		for i, dst := range []*float64{&box.West, &box.South, &box.East, &box.North} {
+			f, err := strconv.ParseFloat(strings.TrimSpace(corners[i]), 64)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "bbox must be west,south,east,north"})
+				return filters, false
+			}
+			*dst = f
+		}
+		filters.BBox = box
+	}
+	var err error
+	if filters.PlusCode, err = plusCodeQuery(c); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return filters, false
+	}
+	return filters, true
+}
+
+// handleListReports lists the reports matching any combination of the
+// listing filters
+func (s *Server) handleListReports() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, err := getPageFromQuery(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page number"})
+			return
+		}
+		filters, ok := reportFilterQuery(c)
+		if !ok {
+			return
+		}
+		sort, ok := reportSort(c)
+		if !ok {
+			return
+		}
+		reports, err := s.IncidentReportService.ListReports(filters, page, sort)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"incident_reports": serializers.PublicReports(reports), "next_cursor": sort.NextCursor(reports)})
+	}
+}
+
+// handleListTrendingReports lists the recent reports matching the filters,
+// most credible first
+func (s *Server) handleListTrendingReports() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, err := getPageFromQuery(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page number"})
+			return
+		}
+		filters, ok := reportFilterQuery(c)
+		if !ok {
+			return
+		}
+		sort, err := db.ParseReportSort(db.SortTrending, "", "", c.Query("cursor"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		reports, err := s.IncidentReportService.TrendingReports(filters, page, sort)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"incident_reports": serializers.PublicReports(reports), "next_cursor": sort.NextCursor(reports)})
+	}
+}
+
+func (s *Server) handleSearchReports() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, err := getPageFromQuery(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page number"})
+			return
+		}
+
+		filters, ok := reportFilterQuery(c)
+		if !ok {
+			return
+		}
+		sort, ok := reportSort(c)
+		if !ok {
+			return
+		}
+		reports, err := s.SearchService.SearchReports(c.Request.Context(), c.Query("q"), filters, page, sort)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		nextCursor := ""
+		if !sort.Ranked(c.Query("q")) {
+			nextCursor = sort.NextCursor(reports)
+		}
+		c.JSON(http.StatusOK, gin.H{"incident_reports": serializers.PublicReports(reports), "next_cursor": nextCursor})
+	}
+}
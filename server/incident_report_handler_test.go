@@ -0,0 +1,31 @@
+package server
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/techagentng/citizenx/mocks"
+	"github.com/techagentng/citizenx/models"
+	"go.uber.org/mock/gomock"
+	"gorm.io/gorm"
+)
+
+func TestDeleteIncidentReport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	reports := mocks.NewMockIncidentReportRepository(ctrl)
+	s := &Server{IncidentReportRepository: reports}
+	r := testRouter(http.MethodDelete, "/incident-report/:id", models.RoleUser, s.DeleteIncidentReportHandler())
+	id := uuid.NewString()
+
+	// The handler passes on who deleted the report
+	reports.EXPECT().DeleteByID(id, testUser.ID).Return(nil)
+	if w := serve(t, r, http.MethodDelete, "/incident-report/"+id, nil); w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200: %s", w.Code, w.Body)
+	}
+
+	reports.EXPECT().DeleteByID(id, testUser.ID).Return(gorm.ErrRecordNotFound)
+	if w := serve(t, r, http.MethodDelete, "/incident-report/"+id, nil); w.Code != http.StatusNotFound {
+		t.Fatalf("got status %d for a missing report, want 404", w.Code)
+	}
+}
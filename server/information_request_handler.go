@@ -0,0 +1,132 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+)
+
+// handleRequestInformation asks the reporter of a pending report for the
+// fields named in the body
+func (s *Server) handleRequestInformation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			Fields  []string `json:"fields"`
+			Message string   `json:"message"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		request, err := s.InformationRequestService.RequestInformation(c.GetUint("userID"), c.Param("id"), body.Fields, body.Message)
+		if err != nil {
+			respondInformationRequestError(c, err)
+			return
+		}
+		response.JSON(c, "Information requested", http.StatusCreated, request, nil)
+	}
+}
+
+func (s *Server) handleListReportInformationRequests() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requests, err := s.InformationRequestService.ReportRequests(c.Param("id"))
+		if err != nil {
+			response.JSON(c, "Failed to load information requests", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Information requests retrieved", http.StatusOK, requests, nil)
+	}
+}
+
+// handleListMyInformationRequests lists the requests made of the signed-in
+// user, only the open ones with ?open=true
+func (s *Server) handleListMyInformationRequests() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requests, err := s.InformationRequestService.UserRequests(c.GetUint("userID"), c.Query("open") == "true")
+		if err != nil {
+			response.JSON(c, "Failed to load information requests", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Information requests retrieved", http.StatusOK, requests, nil)
+	}
+}
+
+// handleAnswerInformationRequest applies a multipart answer to the
+// reporter's report. Only the form fields that are sent are changed, and
+// photos are sent as mediaFiles.
+func (s *Server) handleAnswerInformationRequest() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid information request ID"})
+			return
+		}
+		userID := c.GetUint("userID")
+		request, err := s.InformationRequestService.OpenRequest(userID, uint(requestID))
+		if err != nil {
+			respondInformationRequestError(c, err)
+			return
+		}
+
+		var answer models.InformationAnswer
+		if value, ok := c.GetPostForm("description"); ok {
+			answer.Description = &value
+		}
+		if value, ok := c.GetPostForm("date_of_incidence"); ok {
+			answer.DateOfIncidence = &value
+		}
+		if value, ok := c.GetPostForm("address"); ok {
+			answer.Address = &value
+		}
+		for _, coordinate := range []struct {
+			field string
+			into  **float64
+		}{{"latitude", &answer.Latitude}, {"longitude", &answer.Longitude}} {
+			value, ok := c.GetPostForm(coordinate.field)
+			if !ok {
+				continue
+			}
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + coordinate.field})
+				return
+			}
+			*coordinate.into = &parsed
+		}
+
+		if form, err := c.MultipartForm(); err == nil && len(form.File["mediaFiles"]) > 0 {
+			media, ok := s.processReportMedia(c, form.File["mediaFiles"], userID, request.ReportID.String())
+			if !ok {
+				return
+			}
+			answer.Media = media
+		}
+
+		if err := s.InformationRequestService.Answer(userID, request.ID, answer); err != nil {
+			respondInformationRequestError(c, err)
+			return
+		}
+		response.JSON(c, "Information provided", http.StatusOK, nil, nil)
+	}
+}
+
+func respondInformationRequestError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidInformationRequest):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInformationRequestNotFound), errors.Is(err, services.ErrReportNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInformationRequestClosed):
+		c.JSON(http.StatusGone, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInformationRequestOpen), errors.Is(err, services.ErrReportNotPending):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		response.JSON(c, "Failed to process information request", http.StatusInternalServerError, nil, err)
+	}
+}
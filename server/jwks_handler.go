@@ -0,0 +1,17 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/services/jwt"
+)
+
+// handleJWKS publishes the public signing keys, so other services can
+// validate CitizenX tokens without sharing a secret
+func (s *Server) handleJWKS() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, jwt.PublicJWKS())
+	}
+}
@@ -0,0 +1,104 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/serializers"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+)
+
+// handleSearchLandmarks finds known landmarks by name, e.g.
+// ?q=first bank&state=Lagos
+func (s *Server) handleSearchLandmarks() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, ok := incidentPage(c)
+		if !ok {
+			return
+		}
+		landmarks, err := s.LandmarkService.SearchLandmarks(c.Query("q"), c.Query("state"), page)
+		if err != nil {
+			response.JSON(c, "Failed to search landmarks", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Landmarks retrieved", http.StatusOK, landmarks, nil)
+	}
+}
+
+func (s *Server) handleGetLandmarkReports() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := s.idParam(c, "id", "landmark", &models.Landmark{})
+		if !ok {
+			return
+		}
+		page, ok := incidentPage(c)
+		if !ok {
+			return
+		}
+		reports, err := s.LandmarkService.LandmarkReports(id, page)
+		if errors.Is(err, services.ErrLandmarkNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			response.JSON(c, "Failed to load landmark reports", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Landmark reports retrieved", http.StatusOK, serializers.PublicReports(reports), nil)
+	}
+}
+
+func (s *Server) handleGetReportLocation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		location, err := s.LandmarkService.GetReportLocation(c.Param("id"))
+		if errors.Is(err, services.ErrReportLocationNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			response.JSON(c, "Failed to load report location", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Report location retrieved", http.StatusOK, location, nil)
+	}
+}
+
+// handleNormalizeLocation previews how a written location will be read,
+// so the app can confirm the place before the report is submitted
+func (s *Server) handleNormalizeLocation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			Text      string `json:"text" binding:"required"`
+			StateName string `json:"state_name"`
+			LGAName   string `json:"lga_name"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "The location text is required"})
+			return
+		}
+		location, err := s.LandmarkService.Normalize(c.Request.Context(), body.Text, body.StateName, body.LGAName)
+		if err != nil {
+			response.JSON(c, "Failed to read location", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Location read", http.StatusOK, location, nil)
+	}
+}
+
+func (s *Server) handleCreateLandmark() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var landmark models.Landmark
+		if err := c.ShouldBindJSON(&landmark); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A name, latitude and longitude are required"})
+			return
+		}
+		if err := s.LandmarkService.CreateLandmark(&landmark, c.GetUint("userID")); err != nil {
+			response.JSON(c, "Failed to create landmark", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Landmark created", http.StatusCreated, landmark, nil)
+	}
+}
@@ -0,0 +1,133 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+)
+
+// handleGetLegalDocuments returns the current Terms of Service and Privacy
+// Policy
+func (s *Server) handleGetLegalDocuments() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		documents, err := s.LegalService.Current()
+		if err != nil {
+			response.JSON(c, "Failed to load legal documents", http.StatusInternalServerError, nil, err)
+			return
+		}
+		c.Header("Cache-Control", "public, max-age=60")
+		response.JSON(c, "Legal documents retrieved", http.StatusOK, documents, nil)
+	}
+}
+
+func (s *Server) handleGetLegalDocument() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		document, err := s.LegalService.CurrentOf(c.Param("kind"))
+		if errors.Is(err, services.ErrLegalDocumentNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			response.JSON(c, "Failed to load legal document", http.StatusInternalServerError, nil, err)
+			return
+		}
+		c.Header("Cache-Control", "public, max-age=60")
+		response.JSON(c, "Legal document retrieved", http.StatusOK, document, nil)
+	}
+}
+
+// handleGetConsents returns the versions the user has accepted and the
+// current ones they still need to
+func (s *Server) handleGetConsents() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		status, err := s.LegalService.Status(c.GetUint("userID"))
+		if err != nil {
+			response.JSON(c, "Failed to load consents", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Consents retrieved", http.StatusOK, status, nil)
+	}
+}
+
+// handleAcceptLegalDocuments records the user accepting current documents,
+// e.g. {"document_ids": [4, 7]}, with the time and their IP address
+func (s *Server) handleAcceptLegalDocuments() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request models.LegalAcceptanceRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "document_ids is required"})
+			return
+		}
+		userID := c.GetUint("userID")
+		err := s.LegalService.Accept(userID, request.DocumentIDs, c.ClientIP())
+		if errors.Is(err, services.ErrNotCurrentLegalDocument) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			response.JSON(c, "Failed to record consent", http.StatusInternalServerError, nil, err)
+			return
+		}
+		status, err := s.LegalService.Status(userID)
+		if err != nil {
+			response.JSON(c, "Failed to load consents", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Consent recorded", http.StatusOK, status, nil)
+	}
+}
+
+func (s *Server) handleListLegalDocuments() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		documents, err := s.LegalService.Documents(c.Query("kind"))
+		if err != nil {
+			response.JSON(c, "Failed to load legal documents", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Legal documents retrieved", http.StatusOK, documents, nil)
+	}
+}
+
+// handleCreateLegalDocument drafts a new version, e.g. {"kind": "terms",
+// "version": "2026-11", "title": "Terms of Service", "body": "..."}
+func (s *Server) handleCreateLegalDocument() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var document models.LegalDocument
+		if err := c.ShouldBindJSON(&document); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A kind of terms or privacy, version, title and body are required"})
+			return
+		}
+		if err := s.LegalService.CreateDocument(&document, c.GetUint("userID")); err != nil {
+			response.JSON(c, "Failed to save legal document", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Legal document saved", http.StatusCreated, document, nil)
+	}
+}
+
+// handlePublishLegalDocument makes a draft the current version. Every user
+// must accept it before they can carry on using the app.
+func (s *Server) handlePublishLegalDocument() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid document ID"})
+			return
+		}
+		err = s.LegalService.Publish(uint(id))
+		if errors.Is(err, services.ErrLegalDocumentNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			response.JSON(c, "Failed to publish legal document", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Legal document published", http.StatusOK, nil, nil)
+	}
+}
@@ -28,11 +28,15 @@ func (s *Server) HandleUpvoteReport() gin.HandlerFunc {
 		}
 
 		reportID := c.Param("reportID")
-		err := s.LikeService.LikeReport(userID, reportID)
+		cast, err := s.LikeService.LikeReport(userID, reportID)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
+		if !cast {
+			c.JSON(http.StatusOK, gin.H{"message": "Already upvoted"})
+			return
+		}
 
 		c.JSON(http.StatusOK, gin.H{"message": "Upvoted successfully"})
 	}
@@ -56,12 +60,44 @@ func (s *Server) HandleDownvoteReport() gin.HandlerFunc {
 		}
 
 		reportID := c.Param("reportID")
-		err := s.LikeService.DownVoteReport(userID, reportID)
+		cast, err := s.LikeService.DownVoteReport(userID, reportID)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
+		if !cast {
+			c.JSON(http.StatusOK, gin.H{"message": "Already downvoted"})
+			return
+		}
 
 		c.JSON(http.StatusOK, gin.H{"message": "Downvoted successfully"})
 	}
 }
+
+// HandleRetractVote handles the withdrawal of a user's vote on a report
+func (s *Server) HandleRetractVote() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userIDCtx, ok := c.Get("userID")
+		if !ok {
+			response.JSON(c, "", http.StatusInternalServerError, nil, errors.New("userID not found in context", http.StatusInternalServerError))
+			return
+		}
+		userID, ok := userIDCtx.(uint)
+		if !ok {
+			response.JSON(c, "", http.StatusInternalServerError, nil, errors.New("userID is not of type uint", http.StatusInternalServerError))
+			return
+		}
+
+		retracted, err := s.LikeService.RetractVote(userID, c.Param("reportID"))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if !retracted {
+			c.JSON(http.StatusOK, gin.H{"message": "No vote to retract"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "Vote retracted"})
+	}
+}
@@ -0,0 +1,27 @@
+package server
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/locale"
+	"github.com/techagentng/citizenx/services"
+)
+
+// requestLocale returns the formatter for the locale a request is answered
+// in: a ?locale= parameter, then the signed-in user's preference, then the
+// Accept-Language header
+func requestLocale(c *gin.Context) locale.Formatter {
+	if tag, ok := locale.Normalize(c.Query("locale")); ok {
+		return locale.For(tag)
+	}
+	if tag := c.GetString("locale"); tag != "" {
+		return locale.For(tag)
+	}
+	return locale.For(locale.FromAcceptLanguage(c.GetHeader("Accept-Language")))
+}
+
+// unsupportedLocale reports whether err rejects a profile's locale
+func unsupportedLocale(err error) bool {
+	return errors.Is(err, services.ErrUnsupportedLocale)
+}
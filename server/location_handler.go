@@ -0,0 +1,57 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+)
+
+// handleDeleteLocationHistory lets users drop the precise coordinates of
+// their past reports
+func (s *Server) handleDeleteLocationHistory() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := c.Get("userID")
+		if !ok {
+			response.JSON(c, "User ID not found in context", http.StatusUnauthorized, nil, nil)
+			return
+		}
+
+		reports, err := s.LocationService.DeleteHistory(userID.(uint))
+		if err != nil {
+			response.JSON(c, "Failed to delete location history", http.StatusInternalServerError, nil, err)
+			return
+		}
+
+		response.JSON(c, "Location history deleted", http.StatusOK, gin.H{"reports_updated": reports}, nil)
+	}
+}
+
+// handleGetReportPoints lists the places a report spans, in order
+func (s *Server) handleGetReportPoints() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, err := uuid.Parse(c.Param("id")); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid report ID"})
+			return
+		}
+		points, err := s.IncidentReportService.GetReportPoints(c.Param("id"))
+		if errors.Is(err, services.ErrReportNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			response.JSON(c, "Failed to load report points", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Report points retrieved", http.StatusOK, points, nil)
+	}
+}
+
+// invalidReportLocation reports whether a report was refused for the route
+// or points it was submitted with
+func invalidReportLocation(err error) bool {
+	return errors.Is(err, services.ErrInvalidPoints) || errors.Is(err, services.ErrInvalidRoute)
+}
@@ -0,0 +1,58 @@
+package server
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+)
+
+// handleRequestMagicLink emails a sign-in link. It answers the same whether
+// or not the address has an account.
+func (s *Server) handleRequestMagicLink() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request models.MagicLinkRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			response.JSON(c, "An email and device_id are required", http.StatusBadRequest, nil, err)
+			return
+		}
+
+		if err := s.MagicLinkService.RequestLink(request.Email, request.DeviceID, c.ClientIP()); err != nil {
+			response.JSON(c, "Unable to send sign-in link", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "If an account uses this email, a sign-in link is on its way", http.StatusOK, nil, nil)
+	}
+}
+
+// handleVerifyMagicLink signs in with the token of a sign-in link
+func (s *Server) handleVerifyMagicLink() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request models.MagicLinkVerification
+		if err := c.ShouldBindJSON(&request); err != nil {
+			response.JSON(c, "A token and device_id are required", http.StatusBadRequest, nil, err)
+			return
+		}
+
+		login, err := s.MagicLinkService.VerifyLink(request.Token, request.DeviceID)
+		switch {
+		case errors.Is(err, services.ErrMagicLinkInvalid), errors.Is(err, services.ErrMagicLinkExpired):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		case errors.Is(err, services.ErrMagicLinkOtherDevice):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		case err != nil:
+			response.JSON(c, "Unable to sign in", http.StatusInternalServerError, nil, err)
+			return
+		}
+		if err := s.ActivityService.Track(login.ID, models.ActivityLogin, map[string]interface{}{"method": "magic_link"}); err != nil {
+			log.Printf("tracking login of user %d: %v", login.ID, err)
+		}
+		response.JSON(c, "login successful", http.StatusOK, login, nil)
+	}
+}
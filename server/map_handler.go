@@ -0,0 +1,154 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/services"
+	"github.com/techagentng/citizenx/tiles"
+)
+
+// handleGetReportTile serves /tiles/reports/{z}/{x}/{y}.mvt for web maps
+func (s *Server) handleGetReportTile() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		z, errZ := strconv.Atoi(c.Param("z"))
+		x, errX := strconv.Atoi(c.Param("x"))
+		y, errY := strconv.Atoi(strings.TrimSuffix(c.Param("y"), ".mvt"))
+		if errZ != nil || errX != nil || errY != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid tile coordinates"})
+			return
+		}
+
+		tile, err := s.MapService.ReportTile(tiles.Tile{Z: z, X: x, Y: y}, c.Query("category"))
+		if err != nil {
+			if errors.Is(err, tiles.ErrInvalidTile) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Header("Cache-Control", "public, max-age=60")
+		c.Data(http.StatusOK, "application/vnd.mapbox-vector-tile", tile)
+	}
+}
+
+// handleGetHeatmap serves report density per geohash cell. bbox is
+// west,south,east,north; start_date and end_date are inclusive days.
+func (s *Server) handleGetHeatmap() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		mapFilter, ok := parseMapFilter(c)
+		if !ok {
+			return
+		}
+		precision, err := strconv.Atoi(c.DefaultQuery("precision", "5"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid precision"})
+			return
+		}
+
+		heatmap, err := s.MapService.Heatmap(services.HeatmapFilter{MapFilter: mapFilter, Precision: precision})
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidHeatmap) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, heatmap)
+	}
+}
+
+// handleGetReportMarkers serves the reports in a bounding box clustered for
+// the map's zoom level, taking the heatmap's filters and zoom in place of
+// precision
+func (s *Server) handleGetReportMarkers() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		mapFilter, ok := parseMapFilter(c)
+		if !ok {
+			return
+		}
+		zoom, err := strconv.Atoi(c.Query("zoom"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid zoom"})
+			return
+		}
+
+		markers, err := s.MapService.Markers(services.MarkerFilter{MapFilter: mapFilter, Zoom: zoom})
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidHeatmap) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Header("Cache-Control", "public, max-age=30")
+		c.JSON(http.StatusOK, markers)
+	}
+}
+
+// handleGetReportsGeoJSON exports the reports in a bounding box as a GeoJSON
+// feature collection, taking the same parameters as the heatmap
+func (s *Server) handleGetReportsGeoJSON() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter, ok := parseMapFilter(c)
+		if !ok {
+			return
+		}
+
+		collection, err := s.MapService.ReportFeatures(filter)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidHeatmap) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		body, err := json.Marshal(collection)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Header("Cache-Control", "public, max-age=60")
+		c.Data(http.StatusOK, "application/geo+json", body)
+	}
+}
+
+// parseMapFilter reads the bbox, category, start_date and end_date
+// parameters, responding with 400 when they are invalid
+func parseMapFilter(c *gin.Context) (services.MapFilter, bool) {
+	var filter services.MapFilter
+	bbox := strings.Split(c.Query("bbox"), ",")
+	if len(bbox) != 4 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "bbox must be west,south,east,north"})
+		return filter, false
+	}
+	for i, dst := range []*float64{&filter.West, &filter.South, &filter.East, &filter.North} {
+		v, err := strconv.ParseFloat(strings.TrimSpace(bbox[i]), 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "bbox must be west,south,east,north"})
+			return filter, false
+		}
+		*dst = v
+	}
+	filter.Category = c.Query("category")
+
+	var err error
+	if filter.Start, filter.End, err = db.ParseDayRange(c.Query("start_date"), c.Query("end_date")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return filter, false
+	}
+	return filter, true
+}
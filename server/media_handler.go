@@ -0,0 +1,36 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+)
+
+// handleMarkMediaGraphic lets the reporter or a moderator mark one of a
+// report's media as graphic, so clients show it behind a warning
+func (s *Server) handleMarkMediaGraphic() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req models.MediaGraphicRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		err := s.MediaService.MarkGraphic(c.Param("reportID"), c.Param("mediaID"), *req.Graphic, subject(c).IsAdmin())
+		switch {
+		case errors.Is(err, services.ErrMediaNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		case errors.Is(err, services.ErrGraphicSetByModerator):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		case err != nil:
+			response.JSON(c, "Failed to update media", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Media updated", http.StatusOK, gin.H{"graphic": *req.Graphic}, nil)
+	}
+}
@@ -3,9 +3,9 @@ package server
 import (
 	"bytes"
 	"errors"
-	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"sync"
 
 	// ratelimit "github.com/JGLTechnologies/gin-rate-limit"
@@ -21,6 +21,7 @@ import (
 	errs "github.com/techagentng/citizenx/errors"
 	"github.com/techagentng/citizenx/models"
 	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
 	"github.com/techagentng/citizenx/services/jwt"
 )
 
@@ -74,6 +75,22 @@ func (s *Server) Authorize() gin.HandlerFunc {
 			return
 		}
 
+		// Refresh and step-up tokens are not access tokens, and every access
+		// token belongs to a live session
+		if _, typed := accessClaims["type"]; typed {
+			respondAndAbort(c, "", http.StatusUnauthorized, nil, errs.New("Unauthorized", http.StatusUnauthorized))
+			return
+		}
+		sessionID, _ := accessClaims["sid"].(string)
+		if err := s.SessionService.Touch(sessionID, userID); err != nil {
+			if errors.Is(err, services.ErrSessionExpired) {
+				respondAndAbort(c, "Session expired", http.StatusUnauthorized, nil, errs.New(err.Error(), http.StatusUnauthorized))
+				return
+			}
+			respondAndAbort(c, "Unable to check session", http.StatusInternalServerError, nil, errs.New("Internal server error", http.StatusInternalServerError))
+			return
+		}
+
 		// Fetch the user from the database by ID
 		user, err := s.AuthRepository.FindUserByID(userID)
 		if err != nil {
@@ -94,16 +111,69 @@ func (s *Server) Authorize() gin.HandlerFunc {
 		c.Set("user", user)
 		c.Set("userID", userID)
 		c.Set("access_token", accessToken)
+		c.Set("session_id", sessionID)
 		c.Set("fullName", user.Fullname)
 		c.Set("username", user.Username)
 		c.Set("profile_image", user.ThumbNailURL)
+		c.Set("locale", user.Locale)
 		c.Set("user_role", accessClaims["role"].(string))
-		fmt.Println("Username set in context:", user.Username)
 		// Continue to the next middleware or handler
 		c.Next()
 	}
 }
 
+// RequireStepUp guards sensitive operations, which need a step-up token for
+// the current session in the X-Step-Up-Token header
+func (s *Server) RequireStepUp() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := s.SessionService.CheckStepUp(c.GetHeader("X-Step-Up-Token"), c.GetUint("userID"), c.GetString("session_id"))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error(), "step_up_required": true})
+			return
+		}
+		c.Next()
+	}
+}
+
+// consentExempt lists the routes a user who has not accepted the current
+// legal documents can still use: to read and accept them, to tell us
+// something is wrong, or to leave
+var consentExempt = map[string]bool{
+	"/api/v1/me/consents":   true,
+	"/api/v1/logout":        true,
+	"/api/v1/me":            true,
+	"/api/v1/me/step-up":    true,
+	"/api/v1/me/deactivate": true,
+	"/api/v1/delete/user":   true,
+	"/api/v1/feedback":      true,
+}
+
+// RequireConsent holds back users who have not accepted the current Terms
+// of Service and Privacy Policy, answering with consent_required and the
+// documents to accept. It must run after Authorize.
+func (s *Server) RequireConsent() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if consentExempt[c.FullPath()] {
+			c.Next()
+			return
+		}
+		pending, err := s.LegalService.Pending(c.GetUint("userID"))
+		if err != nil {
+			respondAndAbort(c, "Unable to check consent", http.StatusInternalServerError, nil, errs.New("Internal server error", http.StatusInternalServerError))
+			return
+		}
+		if len(pending) > 0 {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error":            "Please accept the updated terms to continue",
+				"consent_required": true,
+				"documents":        pending,
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
 func limitRateForPasswordReset(store ratelimit.Store) gin.HandlerFunc {
 	// Initialize rate limiter using the provided store
 	mw := ratelimit.RateLimiter(store, &ratelimit.Options{
@@ -290,3 +360,34 @@ func containsString(s []string, str string) bool {
 	}
 	return false
 }
+
+// RequireAdmin rejects requests from users without the admin role. It must
+// run after Authorize.
+func (s *Server) RequireAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !strings.EqualFold(c.GetString("user_role"), models.RoleAdmin) {
+			respondAndAbort(c, "Admin access required", http.StatusForbidden, nil, errs.New("Forbidden", http.StatusForbidden))
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequirePartner authenticates partners by the API key of their
+// data-sharing agreement, sent in the X-API-Key header, and rejects keys of
+// agreements that have ended
+func (s *Server) RequirePartner() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		share, err := s.DataShareService.Authenticate(strings.TrimSpace(c.GetHeader("X-API-Key")))
+		switch {
+		case errors.Is(err, services.ErrInvalidPartnerKey), errors.Is(err, services.ErrDataShareExpired):
+			respondAndAbort(c, err.Error(), http.StatusUnauthorized, nil, errs.New(err.Error(), http.StatusUnauthorized))
+			return
+		case err != nil:
+			respondAndAbort(c, "Unable to check API key", http.StatusInternalServerError, nil, errs.New(err.Error(), http.StatusInternalServerError))
+			return
+		}
+		c.Set("data_share", share)
+		c.Next()
+	}
+}
@@ -0,0 +1,45 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/geo"
+	"github.com/techagentng/citizenx/serializers"
+	"github.com/techagentng/citizenx/services"
+)
+
+// handleListNearbyReports lists the reports within ?radius= km (5 by
+// default) of ?lat= and ?lng=, nearest first
+func (s *Server) handleListNearbyReports() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, err := getPageFromQuery(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page number"})
+			return
+		}
+		origin, err := geo.ParseCoordinates(c.Query("lat"), c.Query("lng"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "lat and lng must give a valid location"})
+			return
+		}
+		radius, err := strconv.ParseFloat(c.DefaultQuery("radius", "5"), 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": services.ErrInvalidRadius.Error()})
+			return
+		}
+
+		reports, err := s.IncidentReportService.NearbyReports(origin.Latitude, origin.Longitude, radius, page)
+		if errors.Is(err, services.ErrInvalidRadius) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"incident_reports": serializers.NearbyReports(reports, origin)})
+	}
+}
@@ -0,0 +1,116 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+)
+
+func (s *Server) handleGetNotificationPreferences() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		prefs, err := s.NotificationService.GetPreferences(c.GetUint("userID"))
+		if err != nil {
+			response.JSON(c, "Failed to load notification preferences", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Notification preferences retrieved", http.StatusOK, prefs, nil)
+	}
+}
+
+// handleUpdateNotificationPreferences takes the settings to change as a
+// channel to category to boolean object, e.g. {"sms": {"status": true}}
+func (s *Server) handleUpdateNotificationPreferences() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var changes models.NotificationPreferences
+		if err := c.ShouldBindJSON(&changes); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification preferences"})
+			return
+		}
+
+		prefs, err := s.NotificationService.UpdatePreferences(c.GetUint("userID"), changes)
+		if errors.Is(err, services.ErrUnknownNotificationSetting) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			response.JSON(c, "Failed to update notification preferences", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Notification preferences updated", http.StatusOK, prefs, nil)
+	}
+}
+
+func (s *Server) handleGetQuietHours() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		hours, err := s.NotificationService.GetQuietHours(c.GetUint("userID"))
+		if err != nil {
+			response.JSON(c, "Failed to load quiet hours", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Quiet hours retrieved", http.StatusOK, hours, nil)
+	}
+}
+
+func (s *Server) handleUpdateQuietHours() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var hours models.QuietHours
+		if err := c.ShouldBindJSON(&hours); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid quiet hours"})
+			return
+		}
+
+		saved, err := s.NotificationService.UpdateQuietHours(c.GetUint("userID"), hours)
+		if errors.Is(err, services.ErrInvalidQuietHours) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			response.JSON(c, "Failed to update quiet hours", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Quiet hours updated", http.StatusOK, saved, nil)
+	}
+}
+
+func (s *Server) handleListNotifications() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+		if err != nil || page < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page number"})
+			return
+		}
+		notifications, err := s.NotificationService.ListNotifications(c.GetUint("userID"), page)
+		if err != nil {
+			response.JSON(c, "Failed to load notifications", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Notifications retrieved", http.StatusOK, notifications, nil)
+	}
+}
+
+// handleMarkNotificationRead records that the user opened a notification,
+// which is what announcement open rates are counted from
+func (s *Server) handleMarkNotificationRead() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+			return
+		}
+		err = s.NotificationService.MarkRead(c.GetUint("userID"), uint(id))
+		if errors.Is(err, services.ErrNotificationNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			response.JSON(c, "Failed to update notification", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Notification marked as read", http.StatusOK, nil, nil)
+	}
+}
@@ -0,0 +1,164 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+)
+
+// respondPayoutError maps payout errors to responses
+func respondPayoutError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, services.ErrPayoutsUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrUnknownBank), errors.Is(err, services.ErrBankAccountNotFound):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrNoBankAccount), errors.Is(err, services.ErrPayoutBatchNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		response.JSON(c, message, http.StatusInternalServerError, nil, err)
+	}
+}
+
+func (s *Server) handleListBanks() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		banks, err := s.PayoutService.Banks()
+		if err != nil {
+			respondPayoutError(c, "Failed to load banks", err)
+			return
+		}
+		c.Header("Cache-Control", "public, max-age=3600")
+		response.JSON(c, "Banks retrieved", http.StatusOK, banks, nil)
+	}
+}
+
+func (s *Server) handleGetBankAccount() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		account, err := s.PayoutService.BankAccount(c.GetUint("userID"))
+		if err != nil {
+			respondPayoutError(c, "Failed to load bank account", err)
+			return
+		}
+		response.JSON(c, "Bank account retrieved", http.StatusOK, account, nil)
+	}
+}
+
+// handleSetBankAccount sets where the user's payouts go, e.g. {"bank_code":
+// "058", "account_number": "0123456789"}. The answer carries the name the
+// bank holds for the user to confirm.
+func (s *Server) handleSetBankAccount() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request models.BankAccountRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A bank_code and a 10 digit account_number are required"})
+			return
+		}
+		account, err := s.PayoutService.SetBankAccount(c.GetUint("userID"), &request)
+		if err != nil {
+			respondPayoutError(c, "Failed to save bank account", err)
+			return
+		}
+		response.JSON(c, "Bank account saved", http.StatusOK, account, nil)
+	}
+}
+
+// handleListMyPayouts returns the user's payouts, newest first, with the
+// amounts written for their locale
+func (s *Server) handleListMyPayouts() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, ok := incidentPage(c)
+		if !ok {
+			return
+		}
+		payouts, err := s.PayoutService.History(c.GetUint("userID"), page)
+		if err != nil {
+			respondPayoutError(c, "Failed to load payouts", err)
+			return
+		}
+		format := requestLocale(c)
+		for i := range payouts {
+			payouts[i].Amount = format.Currency(payouts[i].AmountKobo)
+		}
+		response.JSON(c, "Payouts retrieved", http.StatusOK, payouts, nil)
+	}
+}
+
+// handleCreatePayoutBatch pays out the points of the users listed, e.g.
+// {"program": "Flood monitoring, Q3", "user_ids": [12, 40], "minimum_points":
+// 500}. Users without a bank account or with too few points are skipped.
+func (s *Server) handleCreatePayoutBatch() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request models.PayoutBatchRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A program and user_ids are required"})
+			return
+		}
+		batch, err := s.PayoutService.CreateBatch(&request, c.GetUint("userID"))
+		if err != nil {
+			respondPayoutError(c, "Failed to create payout batch", err)
+			return
+		}
+		response.JSON(c, "Payout batch created", http.StatusCreated, batch, nil)
+	}
+}
+
+func (s *Server) handleListPayoutBatches() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, ok := incidentPage(c)
+		if !ok {
+			return
+		}
+		batches, err := s.PayoutService.Batches(page)
+		if err != nil {
+			respondPayoutError(c, "Failed to load payout batches", err)
+			return
+		}
+		response.JSON(c, "Payout batches retrieved", http.StatusOK, batches, nil)
+	}
+}
+
+func (s *Server) handleListBatchPayouts() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid batch ID"})
+			return
+		}
+		payouts, err := s.PayoutService.BatchPayouts(uint(id))
+		if err != nil {
+			respondPayoutError(c, "Failed to load payouts", err)
+			return
+		}
+		response.JSON(c, "Payouts retrieved", http.StatusOK, payouts, nil)
+	}
+}
+
+// handlePaymentWebhook takes the payment provider's notifications of how
+// transfers ended. Notifications about unknown transfers are acknowledged
+// so the provider stops retrying them.
+func (s *Server) handlePaymentWebhook() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, 1<<20))
+		if err != nil {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		err = s.PayoutService.Reconcile(body, c.GetHeader("X-Paystack-Signature"))
+		switch {
+		case err == nil, errors.Is(err, services.ErrPayoutNotFound):
+			c.Status(http.StatusOK)
+		case errors.Is(err, services.ErrPayoutsUnavailable):
+			c.Status(http.StatusNotFound)
+		default:
+			log.Printf("reconciling payment webhook: %v", err)
+			c.Status(http.StatusBadRequest)
+		}
+	}
+}
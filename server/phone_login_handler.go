@@ -0,0 +1,97 @@
+package server
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+)
+
+// handleRequestPhoneCode sends a sign-in code to the phone number in the
+// body
+func (s *Server) handleRequestPhoneCode() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request models.PhoneLoginRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A phone number is required"})
+			return
+		}
+
+		challenge, err := s.PhoneLoginService.RequestCode(request.Phone, c.ClientIP())
+		if err != nil {
+			respondPhoneLoginError(c, err)
+			return
+		}
+		response.JSON(c, "Code sent", http.StatusOK, challenge, nil)
+	}
+}
+
+// handleVerifyPhoneCode signs in with a code sent by handleRequestPhoneCode,
+// answering 201 when a new phone-only account was opened
+func (s *Server) handleVerifyPhoneCode() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request models.PhoneLoginRequest
+		if err := c.ShouldBindJSON(&request); err != nil || request.Code == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A phone number and code are required"})
+			return
+		}
+
+		login, created, err := s.PhoneLoginService.VerifyCode(request.Phone, request.Code)
+		if err != nil {
+			respondPhoneLoginError(c, err)
+			return
+		}
+		if err := s.ActivityService.Track(login.ID, models.ActivityLogin, map[string]interface{}{"method": "phone_otp"}); err != nil {
+			log.Printf("tracking login of user %d: %v", login.ID, err)
+		}
+		status := http.StatusOK
+		if created {
+			status = http.StatusCreated
+		}
+		response.JSON(c, "login successful", status, login, nil)
+	}
+}
+
+// handleCompleteAccount adds a name, email and password to the signed-in
+// user's phone-only account
+func (s *Server) handleCompleteAccount() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request models.CompleteAccountRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			response.JSON(c, "Invalid account details", http.StatusBadRequest, nil, err)
+			return
+		}
+
+		if err := s.PhoneLoginService.CompleteAccount(c.GetUint("userID"), &request); err != nil {
+			respondPhoneLoginError(c, err)
+			return
+		}
+		response.JSON(c, "Account completed", http.StatusOK, nil, nil)
+	}
+}
+
+func respondPhoneLoginError(c *gin.Context, err error) {
+	if respondDisplayNameRejected(c, err) || respondAgeRejected(c, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, services.ErrInvalidPhone):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrOTPInvalid):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrOTPTooSoon), errors.Is(err, services.ErrOTPRateLimited):
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrOTPUsePassword):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error(), "password_fallback": true})
+	case errors.Is(err, services.ErrOTPUnavailable):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error(), "password_fallback": false})
+	case errors.Is(err, services.ErrAccountComplete), errors.Is(err, services.ErrEmailInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		response.JSON(c, "Unable to sign in with phone", http.StatusInternalServerError, nil, err)
+	}
+}
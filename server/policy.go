@@ -0,0 +1,57 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/policy"
+	"gorm.io/gorm"
+)
+
+// resourceLoader finds the resource a request acts on
+type resourceLoader func(c *gin.Context) (policy.Resource, error)
+
+// subject describes the signed-in user for the policy
+func subject(c *gin.Context) policy.Subject {
+	sub := policy.Subject{UserID: c.GetUint("userID"), Role: c.GetString("user_role")}
+	if user, ok := c.MustGet("user").(*models.User); ok {
+		sub.AgencyID = user.AgencyID
+		sub.AmbassadorLGA = user.AmbassadorLGA
+	}
+	return sub
+}
+
+// Allow lets the request through only when the policy allows the signed-in
+// user to take action on the resource load finds. It must run after
+// Authorize.
+func (s *Server) Allow(action policy.Action, load resourceLoader) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		resource, err := load(c)
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		case err != nil:
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Unable to check access"})
+			return
+		}
+		if err := policy.Authorize(subject(c), action, resource); err != nil {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		c.Next()
+	}
+}
+
+// reportParam loads the report named by the route parameter
+func (s *Server) reportParam(name string) resourceLoader {
+	return func(c *gin.Context) (policy.Resource, error) {
+		report, err := s.IncidentReportRepository.GetIncidentReportByID(c.Param(name))
+		if err != nil {
+			return policy.Resource{}, err
+		}
+		return policy.Report(report), nil
+	}
+}
@@ -2,12 +2,10 @@ package server
 
 import (
 	"net/http"
-	"os"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/techagentng/citizenx/models"
-	jwtPackage "github.com/techagentng/citizenx/services/jwt"
 )
 
 func (s *Server) handleCreatePost() gin.HandlerFunc {
@@ -20,34 +18,14 @@ func (s *Server) handleCreatePost() gin.HandlerFunc {
 		}
 
 		// Validate file type and size (same as for profile images)
-		if err := validateFile(fileHeader); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		if err := s.validateFile(fileHeader); err != nil {
+			if !respondValidationError(c, err) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			}
 			return
 		}
 
-		// Get the access token from the authorization header
-		accessToken := getTokenFromHeader(c)
-		if accessToken == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-			return
-		}
-
-		// Validate and decode the access token to get the userID
-		secret := s.Config.JWTSecret
-		accessClaims, err := jwtPackage.ValidateAndGetClaims(accessToken, secret)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-			return
-		}
-
-		var userID uint
-		switch userIDValue := accessClaims["id"].(type) {
-		case float64:
-			userID = uint(userIDValue)
-		default:
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid userID format"})
-			return
-		}
+		userID := c.GetUint("userID")
 
 		// Validate form fields
 		title := c.PostForm("title")
@@ -59,20 +37,14 @@ func (s *Server) handleCreatePost() gin.HandlerFunc {
 			return
 		}
 
-		// Create S3 client
-		s3Client, err := createS3Client()
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create S3 client"})
-			return
-		}
-
 		userIDString := strconv.FormatUint(uint64(userID), 10)
 
 		// Generate unique filename
 		filename := userIDString + "_" + fileHeader.Filename
 
-		// Upload file to S3
-		filepath, err := uploadFileToS3(s3Client, file, os.Getenv("AWS_BUCKET"), filename)
+		// Upload file to storage
+		defer file.Close()
+		filepath, err := s.ObjectService.Put(filename, file, fileHeader.Size, fileHeader.Header.Get("Content-Type"), models.ObjectOwnerPost, "")
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to upload file to S3"})
 			return
@@ -88,7 +60,7 @@ func (s *Server) handleCreatePost() gin.HandlerFunc {
 		}
 
 		// Save the post to the database
-		if err := s.PostRepository.CreatePost(&post); err != nil {
+		if err := s.PostService.CreatePost(&post); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create post"})
 			return
 		}
@@ -102,29 +74,7 @@ func (s *Server) handleCreatePost() gin.HandlerFunc {
 
 func (s *Server) handleGetPostsByUserID() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Get the access token from the authorization header
-		accessToken := getTokenFromHeader(c)
-		if accessToken == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-			return
-		}
-
-		// Validate and decode the access token to get the userID
-		secret := s.Config.JWTSecret
-		accessClaims, err := jwtPackage.ValidateAndGetClaims(accessToken, secret)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-			return
-		}
-
-		var userID uint
-		switch userIDValue := accessClaims["id"].(type) {
-		case float64:
-			userID = uint(userIDValue)
-		default:
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid userID format"})
-			return
-		}
+		userID := c.GetUint("userID")
 
 		// Fetch all posts by the user from the database
 		posts, err := s.PostRepository.GetPostsByUserID(userID)
@@ -164,9 +114,12 @@ func (s *Server) HandleGetAllPosts() gin.HandlerFunc {
 
 func (s *Server) GetPostByID() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		postID := c.Param("id")
+		postID, ok := s.idParam(c, "id", "post", &models.Post{})
+		if !ok {
+			return
+		}
 
-		post, err := s.PostRepository.GetPostByID(postID)
+		post, err := s.PostRepository.GetPostByID(strconv.FormatUint(uint64(postID), 10))
 		if err != nil {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Post not found"})
 			return
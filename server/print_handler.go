@@ -0,0 +1,45 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/techagentng/citizenx/services"
+)
+
+// handleGetReportPDF prints a published report for anyone
+func (s *Server) handleGetReportPDF() gin.HandlerFunc {
+	return s.reportPDF(true)
+}
+
+// handleGetPrivateReportPDF prints a report whatever its status, for its
+// reporter and admins
+func (s *Server) handleGetPrivateReportPDF() gin.HandlerFunc {
+	return s.reportPDF(false)
+}
+
+func (s *Server) reportPDF(publishedOnly bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reportID := c.Param("id")
+		if _, err := uuid.Parse(reportID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid report ID"})
+			return
+		}
+
+		pdf, err := s.ReportPrintService.ReportPDF(reportID, requestLocale(c), publishedOnly)
+		if errors.Is(err, services.ErrReportNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Header("Content-Disposition", fmt.Sprintf("inline; filename=report-%s.pdf", reportID))
+		c.Data(http.StatusOK, "application/pdf", pdf)
+	}
+}
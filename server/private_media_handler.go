@@ -0,0 +1,67 @@
+package server
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+	"github.com/techagentng/citizenx/storage"
+)
+
+// handleGetMediaOriginal gives a moderator a short lived link to the
+// unmodified original of restricted media
+func (s *Server) handleGetMediaOriginal() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		url, expires, err := s.MediaService.OriginalURL(c.Param("id"))
+		switch {
+		case errors.Is(err, services.ErrMediaNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		case errors.Is(err, services.ErrOriginalNotRestricted):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		case err != nil:
+			response.JSON(c, "Failed to sign media URL", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Media URL signed", http.StatusOK, gin.H{
+			"url":        url,
+			"expires_at": expires.Unix(),
+		}, nil)
+	}
+}
+
+// handleServePrivateMedia serves private objects from local storage to
+// holders of a signed URL. With S3 storage signed URLs point at the bucket
+// and this route is not used.
+func (s *Server) handleServePrivateMedia() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := strings.TrimPrefix(c.Param("key"), "/")
+		object, err := s.ObjectService.OpenSigned(key, c.Query("expires"), c.Query("signature"))
+		switch {
+		case errors.Is(err, storage.ErrInvalidSignature):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		case errors.Is(err, storage.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Media not found"})
+			return
+		case err != nil:
+			response.JSON(c, "Failed to read media", http.StatusInternalServerError, nil, err)
+			return
+		}
+		defer object.Close()
+
+		contentType := mime.TypeByExtension(path.Ext(key))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		c.DataFromReader(http.StatusOK, -1, contentType, object, map[string]string{
+			"Cache-Control": "private, no-store",
+		})
+	}
+}
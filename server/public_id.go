@@ -0,0 +1,38 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// idParam reads the ID of a record of model's type, named noun in errors,
+// from the route parameter name. The parameter is the record's external ID
+// or, while accept_numeric_ids is on, its numeric ID. When neither is valid
+// the request is answered and ok is false.
+func (s *Server) idParam(c *gin.Context, name, noun string, model interface{}) (uint, bool) {
+	value := c.Param(name)
+	if publicID, err := uuid.Parse(value); err == nil {
+		id, err := s.PublicIDRepository.ResolveID(model, publicID)
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": noun + " not found"})
+			return 0, false
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to look up " + noun})
+			return 0, false
+		}
+		return id, true
+	}
+	if s.Config.AcceptNumericIDs {
+		if id, err := strconv.ParseUint(value, 10, 32); err == nil {
+			return uint(id), true
+		}
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + noun + " ID"})
+	return 0, false
+}
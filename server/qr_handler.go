@@ -0,0 +1,73 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/qr"
+	"github.com/techagentng/citizenx/server/response"
+	"gorm.io/gorm"
+)
+
+// handleGetReportQR draws a QR code linking to a report's public page, in
+// ?format=png (the default) or svg, with ?size= in pixels for PNG
+func (s *Server) handleGetReportQR() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reportID := c.Param("id")
+		if _, err := s.IncidentReportRepository.GetIncidentReportByID(reportID); errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Report not found"})
+			return
+		} else if err != nil {
+			response.JSON(c, "Failed to load report", http.StatusInternalServerError, nil, err)
+			return
+		}
+		link, err := s.ShortLinkService.ReportLink(reportID, models.ShortLinkPoster)
+		if err != nil {
+			response.JSON(c, "Failed to make report link", http.StatusInternalServerError, nil, err)
+			return
+		}
+		writeQR(c, link.URL, "report-"+reportID)
+	}
+}
+
+// handleGetCategoryQR draws a QR code linking to the page for reporting in a
+// category, for a campaign's posters and flyers. ?campaign= names the
+// campaign; format and size are as for reports.
+func (s *Server) handleGetCategoryQR() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		category := strings.TrimSpace(c.Param("category"))
+		link, err := s.ShortLinkService.SubmissionLink(category, strings.TrimSpace(c.Query("campaign")))
+		if err != nil {
+			response.JSON(c, "Failed to make submission link", http.StatusInternalServerError, nil, err)
+			return
+		}
+		writeQR(c, link.URL, "report-"+strings.ToLower(strings.ReplaceAll(category, " ", "-")))
+	}
+}
+
+// writeQR answers with content drawn as a QR code in the requested format
+func writeQR(c *gin.Context, content, name string) {
+	format := strings.ToLower(c.DefaultQuery("format", qr.FormatPNG))
+	size, err := strconv.Atoi(c.DefaultQuery("size", strconv.Itoa(qr.DefaultSize)))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "size must be a number of pixels"})
+		return
+	}
+	image, contentType, err := qr.Encode(content, format, size)
+	if errors.Is(err, qr.ErrUnsupportedFormat) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		response.JSON(c, "Failed to draw QR code", http.StatusInternalServerError, nil, err)
+		return
+	}
+	c.Header("Cache-Control", "public, max-age=86400")
+	c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s.%s"`, name, format))
+	c.Data(http.StatusOK, contentType, image)
+}
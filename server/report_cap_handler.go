@@ -0,0 +1,71 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+)
+
+// respondDailyCapReached answers when err rejects a report because its
+// reporter has reached today's limit, reporting whether it did
+func respondDailyCapReached(c *gin.Context, err error) bool {
+	if !errors.Is(err, services.ErrDailyCapReached) {
+		return false
+	}
+	c.JSON(http.StatusTooManyRequests, gin.H{"error": err.Error()})
+	return true
+}
+
+func (s *Server) handleListReportCapExemptions() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		exemptions, err := s.ReportCapService.ListExemptions()
+		if err != nil {
+			response.JSON(c, "Failed to load report cap exemptions", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Report cap exemptions retrieved", http.StatusOK, exemptions, nil)
+	}
+}
+
+// handleCreateReportCapExemption lifts the daily report cap for an
+// organisation's account, e.g. {"user_id": 42, "reason": "State emergency
+// management agency"}
+func (s *Server) handleCreateReportCapExemption() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var exemption models.ReportCapExemption
+		if err := c.ShouldBindJSON(&exemption); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A user_id and a reason of up to 500 characters are required"})
+			return
+		}
+		if err := s.ReportCapService.Exempt(&exemption, c.GetUint("userID")); err != nil {
+			response.JSON(c, "Failed to save report cap exemption", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Report cap exemption saved", http.StatusCreated, exemption, nil)
+	}
+}
+
+func (s *Server) handleDeleteReportCapExemption() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := strconv.ParseUint(c.Param("userID"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+			return
+		}
+		err = s.ReportCapService.RemoveExemption(uint(userID))
+		if errors.Is(err, services.ErrCapExemptionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			response.JSON(c, "Failed to remove report cap exemption", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Report cap exemption removed", http.StatusOK, nil, nil)
+	}
+}
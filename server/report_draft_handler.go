@@ -0,0 +1,180 @@
+package server
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/techagentng/citizenx/geo"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+)
+
+func (s *Server) handleCreateReportDraft() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var draft models.ReportDraft
+		if err := c.ShouldBindJSON(&draft); err != nil {
+			response.JSON(c, "Invalid report details", http.StatusBadRequest, nil, err)
+			return
+		}
+
+		err := s.IncidentReportService.CreateDraft(c.GetUint("userID"), &draft)
+		if errors.Is(err, geo.ErrInvalidPlusCode) {
+			response.JSON(c, "Invalid plus code", http.StatusBadRequest, nil, err)
+			return
+		}
+		if invalidReportLocation(err) {
+			response.JSON(c, "Invalid report location", http.StatusBadRequest, nil, err)
+			return
+		}
+		if respondAgeRejected(c, err) {
+			return
+		}
+		if err != nil {
+			response.JSON(c, "Unable to save report draft", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Report draft created", http.StatusCreated, draft, nil)
+	}
+}
+
+// handleGetReportDraft returns one of the user's drafts with the media
+// uploaded to it so far, so clients can resume a draft or check its
+// uploads before finalizing it
+func (s *Server) handleGetReportDraft() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		draftID := c.Param("id")
+		if _, err := uuid.Parse(draftID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid draft ID"})
+			return
+		}
+		userID := c.GetUint("userID")
+		draft, err := s.IncidentReportService.GetDraft(draftID, userID)
+		if err != nil {
+			respondDraftError(c, err)
+			return
+		}
+		media, err := s.IncidentReportService.GetDraftMedia(draftID, userID)
+		if err != nil {
+			respondDraftError(c, err)
+			return
+		}
+		response.JSON(c, "Report draft retrieved", http.StatusOK, gin.H{"draft": draft, "media": media}, nil)
+	}
+}
+
+func (s *Server) handleUploadDraftMedia() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		draftID := c.Param("id")
+		if _, err := uuid.Parse(draftID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid draft ID"})
+			return
+		}
+		userID := c.GetUint("userID")
+		if _, err := s.IncidentReportService.GetDraft(draftID, userID); err != nil {
+			respondDraftError(c, err)
+			return
+		}
+
+		form, err := c.MultipartForm()
+		if err != nil || len(form.File["mediaFiles"]) == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "No media files found in the request"})
+			return
+		}
+
+		feedURLs, thumbnailURLs, fullsizeURLs, fileTypes, fingerprints, err := s.MediaService.ProcessMedia(c, form.File["mediaFiles"], userID, draftID)
+		if err != nil {
+			log.Printf("Error processing draft media: %v", err)
+			if respondValidationError(c, err) {
+				return
+			}
+			response.JSON(c, "Unable to process media files", http.StatusInternalServerError, nil, err)
+			return
+		}
+
+		for i := range feedURLs {
+			media := models.Media{
+				FeedURL:     feedURLs[i],
+				FileType:    fileTypes[i],
+				SHA256:      fingerprints[i].SHA256,
+				PHash:       fingerprints[i].PHash,
+				OriginalKey: fingerprints[i].OriginalKey,
+				BlurredURL:  fingerprints[i].BlurredURL,
+			}
+			if i < len(thumbnailURLs) {
+				media.ThumbnailURL = thumbnailURLs[i]
+			}
+			if i < len(fullsizeURLs) {
+				media.FullSizeURL = fullsizeURLs[i]
+			}
+			if mark := fingerprints[i].Watermark; mark != nil {
+				media.WatermarkCode = mark.Code
+				media.WatermarkedAt = mark.Timestamp.Unix()
+			}
+			if err := s.MediaService.SaveDraftMedia(media, draftID, userID); err != nil {
+				response.JSON(c, "Unable to save media", http.StatusInternalServerError, nil, err)
+				return
+			}
+		}
+
+		response.JSON(c, "Media added to report draft", http.StatusOK, gin.H{
+			"draftID":       draftID,
+			"feedURLs":      feedURLs,
+			"thumbnailURLs": thumbnailURLs,
+			"fullsizeURLs":  fullsizeURLs,
+			"fileTypes":     fileTypes,
+		}, nil)
+	}
+}
+
+func (s *Server) handleFinalizeReportDraft() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		draftID := c.Param("id")
+		if _, err := uuid.Parse(draftID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid draft ID"})
+			return
+		}
+		user, ok := c.MustGet("user").(*models.User)
+		if !ok {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user type"})
+			return
+		}
+		// Clients uploading media separately name it in a JSON body; mobile
+		// clients finalize without one
+		var req models.ReportDraftFinalizeRequest
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				response.JSON(c, "Invalid request body", http.StatusBadRequest, nil, err)
+				return
+			}
+		}
+
+		report, err := s.IncidentReportService.FinalizeDraft(c.Request.Context(), user, draftID, c.ClientIP(), req.MediaIDs)
+		if err != nil {
+			respondDraftError(c, err)
+			return
+		}
+		response.JSON(c, "Incident Report Submitted Successfully", http.StatusCreated, gin.H{
+			"reportID":            draftID,
+			"savedIncidentReport": report,
+		}, nil)
+	}
+}
+
+func respondDraftError(c *gin.Context, err error) {
+	if respondAgeRejected(c, err) || respondCategoryClosed(c, err) || respondDailyCapReached(c, err) {
+		return
+	}
+	if errors.Is(err, services.ErrDraftNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, services.ErrDraftMediaNotFound) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	response.JSON(c, "Unable to load report draft", http.StatusInternalServerError, nil, err)
+}
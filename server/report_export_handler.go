@@ -0,0 +1,43 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/locale"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+)
+
+// handleExportReports downloads the reports matching the listing filters
+// as CSV or, with format=xlsx, as a spreadsheet
+func (s *Server) handleExportReports() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		format := c.DefaultQuery("format", services.ExportCSV)
+		contentType, err := s.ReportExportService.ContentType(format)
+		if errors.Is(err, services.ErrUnknownExportFormat) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		filters, ok := reportFilterQuery(c)
+		if !ok {
+			return
+		}
+
+		c.Header("Content-Type", contentType)
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="citizenx-reports-%s.%s"`, time.Now().In(locale.TimeZone).Format("2006-01-02"), format))
+		if err := s.ReportExportService.Export(c.Writer, format, filters); err != nil {
+			if c.Writer.Written() {
+				// The header has gone out, so the export just ends short
+				log.Printf("exporting reports: %v", err)
+				return
+			}
+			c.Writer.Header().Del("Content-Disposition")
+			response.JSON(c, "Failed to export reports", http.StatusInternalServerError, nil, err)
+		}
+	}
+}
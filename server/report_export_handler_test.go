@@ -0,0 +1,53 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/mocks"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/services"
+	"go.uber.org/mock/gomock"
+)
+
+func TestExportReports(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	exports := mocks.NewMockReportExportService(ctrl)
+	s := &Server{ReportExportService: exports}
+	r := testRouter(http.MethodGet, "/reports/export", models.RoleAdmin, s.handleExportReports())
+
+	t.Run("unknown format", func(t *testing.T) {
+		exports.EXPECT().ContentType("pdf").Return("", services.ErrUnknownExportFormat)
+		if w := serve(t, r, http.MethodGet, "/reports/export?format=pdf", nil); w.Code != http.StatusBadRequest {
+			t.Fatalf("got status %d, want 400", w.Code)
+		}
+	})
+
+	t.Run("filtered csv", func(t *testing.T) {
+		exports.EXPECT().ContentType(services.ExportCSV).Return("text/csv", nil)
+		exports.EXPECT().Export(gomock.Any(), services.ExportCSV, db.ReportFilter{StateName: "Lagos", Category: "Roads"}).
+			DoAndReturn(func(w io.Writer, format string, filters db.ReportFilter) error {
+				_, err := io.WriteString(w, "id,category\n")
+				return err
+			})
+		w := serve(t, r, http.MethodGet, "/reports/export?state=Lagos&category=Roads", nil)
+		if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "text/csv" {
+			t.Fatalf("got status %d and type %q, want 200 text/csv", w.Code, w.Header().Get("Content-Type"))
+		}
+		if w.Header().Get("Content-Disposition") == "" || w.Body.String() != "id,category\n" {
+			t.Fatalf("got disposition %q and body %q", w.Header().Get("Content-Disposition"), w.Body)
+		}
+	})
+
+	t.Run("fails before writing", func(t *testing.T) {
+		exports.EXPECT().ContentType(services.ExportCSV).Return("text/csv", nil)
+		exports.EXPECT().Export(gomock.Any(), services.ExportCSV, gomock.Any()).Return(errors.New("connection refused"))
+		w := serve(t, r, http.MethodGet, "/reports/export", nil)
+		if w.Code != http.StatusInternalServerError || w.Header().Get("Content-Disposition") != "" {
+			t.Fatalf("got status %d and disposition %q, want 500 without an attachment", w.Code, w.Header().Get("Content-Disposition"))
+		}
+	})
+}
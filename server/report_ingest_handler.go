@@ -0,0 +1,50 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+)
+
+// queueIncidentReport acknowledges a report with 202 once it is queued.
+// The client follows it at /reports/submissions/:id until it is saved.
+func (s *Server) queueIncidentReport(c *gin.Context, userID uint, report *models.IncidentReport, subReportType string) {
+	submission, err := s.ReportIngestService.Queue(userID, report, subReportType)
+	if invalidReportLocation(err) {
+		response.JSON(c, "Invalid report location", http.StatusBadRequest, nil, err)
+		return
+	}
+	if respondCategoryClosed(c, err) || respondDailyCapReached(c, err) {
+		return
+	}
+	if err != nil {
+		response.JSON(c, "Unable to queue incident report", http.StatusInternalServerError, nil, err)
+		return
+	}
+	response.JSON(c, "Incident Report Received", http.StatusAccepted, gin.H{
+		"reportID":   submission.ID.String(),
+		"submission": submission,
+		"status_url": "/api/v1/reports/submissions/" + submission.ID.String(),
+	}, nil)
+}
+
+// handleGetReportSubmission returns the status of one of the user's queued
+// report submissions
+func (s *Server) handleGetReportSubmission() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		submission, err := s.ReportIngestService.GetSubmission(c.Param("id"), c.GetUint("userID"))
+		if errors.Is(err, services.ErrSubmissionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			response.JSON(c, "Failed to load report submission", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Report submission retrieved", http.StatusOK, submission, nil)
+	}
+}
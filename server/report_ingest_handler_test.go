@@ -0,0 +1,46 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/techagentng/citizenx/mocks"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/services"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetReportSubmission(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	ingest := mocks.NewMockReportIngestService(ctrl)
+	s := &Server{ReportIngestService: ingest}
+	r := testRouter(http.MethodGet, "/reports/submissions/:id", models.RoleUser, s.handleGetReportSubmission())
+	id := uuid.New()
+
+	// Submissions are looked up for the signed-in user only
+	ingest.EXPECT().GetSubmission(id.String(), testUser.ID).Return(&models.ReportSubmission{
+		ID:     id,
+		UserID: testUser.ID,
+		Status: models.SubmissionQueued,
+	}, nil)
+	w := serve(t, r, http.MethodGet, "/reports/submissions/"+id.String(), nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("got status %d, want 200: %s", w.Code, w.Body)
+	}
+	var body struct {
+		Data models.ReportSubmission `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if body.Data.Status != models.SubmissionQueued {
+		t.Fatalf("got status %q, want %q", body.Data.Status, models.SubmissionQueued)
+	}
+
+	ingest.EXPECT().GetSubmission(id.String(), testUser.ID).Return(nil, services.ErrSubmissionNotFound)
+	if w := serve(t, r, http.MethodGet, "/reports/submissions/"+id.String(), nil); w.Code != http.StatusNotFound {
+		t.Fatalf("got status %d for another user's submission, want 404", w.Code)
+	}
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+)
+
+// handleEditReport applies the reporter's edit to their report while the
+// edit window is open
+func (s *Server) handleEditReport() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var edit models.ReportEditRequest
+		if err := c.ShouldBindJSON(&edit); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+		revision, err := s.IncidentReportService.EditReport(c.GetUint("userID"), c.Param("reportID"), edit)
+		if err != nil {
+			respondRevisionError(c, err)
+			return
+		}
+		response.JSON(c, "Report updated", http.StatusOK, gin.H{"version": revision.Version + 1, "previous_version": revision.Version}, nil)
+	}
+}
+
+// handleListReportRevisions lists a report's earlier versions, oldest
+// first
+func (s *Server) handleListReportRevisions() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		revisions, err := s.IncidentReportService.GetReportRevisions(c.Param("id"))
+		if err != nil {
+			respondRevisionError(c, err)
+			return
+		}
+		response.JSON(c, "Report revisions retrieved", http.StatusOK, gin.H{
+			"revisions":       revisions,
+			"current_version": len(revisions) + 1,
+		}, nil)
+	}
+}
+
+// handleDiffReportRevisions shows what changed between ?from= and ?to=
+// versions of a report, by default in its latest edit
+func (s *Server) handleDiffReportRevisions() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var versions [2]int
+		for i, param := range []string{"from", "to"} {
+			value := c.Query(param)
+			if value == "" {
+				continue
+			}
+			version, err := strconv.Atoi(value)
+			if err != nil || version < 1 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": param + " must be a version number"})
+				return
+			}
+			versions[i] = version
+		}
+		diff, err := s.IncidentReportService.DiffReportRevisions(c.Param("id"), versions[0], versions[1])
+		if err != nil {
+			respondRevisionError(c, err)
+			return
+		}
+		response.JSON(c, "Report changes retrieved", http.StatusOK, diff, nil)
+	}
+}
+
+func respondRevisionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidReportEdit):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrReportNotFound), errors.Is(err, services.ErrRevisionNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrNotReportOwner):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrReportNotEditable):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		response.JSON(c, "Failed to process report revision", http.StatusInternalServerError, nil, err)
+	}
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+)
+
+// respondReportStatusError maps report status errors to responses
+func respondReportStatusError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, services.ErrUnknownReportStatus):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrReportNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidStatusTransition):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		response.JSON(c, message, http.StatusInternalServerError, nil, err)
+	}
+}
+
+// moderationErrorStatus is the status code for an error approving,
+// rejecting or accepting a report: a conflict when the report's status does
+// not allow it
+func moderationErrorStatus(err error) int {
+	if errors.Is(err, db.ErrInvalidTransition) {
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+// handleChangeReportStatus moves a report to another status, e.g.
+// {"status": "under_review", "reason": "Checking with the LGA"}
+func (s *Server) handleChangeReportStatus() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var change models.ReportStatusChange
+		if err := c.ShouldBindJSON(&change); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A status and a reason of up to 500 characters are expected"})
+			return
+		}
+		transition, err := s.ReportStatusService.ChangeStatus(c.Param("reportID"), &change, c.GetUint("userID"))
+		if err != nil {
+			respondReportStatusError(c, "Failed to change report status", err)
+			return
+		}
+		response.JSON(c, "Report status changed", http.StatusOK, gin.H{
+			"transition": transition,
+			"next":       models.ReportStatusTransitions(transition.ToStatus),
+		}, nil)
+	}
+}
+
+func (s *Server) handleListReportStatusHistory() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		transitions, err := s.ReportStatusService.History(c.Param("id"))
+		if err != nil {
+			respondReportStatusError(c, "Failed to load report status history", err)
+			return
+		}
+		response.JSON(c, "Report status history retrieved", http.StatusOK, transitions, nil)
+	}
+}
@@ -0,0 +1,59 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+)
+
+// respondReportTransferError maps report transfer errors to responses
+func respondReportTransferError(c *gin.Context, message string, err error) {
+	switch {
+	case errors.Is(err, services.ErrTransferRecipientRequired), errors.Is(err, services.ErrInvalidPhone):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrReportNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrTransferToOwner), errors.Is(err, services.ErrTransferRewardsSpent):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		response.JSON(c, message, http.StatusInternalServerError, nil, err)
+	}
+}
+
+// handleTransferReport hands a report to another account, e.g. {"email":
+// "ada@example.com", "reason": "Filed by the call centre for the caller"}.
+// The response is the same whether or not an account matches, so transfers
+// can't be used to find out who has an account; the report's transfers
+// show where it went.
+func (s *Server) handleTransferReport() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request models.ReportTransferRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A reason of up to 500 characters is required"})
+			return
+		}
+		byAdmin := strings.EqualFold(c.GetString("user_role"), models.RoleAdmin)
+		_, err := s.ReportTransferService.Transfer(c.Param("id"), &request, c.GetUint("userID"), byAdmin)
+		if err != nil && !errors.Is(err, services.ErrTransferRecipientNotFound) {
+			respondReportTransferError(c, "Failed to transfer report", err)
+			return
+		}
+		response.JSON(c, "If an account matches the recipient, the report has been transferred to it", http.StatusOK, nil, nil)
+	}
+}
+
+func (s *Server) handleListReportTransfers() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		transfers, err := s.ReportTransferService.History(c.Param("id"))
+		if err != nil {
+			respondReportTransferError(c, "Failed to load report transfers", err)
+			return
+		}
+		response.JSON(c, "Report transfers retrieved", http.StatusOK, transfers, nil)
+	}
+}
@@ -0,0 +1,99 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+)
+
+// handleGetTriageQueue lists reports awaiting moderation with their
+// reporters' reputations, most reputable first. ?flagged=true shows only
+// reports from VPN and datacenter addresses.
+func (s *Server) handleGetTriageQueue() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, ok := incidentPage(c)
+		if !ok {
+			return
+		}
+		queue, err := s.ReputationService.TriageQueue(page, c.Query("flagged") == "true")
+		if err != nil {
+			response.JSON(c, "Failed to load moderation queue", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Moderation queue retrieved", http.StatusOK, queue, nil)
+	}
+}
+
+func (s *Server) handleGetReputation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, ok := s.idParam(c, "id", "user", &models.User{})
+		if !ok {
+			return
+		}
+		reputation, err := s.ReputationService.GetReputation(userID)
+		if err != nil {
+			response.JSON(c, "Failed to load reputation", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Reputation retrieved", http.StatusOK, reputation, nil)
+	}
+}
+
+// handleListReportAudits lists sampled auto-published reports awaiting
+// audit, or with ?outcome=passed or failed the completed audits
+func (s *Server) handleListReportAudits() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, ok := incidentPage(c)
+		if !ok {
+			return
+		}
+		audits, err := s.AutoPublishService.ListAudits(c.Query("outcome"), page)
+		if errors.Is(err, services.ErrInvalidAuditOutcome) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			response.JSON(c, "Failed to load report audits", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Report audits retrieved", http.StatusOK, audits, nil)
+	}
+}
+
+// handleCompleteReportAudit records an audit's outcome, e.g.
+// {"outcome": "failed", "note": "photo is from another incident"}
+func (s *Server) handleCompleteReportAudit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid audit ID"})
+			return
+		}
+		var body struct {
+			Outcome string `json:"outcome" binding:"required"`
+			Note    string `json:"note"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "An outcome is required"})
+			return
+		}
+		audit, err := s.AutoPublishService.CompleteAudit(uint(id), c.GetUint("userID"), body.Outcome, body.Note)
+		switch {
+		case errors.Is(err, services.ErrInvalidAuditOutcome):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrAuditNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrAuditCompleted):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case err != nil:
+			response.JSON(c, "Failed to record audit", http.StatusInternalServerError, nil, err)
+		default:
+			response.JSON(c, "Audit recorded", http.StatusOK, audit, nil)
+		}
+	}
+}
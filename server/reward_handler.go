@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/server/response"
 )
 
 func (s *Server) handleSumAllRewardsBalance() gin.HandlerFunc {
@@ -58,3 +59,16 @@ func (s *Server) handleGetUserRewardBalance() gin.HandlerFunc {
 		})
 	}
 }
+
+// handleGetBonusAreas lists the categories and areas whose reports earn
+// bonus points when approved
+func (s *Server) handleGetBonusAreas() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		bonuses, err := s.RewardBonusService.Bonuses()
+		if err != nil {
+			response.JSON(c, "Failed to load bonus areas", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Bonus areas retrieved", http.StatusOK, bonuses, nil)
+	}
+}
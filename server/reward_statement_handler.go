@@ -0,0 +1,72 @@
+package server
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/locale"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+)
+
+// handleGetRewardStatement returns the user's statement of points for
+// ?month=YYYY-MM, the current month by default, as a PDF, or as CSV or
+// JSON with ?format=
+func (s *Server) handleGetRewardStatement() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		statement, err := s.RewardStatementService.Statement(c.MustGet("user").(*models.User), c.Query("month"))
+		if errors.Is(err, services.ErrInvalidMonth) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			response.JSON(c, "Failed to load reward statement", http.StatusInternalServerError, nil, err)
+			return
+		}
+
+		filename := "citizenx-rewards-" + statement.Month
+		switch c.DefaultQuery("format", "pdf") {
+		case "pdf":
+			pdf, err := s.RewardStatementService.StatementPDF(statement, requestLocale(c))
+			if err != nil {
+				response.JSON(c, "Failed to render reward statement", http.StatusInternalServerError, nil, err)
+				return
+			}
+			c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.pdf"`, filename))
+			c.Data(http.StatusOK, "application/pdf", pdf)
+		case "csv":
+			c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, filename))
+			c.Data(http.StatusOK, "text/csv", rewardStatementCSV(statement))
+		case "json":
+			response.JSON(c, "Reward statement retrieved", http.StatusOK, statement, nil)
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "format must be pdf, csv or json"})
+		}
+	}
+}
+
+// rewardStatementCSV writes a statement as CSV: opening and closing balance
+// rows around a row per entry, with Lagos times and amounts as plain
+// integers for spreadsheets
+func rewardStatementCSV(statement *models.RewardStatement) []byte {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Write([]string{"date", "type", "description", "reference", "points", "balance"})
+	w.Write([]string{"", "opening", "Opening balance", "", "", strconv.FormatInt(statement.OpeningBalance, 10)})
+	for _, line := range statement.Lines {
+		w.Write([]string{
+			time.Unix(line.At, 0).In(locale.TimeZone).Format("2006-01-02 15:04"), line.Kind, line.Description, line.Reference,
+			strconv.FormatInt(line.Points, 10), strconv.FormatInt(line.Balance, 10),
+		})
+	}
+	w.Write([]string{"", "closing", "Closing balance", "", "", strconv.FormatInt(statement.ClosingBalance, 10)})
+	w.Flush()
+	return buf.Bytes()
+}
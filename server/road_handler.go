@@ -0,0 +1,93 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/serializers"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+)
+
+// handleListRoadSegments lists road segments, optionally of one road or
+// state, e.g. ?road=Lagos-Ibadan Expressway
+func (s *Server) handleListRoadSegments() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, ok := incidentPage(c)
+		if !ok {
+			return
+		}
+		segments, err := s.RoadService.ListSegments(c.Query("state"), c.Query("road"), page)
+		if err != nil {
+			response.JSON(c, "Failed to list road segments", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Road segments retrieved", http.StatusOK, segments, nil)
+	}
+}
+
+// handleGetRoadConditions aggregates road-condition reports per segment
+// over the last days (default 90), for works ministries planning repairs
+func (s *Server) handleGetRoadConditions() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		days, err := strconv.Atoi(c.DefaultQuery("days", "0"))
+		if err != nil || days < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid days"})
+			return
+		}
+		conditions, err := s.RoadService.SegmentConditions(c.Query("state"), c.Query("road"), days)
+		if err != nil {
+			response.JSON(c, "Failed to load road conditions", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Road conditions retrieved", http.StatusOK, conditions, nil)
+	}
+}
+
+func (s *Server) handleGetRoadSegmentReports() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, ok := s.idParam(c, "id", "road segment", &models.RoadSegment{})
+		if !ok {
+			return
+		}
+		page, ok := incidentPage(c)
+		if !ok {
+			return
+		}
+		reports, err := s.RoadService.SegmentReports(id, page)
+		if errors.Is(err, services.ErrRoadSegmentNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			response.JSON(c, "Failed to load road segment reports", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Road segment reports retrieved", http.StatusOK, serializers.PublicReports(reports), nil)
+	}
+}
+
+// handleCreateRoadSegment adds a segment from its centre line as an encoded
+// polyline
+func (s *Server) handleCreateRoadSegment() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var segment models.RoadSegment
+		if err := c.ShouldBindJSON(&segment); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A road, name and polyline are required"})
+			return
+		}
+		err := s.RoadService.CreateSegment(&segment, c.GetUint("userID"))
+		if errors.Is(err, services.ErrInvalidRoute) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			response.JSON(c, "Failed to create road segment", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Road segment created", http.StatusCreated, segment, nil)
+	}
+}
@@ -1,28 +1,52 @@
 package server
 
 import (
+	"expvar"
 	"fmt"
 
 	// rateLimit "github.com/JGLTechnologies/gin-rate-limit"
 	// "net/http"
+	"log"
 	"os"
 	// "path/filepath"
 	// "runtime"
+	"strings"
 	"time"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/policy"
 )
 
+// trustProxies limits the proxies whose X-Forwarded-For gin believes to those
+// configured, so ClientIP - which per-IP limits and report locations rely on -
+// can't be forged by a client setting the header itself.
+func (s *Server) trustProxies(r *gin.Engine) {
+	var proxies []string
+	if s.Config != nil {
+		for _, p := range strings.Split(s.Config.TrustedProxies, ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				proxies = append(proxies, p)
+			}
+		}
+	}
+	if err := r.SetTrustedProxies(proxies); err != nil {
+		log.Printf("invalid trusted proxies %q, trusting none: %v", s.Config.TrustedProxies, err)
+		_ = r.SetTrustedProxies(nil)
+	}
+}
+
 func (s *Server) setupRouter() *gin.Engine {
 	ginMode := os.Getenv("GIN_MODE")
 	if ginMode == "test" {
 		r := gin.New()
+		s.trustProxies(r)
 		s.defineRoutes(r)
 		return r
 	}
 
 	r := gin.New()
+	s.trustProxies(r)
 	// r.Static("/static", "./build/static")
 
 	// staticFiles := "server/templates/static"
@@ -61,8 +85,10 @@ func (s *Server) setupRouter() *gin.Engine {
 	// Use CORS middleware with appropriate configuration
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"https://citizenx.ng", "http://localhost:3001", "https://citizenx-9hk2.onrender.com", "https://www.citizenx-9hk2.onrender.com", "https://www.citizenx.ng"}, 
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE"},
-		AllowHeaders:     []string{"Origin", "Authorization", "Content-Type"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "HEAD", "DELETE"},
+		AllowHeaders:     []string{"Origin", "Authorization", "Content-Type", "Tus-Resumable", "Upload-Length", "Upload-Metadata", "Upload-Offset", "X-Step-Up-Token"},
+		// Resumable upload clients read these to find where to continue
+		ExposeHeaders:    []string{"Location", "Tus-Resumable", "Tus-Version", "Tus-Extension", "Tus-Max-Size", "Upload-Offset", "Upload-Length", "Upload-Media-Id"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
@@ -77,9 +103,20 @@ func (s *Server) defineRoutes(router *gin.Engine) {
 	// store := rateLimit.InMemoryStore(&rateLimit.InMemoryOptions{})
 	// limitRate := limitRateForPasswordReset(store)
 
+	router.GET("/.well-known/jwks.json", s.handleJWKS())
+	router.GET("/s/:code", s.handleFollowShortLink())
+	router.POST("/webhooks/payments", s.handlePaymentWebhook())
+
 	apirouter := router.Group("/api/v1")
 	apirouter.POST("/auth/signup", s.handleSignup())
 	apirouter.POST("/auth/login", s.handleLogin())
+	apirouter.POST("/auth/phone/code", s.handleRequestPhoneCode())
+	apirouter.POST("/auth/phone/verify", s.handleVerifyPhoneCode())
+	apirouter.POST("/auth/magic-link", s.handleRequestMagicLink())
+	apirouter.POST("/auth/magic-link/verify", s.handleVerifyMagicLink())
+	apirouter.GET("/digests/unsubscribe", s.handleConfirmDigestUnsubscribe())
+	apirouter.POST("/digests/unsubscribe", s.handleUnsubscribeDigestLink())
+	apirouter.POST("/auth/refresh", s.handleRefreshSession())
 	apirouter.POST("/no-cred/login", restrictAccessToProtectedRoutes(), s.handleNonCredentialLogin())
 	apirouter.GET("/fb/auth", s.handleFBLogin())
 	apirouter.GET("fb/callback", s.handleFBCallback())
@@ -89,6 +126,40 @@ func (s *Server) defineRoutes(router *gin.Engine) {
 	apirouter.GET("/incident_reports/state/:state", s.handleGetAllReportsByState())
 	apirouter.GET("/incident_reports/lga/:lga", s.handleGetAllReportsByLGA())
 	apirouter.GET("/incident_reports/report_type/:report_type", s.handleGetAllReportsByReportType())
+	apirouter.GET("/reports", s.handleListReports())
+	apirouter.GET("/reports/trending", s.handleListTrendingReports())
+	apirouter.GET("/reports/nearby", s.handleListNearbyReports())
+	apirouter.GET("/reports/markers", s.handleGetReportMarkers())
+	apirouter.GET("/reports/search", s.handleSearchReports())
+	apirouter.GET("/tiles/reports/:z/:x/:y", s.handleGetReportTile())
+	apirouter.GET("/evidence/public-key", s.handleGetEvidencePublicKey())
+	apirouter.GET("/reports/:id/pdf", s.handleGetReportPDF())
+	apirouter.GET("/media/private/*key", s.handleServePrivateMedia())
+	apirouter.GET("/incidents", s.handleListIncidents())
+	apirouter.GET("/incidents/:id", s.handleGetIncident())
+	apirouter.GET("/incidents/:id/reports", s.handleGetIncidentReports())
+	apirouter.GET("/incidents/:id/timeline", s.handleGetIncidentTimeline())
+	apirouter.GET("/config", s.handleGetClientConfig())
+	apirouter.GET("/help", s.handleGetHelp())
+	apirouter.GET("/help/:slug", s.handleGetHelpArticle())
+	apirouter.GET("/legal", s.handleGetLegalDocuments())
+	apirouter.GET("/legal/:kind", s.handleGetLegalDocument())
+	apirouter.GET("/agencies", s.handleListAgencies())
+	apirouter.GET("/agencies/scorecards", s.handleGetAgencyScorecards())
+	apirouter.GET("/agencies/:id/scorecard", s.handleGetAgencyScorecard())
+	apirouter.GET("/landmarks", s.handleSearchLandmarks())
+	apirouter.GET("/landmarks/:id/reports", s.handleGetLandmarkReports())
+	apirouter.GET("/reports/:id/location", s.handleGetReportLocation())
+	apirouter.GET("/reports/:id/share", s.handleShareReport())
+	apirouter.GET("/reports/:id/qr", s.handleGetReportQR())
+	apirouter.GET("/categories/:category/qr", s.handleGetCategoryQR())
+	apirouter.GET("/reports/:id/points", s.handleGetReportPoints())
+	apirouter.GET("/reports/:id/contributions", s.handleListContributions())
+	apirouter.GET("/reports/:id/history-context", s.handleGetReportHistoryContext())
+	apirouter.GET("/reports/geojson", s.handleGetReportsGeoJSON())
+	apirouter.GET("/roads/segments", s.handleListRoadSegments())
+	apirouter.GET("/roads/conditions", s.handleGetRoadConditions())
+	apirouter.GET("/roads/segments/:id/reports", s.handleGetRoadSegmentReports())
 	// apirouter.GET("/verifyEmail/:token", s.HandleVerifyEmail())
 	apirouter.POST("/password/forgot", s.HandleForgotPassword())
 	apirouter.POST("/password/reset/:token", s.HandleForgotPassword())
@@ -97,21 +168,96 @@ func (s *Server) defineRoutes(router *gin.Engine) {
 	apirouter.GET("/publication/:id", s.GetPostByID())
 
 	authorized := apirouter.Group("/")
-	authorized.Use(s.Authorize())
+	authorized.Use(s.Authorize(), s.RequireConsent())
 	// Upload endpoint
 	authorized.GET("/logout", s.handleLogout())
 	authorized.GET("/users/online", s.handleGetOnlineUsers())
 	authorized.POST("/user/report/", s.handleIncidentReport())
 	authorized.POST("/user/report/media", s.handleUploadMedia())
+	authorized.GET("/reports/submissions/:id", s.handleGetReportSubmission())
+	authorized.GET("/reports/export", s.RequireAdmin(), s.handleExportReports())
+	authorized.POST("/reports/drafts", s.handleCreateReportDraft())
+	authorized.GET("/reports/drafts/:id", s.handleGetReportDraft())
+	authorized.POST("/reports/drafts/:id/media", s.handleUploadDraftMedia())
+	authorized.POST("/reports/drafts/:id/finalize", s.handleFinalizeReportDraft())
+	authorized.PUT("/reports/:reportID/resolution", s.handleConfirmResolution())
+	authorized.GET("/reports/:id/collaborators", s.handleListCollaborators())
+	authorized.POST("/reports/:id/collaborators", s.handleAddCollaborator())
+	authorized.DELETE("/reports/:id/collaborators/:userID", s.handleRemoveCollaborator())
+	authorized.PUT("/reports/:reportID/reward-shares", s.handleSetRewardShares())
+	authorized.POST("/reports/:id/updates", s.handleAddReportUpdate())
+	authorized.POST("/reports/:id/media", s.handleAddReportMedia())
+	authorized.GET("/reports/:id/comments", s.handleListComments())
+	authorized.POST("/reports/:id/comments", s.handleAddComment())
+	authorized.PUT("/reports/:reportID/comments/:commentID", s.handleEditComment())
+	authorized.DELETE("/reports/:id/comments/:commentID", s.handleDeleteComment())
+	authorized.PUT("/reports/:reportID/media/:mediaID/graphic", s.Allow(policy.MarkMediaGraphic, s.reportParam("reportID")), s.handleMarkMediaGraphic())
+	authorized.POST("/reports/:id/transfer", s.Allow(policy.TransferReport, s.reportParam("id")), s.handleTransferReport())
+	authorized.GET("/reports/:id/transfers", s.Allow(policy.TransferReport, s.reportParam("id")), s.handleListReportTransfers())
+	authorized.PUT("/reports/:reportID/status", s.Allow(policy.ReviewReport, s.reportParam("reportID")), s.handleChangeReportStatus())
+	authorized.GET("/reports/:id/pdf/private", s.Allow(policy.PrintReport, s.reportParam("id")), s.handleGetPrivateReportPDF())
+	authorized.GET("/reports/:id/status-history", s.Allow(policy.ViewReportHistory, s.reportParam("id")), s.handleListReportStatusHistory())
+	authorized.PUT("/reports/:reportID", s.handleEditReport())
+	authorized.GET("/reports/:id/revisions", s.Allow(policy.ViewReportHistory, s.reportParam("id")), s.handleListReportRevisions())
+	authorized.GET("/reports/:id/revisions/diff", s.Allow(policy.ViewReportHistory, s.reportParam("id")), s.handleDiffReportRevisions())
+	authorized.GET("/reports/:id/duplicates", s.Allow(policy.ViewDuplicates, s.reportParam("id")), s.handleGetReportDuplicates())
+	authorized.POST("/locations/normalize", s.handleNormalizeLocation())
+	authorized.POST("/agency/reports/:reportID/acknowledge", s.Allow(policy.RespondToReport, s.reportParam("reportID")), s.handleAcknowledgeAgencyReport())
+	authorized.POST("/agency/reports/:reportID/resolve", s.Allow(policy.RespondToReport, s.reportParam("reportID")), s.handleResolveAgencyReport())
+	authorized.POST("/agency/reports/statuses", s.handleUpdateAgencyStatuses())
+	authorized.GET("/ambassador/dashboard", s.handleGetAmbassadorDashboard())
+	authorized.GET("/ambassador/summaries", s.handleGetAmbassadorSummaries())
+	authorized.POST("/ambassador/reports/:reportID/verify", s.Allow(policy.VerifyReport, s.reportParam("reportID")), s.handleVerifyReport())
+
+	uploads := authorized.Group("/uploads")
+	uploads.Use(requireTus())
+	uploads.OPTIONS("", s.handleUploadOptions())
+	uploads.POST("", s.handleCreateUpload())
+	uploads.HEAD("/:id", s.handleUploadStatus())
+	uploads.PATCH("/:id", s.handlePatchUpload())
+	uploads.DELETE("/:id", s.handleDeleteUpload())
+
 	authorized.GET("/categories", s.handleGetAllCategories())
 	authorized.GET("/states", s.handleGetAllStates())
 	authorized.PUT("/me/updateUserProfile", s.handleEditUserProfile())
 	authorized.GET("/me", s.handleShowProfile())
+	authorized.GET("/me/consents", s.handleGetConsents())
+	authorized.POST("/me/consents", s.handleAcceptLegalDocuments())
+	authorized.POST("/feedback", s.handleSubmitFeedback())
+	authorized.GET("/me/identity", s.handleGetIdentityStatus())
+	authorized.POST("/me/identity", s.handleVerifyIdentity())
+	authorized.GET("/me/stats", s.handleGetMyStats())
+	authorized.PUT("/me/daily-goal", s.handleSetDailyGoal())
+	authorized.GET("/me/digests", s.handleListDigests())
+	authorized.POST("/me/digests", s.handleSubscribeDigest())
+	authorized.DELETE("/me/digests/:id", s.handleUnsubscribeDigest())
+	authorized.GET("/me/rewards/statement", s.handleGetRewardStatement())
+	authorized.GET("/banks", s.handleListBanks())
+	authorized.GET("/me/bank-account", s.handleGetBankAccount())
+	authorized.PUT("/me/bank-account", s.RequireStepUp(), s.handleSetBankAccount())
+	authorized.GET("/me/payouts", s.handleListMyPayouts())
+	authorized.GET("/me/reports", s.handleListMyReports())
+	authorized.POST("/me/reports/:id/withdraw", s.handleWithdrawReport())
+	authorized.GET("/me/information-requests", s.handleListMyInformationRequests())
+	authorized.POST("/me/information-requests/:id/answer", s.handleAnswerInformationRequest())
+	authorized.POST("/me/complete-account", s.RequireStepUp(), s.handleCompleteAccount())
+	authorized.POST("/me/step-up", s.handleStepUp())
+	authorized.POST("/me/deactivate", s.RequireStepUp(), s.handleDeactivateAccount())
+	authorized.PUT("/me/email", s.RequireStepUp(), s.handleChangeEmail())
+	authorized.GET("/me/display-name-appeals", s.handleListMyDisplayNameAppeals())
+	authorized.POST("/me/display-name-appeals", s.handleAppealDisplayName())
+	authorized.DELETE("/me/location-history", s.handleDeleteLocationHistory())
+	authorized.GET("/me/notification-preferences", s.handleGetNotificationPreferences())
+	authorized.PUT("/me/notification-preferences", s.handleUpdateNotificationPreferences())
+	authorized.GET("/me/quiet-hours", s.handleGetQuietHours())
+	authorized.PUT("/me/quiet-hours", s.handleUpdateQuietHours())
+	authorized.GET("/me/notifications", s.handleListNotifications())
+	authorized.PUT("/me/notifications/:id/read", s.handleMarkNotificationRead())
 	authorized.GET("/user/bookmark/:reportID", s.HandleBookmarkReport())
 	authorized.GET("/user/bookmarked/report", s.HandleGetBookmarkedReports())
-	authorized.GET("/approve/:reportID/:userID/report", s.handleApproveReportPoints())
-	authorized.GET("/reject/:reportID/:userID/report", s.handleRejectReportPoints())
-	authorized.GET("/accept/:reportID/:userID/report", s.handleAcceptReportPoints())
+	authorized.GET("/approve/:reportID/:userID/report", s.Allow(policy.ReviewReport, s.reportParam("reportID")), s.handleApproveReportPoints())
+	authorized.GET("/reject/:reportID/:userID/report", s.Allow(policy.ReviewReport, s.reportParam("reportID")), s.handleRejectReportPoints())
+	authorized.GET("/accept/:reportID/:userID/report", s.Allow(policy.ReviewReport, s.reportParam("reportID")), s.handleAcceptReportPoints())
 	authorized.GET("/report-percentage-by-state", s.handleGetReportPercentageByState())
 	authorized.GET("/today/report", s.handleGetTodayReportCount())
 	authorized.GET("/all/user", s.handleGetTotalUserCount())
@@ -122,10 +268,17 @@ func (s *Server) defineRoutes(router *gin.Engine) {
 	authorized.GET("/count/all/rewards", s.handleSumAllRewardsBalance())
 	authorized.GET("/users/lga/:lga/report-type/:reportType", s.handleGetReportsByTypeAndLGA())
 	authorized.GET("/rewards/list", s.handleGetAllRewardsList())
+	authorized.GET("/rewards/bonus-areas", s.handleGetBonusAreas())
 	authorized.GET("/report/type/count", s.handleGetReportTypeCounts())
 	authorized.GET("/lgas", s.handleGetLGAs())
 	authorized.GET("/lgas/lat/lng", s.IncidentMarkersHandler())
-	authorized.DELETE("/incident-report/:id", s.DeleteIncidentReportHandler())
+	authorized.GET("/analytics/heatmap", s.handleGetHeatmap())
+	authorized.GET("/analytics/reports/timeseries", s.handleGetReportTimeseries())
+	authorized.GET("/analytics/calendar", s.handleGetCalendar())
+	authorized.GET("/analytics/reports/snapshots", s.handleGetReportSnapshot())
+	authorized.POST("/analytics/reports/query", s.handleQueryReports())
+	authorized.GET("/incident-report/:id", s.handleGetIncidentReport())
+	authorized.DELETE("/incident-report/:id", s.Allow(policy.DeleteReport, s.reportParam("id")), s.DeleteIncidentReportHandler())
 	authorized.GET("/incident-report/state/count", s.HandleGetStateReportCounts())
 	authorized.PUT("/upload", s.handleUpdateUserImageUrl())
 	authorized.GET("/report/rating", s.handleGetRatingPercentages())
@@ -136,15 +289,104 @@ func (s *Server) defineRoutes(router *gin.Engine) {
 	authorized.GET("/report/sub_reports", s.HandleGetSubReportsByCategory())
 	authorized.PUT("/report/upvote/:reportID", s.HandleUpvoteReport())
 	authorized.PUT("/report/downvote/:reportID", s.HandleDownvoteReport())
+	authorized.DELETE("/report/vote/:reportID", s.HandleRetractVote())
 	authorized.GET("/user/reports", s.HandleGetAllReportsByUser())
 	authorized.GET("/report/votecounts/:reportID", s.HandleGetVoteCounts())
 	authorized.GET("/report/counts/lga/:lga", s.GetReportTypeCountsByLGA())
 	authorized.GET("/report/counts/state/:state", s.GetReportCountsByStateAndLGA())
-	authorized.DELETE("/delete/user", s.handleDeleteUser())
+	authorized.DELETE("/delete/user", s.RequireStepUp(), s.handleDeleteUser())
 	authorized.GET("/top/report/categories", s.handleGetTopCategories())
 	authorized.GET("/report/type/id", s.GetReportsByCategory())
 	authorized.GET("/get/user/balance", s.handleGetUserRewardBalance())
 	authorized.GET("reports/filters", s.handleGetReportsByFilters())
 	authorized.POST("posts/create", s.handleCreatePost())
 	authorized.GET("/all/posts/:userID", s.handleGetPostsByUserID())
+
+	admin := authorized.Group("/admin")
+	admin.Use(s.RequireAdmin())
+	admin.GET("/overview", s.handleGetAdminOverview())
+	admin.GET("/debug/vars", gin.WrapH(expvar.Handler()))
+	admin.GET("/analytics/signups", s.handleGetSignups())
+	admin.GET("/analytics/active-users", s.handleGetActiveUsers())
+	admin.GET("/analytics/moderators", s.handleGetModeratorPerformance())
+	admin.GET("/analytics/retention", s.handleGetRetention())
+	admin.GET("/media-reuse-flags", s.handleGetMediaReuseFlags())
+	admin.GET("/media/:id/original", s.handleGetMediaOriginal())
+	admin.GET("/reports/:id/evidence", s.handleExportEvidence())
+	admin.GET("/display-name-appeals", s.handleListDisplayNameAppeals())
+	admin.PUT("/display-name-appeals/:id", s.handleReviewDisplayNameAppeal())
+	admin.POST("/reports/:id/information-requests", s.handleRequestInformation())
+	admin.GET("/reports/:id/information-requests", s.handleListReportInformationRequests())
+	admin.PUT("/reports/:id/official-response", s.handleSetOfficialResponse())
+	admin.GET("/reports/:id/text", s.handleGetReportText())
+	admin.GET("/posts/:id/text", s.handleGetPostText())
+	admin.POST("/announcements/preview", s.handlePreviewAnnouncementAudience())
+	admin.POST("/announcements", s.handleCreateAnnouncement())
+	admin.GET("/announcements", s.handleListAnnouncements())
+	admin.GET("/announcements/:id/stats", s.handleGetAnnouncementStats())
+	admin.GET("/auto-close-rules", s.handleListAutoCloseRules())
+	admin.PUT("/auto-close-rules", s.handleSaveAutoCloseRule())
+	admin.DELETE("/auto-close-rules/:id", s.handleDeleteAutoCloseRule())
+	admin.GET("/calendar-events", s.handleListCalendarEvents())
+	admin.POST("/calendar-events", s.handleSaveCalendarEvent())
+	admin.PUT("/calendar-events/:id", s.handleSaveCalendarEvent())
+	admin.DELETE("/calendar-events/:id", s.handleDeleteCalendarEvent())
+	admin.GET("/submission-windows", s.handleListSubmissionWindows())
+	admin.POST("/submission-windows", s.handleCreateSubmissionWindow())
+	admin.DELETE("/submission-windows/:id", s.handleDeleteSubmissionWindow())
+	admin.GET("/report-caps/exemptions", s.handleListReportCapExemptions())
+	admin.POST("/report-caps/exemptions", s.handleCreateReportCapExemption())
+	admin.DELETE("/report-caps/exemptions/:userID", s.handleDeleteReportCapExemption())
+	admin.GET("/legal/documents", s.handleListLegalDocuments())
+	admin.POST("/legal/documents", s.handleCreateLegalDocument())
+	admin.POST("/legal/documents/:id/publish", s.handlePublishLegalDocument())
+	admin.GET("/feedback", s.handleListFeedback())
+	admin.GET("/feedback/:id", s.handleGetFeedback())
+	admin.PUT("/feedback/:id", s.handleTriageFeedback())
+	admin.GET("/help/articles", s.handleListHelpArticles())
+	admin.POST("/help/articles", s.handleCreateHelpArticle())
+	admin.PUT("/help/articles/:id", s.handleUpdateHelpArticle())
+	admin.DELETE("/help/articles/:id", s.handleDeleteHelpArticle())
+	admin.GET("/help/articles/:id/revisions", s.handleListHelpRevisions())
+	admin.POST("/help/articles/:id/revisions", s.handleAddHelpRevision())
+	admin.POST("/help/articles/:id/revisions/:revisionID/publish", s.handlePublishHelpRevision())
+	admin.GET("/payout-batches", s.handleListPayoutBatches())
+	admin.POST("/payout-batches", s.handleCreatePayoutBatch())
+	admin.GET("/payout-batches/:id/payouts", s.handleListBatchPayouts())
+	admin.POST("/incidents", s.handleCreateIncident())
+	admin.PUT("/incidents/:id", s.handleUpdateIncident())
+	admin.DELETE("/incidents/:id", s.handleDeleteIncident())
+	admin.POST("/incidents/:id/updates", s.handleAddIncidentUpdate())
+	admin.POST("/incidents/:id/reports", s.handleAttachIncidentReports())
+	admin.DELETE("/incidents/:id/reports/:reportID", s.handleDetachIncidentReport())
+	admin.GET("/warehouse/manifest", s.handleGetWarehouseManifest())
+	admin.POST("/agencies", s.handleCreateAgency())
+	admin.PUT("/users/:id/agency", s.handleSetAgencyMember())
+	admin.PUT("/users/:id/ambassador", s.handleSetAmbassador())
+	admin.GET("/ambassadors/summaries", s.handleListAmbassadorSummaries())
+	admin.PUT("/reports/:id/agency", s.handleAssignReportAgency())
+	admin.GET("/moderation/queue", s.handleGetTriageQueue())
+	admin.GET("/users/:id/reputation", s.handleGetReputation())
+	admin.DELETE("/users/:id/identity", s.handleRevokeIdentity())
+	admin.GET("/users/duplicates", s.handleListDuplicateAccounts())
+	admin.GET("/users/merges", s.handleListAccountMerges())
+	admin.POST("/users/merges", s.RequireStepUp(), s.handleMergeAccounts())
+	admin.GET("/report-audits", s.handleListReportAudits())
+	admin.PUT("/report-audits/:id", s.handleCompleteReportAudit())
+	admin.POST("/landmarks", s.handleCreateLandmark())
+	admin.POST("/roads/segments", s.handleCreateRoadSegment())
+	admin.GET("/data-shares", s.handleListDataShares())
+	admin.POST("/data-shares", s.handleCreateDataShare())
+	admin.DELETE("/data-shares/:id", s.handleRevokeDataShare())
+	admin.GET("/webhooks", s.handleListWebhooks())
+	admin.POST("/webhooks", s.handleCreateWebhook())
+	admin.DELETE("/webhooks/:id", s.handleDeleteWebhook())
+	admin.GET("/webhooks/deliveries", s.handleListWebhookDeliveries())
+	admin.POST("/webhooks/deliveries/:id/replay", s.handleReplayWebhookDelivery())
+	admin.GET("/short-links/:code", s.handleGetShortLinkStats())
+
+	partner := apirouter.Group("/partner")
+	partner.Use(s.RequirePartner())
+	partner.GET("/reports", s.handleGetPartnerReports())
+	partner.GET("/reports/export", s.handleExportPartnerReports())
 }
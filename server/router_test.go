@@ -0,0 +1,37 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/config"
+)
+
+func TestTrustProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	clientIP := func(proxies string) string {
+		s := &Server{Config: &config.Config{TrustedProxies: proxies}}
+		r := gin.New()
+		s.trustProxies(r)
+		var ip string
+		r.GET("/", func(c *gin.Context) { ip = c.ClientIP() })
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set("X-Forwarded-For", "203.0.113.7")
+		r.ServeHTTP(httptest.NewRecorder(), req)
+		return ip
+	}
+
+	// A forged X-Forwarded-For is ignored unless it comes from a trusted proxy
+	if ip := clientIP(""); ip != "10.0.0.1" {
+		t.Fatalf("got client IP %s with no trusted proxies, want 10.0.0.1", ip)
+	}
+	if ip := clientIP("10.0.0.0/8, 192.168.0.1"); ip != "203.0.113.7" {
+		t.Fatalf("got client IP %s behind a trusted proxy, want 203.0.113.7", ip)
+	}
+	if ip := clientIP("not-an-ip"); ip != "10.0.0.1" {
+		t.Fatalf("got client IP %s with invalid trusted proxies, want 10.0.0.1", ip)
+	}
+}
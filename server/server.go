@@ -16,23 +16,82 @@ import (
 )
 
 type Server struct {
-	Config                   *config.Config
-	AuthRepository           db.AuthRepository
-	AuthService              services.AuthService
-	Mail                     mailingservices.Mailer
-	MediaRepository          db.MediaRepository
-	MediaService             services.MediaService
-	IncidentReportService    services.IncidentReportService
-	IncidentReportRepository db.IncidentReportRepository
-	RewardService            services.RewardService
-	RewardRepository         db.RewardRepository
-	LikeService              services.LikeService
-	PostService              services.PostService
-	PostRepository           db.PostRepository
-	DB                       db.GormDB
+	Config                    *config.Config
+	AuthRepository            db.AuthRepository
+	AuthService               services.AuthService
+	Mail                      mailingservices.Mailer
+	MediaRepository           db.MediaRepository
+	MediaService              services.MediaService
+	IncidentReportService     services.IncidentReportService
+	IncidentReportRepository  db.IncidentReportRepository
+	RewardService             services.RewardService
+	RewardRepository          db.RewardRepository
+	LikeService               services.LikeService
+	PostService               services.PostService
+	PostRepository            db.PostRepository
+	SearchService             services.SearchService
+	MapService                services.MapService
+	AnalyticsService          services.AnalyticsService
+	LocationService           services.LocationService
+	AdminService              services.AdminService
+	GrowthService             services.GrowthService
+	ActivityService           services.ActivityService
+	EvidenceService           services.EvidenceService
+	ReportPrintService        services.ReportPrintService
+	UploadService             services.UploadService
+	NotificationService       services.NotificationService
+	ObjectService             services.ObjectService
+	AnnouncementService       services.AnnouncementService
+	AutoCloseService          services.AutoCloseService
+	SubmissionWindowService   services.SubmissionWindowService
+	IncidentGroupService      services.IncidentGroupService
+	WarehouseService          services.WarehouseService
+	ShortLinkService          services.ShortLinkService
+	LegalService              services.LegalService
+	FeedbackService           services.FeedbackService
+	IdentityService           services.IdentityService
+	RewardStatementService    services.RewardStatementService
+	PayoutService             services.PayoutService
+	AccountMergeService       services.AccountMergeService
+	ReportTransferService     services.ReportTransferService
+	PublicIDRepository        db.PublicIDRepository
+	ReportStatusService       services.ReportStatusService
+	ReportCapService          services.ReportCapService
+	ReportIngestService       services.ReportIngestService
+	DuplicateService          services.DuplicateService
+	ReportExportService       services.ReportExportService
+	WebhookService            services.WebhookService
+	CommentService            services.CommentService
+	RewardBonusService        services.RewardBonusService
+	StreakService             services.StreakService
+	CalendarService           services.CalendarService
+	DigestService             services.DigestService
+	HelpService               services.HelpService
+	DataShareService          services.DataShareService
+	AmbassadorService         services.AmbassadorService
+	AgencyService             services.AgencyService
+	ReputationService         services.ReputationService
+	AutoPublishService        services.AutoPublishService
+	LandmarkService           services.LandmarkService
+	RoadService               services.RoadService
+	IPLocationService         services.IPLocationService
+	UserStatsService          services.UserStatsService
+	InformationRequestService services.InformationRequestService
+	CollaboratorService       services.CollaboratorService
+	DisplayNameService        services.DisplayNameService
+	PhoneLoginService         services.PhoneLoginService
+	MagicLinkService          services.MagicLinkService
+	SessionService            services.SessionService
+	DB                        db.GormDB
 }
 
-// Server serves requests to DB with rout
+// Handler returns the server's routes without listening, for serving them
+// from tests
+func (s *Server) Handler() http.Handler {
+	return s.setupRouter()
+}
+
+// Start serves requests to DB with the server's routes until it is shut down
 func (s *Server) Start() {
 	r := s.setupRouter()
 	// TODO: user config.PORT here
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+)
+
+// handleRefreshSession swaps a refresh token for new tokens while the
+// session is live
+func (s *Server) handleRefreshSession() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request models.RefreshRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A refresh_token is required"})
+			return
+		}
+
+		login, err := s.SessionService.Refresh(request.RefreshToken)
+		if err != nil {
+			respondSessionError(c, err)
+			return
+		}
+		response.JSON(c, "Session renewed", http.StatusOK, login, nil)
+	}
+}
+
+// handleStepUp confirms the signed-in user's password, returning a token
+// that unlocks sensitive operations for a few minutes
+func (s *Server) handleStepUp() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request models.StepUpRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A password is required"})
+			return
+		}
+
+		stepUp, err := s.SessionService.StepUp(c.GetUint("userID"), c.GetString("session_id"), request.Password)
+		if err != nil {
+			respondSessionError(c, err)
+			return
+		}
+		response.JSON(c, "Confirmed", http.StatusOK, stepUp, nil)
+	}
+}
+
+// handleChangeEmail moves the signed-in user to a new email address and
+// signs their other sessions out
+func (s *Server) handleChangeEmail() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request models.ChangeEmailRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A valid email is required"})
+			return
+		}
+
+		userID := c.GetUint("userID")
+		if err := s.AuthService.ChangeEmail(userID, request.Email); err != nil {
+			respondSessionError(c, err)
+			return
+		}
+		if err := s.SessionService.EndOthers(userID, c.GetString("session_id")); err != nil {
+			response.JSON(c, "Email changed, but other sessions could not be signed out", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Email changed", http.StatusOK, nil, nil)
+	}
+}
+
+func respondSessionError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrSessionExpired), errors.Is(err, services.ErrStepUpWrongPassword):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrStepUpNoPassword):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error(), "reauthenticate": true})
+	case errors.Is(err, services.ErrEmailInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		response.JSON(c, "Unable to update session", http.StatusInternalServerError, nil, err)
+	}
+}
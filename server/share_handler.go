@@ -0,0 +1,61 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/techagentng/citizenx/geo"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+)
+
+// handleShareReport returns the link and message the app shares a report with
+func (s *Server) handleShareReport() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reportID := c.Param("id")
+		if _, err := uuid.Parse(reportID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid report ID"})
+			return
+		}
+
+		share, err := s.IncidentReportService.ShareReport(reportID)
+		if errors.Is(err, services.ErrReportNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			response.JSON(c, "Failed to share report", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Report share link created", http.StatusOK, share, nil)
+	}
+}
+
+// plusCodeQuery reads the plus_code search parameter as a code prefix. A
+// short code such as G9FH+QM needs near=lat,lng, a point in the same town,
+// to recover the full code.
+func plusCodeQuery(c *gin.Context) (string, error) {
+	code := strings.TrimSpace(c.Query("plus_code"))
+	if code == "" {
+		return "", nil
+	}
+	if !geo.IsFullPlusCode(code) {
+		near := strings.Split(c.Query("near"), ",")
+		if len(near) != 2 {
+			return "", errors.New("short plus codes need near=lat,lng")
+		}
+		point, err := geo.ParseCoordinates(near[0], near[1])
+		if err != nil {
+			return "", errors.New("near must be lat,lng")
+		}
+		full, err := geo.RecoverPlusCode(code, point.Latitude, point.Longitude)
+		if err != nil {
+			return "", err
+		}
+		code = full
+	}
+	return geo.PlusCodePrefix(code, geo.PlusCodeLength)
+}
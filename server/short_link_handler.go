@@ -0,0 +1,48 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+)
+
+// handleFollowShortLink redirects a short link to where it leads, counting
+// the click
+func (s *Server) handleFollowShortLink() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		target, err := s.ShortLinkService.Follow(c.Param("code"), c.GetHeader("Referer"))
+		switch {
+		case errors.Is(err, services.ErrShortLinkNotFound):
+			c.String(http.StatusNotFound, err.Error())
+			return
+		case errors.Is(err, services.ErrShortLinkExpired):
+			c.String(http.StatusGone, err.Error())
+			return
+		case err != nil:
+			c.String(http.StatusInternalServerError, "Unable to follow link")
+			return
+		}
+		// Not cached, so every click is counted
+		c.Header("Cache-Control", "no-store")
+		c.Redirect(http.StatusFound, target)
+	}
+}
+
+// handleGetShortLinkStats serves a short link's clicks per day
+func (s *Server) handleGetShortLinkStats() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		stats, err := s.ShortLinkService.Stats(c.Param("code"))
+		if errors.Is(err, services.ErrShortLinkNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			response.JSON(c, "Failed to load link stats", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Link stats retrieved", http.StatusOK, stats, nil)
+	}
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+)
+
+// respondCategoryClosed answers when err rejects a report made outside its
+// category's submission windows, reporting whether it did
+func respondCategoryClosed(c *gin.Context, err error) bool {
+	if !errors.Is(err, services.ErrCategoryClosed) {
+		return false
+	}
+	c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	return true
+}
+
+func (s *Server) handleListSubmissionWindows() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		windows, err := s.SubmissionWindowService.ListWindows()
+		if err != nil {
+			response.JSON(c, "Failed to load submission windows", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Submission windows retrieved", http.StatusOK, windows, nil)
+	}
+}
+
+// handleCreateSubmissionWindow opens a category for a period, e.g.
+// {"campaign": "Governorship election", "category": "Election", "opens_at":
+// 1767250800, "closes_at": 1767294000}. From then on the category accepts
+// reports only inside its windows.
+func (s *Server) handleCreateSubmissionWindow() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var window models.SubmissionWindow
+		if err := c.ShouldBindJSON(&window); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A category, opens_at and closes_at are required"})
+			return
+		}
+		err := s.SubmissionWindowService.CreateWindow(&window, c.GetUint("userID"))
+		if errors.Is(err, services.ErrInvalidSubmissionWindow) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			response.JSON(c, "Failed to save submission window", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Submission window saved", http.StatusCreated, window, nil)
+	}
+}
+
+func (s *Server) handleDeleteSubmissionWindow() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid window ID"})
+			return
+		}
+		err = s.SubmissionWindowService.DeleteWindow(uint(id))
+		if errors.Is(err, services.ErrSubmissionWindowNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			response.JSON(c, "Failed to delete submission window", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Submission window deleted", http.StatusOK, nil, nil)
+	}
+}
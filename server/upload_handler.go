@@ -0,0 +1,218 @@
+package server
+
+import (
+	"encoding/base64"
+	"errors"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+	"github.com/techagentng/citizenx/storage"
+)
+
+// Resumable uploads follow the tus 1.0.0 protocol (https://tus.io), so
+// mobile clients can use an off the shelf tus library
+const (
+	tusVersion    = "1.0.0"
+	tusExtensions = "creation,termination"
+)
+
+// requireTus answers OPTIONS discovery requests and rejects requests for a
+// protocol version the server does not speak
+func requireTus() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Tus-Resumable", tusVersion)
+		if c.Request.Method == http.MethodOptions {
+			return
+		}
+		if c.GetHeader("Tus-Resumable") != tusVersion {
+			c.Header("Tus-Version", tusVersion)
+			c.AbortWithStatusJSON(http.StatusPreconditionFailed, gin.H{"error": "Unsupported tus version"})
+			return
+		}
+		c.Next()
+	}
+}
+
+func (s *Server) handleUploadOptions() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Tus-Version", tusVersion)
+		c.Header("Tus-Extension", tusExtensions)
+		c.Header("Tus-Max-Size", strconv.FormatInt(s.UploadService.MaxSize(), 10))
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// handleCreateUpload starts an upload. The draft the media belongs to is
+// named in the Upload-Metadata header as draft_id, alongside the optional
+// filename and filetype.
+func (s *Server) handleCreateUpload() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		length, err := strconv.ParseInt(c.GetHeader("Upload-Length"), 10, 64)
+		if err != nil || length <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Upload-Length"})
+			return
+		}
+		metadata := parseUploadMetadata(c.GetHeader("Upload-Metadata"))
+		if _, err := uuid.Parse(metadata["draft_id"]); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Upload-Metadata must name a valid draft_id"})
+			return
+		}
+
+		upload, err := s.UploadService.CreateUpload(c.GetUint("userID"), metadata["draft_id"], length, metadata["filename"], metadata["filetype"])
+		if err != nil {
+			respondUploadError(c, err)
+			return
+		}
+		c.Header("Location", strings.TrimSuffix(c.Request.URL.Path, "/")+"/"+upload.ID.String())
+		c.Header("Upload-Offset", "0")
+		c.Status(http.StatusCreated)
+	}
+}
+
+func (s *Server) handleUploadStatus() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		upload, err := s.UploadService.GetUpload(c.Param("id"), c.GetUint("userID"))
+		if err != nil {
+			respondUploadError(c, err)
+			return
+		}
+		c.Header("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+		c.Header("Upload-Length", strconv.FormatInt(upload.Length, 10))
+		c.Header("Cache-Control", "no-store")
+		c.Status(http.StatusOK)
+	}
+}
+
+func (s *Server) handlePatchUpload() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.ContentType() != "application/offset+octet-stream" {
+			c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": "Content-Type must be application/offset+octet-stream"})
+			return
+		}
+		offset, err := strconv.ParseInt(c.GetHeader("Upload-Offset"), 10, 64)
+		if err != nil || offset < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Upload-Offset"})
+			return
+		}
+		upload, err := s.UploadService.GetUpload(c.Param("id"), c.GetUint("userID"))
+		if err != nil {
+			respondUploadError(c, err)
+			return
+		}
+
+		err = s.UploadService.WriteChunk(upload, offset, c.Request.Body)
+		c.Header("Upload-Offset", strconv.FormatInt(upload.Offset, 10))
+		if err != nil {
+			respondUploadError(c, err)
+			return
+		}
+		if upload.MediaID != "" {
+			c.Header("Upload-Media-Id", upload.MediaID)
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+func (s *Server) handleDeleteUpload() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		upload, err := s.UploadService.GetUpload(c.Param("id"), c.GetUint("userID"))
+		if err != nil {
+			respondUploadError(c, err)
+			return
+		}
+		if err := s.UploadService.DeleteUpload(upload); err != nil {
+			respondUploadError(c, err)
+			return
+		}
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// parseUploadMetadata decodes the tus Upload-Metadata header, a comma
+// separated list of keys each followed by a base64 value
+func parseUploadMetadata(header string) map[string]string {
+	metadata := map[string]string{}
+	for _, pair := range strings.Split(header, ",") {
+		parts := strings.Fields(pair)
+		if len(parts) == 0 {
+			continue
+		}
+		value := ""
+		if len(parts) > 1 {
+			decoded, err := base64.StdEncoding.DecodeString(parts[1])
+			if err != nil {
+				continue
+			}
+			value = string(decoded)
+		}
+		metadata[parts[0]] = value
+	}
+	return metadata
+}
+
+func respondUploadError(c *gin.Context, err error) {
+	if respondValidationError(c, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, services.ErrUploadNotFound), errors.Is(err, services.ErrDraftNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrUploadOffsetMismatch), errors.Is(err, services.ErrUploadComplete):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrUploadTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Unable to store upload", "details": err.Error()})
+	}
+}
+
+// respondValidationError answers 422 with the structured reason when err is
+// a rejected upload, and reports whether it did
+func respondValidationError(c *gin.Context, err error) bool {
+	var invalid *storage.ValidationError
+	if !errors.As(err, &invalid) {
+		return false
+	}
+	c.JSON(http.StatusUnprocessableEntity, gin.H{"error": invalid.Error(), "validation": invalid})
+	return true
+}
+
+// processReportMedia stores media uploaded for a report, answering the
+// request itself and reporting false when they cannot be
+func (s *Server) processReportMedia(c *gin.Context, files []*multipart.FileHeader, userID uint, reportID string) ([]models.Media, bool) {
+	feedURLs, thumbnailURLs, fullsizeURLs, fileTypes, fingerprints, err := s.MediaService.ProcessMedia(c, files, userID, reportID)
+	if err != nil {
+		log.Printf("Error processing media for report %s: %v", reportID, err)
+		if !respondValidationError(c, err) {
+			response.JSON(c, "Unable to process media files", http.StatusInternalServerError, nil, err)
+		}
+		return nil, false
+	}
+	media := make([]models.Media, 0, len(feedURLs))
+	for i := range feedURLs {
+		m := models.Media{
+			FeedURL:     feedURLs[i],
+			FileType:    fileTypes[i],
+			SHA256:      fingerprints[i].SHA256,
+			PHash:       fingerprints[i].PHash,
+			OriginalKey: fingerprints[i].OriginalKey,
+			BlurredURL:  fingerprints[i].BlurredURL,
+		}
+		if i < len(thumbnailURLs) {
+			m.ThumbnailURL = thumbnailURLs[i]
+		}
+		if i < len(fullsizeURLs) {
+			m.FullSizeURL = fullsizeURLs[i]
+		}
+		media = append(media, m)
+	}
+	return media, true
+}
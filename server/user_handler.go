@@ -1 +1,81 @@
 package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+)
+
+// handleGetMyStats returns the signed-in user's reporting statistics for
+// the profile screen
+func (s *Server) handleGetMyStats() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := c.MustGet("user").(*models.User)
+		if !ok {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user type"})
+			return
+		}
+		stats, err := s.UserStatsService.Stats(user)
+		if err != nil {
+			response.JSON(c, "Failed to load statistics", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Statistics retrieved", http.StatusOK, stats, nil)
+	}
+}
+
+// handleSetDailyGoal sets how many reports a day keep the signed-in user's
+// streak going
+func (s *Server) handleSetDailyGoal() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			Reports int `json:"reports" binding:"required"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+		progress, err := s.StreakService.SetDailyGoal(c.GetUint("userID"), body.Reports)
+		if errors.Is(err, services.ErrInvalidDailyGoal) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			response.JSON(c, "Failed to set daily goal", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Daily goal set", http.StatusOK, progress, nil)
+	}
+}
+
+// handleDeactivateAccount puts the signed-in user's account to sleep and
+// signs it out everywhere. Signing in again reactivates it.
+func (s *Server) handleDeactivateAccount() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := s.AuthService.DeactivateAccount(c.GetUint("userID")); err != nil {
+			response.JSON(c, "Unable to deactivate account", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Account deactivated. Sign in again to reactivate it", http.StatusOK, nil, nil)
+	}
+}
+
+// respondAgeRejected answers when err comes from the age policy, reporting
+// whether it did
+func respondAgeRejected(c *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, services.ErrInvalidDateOfBirth):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrTooYoung), errors.Is(err, services.ErrCategoryRestricted):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrDateOfBirthSet):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/locale"
+	"github.com/techagentng/citizenx/serializers"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+)
+
+// handleListMyReports lists the signed-in user's reports, filtered by
+// ?status=, ?category= and a ?from= and ?to= date (YYYY-MM-DD, inclusive),
+// with their counts by status
+func (s *Server) handleListMyReports() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, ok := incidentPage(c)
+		if !ok {
+			return
+		}
+		filter := db.UserReportFilter{
+			Status:   strings.TrimSpace(c.Query("status")),
+			Category: strings.TrimSpace(c.Query("category")),
+		}
+		for _, bound := range []struct {
+			param string
+			into  *int64
+			days  int
+		}{{"from", &filter.From, 0}, {"to", &filter.To, 1}} {
+			value := c.Query(bound.param)
+			if value == "" {
+				continue
+			}
+			day, err := time.ParseInLocation("2006-01-02", value, locale.TimeZone)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Dates must be given as YYYY-MM-DD"})
+				return
+			}
+			*bound.into = day.AddDate(0, 0, bound.days).Unix()
+		}
+
+		reports, err := s.IncidentReportService.ListUserReports(c.GetUint("userID"), filter, page)
+		if err != nil {
+			response.JSON(c, "Failed to load your reports", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Reports retrieved", http.StatusOK, gin.H{
+			"reports": serializers.OwnerReports(reports.Reports),
+			"counts":  reports.Counts,
+			"page":    reports.Page,
+		}, nil)
+	}
+}
+
+// handleWithdrawReport retracts one of the signed-in user's pending reports
+func (s *Server) handleWithdrawReport() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body struct {
+			Reason string `json:"reason"`
+		}
+		if err := c.ShouldBindJSON(&body); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+			return
+		}
+
+		err := s.IncidentReportService.WithdrawReport(c.GetUint("userID"), c.Param("id"), body.Reason)
+		switch {
+		case errors.Is(err, services.ErrWithdrawReasonRequired):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrReportNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrNotReportOwner):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrReportNotPending):
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		case err != nil:
+			response.JSON(c, "Failed to withdraw report", http.StatusInternalServerError, nil, err)
+		default:
+			response.JSON(c, "Report withdrawn", http.StatusOK, nil, nil)
+		}
+	}
+}
@@ -0,0 +1,21 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/server/response"
+)
+
+// handleGetWarehouseManifest lists the exported datasets with their schema
+// and partitions, for configuring Athena or BigQuery external tables
+func (s *Server) handleGetWarehouseManifest() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		manifest, err := s.WarehouseService.Manifest()
+		if err != nil {
+			response.JSON(c, "Failed to load warehouse manifest", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Warehouse manifest retrieved", http.StatusOK, manifest, nil)
+	}
+}
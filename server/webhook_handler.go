@@ -0,0 +1,127 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/server/response"
+	"github.com/techagentng/citizenx/services"
+)
+
+func (s *Server) handleListWebhooks() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		subscriptions, err := s.WebhookService.ListSubscriptions()
+		if err != nil {
+			response.JSON(c, "Failed to load webhooks", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Webhooks retrieved", http.StatusOK, gin.H{
+			"webhooks": subscriptions,
+			"events":   services.WebhookEvents,
+		}, nil)
+	}
+}
+
+// handleCreateWebhook subscribes a partner's URL to report events, e.g.
+// {"name": "Lagos Roads Trust", "url": "https://example.org/hooks",
+// "events": ["report.created", "report.deleted"]}. Leaving out events
+// subscribes to all of them. The signing secret in the response is shown
+// only once.
+func (s *Server) handleCreateWebhook() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request models.WebhookSubscriptionRequest
+		if err := c.ShouldBindJSON(&request); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "A name and a URL are required"})
+			return
+		}
+		subscription, secret, err := s.WebhookService.CreateSubscription(&request, c.GetUint("userID"))
+		if errors.Is(err, services.ErrInvalidWebhook) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			response.JSON(c, "Failed to create webhook", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Webhook created", http.StatusCreated, gin.H{
+			"webhook": subscription,
+			"secret":  secret,
+		}, nil)
+	}
+}
+
+func (s *Server) handleDeleteWebhook() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+			return
+		}
+		err = s.WebhookService.DeleteSubscription(uint(id))
+		if errors.Is(err, services.ErrWebhookNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			response.JSON(c, "Failed to delete webhook", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Webhook deleted", http.StatusOK, nil, nil)
+	}
+}
+
+// handleListWebhookDeliveries pages through the delivery log, newest first.
+// status=failed lists the deliveries that can be replayed; webhook_id
+// narrows it to one subscriber.
+func (s *Server) handleListWebhookDeliveries() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		page, err := strconv.Atoi(c.DefaultQuery("page", "1"))
+		if err != nil || page < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid page number"})
+			return
+		}
+		status := c.Query("status")
+		if status != "" && status != models.WebhookPending && status != models.WebhookDelivered && status != models.WebhookFailed {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "status must be pending, delivered or failed"})
+			return
+		}
+		var subscriptionID uint64
+		if raw := c.Query("webhook_id"); raw != "" {
+			if subscriptionID, err = strconv.ParseUint(raw, 10, 64); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid webhook ID"})
+				return
+			}
+		}
+		deliveries, err := s.WebhookService.ListDeliveries(status, uint(subscriptionID), page)
+		if err != nil {
+			response.JSON(c, "Failed to load webhook deliveries", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Webhook deliveries retrieved", http.StatusOK, deliveries, nil)
+	}
+}
+
+// handleReplayWebhookDelivery sends a failed delivery again, with a fresh
+// set of attempts
+func (s *Server) handleReplayWebhookDelivery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid delivery ID"})
+			return
+		}
+		err = s.WebhookService.ReplayDelivery(uint(id))
+		if errors.Is(err, services.ErrWebhookDeliveryNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			response.JSON(c, "Failed to replay webhook delivery", http.StatusInternalServerError, nil, err)
+			return
+		}
+		response.JSON(c, "Webhook delivery queued", http.StatusAccepted, nil, nil)
+	}
+}
@@ -0,0 +1,68 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/techagentng/citizenx/mocks"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/services"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateWebhook(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	webhooks := mocks.NewMockWebhookService(ctrl)
+	s := &Server{WebhookService: webhooks}
+	r := testRouter(http.MethodPost, "/webhooks", models.RoleAdmin, s.handleCreateWebhook())
+
+	if w := serve(t, r, http.MethodPost, "/webhooks", strings.NewReader(`{"name": "Roads Trust"}`)); w.Code != http.StatusBadRequest {
+		t.Fatalf("got status %d without a URL, want 400", w.Code)
+	}
+
+	request := &models.WebhookSubscriptionRequest{Name: "Roads Trust", URL: "https://example.org/hooks", Events: []string{"report.created"}}
+	webhooks.EXPECT().CreateSubscription(request, testUser.ID).Return(&models.WebhookSubscription{ID: 3, Name: request.Name, URL: request.URL}, "whsec_test", nil)
+	w := serve(t, r, http.MethodPost, "/webhooks", strings.NewReader(`{"name": "Roads Trust", "url": "https://example.org/hooks", "events": ["report.created"]}`))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("got status %d, want 201: %s", w.Code, w.Body)
+	}
+	var body struct {
+		Data struct {
+			Secret string `json:"secret"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decoding response: %v", err)
+	}
+	if body.Data.Secret != "whsec_test" {
+		t.Fatalf("got secret %q, want the one the service created", body.Data.Secret)
+	}
+
+	webhooks.EXPECT().CreateSubscription(gomock.Any(), testUser.ID).Return(nil, "", services.ErrInvalidWebhook)
+	if w := serve(t, r, http.MethodPost, "/webhooks", strings.NewReader(`{"name": "Roads Trust", "url": "ftp://example.org/hooks"}`)); w.Code != http.StatusBadRequest {
+		t.Fatalf("got status %d for an invalid webhook, want 400", w.Code)
+	}
+}
+
+func TestReplayWebhookDelivery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	webhooks := mocks.NewMockWebhookService(ctrl)
+	s := &Server{WebhookService: webhooks}
+	r := testRouter(http.MethodPost, "/webhooks/deliveries/:id/replay", models.RoleAdmin, s.handleReplayWebhookDelivery())
+
+	if w := serve(t, r, http.MethodPost, "/webhooks/deliveries/abc/replay", nil); w.Code != http.StatusBadRequest {
+		t.Fatalf("got status %d for a malformed ID, want 400", w.Code)
+	}
+
+	webhooks.EXPECT().ReplayDelivery(uint(12)).Return(nil)
+	if w := serve(t, r, http.MethodPost, "/webhooks/deliveries/12/replay", nil); w.Code != http.StatusAccepted {
+		t.Fatalf("got status %d, want 202: %s", w.Code, w.Body)
+	}
+
+	webhooks.EXPECT().ReplayDelivery(uint(13)).Return(services.ErrWebhookDeliveryNotFound)
+	if w := serve(t, r, http.MethodPost, "/webhooks/deliveries/13/replay", nil); w.Code != http.StatusNotFound {
+		t.Fatalf("got status %d for a delivery that has not failed, want 404", w.Code)
+	}
+}
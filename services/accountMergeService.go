@@ -0,0 +1,155 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/techagentng/citizenx/cache"
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrMergeSameAccount is returned when merging an account into itself.
+	ErrMergeSameAccount = errors.New("an account cannot be merged into itself")
+	// ErrMergeAccountNotFound is returned when either account of a merge
+	// does not exist or is already closed.
+	ErrMergeAccountNotFound = errors.New("both accounts must exist and be active")
+)
+
+// DuplicatesCacheTTL is how long the duplicate scan, which reads every
+// account, is reused
+const DuplicatesCacheTTL = 10 * time.Minute
+
+// duplicateScanBatch is how many accounts the duplicate scan reads at once
+const duplicateScanBatch = 1000
+
+// placeholderMAC is reported by Android devices that hide their real MAC
+// address, so it says nothing about the device
+const placeholderMAC = "020000000000"
+
+// AccountMergeService finds accounts likely to belong to the same person and
+// lets admins fold them into one
+type AccountMergeService interface {
+	Duplicates() ([]models.DuplicateGroup, error)
+	Merge(request *models.AccountMergeRequest, adminID uint) (*models.AccountMerge, error)
+	Merges(page int) ([]models.AccountMerge, error)
+}
+
+type accountMergeService struct {
+	Config           *config.Config
+	accountMergeRepo db.AccountMergeRepository
+	duplicates       *cache.TTL[[]models.DuplicateGroup]
+}
+
+// NewAccountMergeService creates a new instance of AccountMergeService
+func NewAccountMergeService(accountMergeRepo db.AccountMergeRepository, conf *config.Config) AccountMergeService {
+	return &accountMergeService{
+		Config:           conf,
+		accountMergeRepo: accountMergeRepo,
+		duplicates:       cache.New[[]models.DuplicateGroup](DuplicatesCacheTTL),
+	}
+}
+
+// Duplicates groups active accounts sharing a phone number, however it was
+// written, a device's MAC address, or an email address once plus tags and,
+// for Gmail, dots are taken away
+func (s *accountMergeService) Duplicates() ([]models.DuplicateGroup, error) {
+	return s.duplicates.GetOrLoad("", s.scanDuplicates)
+}
+
+func (s *accountMergeService) scanDuplicates() ([]models.DuplicateGroup, error) {
+	type key struct{ signal, value string }
+	found := map[key][]models.DuplicateUser{}
+	var afterID uint
+	for {
+		users, err := s.accountMergeRepo.UserContacts(afterID, duplicateScanBatch)
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range users {
+			summary := models.DuplicateUser{
+				ID:        user.ID,
+				Fullname:  user.Fullname,
+				Username:  user.Username,
+				Email:     user.Email,
+				CreatedAt: user.CreatedAt,
+			}
+			if phone := strings.TrimSpace(user.Telephone); phone != "" {
+				if normalized, err := NormalizePhone(phone); err == nil {
+					phone = normalized
+				}
+				found[key{models.DuplicateByTelephone, phone}] = append(found[key{models.DuplicateByTelephone, phone}], summary)
+			}
+			if mac := normalizeMAC(user.MacAddress); mac != "" && mac != placeholderMAC {
+				found[key{models.DuplicateByDevice, mac}] = append(found[key{models.DuplicateByDevice, mac}], summary)
+			}
+			if email := canonicalEmail(user.Email); email != "" {
+				found[key{models.DuplicateByEmail, email}] = append(found[key{models.DuplicateByEmail, email}], summary)
+			}
+		}
+		if len(users) < duplicateScanBatch {
+			break
+		}
+		afterID = users[len(users)-1].ID
+	}
+
+	groups := []models.DuplicateGroup{}
+	for k, users := range found {
+		if len(users) > 1 {
+			groups = append(groups, models.DuplicateGroup{Signal: k.signal, Users: users})
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Users[0].ID != groups[j].Users[0].ID {
+			return groups[i].Users[0].ID < groups[j].Users[0].ID
+		}
+		return groups[i].Signal < groups[j].Signal
+	})
+	return groups, nil
+}
+
+// normalizeMAC writes a MAC address as bare upper-case hex digits
+func normalizeMAC(mac string) string {
+	return strings.ToUpper(strings.NewReplacer(":", "", "-", "", ".", "", " ", "").Replace(mac))
+}
+
+// canonicalEmail lower-cases an email address and drops what mail providers
+// ignore: the +tag of the local part and, for Gmail, its dots
+func canonicalEmail(email string) string {
+	local, domain, ok := strings.Cut(strings.ToLower(strings.TrimSpace(email)), "@")
+	if !ok || local == "" || domain == "" {
+		return ""
+	}
+	local, _, _ = strings.Cut(local, "+")
+	if domain == "gmail.com" || domain == "googlemail.com" {
+		domain = "gmail.com"
+		local = strings.ReplaceAll(local, ".", "")
+	}
+	return local + "@" + domain
+}
+
+// Merge folds the source account into the target, which keeps its own
+// profile and sign-in details
+func (s *accountMergeService) Merge(request *models.AccountMergeRequest, adminID uint) (*models.AccountMerge, error) {
+	if request.SourceID == request.TargetID {
+		return nil, ErrMergeSameAccount
+	}
+	merge, err := s.accountMergeRepo.MergeAccounts(request.SourceID, request.TargetID, adminID, time.Now().Unix())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrMergeAccountNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.duplicates.Clear()
+	return merge, nil
+}
+
+func (s *accountMergeService) Merges(page int) ([]models.AccountMerge, error) {
+	return s.accountMergeRepo.ListMerges(page)
+}
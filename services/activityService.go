@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/events"
+	"github.com/techagentng/citizenx/jobs"
+	"github.com/techagentng/citizenx/models"
+)
+
+// RecordActivityJob is the job type that writes an activity event.
+const RecordActivityJob = "activity.record"
+
+// activityJob is the payload of a RecordActivityJob.
+type activityJob struct {
+	UserID     uint                   `json:"user_id"`
+	EventType  string                 `json:"event_type"`
+	Metadata   map[string]interface{} `json:"metadata"`
+	OccurredAt time.Time              `json:"occurred_at"`
+	DedupKey   *string                `json:"dedup_key,omitempty"`
+}
+
+// ActivityService tracks key user actions for engagement analytics
+type ActivityService interface {
+	Track(userID uint, eventType string, metadata map[string]interface{}) error
+	Subscribe(bus events.Bus)
+	RegisterJobs(worker *jobs.Worker)
+}
+
+type activityService struct {
+	Config       *config.Config
+	activityRepo db.ActivityRepository
+	jobRepo      db.JobRepository
+}
+
+// NewActivityService creates a new instance of ActivityService
+func NewActivityService(activityRepo db.ActivityRepository, jobRepo db.JobRepository, conf *config.Config) ActivityService {
+	return &activityService{
+		Config:       conf,
+		activityRepo: activityRepo,
+		jobRepo:      jobRepo,
+	}
+}
+
+// Track queues an activity event; the job worker writes it, keeping the
+// write off the request path
+func (s *activityService) Track(userID uint, eventType string, metadata map[string]interface{}) error {
+	return s.enqueue(userID, eventType, metadata, nil)
+}
+
+func (s *activityService) enqueue(userID uint, eventType string, metadata map[string]interface{}, dedupKey *string) error {
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	return s.jobRepo.Enqueue(RecordActivityJob, activityJob{
+		UserID:     userID,
+		EventType:  eventType,
+		Metadata:   metadata,
+		OccurredAt: time.Now(),
+		DedupKey:   dedupKey,
+	})
+}
+
+// Subscribe tracks the actions already published as domain events
+func (s *activityService) Subscribe(bus events.Bus) {
+	bus.Subscribe(events.ReportCreatedEvent, s.handleEvent)
+	bus.Subscribe(events.CommentAddedEvent, s.handleEvent)
+}
+
+func (s *activityService) handleEvent(ctx context.Context, event events.Event) error {
+	key := event.DedupKey()
+	switch e := event.(type) {
+	case events.ReportCreated:
+		return s.enqueue(e.UserID, models.ActivityReportSubmitted, map[string]interface{}{
+			"report_id": e.ReportID,
+			"category":  e.Category,
+		}, &key)
+	case events.CommentAdded:
+		return s.enqueue(e.UserID, models.ActivityComment, map[string]interface{}{
+			"report_id":  e.ReportID,
+			"comment_id": e.CommentID,
+		}, &key)
+	}
+	return nil
+}
+
+// RegisterJobs installs the activity job handler on worker
+func (s *activityService) RegisterJobs(worker *jobs.Worker) {
+	worker.Handle(RecordActivityJob, s.record)
+}
+
+func (s *activityService) record(ctx context.Context, payload []byte) error {
+	var job activityJob
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return err
+	}
+	metadata, err := json.Marshal(job.Metadata)
+	if err != nil {
+		return err
+	}
+	return s.activityRepo.RecordActivity(&models.ActivityEvent{
+		UserID:     job.UserID,
+		EventType:  job.EventType,
+		Metadata:   string(metadata),
+		OccurredAt: job.OccurredAt,
+		DedupKey:   job.DedupKey,
+	})
+}
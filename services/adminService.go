@@ -0,0 +1,58 @@
+package services
+
+import (
+	"time"
+
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/models"
+)
+
+// AdminService serves the admin dashboard
+type AdminService interface {
+	Overview() (*models.AdminOverview, error)
+	ModeratorPerformance(start, end time.Time) ([]models.ModeratorPerformance, error)
+}
+
+type adminService struct {
+	Config         *config.Config
+	adminRepo      db.AdminRepository
+	moderationRepo db.ModerationRepository
+}
+
+// NewAdminService creates a new instance of AdminService
+func NewAdminService(adminRepo db.AdminRepository, moderationRepo db.ModerationRepository, conf *config.Config) AdminService {
+	return &adminService{
+		Config:         conf,
+		adminRepo:      adminRepo,
+		moderationRepo: moderationRepo,
+	}
+}
+
+// Overview returns today's operational summary, counting from local midnight
+func (s *adminService) Overview() (*models.AdminOverview, error) {
+	now := time.Now()
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	return s.adminRepo.GetOverview(midnight)
+}
+
+// ModeratorPerformance measures each moderator's decisions between start
+// and end, with throughput as decisions per day of the window
+func (s *adminService) ModeratorPerformance(start, end time.Time) ([]models.ModeratorPerformance, error) {
+	performance, err := s.moderationRepo.ModeratorPerformance(start, end)
+	if err != nil {
+		return nil, err
+	}
+	days := end.Sub(start).Hours() / 24
+	if days < 1 {
+		days = 1
+	}
+	for i := range performance {
+		p := &performance[i]
+		p.PerDay = float64(p.Decisions) / days
+		if p.Decisions > 0 {
+			p.OverturnRate = float64(p.Overturned) / float64(p.Decisions)
+		}
+	}
+	return performance, nil
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/techagentng/citizenx/config"
+)
+
+var (
+	// ErrInvalidDateOfBirth is returned for dates of birth that are missing,
+	// malformed or in the future.
+	ErrInvalidDateOfBirth = errors.New("date_of_birth must be a past date written YYYY-MM-DD")
+	// ErrTooYoung is returned when signing up below the minimum age.
+	ErrTooYoung = errors.New("you are not old enough to use CitizenX")
+	// ErrDateOfBirthSet is returned when changing a date of birth already
+	// given.
+	ErrDateOfBirthSet = errors.New("your date of birth cannot be changed")
+	// ErrCategoryRestricted is returned when a user in restricted mode
+	// reports in a category whose media is often graphic.
+	ErrCategoryRestricted = errors.New("this category is not available until you are older")
+)
+
+// AgePolicy keeps children off the service and puts younger users in a
+// restricted mode: their profile is never shown publicly, their reports are
+// anonymous and they cannot report in categories whose media is often
+// graphic.
+type AgePolicy struct {
+	minimumAge int
+	adultAge   int
+	graphic    map[string]bool
+}
+
+// NewAgePolicy builds the policy from minimum_age, adult_age and
+// graphic_categories
+func NewAgePolicy(conf *config.Config) *AgePolicy {
+	p := &AgePolicy{minimumAge: conf.MinimumAge, adultAge: conf.AdultAge, graphic: map[string]bool{}}
+	for _, category := range strings.Split(conf.GraphicCategories, ",") {
+		if category = strings.TrimSpace(category); category != "" {
+			p.graphic[strings.ToLower(category)] = true
+		}
+	}
+	return p
+}
+
+// Check validates a date of birth written YYYY-MM-DD, returning it and when
+// the user leaves restricted mode, which has passed for adults
+func (p *AgePolicy) Check(dateOfBirth string, now time.Time) (time.Time, int64, error) {
+	born, err := time.Parse("2006-01-02", strings.TrimSpace(dateOfBirth))
+	if err != nil || born.After(now) || born.Year() < now.Year()-130 {
+		return time.Time{}, 0, ErrInvalidDateOfBirth
+	}
+	if born.AddDate(p.minimumAge, 0, 0).After(now) {
+		return time.Time{}, 0, fmt.Errorf("%w: the minimum age is %d", ErrTooYoung, p.minimumAge)
+	}
+	return born, born.AddDate(p.adultAge, 0, 0).Unix(), nil
+}
+
+// Graphic reports whether media in category is often graphic
+func (p *AgePolicy) Graphic(category string) bool {
+	return p.graphic[strings.ToLower(strings.TrimSpace(category))]
+}
+
+// AllowCategory returns ErrCategoryRestricted when a user restricted until
+// restrictedUntil may not yet report in category
+func (p *AgePolicy) AllowCategory(restrictedUntil int64, category string, now time.Time) error {
+	if restrictedUntil > now.Unix() && p.Graphic(category) {
+		return ErrCategoryRestricted
+	}
+	return nil
+}
@@ -0,0 +1,372 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techagentng/citizenx/cache"
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/policy"
+	"gorm.io/gorm"
+)
+
+// ScorecardWindowDays is the period a current scorecard covers: reports
+// assigned in the last 90 days
+const ScorecardWindowDays = 90
+
+// ScorecardCacheTTL is how long current scorecards are served from memory
+const ScorecardCacheTTL = 10 * time.Minute
+
+// MaxStatusUpdateReports bounds the reports one bulk status update may
+// cover, and MaxStatusUpdateEvidence the evidence links it may carry
+const (
+	MaxStatusUpdateReports  = 200
+	MaxStatusUpdateEvidence = 10
+)
+
+var (
+	// ErrAgencyNotFound is returned for agencies that do not exist.
+	ErrAgencyNotFound = errors.New("agency not found")
+	// ErrNotAgencyReport is returned when a user acts on a report that is
+	// not assigned to their agency.
+	ErrNotAgencyReport = errors.New("report is not assigned to your agency")
+	// ErrReportAlreadyResolved is returned when resolving a resolved report.
+	ErrReportAlreadyResolved = errors.New("report is already resolved")
+	// ErrReportNotResolved is returned when confirming the resolution of a
+	// report no agency has resolved.
+	ErrReportNotResolved = errors.New("report has not been resolved")
+	// ErrNotReportOwner is returned when a user acts on another user's
+	// report as if it were their own.
+	ErrNotReportOwner = errors.New("only the reporter can do this")
+	// ErrReportAlreadyAcknowledged is returned for a report of a bulk
+	// update that its agency had acknowledged before.
+	ErrReportAlreadyAcknowledged = errors.New("report is already acknowledged")
+	// ErrInvalidStatusUpdate is returned for bulk status updates with an
+	// unknown status, too many or no reports, or evidence that is not a
+	// list of http or https links.
+	ErrInvalidStatusUpdate = errors.New("invalid status update")
+)
+
+// AgencyService manages responding agencies, the reports assigned to them
+// and the public scorecards that hold them to account
+type AgencyService interface {
+	CreateAgency(agency *models.Agency) error
+	ListAgencies() ([]models.Agency, error)
+	SetMemberAgency(userID uint, agencyID *uint) error
+	AssignReport(reportID string, agencyID uint) error
+	AcknowledgeReport(userID uint, reportID string) error
+	ResolveReport(userID uint, reportID, note string) error
+	UpdateStatuses(userID uint, update models.AgencyStatusUpdate) ([]models.AgencyStatusResult, error)
+	ConfirmResolution(userID uint, reportID string, confirmed bool) error
+	Scorecards() ([]models.AgencyScorecard, error)
+	ScorecardHistory(agencyID uint) (*models.AgencyScorecardHistory, error)
+	TakeMonthlySnapshot() (int, error)
+}
+
+type agencyService struct {
+	Config     *config.Config
+	agencyRepo db.AgencyRepository
+	scorecards *cache.TTL[[]models.AgencyScorecard]
+}
+
+// NewAgencyService creates a new instance of AgencyService
+func NewAgencyService(agencyRepo db.AgencyRepository, conf *config.Config) AgencyService {
+	return &agencyService{
+		Config:     conf,
+		agencyRepo: agencyRepo,
+		scorecards: cache.New[[]models.AgencyScorecard](ScorecardCacheTTL),
+	}
+}
+
+func (s *agencyService) CreateAgency(agency *models.Agency) error {
+	agency.ID = 0
+	agency.Name = strings.TrimSpace(agency.Name)
+	agency.CreatedAt = time.Now().Unix()
+	return s.agencyRepo.CreateAgency(agency)
+}
+
+func (s *agencyService) ListAgencies() ([]models.Agency, error) {
+	return s.agencyRepo.ListAgencies()
+}
+
+// SetMemberAgency makes a user staff of an agency, or removes them from
+// their agency when agencyID is nil
+func (s *agencyService) SetMemberAgency(userID uint, agencyID *uint) error {
+	if agencyID != nil {
+		if _, err := s.getAgency(*agencyID); err != nil {
+			return err
+		}
+	}
+	return s.agencyRepo.SetMemberAgency(userID, agencyID)
+}
+
+func (s *agencyService) getAgency(id uint) (*models.Agency, error) {
+	agency, err := s.agencyRepo.GetAgency(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAgencyNotFound
+	}
+	return agency, err
+}
+
+func (s *agencyService) getReport(reportID string) (*models.IncidentReport, error) {
+	report, err := s.agencyRepo.GetReport(reportID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrReportNotFound
+	}
+	return report, err
+}
+
+// AssignReport hands a report to an agency to respond to
+func (s *agencyService) AssignReport(reportID string, agencyID uint) error {
+	if _, err := s.getAgency(agencyID); err != nil {
+		return err
+	}
+	if _, err := s.getReport(reportID); err != nil {
+		return err
+	}
+	return s.agencyRepo.AssignReport(reportID, agencyID, time.Now().Unix())
+}
+
+// agencyReport loads a report assigned to the agency userID works for
+func (s *agencyService) agencyReport(userID uint, reportID string) (*models.IncidentReport, error) {
+	report, err := s.getReport(reportID)
+	if err != nil {
+		return nil, err
+	}
+	agencyID, err := s.agencyRepo.MemberAgencyID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if policy.Authorize(policy.Subject{UserID: userID, AgencyID: agencyID}, policy.RespondToReport, policy.Report(report)) != nil {
+		return nil, ErrNotAgencyReport
+	}
+	return report, nil
+}
+
+// AcknowledgeReport records that the agency has seen a report. Only the
+// first acknowledgement counts.
+func (s *agencyService) AcknowledgeReport(userID uint, reportID string) error {
+	report, err := s.agencyReport(userID, reportID)
+	if err != nil {
+		return err
+	}
+	_, err = s.agencyRepo.AcknowledgeReport(report, "", time.Now())
+	return err
+}
+
+// ResolveReport marks a report resolved by the agency, with an optional
+// note shown as the official response
+func (s *agencyService) ResolveReport(userID uint, reportID, note string) error {
+	report, err := s.agencyReport(userID, reportID)
+	if err != nil {
+		return err
+	}
+	if report.ResolvedAt > 0 {
+		return ErrReportAlreadyResolved
+	}
+	return s.resolve(report, userID, strings.TrimSpace(note), nil)
+}
+
+// resolve resolves a report for userID's agency, reporting a resolution
+// that got there first as ErrReportAlreadyResolved
+func (s *agencyService) resolve(report *models.IncidentReport, userID uint, note string, evidence []string) error {
+	err := s.agencyRepo.ResolveReport(report, userID, note, evidence, time.Now())
+	if errors.Is(err, db.ErrReportResolved) {
+		return ErrReportAlreadyResolved
+	}
+	return err
+}
+
+// UpdateStatuses acknowledges or resolves a batch of reports assigned to
+// the agency userID works for. The update as a whole is refused when it is
+// malformed; otherwise each report is updated on its own, and one that
+// cannot be, because it is unknown, another agency's, already acknowledged
+// or resolved, or not yet published, gets an error in its result without holding up the rest. Each update
+// publishes its event, so reporters and webhook subscribers hear of it.
+func (s *agencyService) UpdateStatuses(userID uint, update models.AgencyStatusUpdate) ([]models.AgencyStatusResult, error) {
+	status := strings.ToLower(strings.TrimSpace(update.Status))
+	if status != models.AgencyStatusAcknowledged && status != models.AgencyStatusResolved {
+		return nil, fmt.Errorf("%w: status must be %s or %s", ErrInvalidStatusUpdate, models.AgencyStatusAcknowledged, models.AgencyStatusResolved)
+	}
+	if len(update.ReportIDs) == 0 || len(update.ReportIDs) > MaxStatusUpdateReports {
+		return nil, fmt.Errorf("%w: give between 1 and %d report IDs", ErrInvalidStatusUpdate, MaxStatusUpdateReports)
+	}
+	evidence, err := evidenceURLs(update.EvidenceURLs)
+	if err != nil {
+		return nil, err
+	}
+	if len(evidence) > 0 && status != models.AgencyStatusResolved {
+		return nil, fmt.Errorf("%w: evidence can only be sent with a resolution", ErrInvalidStatusUpdate)
+	}
+	note := strings.TrimSpace(update.Note)
+
+	results := make([]models.AgencyStatusResult, len(update.ReportIDs))
+	seen := make(map[string]bool, len(update.ReportIDs))
+	for i, id := range update.ReportIDs {
+		id = strings.ToLower(strings.TrimSpace(id))
+		results[i].ReportID = id
+		if seen[id] {
+			results[i].Error = "report is listed more than once"
+			continue
+		}
+		seen[id] = true
+
+		if err := s.updateStatus(userID, id, status, note, evidence); err != nil {
+			if !statusUpdateRefused(err) {
+				return nil, err
+			}
+			results[i].Error = err.Error()
+			continue
+		}
+		results[i].Updated = true
+	}
+	return results, nil
+}
+
+func (s *agencyService) updateStatus(userID uint, reportID, status, note string, evidence []string) error {
+	if _, err := uuid.Parse(reportID); err != nil {
+		return ErrReportNotFound
+	}
+	report, err := s.agencyReport(userID, reportID)
+	if err != nil {
+		return err
+	}
+	if status == models.AgencyStatusAcknowledged {
+		acknowledged, err := s.agencyRepo.AcknowledgeReport(report, note, time.Now())
+		if err == nil && !acknowledged {
+			return ErrReportAlreadyAcknowledged
+		}
+		return err
+	}
+	if report.ResolvedAt > 0 {
+		return ErrReportAlreadyResolved
+	}
+	return s.resolve(report, userID, note, evidence)
+}
+
+// statusUpdateRefused reports whether err is about one report of a bulk
+// update rather than something that stops the whole batch
+func statusUpdateRefused(err error) bool {
+	return errors.Is(err, ErrReportNotFound) || errors.Is(err, ErrNotAgencyReport) ||
+		errors.Is(err, ErrReportAlreadyAcknowledged) || errors.Is(err, ErrReportAlreadyResolved) ||
+		errors.Is(err, db.ErrInvalidTransition)
+}
+
+// evidenceURLs checks that evidence is a short list of http or https links
+func evidenceURLs(evidence []string) ([]string, error) {
+	if len(evidence) > MaxStatusUpdateEvidence {
+		return nil, fmt.Errorf("%w: at most %d evidence URLs", ErrInvalidStatusUpdate, MaxStatusUpdateEvidence)
+	}
+	urls := make([]string, 0, len(evidence))
+	for _, raw := range evidence {
+		raw = strings.TrimSpace(raw)
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("%w: %q is not an http or https URL", ErrInvalidStatusUpdate, raw)
+		}
+		urls = append(urls, u.String())
+	}
+	return urls, nil
+}
+
+// ConfirmResolution records whether the reporter agrees the issue was
+// resolved. The answer may be changed later.
+func (s *agencyService) ConfirmResolution(userID uint, reportID string, confirmed bool) error {
+	report, err := s.getReport(reportID)
+	if err != nil {
+		return err
+	}
+	if policy.Authorize(policy.Subject{UserID: userID}, policy.EditReport, policy.Report(report)) != nil {
+		return ErrNotReportOwner
+	}
+	if report.ResolvedAt == 0 {
+		return ErrReportNotResolved
+	}
+	return s.agencyRepo.ConfirmResolution(reportID, confirmed)
+}
+
+// Scorecards returns every agency's scorecard over the reports assigned in
+// the last ScorecardWindowDays
+func (s *agencyService) Scorecards() ([]models.AgencyScorecard, error) {
+	return s.scorecards.GetOrLoad("current", func() ([]models.AgencyScorecard, error) {
+		end := time.Now()
+		scorecards, err := s.agencyRepo.Scorecards(end.AddDate(0, 0, -ScorecardWindowDays), end)
+		if err != nil {
+			return nil, err
+		}
+		for i := range scorecards {
+			scorecards[i].ConfirmedResolutionRate = confirmedRate(scorecards[i])
+		}
+		return scorecards, nil
+	})
+}
+
+func confirmedRate(scorecard models.AgencyScorecard) *float64 {
+	if scorecard.Resolved == 0 {
+		return nil
+	}
+	rate := float64(scorecard.Confirmed) / float64(scorecard.Resolved)
+	return &rate
+}
+
+// ScorecardHistory returns an agency's current scorecard and its monthly
+// snapshots
+func (s *agencyService) ScorecardHistory(agencyID uint) (*models.AgencyScorecardHistory, error) {
+	scorecards, err := s.Scorecards()
+	if err != nil {
+		return nil, err
+	}
+	history := &models.AgencyScorecardHistory{}
+	found := false
+	for _, scorecard := range scorecards {
+		if scorecard.AgencyID == agencyID {
+			history.Current, found = scorecard, true
+		}
+	}
+	if !found {
+		// The agency may have been created since the scorecards were cached
+		agency, err := s.getAgency(agencyID)
+		if err != nil {
+			return nil, err
+		}
+		history.Current = models.AgencyScorecard{AgencyID: agency.ID, AgencyName: agency.Name}
+	}
+	if history.Monthly, err = s.agencyRepo.ScorecardSnapshots(agencyID); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// TakeMonthlySnapshot freezes the scorecards of the month that last ended,
+// in UTC, unless that has been done already. It returns the number of
+// agencies snapshotted.
+func (s *agencyService) TakeMonthlySnapshot() (int, error) {
+	now := time.Now().UTC()
+	end := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	start := end.AddDate(0, -1, 0)
+	done, err := s.agencyRepo.HasScorecardSnapshot(start)
+	if err != nil || done {
+		return 0, err
+	}
+
+	scorecards, err := s.agencyRepo.Scorecards(start, end)
+	if err != nil {
+		return 0, err
+	}
+	snapshots := make([]models.AgencyScorecardSnapshot, len(scorecards))
+	for i, scorecard := range scorecards {
+		scorecard.ConfirmedResolutionRate = confirmedRate(scorecard)
+		snapshots[i] = models.AgencyScorecardSnapshot{
+			Month:           start,
+			AgencyScorecard: scorecard,
+			TakenAt:         now.Unix(),
+		}
+	}
+	return len(snapshots), s.agencyRepo.SaveScorecardSnapshots(snapshots)
+}
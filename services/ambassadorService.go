@@ -0,0 +1,158 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrLGANotFound is returned when making a user ambassador of an LGA
+	// that does not exist.
+	ErrLGANotFound = errors.New("LGA not found")
+	// ErrNotAmbassador is returned to users who are not the ambassador of
+	// any LGA.
+	ErrNotAmbassador = errors.New("you are not an ambassador")
+	// ErrInvalidMonth is returned for months not written YYYY-MM.
+	ErrInvalidMonth = errors.New("month must be written YYYY-MM")
+	// ErrNotVerifiable is returned when an ambassador verifies a report
+	// that needs a moderator.
+	ErrNotVerifiable = errors.New("this report needs a moderator")
+)
+
+// ambassadorQueueSize is how many reports awaiting verification the
+// dashboard lists
+const ambassadorQueueSize = 20
+
+// AmbassadorService runs the ambassador programme: volunteers who look after
+// the reports of their LGA
+type AmbassadorService interface {
+	SetAmbassador(userID uint, lga string) error
+	Dashboard(user *models.User) (*models.AmbassadorDashboard, error)
+	Verify(ambassadorID uint, reportID string) error
+	TakeMonthlySummary() (int, error)
+	ListSummaries(month string) ([]models.AmbassadorSummary, error)
+	UserSummaries(userID uint) ([]models.AmbassadorSummary, error)
+}
+
+type ambassadorService struct {
+	Config         *config.Config
+	ambassadorRepo db.AmbassadorRepository
+	incidentRepo   db.IncidentReportRepository
+	autoPublish    AutoPublishService
+	rewardService  RewardService
+}
+
+// NewAmbassadorService creates a new instance of AmbassadorService
+func NewAmbassadorService(ambassadorRepo db.AmbassadorRepository, incidentRepo db.IncidentReportRepository, autoPublish AutoPublishService, rewardService RewardService, conf *config.Config) AmbassadorService {
+	return &ambassadorService{
+		Config:         conf,
+		ambassadorRepo: ambassadorRepo,
+		incidentRepo:   incidentRepo,
+		autoPublish:    autoPublish,
+		rewardService:  rewardService,
+	}
+}
+
+// SetAmbassador makes a user ambassador of an LGA, or ends their role when
+// lga is empty. It returns gorm.ErrRecordNotFound for unknown users.
+func (s *ambassadorService) SetAmbassador(userID uint, lga string) error {
+	if lga != "" {
+		name, err := s.ambassadorRepo.FindLGAName(lga)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrLGANotFound
+		}
+		if err != nil {
+			return err
+		}
+		lga = name
+	}
+	return s.ambassadorRepo.SetAmbassadorLGA(userID, lga)
+}
+
+// Dashboard summarises the ambassador's LGA and lists the reports there
+// they can verify
+func (s *ambassadorService) Dashboard(user *models.User) (*models.AmbassadorDashboard, error) {
+	if user.AmbassadorLGA == "" {
+		return nil, ErrNotAmbassador
+	}
+	now := time.Now()
+	dashboard, err := s.ambassadorRepo.Dashboard(user.AmbassadorLGA, now.AddDate(0, 0, -7).Unix(), now.AddDate(0, 0, -30).Unix())
+	if err != nil {
+		return nil, err
+	}
+	dashboard.ToVerify, err = s.ambassadorRepo.PendingReports(user.AmbassadorLGA, s.autoPublish.LowRiskCategories(), ambassadorQueueSize)
+	if err != nil {
+		return nil, err
+	}
+	return dashboard, nil
+}
+
+// Verify approves a report awaiting review the way a moderator would. Only
+// low-risk reports qualify: in the auto_publish_categories, not sent
+// through an anonymizer or datacenter, and not the ambassador's own. The
+// route checks that the report is in the ambassador's LGA.
+func (s *ambassadorService) Verify(ambassadorID uint, reportID string) error {
+	report, err := s.incidentRepo.GetReportByID(reportID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrReportNotFound
+	}
+	if err != nil {
+		return err
+	}
+	switch {
+	case report.ReportStatus != "pending" && report.ReportStatus != "":
+		return fmt.Errorf("%w: it is already %s", ErrNotVerifiable, report.ReportStatus)
+	case report.UserID == ambassadorID:
+		return fmt.Errorf("%w: you cannot verify your own report", ErrNotVerifiable)
+	case report.NetworkFlag != "" || !s.autoPublish.LowRisk(report.Category):
+		return ErrNotVerifiable
+	}
+	return s.rewardService.ApproveReportPoints(reportID, report.UserID, ambassadorID)
+}
+
+// TakeMonthlySummary records every ambassador's last calendar month once,
+// returning how many it recorded
+func (s *ambassadorService) TakeMonthlySummary() (int, error) {
+	now := time.Now().UTC()
+	end := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	start := end.AddDate(0, -1, 0)
+	done, err := s.ambassadorRepo.HasSummaries(start)
+	if err != nil || done {
+		return 0, err
+	}
+
+	summaries, err := s.ambassadorRepo.Summaries(start, end)
+	if err != nil {
+		return 0, err
+	}
+	for i := range summaries {
+		summaries[i].Month = start
+		summaries[i].TakenAt = now.Unix()
+	}
+	return len(summaries), s.ambassadorRepo.SaveSummaries(summaries)
+}
+
+// ListSummaries returns every ambassador's summary of a month written
+// YYYY-MM, by default the last one
+func (s *ambassadorService) ListSummaries(month string) ([]models.AmbassadorSummary, error) {
+	now := time.Now().UTC()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0)
+	if month != "" {
+		parsed, err := time.Parse("2006-01", month)
+		if err != nil {
+			return nil, ErrInvalidMonth
+		}
+		start = parsed
+	}
+	return s.ambassadorRepo.ListSummaries(start)
+}
+
+func (s *ambassadorService) UserSummaries(userID uint) ([]models.AmbassadorSummary, error) {
+	return s.ambassadorRepo.UserSummaries(userID)
+}
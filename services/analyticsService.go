@@ -0,0 +1,143 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+// ErrSnapshotNotFound is returned when no snapshot was taken on or before
+// the requested date.
+var ErrSnapshotNotFound = errors.New("no snapshot was taken on or before that date")
+
+// AnalyticsService serves grouped report aggregations for dashboards
+type AnalyticsService interface {
+	ReportCounts(q db.AggregateQuery) ([]models.GroupCount, error)
+	ReportTimeseries(q db.AggregateQuery, interval string) (*models.Timeseries, error)
+	TakeDailySnapshot() (int64, error)
+	Snapshot(scope string, date time.Time) (time.Time, []models.AggregateSnapshot, error)
+	CompareSnapshots(scope string, date, previous time.Time) (*models.SnapshotComparison, error)
+	QueryReports(q db.ReportQuery) ([]map[string]interface{}, error)
+}
+
+type analyticsService struct {
+	Config        *config.Config
+	analyticsRepo db.AnalyticsRepository
+	calendar      CalendarService
+}
+
+// NewAnalyticsService creates a new instance of AnalyticsService
+func NewAnalyticsService(analyticsRepo db.AnalyticsRepository, calendar CalendarService, conf *config.Config) AnalyticsService {
+	return &analyticsService{
+		Config:        conf,
+		analyticsRepo: analyticsRepo,
+		calendar:      calendar,
+	}
+}
+
+// ReportCounts returns report totals per group
+func (s *analyticsService) ReportCounts(q db.AggregateQuery) ([]models.GroupCount, error) {
+	return s.analyticsRepo.CountReports(q)
+}
+
+// ReportTimeseries returns report totals per group per interval, read
+// against the calendar
+func (s *analyticsService) ReportTimeseries(q db.AggregateQuery, interval string) (*models.Timeseries, error) {
+	points, err := s.analyticsRepo.ReportTimeseries(q, interval)
+	if err != nil {
+		return nil, err
+	}
+	calendar, err := s.calendar.Annotate(q, interval, points)
+	if err != nil {
+		return nil, err
+	}
+	return &models.Timeseries{
+		GroupBy:  q.GroupBy,
+		Interval: interval,
+		Points:   points,
+		Calendar: calendar,
+	}, nil
+}
+
+// QueryReports answers an analyst's ad-hoc report query
+func (s *analyticsService) QueryReports(q db.ReportQuery) ([]map[string]interface{}, error) {
+	return s.analyticsRepo.QueryReports(q)
+}
+
+// TakeDailySnapshot records the totals for the day that last ended, in UTC,
+// unless that has been done already. It returns the number of rows written.
+func (s *analyticsService) TakeDailySnapshot() (int64, error) {
+	now := time.Now().UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, -1)
+	taken, err := s.analyticsRepo.HasSnapshot(day)
+	if err != nil || taken {
+		return 0, err
+	}
+	return s.analyticsRepo.TakeSnapshot(day)
+}
+
+// Snapshot returns the figures of scope as they were recorded for date, or
+// for the closest earlier day with a snapshot, along with that day
+func (s *analyticsService) Snapshot(scope string, date time.Time) (time.Time, []models.AggregateSnapshot, error) {
+	day, snapshots, err := s.analyticsRepo.SnapshotOn(scope, date)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return time.Time{}, nil, ErrSnapshotNotFound
+	}
+	return day, snapshots, err
+}
+
+// CompareSnapshots pairs up each group's figures on two dates. Groups found
+// on only one of them have a nil snapshot for the other.
+func (s *analyticsService) CompareSnapshots(scope string, date, previous time.Time) (*models.SnapshotComparison, error) {
+	day, current, err := s.Snapshot(scope, date)
+	if err != nil {
+		return nil, err
+	}
+	previousDay, earlier, err := s.Snapshot(scope, previous)
+	if err != nil {
+		return nil, err
+	}
+
+	comparison := &models.SnapshotComparison{
+		Scope:        scope,
+		Date:         day,
+		PreviousDate: previousDay,
+		Changes:      make([]models.SnapshotChange, 0, len(current)),
+	}
+	before := make(map[string]*models.AggregateSnapshot, len(earlier))
+	for i := range earlier {
+		before[earlier[i].GroupName] = &earlier[i]
+	}
+	for i := range current {
+		change := models.SnapshotChange{Group: current[i].GroupName, Current: &current[i]}
+		if prev, ok := before[current[i].GroupName]; ok {
+			change.Previous = prev
+			delete(before, current[i].GroupName)
+		}
+		comparison.Changes = append(comparison.Changes, withDeltas(change))
+	}
+	for i := range earlier {
+		if prev, ok := before[earlier[i].GroupName]; ok {
+			comparison.Changes = append(comparison.Changes, withDeltas(models.SnapshotChange{Group: prev.GroupName, Previous: prev}))
+		}
+	}
+	return comparison, nil
+}
+
+// withDeltas fills in how far a group's figures moved between snapshots
+func withDeltas(change models.SnapshotChange) models.SnapshotChange {
+	var current, previous models.AggregateSnapshot
+	if change.Current != nil {
+		current = *change.Current
+	}
+	if change.Previous != nil {
+		previous = *change.Previous
+	}
+	change.ReportsChange = current.Reports - previous.Reports
+	change.ResolutionRateChange = current.ResolutionRate - previous.ResolutionRate
+	return change
+}
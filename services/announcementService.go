@@ -0,0 +1,139 @@
+package services
+
+import (
+	"errors"
+	"time"
+
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+// ErrAnnouncementNotFound is returned for announcements that do not exist.
+var ErrAnnouncementNotFound = errors.New("announcement not found")
+
+// announcementBatchSize is how many recipients are notified at a time
+const announcementBatchSize = 500
+
+// AnnouncementService lets admins broadcast messages to segments of users
+type AnnouncementService interface {
+	PreviewAudience(segment models.AnnouncementSegment) (int64, error)
+	CreateAnnouncement(announcement *models.Announcement, adminID uint) error
+	ListAnnouncements(page int) ([]models.Announcement, error)
+	GetAnnouncementStats(id uint) (*models.AnnouncementStats, error)
+	SendDue() (int, error)
+}
+
+type announcementService struct {
+	Config              *config.Config
+	announcementRepo    db.AnnouncementRepository
+	notificationService NotificationService
+}
+
+// NewAnnouncementService creates a new instance of AnnouncementService
+func NewAnnouncementService(announcementRepo db.AnnouncementRepository, notificationService NotificationService, conf *config.Config) AnnouncementService {
+	return &announcementService{
+		Config:              conf,
+		announcementRepo:    announcementRepo,
+		notificationService: notificationService,
+	}
+}
+
+// PreviewAudience returns how many users an announcement to the segment
+// would reach
+func (s *announcementService) PreviewAudience(segment models.AnnouncementSegment) (int64, error) {
+	return s.announcementRepo.CountAudience(segment)
+}
+
+// CreateAnnouncement schedules an announcement. One without a time, or with
+// a time in the past, goes out with the next delivery run.
+func (s *announcementService) CreateAnnouncement(announcement *models.Announcement, adminID uint) error {
+	now := time.Now().Unix()
+	announcement.ID = 0
+	announcement.CreatedBy = adminID
+	announcement.Status = models.AnnouncementScheduled
+	announcement.SentAt = 0
+	announcement.CreatedAt = now
+	if announcement.ScheduledAt < now {
+		announcement.ScheduledAt = now
+	}
+
+	recipients, err := s.announcementRepo.CountAudience(announcement.Segment)
+	if err != nil {
+		return err
+	}
+	announcement.Recipients = recipients
+	return s.announcementRepo.CreateAnnouncement(announcement)
+}
+
+func (s *announcementService) ListAnnouncements(page int) ([]models.Announcement, error) {
+	return s.announcementRepo.ListAnnouncements(page)
+}
+
+// GetAnnouncementStats reports delivery and opens of an announcement
+func (s *announcementService) GetAnnouncementStats(id uint) (*models.AnnouncementStats, error) {
+	announcement, err := s.announcementRepo.GetAnnouncement(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAnnouncementNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	delivered, opened, err := s.announcementRepo.AnnouncementDelivery(id)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &models.AnnouncementStats{
+		AnnouncementID: id,
+		Status:         announcement.Status,
+		Recipients:     announcement.Recipients,
+		Delivered:      delivered,
+		Opened:         opened,
+	}
+	if delivered > 0 {
+		stats.OpenRate = float64(opened) / float64(delivered)
+	}
+	return stats, nil
+}
+
+// SendDue delivers every announcement whose time has come and returns how
+// many were sent. The audience is resolved at send time, so users who join
+// the segment after scheduling are included. An announcement interrupted
+// part way is resumed from the start; users already notified are skipped.
+func (s *announcementService) SendDue() (int, error) {
+	due, err := s.announcementRepo.DueAnnouncements(time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range due {
+		announcement := &due[i]
+		if err := s.announcementRepo.SetAnnouncementStatus(announcement.ID, models.AnnouncementSending, announcement.Recipients); err != nil {
+			return i, err
+		}
+
+		var recipients int64
+		var afterID uint
+		for {
+			ids, err := s.announcementRepo.AudiencePage(announcement.Segment, afterID, announcementBatchSize)
+			if err != nil {
+				return i, err
+			}
+			if len(ids) == 0 {
+				break
+			}
+			if err := s.notificationService.Announce(announcement, ids); err != nil {
+				return i, err
+			}
+			recipients += int64(len(ids))
+			afterID = ids[len(ids)-1]
+		}
+
+		if err := s.announcementRepo.SetAnnouncementStatus(announcement.ID, models.AnnouncementSent, recipients); err != nil {
+			return i, err
+		}
+	}
+	return len(due), nil
+}
@@ -13,15 +13,21 @@ import (
 	"github.com/techagentng/citizenx/config"
 	"github.com/techagentng/citizenx/db"
 	apiError "github.com/techagentng/citizenx/errors"
+	"github.com/techagentng/citizenx/locale"
 	"github.com/techagentng/citizenx/models"
 	"github.com/techagentng/citizenx/services/jwt"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 	"log"
 	"net/http"
+	"time"
 )
 
-//go:generate mockgen -destination=../mocks/auth_mock.go -package=mocks github.com/decagonhq/meddle-api/services AuthService
+// ErrUnsupportedLocale is returned when a profile names a locale the API
+// cannot format for.
+var ErrUnsupportedLocale = errors.New("unsupported locale")
+
+//go:generate mockgen -destination=../mocks/auth_mock.go -package=mocks github.com/techagentng/citizenx/services AuthService
 
 // AuthService interface
 type AuthService interface {
@@ -39,19 +45,27 @@ type AuthService interface {
 	// DeleteUserByEmail(userEmail string) *apiError.Error
 	GetRoleByName(name string) (*models.Role, error)
 	DeleteUser(userID uint) error
+	ChangeEmail(userID uint, email string) error
+	DeactivateAccount(userID uint) error
 }
 
 // authService struct
 type authService struct {
-	Config   *config.Config
-	authRepo db.AuthRepository
+	Config       *config.Config
+	authRepo     db.AuthRepository
+	sessions     SessionService
+	displayNames *DisplayNamePolicy
+	ages         *AgePolicy
 }
 
 // NewAuthService instantiate an authService
-func NewAuthService(authRepo db.AuthRepository, conf *config.Config) AuthService {
+func NewAuthService(authRepo db.AuthRepository, sessions SessionService, conf *config.Config) AuthService {
 	return &authService{
-		Config:   conf,
-		authRepo: authRepo,
+		Config:       conf,
+		authRepo:     authRepo,
+		sessions:     sessions,
+		displayNames: NewDisplayNamePolicy(conf),
+		ages:         NewAgePolicy(conf),
 	}
 }
 
@@ -66,8 +80,24 @@ func (s *authService) SignupUser(user *models.User) (*models.User, error) {
 		return nil, errors.New("email is empty")
 	}
 
+	// Public names must pass the display-name policy
+	if err := s.displayNames.Check(models.DisplayNameUsername, user.Username); err != nil {
+		return nil, err
+	}
+	if err := s.displayNames.Check(models.DisplayNameFullname, user.Fullname); err != nil {
+		return nil, err
+	}
+
+	// Younger users start in restricted mode
+	born, restrictedUntil, err := s.ages.Check(user.DateOfBirth, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	user.DateOfBirth = born.Format("2006-01-02")
+	user.RestrictedUntil = restrictedUntil
+
 	// Check if the email already exists
-	err := s.authRepo.IsEmailExist(user.Email)
+	err = s.authRepo.IsEmailExist(user.Email)
 	if err != nil {
 		log.Printf("SignupUser error: %v", err)
 		return nil, apiError.GetUniqueContraintError(err)
@@ -150,36 +180,12 @@ func (a *authService) LoginUser(loginRequest *models.LoginRequest) (*models.Logi
 		return nil, apiError.New("user role not assigned", http.StatusInternalServerError)
 	}
 
-	// Fetch the user's role
-	log.Printf("Fetching role with ID: %s for user %s", foundUser.RoleID.String(), foundUser.Email)
-	role, err := a.authRepo.FindRoleByID(foundUser.RoleID)
+	login, err := a.sessions.Start(foundUser)
 	if err != nil {
-		log.Printf("Error fetching role for user %s: %v", foundUser.Email, err)
-		return nil, apiError.New("unable to fetch role", http.StatusInternalServerError)
-	}
-
-	roleName := role.Name
-
-	// Generate tokens with role information
-	log.Printf("Generating token pair for user %s with role %s", foundUser.Email, roleName)
-	accessToken, refreshToken, err := jwt.GenerateTokenPair(foundUser.Email, a.Config.JWTSecret, foundUser.AdminStatus, foundUser.ID, roleName)
-	if err != nil {
-		log.Printf("Error generating token pair for user %s: %v", foundUser.Email, err)
+		log.Printf("Error starting session for user %s: %v", foundUser.Email, err)
 		return nil, apiError.ErrInternalServerError
 	}
-
-	return &models.LoginResponse{
-		UserResponse: models.UserResponse{
-			ID:        foundUser.ID,
-			Fullname:  foundUser.Fullname,
-			Username:  foundUser.Username,
-			Telephone: foundUser.Telephone,
-			Email:     foundUser.Email,
-			RoleName:  roleName,
-		},
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-	}, nil
+	return login, nil
 }
 
 // func (a *authService) GetUserByID(id string) (*models.User, error) {
@@ -226,13 +232,61 @@ func (a *authService) GetUserProfile(userID uint) (*models.User, error) {
 }
 
 func (a *authService) EditUserProfile(userID uint, userDetail *models.EditProfileResponse) error {
-	// Implement your business logic here, if needed
-	// For example, you might want to perform validation on the user details before updating
+	if userDetail.Locale != "" {
+		tag, ok := locale.Normalize(userDetail.Locale)
+		if !ok {
+			return ErrUnsupportedLocale
+		}
+		userDetail.Locale = tag
+	}
+
+	// Names already on the profile, possibly allowed on appeal, are not
+	// checked again
+	user, err := a.authRepo.FindUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if userDetail.Username != "" && userDetail.Username != user.Username {
+		if err := a.displayNames.Check(models.DisplayNameUsername, userDetail.Username); err != nil {
+			return err
+		}
+	}
+	if userDetail.FullName != "" && userDetail.FullName != user.Fullname {
+		if err := a.displayNames.Check(models.DisplayNameFullname, userDetail.FullName); err != nil {
+			return err
+		}
+	}
+	// A date of birth cannot be changed once given, or restricted mode could
+	// be left early
+	if userDetail.DateOfBirth != "" && userDetail.DateOfBirth != user.DateOfBirth {
+		if user.DateOfBirth != "" {
+			return ErrDateOfBirthSet
+		}
+		born, restrictedUntil, err := a.ages.Check(userDetail.DateOfBirth, time.Now())
+		if err != nil {
+			return err
+		}
+		userDetail.DateOfBirth = born.Format("2006-01-02")
+		userDetail.RestrictedUntil = restrictedUntil
+	}
 
 	// Call the repository method to update user profile
 	return a.authRepo.EditUserProfile(userID, userDetail)
 }
 
+// ChangeEmail moves the account to a new email address, which has to be
+// verified again
+func (a *authService) ChangeEmail(userID uint, email string) error {
+	updated, err := a.authRepo.UpdateEmail(userID, email)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return ErrEmailInUse
+	}
+	return nil
+}
+
 func (a *authService) SendEmailForPasswordReset(user *models.ForgotPassword) *apiError.Error {
 	return apiError.ErrBadRequest
 }
@@ -262,3 +316,13 @@ func (a *authService) GetRoleByName(name string) (*models.Role, error) {
 func (s *authService) DeleteUser(userID uint) error {
 	return s.authRepo.SoftDeleteUser(userID)
 }
+
+// DeactivateAccount hides the account from other users and stops its
+// notifications, keeping its reports attributed, and signs it out
+// everywhere. Signing in again reactivates it.
+func (s *authService) DeactivateAccount(userID uint) error {
+	if err := s.authRepo.DeactivateUser(userID, time.Now().Unix()); err != nil {
+		return err
+	}
+	return s.sessions.EndOthers(userID, "")
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrAutoCloseRuleNotFound is returned for rules that do not exist.
+	ErrAutoCloseRuleNotFound = errors.New("auto-close rule not found")
+	// ErrInvalidAutoCloseRule is returned for a rule that would close
+	// reports already closed.
+	ErrInvalidAutoCloseRule = errors.New("auto-close rules cannot apply to closed reports")
+)
+
+// autoCloseBatchSize is how many reports are closed per transaction
+const autoCloseBatchSize = 200
+
+// AutoCloseService closes reports that have gone stale under the rules
+// admins configure
+type AutoCloseService interface {
+	ListRules() ([]models.AutoCloseRule, error)
+	SaveRule(rule *models.AutoCloseRule, adminID uint) error
+	DeleteRule(id uint) error
+	CloseStaleReports() (int, error)
+}
+
+type autoCloseService struct {
+	Config        *config.Config
+	autoCloseRepo db.AutoCloseRepository
+}
+
+// NewAutoCloseService creates a new instance of AutoCloseService
+func NewAutoCloseService(autoCloseRepo db.AutoCloseRepository, conf *config.Config) AutoCloseService {
+	return &autoCloseService{
+		Config:        conf,
+		autoCloseRepo: autoCloseRepo,
+	}
+}
+
+func (s *autoCloseService) ListRules() ([]models.AutoCloseRule, error) {
+	return s.autoCloseRepo.ListRules()
+}
+
+// SaveRule creates a rule or replaces the one for the same category and
+// status. Categories and statuses are matched without regard to case.
+func (s *autoCloseService) SaveRule(rule *models.AutoCloseRule, adminID uint) error {
+	rule.ID = 0
+	rule.Category = strings.ToLower(strings.TrimSpace(rule.Category))
+	rule.Status = strings.ToLower(strings.TrimSpace(rule.Status))
+	if rule.Status == "" || rule.Status == models.ReportStatusClosed {
+		return ErrInvalidAutoCloseRule
+	}
+	now := time.Now().Unix()
+	rule.CreatedBy = adminID
+	rule.CreatedAt = now
+	rule.UpdatedAt = now
+	return s.autoCloseRepo.SaveRule(rule)
+}
+
+func (s *autoCloseService) DeleteRule(id uint) error {
+	err := s.autoCloseRepo.DeleteRule(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrAutoCloseRuleNotFound
+	}
+	return err
+}
+
+// CloseStaleReports applies every enabled rule and returns how many reports
+// were closed. Reporters are notified through the ReportClosed event.
+func (s *autoCloseService) CloseStaleReports() (int, error) {
+	rules, err := s.autoCloseRepo.ListRules()
+	if err != nil {
+		return 0, err
+	}
+
+	// Categories with a rule of their own for a status are left out of the
+	// catch-all rule for that status; a disabled one exempts the category
+	overridden := map[string][]string{}
+	for _, rule := range rules {
+		if rule.Category != "" {
+			overridden[rule.Status] = append(overridden[rule.Status], rule.Category)
+		}
+	}
+
+	total := 0
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		for {
+			closed, err := s.autoCloseRepo.CloseStaleReports(rule, overridden[rule.Status], autoCloseBatchSize)
+			total += closed
+			if err != nil {
+				return total, err
+			}
+			if closed < autoCloseBatchSize {
+				break
+			}
+		}
+	}
+	return total, nil
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"math/rand"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrAuditNotFound is returned for report audits that do not exist.
+	ErrAuditNotFound = errors.New("report audit not found")
+	// ErrAuditCompleted is returned when recording the outcome of an audit
+	// that already has one.
+	ErrAuditCompleted = errors.New("report audit is already complete")
+	// ErrInvalidAuditOutcome is returned for outcomes other than passed and
+	// failed.
+	ErrInvalidAuditOutcome = errors.New("outcome must be passed or failed")
+)
+
+// AutoPublishService lets trusted reporters skip review in low-risk
+// categories, and audits a sample of what they publish
+type AutoPublishService interface {
+	Eligible(userID uint, category string) bool
+	LowRisk(category string) bool
+	LowRiskCategories() []string
+	Sample(report *models.IncidentReport) error
+	ListAudits(outcome string, page int) ([]models.ReportAudit, error)
+	CompleteAudit(auditID, auditorID uint, outcome, note string) (*models.ReportAudit, error)
+}
+
+type autoPublishService struct {
+	Config            *config.Config
+	auditRepo         db.ReportAuditRepository
+	reputationService ReputationService
+	categories        map[string]bool
+}
+
+// NewAutoPublishService creates a new instance of AutoPublishService. Only
+// the categories listed in auto_publish_categories are auto-published.
+func NewAutoPublishService(auditRepo db.ReportAuditRepository, reputationService ReputationService, conf *config.Config) AutoPublishService {
+	categories := map[string]bool{}
+	for _, category := range strings.Split(conf.AutoPublishCategories, ",") {
+		if category = strings.TrimSpace(category); category != "" {
+			categories[strings.ToLower(category)] = true
+		}
+	}
+	return &autoPublishService{
+		Config:            conf,
+		auditRepo:         auditRepo,
+		reputationService: reputationService,
+		categories:        categories,
+	}
+}
+
+// Eligible reports whether a report in category by the user may publish
+// without review. When reputation cannot be checked the report goes through
+// review as usual.
+func (s *autoPublishService) Eligible(userID uint, category string) bool {
+	if !s.LowRisk(category) {
+		return false
+	}
+	allowed, err := s.reputationService.Allowed(userID, PrivilegeAutoPublish)
+	if err != nil {
+		log.Printf("checking auto-publish privilege of user %d: %v", userID, err)
+		return false
+	}
+	return allowed
+}
+
+// LowRisk reports whether category is one of the auto_publish_categories
+func (s *autoPublishService) LowRisk(category string) bool {
+	return s.categories[strings.ToLower(strings.TrimSpace(category))]
+}
+
+// LowRiskCategories returns the auto_publish_categories, lower-cased
+func (s *autoPublishService) LowRiskCategories() []string {
+	categories := make([]string, 0, len(s.categories))
+	for category := range s.categories {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	return categories
+}
+
+// Sample queues an auto-published report for audit at the configured rate
+func (s *autoPublishService) Sample(report *models.IncidentReport) error {
+	if rand.Intn(100) >= s.Config.AutoPublishAuditPercent {
+		return nil
+	}
+	return s.auditRepo.CreateAudit(&models.ReportAudit{
+		ReportID:  report.ID,
+		UserID:    report.UserID,
+		Category:  report.Category,
+		SampledAt: time.Now().Unix(),
+	})
+}
+
+// ListAudits returns audits awaiting review, or with ?outcome= those
+// completed with that outcome
+func (s *autoPublishService) ListAudits(outcome string, page int) ([]models.ReportAudit, error) {
+	switch outcome {
+	case models.AuditOutcomePending, models.AuditOutcomePassed, models.AuditOutcomeFailed:
+	default:
+		return nil, ErrInvalidAuditOutcome
+	}
+	return s.auditRepo.ListAudits(outcome, page)
+}
+
+// CompleteAudit records a moderator's verdict on an auto-published report.
+// A failed audit rejects the report and suspends the reporter's privileges,
+// including auto-publishing, for auto_publish_suspension_days.
+func (s *autoPublishService) CompleteAudit(auditID, auditorID uint, outcome, note string) (*models.ReportAudit, error) {
+	if outcome != models.AuditOutcomePassed && outcome != models.AuditOutcomeFailed {
+		return nil, ErrInvalidAuditOutcome
+	}
+	audit, err := s.auditRepo.GetAudit(auditID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAuditNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if audit.Outcome != models.AuditOutcomePending {
+		return nil, ErrAuditCompleted
+	}
+
+	now := time.Now()
+	audit.Outcome = outcome
+	audit.Note = strings.TrimSpace(note)
+	audit.AuditedBy = &auditorID
+	audit.AuditedAt = now.Unix()
+	suspendUntil := now.AddDate(0, 0, s.Config.AutoPublishSuspensionDays).Unix()
+	if err := s.auditRepo.CompleteAudit(audit, suspendUntil); err != nil {
+		return nil, err
+	}
+	return audit, nil
+}
@@ -0,0 +1,266 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+const (
+	// anomalyHistory is how many earlier periods a group's baseline is
+	// taken from
+	anomalyHistory = 8
+	// anomalyMinHistory is how many earlier periods a group needs before its
+	// counts are judged
+	anomalyMinHistory = 3
+	// anomalyMinReports is the smallest count flagged as an anomaly, so a
+	// quiet LGA going from one report to three is not
+	anomalyMinReports = 5
+)
+
+var (
+	// ErrCalendarEventNotFound is returned for calendar events that do not
+	// exist.
+	ErrCalendarEventNotFound = errors.New("calendar event not found")
+	// ErrInvalidCalendarEvent is returned for a calendar event with an
+	// unknown kind, bad dates or a negative expected change.
+	ErrInvalidCalendarEvent = errors.New("invalid calendar event")
+)
+
+// CalendarService keeps the calendar of holidays, elections and seasons and
+// reads report timeseries against it
+type CalendarService interface {
+	ListEvents() ([]models.CalendarEvent, error)
+	SaveEvent(event *models.CalendarEvent, adminID uint) error
+	DeleteEvent(id uint) error
+	Between(from, to time.Time) ([]models.CalendarEvent, error)
+	Annotate(q db.AggregateQuery, interval string, points []models.TimeseriesPoint) ([]models.CalendarEvent, error)
+}
+
+type calendarService struct {
+	Config       *config.Config
+	calendarRepo db.CalendarRepository
+}
+
+// NewCalendarService creates a new instance of CalendarService
+func NewCalendarService(calendarRepo db.CalendarRepository, conf *config.Config) CalendarService {
+	return &calendarService{
+		Config:       conf,
+		calendarRepo: calendarRepo,
+	}
+}
+
+func (s *calendarService) ListEvents() ([]models.CalendarEvent, error) {
+	return s.calendarRepo.ListCalendarEvents()
+}
+
+// SaveEvent creates an event, or replaces the one with the same ID
+func (s *calendarService) SaveEvent(event *models.CalendarEvent, adminID uint) error {
+	event.Kind = strings.ToLower(strings.TrimSpace(event.Kind))
+	event.Name = strings.TrimSpace(event.Name)
+	if !slices.Contains(models.CalendarEventKinds, event.Kind) || event.Name == "" {
+		return fmt.Errorf("%w: kind must be one of %s", ErrInvalidCalendarEvent, strings.Join(models.CalendarEventKinds, ", "))
+	}
+	start, errStart := time.Parse(time.DateOnly, event.StartDate)
+	end, errEnd := time.Parse(time.DateOnly, event.EndDate)
+	if errStart != nil || errEnd != nil || end.Before(start) {
+		return fmt.Errorf("%w: dates must be YYYY-MM-DD, ending on or after the start", ErrInvalidCalendarEvent)
+	}
+	if event.Recurring && end.After(start.AddDate(1, 0, -1)) {
+		return fmt.Errorf("%w: a recurring event must be shorter than a year", ErrInvalidCalendarEvent)
+	}
+	if event.ExpectedPercent == 0 {
+		event.ExpectedPercent = 100
+	}
+	if event.ExpectedPercent < 0 {
+		return fmt.Errorf("%w: expected_percent cannot be negative", ErrInvalidCalendarEvent)
+	}
+	event.StateName = strings.TrimSpace(event.StateName)
+	event.Category = strings.TrimSpace(event.Category)
+
+	now := time.Now().Unix()
+	event.CreatedBy = adminID
+	event.CreatedAt = now
+	event.UpdatedAt = now
+	err := s.calendarRepo.SaveCalendarEvent(event)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrCalendarEventNotFound
+	}
+	return err
+}
+
+func (s *calendarService) DeleteEvent(id uint) error {
+	err := s.calendarRepo.DeleteCalendarEvent(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrCalendarEventNotFound
+	}
+	return err
+}
+
+// Between returns the events falling on the days from to to, with each
+// recurring event moved to the years it falls in
+func (s *calendarService) Between(from, to time.Time) ([]models.CalendarEvent, error) {
+	first, last := from.Format(time.DateOnly), to.Format(time.DateOnly)
+	stored, err := s.calendarRepo.CalendarEventsBetween(first, last)
+	if err != nil {
+		return nil, err
+	}
+	calendar := []models.CalendarEvent{}
+	for _, event := range stored {
+		if !event.Recurring {
+			calendar = append(calendar, event)
+			continue
+		}
+		start, _ := time.Parse(time.DateOnly, event.StartDate)
+		end, _ := time.Parse(time.DateOnly, event.EndDate)
+		// A season that runs into the new year starts the year before
+		for year := from.Year() - 1; year <= to.Year(); year++ {
+			years := year - start.Year()
+			occurrence := event
+			occurrence.StartDate = start.AddDate(years, 0, 0).Format(time.DateOnly)
+			occurrence.EndDate = end.AddDate(years, 0, 0).Format(time.DateOnly)
+			if occurrence.StartDate <= last && occurrence.EndDate >= first {
+				calendar = append(calendar, occurrence)
+			}
+		}
+	}
+	slices.SortStableFunc(calendar, func(a, b models.CalendarEvent) int {
+		return strings.Compare(a.StartDate, b.StartDate)
+	})
+	return calendar, nil
+}
+
+// Annotate names the calendar events in each period of points and flags
+// counts far above what the group's earlier periods predict. The baseline is
+// taken with the calendar's expected changes divided out, and the
+// prediction multiplies them back in, so an election week or the rains
+// raise the bar instead of being flagged. It returns the events in the
+// timeseries.
+func (s *calendarService) Annotate(q db.AggregateQuery, interval string, points []models.TimeseriesPoint) ([]models.CalendarEvent, error) {
+	if len(points) == 0 {
+		return []models.CalendarEvent{}, nil
+	}
+	step := func(t time.Time, n int) time.Time {
+		switch interval {
+		case "week":
+			return t.AddDate(0, 0, 7*n)
+		case "month":
+			return t.AddDate(0, n, 0)
+		}
+		return t.AddDate(0, 0, n)
+	}
+	first, last := points[0].Period, points[0].Period
+	for _, point := range points {
+		if point.Period.Before(first) {
+			first = point.Period
+		}
+		if point.Period.After(last) {
+			last = point.Period
+		}
+	}
+	calendar, err := s.Between(first, step(last, 1).AddDate(0, 0, -1))
+	if err != nil {
+		return nil, err
+	}
+
+	// factor is how many times its usual count the calendar expects of a
+	// group in the period starting at period, with each event weighed by the
+	// share of the period it covers
+	factor := func(group string, period time.Time, names *[]string) float64 {
+		end := step(period, 1)
+		days := end.Sub(period).Hours() / 24
+		f := 1.0
+		for _, event := range calendar {
+			if !calendarApplies(event, q, group) {
+				continue
+			}
+			start, _ := time.Parse(time.DateOnly, event.StartDate)
+			stop, _ := time.Parse(time.DateOnly, event.EndDate)
+			stop = stop.AddDate(0, 0, 1)
+			from, to := maxTime(start, period), minTime(stop, end)
+			if !from.Before(to) {
+				continue
+			}
+			if names != nil {
+				*names = append(*names, event.Name)
+			}
+			f += (float64(event.ExpectedPercent)/100 - 1) * to.Sub(from).Hours() / 24 / days
+		}
+		return max(f, 0.01)
+	}
+
+	counts := make(map[string]map[time.Time]int64)
+	for _, point := range points {
+		if counts[point.Group] == nil {
+			counts[point.Group] = map[time.Time]int64{}
+		}
+		counts[point.Group][point.Period.UTC()] = point.Count
+	}
+	threshold := float64(s.Config.AnomalyThresholdPercent) / 100
+	for i := range points {
+		point := &points[i]
+		expected := factor(point.Group, point.Period, &point.Events)
+
+		// Periods before the timeseries starts are unknown rather than empty
+		var usual float64
+		history := 0
+		for n := 1; n <= anomalyHistory; n++ {
+			earlier := step(point.Period, -n)
+			if earlier.Before(first) {
+				break
+			}
+			usual += float64(counts[point.Group][earlier.UTC()]) / factor(point.Group, earlier, nil)
+			history++
+		}
+		if history < anomalyMinHistory {
+			continue
+		}
+		point.Expected = usual / float64(history) * expected
+		point.Anomaly = threshold > 0 && point.Count >= anomalyMinReports &&
+			float64(point.Count) > point.Expected*threshold
+	}
+	return calendar, nil
+}
+
+// calendarApplies reports whether event concerns the reports of group under
+// q: an event for one state or category only applies to timeseries of that
+// state or category
+func calendarApplies(event models.CalendarEvent, q db.AggregateQuery, group string) bool {
+	for _, scope := range []struct{ value, filter, dimension string }{
+		{event.StateName, q.StateName, "state"},
+		{event.Category, q.Category, "category"},
+	} {
+		if scope.value == "" {
+			continue
+		}
+		if q.GroupBy == scope.dimension {
+			if !strings.EqualFold(scope.value, group) {
+				return false
+			}
+		} else if !strings.EqualFold(scope.value, scope.filter) {
+			return false
+		}
+	}
+	return true
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
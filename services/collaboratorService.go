@@ -0,0 +1,314 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/events"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/policy"
+	"github.com/techagentng/citizenx/textfilter"
+	"gorm.io/gorm"
+)
+
+const (
+	// MaxCollaborators bounds the collaborators on one report
+	MaxCollaborators = 10
+	// MaxUpdateLength bounds the text of an update added to a report
+	MaxUpdateLength = 2000
+)
+
+var (
+	// ErrCollaboratorNotFound is returned when adding a user who does not
+	// exist, or acting on one who is not a collaborator on the report.
+	ErrCollaboratorNotFound = errors.New("collaborator not found")
+	// ErrAlreadyCollaborator is returned when adding a user who already
+	// collaborates on the report, or the reporter themselves.
+	ErrAlreadyCollaborator = errors.New("user is already on this report")
+	// ErrTooManyCollaborators is returned when a report has as many
+	// collaborators as it may.
+	ErrTooManyCollaborators = errors.New("report has the maximum number of collaborators")
+	// ErrInvalidRewardShare is returned for reward shares outside 0-100 or
+	// adding up to more than the report's points.
+	ErrInvalidRewardShare = errors.New("reward shares must be between 0 and 100 and add up to at most 100")
+	// ErrNotContributor is returned when someone who is neither the reporter
+	// nor a collaborator adds to a report.
+	ErrNotContributor = errors.New("only the reporter and collaborators can add to this report")
+	// ErrInvalidContribution is returned for empty or oversized updates.
+	ErrInvalidContribution = errors.New("an update needs between 1 and 2000 characters")
+)
+
+// CollaboratorService lets reporters share their reports with other users,
+// who may then add media and updates, each attributed to its author
+type CollaboratorService interface {
+	AddCollaborator(ownerID uint, reportID, username string) (*models.ReportCollaborator, error)
+	RemoveCollaborator(ownerID uint, reportID string, userID uint) error
+	Collaborators(userID uint, reportID string) ([]models.ReportCollaborator, error)
+	SetRewardShares(ownerID uint, reportID string, shares map[uint]int) error
+	CanContribute(userID uint, reportID string) error
+	AddUpdate(userID uint, reportID, text string) (*models.ReportContribution, error)
+	AddMedia(userID uint, reportID string, media []models.Media) ([]models.ReportContribution, error)
+	Contributions(reportID string, page int) ([]models.ReportContribution, error)
+}
+
+type collaboratorService struct {
+	Config           *config.Config
+	collaboratorRepo db.CollaboratorRepository
+	incidentRepo     db.IncidentReportRepository
+	authRepo         db.AuthRepository
+	textFilter       *textfilter.Filter
+}
+
+// NewCollaboratorService creates a new instance of CollaboratorService
+func NewCollaboratorService(collaboratorRepo db.CollaboratorRepository, incidentRepo db.IncidentReportRepository, authRepo db.AuthRepository, conf *config.Config) CollaboratorService {
+	return &collaboratorService{
+		Config:           conf,
+		collaboratorRepo: collaboratorRepo,
+		incidentRepo:     incidentRepo,
+		authRepo:         authRepo,
+		textFilter:       textfilter.New(strings.Split(conf.BannedWords, ",")),
+	}
+}
+
+// report returns the report, mapping unknown and malformed IDs to
+// ErrReportNotFound
+func (s *collaboratorService) report(reportID string) (*models.IncidentReport, error) {
+	if _, err := uuid.Parse(reportID); err != nil {
+		return nil, ErrReportNotFound
+	}
+	report, err := s.incidentRepo.GetIncidentReportByID(reportID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrReportNotFound
+	}
+	return report, err
+}
+
+// ownReport returns the report when userID is its reporter
+func (s *collaboratorService) ownReport(userID uint, reportID string) (*models.IncidentReport, error) {
+	report, err := s.report(reportID)
+	if err != nil {
+		return nil, err
+	}
+	if policy.Authorize(policy.Subject{UserID: userID}, policy.EditReport, policy.Report(report)) != nil {
+		return nil, ErrNotReportOwner
+	}
+	return report, nil
+}
+
+// AddCollaborator lets the user with the given username contribute to the
+// owner's report. New collaborators get no share of the reward until the
+// owner sets one.
+func (s *collaboratorService) AddCollaborator(ownerID uint, reportID, username string) (*models.ReportCollaborator, error) {
+	report, err := s.ownReport(ownerID, reportID)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.authRepo.FindUserByUsername(strings.TrimSpace(username))
+	if err != nil || user == nil {
+		return nil, ErrCollaboratorNotFound
+	}
+	if user.ID == ownerID {
+		return nil, ErrAlreadyCollaborator
+	}
+	collaborators, err := s.collaboratorRepo.Collaborators(reportID)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range collaborators {
+		if c.UserID == user.ID {
+			return nil, ErrAlreadyCollaborator
+		}
+	}
+	if len(collaborators) >= MaxCollaborators {
+		return nil, ErrTooManyCollaborators
+	}
+
+	now := time.Now()
+	collaborator := &models.ReportCollaborator{
+		ReportID:  report.ID,
+		UserID:    user.ID,
+		AddedBy:   ownerID,
+		CreatedAt: now.Unix(),
+	}
+	err = s.collaboratorRepo.AddCollaborator(collaborator, events.CollaboratorAdded{
+		ReportID:   report.ID,
+		UserID:     user.ID,
+		OwnerID:    ownerID,
+		OccurredAt: now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	collaborator.Username = user.Username
+	return collaborator, nil
+}
+
+// RemoveCollaborator stops a user contributing to the owner's report. What
+// they already added stays, attributed to them.
+func (s *collaboratorService) RemoveCollaborator(ownerID uint, reportID string, userID uint) error {
+	if _, err := s.ownReport(ownerID, reportID); err != nil {
+		return err
+	}
+	removed, err := s.collaboratorRepo.RemoveCollaborator(reportID, userID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrCollaboratorNotFound
+	}
+	return nil
+}
+
+// Collaborators lists a report's collaborators and their reward shares to
+// the reporter and the collaborators themselves
+func (s *collaboratorService) Collaborators(userID uint, reportID string) ([]models.ReportCollaborator, error) {
+	if err := s.CanContribute(userID, reportID); err != nil {
+		return nil, err
+	}
+	return s.collaboratorRepo.Collaborators(reportID)
+}
+
+// SetRewardShares sets the percentage of the report's points each
+// collaborator receives on approval. Collaborators left out get none.
+func (s *collaboratorService) SetRewardShares(ownerID uint, reportID string, shares map[uint]int) error {
+	if _, err := s.ownReport(ownerID, reportID); err != nil {
+		return err
+	}
+	collaborators, err := s.collaboratorRepo.Collaborators(reportID)
+	if err != nil {
+		return err
+	}
+	known := map[uint]bool{}
+	for _, c := range collaborators {
+		known[c.UserID] = true
+	}
+	total := 0
+	for userID, share := range shares {
+		if !known[userID] {
+			return fmt.Errorf("%w: user %d", ErrCollaboratorNotFound, userID)
+		}
+		if share < 0 || share > 100 {
+			return ErrInvalidRewardShare
+		}
+		total += share
+	}
+	if total > 100 {
+		return ErrInvalidRewardShare
+	}
+	return s.collaboratorRepo.SetRewardShares(reportID, shares)
+}
+
+// CanContribute checks that the user is the report's reporter or one of its
+// collaborators
+func (s *collaboratorService) CanContribute(userID uint, reportID string) error {
+	report, err := s.report(reportID)
+	if err != nil {
+		return err
+	}
+	if report.UserID == userID {
+		return nil
+	}
+	ok, err := s.collaboratorRepo.IsCollaborator(reportID, userID)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotContributor
+	}
+	return nil
+}
+
+// AddUpdate records a text update on the report by the reporter or a
+// collaborator
+func (s *collaboratorService) AddUpdate(userID uint, reportID, text string) (*models.ReportContribution, error) {
+	text = strings.TrimSpace(text)
+	if text == "" || len([]rune(text)) > MaxUpdateLength {
+		return nil, ErrInvalidContribution
+	}
+	if err := s.CanContribute(userID, reportID); err != nil {
+		return nil, err
+	}
+	contributions := []models.ReportContribution{{
+		ReportID:  uuid.MustParse(reportID),
+		UserID:    userID,
+		Kind:      models.ContributionUpdate,
+		Text:      s.textFilter.Mask(text),
+		CreatedAt: time.Now().Unix(),
+	}}
+	if err := s.collaboratorRepo.AddContributions(reportID, contributions, nil); err != nil {
+		return nil, err
+	}
+	return &contributions[0], nil
+}
+
+// AddMedia attributes media already uploaded under the report to the user
+// who added it and appends it to the report's media lists
+func (s *collaboratorService) AddMedia(userID uint, reportID string, media []models.Media) ([]models.ReportContribution, error) {
+	if err := s.CanContribute(userID, reportID); err != nil {
+		return nil, err
+	}
+	report, err := s.report(reportID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	feed, thumbnails, fullsize := []string{report.FeedURLs}, []string{report.ThumbnailURLs}, []string{report.FullSizeURLs}
+	contributions := make([]models.ReportContribution, 0, len(media))
+	for _, m := range media {
+		contributions = append(contributions, models.ReportContribution{
+			ReportID:     report.ID,
+			UserID:       userID,
+			Kind:         models.ContributionMedia,
+			FeedURL:      m.FeedURL,
+			ThumbnailURL: m.ThumbnailURL,
+			FullSizeURL:  m.FullSizeURL,
+			FileType:     m.FileType,
+			CreatedAt:    now,
+		})
+		feed = append(feed, m.FeedURL)
+		thumbnails = append(thumbnails, m.ThumbnailURL)
+		fullsize = append(fullsize, m.FullSizeURL)
+	}
+	changes := map[string]interface{}{
+		"FeedURLs":      joinNonEmpty(",", feed...),
+		"ThumbnailURLs": joinNonEmpty(",", thumbnails...),
+		"FullSizeURLs":  joinNonEmpty(",", fullsize...),
+	}
+	if err := s.collaboratorRepo.AddContributions(reportID, contributions, changes); err != nil {
+		return nil, err
+	}
+	return contributions, nil
+}
+
+func (s *collaboratorService) Contributions(reportID string, page int) ([]models.ReportContribution, error) {
+	if _, err := uuid.Parse(reportID); err != nil {
+		return nil, ErrReportNotFound
+	}
+	return s.collaboratorRepo.Contributions(reportID, page)
+}
+
+// splitPoints divides a report's points between its reporter and its
+// collaborators by their reward shares. Shares are rounded down and the
+// reporter keeps the remainder.
+func splitPoints(points int, ownerID uint, collaborators []models.ReportCollaborator) map[uint]int {
+	split := map[uint]int{}
+	given := 0
+	for _, c := range collaborators {
+		if c.RewardShare <= 0 {
+			continue
+		}
+		share := points * c.RewardShare / 100
+		if share > 0 {
+			split[c.UserID] = share
+			given += share
+		}
+	}
+	split[ownerID] = points - given
+	return split
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/policy"
+	"github.com/techagentng/citizenx/textfilter"
+	"gorm.io/gorm"
+)
+
+// MaxCommentLength bounds the text of a comment on a report
+const MaxCommentLength = 1000
+
+var (
+	// ErrCommentNotFound is returned for comments that do not exist, are on
+	// another report or have been deleted.
+	ErrCommentNotFound = errors.New("comment not found")
+	// ErrInvalidComment is returned for empty or oversized comments.
+	ErrInvalidComment = errors.New("a comment needs between 1 and 1000 characters")
+)
+
+// CommentService runs the discussion under each report. Anyone signed in
+// may comment or reply; authors may edit their comments, and authors and
+// admins may delete them.
+type CommentService interface {
+	AddComment(userID uint, reportID string, request *models.ReportCommentRequest) (*models.ReportComment, error)
+	ListComments(reportID string, page int) ([]models.ReportComment, error)
+	EditComment(userID uint, reportID string, commentID uint, body string) (*models.ReportComment, error)
+	DeleteComment(subject policy.Subject, reportID string, commentID uint) error
+}
+
+type commentService struct {
+	Config       *config.Config
+	commentRepo  db.CommentRepository
+	incidentRepo db.IncidentReportRepository
+	textFilter   *textfilter.Filter
+}
+
+// NewCommentService creates a new instance of CommentService
+func NewCommentService(commentRepo db.CommentRepository, incidentRepo db.IncidentReportRepository, conf *config.Config) CommentService {
+	return &commentService{
+		Config:       conf,
+		commentRepo:  commentRepo,
+		incidentRepo: incidentRepo,
+		textFilter:   textfilter.New(strings.Split(conf.BannedWords, ",")),
+	}
+}
+
+// report returns the report, mapping unknown and malformed IDs to
+// ErrReportNotFound
+func (s *commentService) report(reportID string) (*models.IncidentReport, error) {
+	if _, err := uuid.Parse(reportID); err != nil {
+		return nil, ErrReportNotFound
+	}
+	report, err := s.incidentRepo.GetIncidentReportByID(reportID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrReportNotFound
+	}
+	return report, err
+}
+
+// comment returns a comment on the report that has not been deleted
+func (s *commentService) comment(reportID string, commentID uint) (*models.ReportComment, error) {
+	if _, err := uuid.Parse(reportID); err != nil {
+		return nil, ErrReportNotFound
+	}
+	comment, err := s.commentRepo.GetComment(reportID, commentID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && comment.Deleted) {
+		return nil, ErrCommentNotFound
+	}
+	return comment, err
+}
+
+// commentBody trims and masks the text of a comment
+func (s *commentService) commentBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" || len([]rune(body)) > MaxCommentLength {
+		return "", ErrInvalidComment
+	}
+	return s.textFilter.Mask(body), nil
+}
+
+// AddComment comments on a report, or replies to a comment on it when the
+// request names a parent. A reply to a reply joins the thread of the
+// comment it answers.
+func (s *commentService) AddComment(userID uint, reportID string, request *models.ReportCommentRequest) (*models.ReportComment, error) {
+	body, err := s.commentBody(request.Body)
+	if err != nil {
+		return nil, err
+	}
+	report, err := s.report(reportID)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := &models.ReportComment{
+		ReportID:  report.ID,
+		UserID:    userID,
+		Body:      body,
+		CreatedAt: time.Now().Unix(),
+	}
+	if request.ParentID != nil {
+		parent, err := s.comment(reportID, *request.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		threadID := parent.ID
+		if parent.ParentID != nil {
+			threadID = *parent.ParentID
+		}
+		comment.ParentID = &threadID
+	}
+	if err := s.commentRepo.CreateComment(comment, report.UserID); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+func (s *commentService) ListComments(reportID string, page int) ([]models.ReportComment, error) {
+	if _, err := s.report(reportID); err != nil {
+		return nil, err
+	}
+	return s.commentRepo.ListComments(reportID, page, db.DefaultPageSize)
+}
+
+// EditComment replaces the text of the user's own comment
+func (s *commentService) EditComment(userID uint, reportID string, commentID uint, body string) (*models.ReportComment, error) {
+	body, err := s.commentBody(body)
+	if err != nil {
+		return nil, err
+	}
+	comment, err := s.comment(reportID, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := policy.Authorize(policy.Subject{UserID: userID}, policy.EditComment, policy.Comment(comment)); err != nil {
+		return nil, err
+	}
+
+	comment.Body = body
+	comment.EditedAt = time.Now().Unix()
+	err = s.commentRepo.UpdateComment(comment.ID, comment.Body, comment.EditedAt)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrCommentNotFound
+	}
+	return comment, err
+}
+
+// DeleteComment removes a comment's text, leaving its replies in place
+func (s *commentService) DeleteComment(subject policy.Subject, reportID string, commentID uint) error {
+	comment, err := s.comment(reportID, commentID)
+	if err != nil {
+		return err
+	}
+	if err := policy.Authorize(subject, policy.DeleteComment, policy.Comment(comment)); err != nil {
+		return err
+	}
+	err = s.commentRepo.DeleteComment(comment.ID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrCommentNotFound
+	}
+	return err
+}
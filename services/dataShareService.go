@@ -0,0 +1,191 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrDataShareNotFound is returned for agreements that do not exist or
+	// are already revoked.
+	ErrDataShareNotFound = errors.New("data-sharing agreement not found")
+	// ErrInvalidDataShare is returned for agreements that would already have
+	// expired.
+	ErrInvalidDataShare = errors.New("a data-sharing agreement must expire in the future")
+	// ErrInvalidPartnerKey is returned for API keys that match no agreement.
+	ErrInvalidPartnerKey = errors.New("invalid partner API key")
+	// ErrDataShareExpired is returned for keys of agreements that have
+	// expired or been revoked.
+	ErrDataShareExpired = errors.New("this data-sharing agreement has ended")
+)
+
+// partnerKeyPrefix starts every partner API key, so leaked keys are easy to
+// recognise
+const partnerKeyPrefix = "cxp_"
+
+// DataShareService manages partner data-sharing agreements and exports the
+// reports each one covers
+type DataShareService interface {
+	CreateShare(request *models.DataShareRequest, adminID uint) (*models.DataShare, string, error)
+	ListShares() ([]models.DataShare, error)
+	RevokeShare(id uint) error
+	Authenticate(key string) (*models.DataShare, error)
+	Reports(share *models.DataShare, since, until int64, page, pageSize int) ([]models.SharedReport, error)
+	ExpireShares() (int64, error)
+}
+
+type dataShareService struct {
+	Config        *config.Config
+	dataShareRepo db.DataShareRepository
+}
+
+// NewDataShareService creates a new instance of DataShareService
+func NewDataShareService(dataShareRepo db.DataShareRepository, conf *config.Config) DataShareService {
+	return &dataShareService{
+		Config:        conf,
+		dataShareRepo: dataShareRepo,
+	}
+}
+
+// CreateShare records an agreement and returns the partner's API key, which
+// is not kept and cannot be shown again
+func (s *dataShareService) CreateShare(request *models.DataShareRequest, adminID uint) (*models.DataShare, string, error) {
+	now := time.Now().Unix()
+	if request.ExpiresAt <= now {
+		return nil, "", ErrInvalidDataShare
+	}
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", err
+	}
+	key := partnerKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+	share := &models.DataShare{
+		Partner:    strings.TrimSpace(request.Partner),
+		Contact:    strings.TrimSpace(request.Contact),
+		KeyHash:    hashPartnerKey(key),
+		KeyPrefix:  key[:len(partnerKeyPrefix)+6],
+		States:     lowerAll(request.States),
+		Categories: lowerAll(request.Categories),
+		PIILevel:   request.PIILevel,
+		ExpiresAt:  request.ExpiresAt,
+		CreatedBy:  adminID,
+		CreatedAt:  now,
+	}
+	if err := s.dataShareRepo.CreateShare(share); err != nil {
+		return nil, "", err
+	}
+	return share, key, nil
+}
+
+func (s *dataShareService) ListShares() ([]models.DataShare, error) {
+	return s.dataShareRepo.ListShares()
+}
+
+func (s *dataShareService) RevokeShare(id uint) error {
+	err := s.dataShareRepo.RevokeShare(id, time.Now().Unix())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrDataShareNotFound
+	}
+	return err
+}
+
+// Authenticate finds the agreement in force for a partner API key
+func (s *dataShareService) Authenticate(key string) (*models.DataShare, error) {
+	if !strings.HasPrefix(key, partnerKeyPrefix) {
+		return nil, ErrInvalidPartnerKey
+	}
+	share, err := s.dataShareRepo.FindShareByKeyHash(hashPartnerKey(key))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidPartnerKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().Unix()
+	if !share.Active(now) {
+		return nil, ErrDataShareExpired
+	}
+	if err := s.dataShareRepo.TouchShare(share.ID, now); err != nil {
+		return nil, err
+	}
+	return share, nil
+}
+
+// Reports returns a page of the published reports the agreement covers,
+// created between since and until, with as much of the reporter and
+// location as its PII level allows
+func (s *dataShareService) Reports(share *models.DataShare, since, until int64, page, pageSize int) ([]models.SharedReport, error) {
+	reports, err := s.dataShareRepo.SharedReports(share, since, until, page, pageSize)
+	if err != nil {
+		return nil, err
+	}
+	shared := make([]models.SharedReport, len(reports))
+	for i, report := range reports {
+		shared[i] = models.SharedReport{
+			ID:            report.ID.String(),
+			Category:      report.Category,
+			SubReportType: report.SubReportType,
+			Description:   report.Description,
+			StateName:     report.StateName,
+			LGAName:       report.LGAName,
+			Latitude:      report.Latitude,
+			Longitude:     report.Longitude,
+			Status:        report.ReportStatus,
+			CreatedAt:     report.CreatedAt,
+			ResolvedAt:    report.ResolvedAt,
+		}
+		switch {
+		case share.PIILevel == models.PIILevelNone:
+			scale := math.Pow(10, db.CoarseLocationDecimals)
+			shared[i].Latitude = math.Round(report.Latitude*scale) / scale
+			shared[i].Longitude = math.Round(report.Longitude*scale) / scale
+		case report.UserIsAnonymous:
+		case share.PIILevel == models.PIILevelFull:
+			shared[i].Reporter = report.UserFullname
+			shared[i].ReporterUsername = report.UserUsername
+		default:
+			shared[i].Reporter = reporterPseudonym(share, report.UserID)
+		}
+	}
+	return shared, nil
+}
+
+// ExpireShares revokes the agreements that have run out, returning how many
+func (s *dataShareService) ExpireShares() (int64, error) {
+	return s.dataShareRepo.ExpireShares(time.Now().Unix())
+}
+
+func hashPartnerKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// reporterPseudonym names a reporter the same way in every export of one
+// agreement and differently in any other
+func reporterPseudonym(share *models.DataShare, userID uint) string {
+	sum := sha256.Sum256([]byte(share.KeyHash + "|" + strconv.FormatUint(uint64(userID), 10)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// lowerAll trims and lower-cases values, dropping empty ones
+func lowerAll(values []string) []string {
+	var lowered []string
+	for _, value := range values {
+		if value = strings.ToLower(strings.TrimSpace(value)); value != "" {
+			lowered = append(lowered, value)
+		}
+	}
+	return lowered
+}
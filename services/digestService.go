@@ -0,0 +1,338 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html/template"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/locale"
+	"github.com/techagentng/citizenx/mailingservices"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+const (
+	// MaxDigestSubscriptions is how many digests a user may subscribe to
+	MaxDigestSubscriptions = 10
+	// digestBatchSize is how many due subscriptions are loaded at a time
+	digestBatchSize = 100
+	// digestReportsShown is how many reports a digest lists
+	digestReportsShown = 10
+	// digestExcerptLength is how much of each report's description is shown
+	digestExcerptLength = 160
+)
+
+var (
+	// ErrDigestNotFound is returned for subscriptions that do not exist or
+	// belong to another user.
+	ErrDigestNotFound = errors.New("digest subscription not found")
+	// ErrDigestExists is returned when subscribing to a digest twice.
+	ErrDigestExists = errors.New("you already receive this digest")
+	// ErrTooManyDigests is returned when subscribing past
+	// MaxDigestSubscriptions.
+	ErrTooManyDigests = fmt.Errorf("you can subscribe to at most %d digests", MaxDigestSubscriptions)
+	// ErrInvalidUnsubscribeToken is returned for forged or malformed
+	// unsubscribe links.
+	ErrInvalidUnsubscribeToken = errors.New("this unsubscribe link is not valid")
+)
+
+// DigestService emails users a daily or weekly summary of the new reports
+// in the areas and categories they follow
+type DigestService interface {
+	ListSubscriptions(userID uint) ([]models.DigestSubscription, error)
+	Subscribe(userID uint, sub *models.DigestSubscription) error
+	Unsubscribe(userID, id uint) error
+	CheckUnsubscribeToken(token string) error
+	UnsubscribeByToken(token string) error
+	SendDue() (int, error)
+}
+
+type digestService struct {
+	Config     *config.Config
+	digestRepo db.DigestRepository
+	authRepo   db.AuthRepository
+	mailer     mailingservices.Mailer
+}
+
+// NewDigestService creates a new instance of DigestService. Digests go out
+// at digest_hour, Lagos time, and weekly ones on Mondays.
+func NewDigestService(digestRepo db.DigestRepository, authRepo db.AuthRepository, mailer mailingservices.Mailer, conf *config.Config) DigestService {
+	return &digestService{
+		Config:     conf,
+		digestRepo: digestRepo,
+		authRepo:   authRepo,
+		mailer:     mailer,
+	}
+}
+
+func (s *digestService) ListSubscriptions(userID uint) ([]models.DigestSubscription, error) {
+	return s.digestRepo.ListSubscriptions(userID)
+}
+
+// Subscribe starts a digest of the reports made from now on. Its first
+// email goes out at the next scheduled time.
+func (s *digestService) Subscribe(userID uint, sub *models.DigestSubscription) error {
+	existing, err := s.digestRepo.ListSubscriptions(userID)
+	if err != nil {
+		return err
+	}
+	if len(existing) >= MaxDigestSubscriptions {
+		return ErrTooManyDigests
+	}
+	now := time.Now()
+	sub.ID = 0
+	sub.UserID = userID
+	sub.StateName = strings.TrimSpace(sub.StateName)
+	sub.LGAName = strings.TrimSpace(sub.LGAName)
+	sub.Category = strings.TrimSpace(sub.Category)
+	sub.CoveredUntil = now.Unix()
+	sub.NextSendAt = s.nextDigest(sub.Frequency, now).Unix()
+	sub.LastSentAt = 0
+	sub.CreatedAt = now.Unix()
+	created, err := s.digestRepo.CreateSubscription(sub)
+	if err != nil {
+		return err
+	}
+	if !created {
+		return ErrDigestExists
+	}
+	return nil
+}
+
+func (s *digestService) Unsubscribe(userID, id uint) error {
+	err := s.digestRepo.DeleteSubscription(id, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrDigestNotFound
+	}
+	return err
+}
+
+// CheckUnsubscribeToken returns ErrInvalidUnsubscribeToken unless token is
+// an unsubscribe link this service made
+func (s *digestService) CheckUnsubscribeToken(token string) error {
+	_, _, err := s.parseUnsubscribeToken(token)
+	return err
+}
+
+// UnsubscribeByToken ends the subscription an email's unsubscribe link was
+// made for, without signing in. Ending one already ended is not an error.
+func (s *digestService) UnsubscribeByToken(token string) error {
+	id, userID, err := s.parseUnsubscribeToken(token)
+	if err != nil {
+		return err
+	}
+	err = s.digestRepo.DeleteSubscription(id, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	return err
+}
+
+func (s *digestService) parseUnsubscribeToken(token string) (id, userID uint, err error) {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 {
+		return 0, 0, ErrInvalidUnsubscribeToken
+	}
+	parsedID, errID := strconv.ParseUint(parts[0], 10, 64)
+	parsedUser, errUser := strconv.ParseUint(parts[1], 10, 64)
+	if errID != nil || errUser != nil ||
+		!hmac.Equal([]byte(parts[2]), []byte(s.signUnsubscribe(uint(parsedID), uint(parsedUser)))) {
+		return 0, 0, ErrInvalidUnsubscribeToken
+	}
+	return uint(parsedID), uint(parsedUser), nil
+}
+
+func (s *digestService) unsubscribeToken(sub *models.DigestSubscription) string {
+	return fmt.Sprintf("%d.%d.%s", sub.ID, sub.UserID, s.signUnsubscribe(sub.ID, sub.UserID))
+}
+
+func (s *digestService) signUnsubscribe(id, userID uint) string {
+	mac := hmac.New(sha256.New, []byte(s.Config.JWTSecret))
+	fmt.Fprintf(mac, "digest-unsubscribe|%d|%d", id, userID)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// nextDigest returns when a digest sent with frequency is next due after t:
+// the next digest_hour in Lagos, on a Monday for weekly digests
+func (s *digestService) nextDigest(frequency string, t time.Time) time.Time {
+	local := t.In(locale.TimeZone)
+	next := time.Date(local.Year(), local.Month(), local.Day(), s.Config.DigestHour, 0, 0, 0, locale.TimeZone)
+	for !next.After(t) || (frequency == models.DigestWeekly && next.Weekday() != time.Monday) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// SendDue emails every digest that is due, covering the reports made since
+// the last one, and returns how many were sent. Periods with no new reports
+// are skipped without an email. A digest that fails to send is tried again
+// on the next run.
+func (s *digestService) SendDue() (int, error) {
+	now := time.Now()
+	sent := 0
+	var afterID uint
+	for {
+		subs, err := s.digestRepo.DueSubscriptions(now.Unix(), afterID, digestBatchSize)
+		if err != nil {
+			return sent, err
+		}
+		if len(subs) == 0 {
+			return sent, nil
+		}
+		for i := range subs {
+			sub := &subs[i]
+			mailed, err := s.send(sub, now)
+			if err != nil {
+				log.Printf("sending digest %d to user %d: %v", sub.ID, sub.UserID, err)
+				continue
+			}
+			if mailed {
+				sent++
+			}
+			if err := s.digestRepo.MarkSent(sub.ID, now.Unix(), s.nextDigest(sub.Frequency, now).Unix(), now.Unix()); err != nil {
+				return sent, err
+			}
+		}
+		afterID = subs[len(subs)-1].ID
+	}
+}
+
+// send emails one digest, reporting false when there was nothing to send
+func (s *digestService) send(sub *models.DigestSubscription, now time.Time) (bool, error) {
+	reports, total, err := s.digestRepo.DigestReports(sub, now.Unix(), digestReportsShown)
+	if err != nil || total == 0 {
+		return false, err
+	}
+	user, err := s.authRepo.FindUserByID(sub.UserID)
+	if err != nil {
+		return false, err
+	}
+
+	base := strings.TrimRight(s.Config.BaseUrl, "/")
+	unsubscribe := base + "/api/v1/digests/unsubscribe?token=" + url.QueryEscape(s.unsubscribeToken(sub))
+	format := locale.For(user.Locale)
+	digest := digestEmail{
+		Title:       digestTitle(sub, total),
+		Period:      format.Date(time.Unix(sub.CoveredUntil, 0)) + " to " + format.Date(now),
+		More:        total - int64(len(reports)),
+		Unsubscribe: unsubscribe,
+	}
+	for _, report := range reports {
+		digest.Reports = append(digest.Reports, digestReport{
+			Category: report.Category,
+			Place:    strings.Join(nonEmpty(report.LGAName, report.StateName), ", "),
+			Date:     format.Unix(report.CreatedAt),
+			Excerpt:  excerpt(report.Description, digestExcerptLength),
+			Link:     base + "/reports/" + report.ID.String(),
+		})
+	}
+
+	var text, html bytes.Buffer
+	if err := digestText.Execute(&text, digest); err != nil {
+		return false, err
+	}
+	if err := digestHTML.Execute(&html, digest); err != nil {
+		return false, err
+	}
+	_, err = s.mailer.SendHTMLMessage(user.Email, "CitizenX: "+digest.Title, text.String(), html.String(), map[string]string{
+		"List-Unsubscribe":      "<" + unsubscribe + ">",
+		"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+	})
+	return err == nil, err
+}
+
+// digestTitle names what a digest covers, such as "12 new Roads reports in
+// Ikeja, Lagos"
+func digestTitle(sub *models.DigestSubscription, total int64) string {
+	noun := "reports"
+	if total == 1 {
+		noun = "report"
+	}
+	if sub.Category != "" {
+		noun = sub.Category + " " + noun
+	}
+	title := fmt.Sprintf("%d new %s", total, noun)
+	if place := nonEmpty(sub.LGAName, sub.StateName); len(place) > 0 {
+		title += " in " + strings.Join(place, ", ")
+	}
+	return title
+}
+
+func nonEmpty(values ...string) []string {
+	var kept []string
+	for _, value := range values {
+		if value != "" {
+			kept = append(kept, value)
+		}
+	}
+	return kept
+}
+
+// excerpt shortens text to at most n characters, at a word boundary
+func excerpt(text string, n int) string {
+	text = strings.Join(strings.Fields(text), " ")
+	runes := []rune(text)
+	if len(runes) <= n {
+		return text
+	}
+	cut := string(runes[:n])
+	if space := strings.LastIndex(cut, " "); space > n/2 {
+		cut = cut[:space]
+	}
+	return cut + "…"
+}
+
+type digestEmail struct {
+	Title       string
+	Period      string
+	Reports     []digestReport
+	More        int64
+	Unsubscribe string
+}
+
+type digestReport struct {
+	Category, Place, Date, Excerpt, Link string
+}
+
+var digestHTML = template.Must(template.New("digest").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: Arial, sans-serif; color: #222; max-width: 600px; margin: 0 auto;">
+<h2 style="color: #0b6e4f;">{{.Title}}</h2>
+<p style="color: #666;">{{.Period}}</p>
+{{range .Reports}}<div style="border-top: 1px solid #eee; padding: 12px 0;">
+<strong>{{.Category}}</strong>{{if .Place}} &middot; {{.Place}}{{end}} &middot; {{.Date}}
+<p style="margin: 6px 0;">{{.Excerpt}}</p>
+<a href="{{.Link}}" style="color: #0b6e4f;">View report</a>
+</div>
+{{end}}{{if .More}}<p>And {{.More}} more on CitizenX.</p>
+{{end}}<p style="font-size: 12px; color: #999; border-top: 1px solid #eee; padding-top: 12px;">
+You receive this digest because you subscribed to it on CitizenX.
+<a href="{{.Unsubscribe}}" style="color: #999;">Unsubscribe</a>
+</p>
+</body>
+</html>
+`))
+
+var digestText = texttemplate.Must(texttemplate.New("digest").Parse(`{{.Title}}
+{{.Period}}
+{{range .Reports}}
+{{.Category}}{{if .Place}} - {{.Place}}{{end}} - {{.Date}}
+{{.Excerpt}}
+{{.Link}}
+{{end}}{{if .More}}
+And {{.More}} more on CitizenX.
+{{end}}
+To stop receiving this digest, visit {{.Unsubscribe}}
+`))
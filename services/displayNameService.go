@@ -0,0 +1,272 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/textfilter"
+	"gorm.io/gorm"
+)
+
+// Rules of the display-name policy
+const (
+	DisplayNameReserved  = "reserved"
+	DisplayNameOfficial  = "official"
+	DisplayNameProfanity = "profanity"
+)
+
+// MaxAppealMessageLength bounds what a user writes in support of an appeal
+const MaxAppealMessageLength = 1000
+
+var (
+	// ErrDisplayNameRejected is matched by every *DisplayNameError.
+	ErrDisplayNameRejected = errors.New("display name not allowed")
+	// ErrDisplayNameAllowed is returned when appealing a name the policy
+	// already allows.
+	ErrDisplayNameAllowed = errors.New("this name is allowed; set it on your profile instead")
+	// ErrAppealPending is returned when the user already has an appeal for
+	// the same field awaiting review.
+	ErrAppealPending = errors.New("an appeal for this name is already awaiting review")
+	// ErrAppealNotFound is returned for appeals that do not exist.
+	ErrAppealNotFound = errors.New("appeal not found")
+	// ErrAppealReviewed is returned when reviewing an appeal that was already
+	// decided.
+	ErrAppealReviewed = errors.New("appeal has already been reviewed")
+	// ErrInvalidAppeal is returned for appeals of unknown fields or with an
+	// oversized message.
+	ErrInvalidAppeal = errors.New("invalid appeal")
+)
+
+// DisplayNameError explains why the display-name policy rejected a name
+type DisplayNameError struct {
+	Field string `json:"field"`
+	Name  string `json:"name"`
+	Rule  string `json:"rule"`
+}
+
+func (e *DisplayNameError) Error() string {
+	switch e.Rule {
+	case DisplayNameReserved:
+		return fmt.Sprintf("%s %q is reserved", e.Field, e.Name)
+	case DisplayNameOfficial:
+		return fmt.Sprintf("%s %q looks like the name of a public official or agency", e.Field, e.Name)
+	default:
+		return fmt.Sprintf("%s %q contains language that is not allowed", e.Field, e.Name)
+	}
+}
+
+func (e *DisplayNameError) Is(target error) bool {
+	return target == ErrDisplayNameRejected
+}
+
+// leet maps the digits and symbols commonly swapped for letters back to the
+// letters, so "4dm1n" reads as "admin"
+var leet = map[rune]rune{'0': 'o', '1': 'i', '3': 'e', '4': 'a', '5': 's', '7': 't', '@': 'a', '$': 's', '!': 'i'}
+
+// minPrefixLength is the shortest reserved word or title that also rejects
+// names starting with it, such as "adminjohn"; shorter words would catch
+// too many ordinary names
+const minPrefixLength = 5
+
+// DisplayNamePolicy decides which public names users may go by: no reserved
+// words, nothing passing for a public official, and no profanity.
+type DisplayNamePolicy struct {
+	reserved []string
+	titles   [][]string
+	filter   *textfilter.Filter
+}
+
+// NewDisplayNamePolicy builds the policy from reserved_display_names,
+// official_titles and banned_words
+func NewDisplayNamePolicy(conf *config.Config) *DisplayNamePolicy {
+	p := &DisplayNamePolicy{filter: textfilter.New(strings.Split(conf.BannedWords, ","))}
+	for _, word := range strings.Split(conf.ReservedDisplayNames, ",") {
+		if tokens := nameTokens(word); len(tokens) > 0 {
+			p.reserved = append(p.reserved, strings.Join(tokens, ""))
+		}
+	}
+	for _, title := range strings.Split(conf.OfficialTitles, ",") {
+		if tokens := nameTokens(title); len(tokens) > 0 {
+			p.titles = append(p.titles, tokens)
+		}
+	}
+	return p
+}
+
+// nameTokens lower-cases name, undoes letter substitutions and splits it
+// into words at anything that is not a letter
+func nameTokens(name string) []string {
+	mapped := strings.Map(func(r rune) rune {
+		if l, ok := leet[r]; ok {
+			return l
+		}
+		return unicode.ToLower(r)
+	}, name)
+	return strings.FieldsFunc(mapped, func(r rune) bool { return !unicode.IsLetter(r) })
+}
+
+// Check returns a *DisplayNameError when name may not be used for field
+func (p *DisplayNamePolicy) Check(field, name string) error {
+	tokens := nameTokens(name)
+	compact := strings.Join(tokens, "")
+	if compact == "" {
+		return nil
+	}
+	reject := func(rule string) error {
+		return &DisplayNameError{Field: field, Name: name, Rule: rule}
+	}
+
+	for _, word := range p.reserved {
+		if compact == word || containsToken(tokens, word) ||
+			(len(word) >= minPrefixLength && strings.HasPrefix(compact, word)) {
+			return reject(DisplayNameReserved)
+		}
+	}
+	for _, title := range p.titles {
+		joined := strings.Join(title, "")
+		if containsSequence(tokens, title) ||
+			(len(joined) >= minPrefixLength && strings.HasPrefix(compact, joined)) {
+			return reject(DisplayNameOfficial)
+		}
+	}
+	if p.filter.HasBanned(strings.Join(tokens, " ")) || p.filter.HasBanned(compact) {
+		return reject(DisplayNameProfanity)
+	}
+	return nil
+}
+
+func containsToken(tokens []string, word string) bool {
+	for _, token := range tokens {
+		if token == word {
+			return true
+		}
+	}
+	return false
+}
+
+// containsSequence reports whether seq appears in tokens as consecutive words
+func containsSequence(tokens, seq []string) bool {
+	for i := 0; i+len(seq) <= len(tokens); i++ {
+		match := true
+		for j := range seq {
+			if tokens[i+j] != seq[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+// DisplayNameService applies the display-name policy and runs the queue of
+// appeals against it, where admins can allow a rejected name
+type DisplayNameService interface {
+	Check(field, name string) error
+	Appeal(userID uint, field, name, message string) (*models.DisplayNameAppeal, error)
+	UserAppeals(userID uint) ([]models.DisplayNameAppeal, error)
+	ListAppeals(status string, page int) ([]models.DisplayNameAppeal, error)
+	ReviewAppeal(adminID, appealID uint, approve bool, note string) error
+}
+
+type displayNameService struct {
+	Config          *config.Config
+	displayNameRepo db.DisplayNameRepository
+	policy          *DisplayNamePolicy
+}
+
+// NewDisplayNameService creates a new instance of DisplayNameService
+func NewDisplayNameService(displayNameRepo db.DisplayNameRepository, conf *config.Config) DisplayNameService {
+	return &displayNameService{
+		Config:          conf,
+		displayNameRepo: displayNameRepo,
+		policy:          NewDisplayNamePolicy(conf),
+	}
+}
+
+func (s *displayNameService) Check(field, name string) error {
+	return s.policy.Check(field, name)
+}
+
+// Appeal queues a rejected name for an admin to review
+func (s *displayNameService) Appeal(userID uint, field, name, message string) (*models.DisplayNameAppeal, error) {
+	name, message = strings.TrimSpace(name), strings.TrimSpace(message)
+	if field != models.DisplayNameUsername && field != models.DisplayNameFullname {
+		return nil, fmt.Errorf("%w: field must be %s or %s", ErrInvalidAppeal, models.DisplayNameUsername, models.DisplayNameFullname)
+	}
+	if len([]rune(message)) > MaxAppealMessageLength {
+		return nil, fmt.Errorf("%w: the message can be at most %d characters", ErrInvalidAppeal, MaxAppealMessageLength)
+	}
+	var rejected *DisplayNameError
+	if err := s.policy.Check(field, name); !errors.As(err, &rejected) {
+		return nil, ErrDisplayNameAllowed
+	}
+	pending, err := s.displayNameRepo.HasPendingAppeal(userID, field)
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return nil, ErrAppealPending
+	}
+
+	appeal := &models.DisplayNameAppeal{
+		UserID:    userID,
+		Field:     field,
+		Name:      name,
+		Rule:      rejected.Rule,
+		Message:   message,
+		Status:    models.AppealPending,
+		CreatedAt: time.Now().Unix(),
+	}
+	if err := s.displayNameRepo.CreateAppeal(appeal); err != nil {
+		return nil, err
+	}
+	return appeal, nil
+}
+
+func (s *displayNameService) UserAppeals(userID uint) ([]models.DisplayNameAppeal, error) {
+	return s.displayNameRepo.UserAppeals(userID)
+}
+
+func (s *displayNameService) ListAppeals(status string, page int) ([]models.DisplayNameAppeal, error) {
+	return s.displayNameRepo.ListAppeals(status, page)
+}
+
+// ReviewAppeal decides an appeal. Approving it sets the name on the user's
+// profile despite the policy.
+func (s *displayNameService) ReviewAppeal(adminID, appealID uint, approve bool, note string) error {
+	appeal, err := s.displayNameRepo.GetAppeal(appealID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrAppealNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if appeal.Status != models.AppealPending {
+		return ErrAppealReviewed
+	}
+
+	appeal.Status = models.AppealRejected
+	if approve {
+		appeal.Status = models.AppealApproved
+	}
+	appeal.ReviewedBy = &adminID
+	appeal.ReviewNote = strings.TrimSpace(note)
+	appeal.ReviewedAt = time.Now().Unix()
+	reviewed, err := s.displayNameRepo.ReviewAppeal(appeal)
+	if err != nil {
+		return err
+	}
+	if !reviewed {
+		return ErrAppealReviewed
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/locale"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+// ReportDuplicates are the reports that look like duplicates of a report:
+// the others in its cluster or, when it is only flagged, the reports it
+// could not be told apart from
+type ReportDuplicates struct {
+	Report  *models.IncidentReport
+	Cluster *models.ReportCluster
+	Reports []models.IncidentReport
+}
+
+// MaxHistoryExamples is how many earlier reports a report's history shows
+const MaxHistoryExamples = 5
+
+// ReportHistoryContext is how often the thing a report describes was
+// reported before at the same place, with the latest of those reports
+type ReportHistoryContext struct {
+	Report   *models.IncidentReport
+	History  *db.ReportHistory
+	RadiusM  int
+	Examples []models.IncidentReport
+	Summary  string
+}
+
+//go:generate mockgen -destination=../mocks/duplicate_mock.go -package=mocks github.com/techagentng/citizenx/services DuplicateService
+
+// DuplicateService spots reports that describe something already reported,
+// so moderators and agencies can handle them together
+type DuplicateService interface {
+	Detect(report *models.IncidentReport) (*models.ReportCluster, error)
+	Duplicates(reportID string) (*ReportDuplicates, error)
+	HistoryContext(reportID string) (*ReportHistoryContext, error)
+}
+
+type duplicateService struct {
+	Config       *config.Config
+	clusterRepo  db.ReportClusterRepository
+	incidentRepo db.IncidentReportRepository
+}
+
+// NewDuplicateService creates a new instance of DuplicateService
+func NewDuplicateService(clusterRepo db.ReportClusterRepository, incidentRepo db.IncidentReportRepository, conf *config.Config) DuplicateService {
+	return &duplicateService{
+		Config:       conf,
+		clusterRepo:  clusterRepo,
+		incidentRepo: incidentRepo,
+	}
+}
+
+func (s *duplicateService) rule() db.DuplicateRule {
+	return db.DuplicateRule{
+		Window:  time.Duration(s.Config.DuplicateWindowHours) * time.Hour,
+		RadiusM: float64(s.Config.DuplicateRadiusM),
+	}
+}
+
+// Detect compares a newly saved report with the reports before it, linking
+// it to the cluster of the one it duplicates or flagging it
+func (s *duplicateService) Detect(report *models.IncidentReport) (*models.ReportCluster, error) {
+	if s.Config.DuplicateWindowHours <= 0 {
+		return nil, nil
+	}
+	return s.clusterRepo.LinkDuplicates(report, s.rule())
+}
+
+func (s *duplicateService) Duplicates(reportID string) (*ReportDuplicates, error) {
+	report, err := s.incidentRepo.GetIncidentReportByID(reportID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrReportNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	duplicates := &ReportDuplicates{Report: report, Reports: []models.IncidentReport{}}
+
+	switch {
+	case report.ClusterID != nil:
+		if duplicates.Cluster, err = s.clusterRepo.GetCluster(*report.ClusterID); err != nil {
+			return nil, err
+		}
+		reports, err := s.clusterRepo.ClusterReports(*report.ClusterID)
+		if err != nil {
+			return nil, err
+		}
+		for _, other := range reports {
+			if other.ID != report.ID {
+				duplicates.Reports = append(duplicates.Reports, other)
+			}
+		}
+	case report.PossibleDuplicate:
+		if duplicates.Reports, err = s.clusterRepo.FindDuplicates(report, s.rule()); err != nil {
+			return nil, err
+		}
+	}
+	return duplicates, nil
+}
+
+// HistoryContext looks up the reports of the same category made before a
+// report at the same place, so readers can tell a one-off from a recurring
+// fault
+func (s *duplicateService) HistoryContext(reportID string) (*ReportHistoryContext, error) {
+	report, err := s.incidentRepo.GetIncidentReportByID(reportID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrReportNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	radius := s.Config.HistoryRadiusM
+	if report.Latitude == 0 && report.Longitude == 0 {
+		radius = 0
+	}
+	history, examples, err := s.clusterRepo.History(report, float64(radius), MaxHistoryExamples, db.ReportCard()...)
+	if err != nil {
+		return nil, err
+	}
+	return &ReportHistoryContext{
+		Report:   report,
+		History:  history,
+		RadiusM:  radius,
+		Examples: examples,
+		Summary:  historySummary(report, history, radius),
+	}, nil
+}
+
+// historySummary puts a report's history in a sentence such as "Power
+// outage has been reported 7 times within 100m since 2023"
+func historySummary(report *models.IncidentReport, history *db.ReportHistory, radiusM int) string {
+	place := fmt.Sprintf("within %dm", radiusM)
+	if radiusM == 0 {
+		place = "in " + report.LGAName
+	}
+	if history.Reports == 0 {
+		return fmt.Sprintf("%s has not been reported %s before", report.Category, place)
+	}
+	times := "once"
+	if history.Reports > 1 {
+		times = fmt.Sprintf("%d times", history.Reports)
+	}
+	since := time.Unix(history.FirstReportAt, 0).In(locale.TimeZone).Year()
+	return fmt.Sprintf("%s has been reported %s %s since %d", report.Category, times, place, since)
+}
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/events"
+	"github.com/techagentng/citizenx/evidence"
+	"github.com/techagentng/citizenx/httpclient"
+	"github.com/techagentng/citizenx/models"
+)
+
+var (
+	// ErrEvidenceDisabled is returned when no evidence signing key is configured.
+	ErrEvidenceDisabled = errors.New("evidence export is not configured")
+	// ErrReportNotFound is returned for reports that do not exist.
+	ErrReportNotFound = errors.New("report not found")
+	// ErrReportNotVerified is returned when exporting a report that has not been verified.
+	ErrReportNotVerified = errors.New("only verified reports can be exported as evidence")
+)
+
+// mediaFetchTimeout bounds downloading one original from storage
+const mediaFetchTimeout = time.Minute
+
+// EvidenceService packages verified reports into signed evidence bundles
+type EvidenceService interface {
+	ExportBundle(reportID string, exportedBy uint) ([]byte, error)
+	PublicKey() (ed25519.PublicKey, error)
+}
+
+type evidenceService struct {
+	Config       *config.Config
+	incidentRepo db.IncidentReportRepository
+	mediaRepo    db.MediaRepository
+	statusRepo   db.ReportStatusRepository
+	auditRepo    db.ReportAuditRepository
+	objects      ObjectService
+	signingKey   ed25519.PrivateKey
+	client       *http.Client
+}
+
+// NewEvidenceService creates a new instance of EvidenceService. Exports are
+// refused until an evidence signing key is configured.
+func NewEvidenceService(incidentRepo db.IncidentReportRepository, mediaRepo db.MediaRepository, statusRepo db.ReportStatusRepository, auditRepo db.ReportAuditRepository, objects ObjectService, conf *config.Config) (EvidenceService, error) {
+	s := &evidenceService{
+		Config:       conf,
+		incidentRepo: incidentRepo,
+		mediaRepo:    mediaRepo,
+		statusRepo:   statusRepo,
+		auditRepo:    auditRepo,
+		objects:      objects,
+		client:       httpclient.New("evidence-media", httpclient.Options{Timeout: mediaFetchTimeout}),
+	}
+	if conf.EvidenceSigningKey != "" {
+		key, err := evidence.ParseKey(conf.EvidenceSigningKey)
+		if err != nil {
+			return nil, err
+		}
+		s.signingKey = key
+	}
+	return s, nil
+}
+
+// evidenceMedia describes one original in the bundle's metadata
+type evidenceMedia struct {
+	ID       string `json:"id"`
+	File     string `json:"file"`
+	FileType string `json:"file_type"`
+	SHA256   string `json:"sha256"`
+	// RecordedSHA256 is the digest taken at upload; a mismatch means the
+	// stored original changed since
+	RecordedSHA256 string `json:"recorded_sha256,omitempty"`
+	Integrity      string `json:"integrity"`
+	WatermarkCode  string `json:"watermark_code,omitempty"`
+}
+
+// statusEvent is one entry of the bundle's status history
+type statusEvent struct {
+	Event      string      `json:"event"`
+	RecordedAt time.Time   `json:"recorded_at"`
+	Details    interface{} `json:"details"`
+}
+
+// ExportBundle builds the signed evidence ZIP of a verified report
+func (s *evidenceService) ExportBundle(reportID string, exportedBy uint) ([]byte, error) {
+	if s.signingKey == nil {
+		return nil, ErrEvidenceDisabled
+	}
+
+	reports, err := s.incidentRepo.GetReportsByIDs([]string{reportID})
+	if err != nil {
+		return nil, err
+	}
+	if len(reports) == 0 {
+		return nil, ErrReportNotFound
+	}
+	report := reports[0]
+	if !models.IsPublishedReportStatus(report.ReportStatus) {
+		return nil, ErrReportNotVerified
+	}
+
+	media, err := s.mediaRepo.GetMediaByReportID(reportID)
+	if err != nil {
+		return nil, fmt.Errorf("loading media: %w", err)
+	}
+	statuses, err := s.statusHistory(report)
+	if err != nil {
+		return nil, fmt.Errorf("loading status history: %w", err)
+	}
+
+	var buf bytes.Buffer
+	bundle := evidence.NewWriter(&buf, reportID, exportedBy, time.Now())
+
+	described := make([]evidenceMedia, 0, len(media))
+	for _, m := range media {
+		data, err := s.fetchOriginal(m)
+		if err != nil {
+			return nil, fmt.Errorf("fetching media %s: %w", m.ID, err)
+		}
+		name := "media/" + m.ID + path.Ext(originalLocation(m))
+		if err := bundle.Add(name, data); err != nil {
+			return nil, err
+		}
+
+		sum := sha256.Sum256(data)
+		entry := evidenceMedia{
+			ID:             m.ID,
+			File:           name,
+			FileType:       m.FileType,
+			SHA256:         hex.EncodeToString(sum[:]),
+			RecordedSHA256: m.SHA256,
+			WatermarkCode:  m.WatermarkCode,
+		}
+		switch {
+		case m.SHA256 == "":
+			entry.Integrity = "unrecorded"
+		case m.SHA256 == entry.SHA256:
+			entry.Integrity = "match"
+		default:
+			entry.Integrity = "mismatch"
+			log.Printf("evidence export: media %s of report %s no longer matches its upload digest", m.ID, reportID)
+		}
+		described = append(described, entry)
+	}
+
+	if err := bundle.AddJSON("metadata.json", map[string]interface{}{
+		"report": report,
+		"media":  described,
+	}); err != nil {
+		return nil, err
+	}
+	if err := bundle.AddJSON("status_history.json", statuses); err != nil {
+		return nil, err
+	}
+	if err := bundle.Close(s.signingKey); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// statusHistory rebuilds the report's history from its submission, status
+// transitions and audit, which are kept for the life of the report
+func (s *evidenceService) statusHistory(report models.IncidentReport) ([]statusEvent, error) {
+	transitions, err := s.statusRepo.ListTransitions(report.ID.String())
+	if err != nil {
+		return nil, err
+	}
+	audit, err := s.auditRepo.FindReportAudit(report.ID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	history := []statusEvent{{
+		Event:      events.ReportCreatedEvent,
+		RecordedAt: time.Unix(report.CreatedAt, 0).UTC(),
+		Details:    map[string]interface{}{"report_id": report.ID, "status": models.ReportStatusPending},
+	}}
+	for _, t := range transitions {
+		history = append(history, statusEvent{
+			Event:      events.StatusChangedEvent,
+			RecordedAt: time.Unix(t.CreatedAt, 0).UTC(),
+			Details:    t,
+		})
+	}
+	if audit != nil {
+		history = append(history, statusEvent{
+			Event:      "report.audit_sampled",
+			RecordedAt: time.Unix(audit.SampledAt, 0).UTC(),
+			Details:    map[string]interface{}{"audit_id": audit.ID},
+		})
+		if audit.AuditedAt > 0 {
+			history = append(history, statusEvent{
+				Event:      "report.audited",
+				RecordedAt: time.Unix(audit.AuditedAt, 0).UTC(),
+				Details:    audit,
+			})
+		}
+	}
+	sort.SliceStable(history, func(i, j int) bool { return history[i].RecordedAt.Before(history[j].RecordedAt) })
+	return history, nil
+}
+
+// PublicKey returns the key recipients verify bundle signatures with
+func (s *evidenceService) PublicKey() (ed25519.PublicKey, error) {
+	if s.signingKey == nil {
+		return nil, ErrEvidenceDisabled
+	}
+	return s.signingKey.Public().(ed25519.PublicKey), nil
+}
+
+// originalLocation returns where the unmodified upload is stored. Restricted
+// originals are private objects; otherwise the feed URL points at the
+// original in object storage, and older records only have the local full
+// size copy.
+func originalLocation(m models.Media) string {
+	if m.OriginalKey != "" {
+		return m.OriginalKey
+	}
+	if m.FeedURL != "" {
+		return m.FeedURL
+	}
+	return m.FullSizeURL
+}
+
+func (s *evidenceService) fetchOriginal(m models.Media) ([]byte, error) {
+	if m.OriginalKey != "" {
+		object, err := s.objects.Open(m.OriginalKey)
+		if err != nil {
+			return nil, err
+		}
+		defer object.Close()
+		return io.ReadAll(object)
+	}
+	location := originalLocation(m)
+	if location == "" {
+		return nil, errors.New("media has no stored original")
+	}
+	return readStoredMedia(s.client, location)
+}
@@ -0,0 +1,226 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/httpclient"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+// ErrFeedbackNotFound is returned for feedback that does not exist.
+var ErrFeedbackNotFound = errors.New("feedback not found")
+
+const (
+	// feedbackForwardAttempts is how often forwarding one piece of feedback
+	// is tried before it is left for an admin to look at
+	feedbackForwardAttempts = 5
+	feedbackForwardBatch    = 50
+	feedbackForwardTimeout  = 10 * time.Second
+)
+
+// FeedbackScreenshot is a screenshot sent with feedback
+type FeedbackScreenshot struct {
+	Body        io.Reader
+	Size        int64
+	ContentType string
+	Filename    string
+}
+
+// FeedbackService takes bug reports and suggestions about the app, lists
+// them for triage and forwards them to the issue tracker when one is
+// configured
+type FeedbackService interface {
+	Submit(userID uint, request *models.FeedbackRequest, screenshot *FeedbackScreenshot) (*models.Feedback, error)
+	List(status, category string, page int) ([]models.Feedback, error)
+	Get(id uint) (*models.Feedback, error)
+	Triage(id uint, triage *models.FeedbackTriage, adminID uint) error
+	Forward() (int, error)
+}
+
+type feedbackService struct {
+	Config       *config.Config
+	feedbackRepo db.FeedbackRepository
+	objects      ObjectService
+	client       *http.Client
+}
+
+// NewFeedbackService creates a new instance of FeedbackService
+func NewFeedbackService(feedbackRepo db.FeedbackRepository, objects ObjectService, conf *config.Config) FeedbackService {
+	return &feedbackService{
+		Config:       conf,
+		feedbackRepo: feedbackRepo,
+		objects:      objects,
+		client:       httpclient.New("feedback-webhook", httpclient.Options{Timeout: feedbackForwardTimeout}),
+	}
+}
+
+// Submit saves feedback and its screenshot, which is stored privately since
+// it may show the user's own data
+func (s *feedbackService) Submit(userID uint, request *models.FeedbackRequest, screenshot *FeedbackScreenshot) (*models.Feedback, error) {
+	now := time.Now().Unix()
+	feedback := &models.Feedback{
+		UserID:      userID,
+		Category:    request.Category,
+		Text:        strings.TrimSpace(request.Text),
+		AppVersion:  strings.TrimSpace(request.AppVersion),
+		Platform:    strings.ToLower(strings.TrimSpace(request.Platform)),
+		OSVersion:   strings.TrimSpace(request.OSVersion),
+		DeviceModel: strings.TrimSpace(request.DeviceModel),
+		Status:      models.FeedbackNew,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.feedbackRepo.CreateFeedback(feedback); err != nil {
+		return nil, err
+	}
+	if screenshot == nil {
+		return feedback, nil
+	}
+
+	id := strconv.FormatUint(uint64(feedback.ID), 10)
+	key := "feedback/" + id + "/" + generateUniqueFilename(strings.ToLower(filepath.Ext(screenshot.Filename)))
+	if err := s.objects.PutPrivate(key, screenshot.Body, screenshot.Size, screenshot.ContentType, models.ObjectOwnerFeedback, id); err != nil {
+		return nil, err
+	}
+	if err := s.feedbackRepo.SetScreenshot(feedback.ID, key); err != nil {
+		return nil, err
+	}
+	feedback.ScreenshotKey = key
+	return feedback, nil
+}
+
+func (s *feedbackService) List(status, category string, page int) ([]models.Feedback, error) {
+	feedback, err := s.feedbackRepo.ListFeedback(status, category, page)
+	if err != nil {
+		return nil, err
+	}
+	for i := range feedback {
+		s.signScreenshot(&feedback[i])
+	}
+	return feedback, nil
+}
+
+func (s *feedbackService) Get(id uint) (*models.Feedback, error) {
+	feedback, err := s.feedbackRepo.GetFeedback(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrFeedbackNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.signScreenshot(feedback)
+	return feedback, nil
+}
+
+func (s *feedbackService) Triage(id uint, triage *models.FeedbackTriage, adminID uint) error {
+	err := s.feedbackRepo.TriageFeedback(id, triage.Status, strings.TrimSpace(triage.Note), adminID, time.Now().Unix())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrFeedbackNotFound
+	}
+	return err
+}
+
+// Forward posts feedback not yet sent to feedback_webhook_url, returning how
+// many were sent. It does nothing when no webhook is configured.
+func (s *feedbackService) Forward() (int, error) {
+	if s.Config.FeedbackWebhookURL == "" {
+		return 0, nil
+	}
+	pending, err := s.feedbackRepo.UnforwardedFeedback(feedbackForwardAttempts, feedbackForwardBatch)
+	if err != nil {
+		return 0, err
+	}
+	forwarded := 0
+	for i := range pending {
+		feedback := &pending[i]
+		s.signScreenshot(feedback)
+		if err := s.post(feedback); err != nil {
+			log.Printf("forwarding feedback %d: %v", feedback.ID, err)
+			if err := s.feedbackRepo.RecordForwardFailure(feedback.ID, err.Error()); err != nil {
+				return forwarded, err
+			}
+			continue
+		}
+		if err := s.feedbackRepo.MarkForwarded(feedback.ID, time.Now().Unix()); err != nil {
+			return forwarded, err
+		}
+		forwarded++
+	}
+	return forwarded, nil
+}
+
+// post sends feedback to the webhook as an issue: a title, a body with the
+// device details and labels, a shape most trackers' incoming webhooks take
+func (s *feedbackService) post(feedback *models.Feedback) error {
+	title := feedback.Text
+	if line := strings.IndexByte(title, '\n'); line >= 0 {
+		title = title[:line]
+	}
+	if len([]rune(title)) > 80 {
+		title = string([]rune(title)[:79]) + "…"
+	}
+	var body strings.Builder
+	body.WriteString(feedback.Text)
+	fmt.Fprintf(&body, "\n\n---\nFeedback #%d from user %d\nApp version: %s\nPlatform: %s %s\nDevice: %s\n",
+		feedback.ID, feedback.UserID, feedback.AppVersion, feedback.Platform, feedback.OSVersion, feedback.DeviceModel)
+	if feedback.ScreenshotURL != "" {
+		fmt.Fprintf(&body, "Screenshot: %s\n", feedback.ScreenshotURL)
+	}
+	payload, err := json.Marshal(map[string]interface{}{
+		"title":       fmt.Sprintf("[%s] %s", feedback.Category, title),
+		"body":        body.String(),
+		"labels":      []string{"feedback", feedback.Category},
+		"feedback_id": feedback.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), feedbackForwardTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.Config.FeedbackWebhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.Config.FeedbackWebhookToken != "" {
+		req.Header.Set("Authorization", "Bearer "+s.Config.FeedbackWebhookToken)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("issue tracker answered %s", resp.Status)
+	}
+	return nil
+}
+
+// signScreenshot fills in a link to the screenshot that expires, leaving
+// it out when the link cannot be made
+func (s *feedbackService) signScreenshot(feedback *models.Feedback) {
+	if feedback.ScreenshotKey == "" {
+		return
+	}
+	url, _, err := s.objects.SignedURL(feedback.ScreenshotKey)
+	if err != nil {
+		log.Printf("signing screenshot of feedback %d: %v", feedback.ID, err)
+		return
+	}
+	feedback.ScreenshotURL = url
+}
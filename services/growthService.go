@@ -0,0 +1,76 @@
+package services
+
+import (
+	"time"
+
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/models"
+)
+
+// MaxRetentionPeriods bounds the width of a cohort retention table
+const MaxRetentionPeriods = 24
+
+// GrowthService measures user growth and whether new users keep reporting
+type GrowthService interface {
+	Signups(groupBy string, start, end time.Time) (interface{}, error)
+	ActiveUsers(asOf time.Time) (*models.ActiveUsers, []models.PeriodCount, error)
+	Retention(interval string, periods int) ([]models.RetentionCohort, error)
+}
+
+type growthService struct {
+	Config     *config.Config
+	growthRepo db.GrowthRepository
+}
+
+// NewGrowthService creates a new instance of GrowthService
+func NewGrowthService(growthRepo db.GrowthRepository, conf *config.Config) GrowthService {
+	return &growthService{
+		Config:     conf,
+		growthRepo: growthRepo,
+	}
+}
+
+// Signups counts new users per state, or per day, week or month
+func (s *growthService) Signups(groupBy string, start, end time.Time) (interface{}, error) {
+	if groupBy == "state" {
+		return s.growthRepo.SignupsByState(start, end)
+	}
+	return s.growthRepo.SignupsByPeriod(groupBy, start, end)
+}
+
+// ActiveUsers returns DAU, WAU and MAU as of asOf, with the daily active
+// users of the 30 days before it
+func (s *growthService) ActiveUsers(asOf time.Time) (*models.ActiveUsers, []models.PeriodCount, error) {
+	active, err := s.growthRepo.ActiveUsers(asOf)
+	if err != nil {
+		return nil, nil, err
+	}
+	daily, err := s.growthRepo.DailyActiveUsers(asOf.AddDate(0, 0, -30), asOf)
+	if err != nil {
+		return nil, nil, err
+	}
+	return active, daily, nil
+}
+
+// Retention returns the retention table of the last periods cohorts
+func (s *growthService) Retention(interval string, periods int) ([]models.RetentionCohort, error) {
+	if periods < 1 {
+		periods = 1
+	}
+	if periods > MaxRetentionPeriods {
+		periods = MaxRetentionPeriods
+	}
+
+	now := time.Now()
+	var since time.Time
+	switch interval {
+	case "day":
+		since = now.AddDate(0, 0, -periods)
+	case "week":
+		since = now.AddDate(0, 0, -7*periods)
+	default:
+		since = now.AddDate(0, -periods, 0)
+	}
+	return s.growthRepo.CohortRetention(interval, since, periods)
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/techagentng/citizenx/cache"
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/locale"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrHelpArticleNotFound is returned for articles that do not exist or,
+	// to clients, have nothing published in their locale.
+	ErrHelpArticleNotFound = errors.New("help article not found")
+	// ErrHelpRevisionNotFound is returned for revisions an article does not
+	// have.
+	ErrHelpRevisionNotFound = errors.New("help revision not found")
+)
+
+// HelpCacheTTL is how long published help content is served from memory.
+// Publishing clears the cache on this server; others catch up within it.
+const HelpCacheTTL = 5 * time.Minute
+
+// HelpService manages the help centre: FAQ articles, category guides and
+// onboarding tips, edited and published without an app release
+type HelpService interface {
+	ListArticles() ([]models.HelpArticle, error)
+	CreateArticle(article *models.HelpArticle, adminID uint) error
+	UpdateArticle(id uint, article *models.HelpArticle) error
+	DeleteArticle(id uint) error
+	Revisions(articleID uint) ([]models.HelpRevision, error)
+	AddRevision(articleID uint, request *models.HelpRevisionRequest, adminID uint) (*models.HelpRevision, error)
+	Publish(articleID, revisionID uint) error
+	Content(tag, kind, category string) ([]models.HelpContent, error)
+	Article(tag, slug string) (*models.HelpContent, error)
+}
+
+type helpService struct {
+	Config   *config.Config
+	helpRepo db.HelpRepository
+	content  *cache.TTL[[]models.HelpContent]
+}
+
+// NewHelpService creates a new instance of HelpService
+func NewHelpService(helpRepo db.HelpRepository, conf *config.Config) HelpService {
+	return &helpService{
+		Config:   conf,
+		helpRepo: helpRepo,
+		content:  cache.New[[]models.HelpContent](HelpCacheTTL),
+	}
+}
+
+func (s *helpService) ListArticles() ([]models.HelpArticle, error) {
+	return s.helpRepo.ListArticles()
+}
+
+func (s *helpService) CreateArticle(article *models.HelpArticle, adminID uint) error {
+	now := time.Now().Unix()
+	article.ID = 0
+	article.Slug = strings.ToLower(strings.TrimSpace(article.Slug))
+	article.CreatedBy = adminID
+	article.CreatedAt = now
+	article.UpdatedAt = now
+	return s.helpRepo.CreateArticle(article)
+}
+
+// UpdateArticle changes an article's slug, kind, category or position.
+// Its text changes through revisions.
+func (s *helpService) UpdateArticle(id uint, article *models.HelpArticle) error {
+	if _, err := s.getArticle(id); err != nil {
+		return err
+	}
+	article.ID = id
+	article.Slug = strings.ToLower(strings.TrimSpace(article.Slug))
+	article.UpdatedAt = time.Now().Unix()
+	if err := s.helpRepo.UpdateArticle(article); err != nil {
+		return err
+	}
+	s.content.Clear()
+	return nil
+}
+
+func (s *helpService) DeleteArticle(id uint) error {
+	err := s.helpRepo.DeleteArticle(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrHelpArticleNotFound
+	}
+	if err != nil {
+		return err
+	}
+	s.content.Clear()
+	return nil
+}
+
+func (s *helpService) Revisions(articleID uint) ([]models.HelpRevision, error) {
+	if _, err := s.getArticle(articleID); err != nil {
+		return nil, err
+	}
+	return s.helpRepo.Revisions(articleID)
+}
+
+// AddRevision drafts the next version of an article in a locale. Clients
+// do not see it until it is published.
+func (s *helpService) AddRevision(articleID uint, request *models.HelpRevisionRequest, adminID uint) (*models.HelpRevision, error) {
+	tag, ok := locale.Normalize(request.Locale)
+	if !ok {
+		return nil, ErrUnsupportedLocale
+	}
+	if _, err := s.getArticle(articleID); err != nil {
+		return nil, err
+	}
+	revision := &models.HelpRevision{
+		ArticleID: articleID,
+		Locale:    tag,
+		Title:     strings.TrimSpace(request.Title),
+		Body:      strings.TrimSpace(request.Body),
+		CreatedBy: adminID,
+		CreatedAt: time.Now().Unix(),
+	}
+	if err := s.helpRepo.CreateRevision(revision); err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
+
+// Publish makes a revision the one clients see in its locale. Publishing an
+// earlier revision again rolls back to it.
+func (s *helpService) Publish(articleID, revisionID uint) error {
+	err := s.helpRepo.PublishRevision(articleID, revisionID, time.Now().Unix())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrHelpRevisionNotFound
+	}
+	if err != nil {
+		return err
+	}
+	s.content.Clear()
+	return nil
+}
+
+// Content returns the published help in the locale tag, falling back to the
+// default locale, optionally only of one kind or report category
+func (s *helpService) Content(tag, kind, category string) ([]models.HelpContent, error) {
+	category = strings.ToLower(strings.TrimSpace(category))
+	locales := []string{tag}
+	if tag != locale.Default {
+		locales = append(locales, locale.Default)
+	}
+	return s.content.GetOrLoad(tag+"|"+kind+"|"+category, func() ([]models.HelpContent, error) {
+		return s.helpRepo.PublishedContent(locales, kind, category)
+	})
+}
+
+// Article returns one published article in the locale tag
+func (s *helpService) Article(tag, slug string) (*models.HelpContent, error) {
+	content, err := s.Content(tag, "", "")
+	if err != nil {
+		return nil, err
+	}
+	slug = strings.ToLower(strings.TrimSpace(slug))
+	for i := range content {
+		if content[i].Slug == slug {
+			return &content[i], nil
+		}
+	}
+	return nil, ErrHelpArticleNotFound
+}
+
+func (s *helpService) getArticle(id uint) (*models.HelpArticle, error) {
+	article, err := s.helpRepo.GetArticle(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrHelpArticleNotFound
+	}
+	return article, err
+}
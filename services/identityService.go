@@ -0,0 +1,171 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/identity"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/pii"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrIdentityUnavailable is returned when no verification vendor is
+	// configured.
+	ErrIdentityUnavailable = errors.New("identity verification is not available")
+	// ErrIdentityAlreadyVerified is returned when verifying a user who
+	// already is.
+	ErrIdentityAlreadyVerified = errors.New("your identity is already verified")
+	// ErrIdentityMismatch is returned when the number is unknown or the
+	// register's record is not of the user.
+	ErrIdentityMismatch = errors.New("the number does not match your name and date of birth")
+	// ErrIdentityInUse is returned when the number already verifies
+	// another account.
+	ErrIdentityInUse = errors.New("this number is already used to verify another account")
+	// ErrIdentityAttempts is returned after too many failed attempts in a
+	// day.
+	ErrIdentityAttempts = errors.New("too many failed attempts, try again tomorrow")
+	// ErrIdentityNotVerified is returned when revoking the verification of
+	// a user who is not verified.
+	ErrIdentityNotVerified = errors.New("identity is not verified")
+)
+
+const (
+	// identityAttemptWindow is the period failed attempts are counted over
+	identityAttemptWindow = 24 * time.Hour
+	identityLookupTimeout = 20 * time.Second
+)
+
+// IdentityService lets users optionally verify their identity with their
+// NIN or BVN, earning the verified citizen badge and a higher reputation
+type IdentityService interface {
+	Status(userID uint) (*models.IdentityVerification, error)
+	Verify(userID uint, request *models.IdentityVerificationRequest) (*models.IdentityVerification, error)
+	Revoke(userID uint) error
+}
+
+type identityService struct {
+	Config            *config.Config
+	identityRepo      db.IdentityRepository
+	authRepo          db.AuthRepository
+	verifier          identity.Verifier
+	reputationService ReputationService
+}
+
+// NewIdentityService creates a new instance of IdentityService. verifier is
+// nil when no vendor is configured.
+func NewIdentityService(identityRepo db.IdentityRepository, authRepo db.AuthRepository, verifier identity.Verifier, reputationService ReputationService, conf *config.Config) IdentityService {
+	return &identityService{
+		Config:            conf,
+		identityRepo:      identityRepo,
+		authRepo:          authRepo,
+		verifier:          verifier,
+		reputationService: reputationService,
+	}
+}
+
+// Status returns the user's last check, or nil when they have never tried
+func (s *identityService) Status(userID uint) (*models.IdentityVerification, error) {
+	verification, err := s.identityRepo.GetVerification(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return verification, err
+}
+
+// Verify looks the number up and matches the register's record against the
+// user's name and date of birth. Only the outcome and a keyed hash of the
+// number are stored.
+func (s *identityService) Verify(userID uint, request *models.IdentityVerificationRequest) (*models.IdentityVerification, error) {
+	if s.verifier == nil {
+		return nil, ErrIdentityUnavailable
+	}
+	now := time.Now()
+	verification, err := s.Status(userID)
+	if err != nil {
+		return nil, err
+	}
+	if verification == nil {
+		verification = &models.IdentityVerification{UserID: userID}
+	}
+	if verification.Status == models.IdentityVerified {
+		return nil, ErrIdentityAlreadyVerified
+	}
+	if now.Unix()-verification.WindowStart >= int64(identityAttemptWindow.Seconds()) {
+		verification.Attempts, verification.WindowStart = 0, now.Unix()
+	}
+	if verification.Attempts >= s.Config.IdentityMaxAttempts {
+		return nil, ErrIdentityAttempts
+	}
+
+	reference := pii.BlindIndex(request.Method + ":" + request.Number)
+	inUse, err := s.identityRepo.ReferenceInUse(*reference, userID)
+	if err != nil {
+		return nil, err
+	}
+	if inUse {
+		return nil, ErrIdentityInUse
+	}
+	user, err := s.authRepo.FindUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	dateOfBirth := user.DateOfBirth
+	if dateOfBirth == "" {
+		dateOfBirth = strings.TrimSpace(request.DateOfBirth)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), identityLookupTimeout)
+	defer cancel()
+	record, err := s.verifier.Lookup(ctx, request.Method, request.Number)
+	if err != nil && !errors.Is(err, identity.ErrNotFound) {
+		return nil, err
+	}
+
+	verification.Method = request.Method
+	verification.Provider = s.verifier.Name()
+	verification.UpdatedAt = now.Unix()
+	if record == nil || !record.Matches(user.Fullname, dateOfBirth) {
+		verification.Status = models.IdentityFailed
+		verification.ReferenceHash = nil
+		verification.Attempts++
+		if err := s.identityRepo.SaveVerification(verification); err != nil {
+			return nil, err
+		}
+		return nil, ErrIdentityMismatch
+	}
+
+	verification.Status = models.IdentityVerified
+	verification.ReferenceHash = reference
+	verification.Attempts = 0
+	verification.VerifiedAt = now.Unix()
+	if err := s.identityRepo.SaveVerification(verification); err != nil {
+		return nil, err
+	}
+	if err := s.reputationService.SetIdentityVerified(userID, true); err != nil {
+		log.Printf("rescoring user %d after identity verification: %v", userID, err)
+	}
+	return verification, nil
+}
+
+// Revoke withdraws a verification, for numbers found to be used
+// fraudulently. The user loses the badge and the reputation it gave.
+func (s *identityService) Revoke(userID uint) error {
+	err := s.identityRepo.RevokeVerification(userID, time.Now().Unix())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrIdentityNotVerified
+	}
+	if err != nil {
+		return err
+	}
+	if err := s.reputationService.SetIdentityVerified(userID, false); err != nil {
+		log.Printf("rescoring user %d after revoking identity verification: %v", userID, err)
+	}
+	return nil
+}
@@ -1,23 +1,35 @@
 package services
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"log"
 	"math"
 	"strings"
+	"time"
+
     "github.com/google/uuid"
 	"github.com/techagentng/citizenx/config"
 	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/events"
+	"github.com/techagentng/citizenx/geo"
 	"github.com/techagentng/citizenx/models"
-	"gorm.io/gorm"
+	"github.com/techagentng/citizenx/textfilter"
 )
 
 type IncidentReportService interface {
 	SaveReport(userID uint, lat float64, lng float64, report *models.IncidentReport, reportID string, totalPoints int) (*models.IncidentReport, error)
-	GetAllReports(page int) ([]models.IncidentReport, error)
-	GetAllReportsByState(state string, page int) ([]models.IncidentReport, error)
-	GetAllReportsByLGA(lga string, page int) ([]models.IncidentReport, error)
-	GetAllReportsByReportType(reportType string, page int) ([]models.IncidentReport, error)
+	GetAllReports(page int, sort db.ReportSort) ([]models.IncidentReport, error)
+	GetAllReportsByState(state string, page int, sort db.ReportSort) ([]models.IncidentReport, error)
+	GetAllReportsByLGA(lga string, page int, sort db.ReportSort) ([]models.IncidentReport, error)
+	GetAllReportsByReportType(reportType string, page int, sort db.ReportSort) ([]models.IncidentReport, error)
+	ListReports(filters db.ReportFilter, page int, sort db.ReportSort) ([]models.IncidentReport, error)
+	TrendingReports(filters db.ReportFilter, page int, sort db.ReportSort) ([]models.IncidentReport, error)
+	NearbyReports(lat, lng, radiusKM float64, page int) ([]models.IncidentReport, error)
+	EditReport(userID uint, reportID string, edit models.ReportEditRequest) (*models.ReportRevision, error)
+	GetReportRevisions(reportID string) ([]models.ReportRevision, error)
+	DiffReportRevisions(reportID string, from, to int) (*models.ReportRevisionDiff, error)
 	GetReportPercentageByState() ([]models.StateReportPercentage, error)
 	GetTotalUserCount() (int64, error)
 	GetRegisteredUsersCountByLGA(lga string) (int64, error)
@@ -26,11 +38,21 @@ type IncidentReportService interface {
 	ListAllStatesWithReportCounts() ([]models.StateReportCount, error)
 	GetTotalReportCount() (int64, error)
 	GetNamesByCategory(stateName string, lgaID string, reportTypeCategory string) ([]string, error)
-	BookmarkReport(userID uint, reportID uuid.UUID) error
+	BookmarkReport(userID uint, reportID uuid.UUID) (bool, error)
 	GetBookmarkedReports(userID uint) ([]models.IncidentReport, error)
 	GetUserReports(userID uint) ([]models.ReportType, error)
 	GetReportTypeCountsByLGA(lga string) (map[string]interface{}, error)
 	AddMediaToReport(reportTypeID string, feedURLs, thumbnailURLs, fullsizeURLs []string) error
+	GetReportText(reportID string) (*models.ModeratedText, error)
+	CreateDraft(userID uint, draft *models.ReportDraft) error
+	GetDraft(draftID string, userID uint) (*models.ReportDraft, error)
+	GetDraftMedia(draftID string, userID uint) ([]models.Media, error)
+	FinalizeDraft(ctx context.Context, user *models.User, draftID, clientIP string, mediaIDs []string) (*models.IncidentReport, error)
+	PurgeAbandonedDrafts() (int64, error)
+	ShareReport(reportID string) (*models.ReportShare, error)
+	GetReportPoints(reportID string) ([]models.ReportPoint, error)
+	ListUserReports(userID uint, filter db.UserReportFilter, page int) (*models.UserReportPage, error)
+	WithdrawReport(userID uint, reportID, reason string) error
 }
 
 type IncidentService struct {
@@ -38,21 +60,76 @@ type IncidentService struct {
 	incidentRepo db.IncidentReportRepository
 	rewardRepo   db.RewardRepository
 	mediaRepo    db.MediaRepository
+	draftRepo    db.ReportDraftRepository
+	autoPublish  AutoPublishService
+	ipLocation   IPLocationService
+	textFilter   *textfilter.Filter
+	ages         *AgePolicy
+	windows      SubmissionWindowService
+	links        ShortLinkService
+	caps         ReportCapService
+	duplicates   DuplicateService
 }
 
 // NewIncidentReportService instantiates an IncidentReportService
-func NewIncidentReportService(incidentReportRepo db.IncidentReportRepository, rewardRepo db.RewardRepository, mediaRepo db.MediaRepository, conf *config.Config) *IncidentService {
+func NewIncidentReportService(incidentReportRepo db.IncidentReportRepository, rewardRepo db.RewardRepository, mediaRepo db.MediaRepository, draftRepo db.ReportDraftRepository, autoPublish AutoPublishService, ipLocation IPLocationService, windows SubmissionWindowService, links ShortLinkService, caps ReportCapService, duplicates DuplicateService, conf *config.Config) *IncidentService {
 	return &IncidentService{
 		Config:       conf,
 		incidentRepo: incidentReportRepo,
 		rewardRepo:   rewardRepo,
 		mediaRepo:    mediaRepo,
+		draftRepo:    draftRepo,
+		autoPublish:  autoPublish,
+		ipLocation:   ipLocation,
+		textFilter:   textfilter.New(strings.Split(conf.BannedWords, ",")),
+		ages:         NewAgePolicy(conf),
+		windows:      windows,
+		links:        links,
+		caps:         caps,
+		duplicates:   duplicates,
 	}
 }
 
 func (s *IncidentService) SaveReport(userID uint, lat float64, lng float64, report *models.IncidentReport, reportID string, totalPoints int) (*models.IncidentReport, error) {
 	fmt.Println("Report ID:", reportID)
 
+	if err := s.windows.Check(report.Category, time.Now()); err != nil {
+		return nil, err
+	}
+	if err := s.caps.Check(userID, report.Category, time.Now()); err != nil {
+		return nil, err
+	}
+
+	// Users in restricted mode report anonymously, outside graphic
+	// categories
+	restrictedUntil, err := s.incidentRepo.UserRestrictedUntil(userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.ages.AllowCategory(restrictedUntil, report.Category, time.Now()); err != nil {
+		return nil, err
+	}
+	if restrictedUntil > time.Now().Unix() {
+		report.UserIsAnonymous = true
+		report.UserFullname = ""
+		report.UserUsername = ""
+	}
+
+	if report.Route != "" {
+		route, err := ParseRoute(report.Route)
+		if err != nil {
+			return nil, err
+		}
+		report.Route = geo.EncodePolyline(route)
+		report.RouteLengthM = int(math.Round(geo.PolylineLength(route)))
+	}
+	if err := prepareReportPoints(report); err != nil {
+		return nil, err
+	}
+	if lat == 0 && lng == 0 {
+		lat, lng = report.Latitude, report.Longitude
+	}
+
 	var reward *models.Reward
 	mediaPoints := totalPoints * 10
 	var descPoint, locationPoint int
@@ -88,11 +165,16 @@ func (s *IncidentService) SaveReport(userID uint, lat float64, lng float64, repo
 		}
 	}
 
-	if err := s.incidentRepo.UpdateReward(userID, reward); err != nil {
-		return nil, fmt.Errorf("error creating reward: %v", err)
+	report.RewardPoint = reportPoints
+	report.UserID = userID
+	if locationPoint > 0 && (lat != 0 || lng != 0) {
+		report.PlusCode = geo.EncodePlusCode(lat, lng, geo.PlusCodeLength)
 	}
 
-	report.RewardPoint = reportPoints
+	// The public description has contact details and banned words masked;
+	// moderators can still read what was written
+	report.DescriptionRaw = report.Description
+	report.Description = s.textFilter.Mask(report.Description)
 
 	// Fetch the ReportTypeID based on category
 	reportType, err := s.incidentRepo.GetReportTypeByCategory(report.Category)
@@ -103,10 +185,51 @@ func (s *IncidentService) SaveReport(userID uint, lat float64, lng float64, repo
 	// Assign the fetched ReportTypeID
 	report.ReportTypeID = reportType.ID
 
-	savedReport, err := s.incidentRepo.SaveIncidentReport(report)
+	now := time.Now()
+	evts := []events.Event{
+		events.ReportCreated{
+			ReportID:   report.ID,
+			UserID:     userID,
+			Category:   report.Category,
+			StateName:  report.StateName,
+			LGAName:    report.LGAName,
+			Latitude:   report.Latitude,
+			Longitude:  report.Longitude,
+			OccurredAt: now,
+		},
+		events.RewardEarned{
+			UserID:     userID,
+			ReportID:   reportID,
+			RewardType: reward.RewardType,
+			Points:     reward.Point,
+			OccurredAt: now,
+		},
+	}
+
+	// Trusted reporters publish straight away in low-risk categories; a
+	// sample of those reports is audited afterwards. Reports sent through
+	// anonymizers or datacenters always wait for a moderator.
+	autoPublished := report.NetworkFlag == "" && s.autoPublish.Eligible(userID, report.Category)
+	report.AutoPublished = autoPublished
+
+	savedReport, err := s.incidentRepo.SaveIncidentReport(report, reward, evts...)
 	if err != nil {
 		return nil, fmt.Errorf("error saving report: %v", err)
 	}
+	// Media uploaded to the draft takes the category's default
+	if s.ages.Graphic(report.Category) {
+		if err := s.mediaRepo.MarkCategoryGraphic(reportID); err != nil {
+			log.Printf("marking media of report %s graphic: %v", reportID, err)
+		}
+	}
+	if autoPublished {
+		if err := s.autoPublish.Sample(savedReport); err != nil {
+			log.Printf("sampling auto-published report %s for audit: %v", savedReport.ID, err)
+		}
+	}
+	if _, err := s.duplicates.Detect(savedReport); err != nil {
+		log.Printf("checking report %s for duplicates: %v", savedReport.ID, err)
+	}
 
 	reportResponse := &models.IncidentReport{
 		DateOfIncidence:      savedReport.DateOfIncidence,
@@ -136,25 +259,64 @@ func (s *IncidentService) SaveReport(userID uint, lat float64, lng float64, repo
 		ThumbnailURLs:        savedReport.ThumbnailURLs,
 		StateName: savedReport.StateName,
 		LGAName: savedReport.LGAName,
+		PlusCode: savedReport.PlusCode,
+		Route: savedReport.Route,
+		RouteLengthM: savedReport.RouteLengthM,
+		Points: savedReport.Points,
+		LocationApproximate: savedReport.LocationApproximate,
 	}
 
 	return reportResponse, nil
 }
 
-func (s *IncidentService) GetAllReports(page int) ([]models.IncidentReport, error) {
-	return s.incidentRepo.GetAllReports(page)
+func (s *IncidentService) GetAllReports(page int, sort db.ReportSort) ([]models.IncidentReport, error) {
+	return s.incidentRepo.GetAllReports(page, sort, db.ReportCard()...)
+}
+
+func (s *IncidentService) GetAllReportsByState(state string, page int, sort db.ReportSort) ([]models.IncidentReport, error) {
+	return s.incidentRepo.GetAllReportsByState(state, page, sort, db.ReportCard()...)
+}
+
+func (s *IncidentService) GetAllReportsByLGA(lga string, page int, sort db.ReportSort) ([]models.IncidentReport, error) {
+	return s.incidentRepo.GetAllReportsByLGA(lga, page, sort, db.ReportCard()...)
 }
 
-func (s *IncidentService) GetAllReportsByState(state string, page int) ([]models.IncidentReport, error) {
-	return s.incidentRepo.GetAllReportsByState(state, page)
+func (s *IncidentService) GetAllReportsByReportType(lga string, page int, sort db.ReportSort) ([]models.IncidentReport, error) {
+	return s.incidentRepo.GetAllReportsByReportType(lga, page, sort, db.ReportCard()...)
 }
 
-func (s *IncidentService) GetAllReportsByLGA(lga string, page int) ([]models.IncidentReport, error) {
-	return s.incidentRepo.GetAllReportsByLGA(lga, page)
+// ListReports returns the reports matching every filter at once
+func (s *IncidentService) ListReports(filters db.ReportFilter, page int, sort db.ReportSort) ([]models.IncidentReport, error) {
+	return s.incidentRepo.ListReports(filters, page, sort, db.ReportCard()...)
+}
+
+// TrendingReports returns the recent reports matching every filter, most
+// credible first. Reports are recent when they happened within the last
+// TrendingDays; an earlier start in filters is moved up to that.
+func (s *IncidentService) TrendingReports(filters db.ReportFilter, page int, sort db.ReportSort) ([]models.IncidentReport, error) {
+	since := time.Now().AddDate(0, 0, -s.Config.TrendingDays)
+	if filters.Start == nil || filters.Start.Before(since) {
+		filters.Start = &since
+	}
+	sort.Order = db.SortTrending
+	return s.incidentRepo.ListReports(filters, page, sort, db.ReportCard()...)
 }
 
-func (s *IncidentService) GetAllReportsByReportType(lga string, page int) ([]models.IncidentReport, error) {
-	return s.incidentRepo.GetAllReportsByReportType(lga, page)
+// MaxNearbyRadiusKM is the widest radius reports near a point are looked
+// for in
+const MaxNearbyRadiusKM = 50
+
+// ErrInvalidRadius is returned for a search radius that is not positive or
+// is wider than MaxNearbyRadiusKM
+var ErrInvalidRadius = fmt.Errorf("radius must be more than 0 and at most %d km", MaxNearbyRadiusKM)
+
+// NearbyReports returns a page of the reports within radiusKM of a point,
+// nearest first
+func (s *IncidentService) NearbyReports(lat, lng, radiusKM float64, page int) ([]models.IncidentReport, error) {
+	if !(radiusKM > 0 && radiusKM <= MaxNearbyRadiusKM) {
+		return nil, ErrInvalidRadius
+	}
+	return s.incidentRepo.GetReportsWithinRadius(lat, lng, radiusKM, page, db.ReportCard()...)
 }
 
 func (s *IncidentService) GetReportPercentageByState() ([]models.StateReportPercentage, error) {
@@ -202,43 +364,29 @@ func (s *IncidentService) GetNamesByCategory(stateName string, lgaID string, rep
 	return names, nil
 }
 
-func (s *IncidentService) BookmarkReport(userID uint, reportID uuid.UUID) error {
-    // First check if the report exists
-    exists, err := s.incidentRepo.ReportExists(reportID)
-    if err != nil {
-        return err
-    }
-    if !exists {
-        return errors.New("report not found")
-    }
-
-    // Check if the report is already bookmarked by the user
-    var bookmark models.Bookmark
-    err = s.incidentRepo.IsBookmarked(userID, reportID, &bookmark)
-    if err == nil {
-        // Report is already bookmarked
-        return errors.New("report already bookmarked")
-    }
-    
-    // Only proceed if the error was "record not found"
-    if err != gorm.ErrRecordNotFound {
-        return err // Return any other unexpected errors
-    }
-
-    // Create a new bookmark
-    newBookmark := models.Bookmark{
-        UserID:   userID,
-        ReportID: reportID,
-    }
-
-    // Save the bookmark
-    return s.incidentRepo.SaveBookmark(&newBookmark)
+// BookmarkReport saves the report to the user's bookmarks, reporting false
+// when it was there already
+func (s *IncidentService) BookmarkReport(userID uint, reportID uuid.UUID) (bool, error) {
+	exists, err := s.incidentRepo.ReportExists(reportID)
+	if err != nil {
+		return false, err
+	}
+	if !exists {
+		return false, errors.New("report not found")
+	}
+	return s.incidentRepo.SaveBookmark(&models.Bookmark{
+		UserID:   userID,
+		ReportID: reportID,
+	}, events.ReportBookmarked{
+		ReportID:   reportID,
+		UserID:     userID,
+		OccurredAt: time.Now(),
+	})
 }
 
-
 func (s *IncidentService) GetBookmarkedReports(userID uint) ([]models.IncidentReport, error) {
 	// Call the repository method to get the bookmarked reports
-	return s.incidentRepo.GetBookmarkedReports(userID)
+	return s.incidentRepo.GetBookmarkedReports(userID, db.ReportCard()...)
 }
 
 func (s *IncidentService) GetUserReports(userID uint) ([]models.ReportType, error) {
@@ -287,3 +435,13 @@ func appendURLs(existingURLs string, newURLs []string) string {
 	}
 	return strings.Join(newURLs, ",")
 }
+
+// GetReportText returns a report's description as shown publicly and as
+// written by the reporter
+func (s *IncidentService) GetReportText(reportID string) (*models.ModeratedText, error) {
+	report, err := s.incidentRepo.GetIncidentReportByID(reportID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ModeratedText{ID: reportID, Public: report.Description, Raw: report.DescriptionRaw}, nil
+}
@@ -0,0 +1,248 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrIncidentNotFound is returned for incidents that do not exist.
+	ErrIncidentNotFound = errors.New("incident not found")
+	// ErrInvalidIncidentStatus is returned for a status incidents cannot have.
+	ErrInvalidIncidentStatus = fmt.Errorf("incident status must be one of %s", strings.Join(models.IncidentStatuses, ", "))
+	// ErrReportNotInIncident is returned when detaching a report that is not
+	// grouped under the incident.
+	ErrReportNotInIncident = errors.New("report is not part of this incident")
+	// ErrInvalidReportIDs is returned when grouping reports by malformed IDs.
+	ErrInvalidReportIDs = errors.New("report_ids must be report UUIDs")
+)
+
+// IncidentGroupService manages incidents, the real-world events that many
+// reports are grouped under
+type IncidentGroupService interface {
+	CreateIncident(incident *models.Incident, adminID uint) error
+	GetIncident(id string) (*models.Incident, error)
+	ListIncidents(status string, page int) ([]models.Incident, error)
+	UpdateIncident(id string, changes *models.Incident, adminID uint) (*models.Incident, error)
+	DeleteIncident(id string) error
+	AddUpdate(id string, update *models.IncidentUpdate, adminID uint) error
+	AttachReports(id string, reportIDs []string) (int64, error)
+	DetachReport(id, reportID string) error
+	IncidentReports(id string, page int) ([]models.IncidentReport, error)
+	IncidentTimeline(id string) (*models.IncidentTimeline, error)
+}
+
+type incidentGroupService struct {
+	Config       *config.Config
+	incidentRepo db.IncidentRepository
+}
+
+// NewIncidentGroupService creates a new instance of IncidentGroupService
+func NewIncidentGroupService(incidentRepo db.IncidentRepository, conf *config.Config) IncidentGroupService {
+	return &incidentGroupService{
+		Config:       conf,
+		incidentRepo: incidentRepo,
+	}
+}
+
+// CreateIncident opens an incident. It starts now unless a start time is
+// given, and its timeline begins with its summary.
+func (s *incidentGroupService) CreateIncident(incident *models.Incident, adminID uint) error {
+	if incident.Status == "" {
+		incident.Status = models.IncidentOpen
+	}
+	if !validIncidentStatus(incident.Status) {
+		return ErrInvalidIncidentStatus
+	}
+
+	now := time.Now().Unix()
+	incident.ID = uuid.New()
+	incident.CreatedBy = adminID
+	incident.CreatedAt = now
+	incident.UpdatedAt = now
+	if incident.StartedAt == 0 {
+		incident.StartedAt = now
+	}
+	incident.ResolvedAt = 0
+	if incident.Status == models.IncidentResolved {
+		incident.ResolvedAt = now
+	}
+
+	message := "Incident opened"
+	if incident.Summary != "" {
+		message += ": " + incident.Summary
+	}
+	return s.incidentRepo.CreateIncident(incident, &models.IncidentUpdate{
+		IncidentID: incident.ID,
+		Status:     incident.Status,
+		Message:    message,
+		CreatedBy:  adminID,
+		CreatedAt:  now,
+	})
+}
+
+// GetIncident returns an incident with the aggregated stats of its reports
+func (s *incidentGroupService) GetIncident(id string) (*models.Incident, error) {
+	incident, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := s.incidentRepo.IncidentStats(id)
+	if err != nil {
+		return nil, err
+	}
+	incident.Stats = stats
+	incident.ReportCount = stats.Reports
+	return incident, nil
+}
+
+func (s *incidentGroupService) ListIncidents(status string, page int) ([]models.Incident, error) {
+	if status != "" && !validIncidentStatus(status) {
+		return nil, ErrInvalidIncidentStatus
+	}
+	return s.incidentRepo.ListIncidents(status, page)
+}
+
+// UpdateIncident replaces an incident's details. A change of status is
+// recorded on its timeline, and resolving it records when.
+func (s *incidentGroupService) UpdateIncident(id string, changes *models.Incident, adminID uint) (*models.Incident, error) {
+	incident, err := s.find(id)
+	if err != nil {
+		return nil, err
+	}
+	if changes.Status == "" {
+		changes.Status = incident.Status
+	}
+	if !validIncidentStatus(changes.Status) {
+		return nil, ErrInvalidIncidentStatus
+	}
+
+	now := time.Now().Unix()
+	var update *models.IncidentUpdate
+	if changes.Status != incident.Status {
+		update = &models.IncidentUpdate{
+			IncidentID: incident.ID,
+			Status:     changes.Status,
+			Message:    fmt.Sprintf("Status changed from %s to %s", incident.Status, changes.Status),
+			CreatedBy:  adminID,
+			CreatedAt:  now,
+		}
+		incident.ResolvedAt = 0
+		if changes.Status == models.IncidentResolved {
+			incident.ResolvedAt = now
+		}
+	}
+
+	incident.Title = changes.Title
+	incident.Summary = changes.Summary
+	incident.Category = changes.Category
+	incident.Status = changes.Status
+	incident.StateName = changes.StateName
+	incident.LGAName = changes.LGAName
+	incident.Latitude = changes.Latitude
+	incident.Longitude = changes.Longitude
+	if changes.StartedAt != 0 {
+		incident.StartedAt = changes.StartedAt
+	}
+	incident.UpdatedAt = now
+	if err := s.incidentRepo.UpdateIncident(incident, update); err != nil {
+		return nil, err
+	}
+	return incident, nil
+}
+
+func (s *incidentGroupService) DeleteIncident(id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return ErrIncidentNotFound
+	}
+	err := s.incidentRepo.DeleteIncident(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrIncidentNotFound
+	}
+	return err
+}
+
+// AddUpdate posts a note to the incident's timeline
+func (s *incidentGroupService) AddUpdate(id string, update *models.IncidentUpdate, adminID uint) error {
+	incident, err := s.find(id)
+	if err != nil {
+		return err
+	}
+	update.ID = 0
+	update.IncidentID = incident.ID
+	update.Status = ""
+	update.CreatedBy = adminID
+	update.CreatedAt = time.Now().Unix()
+	return s.incidentRepo.AddUpdate(update)
+}
+
+// AttachReports groups reports under the incident and returns how many of
+// them exist
+func (s *incidentGroupService) AttachReports(id string, reportIDs []string) (int64, error) {
+	if _, err := s.find(id); err != nil {
+		return 0, err
+	}
+	for _, reportID := range reportIDs {
+		if _, err := uuid.Parse(reportID); err != nil {
+			return 0, ErrInvalidReportIDs
+		}
+	}
+	return s.incidentRepo.AttachReports(id, reportIDs)
+}
+
+func (s *incidentGroupService) DetachReport(id, reportID string) error {
+	if _, err := s.find(id); err != nil {
+		return err
+	}
+	if _, err := uuid.Parse(reportID); err != nil {
+		return ErrReportNotInIncident
+	}
+	err := s.incidentRepo.DetachReport(id, reportID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrReportNotInIncident
+	}
+	return err
+}
+
+func (s *incidentGroupService) IncidentReports(id string, page int) ([]models.IncidentReport, error) {
+	if _, err := s.find(id); err != nil {
+		return nil, err
+	}
+	return s.incidentRepo.IncidentReports(id, page)
+}
+
+func (s *incidentGroupService) IncidentTimeline(id string) (*models.IncidentTimeline, error) {
+	if _, err := s.find(id); err != nil {
+		return nil, err
+	}
+	return s.incidentRepo.IncidentTimeline(id)
+}
+
+// find loads an incident, treating a malformed ID as one that does not exist
+func (s *incidentGroupService) find(id string) (*models.Incident, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrIncidentNotFound
+	}
+	incident, err := s.incidentRepo.GetIncident(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrIncidentNotFound
+	}
+	return incident, err
+}
+
+func validIncidentStatus(status string) bool {
+	for _, s := range models.IncidentStatuses {
+		if status == s {
+			return true
+		}
+	}
+	return false
+}
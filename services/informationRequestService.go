@@ -0,0 +1,306 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/events"
+	"github.com/techagentng/citizenx/geo"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/textfilter"
+	"gorm.io/gorm"
+)
+
+// informationReminderBatch is how many reminders are sent per run
+const informationReminderBatch = 200
+
+var (
+	// ErrInformationRequestNotFound is returned for information requests
+	// that do not exist or were made of another reporter.
+	ErrInformationRequestNotFound = errors.New("information request not found")
+	// ErrInformationRequestClosed is returned when answering a request that
+	// was already answered or has expired.
+	ErrInformationRequestClosed = errors.New("information request is no longer open")
+	// ErrInformationRequestOpen is returned when asking for information
+	// about a report that already has an open request.
+	ErrInformationRequestOpen = errors.New("report already has an open information request")
+	// ErrInvalidInformationRequest is returned for requests that name no
+	// fields or unknown ones, and for answers that change fields that were
+	// not requested.
+	ErrInvalidInformationRequest = errors.New("invalid information request")
+)
+
+// InformationRequestService lets moderators ask reporters for more on a
+// report, reopening only the requested fields for editing
+type InformationRequestService interface {
+	RequestInformation(moderatorID uint, reportID string, fields []string, message string) (*models.InformationRequest, error)
+	ReportRequests(reportID string) ([]models.InformationRequest, error)
+	UserRequests(userID uint, openOnly bool) ([]models.InformationRequest, error)
+	OpenRequest(userID, requestID uint) (*models.InformationRequest, error)
+	Answer(userID, requestID uint, answer models.InformationAnswer) error
+	RemindAndExpire() (reminded int, expired int64, err error)
+}
+
+type informationRequestService struct {
+	Config              *config.Config
+	requestRepo         db.InformationRequestRepository
+	incidentRepo        db.IncidentReportRepository
+	notificationService NotificationService
+	textFilter          *textfilter.Filter
+}
+
+// NewInformationRequestService creates a new instance of
+// InformationRequestService. Requests stay open for information_request_days
+// and the reporter is reminded every information_reminder_hours.
+func NewInformationRequestService(requestRepo db.InformationRequestRepository, incidentRepo db.IncidentReportRepository, notificationService NotificationService, conf *config.Config) InformationRequestService {
+	return &informationRequestService{
+		Config:              conf,
+		requestRepo:         requestRepo,
+		incidentRepo:        incidentRepo,
+		notificationService: notificationService,
+		textFilter:          textfilter.New(strings.Split(conf.BannedWords, ",")),
+	}
+}
+
+// RequestInformation asks the reporter of a pending report for the given
+// fields. The reporter is notified straight away.
+func (s *informationRequestService) RequestInformation(moderatorID uint, reportID string, fields []string, message string) (*models.InformationRequest, error) {
+	if _, err := uuid.Parse(reportID); err != nil {
+		return nil, ErrReportNotFound
+	}
+	fields, err := informationFields(fields)
+	if err != nil {
+		return nil, err
+	}
+	report, err := s.incidentRepo.GetIncidentReportByID(reportID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrReportNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if status := strings.ToLower(report.ReportStatus); status != "" && status != "pending" {
+		return nil, ErrReportNotPending
+	}
+	open, err := s.requestRepo.HasOpenRequest(reportID)
+	if err != nil {
+		return nil, err
+	}
+	if open {
+		return nil, ErrInformationRequestOpen
+	}
+
+	now := time.Now()
+	request := &models.InformationRequest{
+		ReportID:    report.ID,
+		UserID:      report.UserID,
+		ModeratorID: moderatorID,
+		Fields:      fields,
+		Message:     strings.TrimSpace(message),
+		Status:      models.InformationRequestOpen,
+		ExpiresAt:   now.AddDate(0, 0, s.Config.InformationRequestDays).Unix(),
+		RemindedAt:  now.Unix(),
+		CreatedAt:   now.Unix(),
+	}
+	err = s.requestRepo.CreateRequest(request, events.InformationRequested{
+		ReportID:    report.ID,
+		UserID:      report.UserID,
+		ModeratorID: moderatorID,
+		Fields:      fields,
+		OccurredAt:  now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return request, nil
+}
+
+// informationFields checks that fields name parts of a report that can be
+// requested, dropping repeats
+func informationFields(fields []string) ([]string, error) {
+	known := map[string]bool{}
+	for _, field := range models.InformationFields {
+		known[field] = true
+	}
+	var cleaned []string
+	seen := map[string]bool{}
+	for _, field := range fields {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if !known[field] {
+			return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidInformationRequest, field)
+		}
+		if !seen[field] {
+			seen[field] = true
+			cleaned = append(cleaned, field)
+		}
+	}
+	if len(cleaned) == 0 {
+		return nil, fmt.Errorf("%w: name at least one of %s", ErrInvalidInformationRequest, strings.Join(models.InformationFields, ", "))
+	}
+	return cleaned, nil
+}
+
+func (s *informationRequestService) ReportRequests(reportID string) ([]models.InformationRequest, error) {
+	return s.requestRepo.ReportRequests(reportID)
+}
+
+func (s *informationRequestService) UserRequests(userID uint, openOnly bool) ([]models.InformationRequest, error) {
+	return s.requestRepo.UserRequests(userID, openOnly)
+}
+
+// OpenRequest returns one of the reporter's requests that can still be
+// answered
+func (s *informationRequestService) OpenRequest(userID, requestID uint) (*models.InformationRequest, error) {
+	request, err := s.requestRepo.GetRequest(requestID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && request.UserID != userID) {
+		return nil, ErrInformationRequestNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if request.Status != models.InformationRequestOpen || request.ExpiresAt <= time.Now().Unix() {
+		return nil, ErrInformationRequestClosed
+	}
+	return request, nil
+}
+
+// Answer applies the reporter's answer to their report. Only the requested
+// fields may change, and at least one of them must.
+func (s *informationRequestService) Answer(userID, requestID uint, answer models.InformationAnswer) error {
+	request, err := s.OpenRequest(userID, requestID)
+	if err != nil {
+		return err
+	}
+
+	changes := map[string]interface{}{}
+	steps := []struct {
+		field string
+		given bool
+		apply func() error
+	}{
+		{models.InformationDescription, answer.Description != nil, func() error {
+			description := strings.TrimSpace(*answer.Description)
+			if description == "" {
+				return fmt.Errorf("%w: the description cannot be empty", ErrInvalidInformationRequest)
+			}
+			changes["DescriptionRaw"] = description
+			changes["Description"] = s.textFilter.Mask(description)
+			return nil
+		}},
+		{models.InformationDate, answer.DateOfIncidence != nil, func() error {
+			changes["DateOfIncidence"] = strings.TrimSpace(*answer.DateOfIncidence)
+			return nil
+		}},
+		{models.InformationAddress, answer.Address != nil, func() error {
+			changes["Address"] = strings.TrimSpace(*answer.Address)
+			return nil
+		}},
+		{models.InformationLocation, answer.Latitude != nil || answer.Longitude != nil, func() error {
+			if answer.Latitude == nil || answer.Longitude == nil ||
+				math.Abs(*answer.Latitude) > 90 || math.Abs(*answer.Longitude) > 180 {
+				return fmt.Errorf("%w: a location needs a valid latitude and longitude", ErrInvalidInformationRequest)
+			}
+			changes["Latitude"] = *answer.Latitude
+			changes["Longitude"] = *answer.Longitude
+			changes["PlusCode"] = geo.EncodePlusCode(*answer.Latitude, *answer.Longitude, geo.PlusCodeLength)
+			changes["LocationApproximate"] = false
+			return nil
+		}},
+		{models.InformationPhotos, len(answer.Media) > 0, func() error {
+			return s.attachMedia(request.ReportID.String(), answer.Media, changes)
+		}},
+	}
+	var changed []string
+	for _, step := range steps {
+		if !step.given {
+			continue
+		}
+		if !request.Requested(step.field) {
+			return fmt.Errorf("%w: %s was not requested", ErrInvalidInformationRequest, step.field)
+		}
+		if err := step.apply(); err != nil {
+			return err
+		}
+		changed = append(changed, step.field)
+	}
+	if len(changed) == 0 {
+		return fmt.Errorf("%w: the answer changes nothing", ErrInvalidInformationRequest)
+	}
+
+	now := time.Now()
+	request.AnsweredAt = now.Unix()
+	answered, err := s.requestRepo.AnswerRequest(request, changes, events.InformationProvided{
+		RequestID:  request.ID,
+		ReportID:   request.ReportID,
+		UserID:     userID,
+		Fields:     changed,
+		OccurredAt: now,
+	})
+	if err != nil {
+		return err
+	}
+	if !answered {
+		return ErrInformationRequestClosed
+	}
+	return nil
+}
+
+// attachMedia adds the URLs of media uploaded with an answer to the
+// report's lists
+func (s *informationRequestService) attachMedia(reportID string, media []models.Media, changes map[string]interface{}) error {
+	report, err := s.incidentRepo.GetIncidentReportByID(reportID)
+	if err != nil {
+		return err
+	}
+	feed, thumbnails, fullsize := []string{report.FeedURLs}, []string{report.ThumbnailURLs}, []string{report.FullSizeURLs}
+	for _, m := range media {
+		feed = append(feed, m.FeedURL)
+		thumbnails = append(thumbnails, m.ThumbnailURL)
+		fullsize = append(fullsize, m.FullSizeURL)
+	}
+	changes["FeedURLs"] = joinNonEmpty(",", feed...)
+	changes["ThumbnailURLs"] = joinNonEmpty(",", thumbnails...)
+	changes["FullSizeURLs"] = joinNonEmpty(",", fullsize...)
+	return nil
+}
+
+// RemindAndExpire closes requests past their expiry and reminds reporters
+// of the rest every information_reminder_hours
+func (s *informationRequestService) RemindAndExpire() (int, int64, error) {
+	now := time.Now()
+	expired, err := s.requestRepo.ExpireRequests(now.Unix())
+	if err != nil || s.Config.InformationReminderHours <= 0 {
+		return 0, expired, err
+	}
+
+	due, err := s.requestRepo.DueReminders(now.Add(-time.Duration(s.Config.InformationReminderHours)*time.Hour).Unix(), informationReminderBatch)
+	if err != nil {
+		return 0, expired, err
+	}
+	reminded := 0
+	for _, request := range due {
+		days := int(math.Ceil(time.Until(time.Unix(request.ExpiresAt, 0)).Hours() / 24))
+		message := fmt.Sprintf("A moderator is still waiting for more information about your incident report (%s). You have %d more day(s) to add it.",
+			strings.ReplaceAll(strings.Join(request.Fields, ", "), "_", " "), days)
+		sent, err := s.notificationService.Remind(request.UserID, "More information needed", message)
+		if err != nil {
+			log.Printf("reminding user %d of information request %d: %v", request.UserID, request.ID, err)
+			continue
+		}
+		// Reporters who turned reminders off are not asked again either
+		if err := s.requestRepo.MarkReminded(request.ID, now.Unix()); err != nil {
+			return reminded, expired, err
+		}
+		if sent {
+			reminded++
+		}
+	}
+	return reminded, expired, nil
+}
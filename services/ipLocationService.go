@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"log"
+	"net"
+	"time"
+
+	"github.com/techagentng/citizenx/cache"
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/geo"
+	"github.com/techagentng/citizenx/models"
+)
+
+// IPLocationCacheTTL is how long the lookup of an address is reused. Mobile
+// clients submit drafts and media from the same address in quick
+// succession, and lookups are billed per request.
+const IPLocationCacheTTL = 6 * time.Hour
+
+// IPLocationService places requests by the client's IP address
+type IPLocationService interface {
+	Locate(ctx context.Context, ip string) geo.IPLocation
+	Apply(ctx context.Context, report *models.IncidentReport, ip string)
+}
+
+type ipLocationService struct {
+	Config  *config.Config
+	locator geo.IPLocator
+	lookups *cache.TTL[*geo.IPLocation]
+}
+
+// NewIPLocationService creates a new instance of IPLocationService. Without
+// a locator nothing is known of any address.
+func NewIPLocationService(locator geo.IPLocator, conf *config.Config) IPLocationService {
+	return &ipLocationService{
+		Config:  conf,
+		locator: locator,
+		lookups: cache.New[*geo.IPLocation](IPLocationCacheTTL),
+	}
+}
+
+// Locate looks ip up, returning an empty location when it is unknown or the
+// lookup fails; a failed lookup never holds up a submission. Private and
+// loopback addresses are those of our own proxies, not of the client - seen
+// when TrustedProxies leaves out a proxy in front of the server - and are
+// never looked up.
+func (s *ipLocationService) Locate(ctx context.Context, ip string) geo.IPLocation {
+	parsed := net.ParseIP(ip)
+	if s.locator == nil || parsed == nil || parsed.IsPrivate() || parsed.IsLoopback() || parsed.IsUnspecified() {
+		return geo.IPLocation{}
+	}
+	location, err := s.lookups.GetOrLoad(parsed.String(), func() (*geo.IPLocation, error) {
+		location, err := s.locator.LocateIP(ctx, parsed)
+		if location == nil && err == nil {
+			location = &geo.IPLocation{}
+		}
+		return location, err
+	})
+	if err != nil {
+		log.Printf("locating %s: %v", ip, err)
+		return geo.IPLocation{}
+	}
+	return *location
+}
+
+// Apply places a report submitted without coordinates at its client's
+// approximate location, and flags reports sent through anonymizers or
+// datacenters for closer moderation.
+func (s *ipLocationService) Apply(ctx context.Context, report *models.IncidentReport, ip string) {
+	location := s.Locate(ctx, ip)
+	switch {
+	case location.Anonymous:
+		report.NetworkFlag = models.NetworkVPN
+	case location.Hosting:
+		report.NetworkFlag = models.NetworkHosting
+	}
+	// A VPN exit says nothing about where the reporter is
+	if report.Latitude == 0 && report.Longitude == 0 && location.HasPoint() && report.NetworkFlag == "" {
+		report.Latitude, report.Longitude = location.Latitude, location.Longitude
+		report.LocationApproximate = true
+	}
+}
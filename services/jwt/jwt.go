@@ -12,13 +12,11 @@ import (
 const AccessTokenValidity = time.Hour * 24 * 7   // 7days
 const RefreshTokenValidity = time.Hour * 24 * 30 //30 days
 
-// verifyAccessToken verifies a token
+// verifyAccessToken verifies a token against the key it names, or the
+// shared secret for tokens signed before the key set
 func verifyToken(tokenString string, secret string) (*jwt.Token, error) {
 	return jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("Unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(secret), nil
+		return verificationKey(token, secret)
 	})
 }
 
@@ -75,8 +73,7 @@ func GenerateToken(email string, secret string, isAdmin bool, id uint, roleName
 	claims := GenerateClaims(email, isAdmin, id, roleName)
 
 	// Create and sign the token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(secret))
+	tokenString, err := sign(claims, secret)
 	if err != nil {
 		return "", err
 	}
@@ -91,12 +88,8 @@ func GenerateMacAddressToken(mac string, secret string) (string, error) {
 	// Generate claims
 	claims := GenerateMacAddressClaims(mac)
 
-	// Create a new token object, specifying signing method and the claims
-	// you would like it to contain.
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-
-	// Sign and get the complete encoded token as a string using the secret
-	tokenString, err := token.SignedString([]byte(secret))
+	// Sign and get the complete encoded token as a string
+	tokenString, err := sign(claims, secret)
 	if err != nil {
 		return "", err
 	}
@@ -132,10 +125,8 @@ func GenerateRefreshToken(email string, secret string, isAdmin bool, id uint, ro
 		"type":     "refresh_token",
 	}
 
-	refreshToken := jwt.NewWithClaims(jwt.SigningMethodHS256, refreshTokenClaims)
-
-	// Sign and get the complete encoded token as a string using the secret
-	refreshTokenString, err := refreshToken.SignedString([]byte(secret))
+	// Sign and get the complete encoded token as a string
+	refreshTokenString, err := sign(refreshTokenClaims, secret)
 	if err != nil {
 		return "", err
 	}
@@ -143,6 +134,59 @@ func GenerateRefreshToken(email string, secret string, isAdmin bool, id uint, ro
 	return refreshTokenString, nil
 }
 
+// GenerateSessionTokenPair issues the tokens of a sign-in session. The
+// access token lasts accessTTL, never beyond the session's absolute expiry;
+// the refresh token lasts until that expiry and carries refreshID so a
+// rotated-out refresh token can be recognised.
+func GenerateSessionTokenPair(email string, secret string, isAdmin bool, id uint, roleName string, sessionID string, refreshID string, accessTTL time.Duration, sessionExpires time.Time) (accessToken string, refreshToken string, err error) {
+	if secret == "" {
+		return "", "", errors.New("secret key is required", errors.ErrInternalServerError.Status)
+	}
+
+	accessExpires := time.Now().Add(accessTTL)
+	if accessExpires.After(sessionExpires) {
+		accessExpires = sessionExpires
+	}
+	accessClaims := GenerateClaims(email, isAdmin, id, roleName)
+	accessClaims["exp"] = accessExpires.Unix()
+	accessClaims["sid"] = sessionID
+	accessToken, err = sign(accessClaims, secret)
+	if err != nil {
+		return "", "", err
+	}
+
+	refreshClaims := jwt.MapClaims{
+		"email":    email,
+		"exp":      sessionExpires.Unix(),
+		"is_admin": isAdmin,
+		"id":       id,
+		"role":     roleName,
+		"type":     "refresh_token",
+		"sid":      sessionID,
+		"rti":      refreshID,
+	}
+	refreshToken, err = sign(refreshClaims, secret)
+	if err != nil {
+		return "", "", err
+	}
+	return accessToken, refreshToken, nil
+}
+
+// GenerateStepUpToken issues a short-lived token proving the user of a
+// session recently confirmed who they are
+func GenerateStepUpToken(id uint, sessionID string, secret string, ttl time.Duration) (string, error) {
+	if secret == "" {
+		return "", errors.New("secret key is required", errors.ErrInternalServerError.Status)
+	}
+	claims := jwt.MapClaims{
+		"id":   id,
+		"sid":  sessionID,
+		"exp":  time.Now().Add(ttl).Unix(),
+		"type": "step_up",
+	}
+	return sign(claims, secret)
+}
+
 func GenerateClaims(email string, isAdmin bool, id uint, roleName string) jwt.MapClaims {
 	accessClaims := jwt.MapClaims{
 		"email":    email,
@@ -175,11 +219,8 @@ func GeneratePasswordResetToken(userID uint, secret string) (string, error) {
 		"type":    "password_reset_token",
 	}
 
-	// Create a new token object with the claims
-	resetToken := jwt.NewWithClaims(jwt.SigningMethodHS256, resetTokenClaims)
-
-	// Sign and get the complete encoded token as a string using the secret
-	resetTokenString, err := resetToken.SignedString([]byte(secret))
+	// Sign and get the complete encoded token as a string
+	resetTokenString, err := sign(resetTokenClaims, secret)
 	if err != nil {
 		return "", err
 	}
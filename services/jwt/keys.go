@@ -0,0 +1,226 @@
+package jwt
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	// AlgorithmRS256 signs with a 2048-bit RSA key
+	AlgorithmRS256 = "RS256"
+	// AlgorithmEdDSA signs with an Ed25519 key
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// SigningKey is one key pair of the key set, named by its key ID
+type SigningKey struct {
+	ID        string
+	Algorithm string
+	private   crypto.PrivateKey
+	public    crypto.PublicKey
+}
+
+// KeySet signs tokens with its active key and validates tokens signed by
+// any of its keys. While AcceptHMAC is set, tokens signed with the shared
+// secret, as all tokens were before the key set, are still accepted.
+type KeySet struct {
+	Active     *SigningKey
+	Keys       map[string]*SigningKey
+	AcceptHMAC bool
+}
+
+var (
+	keysMu sync.RWMutex
+	// keys is empty until SetKeys is called, so tokens are signed with the
+	// shared secret as before
+	keys = &KeySet{Keys: map[string]*SigningKey{}, AcceptHMAC: true}
+)
+
+// SetKeys replaces the key set used to sign and validate tokens
+func SetKeys(set *KeySet) {
+	keysMu.Lock()
+	keys = set
+	keysMu.Unlock()
+}
+
+func currentKeys() *KeySet {
+	keysMu.RLock()
+	defer keysMu.RUnlock()
+	return keys
+}
+
+// NewKeySet builds a key set from stored keys, signing with the one named
+// activeID
+func NewKeySet(stored []StoredKey, activeID string, acceptHMAC bool) (*KeySet, error) {
+	set := &KeySet{Keys: map[string]*SigningKey{}, AcceptHMAC: acceptHMAC}
+	for _, s := range stored {
+		key, err := ParseSigningKey(s)
+		if err != nil {
+			return nil, err
+		}
+		if key.ID == activeID {
+			set.Active = key
+		}
+		set.Keys[key.ID] = key
+	}
+	return set, nil
+}
+
+// StoredKey is a key pair as kept in the database, in PEM
+type StoredKey struct {
+	ID         string
+	Algorithm  string
+	PrivateKey string
+	PublicKey  string
+}
+
+// ParseSigningKey reads a stored key pair
+func ParseSigningKey(s StoredKey) (*SigningKey, error) {
+	key := &SigningKey{ID: s.ID, Algorithm: s.Algorithm}
+	var err error
+	switch s.Algorithm {
+	case AlgorithmRS256:
+		if key.private, err = jwt.ParseRSAPrivateKeyFromPEM([]byte(s.PrivateKey)); err == nil {
+			key.public, err = jwt.ParseRSAPublicKeyFromPEM([]byte(s.PublicKey))
+		}
+	case AlgorithmEdDSA:
+		if key.private, err = jwt.ParseEdPrivateKeyFromPEM([]byte(s.PrivateKey)); err == nil {
+			key.public, err = jwt.ParseEdPublicKeyFromPEM([]byte(s.PublicKey))
+		}
+	default:
+		err = fmt.Errorf("unsupported algorithm %q", s.Algorithm)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("signing key %s: %w", s.ID, err)
+	}
+	return key, nil
+}
+
+// GenerateStoredKey creates a new key pair for algorithm, encoded for
+// storage
+func GenerateStoredKey(id, algorithm string) (*StoredKey, error) {
+	var private crypto.PrivateKey
+	var public crypto.PublicKey
+	switch algorithm {
+	case AlgorithmRS256:
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return nil, err
+		}
+		private, public = key, &key.PublicKey
+	case AlgorithmEdDSA:
+		pub, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		private, public = key, pub
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q, use %s or %s", algorithm, AlgorithmRS256, AlgorithmEdDSA)
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		return nil, err
+	}
+	return &StoredKey{
+		ID:         id,
+		Algorithm:  algorithm,
+		PrivateKey: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER})),
+		PublicKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})),
+	}, nil
+}
+
+func (k *SigningKey) method() jwt.SigningMethod {
+	if k.Algorithm == AlgorithmEdDSA {
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
+}
+
+// sign signs claims with the active key, or with secret while there is none
+func sign(claims jwt.MapClaims, secret string) (string, error) {
+	set := currentKeys()
+	if set.Active == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	}
+	token := jwt.NewWithClaims(set.Active.method(), claims)
+	token.Header["kid"] = set.Active.ID
+	return token.SignedString(set.Active.private)
+}
+
+// verificationKey picks the key a token claims to be signed with
+func verificationKey(token *jwt.Token, secret string) (interface{}, error) {
+	set := currentKeys()
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); ok {
+		if set.Active != nil && !set.AcceptHMAC {
+			return nil, fmt.Errorf("tokens signed with the shared secret are no longer accepted")
+		}
+		return []byte(secret), nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := set.Keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if token.Method.Alg() != key.method().Alg() {
+		return nil, fmt.Errorf("signing key %q does not use %s", kid, token.Method.Alg())
+	}
+	return key.public, nil
+}
+
+// JWK is the public half of a signing key as published in a JWKS document
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+// JWKS is the document third parties fetch to validate CitizenX tokens
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// PublicJWKS returns the public keys of the current key set
+func PublicJWKS() JWKS {
+	set := currentKeys()
+	doc := JWKS{Keys: []JWK{}}
+	for _, key := range set.Keys {
+		jwk := JWK{KeyID: key.ID, Use: "sig", Algorithm: key.Algorithm}
+		switch public := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		default:
+			continue
+		}
+		doc.Keys = append(doc.Keys, jwk)
+	}
+	sort.Slice(doc.Keys, func(i, j int) bool { return doc.Keys[i].KeyID < doc.Keys[j].KeyID })
+	return doc
+}
@@ -0,0 +1,189 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/events"
+	"github.com/techagentng/citizenx/geo"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrLandmarkNotFound is returned for landmarks that do not exist.
+	ErrLandmarkNotFound = errors.New("landmark not found")
+	// ErrReportLocationNotFound is returned for reports whose address has
+	// not been structured.
+	ErrReportLocationNotFound = errors.New("report has no structured location")
+)
+
+// LandmarkService turns written locations into structured ones, matched to
+// known landmarks or geocoded
+type LandmarkService interface {
+	CreateLandmark(landmark *models.Landmark, adminID uint) error
+	SearchLandmarks(query, stateName string, page int) ([]models.Landmark, error)
+	LandmarkReports(landmarkID uint, page int) ([]models.IncidentReport, error)
+	Normalize(ctx context.Context, text, stateName, lgaName string) (*models.ReportLocation, error)
+	GetReportLocation(reportID string) (*models.ReportLocation, error)
+	Subscribe(bus events.Bus)
+}
+
+type landmarkService struct {
+	Config       *config.Config
+	landmarkRepo db.LandmarkRepository
+	geocoder     geo.AddressGeocoder
+}
+
+// NewLandmarkService creates a new instance of LandmarkService. Without a
+// geocoder, only locations naming a known landmark are placed.
+func NewLandmarkService(landmarkRepo db.LandmarkRepository, geocoder geo.AddressGeocoder, conf *config.Config) LandmarkService {
+	return &landmarkService{
+		Config:       conf,
+		landmarkRepo: landmarkRepo,
+		geocoder:     geocoder,
+	}
+}
+
+func (s *landmarkService) CreateLandmark(landmark *models.Landmark, adminID uint) error {
+	landmark.ID = 0
+	landmark.Name = strings.TrimSpace(landmark.Name)
+	landmark.NormalizedName = geo.ParseAddress(landmark.Name).Landmark
+	landmark.CreatedBy = adminID
+	landmark.CreatedAt = time.Now().Unix()
+	return s.landmarkRepo.CreateLandmark(landmark)
+}
+
+func (s *landmarkService) SearchLandmarks(query, stateName string, page int) ([]models.Landmark, error) {
+	return s.landmarkRepo.SearchLandmarks(geo.NormalizeText(query), stateName, page)
+}
+
+func (s *landmarkService) LandmarkReports(landmarkID uint, page int) ([]models.IncidentReport, error) {
+	if _, err := s.landmarkRepo.GetLandmark(landmarkID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrLandmarkNotFound
+		}
+		return nil, err
+	}
+	return s.landmarkRepo.LandmarkReports(landmarkID, page)
+}
+
+// Normalize parses a written location and places it, preferring a known
+// landmark of that name in the same area, LGA or state and otherwise
+// geocoding the text within the state and LGA given
+func (s *landmarkService) Normalize(ctx context.Context, text, stateName, lgaName string) (*models.ReportLocation, error) {
+	address, location := parseLocation(text)
+	if address.Landmark == "" {
+		return location, nil
+	}
+
+	candidates, err := s.landmarkRepo.FindLandmarks(address.Landmark)
+	if err != nil {
+		return nil, err
+	}
+	if landmark := bestLandmark(candidates, address, stateName, lgaName); landmark != nil {
+		location.LandmarkID = &landmark.ID
+		location.Landmark = landmark
+		location.Latitude, location.Longitude = landmark.Latitude, landmark.Longitude
+		location.Source = models.LocationFromLandmark
+		return location, nil
+	}
+
+	if s.geocoder == nil {
+		return location, nil
+	}
+	query := joinNonEmpty(", ", address.Landmark, address.Area, lgaName, stateName)
+	point, err := s.geocoder.Geocode(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	if point != nil {
+		location.Latitude, location.Longitude = point.Latitude, point.Longitude
+		location.Source = models.LocationFromGeocoder
+	}
+	return location, nil
+}
+
+// parseLocation returns the parts of a written location, not yet placed
+func parseLocation(text string) (geo.Address, *models.ReportLocation) {
+	address := geo.ParseAddress(text)
+	return address, &models.ReportLocation{
+		RawText:        strings.TrimSpace(text),
+		NormalizedText: address.Normalized,
+		Relation:       address.Relation,
+		LandmarkText:   address.Landmark,
+		Area:           address.Area,
+		Source:         models.LocationUnresolved,
+	}
+}
+
+// bestLandmark picks the landmark the address most likely means. Chains
+// such as banks have many branches of one name, so a match must agree with
+// the address on area, LGA or state unless it is the only one.
+func bestLandmark(candidates []models.Landmark, address geo.Address, stateName, lgaName string) *models.Landmark {
+	var best *models.Landmark
+	bestScore := 0
+	for i := range candidates {
+		c := &candidates[i]
+		score := 0
+		if address.Area != "" && geo.NormalizeText(c.Area) == address.Area {
+			score += 4
+		}
+		if lgaName != "" && strings.EqualFold(c.LGAName, lgaName) {
+			score += 2
+		}
+		if stateName != "" && strings.EqualFold(c.StateName, stateName) {
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = c, score
+		}
+	}
+	if best == nil && len(candidates) == 1 {
+		return &candidates[0]
+	}
+	return best
+}
+
+func (s *landmarkService) GetReportLocation(reportID string) (*models.ReportLocation, error) {
+	location, err := s.landmarkRepo.GetReportLocation(reportID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrReportLocationNotFound
+	}
+	return location, err
+}
+
+// Subscribe structures the address of each new report
+func (s *landmarkService) Subscribe(bus events.Bus) {
+	bus.Subscribe(events.ReportCreatedEvent, s.handleReportCreated)
+}
+
+func (s *landmarkService) handleReportCreated(ctx context.Context, event events.Event) error {
+	e, ok := event.(events.ReportCreated)
+	if !ok {
+		return nil
+	}
+	report, err := s.landmarkRepo.GetReportAddress(e.ReportID.String())
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && strings.TrimSpace(report.Address) == "") {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	location, err := s.Normalize(ctx, report.Address, report.StateName, report.LGAName)
+	if err != nil {
+		// The parsed address is still worth keeping when geocoding fails
+		log.Printf("placing address of report %s: %v", report.ID, err)
+		_, location = parseLocation(report.Address)
+	}
+	location.ReportID = report.ID
+	location.Landmark = nil
+	location.CreatedAt = time.Now().Unix()
+	return s.landmarkRepo.SaveReportLocation(location)
+}
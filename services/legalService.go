@@ -0,0 +1,179 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/techagentng/citizenx/cache"
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrLegalDocumentNotFound is returned for documents that do not exist
+	// or, when publishing, are already published.
+	ErrLegalDocumentNotFound = errors.New("legal document not found")
+	// ErrNotCurrentLegalDocument is returned when accepting a document that
+	// is not the current version of its kind.
+	ErrNotCurrentLegalDocument = errors.New("only the current version of a legal document can be accepted")
+)
+
+// ConsentCacheTTL is how long the current documents and each user's pending
+// ones are kept in memory between requests
+const ConsentCacheTTL = time.Minute
+
+// LegalService serves the Terms of Service and Privacy Policy and tracks
+// which versions each user has accepted
+type LegalService interface {
+	Documents(kind string) ([]models.LegalDocument, error)
+	CreateDocument(document *models.LegalDocument, adminID uint) error
+	Publish(id uint) error
+	Current() ([]models.LegalDocument, error)
+	CurrentOf(kind string) (*models.LegalDocument, error)
+	Status(userID uint) (*models.ConsentStatus, error)
+	Pending(userID uint) ([]models.LegalDocument, error)
+	Accept(userID uint, documentIDs []uint, ip string) error
+}
+
+type legalService struct {
+	Config    *config.Config
+	legalRepo db.LegalRepository
+	current   *cache.TTL[[]models.LegalDocument]
+	pending   *cache.TTL[[]models.LegalDocument]
+}
+
+// NewLegalService creates a new instance of LegalService
+func NewLegalService(legalRepo db.LegalRepository, conf *config.Config) LegalService {
+	return &legalService{
+		Config:    conf,
+		legalRepo: legalRepo,
+		current:   cache.New[[]models.LegalDocument](ConsentCacheTTL),
+		pending:   cache.New[[]models.LegalDocument](ConsentCacheTTL),
+	}
+}
+
+func (s *legalService) Documents(kind string) ([]models.LegalDocument, error) {
+	return s.legalRepo.ListDocuments(strings.ToLower(strings.TrimSpace(kind)))
+}
+
+// CreateDocument stores a draft version. Users see it, and must accept it,
+// only once it is published.
+func (s *legalService) CreateDocument(document *models.LegalDocument, adminID uint) error {
+	document.ID = 0
+	document.Version = strings.TrimSpace(document.Version)
+	document.PublishedAt = nil
+	document.CreatedBy = adminID
+	document.CreatedAt = time.Now().Unix()
+	return s.legalRepo.CreateDocument(document)
+}
+
+// Publish makes a draft the current version of its kind. Every user is asked
+// to accept it on their next request.
+func (s *legalService) Publish(id uint) error {
+	err := s.legalRepo.PublishDocument(id, time.Now().Unix())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrLegalDocumentNotFound
+	}
+	if err != nil {
+		return err
+	}
+	s.current.Clear()
+	s.pending.Clear()
+	return nil
+}
+
+// Current returns the current version of each kind of document
+func (s *legalService) Current() ([]models.LegalDocument, error) {
+	return s.current.GetOrLoad("current", s.legalRepo.CurrentDocuments)
+}
+
+func (s *legalService) CurrentOf(kind string) (*models.LegalDocument, error) {
+	documents, err := s.Current()
+	if err != nil {
+		return nil, err
+	}
+	for i := range documents {
+		if documents[i].Kind == kind {
+			return &documents[i], nil
+		}
+	}
+	return nil, ErrLegalDocumentNotFound
+}
+
+func (s *legalService) Status(userID uint) (*models.ConsentStatus, error) {
+	accepted, err := s.legalRepo.Acceptances(userID)
+	if err != nil {
+		return nil, err
+	}
+	pending, err := s.Pending(userID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.ConsentStatus{Accepted: accepted, Pending: pending}, nil
+}
+
+// Pending returns the current documents the user has not accepted
+func (s *legalService) Pending(userID uint) ([]models.LegalDocument, error) {
+	return s.pending.GetOrLoad(strconv.FormatUint(uint64(userID), 10), func() ([]models.LegalDocument, error) {
+		current, err := s.Current()
+		if err != nil {
+			return nil, err
+		}
+		ids := make([]uint, 0, len(current))
+		for _, document := range current {
+			ids = append(ids, document.ID)
+		}
+		accepted, err := s.legalRepo.AcceptedDocumentIDs(userID, ids)
+		if err != nil {
+			return nil, err
+		}
+		done := make(map[uint]bool, len(accepted))
+		for _, id := range accepted {
+			done[id] = true
+		}
+		pending := []models.LegalDocument{}
+		for _, document := range current {
+			if !done[document.ID] {
+				pending = append(pending, document)
+			}
+		}
+		return pending, nil
+	})
+}
+
+// Accept records the user accepting current documents from ip
+func (s *legalService) Accept(userID uint, documentIDs []uint, ip string) error {
+	current, err := s.Current()
+	if err != nil {
+		return err
+	}
+	byID := make(map[uint]models.LegalDocument, len(current))
+	for _, document := range current {
+		byID[document.ID] = document
+	}
+	now := time.Now().Unix()
+	acceptances := make([]models.LegalAcceptance, 0, len(documentIDs))
+	for _, id := range documentIDs {
+		document, ok := byID[id]
+		if !ok {
+			return ErrNotCurrentLegalDocument
+		}
+		acceptances = append(acceptances, models.LegalAcceptance{
+			UserID:     userID,
+			DocumentID: document.ID,
+			Kind:       document.Kind,
+			Version:    document.Version,
+			IP:         ip,
+			AcceptedAt: now,
+		})
+	}
+	if err := s.legalRepo.CreateAcceptances(acceptances); err != nil {
+		return err
+	}
+	s.pending.Delete(strconv.FormatUint(uint64(userID), 10))
+	return nil
+}
@@ -1,16 +1,21 @@
 package services
 
 import (
+	"time"
+
 	"github.com/techagentng/citizenx/config"
 	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/events"
 	"github.com/techagentng/citizenx/models"
 )
 
 // LikeService interface
 type LikeService interface {
-	LikeReport(userID uint, reportID string) error
-	DownVoteReport(userID uint, reportID string) error
+	LikeReport(userID uint, reportID string) (bool, error)
+	DownVoteReport(userID uint, reportID string) (bool, error)
+	RetractVote(userID uint, reportID string) (bool, error)
 	GetVoteCounts(reportID string) (int, int, error)
+	RefreshCredibility() (int64, error)
 }
 
 // likeService struct
@@ -27,16 +32,42 @@ func NewLikeService(likeRepo db.LikeRepository, conf *config.Config) LikeService
 	}
 }
 
-// LikeReport handles the logic for liking a report
-func (lk *likeService) LikeReport(userID uint, reportID string) error {
-	var like models.Like
-	like.UserID = userID
-	return lk.likeRepo.UpvoteReport(userID, reportID)
+// LikeReport upvotes a report, replacing a downvote, and reports false when
+// the user had already upvoted it
+func (lk *likeService) LikeReport(userID uint, reportID string) (bool, error) {
+	return lk.likeRepo.CastVote(userID, reportID, models.VoteUp, events.ReportVoted{
+		ReportID:   reportID,
+		UserID:     userID,
+		VoteType:   "upvote",
+		OccurredAt: time.Now(),
+	})
+}
+
+// DownVoteReport downvotes a report, replacing an upvote, and reports false
+// when the user had already downvoted it
+func (lk *likeService) DownVoteReport(userID uint, reportID string) (bool, error) {
+	return lk.likeRepo.CastVote(userID, reportID, models.VoteDown, events.ReportVoted{
+		ReportID:   reportID,
+		UserID:     userID,
+		VoteType:   "downvote",
+		OccurredAt: time.Now(),
+	})
+}
+
+// RetractVote withdraws the user's vote on a report, reporting false when
+// they had not voted on it
+func (lk *likeService) RetractVote(userID uint, reportID string) (bool, error) {
+	return lk.likeRepo.RetractVote(userID, reportID, events.ReportVoted{
+		ReportID:   reportID,
+		UserID:     userID,
+		VoteType:   "retracted",
+		OccurredAt: time.Now(),
+	})
 }
 
-// DownVoteReport handles the logic for disliking a report
-func (lk *likeService) DownVoteReport(userID uint, reportID string) error {
-	return lk.likeRepo.DownVoteReport(userID, reportID)
+// RefreshCredibility rescores the reports the trending feed can show
+func (lk *likeService) RefreshCredibility() (int64, error) {
+	return lk.likeRepo.RefreshCredibility(time.Now().AddDate(0, 0, -lk.Config.TrendingDays))
 }
 
 func (lk *likeService) GetVoteCounts(reportID string) (int, int, error) {
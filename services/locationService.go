@@ -0,0 +1,41 @@
+package services
+
+import (
+	"time"
+
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+)
+
+// LocationService applies the location history retention policy
+type LocationService interface {
+	DeleteHistory(userID uint) (int64, error)
+	PurgeExpired() (int64, error)
+}
+
+type locationService struct {
+	Config       *config.Config
+	locationRepo db.LocationRepository
+}
+
+// NewLocationService creates a new instance of LocationService
+func NewLocationService(locationRepo db.LocationRepository, conf *config.Config) LocationService {
+	return &locationService{
+		Config:       conf,
+		locationRepo: locationRepo,
+	}
+}
+
+// DeleteHistory removes the user's location history on their request
+func (s *locationService) DeleteHistory(userID uint) (int64, error) {
+	return s.locationRepo.DeleteUserLocationHistory(userID)
+}
+
+// PurgeExpired removes location history older than location_retention_days.
+// A retention of zero keeps history indefinitely.
+func (s *locationService) PurgeExpired() (int64, error) {
+	if s.Config.LocationRetentionDays <= 0 {
+		return 0, nil
+	}
+	return s.locationRepo.PurgeLocationHistory(time.Now().AddDate(0, 0, -s.Config.LocationRetentionDays))
+}
@@ -0,0 +1,179 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/mailingservices"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrMagicLinkInvalid is returned for forged, malformed and used links.
+	ErrMagicLinkInvalid = errors.New("this sign-in link is not valid; request a new one")
+	// ErrMagicLinkExpired is returned for links past their lifetime.
+	ErrMagicLinkExpired = errors.New("this sign-in link has expired; request a new one")
+	// ErrMagicLinkOtherDevice is returned when a link is opened on another
+	// device than the one that asked for it.
+	ErrMagicLinkOtherDevice = errors.New("open this sign-in link on the device you requested it from")
+)
+
+// MagicLinkService signs users in with single-use links sent to their email
+// instead of a password
+type MagicLinkService interface {
+	RequestLink(email, deviceID, ip string) error
+	VerifyLink(token, deviceID string) (*models.LoginResponse, error)
+}
+
+type magicLinkService struct {
+	Config        *config.Config
+	magicLinkRepo db.MagicLinkRepository
+	authRepo      db.AuthRepository
+	mailer        mailingservices.Mailer
+	sessions      SessionService
+}
+
+// NewMagicLinkService creates a new instance of MagicLinkService. Links
+// last magic_link_ttl_minutes and point at the web app under base_url.
+func NewMagicLinkService(magicLinkRepo db.MagicLinkRepository, authRepo db.AuthRepository, mailer mailingservices.Mailer, sessions SessionService, conf *config.Config) MagicLinkService {
+	return &magicLinkService{
+		Config:        conf,
+		magicLinkRepo: magicLinkRepo,
+		authRepo:      authRepo,
+		mailer:        mailer,
+		sessions:      sessions,
+	}
+}
+
+// RequestLink emails a sign-in link for the account with the email, if
+// there is one. Unknown addresses and rate-limited accounts are not
+// reported, so the endpoint cannot be used to find out who has an account.
+func (s *magicLinkService) RequestLink(email, deviceID, ip string) error {
+	user, err := s.magicLinkRepo.UserByEmail(strings.TrimSpace(email))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	sent, err := s.magicLinkRepo.CountLinks(user.ID, now.Add(-time.Hour).Unix())
+	if err != nil {
+		return err
+	}
+	if sent >= int64(s.Config.MagicLinkMaxPerHour) {
+		log.Printf("not sending sign-in link to user %d: %d sent in the last hour", user.ID, sent)
+		return nil
+	}
+
+	nonce := make([]byte, 32)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	encodedNonce := base64.RawURLEncoding.EncodeToString(nonce)
+	deviceHash := hashDevice(deviceID)
+	expires := now.Add(time.Duration(s.Config.MagicLinkTTLMinutes) * time.Minute).Unix()
+	if err := s.magicLinkRepo.CreateLink(&models.MagicLink{
+		UserID:     user.ID,
+		NonceHash:  hashNonce(encodedNonce),
+		DeviceHash: deviceHash,
+		IP:         ip,
+		ExpiresAt:  expires,
+		CreatedAt:  now.Unix(),
+	}); err != nil {
+		return err
+	}
+
+	token := encodedNonce + "." + strconv.FormatInt(expires, 10) + "." + s.sign(encodedNonce, expires, deviceHash)
+	link := strings.TrimRight(s.Config.BaseUrl, "/") + "/auth/magic-link?token=" + url.QueryEscape(token)
+	body := fmt.Sprintf("Use this link to sign in to CitizenX. It works once, for %d minutes, and only on the device you asked for it from.\n\n%s\n\nIf you did not ask to sign in, ignore this email.",
+		s.Config.MagicLinkTTLMinutes, link)
+	if _, err := s.mailer.SendSimpleMessage(user.Email, "Your CitizenX sign-in link", body); err != nil {
+		return fmt.Errorf("sending sign-in link: %w", err)
+	}
+	return nil
+}
+
+// VerifyLink signs in with a link's token, on the device that asked for it
+func (s *magicLinkService) VerifyLink(token, deviceID string) (*models.LoginResponse, error) {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 {
+		return nil, ErrMagicLinkInvalid
+	}
+	encodedNonce, signature := parts[0], parts[2]
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return nil, ErrMagicLinkInvalid
+	}
+
+	deviceHash := hashDevice(deviceID)
+	if !hmac.Equal([]byte(signature), []byte(s.sign(encodedNonce, expires, deviceHash))) {
+		// A genuine link opened elsewhere deserves a clearer answer than a
+		// forged one
+		if link, err := s.magicLinkRepo.FindLink(hashNonce(encodedNonce)); err == nil && link.DeviceHash != deviceHash {
+			return nil, ErrMagicLinkOtherDevice
+		}
+		return nil, ErrMagicLinkInvalid
+	}
+	now := time.Now().Unix()
+	if expires <= now {
+		return nil, ErrMagicLinkExpired
+	}
+
+	link, err := s.magicLinkRepo.FindLink(hashNonce(encodedNonce))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrMagicLinkInvalid
+	}
+	if err != nil {
+		return nil, err
+	}
+	used, err := s.magicLinkRepo.UseLink(link.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !used {
+		return nil, ErrMagicLinkInvalid
+	}
+
+	user, err := s.authRepo.FindUserByID(link.UserID)
+	if err != nil {
+		return nil, err
+	}
+	// Following the link proves the address is the user's
+	if !user.IsEmailActive {
+		if err := s.magicLinkRepo.MarkEmailVerified(user.ID); err != nil {
+			log.Printf("marking email of user %d verified: %v", user.ID, err)
+		}
+	}
+	return s.sessions.Start(user)
+}
+
+// sign binds a link's nonce and expiry to the device that asked for it
+func (s *magicLinkService) sign(nonce string, expires int64, deviceHash string) string {
+	mac := hmac.New(sha256.New, []byte(s.Config.JWTSecret))
+	fmt.Fprintf(mac, "magic-link|%s|%d|%s", nonce, expires, deviceHash)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func hashNonce(nonce string) string {
+	sum := sha256.Sum256([]byte(nonce))
+	return hex.EncodeToString(sum[:])
+}
+
+func hashDevice(deviceID string) string {
+	sum := sha256.Sum256([]byte("device|" + strings.TrimSpace(deviceID)))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,323 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/techagentng/citizenx/cache"
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/geo"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/tiles"
+)
+
+// TileCacheTTL is how long a rendered tile is served from memory. New
+// reports show up on the map within this window.
+const TileCacheTTL = time.Minute
+
+// TileCacheEntries bounds how many rendered tiles are kept in memory. Tiles
+// are keyed by request, so without a bound a crawl of the tile grid would
+// hold every tile it touched.
+const TileCacheEntries = 2048
+
+// HeatmapCacheTTL is how long a computed heatmap is served from memory.
+const HeatmapCacheTTL = 30 * time.Second
+
+// MaxHeatmapCells bounds the grid a single heatmap request may cover, so a
+// fine precision has to come with a small bounding box.
+const MaxHeatmapCells = 100000
+
+// ReportsLayer is the name of the MVT layer holding report points.
+const ReportsLayer = "reports"
+
+// ErrInvalidHeatmap is returned for heatmap requests that cannot be served.
+var ErrInvalidHeatmap = errors.New("invalid heatmap request")
+
+// MaxMapFeatures bounds the reports exported as GeoJSON in one request;
+// the newest are kept.
+const MaxMapFeatures = 5000
+
+// MapFilter selects the reports shown on a map
+type MapFilter struct {
+	West, South, East, North float64
+	Category                 string
+	Start, End               *time.Time
+}
+
+// HeatmapFilter selects the reports and grid of a heatmap
+type HeatmapFilter struct {
+	MapFilter
+	Precision int
+}
+
+// MarkerFilter selects the reports clustered into markers and the zoom
+// level of the map showing them
+type MarkerFilter struct {
+	MapFilter
+	Zoom int
+}
+
+// MapService renders map data for web clients
+type MapService interface {
+	ReportTile(tile tiles.Tile, category string) ([]byte, error)
+	Heatmap(filter HeatmapFilter) (*models.Heatmap, error)
+	Markers(filter MarkerFilter) (*models.MarkerClusters, error)
+	ReportFeatures(filter MapFilter) (*models.FeatureCollection, error)
+}
+
+type mapService struct {
+	Config   *config.Config
+	geoRepo  db.GeoRepository
+	tiles    *cache.TTL[[]byte]
+	heatmaps *cache.TTL[*models.Heatmap]
+	markers  *cache.TTL[*models.MarkerClusters]
+}
+
+// NewMapService creates a new instance of MapService
+func NewMapService(geoRepo db.GeoRepository, conf *config.Config) MapService {
+	return &mapService{
+		Config:   conf,
+		geoRepo:  geoRepo,
+		tiles:    cache.NewBounded[[]byte](TileCacheTTL, TileCacheEntries),
+		heatmaps: cache.New[*models.Heatmap](HeatmapCacheTTL),
+		markers:  cache.New[*models.MarkerClusters](HeatmapCacheTTL),
+	}
+}
+
+// clusterCellSize returns the clustering grid for a zoom level, in tile
+// units. Street-level tiles show individual reports.
+func clusterCellSize(z int) int {
+	switch {
+	case z < 10:
+		return 256
+	case z < 14:
+		return 128
+	case z < 16:
+		return 32
+	}
+	return 1
+}
+
+// ReportTile returns the MVT tile of report clusters. Each feature has a
+// point_count property; single reports also carry report_id and category.
+func (m *mapService) ReportTile(tile tiles.Tile, category string) ([]byte, error) {
+	if err := tile.Validate(); err != nil {
+		return nil, err
+	}
+
+	key := fmt.Sprintf("%d/%d/%d/%s", tile.Z, tile.X, tile.Y, category)
+	return m.tiles.GetOrLoad(key, func() ([]byte, error) {
+		west, south, east, north := tile.Bounds()
+		clusters, err := m.geoRepo.GetTileClusters(db.TileQuery{
+			Z: tile.Z, X: tile.X, Y: tile.Y,
+			West: west, South: south, East: east, North: north,
+			Extent:   tiles.Extent,
+			CellSize: clusterCellSize(tile.Z),
+			Category: category,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		features := make([]tiles.Feature, 0, len(clusters))
+		for _, c := range clusters {
+			props := map[string]interface{}{"point_count": c.Count}
+			if c.ReportID != nil {
+				props["report_id"] = *c.ReportID
+			}
+			if c.Category != nil {
+				props["category"] = *c.Category
+			}
+			features = append(features, tiles.Feature{
+				X:          int(math.Round(c.X)),
+				Y:          int(math.Round(c.Y)),
+				Properties: props,
+			})
+		}
+		return tiles.Encode(ReportsLayer, features), nil
+	})
+}
+
+// Heatmap counts reports per geohash cell inside the filter's bounding box.
+func (m *mapService) Heatmap(filter HeatmapFilter) (*models.Heatmap, error) {
+	if filter.Precision < 1 || filter.Precision > geo.MaxGeohashPrecision {
+		return nil, fmt.Errorf("%w: precision must be between 1 and %d", ErrInvalidHeatmap, geo.MaxGeohashPrecision)
+	}
+	if !filter.validBox() {
+		return nil, fmt.Errorf("%w: bbox must be west,south,east,north in degrees", ErrInvalidHeatmap)
+	}
+	grid := geo.NewGeohashGrid(filter.Precision)
+	cells := math.Ceil((filter.East-filter.West)/grid.CellWidth) * math.Ceil((filter.North-filter.South)/grid.CellHeight)
+	if cells > MaxHeatmapCells {
+		return nil, fmt.Errorf("%w: bbox is too large for precision %d", ErrInvalidHeatmap, filter.Precision)
+	}
+
+	key := fmt.Sprintf("%g,%g,%g,%g/%d/%s/%s/%s", filter.West, filter.South, filter.East, filter.North,
+		filter.Precision, filter.Category, timeKey(filter.Start), timeKey(filter.End))
+	return m.heatmaps.GetOrLoad(key, func() (*models.Heatmap, error) {
+		rows, err := m.geoRepo.GetHeatmapCells(db.HeatmapQuery{
+			West: filter.West, South: filter.South, East: filter.East, North: filter.North,
+			CellWidth:  grid.CellWidth,
+			CellHeight: grid.CellHeight,
+			Category:   filter.Category,
+			Start:      filter.Start,
+			End:        filter.End,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		heatmap := &models.Heatmap{Precision: filter.Precision, Cells: make([]models.HeatmapCell, 0, len(rows))}
+		for _, row := range rows {
+			lat, lng := grid.Center(row.LngIdx, row.LatIdx)
+			heatmap.Cells = append(heatmap.Cells, models.HeatmapCell{
+				Geohash: grid.Hash(row.LngIdx, row.LatIdx),
+				Lat:     lat,
+				Lng:     lng,
+				Count:   row.Count,
+			})
+			if row.Count > heatmap.MaxCount {
+				heatmap.MaxCount = row.Count
+			}
+		}
+		return heatmap, nil
+	})
+}
+
+// markerPrecision returns the finest geohash precision whose cells are no
+// narrower than a quarter of a map tile at zoom z, and that keeps the grid
+// over the bounding box within MaxHeatmapCells.
+func markerPrecision(z int, f MapFilter) int {
+	precision := 1
+	for p := geo.MaxGeohashPrecision; p > 1; p-- {
+		if geo.NewGeohashGrid(p).LngBits <= z+2 {
+			precision = p
+			break
+		}
+	}
+	for ; precision > 1; precision-- {
+		grid := geo.NewGeohashGrid(precision)
+		if math.Ceil((f.East-f.West)/grid.CellWidth)*math.Ceil((f.North-f.South)/grid.CellHeight) <= MaxHeatmapCells {
+			break
+		}
+	}
+	return precision
+}
+
+// Markers clusters the reports inside the filter's bounding box into one
+// marker per geohash cell, with cells that shrink as the map zooms in.
+func (m *mapService) Markers(filter MarkerFilter) (*models.MarkerClusters, error) {
+	if filter.Zoom < 0 || filter.Zoom > tiles.MaxZoom {
+		return nil, fmt.Errorf("%w: zoom must be between 0 and %d", ErrInvalidHeatmap, tiles.MaxZoom)
+	}
+	if !filter.validBox() {
+		return nil, fmt.Errorf("%w: bbox must be west,south,east,north in degrees", ErrInvalidHeatmap)
+	}
+	precision := markerPrecision(filter.Zoom, filter.MapFilter)
+	grid := geo.NewGeohashGrid(precision)
+
+	key := fmt.Sprintf("%g,%g,%g,%g/%d/%s/%s/%s", filter.West, filter.South, filter.East, filter.North,
+		filter.Zoom, filter.Category, timeKey(filter.Start), timeKey(filter.End))
+	return m.markers.GetOrLoad(key, func() (*models.MarkerClusters, error) {
+		rows, err := m.geoRepo.GetMarkerClusters(db.HeatmapQuery{
+			West: filter.West, South: filter.South, East: filter.East, North: filter.North,
+			CellWidth:  grid.CellWidth,
+			CellHeight: grid.CellHeight,
+			Category:   filter.Category,
+			Start:      filter.Start,
+			End:        filter.End,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		markers := &models.MarkerClusters{Zoom: filter.Zoom, Precision: precision, Clusters: make([]models.MarkerCluster, 0, len(rows))}
+		for _, row := range rows {
+			markers.Clusters = append(markers.Clusters, models.MarkerCluster{
+				Geohash:  grid.Hash(row.LngIdx, row.LatIdx),
+				Lat:      row.Lat,
+				Lng:      row.Lng,
+				Count:    row.Count,
+				ReportID: row.ReportID,
+				Category: row.Category,
+			})
+			markers.Total += row.Count
+		}
+		return markers, nil
+	})
+}
+
+// ReportFeatures exports the reports inside the filter's bounding box as
+// GeoJSON. Reports describing a stretch of road are LineStrings along their
+// route, those spanning several places MultiPoints and the rest Points.
+func (m *mapService) ReportFeatures(filter MapFilter) (*models.FeatureCollection, error) {
+	if !filter.validBox() {
+		return nil, fmt.Errorf("%w: bbox must be west,south,east,north in degrees", ErrInvalidHeatmap)
+	}
+	reports, err := m.geoRepo.GetReportFeatures(db.FeatureQuery{
+		West: filter.West, South: filter.South, East: filter.East, North: filter.North,
+		Category: filter.Category,
+		Start:    filter.Start,
+		End:      filter.End,
+		Limit:    MaxMapFeatures,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	collection := &models.FeatureCollection{Type: "FeatureCollection", Features: make([]models.Feature, 0, len(reports))}
+	for _, report := range reports {
+		status := report.ReportStatus
+		if status == "" {
+			status = "pending"
+		}
+		feature := models.Feature{
+			Type: "Feature",
+			ID:   report.ID.String(),
+			Geometry: models.Geometry{
+				Type:        "Point",
+				Coordinates: [2]float64{report.Longitude, report.Latitude},
+			},
+			Properties: map[string]interface{}{
+				"category":        report.Category,
+				"sub_report_type": report.SubReportType,
+				"status":          status,
+				"state_name":      report.StateName,
+				"lga_name":        report.LGAName,
+				"created_at":      report.CreatedAt,
+			},
+		}
+		if len(report.Points) > 1 {
+			points := make([][2]float64, len(report.Points))
+			for i, p := range report.Points {
+				points[i] = [2]float64{p.Longitude, p.Latitude}
+			}
+			feature.Geometry = models.Geometry{Type: "MultiPoint", Coordinates: points}
+		}
+		if route, err := geo.DecodePolyline(report.Route); err == nil && len(route) > 1 {
+			line := make([][2]float64, len(route))
+			for i, p := range route {
+				line[i] = [2]float64{p.Longitude, p.Latitude}
+			}
+			feature.Geometry = models.Geometry{Type: "LineString", Coordinates: line}
+			feature.Properties["route_length_m"] = report.RouteLengthM
+		}
+		collection.Features = append(collection.Features, feature)
+	}
+	return collection, nil
+}
+
+func (f MapFilter) validBox() bool {
+	return f.West < f.East && f.South < f.North &&
+		f.West >= -180 && f.East <= 180 && f.South >= -90 && f.North <= 90
+}
+
+func timeKey(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
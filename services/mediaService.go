@@ -2,6 +2,8 @@ package services
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"image"
@@ -24,12 +26,21 @@ import (
 	"github.com/google/uuid"
 	"github.com/techagentng/citizenx/config"
 	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/imagehash"
 	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/storage"
+	"github.com/techagentng/citizenx/watermark"
+	"gorm.io/gorm"
 )
 
 type MediaService interface {
-	ProcessMedia(c *gin.Context, formMedia []*multipart.FileHeader, userID uint, reportID string) ([]string, []string, []string, []string, error)
+	ProcessMedia(c *gin.Context, formMedia []*multipart.FileHeader, userID uint, reportID string) ([]string, []string, []string, []string, []MediaFingerprint, error)
 	SaveMedia(media models.Media, reportID string, userID uint, imageCount int, videoCount int, audioCount int, totalPoints int) error
+	SaveDraftMedia(media models.Media, draftID string, userID uint) error
+	ProcessUpload(fileBytes []byte, filename, contentType, draftID string, userID uint) (models.Media, error)
+	OriginalURL(mediaID string) (string, time.Time, error)
+	ListMediaReuseFlags(page int) ([]models.MediaReuseFlag, error)
+	MarkGraphic(reportID, mediaID string, graphic, moderator bool) error
 }
 
 type mediaService struct {
@@ -37,19 +48,40 @@ type mediaService struct {
 	mediaRepo          db.MediaRepository
 	rewardRepo         db.RewardRepository
 	IncidentReportRepo db.IncidentReportRepository
+	objects            ObjectService
+	ages               *AgePolicy
 }
 
-func NewMediaService(mediaRepo db.MediaRepository, rewardRepo db.RewardRepository, reportRepo db.IncidentReportRepository, conf *config.Config) MediaService {
+// NewMediaService creates a new instance of MediaService. Media added to
+// reports in the graphic_categories starts marked graphic.
+func NewMediaService(mediaRepo db.MediaRepository, rewardRepo db.RewardRepository, reportRepo db.IncidentReportRepository, objects ObjectService, conf *config.Config) MediaService {
 	return &mediaService{
 		Config:             conf,
 		mediaRepo:          mediaRepo,
 		rewardRepo:         rewardRepo,
 		IncidentReportRepo: reportRepo,
+		objects:            objects,
+		ages:               NewAgePolicy(conf),
 	}
 }
 
 const MaxAudioFileSize = 10 * 1024 * 1024 // 10 MB
 
+var (
+	// ErrMediaNotFound is returned for media that does not exist.
+	ErrMediaNotFound = errors.New("media not found")
+	// ErrOriginalNotRestricted is returned when signing the original of media
+	// whose original is public.
+	ErrOriginalNotRestricted = errors.New("media original is not restricted")
+	// ErrGraphicSetByModerator is returned when a reporter tries to change
+	// whether media is graphic after a moderator has decided.
+	ErrGraphicSetByModerator = errors.New("a moderator has already decided whether this media is graphic")
+)
+
+// MaxReuseDistance is the largest perceptual hash distance at which an image
+// is flagged as a copy of media on another report
+const MaxReuseDistance = 6
+
 func CheckFileSize(fileHeader *multipart.FileHeader) error {
 	if fileHeader.Size > MaxAudioFileSize {
 		return errors.New("file size exceeds the maximum allowed size")
@@ -84,16 +116,35 @@ type ProcessResult struct {
 	ThumbnailURL string
 	FullSizeURL  string
 	FileType     string
+	Fingerprint  MediaFingerprint
 	Error        error
 }
 
+// MediaFingerprint identifies an upload for duplicate detection and chain of
+// custody
+type MediaFingerprint struct {
+	// SHA256 is the hex digest of the original upload
+	SHA256 string
+	// PHash is the perceptual hash of an image, nil for other media
+	PHash *int64
+	// Watermark is the mark stamped on the public renditions, nil when
+	// watermarking is off or the media is not an image
+	Watermark *watermark.Mark
+	// OriginalKey is the storage key of the original when it is kept
+	// private, empty when the original is public
+	OriginalKey string
+	// BlurredURL is the preview shown behind a graphic-content warning,
+	// empty for audio
+	BlurredURL string
+}
+
 // Change the parameter type to []*multipart.FileHeader to handle multiple files
-func (m *mediaService) ProcessMedia(c *gin.Context, formMedia []*multipart.FileHeader, userID uint, reportID string) ([]string, []string, []string, []string, error) {
+func (m *mediaService) ProcessMedia(c *gin.Context, formMedia []*multipart.FileHeader, userID uint, reportID string) ([]string, []string, []string, []string, []MediaFingerprint, error) {
 	var (
 		feedURLs, thumbnailURLs, fullsizeURLs, fileTypes []string
+		fingerprints                                     []MediaFingerprint
 		mu                                               sync.Mutex
 		wg                                               sync.WaitGroup
-		bucketName                                       = os.Getenv("AWS_BUCKET")
 		results                                          = make(chan *ProcessResult, len(formMedia)) // len is valid now as formMedia is a slice
 	)
 
@@ -125,53 +176,23 @@ func (m *mediaService) ProcessMedia(c *gin.Context, formMedia []*multipart.FileH
 				return
 			}
 
-			fileType := getFileType(fileBytes)
-			var feedURL, thumbnailURL, fullsizeURL string
-
-			// Define the folder name based on the file type
-			folderName := ""
-			switch fileType {
-			case "image":
-				folderName = "images"
-				feedURL, thumbnailURL, fullsizeURL, err = processAndStoreImage(fileBytes)
-				if err != nil {
-					results <- &ProcessResult{Error: fmt.Errorf("failed to process and store image: %v", err)}
-					return
-				}
-			case "video":
-				folderName = "videos"
-				feedURL, thumbnailURL, fullsizeURL, err = processAndStoreVideo(fileBytes)
-				if err != nil {
-					results <- &ProcessResult{Error: fmt.Errorf("failed to process and store video: %v", err)}
-					return
-				}
-			case "audio":
-				folderName = "audio"
-				feedURL, thumbnailURL, err = processAndStoreAudio(fileBytes)
-				if err != nil {
-					results <- &ProcessResult{Error: fmt.Errorf("failed to process and store audio: %v", err)}
-					return
+			result := m.renderMedia(fileBytes, fileHeader.Header.Get("Content-Type"), reportID)
+			if result.Error != nil {
+				var invalid *storage.ValidationError
+				if errors.As(result.Error, &invalid) {
+					invalid.Filename = fileHeader.Filename
 				}
-			default:
-				results <- &ProcessResult{Error: fmt.Errorf("unsupported file type: %s", fileType)}
+				results <- result
 				return
 			}
 
-			// Upload the processed media to S3
-			feedURL, err = m.mediaRepo.UploadMediaToS3(file, fileHeader, bucketName, folderName)
-			if err != nil {
-				results <- &ProcessResult{Error: fmt.Errorf("failed to upload media to S3: %v", err)}
+			if err := m.storeOriginal(result, fileBytes, fileHeader.Filename, fileHeader.Header.Get("Content-Type"), reportID); err != nil {
+				results <- &ProcessResult{Error: err}
 				return
 			}
 
 			// Return the results through the channel
-			results <- &ProcessResult{
-				FeedURL:      feedURL,
-				ThumbnailURL: thumbnailURL,
-				FullSizeURL:  fullsizeURL,
-				FileType:     fileType,
-				Error:        nil,
-			}
+			results <- result
 		}(fileHeader)
 	}
 
@@ -184,7 +205,7 @@ func (m *mediaService) ProcessMedia(c *gin.Context, formMedia []*multipart.FileH
 	// Collect results from the channel
 	for result := range results {
 		if result.Error != nil {
-			return nil, nil, nil, nil, fmt.Errorf("error processing media: %v", result.Error)
+			return nil, nil, nil, nil, nil, fmt.Errorf("error processing media: %w", result.Error)
 		}
 		mu.Lock()
 		feedURLs = append(feedURLs, result.FeedURL)
@@ -195,46 +216,166 @@ func (m *mediaService) ProcessMedia(c *gin.Context, formMedia []*multipart.FileH
 			fullsizeURLs = append(fullsizeURLs, result.FullSizeURL)
 		}
 		fileTypes = append(fileTypes, result.FileType)
+		fingerprints = append(fingerprints, result.Fingerprint)
 		mu.Unlock()
 	}
 
-	return feedURLs, thumbnailURLs, fullsizeURLs, fileTypes, nil
+	return feedURLs, thumbnailURLs, fullsizeURLs, fileTypes, fingerprints, nil
 }
 
-func getFileType(fileBytes []byte) string {
-	// Determine the file type based on the file signature (magic number)
-	fileType := http.DetectContentType(fileBytes)
+// mediaFolders maps a media type to the storage folder its originals go in
+var mediaFolders = map[string]string{
+	"image": "images",
+	"video": "videos",
+	"audio": "audio",
+}
 
-	// Log the detected content type
-	log.Printf("Detected content type: %s", fileType)
+// renderMedia validates and fingerprints an upload and writes its local
+// renditions, the feed rendition becoming FeedURL until the original is
+// stored
+func (m *mediaService) renderMedia(fileBytes []byte, contentType, reportID string) *ProcessResult {
+	kind, err := storage.LimitsFromConfig(m.Config).Validate(fileBytes, contentType)
+	if err != nil {
+		return &ProcessResult{Error: err}
+	}
+	result := &ProcessResult{FileType: string(kind)}
+	digest := sha256.Sum256(fileBytes)
+	result.Fingerprint = MediaFingerprint{SHA256: hex.EncodeToString(digest[:])}
 
-	switch fileType {
-	case "image/jpeg", "image/jpg":
-		return "image"
-	case "image/png":
-		return "image"
-	case "image/gif":
-		return "image"
-	case "video/mp4":
-		return "video"
-	case "video/avi":
-		return "video"
-	case "video/quicktime":
-		return "video"
-	case "audio/mpeg":
-		return "audio"
-	case "audio/wav":
-		return "audio"
-	case "audio/ogg":
-		return "audio"
-	case "audio/flac":
-		return "audio"
-	case "application/ogg":
-		return "audio"
+	switch result.FileType {
+	case "image":
+		if m.Config != nil && m.Config.WatermarkMedia {
+			mark := watermark.New(m.watermarkSecret(), reportID, time.Now(), result.Fingerprint.SHA256)
+			result.Fingerprint.Watermark = &mark
+		}
+		result.FeedURL, result.ThumbnailURL, result.FullSizeURL, err = processAndStoreImage(fileBytes, result.Fingerprint.Watermark)
+		if err != nil {
+			return &ProcessResult{Error: fmt.Errorf("failed to process and store image: %v", err)}
+		}
+		result.Fingerprint.PHash, err = imagePHash(fileBytes)
+		if err != nil {
+			return &ProcessResult{Error: fmt.Errorf("failed to hash image: %v", err)}
+		}
+		img, _, err := image.Decode(bytes.NewReader(fileBytes))
+		if err == nil {
+			result.Fingerprint.BlurredURL, err = storeBlurredPreview(img)
+		}
+		if err != nil {
+			return &ProcessResult{Error: err}
+		}
+	case "video":
+		result.FeedURL, result.ThumbnailURL, result.FullSizeURL, err = processAndStoreVideo(fileBytes)
+		if err != nil {
+			return &ProcessResult{Error: fmt.Errorf("failed to process and store video: %v", err)}
+		}
+		frame, err := imaging.Open(result.ThumbnailURL)
+		if err == nil {
+			result.Fingerprint.BlurredURL, err = storeBlurredPreview(frame)
+		}
+		if err != nil {
+			return &ProcessResult{Error: err}
+		}
+	case "audio":
+		result.FeedURL, result.ThumbnailURL, err = processAndStoreAudio(fileBytes)
+		if err != nil {
+			return &ProcessResult{Error: fmt.Errorf("failed to process and store audio: %v", err)}
+		}
 	default:
-		// If the file type is not recognized, return "unknown"
-		return "unknown"
+		return &ProcessResult{Error: fmt.Errorf("unsupported file type: %s", result.FileType)}
+	}
+	return result
+}
+
+// ProcessUpload stores and renders a file assembled from a resumable upload
+// and attaches it to the draft
+func (m *mediaService) ProcessUpload(fileBytes []byte, filename, contentType, draftID string, userID uint) (models.Media, error) {
+	result := m.renderMedia(fileBytes, contentType, draftID)
+	if result.Error != nil {
+		return models.Media{}, result.Error
+	}
+	if err := m.storeOriginal(result, fileBytes, filename, contentType, draftID); err != nil {
+		return models.Media{}, err
+	}
+
+	media := models.Media{
+		ID:           uuid.New().String(),
+		FileType:     result.FileType,
+		FileSize:     int64(len(fileBytes)),
+		Filename:     filename,
+		FeedURL:      result.FeedURL,
+		ThumbnailURL: result.ThumbnailURL,
+		FullSizeURL:  result.FullSizeURL,
+		SHA256:       result.Fingerprint.SHA256,
+		PHash:        result.Fingerprint.PHash,
+		OriginalKey:  result.Fingerprint.OriginalKey,
+		BlurredURL:   result.Fingerprint.BlurredURL,
+	}
+	if mark := result.Fingerprint.Watermark; mark != nil {
+		media.WatermarkCode = mark.Code
+		media.WatermarkedAt = mark.Timestamp.Unix()
+	}
+	if err := m.SaveDraftMedia(media, draftID, userID); err != nil {
+		return models.Media{}, err
+	}
+	return media, nil
+}
+
+// storeOriginal puts the unmodified upload in storage, owned by the report.
+// Originals are public and become the feed URL unless restricted, in which
+// case they are private, the public rendition stays the feed URL and
+// moderators fetch the original through a signed URL.
+func (m *mediaService) storeOriginal(result *ProcessResult, fileBytes []byte, filename, contentType, reportID string) error {
+	key := mediaFolders[result.FileType] + "/" + generateUniqueFilename(strings.ToLower(filepath.Ext(filename)))
+	if m.Config.RestrictMediaOriginals {
+		key = "originals/" + key
+		if err := m.objects.PutPrivate(key, bytes.NewReader(fileBytes), int64(len(fileBytes)), contentType, models.ObjectOwnerReport, reportID); err != nil {
+			return fmt.Errorf("failed to store media: %v", err)
+		}
+		result.Fingerprint.OriginalKey = key
+		return nil
+	}
+
+	url, err := m.objects.Put(key, bytes.NewReader(fileBytes), int64(len(fileBytes)), contentType, models.ObjectOwnerReport, reportID)
+	if err != nil {
+		return fmt.Errorf("failed to store media: %v", err)
+	}
+	result.FeedURL = url
+	return nil
+}
+
+// OriginalURL returns a short lived URL for the private original of a media
+// file and when it expires
+func (m *mediaService) OriginalURL(mediaID string) (string, time.Time, error) {
+	media, err := m.mediaRepo.GetMediaByID(mediaID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", time.Time{}, ErrMediaNotFound
+	}
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if media.OriginalKey == "" {
+		return "", time.Time{}, ErrOriginalNotRestricted
+	}
+	return m.objects.SignedURL(media.OriginalKey)
+}
+
+// watermarkSecret returns the key watermark codes are signed with
+func (m *mediaService) watermarkSecret() string {
+	if m.Config.WatermarkSecret != "" {
+		return m.Config.WatermarkSecret
 	}
+	return m.Config.JWTSecret
+}
+
+// imagePHash returns the perceptual hash of an encoded image in the form
+// stored on models.Media
+func imagePHash(fileBytes []byte) (*int64, error) {
+	img, _, err := image.Decode(bytes.NewReader(fileBytes))
+	if err != nil {
+		return nil, err
+	}
+	hash := int64(imagehash.PHash(img))
+	return &hash, nil
 }
 
 // ImageResult represents the result of processing an image, video, or audio file.
@@ -273,7 +414,7 @@ func (m *mediaService) processFile(fileCh <-chan *multipart.FileHeader, resultCh
 
 			var result ImageResult
 			if strings.HasPrefix(ext, ".jpg") || strings.HasPrefix(ext, ".jpeg") || strings.HasPrefix(ext, ".png") {
-				feedURL, thumbnailURL, fullsizeURL, err := processAndStoreImage(fileBytes)
+				feedURL, thumbnailURL, fullsizeURL, err := processAndStoreImage(fileBytes, nil)
 				result = ImageResult{feedURL, thumbnailURL, fullsizeURL, err}
 			} else if strings.HasPrefix(ext, ".mp4") {
 				videoURL, thumbnailURL, _, err := processAndStoreVideo(fileBytes)
@@ -288,16 +429,32 @@ func (m *mediaService) processFile(fileCh <-chan *multipart.FileHeader, resultCh
 	}
 }
 
-// Image processing
-func processAndStoreImage(fileBytes []byte) (string, string, string, error) {
+// fullSizeMaxDimension bounds the longer side of the public full size
+// rendition of an image. Re-encoding it drops the upload's metadata, such as
+// EXIF locations; the upload itself is only kept as the private original.
+const fullSizeMaxDimension = 2048
+
+// Image processing. The full size copy is scaled down to
+// fullSizeMaxDimension, and when mark is set it is stamped on every
+// rendition.
+func processAndStoreImage(fileBytes []byte, mark *watermark.Mark) (string, string, string, error) {
 	img, _, err := image.Decode(bytes.NewReader(fileBytes))
 	if err != nil {
 		return "", "", "", fmt.Errorf("failed to decode image: %v", err)
 	}
 
-	feedImg := imaging.Fill(img, 1080, 1080, imaging.Center, imaging.Lanczos)
-	thumbnailImg := imaging.Resize(img, 161, 161, imaging.Lanczos)
+	var feedImg, thumbnailImg image.Image
+	feedImg = imaging.Fill(img, 1080, 1080, imaging.Center, imaging.Lanczos)
+	thumbnailImg = imaging.Resize(img, 161, 161, imaging.Lanczos)
 	fullSizeImg := img
+	if bounds := img.Bounds(); bounds.Dx() > fullSizeMaxDimension || bounds.Dy() > fullSizeMaxDimension {
+		fullSizeImg = imaging.Fit(img, fullSizeMaxDimension, fullSizeMaxDimension, imaging.Lanczos)
+	}
+	if mark != nil {
+		feedImg = watermark.Apply(feedImg, *mark)
+		thumbnailImg = watermark.Apply(thumbnailImg, *mark)
+		fullSizeImg = watermark.Apply(fullSizeImg, *mark)
+	}
 
 	feedFilename := generateUniqueFilename(".jpg")
 	thumbnailFilename := generateUniqueFilename(".jpg")
@@ -353,6 +510,40 @@ func processAndStoreImage(fileBytes []byte) (string, string, string, error) {
 	return feedDestPath, thumbnailDestPath, fullSizeDestPath, nil
 }
 
+// MarkGraphic marks or unmarks one of a report's media as graphic. Once a
+// moderator has decided, the reporter can no longer change it.
+func (m *mediaService) MarkGraphic(reportID, mediaID string, graphic, moderator bool) error {
+	media, err := m.mediaRepo.GetMediaByID(mediaID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && media.IncidentReportID.String() != reportID) {
+		return ErrMediaNotFound
+	}
+	if err != nil {
+		return err
+	}
+	by := models.MediaGraphicByModerator
+	if !moderator {
+		if media.GraphicBy == models.MediaGraphicByModerator {
+			return ErrGraphicSetByModerator
+		}
+		by = models.MediaGraphicByReporter
+	}
+	return m.mediaRepo.SetMediaGraphic(mediaID, graphic, by)
+}
+
+// storeBlurredPreview writes the rendition shown behind a graphic-content
+// warning, small and blurred past recognition
+func storeBlurredPreview(img image.Image) (string, error) {
+	preview := imaging.Blur(imaging.Resize(img, 320, 0, imaging.Lanczos), 24)
+	destPath := filepath.Join("media", "blurred", generateUniqueFilename(".jpg"))
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return "", fmt.Errorf("error creating blurred folder: %v", err)
+	}
+	if err := imaging.Save(preview, destPath); err != nil {
+		return "", fmt.Errorf("failed to encode blurred preview: %v", err)
+	}
+	return destPath, nil
+}
+
 func isValidFileType(fileType string) bool {
 	switch fileType {
 	case "image", "video", "voice_note":
@@ -437,11 +628,6 @@ func getImageDimensions(file []byte) (int, int, error) {
 }
 
 func (m *mediaService) SaveMedia(media models.Media, reportID string, userID uint, imageCount int, videoCount int, audioCount int, totalPoints int) error {
-	// Generate a new UUID for the media ID
-	ID := uuid.New()
-	media.ID = ID.String()
-	media.UserID = userID
-
 	// Multiply totalPoints by 10
 	rewardPoints := totalPoints * 10
 
@@ -449,7 +635,7 @@ func (m *mediaService) SaveMedia(media models.Media, reportID string, userID uin
 	media.Points = rewardPoints
 
 	// Save the media to the database
-	err := m.mediaRepo.SaveMedia(media, reportID, userID)
+	err := m.storeMedia(media, reportID, userID)
 	if err != nil {
 		return err
 	}
@@ -490,6 +676,73 @@ func (m *mediaService) SaveMedia(media models.Media, reportID string, userID uin
 	return nil
 }
 
+// SaveDraftMedia records media uploaded to a report draft. No points are
+// awarded until the draft is finalized.
+func (m *mediaService) SaveDraftMedia(media models.Media, draftID string, userID uint) error {
+	return m.storeMedia(media, draftID, userID)
+}
+
+// storeMedia saves a media record under a report and checks it for reuse
+func (m *mediaService) storeMedia(media models.Media, reportID string, userID uint) error {
+	if media.ID == "" {
+		media.ID = uuid.New().String()
+	}
+	media.UserID = userID
+	if reportUUID, err := uuid.Parse(reportID); err == nil {
+		media.IncidentReportID = reportUUID
+	}
+	// Drafts are not reports yet; their media takes the category default
+	// when the report is submitted
+	if !media.Graphic {
+		if report, err := m.IncidentReportRepo.GetIncidentReportByID(reportID); err == nil && m.ages.Graphic(report.Category) {
+			media.Graphic = true
+			media.GraphicBy = models.MediaGraphicByCategory
+		}
+	}
+
+	if err := m.mediaRepo.SaveMedia(media, reportID, userID); err != nil {
+		return err
+	}
+
+	// Flag images already seen on other reports, a sign of an old photo
+	// being resubmitted for rewards
+	flags, err := m.mediaRepo.FlagReusedMedia(media, MaxReuseDistance)
+	if err != nil {
+		log.Printf("Error checking media %s for reuse: %v", media.ID, err)
+	} else if len(flags) > 0 {
+		log.Printf("Media %s on report %s matches %d image(s) on other reports", media.ID, reportID, len(flags))
+	}
+	return nil
+}
+
+// MediaPoints returns the points a report earns for its attached media
+func MediaPoints(imageCount, videoCount, audioCount int) int {
+	return (imageCount * 5) + (videoCount * 10) + (audioCount * 8)
+}
+
+// readStoredMedia returns the bytes of a stored media file, downloading it
+// when location is a URL and reading it from disk otherwise
+func readStoredMedia(client *http.Client, location string) ([]byte, error) {
+	if !strings.HasPrefix(location, "http://") && !strings.HasPrefix(location, "https://") {
+		return os.ReadFile(location)
+	}
+
+	resp, err := client.Get(location)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("storage returned %s", resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// ListMediaReuseFlags returns a page of images flagged as reused
+func (m *mediaService) ListMediaReuseFlags(page int) ([]models.MediaReuseFlag, error) {
+	return m.mediaRepo.ListMediaReuseFlags(page)
+}
+
 func processAndStoreVideo(fileBytes []byte) (string, string, string, error) {
 	videoFilename := generateUniqueFilename(".mp4")
 	thumbnailFilename := generateUniqueFilename(".jpg")
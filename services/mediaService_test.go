@@ -0,0 +1,121 @@
+package services
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/techagentng/citizenx/watermark"
+)
+
+// chdirTemp runs the rest of the test in an empty directory, since the
+// renditions are written under a relative media folder
+func chdirTemp(t *testing.T) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func decodeRendition(t *testing.T, path string) image.Image {
+	t.Helper()
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("opening %s: %v", path, err)
+	}
+	defer f.Close()
+	img, err := jpeg.Decode(f)
+	if err != nil {
+		t.Fatalf("decoding %s: %v", path, err)
+	}
+	return img
+}
+
+func luminance(img image.Image, x, y int) uint32 {
+	r, g, b, _ := img.At(x, y).RGBA()
+	return (r + g + b) / 3 >> 8
+}
+
+func TestProcessAndStoreImage(t *testing.T) {
+	chdirTemp(t)
+
+	upload := image.NewRGBA(image.Rect(0, 0, 3000, 1500))
+	for y := 0; y < 1500; y++ {
+		for x := 0; x < 3000; x++ {
+			upload.Set(x, y, color.Gray{Y: 200})
+		}
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, upload, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	mark := watermark.New("secret", "report-1", time.Unix(1700000000, 0), "digest")
+	feed, thumbnail, fullSize, err := processAndStoreImage(buf.Bytes(), &mark)
+	if err != nil {
+		t.Fatalf("processing image: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name          string
+		path          string
+		width, height int
+	}{
+		{"feed", feed, 1080, 1080},
+		{"thumbnail", thumbnail, 161, 161},
+		{"full size", fullSize, 2048, 1024},
+	} {
+		img := decodeRendition(t, tc.path)
+		bounds := img.Bounds()
+		if bounds.Dx() != tc.width || bounds.Dy() != tc.height {
+			t.Errorf("%s is %dx%d, want %dx%d", tc.name, bounds.Dx(), bounds.Dy(), tc.width, tc.height)
+			continue
+		}
+		// The watermark band darkens the bottom edge of every rendition
+		if top, bottom := luminance(img, 2, 2), luminance(img, 2, bounds.Dy()-3); bottom+40 > top {
+			t.Errorf("%s has no watermark band: luminance %d at the top, %d at the bottom", tc.name, top, bottom)
+		}
+	}
+}
+
+func TestProcessAndStoreImageWithoutMark(t *testing.T) {
+	chdirTemp(t)
+
+	upload := image.NewGray(image.Rect(0, 0, 400, 300))
+	for i := range upload.Pix {
+		upload.Pix[i] = 200
+	}
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, upload, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	_, _, fullSize, err := processAndStoreImage(buf.Bytes(), nil)
+	if err != nil {
+		t.Fatalf("processing image: %v", err)
+	}
+	img := decodeRendition(t, fullSize)
+	if bounds := img.Bounds(); bounds.Dx() != 400 || bounds.Dy() != 300 {
+		t.Fatalf("full size is %dx%d, want the upload's 400x300", bounds.Dx(), bounds.Dy())
+	}
+	if top, bottom := luminance(img, 2, 2), luminance(img, 2, 297); bottom+40 <= top {
+		t.Fatalf("unmarked full size has a dark bottom edge: luminance %d at the top, %d at the bottom", top, bottom)
+	}
+}
+
+func TestProcessAndStoreImageRejectsNonImages(t *testing.T) {
+	chdirTemp(t)
+
+	if _, _, _, err := processAndStoreImage([]byte("not an image"), nil); err == nil {
+		t.Fatal("processing text as an image succeeded")
+	}
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	_ "time/tzdata" // quiet hours are kept in users' own time zones
+
+	"github.com/techagentng/citizenx/models"
+)
+
+// ErrInvalidQuietHours is returned for quiet hours with a malformed time or
+// an unknown time zone.
+var ErrInvalidQuietHours = errors.New("quiet hours need HH:MM start and end times and a valid time zone")
+
+// pendingFlushLimit bounds how many queued notifications one flush sends
+const pendingFlushLimit = 1000
+
+// deliveryTime returns when a notification about a report raised at now may
+// go out: with any already queued for the report, or after the batching
+// window, and never before the user's quiet hours end.
+func (s *notificationService) deliveryTime(userID uint, reportID string, now time.Time) (time.Time, error) {
+	at := now.Add(time.Duration(s.Config.NotificationBatchSeconds) * time.Second)
+	if reportID != "" {
+		batch, err := s.notificationRepo.PendingBatchTime(userID, reportID)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if batch > 0 {
+			at = time.Unix(batch, 0)
+		}
+	}
+
+	hours, err := s.GetQuietHours(userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if end, quiet := quietUntil(hours, at); quiet {
+		at = end
+	}
+	return at, nil
+}
+
+// FlushPending sends the queued notifications that are due, combining each
+// user's into one message per channel, and returns how many went out. Each
+// user's batch is removed from the queue as soon as it is delivered, so a
+// failure part way through does not resend the batches before it.
+func (s *notificationService) FlushPending() (int, error) {
+	due, err := s.notificationRepo.DuePendingNotifications(time.Now(), pendingFlushLimit)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for start := 0; start < len(due); {
+		end := start
+		var ids []uint
+		for end < len(due) && due[end].UserID == due[start].UserID {
+			ids = append(ids, due[end].ID)
+			end++
+		}
+		if err := s.deliver(due[start].UserID, due[start:end]); err != nil {
+			return sent, err
+		}
+		if err := s.notificationRepo.DeletePendingNotifications(ids); err != nil {
+			return sent, err
+		}
+		sent += len(ids)
+		start = end
+	}
+	return sent, nil
+}
+
+// summarize turns a batch of notifications into one subject and message
+func summarize(batch []models.PendingNotification) (string, string) {
+	if len(batch) == 1 {
+		return batch[0].Subject, batch[0].Message
+	}
+
+	subject := fmt.Sprintf("%d new notifications", len(batch))
+	if batchReport(batch) != "" {
+		subject = fmt.Sprintf("%d updates on your report", len(batch))
+	}
+
+	lines := make([]string, len(batch))
+	for i, n := range batch {
+		lines[i] = "- " + n.Message
+	}
+	return subject, strings.Join(lines, "\n")
+}
+
+// batchReport returns the report every notification in batch is about, or
+// "" when they are not all about the same one
+func batchReport(batch []models.PendingNotification) string {
+	for _, n := range batch[1:] {
+		if n.ReportID != batch[0].ReportID {
+			return ""
+		}
+	}
+	return batch[0].ReportID
+}
+
+// GetQuietHours returns the user's quiet hours, or the defaults when they
+// have not set any
+func (s *notificationService) GetQuietHours(userID uint) (models.QuietHours, error) {
+	hours, err := s.notificationRepo.GetQuietHours(userID)
+	if err != nil {
+		return models.QuietHours{}, err
+	}
+	if hours == nil {
+		defaults := models.DefaultQuietHours()
+		defaults.UserID = userID
+		return defaults, nil
+	}
+	return *hours, nil
+}
+
+func (s *notificationService) UpdateQuietHours(userID uint, hours models.QuietHours) (models.QuietHours, error) {
+	if _, err := clockMinutes(hours.Start); err != nil {
+		return models.QuietHours{}, ErrInvalidQuietHours
+	}
+	if _, err := clockMinutes(hours.End); err != nil {
+		return models.QuietHours{}, ErrInvalidQuietHours
+	}
+	if _, err := time.LoadLocation(hours.TimeZone); err != nil {
+		return models.QuietHours{}, ErrInvalidQuietHours
+	}
+
+	hours.UserID = userID
+	hours.UpdatedAt = time.Now().Unix()
+	if err := s.notificationRepo.SaveQuietHours(&hours); err != nil {
+		return models.QuietHours{}, err
+	}
+	return hours, nil
+}
+
+// quietUntil reports whether t falls in the quiet hours and, if so, when
+// they end
+func quietUntil(hours models.QuietHours, t time.Time) (time.Time, bool) {
+	if !hours.Enabled {
+		return time.Time{}, false
+	}
+	loc, err := time.LoadLocation(hours.TimeZone)
+	if err != nil {
+		return time.Time{}, false
+	}
+	start, err := clockMinutes(hours.Start)
+	if err != nil {
+		return time.Time{}, false
+	}
+	end, err := clockMinutes(hours.End)
+	if err != nil || start == end {
+		return time.Time{}, false
+	}
+
+	local := t.In(loc)
+	now := local.Hour()*60 + local.Minute()
+	var quiet bool
+	if start < end {
+		quiet = now >= start && now < end
+	} else {
+		// The window runs past midnight
+		quiet = now >= start || now < end
+	}
+	if !quiet {
+		return time.Time{}, false
+	}
+
+	until := time.Date(local.Year(), local.Month(), local.Day(), end/60, end%60, 0, 0, loc)
+	if !until.After(local) {
+		until = until.AddDate(0, 0, 1)
+	}
+	return until, true
+}
+
+// clockMinutes parses "HH:MM" into minutes after midnight
+func clockMinutes(clock string) (int, error) {
+	t, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
@@ -0,0 +1,403 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/events"
+	"github.com/techagentng/citizenx/locale"
+	"github.com/techagentng/citizenx/mailingservices"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrUnknownNotificationSetting is returned when preferences name a
+	// channel or category that does not exist, or a channel nothing is
+	// configured to deliver over.
+	ErrUnknownNotificationSetting = errors.New("unknown notification channel or category")
+	// ErrNotificationNotFound is returned for notifications that do not
+	// exist or belong to another user.
+	ErrNotificationNotFound = errors.New("notification not found")
+)
+
+// NotificationService turns domain events into notifications, kept in the
+// user's in-app list and delivered over the channels they have enabled
+type NotificationService interface {
+	Notify(userID uint, message string) error
+	Subscribe(bus events.Bus)
+	SetSender(channel string, sender NotificationSender)
+	GetPreferences(userID uint) (models.NotificationPreferences, error)
+	UpdatePreferences(userID uint, changes models.NotificationPreferences) (models.NotificationPreferences, error)
+	GetQuietHours(userID uint) (models.QuietHours, error)
+	UpdateQuietHours(userID uint, hours models.QuietHours) (models.QuietHours, error)
+	FlushPending() (int, error)
+	Announce(announcement *models.Announcement, userIDs []uint) error
+	Remind(userID uint, subject, message string) (bool, error)
+	ListNotifications(userID uint, page int) ([]models.Notification, error)
+	MarkRead(userID, notificationID uint) error
+}
+
+// NotificationSender delivers a notification to a user over one channel
+type NotificationSender interface {
+	Send(userID uint, subject, message string) error
+}
+
+type notificationService struct {
+	Config           *config.Config
+	notificationRepo db.NotificationRepository
+	senders          map[string]NotificationSender
+}
+
+// NewNotificationService creates a new instance of NotificationService.
+// Notifications only reach the channels a sender is set for.
+func NewNotificationService(notificationRepo db.NotificationRepository, conf *config.Config) NotificationService {
+	return &notificationService{
+		Config:           conf,
+		notificationRepo: notificationRepo,
+		senders:          map[string]NotificationSender{},
+	}
+}
+
+// SetSender sets how notifications are delivered over a channel. It must be
+// called before events are dispatched.
+func (s *notificationService) SetSender(channel string, sender NotificationSender) {
+	s.senders[channel] = sender
+}
+
+// Notify stores a notification for the user
+func (s *notificationService) Notify(userID uint, message string) error {
+	_, err := s.notificationRepo.CreateNotification(&models.Notification{
+		UserID:  userID,
+		Message: message,
+	})
+	return err
+}
+
+// dispatch stores a notification raised by event, at most once per event,
+// and the first time hands it on for delivery over the user's channels.
+// Alerts go out at once; everything else is queued so that notifications
+// about one report are batched and none arrive during quiet hours.
+func (s *notificationService) dispatch(event events.Event, userID uint, reportID, category, subject, message string) error {
+	if asleep, err := s.notificationRepo.Deactivated(userID); err != nil || asleep {
+		return err
+	}
+	key := event.DedupKey()
+	created, err := s.notificationRepo.CreateNotification(&models.Notification{
+		UserID:   userID,
+		Message:  message,
+		DedupKey: &key,
+	})
+	if err != nil || !created || len(s.senders) == 0 {
+		return err
+	}
+
+	if category == models.NotifyAlerts {
+		return s.deliver(userID, []models.PendingNotification{{
+			UserID:   userID,
+			ReportID: reportID,
+			Category: category,
+			Subject:  subject,
+			Message:  message,
+		}})
+	}
+
+	deliverAt, err := s.deliveryTime(userID, reportID, time.Now())
+	if err != nil {
+		return err
+	}
+	return s.notificationRepo.QueueNotification(&models.PendingNotification{
+		UserID:       userID,
+		ReportID:     reportID,
+		Category:     category,
+		Subject:      subject,
+		Message:      message,
+		DeliverAfter: deliverAt.Unix(),
+		CreatedAt:    time.Now().Unix(),
+	})
+}
+
+// deliver sends notifications to a user over every channel the user allows
+// for their categories, one message per channel. A failed channel is logged
+// rather than retried, so one broken provider does not resend through the
+// others. Notifications queued before the user deactivated their account
+// are dropped.
+func (s *notificationService) deliver(userID uint, batch []models.PendingNotification) error {
+	if asleep, err := s.notificationRepo.Deactivated(userID); err != nil || asleep {
+		return err
+	}
+	prefs, err := s.GetPreferences(userID)
+	if err != nil {
+		return err
+	}
+	for _, channel := range models.NotificationChannels {
+		sender, ok := s.senders[channel]
+		if !ok {
+			continue
+		}
+		var allowed []models.PendingNotification
+		for _, n := range batch {
+			if prefs[channel][n.Category] {
+				allowed = append(allowed, n)
+			}
+		}
+		if len(allowed) == 0 {
+			continue
+		}
+		subject, message := summarize(allowed)
+		var err error
+		if reportSender, ok := sender.(ReportNotificationSender); ok && batchReport(allowed) != "" {
+			err = reportSender.SendAboutReport(userID, batchReport(allowed), subject, message)
+		} else {
+			err = sender.Send(userID, subject, message)
+		}
+		if err != nil {
+			log.Printf("Error sending %s notification to user %d: %v", channel, userID, err)
+		}
+	}
+	return nil
+}
+
+// Subscribe registers the service for the events users are notified about
+func (s *notificationService) Subscribe(bus events.Bus) {
+	bus.Subscribe(events.ReportVerifiedEvent, s.handleEvent)
+	bus.Subscribe(events.RewardEarnedEvent, s.handleEvent)
+	bus.Subscribe(events.CommentAddedEvent, s.handleEvent)
+	bus.Subscribe(events.ReportClosedEvent, s.handleEvent)
+	bus.Subscribe(events.ReportAcknowledgedEvent, s.handleEvent)
+	bus.Subscribe(events.ReportResolvedEvent, s.handleEvent)
+	bus.Subscribe(events.InfoRequestedEvent, s.handleEvent)
+	bus.Subscribe(events.CollaboratorAddedEvent, s.handleEvent)
+	bus.Subscribe(events.ReportTransferredEvent, s.handleEvent)
+}
+
+func (s *notificationService) handleEvent(ctx context.Context, event events.Event) error {
+	switch e := event.(type) {
+	case events.ReportVerified:
+		return s.dispatch(e, e.UserID, e.ReportID.String(), models.NotifyStatus, "Report verified", "Your incident report has been verified.")
+	case events.RewardEarned:
+		format, err := s.userLocale(e.UserID)
+		if err != nil {
+			return err
+		}
+		return s.dispatch(e, e.UserID, e.ReportID, models.NotifyRewards, "Points earned", fmt.Sprintf("You earned %s points.", format.Number(int64(e.Points))))
+	case events.CommentAdded:
+		// Users are not notified about their own comments
+		if e.ReportOwnerID == e.UserID {
+			return nil
+		}
+		return s.dispatch(e, e.ReportOwnerID, e.ReportID.String(), models.NotifyComments, "New comment", "Someone commented on your incident report.")
+	case events.ReportClosed:
+		return s.dispatch(e, e.UserID, e.ReportID.String(), models.NotifyStatus, "Report closed",
+			fmt.Sprintf("Your incident report was closed after %d days without an update. You can submit a new report if the issue continues.", e.AfterDays))
+	case events.ReportAcknowledged:
+		return s.dispatch(e, e.UserID, e.ReportID.String(), models.NotifyStatus, "Report acknowledged",
+			"The agency handling your incident report has seen it and is looking into it.")
+	case events.ReportResolved:
+		return s.dispatch(e, e.UserID, e.ReportID.String(), models.NotifyStatus, "Report resolved",
+			"The agency handling your incident report says the issue is resolved. Let us know whether it really is.")
+	case events.InformationRequested:
+		return s.dispatch(e, e.UserID, e.ReportID.String(), models.NotifyStatus, "More information needed",
+			"A moderator needs more information about your incident report ("+strings.ReplaceAll(strings.Join(e.Fields, ", "), "_", " ")+"). Open the report to add it.")
+	case events.CollaboratorAdded:
+		return s.dispatch(e, e.UserID, e.ReportID.String(), models.NotifyStatus, "Added as a collaborator",
+			"You were added as a collaborator on an incident report. You can now add media and updates to it.")
+	case events.ReportTransferred:
+		return s.dispatch(e, e.ToUserID, e.ReportID.String(), models.NotifyStatus, "Report transferred to you",
+			"An incident report filed on your behalf is now in your account. You can follow its progress from My reports.")
+	}
+	return nil
+}
+
+// userLocale returns the formatter for the user's chosen locale
+func (s *notificationService) userLocale(userID uint) (locale.Formatter, error) {
+	tag, err := s.notificationRepo.UserLocale(userID)
+	if err != nil {
+		return locale.Formatter{}, err
+	}
+	return locale.For(tag), nil
+}
+
+// Announce delivers an announcement to each user once, in-app and, as an
+// alert, over their channels once any quiet hours are over
+func (s *notificationService) Announce(announcement *models.Announcement, userIDs []uint) error {
+	message := announcement.Title + ": " + announcement.Message
+	for _, userID := range userIDs {
+		key := fmt.Sprintf("announcement:%d:%d", announcement.ID, userID)
+		created, err := s.notificationRepo.CreateNotification(&models.Notification{
+			UserID:         userID,
+			Message:        message,
+			DedupKey:       &key,
+			AnnouncementID: &announcement.ID,
+		})
+		if err != nil {
+			return err
+		}
+		if !created || len(s.senders) == 0 {
+			continue
+		}
+
+		deliverAt, err := s.deliveryTime(userID, "", time.Now())
+		if err != nil {
+			return err
+		}
+		if err := s.notificationRepo.QueueNotification(&models.PendingNotification{
+			UserID:       userID,
+			Category:     models.NotifyAlerts,
+			Subject:      announcement.Title,
+			Message:      announcement.Message,
+			DeliverAfter: deliverAt.Unix(),
+			CreatedAt:    time.Now().Unix(),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Remind sends the user a reminder unless they have turned reminders off on
+// every channel, and reports whether it was sent. Reminders are never urgent,
+// so they wait for the batching window and the end of quiet hours.
+func (s *notificationService) Remind(userID uint, subject, message string) (bool, error) {
+	if asleep, err := s.notificationRepo.Deactivated(userID); err != nil || asleep {
+		return false, err
+	}
+	prefs, err := s.GetPreferences(userID)
+	if err != nil {
+		return false, err
+	}
+	// With no channel offered there is nothing to opt out on, and the
+	// reminder only goes to the in-app list
+	optedIn := len(prefs) == 0
+	for _, channel := range models.NotificationChannels {
+		optedIn = optedIn || prefs[channel][models.NotifyReminders]
+	}
+	if !optedIn {
+		return false, nil
+	}
+
+	if _, err := s.notificationRepo.CreateNotification(&models.Notification{
+		UserID:  userID,
+		Message: message,
+	}); err != nil {
+		return false, err
+	}
+	if len(s.senders) == 0 {
+		return true, nil
+	}
+	deliverAt, err := s.deliveryTime(userID, "", time.Now())
+	if err != nil {
+		return false, err
+	}
+	return true, s.notificationRepo.QueueNotification(&models.PendingNotification{
+		UserID:       userID,
+		Category:     models.NotifyReminders,
+		Subject:      subject,
+		Message:      message,
+		DeliverAfter: deliverAt.Unix(),
+		CreatedAt:    time.Now().Unix(),
+	})
+}
+
+// ListNotifications returns a page of the user's in-app notifications
+func (s *notificationService) ListNotifications(userID uint, page int) ([]models.Notification, error) {
+	return s.notificationRepo.ListNotifications(userID, page)
+}
+
+func (s *notificationService) MarkRead(userID, notificationID uint) error {
+	err := s.notificationRepo.MarkRead(userID, notificationID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrNotificationNotFound
+	}
+	return err
+}
+
+// GetPreferences returns the user's full preference matrix, with defaults
+// for anything the user has not set
+func (s *notificationService) GetPreferences(userID uint) (models.NotificationPreferences, error) {
+	saved, err := s.notificationRepo.GetPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+	prefs := s.defaultPreferences()
+	for _, p := range saved {
+		if categories, ok := prefs[p.Channel]; ok {
+			if _, ok := categories[p.Category]; ok {
+				categories[p.Category] = p.Enabled
+			}
+		}
+	}
+	return prefs, nil
+}
+
+// UpdatePreferences applies the given changes, which need only name the
+// settings being changed, and returns the resulting matrix
+func (s *notificationService) UpdatePreferences(userID uint, changes models.NotificationPreferences) (models.NotificationPreferences, error) {
+	defaults := s.defaultPreferences()
+	now := time.Now().Unix()
+	var rows []models.NotificationPreference
+	for channel, categories := range changes {
+		if _, ok := defaults[channel]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownNotificationSetting, channel)
+		}
+		for category, enabled := range categories {
+			if _, ok := defaults[channel][category]; !ok {
+				return nil, fmt.Errorf("%w: %s", ErrUnknownNotificationSetting, category)
+			}
+			rows = append(rows, models.NotificationPreference{
+				UserID:    userID,
+				Channel:   channel,
+				Category:  category,
+				Enabled:   enabled,
+				UpdatedAt: now,
+			})
+		}
+	}
+
+	if err := s.notificationRepo.SavePreferences(rows); err != nil {
+		return nil, err
+	}
+	return s.GetPreferences(userID)
+}
+
+// defaultPreferences returns the default matrix for the channels that have
+// a sender. Channels without one, such as push until a push provider is
+// wired in, are not offered, since nothing would be delivered over them.
+func (s *notificationService) defaultPreferences() models.NotificationPreferences {
+	prefs := models.DefaultNotificationPreferences()
+	for channel := range prefs {
+		if _, ok := s.senders[channel]; !ok {
+			delete(prefs, channel)
+		}
+	}
+	return prefs
+}
+
+// emailSender delivers notifications to the address on the user's account
+type emailSender struct {
+	authRepo db.AuthRepository
+	mailer   mailingservices.Mailer
+}
+
+// NewEmailSender returns a NotificationSender for the email channel
+func NewEmailSender(authRepo db.AuthRepository, mailer mailingservices.Mailer) NotificationSender {
+	return &emailSender{authRepo: authRepo, mailer: mailer}
+}
+
+func (e *emailSender) Send(userID uint, subject, message string) error {
+	user, err := e.authRepo.FindUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.Email == "" {
+		return nil
+	}
+	_, err = e.mailer.SendSimpleMessage(user.Email, "CitizenX: "+subject, message)
+	return err
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"time"
+
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/storage"
+)
+
+// orphanBatchSize bounds how many orphans one reconciliation pass deletes
+const orphanBatchSize = 500
+
+// ObjectService puts objects in storage and records their owner, so that
+// objects nothing refers to any more are eventually deleted
+type ObjectService interface {
+	Put(key string, body io.Reader, size int64, contentType, ownerType, ownerID string) (string, error)
+	PutPrivate(key string, body io.Reader, size int64, contentType, ownerType, ownerID string) error
+	SignedURL(key string) (string, time.Time, error)
+	Open(key string) (io.ReadCloser, error)
+	OpenSigned(key, expires, signature string) (io.ReadCloser, error)
+	Delete(key string) error
+	DeleteOrphans() (int, error)
+}
+
+type objectService struct {
+	Config     *config.Config
+	objectRepo db.ObjectRepository
+	store      storage.Store
+}
+
+// NewObjectService creates a new instance of ObjectService
+func NewObjectService(objectRepo db.ObjectRepository, store storage.Store, conf *config.Config) ObjectService {
+	return &objectService{
+		Config:     conf,
+		objectRepo: objectRepo,
+		store:      store,
+	}
+}
+
+// Put stores a public object and records its owner
+func (s *objectService) Put(key string, body io.Reader, size int64, contentType, ownerType, ownerID string) (string, error) {
+	url, err := s.store.Put(context.Background(), key, body, size, contentType)
+	if err != nil {
+		return "", err
+	}
+	if err := s.track(key, url, size, ownerType, ownerID); err != nil {
+		return "", err
+	}
+	return url, nil
+}
+
+// PutPrivate stores an object readable only through SignedURL and records
+// its owner
+func (s *objectService) PutPrivate(key string, body io.Reader, size int64, contentType, ownerType, ownerID string) error {
+	if err := s.store.PutPrivate(context.Background(), key, body, size, contentType); err != nil {
+		return err
+	}
+	return s.track(key, "", size, ownerType, ownerID)
+}
+
+// track records the owner of a stored object. The object is removed again if
+// that fails, since nothing would ever clean it up.
+func (s *objectService) track(key, url string, size int64, ownerType, ownerID string) error {
+	err := s.objectRepo.TrackObject(&models.StoredObject{
+		Key:       key,
+		URL:       url,
+		Size:      size,
+		OwnerType: ownerType,
+		OwnerID:   ownerID,
+		CreatedAt: time.Now().Unix(),
+	})
+	if err != nil {
+		s.store.Delete(context.Background(), key)
+	}
+	return err
+}
+
+// SignedURL returns a URL for a private object valid for the configured
+// lifetime, and when it expires
+func (s *objectService) SignedURL(key string) (string, time.Time, error) {
+	ttl := time.Duration(s.Config.SignedURLTTLSeconds) * time.Second
+	expires := time.Now().Add(ttl)
+	url, err := s.store.SignedURL(context.Background(), key, ttl)
+	return url, expires, err
+}
+
+// OpenSigned opens a private object from the parts of a signed URL. Only
+// stores that serve private objects through the API support it.
+func (s *objectService) OpenSigned(key, expires, signature string) (io.ReadCloser, error) {
+	local, ok := s.store.(*storage.Local)
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	return local.OpenSigned(context.Background(), key, expires, signature)
+}
+
+func (s *objectService) Open(key string) (io.ReadCloser, error) {
+	return s.store.Open(context.Background(), key)
+}
+
+// Delete removes an object and its ownership record. Deleting an object that
+// is already gone is not an error.
+func (s *objectService) Delete(key string) error {
+	if err := s.store.Delete(context.Background(), key); err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return err
+	}
+	return s.objectRepo.ForgetObject(key)
+}
+
+// DeleteOrphans deletes objects whose owner is gone once they are older than
+// the grace period, which leaves time for a report to be saved after its
+// media is stored. It returns the number of objects deleted.
+func (s *objectService) DeleteOrphans() (int, error) {
+	grace := time.Duration(s.Config.OrphanObjectGraceHours) * time.Hour
+	if grace <= 0 {
+		return 0, nil
+	}
+	orphans, err := s.objectRepo.OrphanedObjects(time.Now().Add(-grace), orphanBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, object := range orphans {
+		if err := s.Delete(object.Key); err != nil {
+			log.Printf("Error deleting orphaned object %s: %v", object.Key, err)
+			continue
+		}
+		deleted++
+	}
+	return deleted, nil
+}
@@ -0,0 +1,342 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/techagentng/citizenx/cache"
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/locale"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/payments"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrPayoutsUnavailable is returned when no payment provider is
+	// configured.
+	ErrPayoutsUnavailable = errors.New("payouts are not available")
+	// ErrUnknownBank is returned for bank codes the provider does not list.
+	ErrUnknownBank = errors.New("unknown bank")
+	// ErrBankAccountNotFound is returned when the bank has no account with
+	// the number given.
+	ErrBankAccountNotFound = errors.New("no account with this number was found at the bank")
+	// ErrNoBankAccount is returned for users who have not set a bank
+	// account.
+	ErrNoBankAccount = errors.New("no bank account has been set")
+	// ErrPayoutBatchNotFound is returned for payout batches that do not
+	// exist.
+	ErrPayoutBatchNotFound = errors.New("payout batch not found")
+	// ErrPayoutNotFound is returned for provider notifications about
+	// transfers we did not send.
+	ErrPayoutNotFound = errors.New("payout not found")
+
+	// errPayoutOutcomeUnknown is returned when a payout may or may not have
+	// reached the provider. It stays sending, to be looked up again once
+	// its claim goes stale.
+	errPayoutOutcomeUnknown = errors.New("payout outcome unknown")
+)
+
+const (
+	// payoutSendAttempts is how often sending a payout is tried before it
+	// fails and the points are given back
+	payoutSendAttempts = 5
+	payoutSendBatch    = 50
+	payoutTimeout      = 30 * time.Second
+	// payoutSendLease is how long a claimed payout is left to its sender
+	// before another may look it up and send it
+	payoutSendLease = 15 * time.Minute
+	banksCacheTTL   = 24 * time.Hour
+
+	// payoutNeedsOTPReason is shown to admins on payouts held for an OTP
+	payoutNeedsOTPReason = "the provider is holding this transfer for OTP finalisation; finalise it in the provider's dashboard, or turn off OTPs for transfers"
+)
+
+// PayoutService pays reporters in formal monitoring programs: it keeps
+// their bank accounts, pays out their points in batches created by admins
+// and reconciles the transfers from the provider's notifications
+type PayoutService interface {
+	Banks() ([]payments.Bank, error)
+	BankAccount(userID uint) (*models.BankAccount, error)
+	SetBankAccount(userID uint, request *models.BankAccountRequest) (*models.BankAccount, error)
+	CreateBatch(request *models.PayoutBatchRequest, adminID uint) (*models.PayoutBatch, error)
+	Batches(page int) ([]models.PayoutBatch, error)
+	BatchPayouts(batchID uint) ([]models.Payout, error)
+	History(userID uint, page int) ([]models.Payout, error)
+	Send() (int, error)
+	Reconcile(body []byte, signature string) error
+}
+
+type payoutService struct {
+	Config              *config.Config
+	payoutRepo          db.PayoutRepository
+	authRepo            db.AuthRepository
+	provider            payments.Provider
+	notificationService NotificationService
+	banks               *cache.TTL[[]payments.Bank]
+}
+
+// NewPayoutService creates a new instance of PayoutService. provider is nil
+// when no payment provider is configured.
+func NewPayoutService(payoutRepo db.PayoutRepository, authRepo db.AuthRepository, provider payments.Provider, notificationService NotificationService, conf *config.Config) PayoutService {
+	return &payoutService{
+		Config:              conf,
+		payoutRepo:          payoutRepo,
+		authRepo:            authRepo,
+		provider:            provider,
+		notificationService: notificationService,
+		banks:               cache.New[[]payments.Bank](banksCacheTTL),
+	}
+}
+
+// Banks returns the banks payouts can be sent to
+func (s *payoutService) Banks() ([]payments.Bank, error) {
+	if s.provider == nil {
+		return nil, ErrPayoutsUnavailable
+	}
+	return s.banks.GetOrLoad("banks", func() ([]payments.Bank, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), payoutTimeout)
+		defer cancel()
+		return s.provider.Banks(ctx)
+	})
+}
+
+func (s *payoutService) BankAccount(userID uint) (*models.BankAccount, error) {
+	account, err := s.payoutRepo.GetBankAccount(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNoBankAccount
+	}
+	return account, err
+}
+
+// SetBankAccount resolves the account with the bank and saves it with the
+// name the bank holds, so payouts cannot go to a mistyped number
+func (s *payoutService) SetBankAccount(userID uint, request *models.BankAccountRequest) (*models.BankAccount, error) {
+	banks, err := s.Banks()
+	if err != nil {
+		return nil, err
+	}
+	bankName := ""
+	for _, bank := range banks {
+		if bank.Code == request.BankCode {
+			bankName = bank.Name
+			break
+		}
+	}
+	if bankName == "" {
+		return nil, ErrUnknownBank
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), payoutTimeout)
+	defer cancel()
+	name, err := s.provider.ResolveAccount(ctx, request.AccountNumber, request.BankCode)
+	if errors.Is(err, payments.ErrAccountNotFound) {
+		return nil, ErrBankAccountNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().Unix()
+	account := &models.BankAccount{
+		UserID:        userID,
+		BankCode:      request.BankCode,
+		BankName:      bankName,
+		AccountNumber: request.AccountNumber,
+		Last4:         request.AccountNumber[len(request.AccountNumber)-4:],
+		AccountName:   name,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	if err := s.payoutRepo.SaveBankAccount(account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+// CreateBatch pays out the balance of each user listed who has a bank
+// account and enough points. The transfers are sent in the background.
+func (s *payoutService) CreateBatch(request *models.PayoutBatchRequest, adminID uint) (*models.PayoutBatch, error) {
+	if s.provider == nil {
+		return nil, ErrPayoutsUnavailable
+	}
+	batch := &models.PayoutBatch{
+		Program:   strings.TrimSpace(request.Program),
+		Status:    models.PayoutBatchProcessing,
+		CreatedBy: adminID,
+		CreatedAt: time.Now().Unix(),
+	}
+	if err := s.payoutRepo.CreateBatch(batch, request.UserIDs, request.MinimumPoints, s.Config.PointValueKobo); err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+func (s *payoutService) Batches(page int) ([]models.PayoutBatch, error) {
+	return s.payoutRepo.ListBatches(page)
+}
+
+func (s *payoutService) BatchPayouts(batchID uint) ([]models.Payout, error) {
+	if _, err := s.payoutRepo.GetBatch(batchID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPayoutBatchNotFound
+		}
+		return nil, err
+	}
+	return s.payoutRepo.BatchPayouts(batchID)
+}
+
+func (s *payoutService) History(userID uint, page int) ([]models.Payout, error) {
+	return s.payoutRepo.UserPayouts(userID, page)
+}
+
+// Send hands pending payouts to the provider, returning how many were sent.
+// A payout that keeps failing is failed after payoutSendAttempts and its
+// points given back. Payouts are only failed once the provider is known not
+// to have them: a send that errs may still have started the transfer.
+func (s *payoutService) Send() (int, error) {
+	if s.provider == nil {
+		return 0, nil
+	}
+	now := time.Now()
+	claimed, err := s.payoutRepo.ClaimPayouts(payoutSendAttempts, payoutSendBatch, now.Add(-payoutSendLease).Unix(), now.Unix())
+	if err != nil {
+		return 0, err
+	}
+	sent := 0
+	for _, payout := range claimed {
+		err := s.send(payout)
+		switch {
+		case errors.Is(err, errPayoutOutcomeUnknown):
+			log.Printf("sending payout %s: %v", payout.Reference, err)
+		case err != nil:
+			log.Printf("sending payout %s: %v", payout.Reference, err)
+			if err := s.payoutRepo.RecordSendFailure(payout.ID, err.Error(), payoutSendAttempts, time.Now().Unix()); err != nil {
+				return sent, err
+			}
+		default:
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+func (s *payoutService) send(payout models.Payout) error {
+	// A payout claimed before, by a sender that stopped, may be with the
+	// provider already
+	if payout.Status == models.PayoutSending {
+		providerReference, status, err := s.findTransfer(payout.Reference)
+		if err == nil {
+			return s.markSent(payout, providerReference, status)
+		}
+		if !errors.Is(err, payments.ErrTransferNotFound) {
+			return fmt.Errorf("%w: %v", errPayoutOutcomeUnknown, err)
+		}
+	}
+
+	account, err := s.payoutRepo.GetBankAccount(payout.UserID)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), payoutTimeout)
+	defer cancel()
+	providerReference, status, err := s.provider.Send(ctx, payments.Transfer{
+		Reference:     payout.Reference,
+		AmountKobo:    payout.AmountKobo,
+		AccountName:   account.AccountName,
+		AccountNumber: account.AccountNumber,
+		BankCode:      account.BankCode,
+		Narration:     "CitizenX reward payout",
+	})
+	if err != nil {
+		// Timeouts and duplicate references say nothing of whether the
+		// transfer started, so the provider is asked before failing it
+		found, foundStatus, findErr := s.findTransfer(payout.Reference)
+		switch {
+		case errors.Is(findErr, payments.ErrTransferNotFound):
+			return err
+		case findErr != nil:
+			return fmt.Errorf("%w: %v; looking it up: %v", errPayoutOutcomeUnknown, err, findErr)
+		}
+		providerReference, status = found, foundStatus
+	}
+	return s.markSent(payout, providerReference, status)
+}
+
+// findTransfer looks the payout's transfer up with the provider
+func (s *payoutService) findTransfer(reference string) (string, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), payoutTimeout)
+	defer cancel()
+	return s.provider.FindTransfer(ctx, reference)
+}
+
+// markSent records the payout as with the provider, settling it when the
+// transfer has already ended
+func (s *payoutService) markSent(payout models.Payout, providerReference, status string) error {
+	if err := s.payoutRepo.MarkSent(payout.ID, providerReference, time.Now().Unix()); err != nil {
+		return err
+	}
+	// A transfer held for an OTP waits for an admin to finalise it with the
+	// provider, whose notification then settles it like any other
+	if status == payments.TransferNeedsOTP {
+		log.Printf("payout %s needs OTP finalisation with the provider", payout.Reference)
+		_, _, err := s.payoutRepo.SettlePayout(payout.Reference, models.PayoutNeedsOTP, payoutNeedsOTPReason, time.Now().Unix())
+		return err
+	}
+	// Some transfers complete at once, without a notification to follow
+	if status != payments.TransferPending {
+		return s.settle(&payments.TransferUpdate{Reference: payout.Reference, Status: status})
+	}
+	return nil
+}
+
+// Reconcile applies a provider notification about a transfer
+func (s *payoutService) Reconcile(body []byte, signature string) error {
+	if s.provider == nil {
+		return ErrPayoutsUnavailable
+	}
+	update, err := s.provider.ParseWebhook(body, signature)
+	if err != nil || update == nil {
+		return err
+	}
+	return s.settle(update)
+}
+
+// settle records how a transfer ended and tells the user
+func (s *payoutService) settle(update *payments.TransferUpdate) error {
+	status := models.PayoutPaid
+	switch update.Status {
+	case payments.TransferFailed:
+		status = models.PayoutFailed
+	case payments.TransferReversed:
+		status = models.PayoutReversed
+	}
+	payout, changed, err := s.payoutRepo.SettlePayout(update.Reference, status, update.Reason, time.Now().Unix())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrPayoutNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if !changed {
+		return nil
+	}
+
+	format := locale.For("")
+	if user, err := s.authRepo.FindUserByID(payout.UserID); err == nil {
+		format = locale.For(user.Locale)
+	}
+	amount := format.Currency(payout.AmountKobo)
+	message := fmt.Sprintf("%s has been paid to your account ending %s.", amount, payout.Last4)
+	if status != models.PayoutPaid {
+		message = fmt.Sprintf("Your payout of %s could not be completed. Your %s points have been returned.", amount, format.Number(payout.Points))
+	}
+	if err := s.notificationService.Notify(payout.UserID, message); err != nil {
+		log.Printf("notifying user %d of payout %s: %v", payout.UserID, payout.Reference, err)
+	}
+	return nil
+}
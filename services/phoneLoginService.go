@@ -0,0 +1,332 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/sms"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	// otpDigits is the length of a sign-in code
+	otpDigits = 6
+	// otpResendAfter is how long a phone must wait between codes
+	otpResendAfter = time.Minute
+	// maxOTPAttempts is how many wrong guesses a code survives
+	maxOTPAttempts = 5
+)
+
+var (
+	// ErrInvalidPhone is returned for phone numbers that cannot be read.
+	ErrInvalidPhone = errors.New("enter a valid phone number, such as 08012345678 or +2348012345678")
+	// ErrOTPTooSoon is returned when a code is asked for again before
+	// otpResendAfter has passed.
+	ErrOTPTooSoon = errors.New("wait a minute before asking for another code")
+	// ErrOTPRateLimited is returned when a phone number or client has asked
+	// for too many codes in the last hour.
+	ErrOTPRateLimited = errors.New("too many codes requested; try again later")
+	// ErrOTPInvalid is returned for wrong, expired, used and exhausted codes.
+	ErrOTPInvalid = errors.New("the code is wrong or has expired")
+	// ErrOTPUnavailable is returned when codes cannot be sent.
+	ErrOTPUnavailable = errors.New("sign-in codes cannot be sent right now")
+	// ErrOTPUsePassword is returned instead of ErrOTPUnavailable when the
+	// account can sign in with its password meanwhile.
+	ErrOTPUsePassword = errors.New("sign-in codes cannot be sent right now; sign in with your password instead")
+	// ErrAccountComplete is returned when completing an account that was
+	// not created by phone sign-in, or already completed.
+	ErrAccountComplete = errors.New("account already has an email and password")
+	// ErrEmailInUse is returned when completing an account with an email
+	// another account has.
+	ErrEmailInUse = errors.New("email already in use")
+)
+
+// NormalizePhone returns a phone number in international format. Nigerian
+// numbers may be written locally (08012345678) or with the country code,
+// with or without the plus; other numbers need the plus and country code.
+func NormalizePhone(phone string) (string, error) {
+	digits := strings.Map(func(r rune) rune {
+		switch {
+		case r >= '0' && r <= '9':
+			return r
+		case r == ' ' || r == '-' || r == '(' || r == ')' || r == '.':
+			return -1
+		}
+		return 'x'
+	}, strings.TrimPrefix(strings.TrimSpace(phone), "+"))
+	plus := strings.HasPrefix(strings.TrimSpace(phone), "+")
+	switch {
+	case strings.Contains(digits, "x"):
+		return "", ErrInvalidPhone
+	case !plus && len(digits) == 11 && digits[0] == '0':
+		return "+234" + digits[1:], nil
+	case strings.HasPrefix(digits, "234") && len(digits) == 13:
+		return "+" + digits, nil
+	case plus && len(digits) >= 8 && len(digits) <= 15:
+		return "+" + digits, nil
+	}
+	return "", ErrInvalidPhone
+}
+
+// phoneVariants returns the ways a normalized number may be stored on older
+// accounts
+func phoneVariants(phone string) []string {
+	variants := []string{phone, strings.TrimPrefix(phone, "+")}
+	if strings.HasPrefix(phone, "+234") {
+		variants = append(variants, "0"+phone[4:])
+	}
+	return variants
+}
+
+// PhoneLoginService signs users in with a code sent by SMS instead of a
+// password, creating an account for numbers it has not seen
+type PhoneLoginService interface {
+	RequestCode(phone, ip string) (*models.OTPChallenge, error)
+	VerifyCode(phone, code string) (*models.LoginResponse, bool, error)
+	CompleteAccount(userID uint, request *models.CompleteAccountRequest) error
+}
+
+type phoneLoginService struct {
+	Config         *config.Config
+	phoneLoginRepo db.PhoneLoginRepository
+	authRepo       db.AuthRepository
+	sender         sms.Sender
+	sessions       SessionService
+	displayNames   *DisplayNamePolicy
+	ages           *AgePolicy
+}
+
+// NewPhoneLoginService creates a new instance of PhoneLoginService. Codes
+// cannot be sent while sender is nil.
+func NewPhoneLoginService(phoneLoginRepo db.PhoneLoginRepository, authRepo db.AuthRepository, sender sms.Sender, sessions SessionService, conf *config.Config) PhoneLoginService {
+	return &phoneLoginService{
+		Config:         conf,
+		phoneLoginRepo: phoneLoginRepo,
+		authRepo:       authRepo,
+		sender:         sender,
+		sessions:       sessions,
+		displayNames:   NewDisplayNamePolicy(conf),
+		ages:           NewAgePolicy(conf),
+	}
+}
+
+// RequestCode sends a sign-in code to phone, within the per-number and
+// per-client limits
+func (s *phoneLoginService) RequestCode(phone, ip string) (*models.OTPChallenge, error) {
+	phone, err := NormalizePhone(phone)
+	if err != nil {
+		return nil, err
+	}
+	if s.sender == nil {
+		return nil, s.unavailable(phone)
+	}
+
+	now := time.Now()
+	latest, err := s.phoneLoginRepo.LatestOTP(phone)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if latest != nil && now.Unix()-latest.CreatedAt < int64(otpResendAfter.Seconds()) {
+		return nil, ErrOTPTooSoon
+	}
+	hourAgo := now.Add(-time.Hour).Unix()
+	sent, err := s.phoneLoginRepo.CountOTPs(phone, hourAgo)
+	if err != nil {
+		return nil, err
+	}
+	fromIP, err := s.phoneLoginRepo.CountIPOTPs(ip, hourAgo)
+	if err != nil {
+		return nil, err
+	}
+	if sent >= int64(s.Config.OTPMaxPerHour) || fromIP >= int64(s.Config.OTPMaxPerIPPerHour) {
+		return nil, ErrOTPRateLimited
+	}
+
+	code, err := otpCode()
+	if err != nil {
+		return nil, err
+	}
+	ttl := time.Duration(s.Config.OTPTTLMinutes) * time.Minute
+	if err := s.phoneLoginRepo.CreateOTP(&models.PhoneOTP{
+		Phone:     phone,
+		CodeHash:  s.hash(phone, code),
+		IP:        ip,
+		ExpiresAt: now.Add(ttl).Unix(),
+		CreatedAt: now.Unix(),
+	}); err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	message := fmt.Sprintf("Your CitizenX sign-in code is %s. It expires in %d minutes. Do not share it with anyone.", code, s.Config.OTPTTLMinutes)
+	if err := s.sender.Send(ctx, phone, message); err != nil {
+		log.Printf("sending sign-in code: %v", err)
+		return nil, s.unavailable(phone)
+	}
+	return &models.OTPChallenge{
+		Phone:       phone,
+		ExpiresIn:   int(ttl.Seconds()),
+		ResendAfter: int(otpResendAfter.Seconds()),
+	}, nil
+}
+
+// unavailable explains that no code can be sent, pointing accounts with a
+// password to it when the fallback is on
+func (s *phoneLoginService) unavailable(phone string) error {
+	if !s.Config.OTPPasswordFallback {
+		return ErrOTPUnavailable
+	}
+	user, err := s.phoneLoginRepo.FindUserByPhone(phoneVariants(phone))
+	if err == nil && user.HashedPassword != "" && user.Email != "" {
+		return ErrOTPUsePassword
+	}
+	return ErrOTPUnavailable
+}
+
+// VerifyCode signs in with the code last sent to phone. Numbers without an
+// account get a new phone-only account, reported by the second result.
+func (s *phoneLoginService) VerifyCode(phone, code string) (*models.LoginResponse, bool, error) {
+	phone, err := NormalizePhone(phone)
+	if err != nil {
+		return nil, false, err
+	}
+	otp, err := s.phoneLoginRepo.LatestOTP(phone)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, false, ErrOTPInvalid
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	now := time.Now().Unix()
+	if otp.UsedAt != 0 || otp.ExpiresAt <= now {
+		return nil, false, ErrOTPInvalid
+	}
+	// Every guess, right or wrong, claims one of the code's attempts before
+	// it is compared
+	claimed, err := s.phoneLoginRepo.ClaimAttempt(otp.ID, maxOTPAttempts)
+	if err != nil {
+		return nil, false, err
+	}
+	if !claimed || !hmac.Equal([]byte(otp.CodeHash), []byte(s.hash(phone, strings.TrimSpace(code)))) {
+		return nil, false, ErrOTPInvalid
+	}
+	used, err := s.phoneLoginRepo.UseOTP(otp.ID, now)
+	if err != nil {
+		return nil, false, err
+	}
+	if !used {
+		return nil, false, ErrOTPInvalid
+	}
+
+	created := false
+	user, err := s.phoneLoginRepo.FindUserByPhone(phoneVariants(phone))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		user, err = s.createPhoneUser(phone)
+		created = true
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	login, err := s.sessions.Start(user)
+	if err != nil {
+		return nil, false, err
+	}
+	return login, created, nil
+}
+
+// createPhoneUser opens a phone-only account with a username generated from
+// its ID, which the user can change later
+func (s *phoneLoginService) createPhoneUser(phone string) (*models.User, error) {
+	role, err := s.authRepo.FindRoleByName("User")
+	if err != nil {
+		return nil, fmt.Errorf("fetching role: %w", err)
+	}
+	user := &models.User{
+		Telephone: phone,
+		RoleID:    role.ID,
+		PhoneOnly: true,
+	}
+	if err := s.phoneLoginRepo.CreatePhoneUser(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// CompleteAccount adds a name, email and password to a phone-only account,
+// after which it can also sign in with the password
+func (s *phoneLoginService) CompleteAccount(userID uint, request *models.CompleteAccountRequest) error {
+	request.Email = strings.TrimSpace(request.Email)
+	if err := s.displayNames.Check(models.DisplayNameUsername, request.Username); err != nil {
+		return err
+	}
+	if err := s.displayNames.Check(models.DisplayNameFullname, request.Fullname); err != nil {
+		return err
+	}
+	born, restrictedUntil, err := s.ages.Check(request.DateOfBirth, time.Now())
+	if err != nil {
+		return err
+	}
+	inUse, err := s.phoneLoginRepo.EmailInUse(request.Email)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return ErrEmailInUse
+	}
+	hashed, err := bcrypt.GenerateFromPassword([]byte(request.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	completed, err := s.phoneLoginRepo.CompleteAccount(userID, map[string]interface{}{
+		"fullname":         strings.TrimSpace(request.Fullname),
+		"username":         strings.TrimSpace(request.Username),
+		"email":            request.Email,
+		"hashed_password":  string(hashed),
+		"date_of_birth":    born.Format("2006-01-02"),
+		"restricted_until": restrictedUntil,
+	})
+	if err != nil {
+		return err
+	}
+	if !completed {
+		return ErrAccountComplete
+	}
+	return nil
+}
+
+// otpCode returns a random code of otpDigits digits
+func otpCode() (string, error) {
+	max := big.NewInt(1)
+	for i := 0; i < otpDigits; i++ {
+		max.Mul(max, big.NewInt(10))
+	}
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", otpDigits, n.Int64()), nil
+}
+
+// hash keys a code to the phone it was sent to, so a leaked table reveals
+// no codes
+func (s *phoneLoginService) hash(phone, code string) string {
+	mac := hmac.New(sha256.New, []byte(s.Config.JWTSecret))
+	mac.Write([]byte(phone + ":" + code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/models"
+)
+
+// otpRepo holds one code in memory, claiming attempts the way the database
+// does: the check and the count under one lock
+type otpRepo struct {
+	db.PhoneLoginRepository
+	mu  sync.Mutex
+	otp models.PhoneOTP
+}
+
+func (r *otpRepo) LatestOTP(phone string) (*models.PhoneOTP, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	otp := r.otp
+	return &otp, nil
+}
+
+func (r *otpRepo) ClaimAttempt(id uint, maxAttempts int) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.otp.Attempts >= maxAttempts || r.otp.UsedAt != 0 {
+		return false, nil
+	}
+	r.otp.Attempts++
+	return true, nil
+}
+
+func TestVerifyCodeConcurrentGuesses(t *testing.T) {
+	const phone = "+2348012345678"
+	s := &phoneLoginService{Config: &config.Config{JWTSecret: "test-secret"}}
+	repo := &otpRepo{otp: models.PhoneOTP{ID: 1, Phone: phone, ExpiresAt: time.Now().Add(time.Minute).Unix()}}
+	repo.otp.CodeHash = s.hash(phone, "123456")
+	s.phoneLoginRepo = repo
+
+	// The guesses race each other, all reading the code before most are counted
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, _, err := s.VerifyCode(phone, "000000"); !errors.Is(err, ErrOTPInvalid) {
+				t.Errorf("wrong guess returned %v, want ErrOTPInvalid", err)
+			}
+		}()
+	}
+	wg.Wait()
+	if repo.otp.Attempts != maxOTPAttempts {
+		t.Fatalf("code has %d attempts counted, want %d", repo.otp.Attempts, maxOTPAttempts)
+	}
+
+	// The right code no longer works once the attempts are spent
+	if _, _, err := s.VerifyCode(phone, "123456"); !errors.Is(err, ErrOTPInvalid) {
+		t.Fatalf("right code after %d wrong guesses returned %v, want ErrOTPInvalid", maxOTPAttempts, err)
+	}
+}
@@ -1,25 +1,49 @@
 package services
 
 import (
+	"strings"
+
 	"github.com/techagentng/citizenx/config"
 	"github.com/techagentng/citizenx/db"
-	// "github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/textfilter"
 )
 
 // LikeService interface
 type PostService interface {
+	CreatePost(post *models.Post) error
+	GetPostText(postID string) (*models.ModeratedText, error)
 }
 
 // likeService struct
 type postService struct {
-	Config   *config.Config
-	postRepo db.PostRepository
+	Config     *config.Config
+	postRepo   db.PostRepository
+	textFilter *textfilter.Filter
 }
 
 // NewLikeService creates a new instance of LikeService
 func NewPostService(postRepo db.PostRepository, conf *config.Config) PostService {
 	return &postService{
-		postRepo: postRepo,
-		Config:   conf,
+		postRepo:   postRepo,
+		Config:     conf,
+		textFilter: textfilter.New(strings.Split(conf.BannedWords, ",")),
+	}
+}
+
+// CreatePost saves a post with contact details and banned words masked in
+// its public description
+func (p *postService) CreatePost(post *models.Post) error {
+	post.PostDescriptionRaw = post.PostDescription
+	post.PostDescription = p.textFilter.Mask(post.PostDescription)
+	return p.postRepo.CreatePost(post)
+}
+
+// GetPostText returns a post's description as shown publicly and as written
+func (p *postService) GetPostText(postID string) (*models.ModeratedText, error) {
+	post, err := p.postRepo.GetPostByID(postID)
+	if err != nil {
+		return nil, err
 	}
+	return &models.ModeratedText{ID: postID, Public: post.PostDescription, Raw: post.PostDescriptionRaw}, nil
 }
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/locale"
+	"github.com/techagentng/citizenx/models"
+)
+
+const (
+	// reengagementBatchSize is how many inactive users are loaded at a time
+	reengagementBatchSize = 200
+	// reengagementReportsShown is how many local reports a nudge names
+	reengagementReportsShown = 3
+)
+
+// ReengagementService nudges users who have stopped using the app with
+// news from their area and points they are about to lose
+type ReengagementService interface {
+	NudgeInactiveUsers() (int, error)
+}
+
+type reengagementService struct {
+	Config              *config.Config
+	reengagementRepo    db.ReengagementRepository
+	notificationService NotificationService
+}
+
+// NewReengagementService creates a new instance of ReengagementService
+func NewReengagementService(reengagementRepo db.ReengagementRepository, notificationService NotificationService, conf *config.Config) ReengagementService {
+	return &reengagementService{
+		Config:              conf,
+		reengagementRepo:    reengagementRepo,
+		notificationService: notificationService,
+	}
+}
+
+// NudgeInactiveUsers sends a re-engagement notification to every user
+// inactive for reengagement_inactive_days who has not been nudged within
+// reengagement_cooldown_days and has something worth coming back for. It
+// returns how many were sent.
+func (s *reengagementService) NudgeInactiveUsers() (int, error) {
+	if s.Config.ReengagementInactiveDays <= 0 {
+		return 0, nil
+	}
+	now := time.Now()
+	inactiveSince := now.AddDate(0, 0, -s.Config.ReengagementInactiveDays)
+	nudgedSince := now.AddDate(0, 0, -s.Config.ReengagementCooldownDays)
+
+	sent := 0
+	var afterID uint
+	for {
+		users, err := s.reengagementRepo.InactiveUsers(inactiveSince, nudgedSince, afterID, reengagementBatchSize)
+		if err != nil {
+			return sent, err
+		}
+		if len(users) == 0 {
+			return sent, nil
+		}
+		for i := range users {
+			nudged, err := s.nudge(&users[i], inactiveSince, now)
+			if err != nil {
+				return sent, err
+			}
+			if nudged {
+				sent++
+			}
+		}
+		afterID = users[len(users)-1].ID
+	}
+}
+
+// nudge builds and sends one user's message. Users with nothing new in their
+// LGA and no points at risk are left alone, as are users who opted out.
+func (s *reengagementService) nudge(user *models.User, inactiveSince, now time.Time) (bool, error) {
+	var parts, reasons []string
+	format := locale.For(user.Locale)
+
+	if user.LGAName != "" {
+		reports, total, err := s.reengagementRepo.RecentVerifiedReports(user.LGAName, inactiveSince, reengagementReportsShown)
+		if err != nil {
+			return false, err
+		}
+		if total > 0 {
+			parts = append(parts, localReportsLine(format, user.LGAName, reports, total))
+			reasons = append(reasons, "local_reports")
+		}
+	}
+
+	if s.Config.PointsExpiryDays > 0 {
+		// Points earned before this cutoff expire within the warning period
+		cutoff := now.AddDate(0, 0, s.Config.PointsExpiryWarningDays-s.Config.PointsExpiryDays)
+		points, err := s.reengagementRepo.ExpiringPoints(user.ID, cutoff)
+		if err != nil {
+			return false, err
+		}
+		if points > 0 {
+			parts = append(parts, fmt.Sprintf("%s of your reward points will expire by %s. Sign in to keep earning.",
+				format.Number(points), format.Date(now.AddDate(0, 0, s.Config.PointsExpiryWarningDays))))
+			reasons = append(reasons, "expiring_points")
+		}
+	}
+
+	if len(parts) == 0 {
+		return false, nil
+	}
+
+	greeting := "We miss you on CitizenX."
+	if names := strings.Fields(user.Fullname); len(names) > 0 {
+		greeting = fmt.Sprintf("Hi %s, we miss you on CitizenX.", names[0])
+	}
+	message := greeting + " " + strings.Join(parts, " ")
+	sent, err := s.notificationService.Remind(user.ID, "What's been happening near you", message)
+	if err != nil || !sent {
+		return false, err
+	}
+	return true, s.reengagementRepo.RecordNudge(&models.ReengagementNudge{
+		UserID: user.ID,
+		Reason: strings.Join(reasons, ","),
+		SentAt: now.Unix(),
+	})
+}
+
+// localReportsLine summarizes the reports verified in the user's LGA
+func localReportsLine(format locale.Formatter, lgaName string, reports []models.IncidentReport, total int64) string {
+	line := fmt.Sprintf("%s reports were verified in %s while you were away", format.Number(total), lgaName)
+	if total == 1 {
+		line = fmt.Sprintf("1 report was verified in %s while you were away", lgaName)
+	}
+
+	var categories []string
+	seen := map[string]bool{}
+	for _, report := range reports {
+		if report.Category != "" && !seen[report.Category] {
+			seen[report.Category] = true
+			categories = append(categories, report.Category)
+		}
+	}
+	if len(categories) > 0 {
+		line += ", including " + strings.Join(categories, ", ")
+	}
+	return line + "."
+}
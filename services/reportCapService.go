@@ -0,0 +1,130 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/locale"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrDailyCapReached is returned when a user has filed as many reports
+	// today as they may.
+	ErrDailyCapReached = errors.New("you have reached today's report limit")
+	// ErrCapExemptionNotFound is returned when removing an exemption the
+	// user does not have.
+	ErrCapExemptionNotFound = errors.New("this account is not exempt from the report limit")
+)
+
+//go:generate mockgen -destination=../mocks/report_cap_mock.go -package=mocks github.com/techagentng/citizenx/services ReportCapService
+
+// ReportCapService limits how many reports a user, or the device they use,
+// may file in a day. report_daily_cap applies to all categories together
+// and report_daily_cap_overrides sets lower caps for single categories.
+// Accounts on the exemption list, such as organisations reporting for many
+// people, are not limited.
+type ReportCapService interface {
+	Check(userID uint, category string, now time.Time) error
+	ListExemptions() ([]models.ReportCapExemption, error)
+	Exempt(exemption *models.ReportCapExemption, adminID uint) error
+	RemoveExemption(userID uint) error
+}
+
+type reportCapService struct {
+	Config        *config.Config
+	reportCapRepo db.ReportCapRepository
+	categoryCaps  map[string]int
+}
+
+// NewReportCapService creates a new instance of ReportCapService
+func NewReportCapService(reportCapRepo db.ReportCapRepository, conf *config.Config) ReportCapService {
+	return &reportCapService{
+		Config:        conf,
+		reportCapRepo: reportCapRepo,
+		categoryCaps:  parseCategoryCaps(conf.ReportDailyCapOverrides),
+	}
+}
+
+// parseCategoryCaps reads "category=cap" pairs separated by commas, skipping
+// any that are malformed
+func parseCategoryCaps(overrides string) map[string]int {
+	caps := map[string]int{}
+	for _, pair := range strings.Split(overrides, ",") {
+		category, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		category = strings.ToLower(strings.TrimSpace(category))
+		if err != nil || n < 0 || category == "" {
+			continue
+		}
+		caps[category] = n
+	}
+	return caps
+}
+
+// Check returns ErrDailyCapReached, saying which limit and when it resets,
+// when the user may not file another report in category today. Days run
+// midnight to midnight in Nigerian time.
+func (s *reportCapService) Check(userID uint, category string, now time.Time) error {
+	category = strings.ToLower(strings.TrimSpace(category))
+	categoryCap, hasCategoryCap := s.categoryCaps[category]
+	if s.Config.ReportDailyCap <= 0 && !hasCategoryCap {
+		return nil
+	}
+	exempt, err := s.reportCapRepo.IsExempt(userID)
+	if err != nil || exempt {
+		return err
+	}
+
+	local := now.In(locale.TimeZone)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, locale.TimeZone)
+	resets := midnight.AddDate(0, 0, 1).Format("15:04 on Jan 2")
+
+	if hasCategoryCap {
+		count, err := s.reportCapRepo.CountReportsSince(userID, category, midnight.Unix())
+		if err != nil {
+			return err
+		}
+		if count >= int64(categoryCap) {
+			return fmt.Errorf("%w: you can file %d %s reports a day, try again after %s", ErrDailyCapReached, categoryCap, category, resets)
+		}
+	}
+	if s.Config.ReportDailyCap > 0 {
+		count, err := s.reportCapRepo.CountReportsSince(userID, "", midnight.Unix())
+		if err != nil {
+			return err
+		}
+		if count >= int64(s.Config.ReportDailyCap) {
+			return fmt.Errorf("%w: you can file %d reports a day, try again after %s", ErrDailyCapReached, s.Config.ReportDailyCap, resets)
+		}
+	}
+	return nil
+}
+
+func (s *reportCapService) ListExemptions() ([]models.ReportCapExemption, error) {
+	return s.reportCapRepo.ListExemptions()
+}
+
+func (s *reportCapService) Exempt(exemption *models.ReportCapExemption, adminID uint) error {
+	exemption.Reason = strings.TrimSpace(exemption.Reason)
+	exemption.CreatedBy = adminID
+	exemption.CreatedAt = time.Now().Unix()
+	return s.reportCapRepo.AddExemption(exemption)
+}
+
+func (s *reportCapService) RemoveExemption(userID uint) error {
+	err := s.reportCapRepo.RemoveExemption(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrCapExemptionNotFound
+	}
+	return err
+}
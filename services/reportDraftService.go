@@ -0,0 +1,214 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techagentng/citizenx/geo"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrDraftNotFound is returned for drafts that do not exist, have
+	// expired or belong to another user.
+	ErrDraftNotFound = errors.New("report draft not found")
+	// ErrDraftMediaNotFound is returned when a draft is finalized with media
+	// that was not uploaded to it, or whose upload has not finished.
+	ErrDraftMediaNotFound = errors.New("media was not uploaded to this draft")
+)
+
+// CreateDraft starts a report whose media will be uploaded separately. A full
+// plus code, a route or a list of points may be given instead of
+// coordinates.
+func (s *IncidentService) CreateDraft(userID uint, draft *models.ReportDraft) error {
+	restrictedUntil, err := s.incidentRepo.UserRestrictedUntil(userID)
+	if err != nil {
+		return err
+	}
+	if err := s.ages.AllowCategory(restrictedUntil, draft.Category, time.Now()); err != nil {
+		return err
+	}
+	if draft.PlusCode != "" && draft.Latitude == 0 && draft.Longitude == 0 {
+		area, err := geo.DecodePlusCode(draft.PlusCode)
+		if err != nil {
+			return err
+		}
+		draft.Latitude, draft.Longitude = area.Center()
+	}
+	if len(draft.Points) > 0 {
+		// Checked the way they will be when the report is submitted
+		check := models.IncidentReport{Latitude: draft.Latitude, Longitude: draft.Longitude, Points: draft.Points}
+		if err := prepareReportPoints(&check); err != nil {
+			return err
+		}
+		draft.Latitude, draft.Longitude = check.Latitude, check.Longitude
+	}
+	if draft.Route != "" {
+		route, err := ParseRoute(draft.Route)
+		if err != nil {
+			return err
+		}
+		if draft.Latitude == 0 && draft.Longitude == 0 {
+			draft.Latitude, draft.Longitude = route[len(route)/2].Latitude, route[len(route)/2].Longitude
+		}
+	}
+	draft.ID = uuid.New()
+	draft.UserID = userID
+	draft.CreatedAt = time.Now().Unix()
+	return s.draftRepo.CreateDraft(draft)
+}
+
+// GetDraft returns one of the user's drafts
+func (s *IncidentService) GetDraft(draftID string, userID uint) (*models.ReportDraft, error) {
+	draft, err := s.draftRepo.GetDraft(draftID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrDraftNotFound
+	}
+	return draft, err
+}
+
+// GetDraftMedia returns the media uploaded to one of the user's drafts so
+// far
+func (s *IncidentService) GetDraftMedia(draftID string, userID uint) ([]models.Media, error) {
+	if _, err := s.GetDraft(draftID, userID); err != nil {
+		return nil, err
+	}
+	return s.mediaRepo.GetMediaByReportID(draftID)
+}
+
+// FinalizeDraft turns a draft and the media uploaded to it into a submitted
+// report. The report keeps the draft's ID, so the media already points at it,
+// and media points are awarded now rather than at upload. A draft saved
+// without a location is placed by the address it is finalized from.
+//
+// When mediaIDs are given the report keeps only that media, in that order,
+// and every ID must name finished media of the draft; clients that upload
+// separately use it to make sure the report has what they sent.
+func (s *IncidentService) FinalizeDraft(ctx context.Context, user *models.User, draftID, clientIP string, mediaIDs []string) (*models.IncidentReport, error) {
+	draft, err := s.GetDraft(draftID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	media, err := s.mediaRepo.GetMediaByReportID(draftID)
+	if err != nil {
+		return nil, fmt.Errorf("loading draft media: %v", err)
+	}
+	if len(mediaIDs) > 0 {
+		if media, err = selectDraftMedia(media, mediaIDs); err != nil {
+			return nil, err
+		}
+	}
+	var imageCount, videoCount, audioCount int
+	var feedURLs, thumbnailURLs, fullsizeURLs []string
+	for _, m := range media {
+		switch m.FileType {
+		case "image":
+			imageCount++
+		case "video":
+			videoCount++
+		case "audio":
+			audioCount++
+		}
+		feedURLs = appendNonEmpty(feedURLs, m.FeedURL)
+		thumbnailURLs = appendNonEmpty(thumbnailURLs, m.ThumbnailURL)
+		fullsizeURLs = appendNonEmpty(fullsizeURLs, m.FullSizeURL)
+	}
+
+	report := &models.IncidentReport{
+		ID:              draft.ID,
+		UserFullname:    user.Fullname,
+		UserUsername:    user.Username,
+		DateOfIncidence: draft.DateOfIncidence,
+		Description:     draft.Description,
+		StateName:       draft.StateName,
+		LGAName:         draft.LGAName,
+		Latitude:        draft.Latitude,
+		Longitude:       draft.Longitude,
+		Telephone:       draft.Telephone,
+		Email:           draft.Email,
+		Address:         draft.Address,
+		Rating:          draft.Rating,
+		Category:        draft.Category,
+		SubReportType:   draft.SubReportType,
+		UserIsAnonymous: draft.UserIsAnonymous,
+		Route:           draft.Route,
+		Points:          draft.Points,
+		CreatedAt:       time.Now().Unix(),
+		FeedURLs:        strings.Join(feedURLs, ","),
+		ThumbnailURLs:   strings.Join(thumbnailURLs, ","),
+		FullSizeURLs:    strings.Join(fullsizeURLs, ","),
+	}
+	s.ipLocation.Apply(ctx, report, clientIP)
+
+	reportType := &models.ReportType{
+		ID:                   uuid.New(),
+		UserID:               user.ID,
+		IncidentReportID:     draft.ID,
+		Category:             draft.Category,
+		StateName:            draft.StateName,
+		LGAName:              draft.LGAName,
+		IncidentReportRating: draft.Rating,
+		DateOfIncidence:      time.Now(),
+	}
+	reportType.SubReports = []models.SubReport{{
+		ID:               uuid.New(),
+		ReportTypeID:     reportType.ID,
+		SubReportType:    draft.SubReportType,
+		IncidentReportID: draft.ID,
+	}}
+	report.Classification = reportType
+
+	// Media left out is dropped first; a retry names the same media, so the
+	// selection holds whether or not the report is saved
+	if len(mediaIDs) > 0 {
+		if err := s.mediaRepo.DeleteMediaExcept(draftID, mediaIDs); err != nil {
+			return nil, fmt.Errorf("removing unselected draft media: %v", err)
+		}
+	}
+
+	// The report, its media points and the draft's removal are saved in
+	// one transaction, so a failed finalize can be retried
+	return s.SaveReport(user.ID, report.Latitude, report.Longitude, report, draft.ID.String(), MediaPoints(imageCount, videoCount, audioCount))
+}
+
+// PurgeAbandonedDrafts removes drafts that were never finalized within the
+// configured lifetime, along with their media
+func (s *IncidentService) PurgeAbandonedDrafts() (int64, error) {
+	ttl := time.Duration(s.Config.DraftReportTTLHours) * time.Hour
+	if ttl <= 0 {
+		return 0, nil
+	}
+	return s.draftRepo.PurgeDrafts(time.Now().Add(-ttl))
+}
+
+// selectDraftMedia returns the media named by ids in their order, or
+// ErrDraftMediaNotFound when one is missing or named twice
+func selectDraftMedia(media []models.Media, ids []string) ([]models.Media, error) {
+	byID := make(map[string]models.Media, len(media))
+	for _, m := range media {
+		byID[m.ID] = m
+	}
+	selected := make([]models.Media, 0, len(ids))
+	for _, id := range ids {
+		m, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrDraftMediaNotFound, id)
+		}
+		delete(byID, id)
+		selected = append(selected, m)
+	}
+	return selected, nil
+}
+
+func appendNonEmpty(list []string, value string) []string {
+	if value == "" {
+		return list
+	}
+	return append(list, value)
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/locale"
+	"github.com/techagentng/citizenx/models"
+	"github.com/xuri/excelize/v2"
+)
+
+// Export formats
+const (
+	ExportCSV  = "csv"
+	ExportXLSX = "xlsx"
+)
+
+// exportBatchSize is how many reports are read from the database at a time
+const exportBatchSize = 500
+
+// ErrUnknownExportFormat is returned for an export format other than csv or
+// xlsx.
+var ErrUnknownExportFormat = errors.New("format must be csv or xlsx")
+
+// exportColumns head the exported sheet. Reporters' contact details are
+// never exported, and anonymous reporters are not named.
+var exportColumns = []string{
+	"id", "category", "sub_report_type", "description", "state_name", "lga_name", "ward_name", "address",
+	"latitude", "longitude", "status", "time_of_incidence", "created_at", "resolved_at",
+	"agency_id", "upvotes", "downvotes", "reporter", "reporter_username",
+}
+
+//go:generate mockgen -destination=../mocks/report_export_mock.go -package=mocks github.com/techagentng/citizenx/services ReportExportService
+
+// ReportExportService writes out datasets of reports for partners, such as
+// the government agencies that pull a month of reports at a time
+type ReportExportService interface {
+	ContentType(format string) (string, error)
+	Export(w io.Writer, format string, filters db.ReportFilter) error
+}
+
+type reportExportService struct {
+	Config     *config.Config
+	exportRepo db.ReportExportRepository
+}
+
+// NewReportExportService creates a new instance of ReportExportService
+func NewReportExportService(exportRepo db.ReportExportRepository, conf *config.Config) ReportExportService {
+	return &reportExportService{
+		Config:     conf,
+		exportRepo: exportRepo,
+	}
+}
+
+// ContentType returns the media type of exports in format
+func (s *reportExportService) ContentType(format string) (string, error) {
+	switch format {
+	case ExportCSV:
+		return "text/csv", nil
+	case ExportXLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", nil
+	}
+	return "", ErrUnknownExportFormat
+}
+
+// Export writes the reports matching filters to w. CSV rows are written as
+// each batch is read; a spreadsheet is assembled on disk by excelize's
+// stream writer and written once complete.
+func (s *reportExportService) Export(w io.Writer, format string, filters db.ReportFilter) error {
+	switch format {
+	case ExportCSV:
+		return s.exportCSV(w, filters)
+	case ExportXLSX:
+		return s.exportXLSX(w, filters)
+	}
+	return ErrUnknownExportFormat
+}
+
+func (s *reportExportService) exportCSV(w io.Writer, filters db.ReportFilter) error {
+	out := csv.NewWriter(w)
+	if err := out.Write(exportColumns); err != nil {
+		return err
+	}
+	err := s.exportRepo.EachReportBatch(filters, exportBatchSize, func(reports []models.IncidentReport) error {
+		for i := range reports {
+			row := exportRow(&reports[i])
+			record := make([]string, len(row))
+			for n, value := range row {
+				record[n] = csvValue(value)
+			}
+			if err := out.Write(record); err != nil {
+				return err
+			}
+		}
+		out.Flush()
+		return out.Error()
+	})
+	if err != nil {
+		return err
+	}
+	out.Flush()
+	return out.Error()
+}
+
+func (s *reportExportService) exportXLSX(w io.Writer, filters db.ReportFilter) error {
+	file := excelize.NewFile()
+	defer file.Close()
+	sheet, err := file.NewStreamWriter("Sheet1")
+	if err != nil {
+		return err
+	}
+	header := make([]interface{}, len(exportColumns))
+	for i, column := range exportColumns {
+		header[i] = column
+	}
+	if err := sheet.SetRow("A1", header); err != nil {
+		return err
+	}
+	row := 2
+	err = s.exportRepo.EachReportBatch(filters, exportBatchSize, func(reports []models.IncidentReport) error {
+		for i := range reports {
+			cell, err := excelize.CoordinatesToCellName(1, row)
+			if err != nil {
+				return err
+			}
+			if err := sheet.SetRow(cell, exportRow(&reports[i])); err != nil {
+				return err
+			}
+			row++
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if err := sheet.Flush(); err != nil {
+		return err
+	}
+	return file.Write(w)
+}
+
+// exportRow is report's row under exportColumns
+func exportRow(report *models.IncidentReport) []interface{} {
+	reporter, username := report.UserFullname, report.UserUsername
+	if report.UserIsAnonymous {
+		reporter, username = "", ""
+	}
+	agency := ""
+	if report.AgencyID != nil {
+		agency = strconv.FormatUint(uint64(*report.AgencyID), 10)
+	}
+	incidence := ""
+	if !report.TimeofIncidence.IsZero() {
+		incidence = report.TimeofIncidence.In(locale.TimeZone).Format(time.RFC3339)
+	}
+	return []interface{}{
+		report.ID.String(), report.Category, report.SubReportType, report.Description,
+		report.StateName, report.LGAName, report.WardName, report.Address,
+		report.Latitude, report.Longitude, models.NormalizeReportStatus(report.ReportStatus),
+		incidence, exportTime(report.CreatedAt), exportTime(report.ResolvedAt),
+		agency, report.UpvoteCount, report.DownvoteCount, reporter, username,
+	}
+}
+
+// exportTime formats a Unix time in Nigerian time, leaving unset times blank
+func exportTime(unix int64) string {
+	if unix == 0 {
+		return ""
+	}
+	return time.Unix(unix, 0).In(locale.TimeZone).Format(time.RFC3339)
+}
+
+// csvValue formats a cell for CSV. Text that a spreadsheet would read as a
+// formula is quoted with a leading apostrophe.
+func csvValue(value interface{}) string {
+	switch v := value.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(v)
+	case string:
+		if v != "" && strings.ContainsRune("=+-@", rune(v[0])) {
+			return "'" + v
+		}
+		return v
+	}
+	return ""
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/jobs"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+// IngestReportJob is the job type that saves a queued report submission
+const IngestReportJob = "report.ingest"
+
+// ErrSubmissionNotFound is returned for a report submission that does not
+// exist or that another user made.
+var ErrSubmissionNotFound = errors.New("report submission not found")
+
+// finalSubmissionErrors are the errors saving a report that trying again
+// will not fix
+var finalSubmissionErrors = []error{
+	ErrCategoryClosed,
+	ErrDailyCapReached,
+	ErrTooYoung,
+	ErrCategoryRestricted,
+	ErrInvalidPoints,
+	ErrInvalidRoute,
+}
+
+//go:generate mockgen -destination=../mocks/report_ingest_mock.go -package=mocks github.com/techagentng/citizenx/services ReportIngestService
+
+// ReportIngestService takes report submissions during surges, such as
+// election day, when saving each report while the reporter waits would
+// overwhelm the database. Submissions are checked, stored with a job to save
+// them and acknowledged at once; the job workers save them at the pace the
+// database allows, and reporters follow each submission's status.
+type ReportIngestService interface {
+	Queue(userID uint, report *models.IncidentReport, subReportType string) (*models.ReportSubmission, error)
+	GetSubmission(id string, userID uint) (*models.ReportSubmission, error)
+	RegisterJobs(worker *jobs.Worker)
+}
+
+type reportIngestService struct {
+	Config         *config.Config
+	submissionRepo db.ReportSubmissionRepository
+	incidentRepo   db.IncidentReportRepository
+	reports        IncidentReportService
+	windows        SubmissionWindowService
+	caps           ReportCapService
+}
+
+// queuedReport is a submitted report as it waits in the queue
+type queuedReport struct {
+	UserID        uint                  `json:"user_id"`
+	Report        models.IncidentReport `json:"report"`
+	NetworkFlag   string                `json:"network_flag"` // not part of the report's JSON
+	SubReportType string                `json:"sub_report_type"`
+}
+
+// NewReportIngestService creates a new instance of ReportIngestService
+func NewReportIngestService(submissionRepo db.ReportSubmissionRepository, incidentRepo db.IncidentReportRepository, reports IncidentReportService, windows SubmissionWindowService, caps ReportCapService, conf *config.Config) ReportIngestService {
+	return &reportIngestService{
+		Config:         conf,
+		submissionRepo: submissionRepo,
+		incidentRepo:   incidentRepo,
+		reports:        reports,
+		windows:        windows,
+		caps:           caps,
+	}
+}
+
+// Queue checks what can be checked without writing and queues the report to
+// be saved under its ID. The checks are repeated when it is saved, so a
+// submission acknowledged here may still be rejected.
+func (s *reportIngestService) Queue(userID uint, report *models.IncidentReport, subReportType string) (*models.ReportSubmission, error) {
+	now := time.Now()
+	if err := s.windows.Check(report.Category, now); err != nil {
+		return nil, err
+	}
+	if err := s.caps.Check(userID, report.Category, now); err != nil {
+		return nil, err
+	}
+	if err := prepareReportPoints(report); err != nil {
+		return nil, err
+	}
+	if report.ID == uuid.Nil {
+		report.ID = uuid.New()
+	}
+
+	payload, err := json.Marshal(queuedReport{
+		UserID:        userID,
+		Report:        *report,
+		NetworkFlag:   report.NetworkFlag,
+		SubReportType: subReportType,
+	})
+	if err != nil {
+		return nil, err
+	}
+	submission := &models.ReportSubmission{
+		ID:      report.ID,
+		UserID:  userID,
+		Status:  models.SubmissionQueued,
+		Payload: string(payload),
+	}
+	if err := s.submissionRepo.QueueSubmission(submission, IngestReportJob); err != nil {
+		return nil, err
+	}
+	return submission, nil
+}
+
+func (s *reportIngestService) GetSubmission(id string, userID uint) (*models.ReportSubmission, error) {
+	submission, err := s.submissionRepo.GetSubmission(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && submission.UserID != userID) {
+		return nil, ErrSubmissionNotFound
+	}
+	return submission, err
+}
+
+// RegisterJobs installs the ingestion job handler on worker
+func (s *reportIngestService) RegisterJobs(worker *jobs.Worker) {
+	worker.Handle(IngestReportJob, s.save)
+}
+
+// save saves a queued report the way a direct submission is saved. Errors
+// that another attempt cannot fix reject the submission; others are
+// returned so the job is retried, until it runs out of attempts.
+func (s *reportIngestService) save(ctx context.Context, payload []byte) error {
+	var job struct {
+		SubmissionID string `json:"submission_id"`
+	}
+	if err := json.Unmarshal(payload, &job); err != nil {
+		return err
+	}
+	submission, err := s.submissionRepo.GetSubmission(job.SubmissionID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if submission.Status == models.SubmissionSaved || submission.Status == models.SubmissionRejected {
+		return nil
+	}
+
+	// A worker stopped after saving the report but before the job was
+	// marked done leaves the report saved
+	exists, err := s.incidentRepo.ReportExists(submission.ID)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return s.submissionRepo.UpdateSubmission(submission.ID, models.SubmissionSaved, "")
+	}
+
+	var queued queuedReport
+	if err := json.Unmarshal([]byte(submission.Payload), &queued); err != nil {
+		return s.submissionRepo.UpdateSubmission(submission.ID, models.SubmissionRejected, "the submission could not be read")
+	}
+	if err := s.saveReport(&queued); err != nil {
+		for _, final := range finalSubmissionErrors {
+			if errors.Is(err, final) {
+				return s.submissionRepo.UpdateSubmission(submission.ID, models.SubmissionRejected, err.Error())
+			}
+		}
+		status := models.SubmissionRetrying
+		if submission.Attempts+1 >= db.DefaultJobMaxAttempts {
+			status = models.SubmissionFailed
+		}
+		if updateErr := s.submissionRepo.UpdateSubmission(submission.ID, status, err.Error()); updateErr != nil {
+			return updateErr
+		}
+		return err
+	}
+	return s.submissionRepo.UpdateSubmission(submission.ID, models.SubmissionSaved, "")
+}
+
+func (s *reportIngestService) saveReport(queued *queuedReport) error {
+	report := &queued.Report
+	report.NetworkFlag = queued.NetworkFlag
+
+	reportType := &models.ReportType{
+		ID:                   uuid.New(),
+		UserID:               queued.UserID,
+		IncidentReportID:     report.ID,
+		Category:             report.Category,
+		StateName:            report.StateName,
+		LGAName:              report.LGAName,
+		IncidentReportRating: report.Rating,
+		DateOfIncidence:      time.Now(),
+	}
+	reportType.SubReports = []models.SubReport{{
+		ID:            uuid.New(),
+		ReportTypeID:  reportType.ID,
+		SubReportType: queued.SubReportType,
+	}}
+	report.Classification = reportType
+
+	_, err := s.reports.SaveReport(queued.UserID, report.Latitude, report.Longitude, report, report.ID.String(), 0)
+	return err
+}
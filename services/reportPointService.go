@@ -0,0 +1,61 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/models"
+)
+
+// ErrInvalidPoints is returned when the points of a multi-point report
+// cannot be used
+var ErrInvalidPoints = errors.New("invalid report points")
+
+// prepareReportPoints checks the points of a report and numbers them in the
+// order given. A report without coordinates of its own takes the first
+// point as its primary point.
+func prepareReportPoints(report *models.IncidentReport) error {
+	if len(report.Points) == 0 {
+		return nil
+	}
+	if len(report.Points) > models.MaxReportPoints {
+		return fmt.Errorf("%w: at most %d points are allowed", ErrInvalidPoints, models.MaxReportPoints)
+	}
+	for i := range report.Points {
+		p := &report.Points[i]
+		if math.Abs(p.Latitude) > 90 || math.Abs(p.Longitude) > 180 || (p.Latitude == 0 && p.Longitude == 0) {
+			return fmt.Errorf("%w: point %d has no valid coordinates", ErrInvalidPoints, i+1)
+		}
+		p.ID = 0
+		p.ReportID = report.ID
+		p.Position = i
+		p.Label = strings.TrimSpace(p.Label)
+		if len(p.Label) > 200 {
+			return fmt.Errorf("%w: the label of point %d is too long", ErrInvalidPoints, i+1)
+		}
+	}
+	if report.Latitude == 0 && report.Longitude == 0 {
+		report.Latitude, report.Longitude = report.Points[0].Latitude, report.Points[0].Longitude
+	}
+	return nil
+}
+
+// GetReportPoints returns the ordered points of a report, or just its
+// primary point for reports made at a single place
+func (s *IncidentService) GetReportPoints(reportID string) ([]models.ReportPoint, error) {
+	reports, err := s.incidentRepo.GetReportsByIDs([]string{reportID}, db.WithPoints())
+	if err != nil {
+		return nil, err
+	}
+	if len(reports) == 0 {
+		return nil, ErrReportNotFound
+	}
+	report := reports[0]
+	if len(report.Points) == 0 {
+		return []models.ReportPoint{{Latitude: report.Latitude, Longitude: report.Longitude}}, nil
+	}
+	return report.Points, nil
+}
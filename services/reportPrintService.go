@@ -0,0 +1,233 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/httpclient"
+	"github.com/techagentng/citizenx/locale"
+	"github.com/techagentng/citizenx/models"
+)
+
+// MaxPrintedThumbnails is the number of media thumbnails shown on a printed
+// report, as many as fit across one row of the page
+const MaxPrintedThumbnails = 4
+
+// printFetchTimeout bounds downloading the map snippet or a thumbnail
+const printFetchTimeout = 15 * time.Second
+
+// ReportPrintService renders reports for offline circulation
+type ReportPrintService interface {
+	ReportPDF(reportID string, format locale.Formatter, publishedOnly bool) ([]byte, error)
+}
+
+type reportPrintService struct {
+	Config       *config.Config
+	incidentRepo db.IncidentReportRepository
+	client       *http.Client
+}
+
+// NewReportPrintService creates a new instance of ReportPrintService
+func NewReportPrintService(incidentRepo db.IncidentReportRepository, conf *config.Config) ReportPrintService {
+	return &reportPrintService{
+		Config:       conf,
+		incidentRepo: incidentRepo,
+		client:       httpclient.New("print-media", httpclient.Options{Timeout: printFetchTimeout}),
+	}
+}
+
+// ReportPDF renders a one page A4 summary of a report. The map snippet is
+// only drawn when a Google Maps key is configured, and media that cannot be
+// fetched is left out rather than failing the page. Dates are written for
+// the reader's locale. With publishedOnly, reports not yet or no longer
+// published are not found. The page circulates freely, so coordinates are
+// only printed as precisely as coarsened locations keep them.
+func (s *reportPrintService) ReportPDF(reportID string, format locale.Formatter, publishedOnly bool) ([]byte, error) {
+	reports, err := s.incidentRepo.GetReportsByIDs([]string{reportID}, db.WithMedia())
+	if err != nil {
+		return nil, err
+	}
+	if len(reports) == 0 || (publishedOnly && !models.IsPublishedReportStatus(reports[0].ReportStatus)) {
+		return nil, ErrReportNotFound
+	}
+	report := reports[0]
+	report.Latitude, report.Longitude = coarseCoordinate(report.Latitude), coarseCoordinate(report.Longitude)
+
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle("CitizenX report "+reportID, true)
+	pdf.SetMargins(15, 15, 15)
+	// Everything is laid out on a single sheet; text that would run past
+	// the bottom is cut rather than spilling onto a second page
+	pdf.SetAutoPageBreak(false, 15)
+	pdf.AddPage()
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pageWidth, pageHeight := pdf.GetPageSize()
+	contentWidth := pageWidth - 30
+
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.CellFormat(contentWidth, 10, "CitizenX Incident Report", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.SetTextColor(100, 100, 100)
+	pdf.CellFormat(contentWidth, 5, tr(fmt.Sprintf("Report %s - printed %s", reportID, format.DateTime(time.Now()))), "", 1, "L", false, 0, "")
+	pdf.SetTextColor(0, 0, 0)
+	pdf.Ln(4)
+
+	category := report.Category
+	if report.SubReportType != "" {
+		category += " / " + report.SubReportType
+	}
+	location := joinNonEmpty(", ", report.Address, report.WardName, report.LGAName, report.StateName)
+	status := report.ReportStatus
+	if status == "" {
+		status = "pending"
+	}
+	incidentDate := report.DateOfIncidence
+	if day, err := time.Parse("2006-01-02", incidentDate); err == nil {
+		incidentDate = format.Date(day)
+	}
+	for _, field := range [][2]string{
+		{"Category", category},
+		{"Status", status},
+		{"Date of incident", incidentDate},
+		{"Location", location},
+		{"Coordinates", fmt.Sprintf("%.*f, %.*f", db.CoarseLocationDecimals, report.Latitude, db.CoarseLocationDecimals, report.Longitude)},
+	} {
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.CellFormat(40, 6, field[0], "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.MultiCell(contentWidth-40, 6, tr(field[1]), "", "L", false)
+	}
+	pdf.Ln(3)
+
+	if image := s.mapSnippet(report); image != nil {
+		if name, ok := registerImage(pdf, "map", image); ok {
+			pdf.ImageOptions(name, pdf.GetX(), pdf.GetY(), contentWidth, contentWidth/2, true, fpdf.ImageOptions{}, 0, "")
+			pdf.Ln(3)
+		}
+	}
+
+	section := func(title, body string) {
+		pdf.SetFont("Helvetica", "B", 12)
+		pdf.CellFormat(contentWidth, 7, title, "B", 1, "L", false, 0, "")
+		pdf.Ln(1)
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.MultiCell(contentWidth, 5, tr(body), "", "L", false)
+		pdf.Ln(3)
+	}
+	section("Description", report.Description)
+	if report.OfficialResponse != "" {
+		response := report.OfficialResponse
+		if report.OfficialResponseAt > 0 {
+			response += "\n\nResponded " + format.Unix(report.OfficialResponseAt)
+		}
+		section("Official response", response)
+	} else {
+		section("Official response", "No official response has been recorded.")
+	}
+
+	thumbWidth := (contentWidth - 3*float64(MaxPrintedThumbnails-1)) / MaxPrintedThumbnails
+	if y := pdf.GetY(); y+thumbWidth < pageHeight-15 {
+		x := 15.0
+		placed := 0
+		for i, m := range report.Media {
+			if placed == MaxPrintedThumbnails {
+				break
+			}
+			if m.ThumbnailURL == "" {
+				continue
+			}
+			data, err := readStoredMedia(s.client, m.ThumbnailURL)
+			if err != nil {
+				log.Printf("printing report %s: fetching thumbnail %s: %v", reportID, m.ID, err)
+				continue
+			}
+			name, ok := registerImage(pdf, fmt.Sprintf("media-%d", i), data)
+			if !ok {
+				continue
+			}
+			pdf.ImageOptions(name, x, y, thumbWidth, thumbWidth, false, fpdf.ImageOptions{}, 0, "")
+			x += thumbWidth + 3
+			placed++
+		}
+	}
+
+	pdf.SetY(pageHeight - 12)
+	pdf.SetFont("Helvetica", "I", 8)
+	pdf.SetTextColor(100, 100, 100)
+	pdf.CellFormat(contentWidth, 4, "Generated by CitizenX. Check the online report for the latest status.", "", 0, "C", false, 0, "")
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("rendering report pdf: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// coarseCoordinate rounds a coordinate to db.CoarseLocationDecimals
+func coarseCoordinate(v float64) float64 {
+	scale := math.Pow(10, db.CoarseLocationDecimals)
+	return math.Round(v*scale) / scale
+}
+
+// mapSnippet fetches a static map centred on the report, or nil when maps
+// are not configured or the request fails
+func (s *reportPrintService) mapSnippet(report models.IncidentReport) []byte {
+	if s.Config.GoogleMapsApiKey == "" || (report.Latitude == 0 && report.Longitude == 0) {
+		return nil
+	}
+	center := fmt.Sprintf("%f,%f", report.Latitude, report.Longitude)
+	query := url.Values{
+		"center":  {center},
+		"zoom":    {"15"},
+		"size":    {"640x320"},
+		"markers": {"color:red|" + center},
+		"key":     {s.Config.GoogleMapsApiKey},
+	}
+	data, err := readStoredMedia(s.client, "https://maps.googleapis.com/maps/api/staticmap?"+query.Encode())
+	if err != nil {
+		log.Printf("printing report %s: fetching map: %v", report.ID, err)
+		return nil
+	}
+	return data
+}
+
+// registerImage adds a JPEG or PNG to the document. Anything else, or an
+// image the PDF writer rejects, is skipped.
+func registerImage(pdf *fpdf.Fpdf, name string, data []byte) (string, bool) {
+	var imageType string
+	switch http.DetectContentType(data) {
+	case "image/jpeg":
+		imageType = "JPG"
+	case "image/png":
+		imageType = "PNG"
+	default:
+		return "", false
+	}
+	pdf.RegisterImageOptionsReader(name, fpdf.ImageOptions{ImageType: imageType}, bytes.NewReader(data))
+	if pdf.Err() {
+		log.Printf("printing report: skipping image %s: %v", name, pdf.Error())
+		pdf.ClearError()
+		return "", false
+	}
+	return name, true
+}
+
+// joinNonEmpty joins the non-empty parts with sep
+func joinNonEmpty(sep string, parts ...string) string {
+	kept := parts[:0:0]
+	for _, part := range parts {
+		if part != "" {
+			kept = append(kept, part)
+		}
+	}
+	return strings.Join(kept, sep)
+}
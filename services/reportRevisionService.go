@@ -0,0 +1,166 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/geo"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/policy"
+	"gorm.io/gorm"
+)
+
+// MaxDescriptionLength bounds a report's description, as the column does
+const MaxDescriptionLength = 1000
+
+var (
+	// ErrReportNotEditable is returned when editing a report after the edit
+	// window has closed, or one that is no longer pending.
+	ErrReportNotEditable = errors.New("this report can no longer be edited")
+	// ErrInvalidReportEdit is returned for an edit with an invalid field, or
+	// one that changes nothing.
+	ErrInvalidReportEdit = errors.New("invalid report edit")
+	// ErrRevisionNotFound is returned for a version a report never had.
+	ErrRevisionNotFound = errors.New("report version not found")
+)
+
+// EditReport applies the reporter's edit to their report within
+// report_edit_window_minutes of submitting it, while it waits for review.
+// The version it replaces is kept, so moderators can see what changed.
+func (s *IncidentService) EditReport(userID uint, reportID string, edit models.ReportEditRequest) (*models.ReportRevision, error) {
+	report, err := s.report(reportID)
+	if err != nil {
+		return nil, err
+	}
+	if policy.Authorize(policy.Subject{UserID: userID}, policy.EditReport, policy.Report(report)) != nil {
+		return nil, ErrNotReportOwner
+	}
+	window := time.Duration(s.Config.ReportEditWindowMinutes) * time.Minute
+	if window <= 0 || time.Since(time.Unix(report.CreatedAt, 0)) > window ||
+		models.NormalizeReportStatus(report.ReportStatus) != models.ReportStatusPending {
+		return nil, ErrReportNotEditable
+	}
+
+	changes := map[string]interface{}{}
+	var fields []string
+	change := func(field string, values map[string]interface{}) {
+		fields = append(fields, field)
+		for column, value := range values {
+			changes[column] = value
+		}
+	}
+	if edit.Description != nil {
+		description := strings.TrimSpace(*edit.Description)
+		if description == "" || len([]rune(description)) > MaxDescriptionLength {
+			return nil, fmt.Errorf("%w: the description must be 1 to %d characters", ErrInvalidReportEdit, MaxDescriptionLength)
+		}
+		if description != report.DescriptionRaw {
+			change("description", map[string]interface{}{
+				"DescriptionRaw": description,
+				"Description":    s.textFilter.Mask(description),
+			})
+		}
+	}
+	if edit.DateOfIncidence != nil && strings.TrimSpace(*edit.DateOfIncidence) != report.DateOfIncidence {
+		change("date_of_incidence", map[string]interface{}{"DateOfIncidence": strings.TrimSpace(*edit.DateOfIncidence)})
+	}
+	if edit.Address != nil && strings.TrimSpace(*edit.Address) != report.Address {
+		change("address", map[string]interface{}{"Address": strings.TrimSpace(*edit.Address)})
+	}
+	if edit.Latitude != nil || edit.Longitude != nil {
+		if edit.Latitude == nil || edit.Longitude == nil || math.IsNaN(*edit.Latitude) || math.IsNaN(*edit.Longitude) ||
+			math.Abs(*edit.Latitude) > 90 || math.Abs(*edit.Longitude) > 180 {
+			return nil, fmt.Errorf("%w: a location needs a valid latitude and longitude", ErrInvalidReportEdit)
+		}
+		if *edit.Latitude != report.Latitude || *edit.Longitude != report.Longitude {
+			change("location", map[string]interface{}{
+				"Latitude":            *edit.Latitude,
+				"Longitude":           *edit.Longitude,
+				"PlusCode":            geo.EncodePlusCode(*edit.Latitude, *edit.Longitude, geo.PlusCodeLength),
+				"LocationApproximate": false,
+			})
+		}
+	}
+	if edit.Rating != nil && strings.TrimSpace(*edit.Rating) != report.Rating {
+		change("rating", map[string]interface{}{"Rating": strings.TrimSpace(*edit.Rating)})
+	}
+	if len(changes) == 0 {
+		return nil, fmt.Errorf("%w: the edit changes nothing", ErrInvalidReportEdit)
+	}
+	changes["EditedAt"] = time.Now().Unix()
+
+	revision, err := s.incidentRepo.EditReport(report.ID, userID, changes, fields)
+	switch {
+	case errors.Is(err, db.ErrReportReviewed):
+		return nil, ErrReportNotEditable
+	case errors.Is(err, db.ErrLocationNotEditable):
+		return nil, fmt.Errorf("%w: the location of a report drawn along a route or through several points, or already coarsened, can't be moved", ErrInvalidReportEdit)
+	}
+	return revision, err
+}
+
+// GetReportRevisions returns a report's earlier versions, oldest first
+func (s *IncidentService) GetReportRevisions(reportID string) ([]models.ReportRevision, error) {
+	report, err := s.report(reportID)
+	if err != nil {
+		return nil, err
+	}
+	return s.incidentRepo.GetReportRevisions(report.ID.String())
+}
+
+// DiffReportRevisions returns what changed in a report between two of its
+// versions. The current version is one more than its last revision; to of
+// 0 means the current version and from of 0 the one before to.
+func (s *IncidentService) DiffReportRevisions(reportID string, from, to int) (*models.ReportRevisionDiff, error) {
+	report, err := s.report(reportID)
+	if err != nil {
+		return nil, err
+	}
+	revisions, err := s.incidentRepo.GetReportRevisions(report.ID.String())
+	if err != nil {
+		return nil, err
+	}
+	versions := append(revisions, models.RevisionOf(report))
+	if to == 0 {
+		to = len(versions)
+	}
+	if from == 0 {
+		from = to - 1
+	}
+	if from < 1 || to > len(versions) || from >= to {
+		return nil, ErrRevisionNotFound
+	}
+
+	diff := &models.ReportRevisionDiff{ReportID: report.ID, FromVersion: from, ToVersion: to, Changes: []models.ReportFieldChange{}}
+	before, after := versions[from-1].Fields(), versions[to-1].Fields()
+	for _, field := range reportRevisionFields {
+		if before[field] != after[field] {
+			diff.Changes = append(diff.Changes, models.ReportFieldChange{Field: field, From: before[field], To: after[field]})
+		}
+	}
+	return diff, nil
+}
+
+// reportRevisionFields orders the fields of a diff
+var reportRevisionFields = []string{
+	"description", "date_of_incidence", "address", "latitude", "longitude",
+	"rating", "category", "sub_report_type", "state_name", "lga_name",
+}
+
+// report loads a report, mapping a malformed ID or a missing report to
+// ErrReportNotFound
+func (s *IncidentService) report(reportID string) (*models.IncidentReport, error) {
+	if _, err := uuid.Parse(reportID); err != nil {
+		return nil, ErrReportNotFound
+	}
+	report, err := s.incidentRepo.GetIncidentReportByID(reportID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrReportNotFound
+	}
+	return report, err
+}
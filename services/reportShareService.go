@@ -0,0 +1,48 @@
+package services
+
+import (
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/techagentng/citizenx/geo"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+// ShareReport builds the link and message for sharing a report. Where the
+// report has a plus code the message carries it in its short form with the
+// LGA, which is easier to read out than coordinates.
+func (s *IncidentService) ShareReport(reportID string) (*models.ReportShare, error) {
+	report, err := s.incidentRepo.GetIncidentReportByID(reportID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrReportNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	share := &models.ReportShare{
+		ReportID: reportID,
+		PlusCode: report.PlusCode,
+		Locality: joinNonEmpty(", ", report.LGAName, report.StateName),
+	}
+	// The full link still works when the short one cannot be made
+	if link, err := s.links.ReportLink(reportID, models.ShortLinkShare); err == nil {
+		share.URL = link.URL
+	} else {
+		log.Printf("shortening share link of report %s: %v", reportID, err)
+		share.URL = strings.TrimRight(s.Config.BaseUrl, "/") + "/reports/" + reportID
+	}
+	place := share.Locality
+	if report.PlusCode != "" {
+		share.LocalPlusCode = geo.LocalPlusCode(report.PlusCode)
+		if share.Locality == "" || share.LocalPlusCode == report.PlusCode {
+			place = report.PlusCode
+		} else {
+			place = share.LocalPlusCode + " " + share.Locality
+		}
+	}
+	share.Text = joinNonEmpty(" - ", report.Category, place, share.URL)
+	return share, nil
+}
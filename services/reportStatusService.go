@@ -0,0 +1,80 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrUnknownReportStatus is returned for a status that is not part of
+	// the report lifecycle, or that moderators cannot set.
+	ErrUnknownReportStatus = errors.New("status must be one of pending, under_review, approved, verified, accepted, resolved, rejected or closed")
+	// ErrInvalidStatusTransition is returned when the lifecycle does not
+	// allow a report to move from its status to the one asked for.
+	ErrInvalidStatusTransition = errors.New("the report cannot move to that status from its current one")
+)
+
+// moderatorStatuses are the statuses moderators may move a report to.
+// Reporters withdraw their own reports.
+var moderatorStatuses = map[string]bool{
+	models.ReportStatusPending:     true,
+	models.ReportStatusUnderReview: true,
+	models.ReportStatusApproved:    true,
+	models.ReportStatusVerified:    true,
+	models.ReportStatusAccepted:    true,
+	models.ReportStatusResolved:    true,
+	models.ReportStatusRejected:    true,
+	models.ReportStatusClosed:      true,
+}
+
+// ReportStatusService moves reports through their lifecycle, checking each
+// move is allowed and recording who made it and why
+type ReportStatusService interface {
+	ChangeStatus(reportID string, change *models.ReportStatusChange, actorID uint) (*models.ReportStatusTransition, error)
+	History(reportID string) ([]models.ReportStatusTransition, error)
+}
+
+type reportStatusService struct {
+	Config           *config.Config
+	reportStatusRepo db.ReportStatusRepository
+}
+
+// NewReportStatusService creates a new instance of ReportStatusService
+func NewReportStatusService(reportStatusRepo db.ReportStatusRepository, conf *config.Config) ReportStatusService {
+	return &reportStatusService{
+		Config:           conf,
+		reportStatusRepo: reportStatusRepo,
+	}
+}
+
+// ChangeStatus moves the report to the status asked for. Callers check the
+// actor may review the report.
+func (s *reportStatusService) ChangeStatus(reportID string, change *models.ReportStatusChange, actorID uint) (*models.ReportStatusTransition, error) {
+	id, err := uuid.Parse(reportID)
+	if err != nil {
+		return nil, ErrReportNotFound
+	}
+	status := models.NormalizeReportStatus(change.Status)
+	if !moderatorStatuses[status] {
+		return nil, ErrUnknownReportStatus
+	}
+	transition, err := s.reportStatusRepo.TransitionStatus(id, status, &actorID, strings.TrimSpace(change.Reason))
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, ErrReportNotFound
+	case errors.Is(err, db.ErrInvalidTransition):
+		return nil, ErrInvalidStatusTransition
+	}
+	return transition, err
+}
+
+// History returns the report's status changes, oldest first
+func (s *reportStatusService) History(reportID string) ([]models.ReportStatusTransition, error) {
+	return s.reportStatusRepo.ListTransitions(reportID)
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/pii"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrTransferRecipientRequired is returned when a transfer names no
+	// account to hand the report to.
+	ErrTransferRecipientRequired = errors.New("a user_id, email or telephone is required")
+	// ErrTransferRecipientNotFound is returned when no active account
+	// matches the recipient given.
+	ErrTransferRecipientNotFound = errors.New("no account matches the recipient")
+	// ErrTransferToOwner is returned when transferring a report to the
+	// account that already owns it.
+	ErrTransferToOwner = errors.New("the report already belongs to this account")
+	// ErrTransferRewardsSpent is returned when a report's points are to go
+	// with it but its reporter has already redeemed them.
+	ErrTransferRewardsSpent = errors.New("the reporter has already redeemed this report's points")
+)
+
+// ReportTransferService hands reports to other accounts, keeping a record
+// of every hand-over. Whether the points a report earned go with it is set
+// by report_transfer_rewards, and only on transfers by admins.
+type ReportTransferService interface {
+	Transfer(reportID string, request *models.ReportTransferRequest, actorID uint, byAdmin bool) (*models.ReportTransfer, error)
+	History(reportID string) ([]models.ReportTransfer, error)
+}
+
+type reportTransferService struct {
+	Config             *config.Config
+	reportTransferRepo db.ReportTransferRepository
+}
+
+// NewReportTransferService creates a new instance of ReportTransferService
+func NewReportTransferService(reportTransferRepo db.ReportTransferRepository, conf *config.Config) ReportTransferService {
+	return &reportTransferService{
+		Config:             conf,
+		reportTransferRepo: reportTransferRepo,
+	}
+}
+
+// Transfer hands the report to the account the request names. Callers
+// check the actor may transfer the report. Reporters keep the points when
+// they hand over their own reports, so they can't pass points on to
+// another account.
+func (s *reportTransferService) Transfer(reportID string, request *models.ReportTransferRequest, actorID uint, byAdmin bool) (*models.ReportTransfer, error) {
+	id, err := uuid.Parse(reportID)
+	if err != nil {
+		return nil, ErrReportNotFound
+	}
+	var telephones []string
+	if phone := strings.TrimSpace(request.Telephone); phone != "" {
+		normalized, err := NormalizePhone(phone)
+		if err != nil {
+			return nil, err
+		}
+		telephones = pii.BlindIndexes(phoneVariants(normalized))
+	}
+	email := strings.TrimSpace(request.Email)
+	if request.UserID == 0 && email == "" && len(telephones) == 0 {
+		return nil, ErrTransferRecipientRequired
+	}
+	recipient, err := s.reportTransferRepo.FindRecipient(request.UserID, email, telephones)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrTransferRecipientNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	transfer := &models.ReportTransfer{
+		ReportID:      id,
+		ToUserID:      recipient.ID,
+		TransferredBy: actorID,
+		Reason:        strings.TrimSpace(request.Reason),
+		CreatedAt:     time.Now().Unix(),
+	}
+	moveRewards := byAdmin && s.Config.ReportTransferRewards != models.TransferRewardsToReporter
+	err = s.reportTransferRepo.TransferReport(transfer, moveRewards)
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return nil, ErrReportNotFound
+	case errors.Is(err, db.ErrAlreadyOwner):
+		return nil, ErrTransferToOwner
+	case errors.Is(err, db.ErrRewardsSpent):
+		return nil, ErrTransferRewardsSpent
+	case err != nil:
+		return nil, err
+	}
+	return transfer, nil
+}
+
+func (s *reportTransferService) History(reportID string) ([]models.ReportTransfer, error) {
+	return s.reportTransferRepo.ListTransfers(reportID)
+}
@@ -0,0 +1,197 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+// Privileges gated on reputation
+const (
+	// PrivilegeAutoPublish lets a reporter's reports publish without
+	// waiting for review
+	PrivilegeAutoPublish = "auto_publish"
+)
+
+// tierPrivileges lists what each reputation tier may do beyond the basics
+var tierPrivileges = map[string][]string{
+	models.ReputationTrusted: {PrivilegeAutoPublish},
+}
+
+// Reputation scoring. A reporter is new until about minModeratedReports of
+// their reports have been moderated; the score weighs their verified ratio
+// against how well their votes predicted moderation, and each recent media
+// reuse flag costs flagPenalty points. A verified identity adds
+// identityBonus points and lifts a new reporter to the standard tier.
+const (
+	minModeratedReports = 3
+	reportWeight        = 0.7
+	voteWeight          = 0.3
+	flagPenalty         = 10
+	trustedScore        = 75
+	standardScore       = 40
+	identityBonus       = 10
+)
+
+// ReputationService scores reporters for moderation triage and for the
+// privileges that depend on trust
+type ReputationService interface {
+	Recompute() (int, error)
+	GetReputation(userID uint) (*models.ReporterReputation, error)
+	TriageQueue(page int, flaggedOnly bool) ([]models.TriageReport, error)
+	Allowed(userID uint, privilege string) (bool, error)
+	SetIdentityVerified(userID uint, verified bool) error
+}
+
+type reputationService struct {
+	Config         *config.Config
+	reputationRepo db.ReputationRepository
+}
+
+// NewReputationService creates a new instance of ReputationService
+func NewReputationService(reputationRepo db.ReputationRepository, conf *config.Config) ReputationService {
+	return &reputationService{
+		Config:         conf,
+		reputationRepo: reputationRepo,
+	}
+}
+
+// Recompute rescores every reporter with any moderated history and returns
+// how many were scored. Scores are left as they are while no half-life is
+// configured.
+func (s *reputationService) Recompute() (int, error) {
+	now := time.Now()
+	halfLife := time.Duration(s.Config.ReputationHalfLifeDays) * 24 * time.Hour
+	if halfLife <= 0 {
+		return 0, nil
+	}
+	evidence, err := s.reputationRepo.ReputationEvidence(now, halfLife)
+	if err != nil {
+		return 0, err
+	}
+	reputations := make([]models.ReporterReputation, len(evidence))
+	for i, e := range evidence {
+		reputations[i] = scoreReputation(e, now)
+	}
+	return len(reputations), s.reputationRepo.SaveReputations(reputations)
+}
+
+// scoreReputation turns a reporter's decayed history into a score and tier.
+// Both ratios start from an even prior so a single report cannot swing the
+// score to either end.
+func scoreReputation(e db.ReputationEvidence, now time.Time) models.ReporterReputation {
+	moderated := e.VerifiedReports + e.RejectedReports
+	reportRatio := (e.VerifiedReports + 1) / (moderated + 2)
+	voteAccuracy := (e.AccurateVotes + 1) / (e.AccurateVotes + e.InaccurateVotes + 2)
+	score := 100*(reportWeight*reportRatio+voteWeight*voteAccuracy) - flagPenalty*e.Flags
+	if e.IdentityVerified {
+		score += identityBonus
+	}
+	score = math.Round(math.Max(0, math.Min(100, score))*10) / 10
+
+	tier := models.ReputationLow
+	switch {
+	case moderated < minModeratedReports && e.IdentityVerified:
+		tier = models.ReputationStandard
+	case moderated < minModeratedReports:
+		tier = models.ReputationNew
+	case score >= trustedScore:
+		tier = models.ReputationTrusted
+	case score >= standardScore:
+		tier = models.ReputationStandard
+	}
+
+	return models.ReporterReputation{
+		UserID:           e.UserID,
+		Score:            score,
+		Tier:             tier,
+		VerifiedReports:  e.VerifiedReports,
+		RejectedReports:  e.RejectedReports,
+		Flags:            e.Flags,
+		AccurateVotes:    e.AccurateVotes,
+		InaccurateVotes:  e.InaccurateVotes,
+		IdentityVerified: e.IdentityVerified,
+		ComputedAt:       now.Unix(),
+	}
+}
+
+// GetReputation returns a reporter's last computed reputation. Reporters
+// not scored yet are new with a neutral score.
+func (s *reputationService) GetReputation(userID uint) (*models.ReporterReputation, error) {
+	reputation, err := s.reputationRepo.GetReputation(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &models.ReporterReputation{UserID: userID, Score: 50, Tier: models.ReputationNew}, nil
+	}
+	return reputation, err
+}
+
+// TriageQueue returns reports awaiting moderation with their reporters'
+// reputations, most reputable reporters first. flaggedOnly narrows it to
+// reports sent through VPNs and datacenters.
+func (s *reputationService) TriageQueue(page int, flaggedOnly bool) ([]models.TriageReport, error) {
+	reports, err := s.reputationRepo.PendingReportsByReputation(page, flaggedOnly)
+	if err != nil {
+		return nil, err
+	}
+	userIDs := make([]uint, 0, len(reports))
+	for _, report := range reports {
+		userIDs = append(userIDs, report.UserID)
+	}
+	reputations, err := s.reputationRepo.GetReputations(userIDs)
+	if err != nil {
+		return nil, err
+	}
+	byUser := make(map[uint]*models.ReporterReputation, len(reputations))
+	for i := range reputations {
+		byUser[reputations[i].UserID] = &reputations[i]
+	}
+
+	queue := make([]models.TriageReport, len(reports))
+	for i, report := range reports {
+		queue[i] = models.TriageReport{Report: report, Reputation: byUser[report.UserID], NetworkFlag: report.NetworkFlag}
+	}
+	return queue, nil
+}
+
+// Allowed reports whether the reporter's tier grants privilege and their
+// privileges are not suspended
+func (s *reputationService) Allowed(userID uint, privilege string) (bool, error) {
+	reputation, err := s.GetReputation(userID)
+	if err != nil {
+		return false, err
+	}
+	if reputation.PrivilegesSuspendedUntil > time.Now().Unix() {
+		return false, nil
+	}
+	for _, granted := range tierPrivileges[reputation.Tier] {
+		if granted == privilege {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// SetIdentityVerified rescores a reporter from their stored history when
+// their identity is verified or the verification revoked, rather than
+// waiting for the next recompute
+func (s *reputationService) SetIdentityVerified(userID uint, verified bool) error {
+	current, err := s.GetReputation(userID)
+	if err != nil {
+		return err
+	}
+	reputation := scoreReputation(db.ReputationEvidence{
+		UserID:           userID,
+		VerifiedReports:  current.VerifiedReports,
+		RejectedReports:  current.RejectedReports,
+		Flags:            current.Flags,
+		AccurateVotes:    current.AccurateVotes,
+		InaccurateVotes:  current.InaccurateVotes,
+		IdentityVerified: verified,
+	}, time.Now())
+	return s.reputationRepo.SaveReputations([]models.ReporterReputation{reputation})
+}
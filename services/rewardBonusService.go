@@ -0,0 +1,169 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/techagentng/citizenx/cache"
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/models"
+)
+
+// underReportedShare is how small an LGA's recent report count must be,
+// against the median LGA of its state, for it to count as under-reported
+const underReportedShare = 0.25
+
+// UnderReportedCacheTTL is how long the under-reported LGAs are served from
+// memory; every approved report asks for them
+const UnderReportedCacheTTL = 10 * time.Minute
+
+// RewardBonusService weights the points a report earns when it is approved.
+// reward_category_multipliers raises the points of priority categories and
+// reward_area_multipliers those of chosen LGAs; LGAs with few recent reports
+// earn reward_scarcity_percent unless an area multiplier is set for them.
+type RewardBonusService interface {
+	Multiplier(category, stateName, lgaName string) (float64, error)
+	Bonuses() (*models.RewardBonuses, error)
+}
+
+type rewardBonusService struct {
+	Config            *config.Config
+	rewardBonusRepo   db.RewardBonusRepository
+	categories        map[string]float64
+	areas             map[string]float64
+	underReportedLGAs *cache.TTL[[]models.BonusArea]
+}
+
+// NewRewardBonusService creates a new instance of RewardBonusService
+func NewRewardBonusService(rewardBonusRepo db.RewardBonusRepository, conf *config.Config) RewardBonusService {
+	return &rewardBonusService{
+		Config:            conf,
+		rewardBonusRepo:   rewardBonusRepo,
+		categories:        parseMultipliers(conf.RewardCategoryMultipliers),
+		areas:             parseMultipliers(conf.RewardAreaMultipliers),
+		underReportedLGAs: cache.New[[]models.BonusArea](UnderReportedCacheTTL),
+	}
+}
+
+// parseMultipliers reads "name=multiplier" pairs separated by commas,
+// skipping any that are malformed or would not raise points. Areas are named
+// "state/lga".
+func parseMultipliers(setting string) map[string]float64 {
+	multipliers := map[string]float64{}
+	for _, pair := range strings.Split(setting, ",") {
+		name, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		m, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		name = strings.ToLower(strings.TrimSpace(name))
+		if err != nil || math.IsNaN(m) || m <= 1 || name == "" {
+			continue
+		}
+		multipliers[name] = m
+	}
+	return multipliers
+}
+
+func areaKey(stateName, lgaName string) string {
+	return strings.ToLower(strings.TrimSpace(stateName)) + "/" + strings.ToLower(strings.TrimSpace(lgaName))
+}
+
+// Multiplier returns what a report's points are multiplied by: its
+// category's multiplier times its area's, capped at reward_max_percent. It
+// is 1 for reports with no bonus.
+func (s *rewardBonusService) Multiplier(category, stateName, lgaName string) (float64, error) {
+	multiplier := 1.0
+	if m, ok := s.categories[strings.ToLower(strings.TrimSpace(category))]; ok {
+		multiplier *= m
+	}
+	if m, ok := s.areas[areaKey(stateName, lgaName)]; ok {
+		multiplier *= m
+	} else if lgaName != "" {
+		areas, err := s.underReported()
+		if err != nil {
+			return 0, err
+		}
+		for _, area := range areas {
+			if areaKey(area.StateName, area.LGAName) == areaKey(stateName, lgaName) {
+				multiplier *= s.scarcityMultiplier()
+				break
+			}
+		}
+	}
+	return s.capped(multiplier), nil
+}
+
+func (s *rewardBonusService) scarcityMultiplier() float64 {
+	return float64(s.Config.RewardScarcityPercent) / 100
+}
+
+func (s *rewardBonusService) maxMultiplier() float64 {
+	return float64(s.Config.RewardMaxPercent) / 100
+}
+
+func (s *rewardBonusService) capped(multiplier float64) float64 {
+	if limit := s.maxMultiplier(); limit >= 1 && multiplier > limit {
+		return limit
+	}
+	return multiplier
+}
+
+// underReported returns the under-reported LGAs, none when the scarcity
+// bonus is off. Only LGAs known in their state are returned, so a report
+// naming an LGA of its own never earns the bonus.
+func (s *rewardBonusService) underReported() ([]models.BonusArea, error) {
+	if s.Config.RewardScarcityPercent <= 100 || s.Config.RewardScarcityDays <= 0 {
+		return nil, nil
+	}
+	return s.underReportedLGAs.GetOrLoad("current", func() ([]models.BonusArea, error) {
+		since := time.Now().AddDate(0, 0, -s.Config.RewardScarcityDays)
+		return s.rewardBonusRepo.UnderReportedAreas(since, underReportedShare)
+	})
+}
+
+// Bonuses lists the categories and areas that earn bonus points now, so
+// users can see where their reports count for more
+func (s *rewardBonusService) Bonuses() (*models.RewardBonuses, error) {
+	bonuses := &models.RewardBonuses{
+		Categories:    []models.BonusCategory{},
+		Areas:         []models.BonusArea{},
+		MaxMultiplier: s.maxMultiplier(),
+	}
+	for category, m := range s.categories {
+		bonuses.Categories = append(bonuses.Categories, models.BonusCategory{Category: category, Multiplier: s.capped(m)})
+	}
+	sort.Slice(bonuses.Categories, func(i, j int) bool {
+		return bonuses.Categories[i].Category < bonuses.Categories[j].Category
+	})
+
+	for key, m := range s.areas {
+		stateName, lgaName, _ := strings.Cut(key, "/")
+		bonuses.Areas = append(bonuses.Areas, models.BonusArea{
+			StateName:  stateName,
+			LGAName:    lgaName,
+			Multiplier: s.capped(m),
+			Reason:     models.BonusReasonConfigured,
+		})
+	}
+	underReported, err := s.underReported()
+	if err != nil {
+		return nil, err
+	}
+	for _, area := range underReported {
+		if _, ok := s.areas[areaKey(area.StateName, area.LGAName)]; ok {
+			continue
+		}
+		area.Multiplier = s.capped(s.scarcityMultiplier())
+		area.Reason = models.BonusReasonUnderReported
+		bonuses.Areas = append(bonuses.Areas, area)
+	}
+	sort.SliceStable(bonuses.Areas, func(i, j int) bool {
+		return areaKey(bonuses.Areas[i].StateName, bonuses.Areas[i].LGAName) < areaKey(bonuses.Areas[j].StateName, bonuses.Areas[j].LGAName)
+	})
+	return bonuses, nil
+}
@@ -2,35 +2,79 @@ package services
 
 import (
 	"fmt"
+	"log"
+	"math"
+	"time"
+
 	"github.com/techagentng/citizenx/config"
 	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/events"
 	"github.com/techagentng/citizenx/models"
 )
 
 type RewardService interface {
-	ApproveReportPoints(reportID string, userID uint) error
-	RejectReportPoints(reportID string, userID uint) error
-	AcceptReportPoints(reportID string, userID uint) error
+	ApproveReportPoints(reportID string, userID, moderatorID uint) error
+	RejectReportPoints(reportID string, userID, moderatorID uint) error
+	AcceptReportPoints(reportID string, userID, moderatorID uint) error
 	SaveReward(reward *models.Reward) error
 	GetAllRewardsBalanceCount() (int, error)
 	GetAllRewards() ([]models.Reward, error)
 }
 
 type rewardService struct {
-	Config       *config.Config
-	rewardRepo   db.RewardRepository
-	incidentRepo db.IncidentReportRepository
+	Config           *config.Config
+	rewardRepo       db.RewardRepository
+	incidentRepo     db.IncidentReportRepository
+	moderationRepo   db.ModerationRepository
+	collaboratorRepo db.CollaboratorRepository
+	reportStatusRepo db.ReportStatusRepository
+	bonuses          RewardBonusService
 }
 
-func NewRewardService(rewardRepo db.RewardRepository, incidentRepo db.IncidentReportRepository, conf *config.Config) RewardService {
+func NewRewardService(rewardRepo db.RewardRepository, incidentRepo db.IncidentReportRepository, moderationRepo db.ModerationRepository, collaboratorRepo db.CollaboratorRepository, reportStatusRepo db.ReportStatusRepository, bonuses RewardBonusService, conf *config.Config) RewardService {
 	return &rewardService{
-		Config:       conf,
-		rewardRepo:   rewardRepo,
-		incidentRepo: incidentRepo,
+		Config:           conf,
+		rewardRepo:       rewardRepo,
+		incidentRepo:     incidentRepo,
+		moderationRepo:   moderationRepo,
+		collaboratorRepo: collaboratorRepo,
+		reportStatusRepo: reportStatusRepo,
+		bonuses:          bonuses,
+	}
+}
+
+// recordDecision logs the moderator's decision on a report for moderator
+// analytics. The status change already stands, so a failure here is only
+// logged.
+func (s *rewardService) recordDecision(report *models.IncidentReport, previousStatus string, moderatorID uint) {
+	decision := &models.ModerationDecision{
+		ReportID:       report.ID,
+		ModeratorID:    moderatorID,
+		Decision:       report.ReportStatus,
+		PreviousStatus: previousStatus,
+		DecidedAt:      report.StatusUpdatedAt,
+	}
+	if report.CreatedAt > 0 && report.StatusUpdatedAt > report.CreatedAt {
+		decision.ReviewSeconds = report.StatusUpdatedAt - report.CreatedAt
+	}
+	if err := s.moderationRepo.RecordDecision(decision); err != nil {
+		log.Printf("recording moderation decision on report %s: %v", report.ID, err)
+	}
+}
+
+// moveReport moves the report to status through its lifecycle, which
+// refuses moves it does not allow and records the moderator who made it
+func (s *rewardService) moveReport(report *models.IncidentReport, status string, moderatorID uint) (*models.ReportStatusTransition, error) {
+	transition, err := s.reportStatusRepo.TransitionStatus(report.ID, status, &moderatorID, "")
+	if err != nil {
+		return nil, fmt.Errorf("error updating report status: %w", err)
 	}
+	report.ReportStatus = status
+	report.StatusUpdatedAt = time.Now().Unix()
+	return transition, nil
 }
 
-func (s *rewardService) ApproveReportPoints(reportID string, userID uint) error {
+func (s *rewardService) ApproveReportPoints(reportID string, userID, moderatorID uint) error {
 	report, err := s.incidentRepo.GetReportByID(reportID)
 	var reward models.Reward
 	if err != nil {
@@ -42,62 +86,96 @@ func (s *rewardService) ApproveReportPoints(reportID string, userID uint) error
 	if err != nil {
 		return err
 	}
-	// Update reward balance with the points value
-	report.ReportStatus = "approved"
-
-	// Call UpdateIncidentReport and handle the error
-	if err := s.incidentRepo.UpdateIncidentReport(report); err != nil {
-		return fmt.Errorf("error updating report status: %v", err)
+	// Priority categories and under-reported areas earn more, weighed as
+	// they stand at approval
+	multiplier, err := s.bonuses.Multiplier(report.Category, report.StateName, report.LGAName)
+	if err != nil {
+		return fmt.Errorf("error weighing reward bonus: %v", err)
+	}
+	points = int(math.Round(float64(points) * multiplier))
+	// Collaborators receive the shares of the points the reporter gave them
+	collaborators, err := s.collaboratorRepo.Collaborators(reportID)
+	if err != nil {
+		return fmt.Errorf("error fetching collaborators: %v", err)
 	}
+	split := splitPoints(points, userID, collaborators)
 
-	newBalance := reward.Balance + points
-	reward = models.Reward{
-		Model:            models.Model{},
+	now := time.Now()
+	rewards := []models.Reward{{
 		IncidentReportID: reportID,
 		UserID:           userID,
 		RewardType:       "Another Entry",
-		Point:            points,
-		Balance:          newBalance,
-		AccountNumber:    "",
+		Point:            split[userID],
+		Balance:          reward.Balance + split[userID],
+	}}
+	evts := []events.Event{events.RewardEarned{
+		UserID:     userID,
+		ReportID:   reportID,
+		RewardType: "Another Entry",
+		Points:     split[userID],
+		OccurredAt: now,
+	}}
+	for _, collaborator := range collaborators {
+		share := split[collaborator.UserID]
+		if share == 0 {
+			continue
+		}
+		rewards = append(rewards, models.Reward{
+			IncidentReportID: reportID,
+			UserID:           collaborator.UserID,
+			RewardType:       "Collaboration",
+			Point:            share,
+			Balance:          share,
+		})
+		evts = append(evts, events.RewardEarned{
+			UserID:     collaborator.UserID,
+			ReportID:   reportID,
+			RewardType: "Collaboration",
+			Points:     share,
+			OccurredAt: now,
+		})
 	}
 
-	if err := s.rewardRepo.SaveReward(&reward); err != nil {
-		return fmt.Errorf("error saving reward: %v", err)
+	// The approval, the rewards and their events are saved together
+	previousStatus := report.ReportStatus
+	if _, err := s.rewardRepo.ApproveReport(report.ID, userID, moderatorID, rewards, evts...); err != nil {
+		return fmt.Errorf("error approving report: %w", err)
 	}
+	report.ReportStatus = models.ReportStatusApproved
+	report.StatusUpdatedAt = now.Unix()
+	s.recordDecision(report, previousStatus, moderatorID)
 
 	return nil
 }
 
-func (s *rewardService) RejectReportPoints(reportID string, userID uint) error {
+func (s *rewardService) RejectReportPoints(reportID string, userID, moderatorID uint) error {
 	report, err := s.incidentRepo.GetReportByID(reportID)
 	if err != nil {
 		return fmt.Errorf("error fetching report: %v", err)
 	}
 
 	// Update reward balance with the points value
-	report.ReportStatus = "rejected"
-
-	// Call UpdateIncidentReport and check for errors
-	if err := s.incidentRepo.UpdateIncidentReport(report); err != nil {
-		return fmt.Errorf("error updating report status: %v", err)
+	previousStatus := report.ReportStatus
+	if _, err := s.moveReport(report, models.ReportStatusRejected, moderatorID); err != nil {
+		return err
 	}
+	s.recordDecision(report, previousStatus, moderatorID)
 
 	return nil
 }
 
-func (s *rewardService) AcceptReportPoints(reportID string, userID uint) error {
+func (s *rewardService) AcceptReportPoints(reportID string, userID, moderatorID uint) error {
 	report, err := s.incidentRepo.GetReportByID(reportID)
 	if err != nil {
 		return fmt.Errorf("error fetching report: %v", err)
 	}
 
 	// Update reward balance with the points value
-	report.ReportStatus = "accepted"
-
-	// Call UpdateIncidentReport and handle the error properly
-	if err := s.incidentRepo.UpdateIncidentReport(report); err != nil {
-		return fmt.Errorf("error updating report status: %v", err)
+	previousStatus := report.ReportStatus
+	if _, err := s.moveReport(report, models.ReportStatusAccepted, moderatorID); err != nil {
+		return err
 	}
+	s.recordDecision(report, previousStatus, moderatorID)
 
 	return nil
 }
@@ -0,0 +1,185 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/locale"
+	"github.com/techagentng/citizenx/models"
+)
+
+// RewardStatementService writes users' monthly statements of points earned,
+// redeemed and expired, and expires points left unspent too long
+type RewardStatementService interface {
+	Statement(user *models.User, month string) (*models.RewardStatement, error)
+	StatementPDF(statement *models.RewardStatement, format locale.Formatter) ([]byte, error)
+	ExpirePoints() (int64, error)
+}
+
+type rewardStatementService struct {
+	Config     *config.Config
+	ledgerRepo db.PointLedgerRepository
+}
+
+// NewRewardStatementService creates a new instance of RewardStatementService
+func NewRewardStatementService(ledgerRepo db.PointLedgerRepository, conf *config.Config) RewardStatementService {
+	return &rewardStatementService{
+		Config:     conf,
+		ledgerRepo: ledgerRepo,
+	}
+}
+
+// Statement returns the user's statement for a month written YYYY-MM, the
+// current month when empty, with a running balance on every line
+func (s *rewardStatementService) Statement(user *models.User, month string) (*models.RewardStatement, error) {
+	start := time.Now().In(locale.TimeZone)
+	if month != "" {
+		parsed, err := time.ParseInLocation("2006-01", month, locale.TimeZone)
+		if err != nil {
+			return nil, ErrInvalidMonth
+		}
+		start = parsed
+	}
+	start = time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, locale.TimeZone)
+	end := start.AddDate(0, 1, 0)
+
+	opening, err := s.ledgerRepo.BalanceBefore(user.ID, start.Unix())
+	if err != nil {
+		return nil, err
+	}
+	lines, err := s.ledgerRepo.Entries(user.ID, start.Unix(), end.Unix())
+	if err != nil {
+		return nil, err
+	}
+	statement := &models.RewardStatement{
+		UserID:         user.ID,
+		Fullname:       user.Fullname,
+		Month:          start.Format("2006-01"),
+		OpeningBalance: opening,
+		Lines:          lines,
+	}
+	balance := opening
+	for i := range statement.Lines {
+		line := &statement.Lines[i]
+		balance += line.Points
+		line.Balance = balance
+		switch line.Kind {
+		case models.PointsEarned:
+			statement.Earned += line.Points
+		case models.PointsRedeemed:
+			statement.Redeemed -= line.Points
+		case models.PointsExpired:
+			statement.Expired -= line.Points
+		}
+	}
+	if statement.Lines == nil {
+		statement.Lines = []models.RewardStatementLine{}
+	}
+	statement.ClosingBalance = balance
+	statement.ClosingValueKobo = balance * s.Config.PointValueKobo
+	return statement, nil
+}
+
+// StatementPDF renders a statement on A4 pages, with dates and amounts
+// written for the reader's locale
+func (s *rewardStatementService) StatementPDF(statement *models.RewardStatement, format locale.Formatter) ([]byte, error) {
+	pdf := fpdf.New("P", "mm", "A4", "")
+	pdf.SetTitle("CitizenX reward statement "+statement.Month, true)
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 15)
+	pdf.AddPage()
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pageWidth, _ := pdf.GetPageSize()
+	contentWidth := pageWidth - 30
+	month, _ := time.ParseInLocation("2006-01", statement.Month, locale.TimeZone)
+
+	pdf.SetFont("Helvetica", "B", 18)
+	pdf.CellFormat(contentWidth, 10, "CitizenX Reward Statement", "", 1, "L", false, 0, "")
+	pdf.SetFont("Helvetica", "", 9)
+	pdf.SetTextColor(100, 100, 100)
+	pdf.CellFormat(contentWidth, 5, tr(fmt.Sprintf("%s - %s to %s - issued %s", statement.Fullname,
+		format.Date(month), format.Date(month.AddDate(0, 1, -1)), format.DateTime(time.Now()))), "", 1, "L", false, 0, "")
+	pdf.SetTextColor(0, 0, 0)
+	pdf.Ln(4)
+
+	for _, field := range [][2]string{
+		{"Opening balance", format.Number(statement.OpeningBalance)},
+		{"Points earned", format.Number(statement.Earned)},
+		{"Points redeemed", format.Number(statement.Redeemed)},
+		{"Points expired", format.Number(statement.Expired)},
+		{"Closing balance", format.Number(statement.ClosingBalance)},
+		{"Closing value", format.Currency(statement.ClosingValueKobo)},
+	} {
+		pdf.SetFont("Helvetica", "B", 10)
+		pdf.CellFormat(50, 6, field[0], "", 0, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.CellFormat(contentWidth-50, 6, tr(field[1]), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	widths := []float64{28, 22, contentWidth - 28 - 22 - 25 - 25, 25, 25}
+	header := func() {
+		pdf.SetFont("Helvetica", "B", 9)
+		for i, title := range []string{"Date", "Type", "Description", "Points", "Balance"} {
+			align := "L"
+			if i >= 3 {
+				align = "R"
+			}
+			pdf.CellFormat(widths[i], 7, title, "B", 0, align, false, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont("Helvetica", "", 9)
+	}
+	pdf.SetHeaderFunc(func() {
+		if pdf.PageNo() > 1 {
+			header()
+		}
+	})
+	header()
+	if len(statement.Lines) == 0 {
+		pdf.CellFormat(contentWidth, 7, "No points were earned, redeemed or expired this month.", "", 1, "L", false, 0, "")
+	}
+	for _, line := range statement.Lines {
+		description := line.Description
+		if line.Reference != "" {
+			description += " (" + line.Reference + ")"
+		}
+		cells := []string{format.Unix(line.At), line.Kind, description, format.Number(line.Points), format.Number(line.Balance)}
+		for i, cell := range cells {
+			align := "L"
+			if i >= 3 {
+				align = "R"
+			}
+			text := tr(cell)
+			// Descriptions are cut to their column rather than wrapped
+			for pdf.GetStringWidth(text) > widths[i]-1 && len(text) > 1 {
+				text = text[:len(text)-1]
+			}
+			pdf.CellFormat(widths[i], 6, text, "", 0, align, false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("rendering reward statement: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ExpirePoints expires points earned more than points_expire_months ago
+// and still unspent, returning how many users lost points. Points never
+// expire while it is 0.
+func (s *rewardStatementService) ExpirePoints() (int64, error) {
+	if s.Config.PointsExpireMonths <= 0 {
+		return 0, nil
+	}
+	now := time.Now()
+	cutoff := now.AddDate(0, -s.Config.PointsExpireMonths, 0)
+	description := fmt.Sprintf("Points earned before %s expired", cutoff.In(locale.TimeZone).Format("2006-01-02"))
+	return s.ledgerRepo.ExpireEarnedBefore(cutoff.Unix(), now.Unix(), description)
+}
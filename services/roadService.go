@@ -0,0 +1,227 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/events"
+	"github.com/techagentng/citizenx/geo"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrRoadSegmentNotFound is returned for road segments that do not exist.
+	ErrRoadSegmentNotFound = errors.New("road segment not found")
+	// ErrInvalidRoute is returned for routes and segment lines that cannot be used.
+	ErrInvalidRoute = errors.New("invalid route")
+)
+
+const (
+	// MaxRoutePoints bounds the vertices of a reported route or segment line
+	MaxRoutePoints = 1000
+	// routeSampleMetres is the spacing at which a route is checked against
+	// the segments around it
+	routeSampleMetres = 25
+	// minSegmentOverlapMetres is how far a route must run along a segment
+	// to count against it, so a route crossing a road is not reported on it
+	minSegmentOverlapMetres = 50
+	// defaultConditionDays is the window of reports segment conditions
+	// cover when none is given
+	defaultConditionDays = 90
+)
+
+// ParseRoute decodes a route given as an encoded polyline and checks that
+// it is a usable line.
+func ParseRoute(encoded string) ([]geo.Point, error) {
+	points, err := geo.DecodePolyline(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRoute, err)
+	}
+	if len(points) < 2 || len(points) > MaxRoutePoints {
+		return nil, fmt.Errorf("%w: a route needs between 2 and %d points", ErrInvalidRoute, MaxRoutePoints)
+	}
+	return points, nil
+}
+
+// RoadService places road-condition reports on the road segments they
+// describe and aggregates them per segment
+type RoadService interface {
+	CreateSegment(segment *models.RoadSegment, adminID uint) error
+	ListSegments(stateName, road string, page int) ([]models.RoadSegment, error)
+	SegmentConditions(stateName, road string, days int) ([]models.RoadSegmentCondition, error)
+	SegmentReports(segmentID uint, page int) ([]models.IncidentReport, error)
+	Subscribe(bus events.Bus)
+}
+
+type roadService struct {
+	Config     *config.Config
+	roadRepo   db.RoadRepository
+	categories map[string]bool
+}
+
+// NewRoadService creates a new instance of RoadService. Reports in the
+// categories listed in road_categories are placed on segments.
+func NewRoadService(roadRepo db.RoadRepository, conf *config.Config) RoadService {
+	categories := map[string]bool{}
+	for _, category := range strings.Split(conf.RoadCategories, ",") {
+		if category = strings.TrimSpace(category); category != "" {
+			categories[strings.ToLower(category)] = true
+		}
+	}
+	return &roadService{
+		Config:     conf,
+		roadRepo:   roadRepo,
+		categories: categories,
+	}
+}
+
+// CreateSegment adds a segment and links the road reports already submitted
+// along it
+func (s *roadService) CreateSegment(segment *models.RoadSegment, adminID uint) error {
+	line, err := ParseRoute(segment.Polyline)
+	if err != nil {
+		return err
+	}
+	segment.ID = 0
+	segment.Road = strings.TrimSpace(segment.Road)
+	segment.Name = strings.TrimSpace(segment.Name)
+	segment.Polyline = geo.EncodePolyline(line)
+	segment.LengthM = int(math.Round(geo.PolylineLength(line)))
+	segment.South, segment.West, segment.North, segment.East = geo.Bounds(line)
+	segment.CreatedBy = adminID
+	segment.CreatedAt = time.Now().Unix()
+	if err := s.roadRepo.CreateSegment(segment); err != nil {
+		return err
+	}
+
+	categories := make([]string, 0, len(s.categories))
+	for category := range s.categories {
+		categories = append(categories, category)
+	}
+	south, west, north, east := s.widen(segment.South, segment.West, segment.North, segment.East)
+	reports, err := s.roadRepo.RoadReportsInBox(categories, south, west, north, east)
+	if err != nil {
+		return fmt.Errorf("finding reports along segment: %w", err)
+	}
+	segments := []models.RoadSegment{*segment}
+	for _, report := range reports {
+		if matched := s.match(report, segments); len(matched) > 0 {
+			if err := s.roadRepo.LinkReportSegments(report.ID, matched, segment.CreatedAt); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *roadService) ListSegments(stateName, road string, page int) ([]models.RoadSegment, error) {
+	return s.roadRepo.ListSegments(stateName, road, page)
+}
+
+// SegmentConditions aggregates the reports of the last days on every
+// segment
+func (s *roadService) SegmentConditions(stateName, road string, days int) ([]models.RoadSegmentCondition, error) {
+	if days <= 0 {
+		days = defaultConditionDays
+	}
+	return s.roadRepo.SegmentConditions(stateName, road, time.Now().AddDate(0, 0, -days).Unix())
+}
+
+func (s *roadService) SegmentReports(segmentID uint, page int) ([]models.IncidentReport, error) {
+	if _, err := s.roadRepo.GetSegment(segmentID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRoadSegmentNotFound
+		}
+		return nil, err
+	}
+	return s.roadRepo.SegmentReports(segmentID, page)
+}
+
+// Subscribe places each new road report on its segments
+func (s *roadService) Subscribe(bus events.Bus) {
+	bus.Subscribe(events.ReportCreatedEvent, s.handleReportCreated)
+}
+
+func (s *roadService) handleReportCreated(ctx context.Context, event events.Event) error {
+	e, ok := event.(events.ReportCreated)
+	if !ok || !s.categories[strings.ToLower(e.Category)] {
+		return nil
+	}
+	report, err := s.roadRepo.GetReportRoute(e.ReportID.String())
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	points := []geo.Point{{Latitude: report.Latitude, Longitude: report.Longitude}}
+	if route, err := geo.DecodePolyline(report.Route); err == nil && len(route) > 1 {
+		points = route
+	} else if report.Latitude == 0 && report.Longitude == 0 {
+		return nil
+	}
+	segments, err := s.roadRepo.SegmentsInBox(s.widen(geo.Bounds(points)))
+	if err != nil {
+		return err
+	}
+	matched := s.match(*report, segments)
+	if len(matched) == 0 {
+		return nil
+	}
+	if err := s.roadRepo.LinkReportSegments(report.ID, matched, time.Now().Unix()); err != nil {
+		return err
+	}
+	log.Printf("placed report %s on %d road segments", report.ID, len(matched))
+	return nil
+}
+
+// match returns the segments a report lies on. A report with a route must
+// run along a segment for some distance; otherwise its position must be on
+// the segment's line.
+func (s *roadService) match(report models.IncidentReport, segments []models.RoadSegment) []uint {
+	tolerance := float64(s.Config.RoadSegmentToleranceMeters)
+	samples := []geo.Point{{Latitude: report.Latitude, Longitude: report.Longitude}}
+	needed := 1
+	if route, err := geo.DecodePolyline(report.Route); err == nil && len(route) > 1 {
+		samples = geo.SamplePolyline(route, routeSampleMetres)
+		needed = minSegmentOverlapMetres / routeSampleMetres
+		if half := (len(samples) + 1) / 2; half < needed {
+			needed = half
+		}
+	}
+
+	var matched []uint
+	for _, segment := range segments {
+		line, err := geo.DecodePolyline(segment.Polyline)
+		if err != nil {
+			log.Printf("road segment %d has an unreadable line: %v", segment.ID, err)
+			continue
+		}
+		near := 0
+		for _, p := range samples {
+			if geo.DistanceToPolyline(p, line) <= tolerance {
+				near++
+			}
+		}
+		if near >= needed {
+			matched = append(matched, segment.ID)
+		}
+	}
+	return matched
+}
+
+// widen grows a bounding box by the matching tolerance
+func (s *roadService) widen(south, west, north, east float64) (float64, float64, float64, float64) {
+	dLat := float64(s.Config.RoadSegmentToleranceMeters) / 111000
+	dLng := dLat / math.Max(0.01, math.Cos((south+north)/2*math.Pi/180))
+	return south - dLat, west - dLng, north + dLat, east + dLng
+}
@@ -0,0 +1,47 @@
+package services
+
+import (
+	"context"
+	"log"
+
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/search"
+)
+
+// SearchService finds incident reports by keyword
+type SearchService interface {
+	SearchReports(ctx context.Context, query string, filters db.ReportFilter, page int, sort db.ReportSort) ([]models.IncidentReport, error)
+}
+
+type searchService struct {
+	Config       *config.Config
+	incidentRepo db.IncidentReportRepository
+	index        *search.Client
+}
+
+// NewSearchService creates a search service. Queries go to index when it is
+// non-nil and to Postgres otherwise.
+func NewSearchService(incidentRepo db.IncidentReportRepository, index *search.Client, conf *config.Config) SearchService {
+	return &searchService{
+		Config:       conf,
+		incidentRepo: incidentRepo,
+		index:        index,
+	}
+}
+
+// SearchReports ranks matches in OpenSearch and loads them from Postgres, so
+// results always reflect the current rows. If the cluster is unreachable the
+// search is answered by Postgres instead. OpenSearch ranks by relevance, so
+// a search with a sort or cursor is answered by Postgres as well.
+func (s *searchService) SearchReports(ctx context.Context, query string, filters db.ReportFilter, page int, sort db.ReportSort) ([]models.IncidentReport, error) {
+	if s.index != nil && sort.Order == "" && sort.After == nil {
+		ids, err := s.index.Search(ctx, query, filters, page, db.DefaultPageSize)
+		if err == nil {
+			return s.incidentRepo.GetReportsByIDs(ids, db.ReportCard()...)
+		}
+		log.Printf("search: falling back to postgres: %v", err)
+	}
+	return s.incidentRepo.SearchReports(query, filters, page, sort, db.ReportCard()...)
+}
@@ -0,0 +1,252 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/services/jwt"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrSessionExpired is returned for sessions that were idle too long,
+	// reached their absolute lifetime, or were signed out.
+	ErrSessionExpired = errors.New("your session has expired; sign in again")
+	// ErrStepUpRequired is returned when a sensitive operation is attempted
+	// without a valid step-up token.
+	ErrStepUpRequired = errors.New("confirm your password to continue")
+	// ErrStepUpWrongPassword is returned when step-up is attempted with the
+	// wrong password.
+	ErrStepUpWrongPassword = errors.New("the password is wrong")
+	// ErrStepUpNoPassword is returned for accounts without a password, which
+	// step up by signing in again.
+	ErrStepUpNoPassword = errors.New("this account has no password; sign in again to continue")
+)
+
+// touchEvery limits how often activity is written to a session
+const touchEvery = time.Minute
+
+// SessionService issues and enforces sign-in sessions. A session ends after
+// session_idle_minutes without activity or session_max_hours after sign-in,
+// whichever comes first; each authenticated request slides the idle window.
+type SessionService interface {
+	Start(user *models.User) (*models.LoginResponse, error)
+	Refresh(refreshToken string) (*models.LoginResponse, error)
+	Touch(sessionID string, userID uint) error
+	StepUp(userID uint, sessionID, password string) (*models.StepUpResponse, error)
+	CheckStepUp(token string, userID uint, sessionID string) error
+	End(sessionID string) error
+	EndOthers(userID uint, sessionID string) error
+}
+
+type sessionService struct {
+	Config      *config.Config
+	sessionRepo db.SessionRepository
+	authRepo    db.AuthRepository
+}
+
+// NewSessionService creates a new instance of SessionService
+func NewSessionService(sessionRepo db.SessionRepository, authRepo db.AuthRepository, conf *config.Config) SessionService {
+	return &sessionService{
+		Config:      conf,
+		sessionRepo: sessionRepo,
+		authRepo:    authRepo,
+	}
+}
+
+// Start opens a session for a user who has just proven who they are, with
+// a step-up token since they just did
+func (s *sessionService) Start(user *models.User) (*models.LoginResponse, error) {
+	// Signing in is how a deactivated account comes back
+	reactivated := false
+	if user.DeactivatedAt != nil {
+		var err error
+		if reactivated, err = s.authRepo.ReactivateUser(user.ID); err != nil {
+			return nil, err
+		}
+		user.DeactivatedAt = nil
+	}
+
+	now := time.Now()
+	session := &models.Session{
+		ID:         uuid.NewString(),
+		UserID:     user.ID,
+		RefreshID:  uuid.NewString(),
+		CreatedAt:  now.Unix(),
+		LastSeenAt: now.Unix(),
+		ExpiresAt:  now.Add(time.Duration(s.Config.SessionMaxHours) * time.Hour).Unix(),
+	}
+	if err := s.sessionRepo.CreateSession(session); err != nil {
+		return nil, err
+	}
+	login, err := s.issue(user, session)
+	if err != nil {
+		return nil, err
+	}
+	login.StepUpToken, err = jwt.GenerateStepUpToken(user.ID, session.ID, s.Config.JWTSecret, s.stepUpTTL())
+	if err != nil {
+		return nil, err
+	}
+	login.Reactivated = reactivated
+	return login, nil
+}
+
+// Refresh renews a live session's tokens. Each refresh token works once; a
+// used one coming back means it was copied, so the session is ended.
+func (s *sessionService) Refresh(refreshToken string) (*models.LoginResponse, error) {
+	claims, err := jwt.ValidateAndGetClaims(refreshToken, s.Config.JWTSecret)
+	if err != nil {
+		return nil, ErrSessionExpired
+	}
+	sessionID, _ := claims["sid"].(string)
+	refreshID, _ := claims["rti"].(string)
+	if claims["type"] != "refresh_token" || sessionID == "" || refreshID == "" {
+		return nil, ErrSessionExpired
+	}
+	session, err := s.live(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	next := uuid.NewString()
+	now := time.Now().Unix()
+	rotated, err := s.sessionRepo.RotateRefresh(session.ID, refreshID, next, now)
+	if err != nil {
+		return nil, err
+	}
+	if !rotated {
+		log.Printf("refresh token of session %s reused; ending session", session.ID)
+		if err := s.sessionRepo.RevokeSession(session.ID, now); err != nil {
+			return nil, err
+		}
+		return nil, ErrSessionExpired
+	}
+	session.RefreshID = next
+
+	user, err := s.authRepo.FindUserByID(session.UserID)
+	if err != nil {
+		return nil, ErrSessionExpired
+	}
+	return s.issue(user, session)
+}
+
+// Touch checks the session behind an access token is live and records the
+// activity
+func (s *sessionService) Touch(sessionID string, userID uint) error {
+	if sessionID == "" {
+		return ErrSessionExpired
+	}
+	session, err := s.live(sessionID)
+	if err != nil {
+		return err
+	}
+	if session.UserID != userID {
+		return ErrSessionExpired
+	}
+	now := time.Now().Unix()
+	if now-session.LastSeenAt < int64(touchEvery.Seconds()) {
+		return nil
+	}
+	return s.sessionRepo.TouchSession(session.ID, now)
+}
+
+// StepUp confirms the user's password and returns a step-up token for
+// their session
+func (s *sessionService) StepUp(userID uint, sessionID, password string) (*models.StepUpResponse, error) {
+	user, err := s.authRepo.FindUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.HashedPassword == "" {
+		return nil, ErrStepUpNoPassword
+	}
+	if err := user.VerifyPassword(password); err != nil {
+		return nil, ErrStepUpWrongPassword
+	}
+	token, err := jwt.GenerateStepUpToken(userID, sessionID, s.Config.JWTSecret, s.stepUpTTL())
+	if err != nil {
+		return nil, err
+	}
+	return &models.StepUpResponse{StepUpToken: token, ExpiresIn: int(s.stepUpTTL().Seconds())}, nil
+}
+
+// CheckStepUp reports whether token is a current step-up token for the
+// user's session
+func (s *sessionService) CheckStepUp(token string, userID uint, sessionID string) error {
+	if token == "" {
+		return ErrStepUpRequired
+	}
+	claims, err := jwt.ValidateAndGetClaims(token, s.Config.JWTSecret)
+	if err != nil {
+		return ErrStepUpRequired
+	}
+	id, _ := claims["id"].(float64)
+	if claims["type"] != "step_up" || uint(id) != userID || claims["sid"] != sessionID {
+		return ErrStepUpRequired
+	}
+	return nil
+}
+
+// End signs a session out
+func (s *sessionService) End(sessionID string) error {
+	return s.sessionRepo.RevokeSession(sessionID, time.Now().Unix())
+}
+
+// EndOthers signs the user out everywhere but sessionID
+func (s *sessionService) EndOthers(userID uint, sessionID string) error {
+	return s.sessionRepo.RevokeUserSessions(userID, sessionID, time.Now().Unix())
+}
+
+// live returns the session if it is neither revoked nor past its idle or
+// absolute lifetime
+func (s *sessionService) live(sessionID string) (*models.Session, error) {
+	session, err := s.sessionRepo.FindSession(sessionID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSessionExpired
+	}
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().Unix()
+	idle := int64(s.Config.SessionIdleMinutes) * 60
+	if session.RevokedAt != 0 || now >= session.ExpiresAt || now-session.LastSeenAt > idle {
+		return nil, ErrSessionExpired
+	}
+	return session, nil
+}
+
+func (s *sessionService) issue(user *models.User, session *models.Session) (*models.LoginResponse, error) {
+	role, err := s.authRepo.FindRoleByID(user.RoleID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching role: %w", err)
+	}
+	accessToken, refreshToken, err := jwt.GenerateSessionTokenPair(user.Email, s.Config.JWTSecret, user.AdminStatus, user.ID, role.Name,
+		session.ID, session.RefreshID, time.Duration(s.Config.AccessTokenMinutes)*time.Minute, time.Unix(session.ExpiresAt, 0))
+	if err != nil {
+		return nil, err
+	}
+	return &models.LoginResponse{
+		UserResponse: models.UserResponse{
+			ID:        user.ID,
+			Fullname:  user.Fullname,
+			Username:  user.Username,
+			Telephone: user.Telephone,
+			Email:     user.Email,
+			RoleName:  role.Name,
+		},
+		AccessToken:      accessToken,
+		RefreshToken:     refreshToken,
+		RoleID:           user.RoleID.String(),
+		SessionExpiresAt: session.ExpiresAt,
+	}, nil
+}
+
+func (s *sessionService) stepUpTTL() time.Duration {
+	return time.Duration(s.Config.StepUpMinutes) * time.Minute
+}
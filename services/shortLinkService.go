@@ -0,0 +1,178 @@
+package services
+
+import (
+	"crypto/rand"
+	"errors"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrShortLinkNotFound is returned for codes no link has.
+	ErrShortLinkNotFound = errors.New("short link not found")
+	// ErrShortLinkExpired is returned for links past their expiry.
+	ErrShortLinkExpired = errors.New("this link has expired")
+)
+
+const (
+	// shortCodeLength is the length of generated codes: 62^7 of them leaves
+	// collisions rare enough to retry
+	shortCodeLength   = 7
+	shortCodeAttempts = 5
+	shortCodeAlphabet = "0123456789abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	// shortLinkStatsDays is how far back per-link analytics go
+	shortLinkStatsDays = 90
+)
+
+// ShortLinkService makes short links, for SMS where every character counts
+// and for shares, and counts their clicks
+type ShortLinkService interface {
+	Shorten(targetURL, purpose string, ttl time.Duration) (*models.ShortLink, error)
+	ReportLink(reportID, purpose string) (*models.ShortLink, error)
+	SubmissionLink(category, campaign string) (*models.ShortLink, error)
+	Follow(code, referrer string) (string, error)
+	Stats(code string) (*models.ShortLinkStats, error)
+}
+
+type shortLinkService struct {
+	Config        *config.Config
+	shortLinkRepo db.ShortLinkRepository
+}
+
+// NewShortLinkService creates a new instance of ShortLinkService
+func NewShortLinkService(shortLinkRepo db.ShortLinkRepository, conf *config.Config) ShortLinkService {
+	return &shortLinkService{
+		Config:        conf,
+		shortLinkRepo: shortLinkRepo,
+	}
+}
+
+// Shorten returns a short link to targetURL, reusing one made for the same
+// purpose while it has at least half its life left. A zero ttl makes a link
+// that never expires.
+func (s *shortLinkService) Shorten(targetURL, purpose string, ttl time.Duration) (*models.ShortLink, error) {
+	now := time.Now()
+	var expiresAt int64
+	if ttl > 0 {
+		expiresAt = now.Add(ttl).Unix()
+	}
+	existing, err := s.shortLinkRepo.FindLiveLink(targetURL, purpose, now.Add(ttl/2).Unix())
+	if err == nil && (existing.ExpiresAt == 0) == (expiresAt == 0) {
+		existing.URL = s.url(existing.Code)
+		return existing, nil
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	link := &models.ShortLink{
+		TargetURL: targetURL,
+		Purpose:   purpose,
+		ExpiresAt: expiresAt,
+		CreatedAt: now.Unix(),
+	}
+	for attempt := 0; attempt < shortCodeAttempts; attempt++ {
+		if link.Code, err = shortCode(); err != nil {
+			return nil, err
+		}
+		created, err := s.shortLinkRepo.CreateLink(link)
+		if err != nil {
+			return nil, err
+		}
+		if created {
+			link.URL = s.url(link.Code)
+			return link, nil
+		}
+	}
+	return nil, errors.New("could not find a free short link code")
+}
+
+// ReportLink shortens the link to a report. Links for notifications expire
+// after notification_link_days, those for shares after share_link_days and
+// those printed on posters never.
+func (s *shortLinkService) ReportLink(reportID, purpose string) (*models.ShortLink, error) {
+	var ttl time.Duration
+	switch purpose {
+	case models.ShortLinkNotification:
+		ttl = time.Duration(s.Config.NotificationLinkDays) * 24 * time.Hour
+	case models.ShortLinkShare:
+		ttl = time.Duration(s.Config.ShareLinkDays) * 24 * time.Hour
+	}
+	return s.Shorten(strings.TrimRight(s.Config.BaseUrl, "/")+"/reports/"+reportID, purpose, ttl)
+}
+
+// SubmissionLink shortens the link to the page for reporting in a
+// category, for a campaign's posters. It never expires.
+func (s *shortLinkService) SubmissionLink(category, campaign string) (*models.ShortLink, error) {
+	query := url.Values{"category": {category}}
+	if campaign != "" {
+		query.Set("campaign", campaign)
+	}
+	return s.Shorten(strings.TrimRight(s.Config.BaseUrl, "/")+"/reports/new?"+query.Encode(), models.ShortLinkPoster, 0)
+}
+
+// Follow returns where a code leads and counts the click
+func (s *shortLinkService) Follow(code, referrer string) (string, error) {
+	link, err := s.find(code)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now().Unix()
+	if link.Expired(now) {
+		return "", ErrShortLinkExpired
+	}
+	if len(referrer) > 255 {
+		referrer = referrer[:255]
+	}
+	if err := s.shortLinkRepo.RecordClick(&models.ShortLinkClick{LinkID: link.ID, ClickedAt: now, Referrer: referrer}); err != nil {
+		return "", err
+	}
+	return link.TargetURL, nil
+}
+
+// Stats returns a link with its clicks per day over the last 90 days
+func (s *shortLinkService) Stats(code string) (*models.ShortLinkStats, error) {
+	link, err := s.find(code)
+	if err != nil {
+		return nil, err
+	}
+	daily, err := s.shortLinkRepo.DailyClicks(link.ID, shortLinkStatsDays)
+	if err != nil {
+		return nil, err
+	}
+	link.URL = s.url(link.Code)
+	return &models.ShortLinkStats{Link: *link, Daily: daily}, nil
+}
+
+func (s *shortLinkService) find(code string) (*models.ShortLink, error) {
+	link, err := s.shortLinkRepo.FindLinkByCode(code)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrShortLinkNotFound
+	}
+	return link, err
+}
+
+// url is the short URL of a code on the short domain
+func (s *shortLinkService) url(code string) string {
+	return strings.TrimRight(s.Config.ShortLinkBaseURL, "/") + "/" + code
+}
+
+func shortCode() (string, error) {
+	code := make([]byte, shortCodeLength)
+	max := big.NewInt(int64(len(shortCodeAlphabet)))
+	for i := range code {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		code[i] = shortCodeAlphabet[n.Int64()]
+	}
+	return string(code), nil
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/services/jwt"
+)
+
+// SigningKeyService manages the keys tokens are signed with. Until the
+// first key is created tokens are signed with jwt_secret.
+type SigningKeyService interface {
+	Load() error
+	Rotate(algorithm string, activateIn time.Duration) (*models.SigningKey, error)
+	Prune() (int, error)
+}
+
+type signingKeyService struct {
+	Config         *config.Config
+	signingKeyRepo db.SigningKeyRepository
+}
+
+// NewSigningKeyService creates a new instance of SigningKeyService
+func NewSigningKeyService(signingKeyRepo db.SigningKeyRepository, conf *config.Config) SigningKeyService {
+	return &signingKeyService{
+		Config:         conf,
+		signingKeyRepo: signingKeyRepo,
+	}
+}
+
+// Load installs the stored keys that can still sign or validate tokens.
+// Servers call it periodically to pick up keys rotated elsewhere.
+func (s *signingKeyService) Load() error {
+	usable, activeID, _, err := s.partition()
+	if err != nil {
+		return err
+	}
+	stored := make([]jwt.StoredKey, 0, len(usable))
+	for _, key := range usable {
+		stored = append(stored, jwt.StoredKey{
+			ID:         key.ID,
+			Algorithm:  key.Algorithm,
+			PrivateKey: key.PrivateKey,
+			PublicKey:  key.PublicKey,
+		})
+	}
+	set, err := jwt.NewKeySet(stored, activeID, s.Config.JWTAcceptHS256)
+	if err != nil {
+		return err
+	}
+	jwt.SetKeys(set)
+	return nil
+}
+
+// Rotate creates a key that starts signing after activateIn, long enough
+// for every server to load it and publish it in the JWKS first
+func (s *signingKeyService) Rotate(algorithm string, activateIn time.Duration) (*models.SigningKey, error) {
+	id := uuid.NewString()
+	generated, err := jwt.GenerateStoredKey(id, algorithm)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	key := &models.SigningKey{
+		ID:         id,
+		Algorithm:  generated.Algorithm,
+		PrivateKey: generated.PrivateKey,
+		PublicKey:  generated.PublicKey,
+		ActiveFrom: now.Add(activateIn).Unix(),
+		CreatedAt:  now.Unix(),
+	}
+	if err := s.signingKeyRepo.CreateKey(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Prune deletes keys replaced long enough ago that no token they signed can
+// still be valid
+func (s *signingKeyService) Prune() (int, error) {
+	_, _, expired, err := s.partition()
+	if err != nil {
+		return 0, err
+	}
+	return len(expired), s.signingKeyRepo.DeleteKeys(expired)
+}
+
+// partition sorts the stored keys into those still in use, newest first,
+// naming the one that signs, and the IDs of those past use. A key is past
+// use once the key after it has been active for longer than a session can
+// last.
+func (s *signingKeyService) partition() ([]models.SigningKey, string, []string, error) {
+	keys, err := s.signingKeyRepo.ListKeys()
+	if err != nil {
+		return nil, "", nil, err
+	}
+	now := time.Now().Unix()
+	cutoff := now - int64(s.Config.SessionMaxHours)*3600
+
+	var usable []models.SigningKey
+	var expired []string
+	activeID := ""
+	var successorFrom int64
+	for _, key := range keys {
+		switch {
+		case key.ActiveFrom > now:
+			// Published ahead of use
+		case activeID == "":
+			activeID = key.ID
+		case successorFrom < cutoff:
+			expired = append(expired, key.ID)
+			continue
+		}
+		usable = append(usable, key)
+		successorFrom = key.ActiveFrom
+	}
+	return usable, activeID, expired, nil
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/sms"
+)
+
+// smsLength is what fits in one text message; longer ones are sent and
+// billed as several
+const smsLength = 160
+
+// ReportNotificationSender is a NotificationSender that can link to the
+// report a notification is about
+type ReportNotificationSender interface {
+	NotificationSender
+	SendAboutReport(userID uint, reportID, subject, message string) error
+}
+
+// smsSender delivers notifications by text message to the phone number on
+// the user's account, cut to fit one message
+type smsSender struct {
+	authRepo db.AuthRepository
+	sender   sms.Sender
+	links    ShortLinkService
+}
+
+// NewSMSSender returns a NotificationSender for the SMS channel
+func NewSMSSender(authRepo db.AuthRepository, sender sms.Sender, links ShortLinkService) ReportNotificationSender {
+	return &smsSender{authRepo: authRepo, sender: sender, links: links}
+}
+
+func (s *smsSender) Send(userID uint, subject, message string) error {
+	return s.send(userID, message, "")
+}
+
+// SendAboutReport ends the message with a short link to the report, which
+// leaves room for more of the message than the full link would
+func (s *smsSender) SendAboutReport(userID uint, reportID, subject, message string) error {
+	link, err := s.links.ReportLink(reportID, models.ShortLinkNotification)
+	if err != nil {
+		log.Printf("shortening notification link to report %s: %v", reportID, err)
+		return s.send(userID, message, "")
+	}
+	return s.send(userID, message, link.URL)
+}
+
+func (s *smsSender) send(userID uint, message, link string) error {
+	user, err := s.authRepo.FindUserByID(userID)
+	if err != nil {
+		return err
+	}
+	if user.Telephone == "" {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	return s.sender.Send(ctx, user.Telephone, fitSMS(message, link))
+}
+
+// fitSMS joins message and link into one text message, shortening the
+// message, never the link
+func fitSMS(message, link string) string {
+	message = strings.Join(strings.Fields(message), " ")
+	room := smsLength
+	if link != "" {
+		room -= utf8.RuneCountInString(link) + 1
+	}
+	if utf8.RuneCountInString(message) > room {
+		runes := []rune(message)
+		message = strings.TrimSpace(string(runes[:room-1])) + "…"
+	}
+	if link == "" {
+		return message
+	}
+	return message + " " + link
+}
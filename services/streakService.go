@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/events"
+	"github.com/techagentng/citizenx/locale"
+	"github.com/techagentng/citizenx/models"
+)
+
+const (
+	// MaxDailyGoal is the most reports a day a user may aim for
+	MaxDailyGoal = 20
+	// streakLookbackDays is how far back report days are counted, which
+	// bounds how long a streak can be seen to run
+	streakLookbackDays = 366
+	// streakReminderMinDays is how long a streak must run before losing it
+	// is worth a reminder
+	streakReminderMinDays = 2
+	// streakBatchSize is how many streaks at risk are loaded at a time
+	streakBatchSize = 200
+	// streakBonusType is the reward type of streak bonuses
+	streakBonusType = "Streak bonus"
+)
+
+// ErrInvalidDailyGoal is returned for a daily goal outside 1 to MaxDailyGoal
+var ErrInvalidDailyGoal = fmt.Errorf("the daily goal must be 1 to %d reports", MaxDailyGoal)
+
+// StreakService follows users' runs of days meeting their daily reporting
+// goal, pays bonuses as streaks reach their badges and reminds users of
+// streaks about to end
+type StreakService interface {
+	Subscribe(bus events.Bus)
+	Progress(userID uint) (models.StreakProgress, error)
+	SetDailyGoal(userID uint, goal int) (models.StreakProgress, error)
+	RemindAtRisk() (int, error)
+}
+
+type streakService struct {
+	Config              *config.Config
+	streakRepo          db.StreakRepository
+	notificationService NotificationService
+}
+
+// NewStreakService creates a new instance of StreakService
+func NewStreakService(streakRepo db.StreakRepository, notificationService NotificationService, conf *config.Config) StreakService {
+	return &streakService{
+		Config:              conf,
+		streakRepo:          streakRepo,
+		notificationService: notificationService,
+	}
+}
+
+// Subscribe updates the reporter's streak whenever one of their reports is
+// published or taken down, since only published reports count
+func (s *streakService) Subscribe(bus events.Bus) {
+	bus.Subscribe(events.StatusChangedEvent, s.handleEvent)
+}
+
+func (s *streakService) handleEvent(ctx context.Context, event events.Event) error {
+	e, ok := event.(events.ReportStatusChanged)
+	if !ok || e.UserID == 0 || models.IsPublishedReportStatus(e.FromStatus) == models.IsPublishedReportStatus(e.ToStatus) {
+		return nil
+	}
+	return s.refresh(e.UserID, time.Now())
+}
+
+// refresh works the user's streak out again from their reports and saves
+// it with a bonus for each streak badge it has reached, earned over the
+// streak's first days up to the badge
+func (s *streakService) refresh(userID uint, now time.Time) error {
+	streak, counts, err := s.load(userID, now)
+	if err != nil {
+		return err
+	}
+	streak.StartDay, streak.LastDay, streak.Current = streakRun(counts, s.goal(streak), now)
+	streak.Longest = max(streak.Longest, streak.Current)
+
+	var bonuses []models.StreakBonus
+	if s.Config.StreakBonusPoints > 0 && streak.Current > 0 {
+		first, err := time.Parse(time.DateOnly, streak.StartDay)
+		if err != nil {
+			return err
+		}
+		for _, b := range streakBadges {
+			if int64(streak.Current) < b.target {
+				continue
+			}
+			points := s.Config.StreakBonusPoints * int(b.target)
+			bonuses = append(bonuses, models.StreakBonus{
+				Badge:    b.id,
+				FirstDay: streak.StartDay,
+				LastDay:  first.AddDate(0, 0, int(b.target)-1).Format(time.DateOnly),
+				Reward: models.Reward{
+					UserID:     userID,
+					RewardType: streakBonusType,
+					Point:      points,
+					Balance:    points,
+				},
+			})
+		}
+	}
+	return s.streakRepo.SaveStreak(streak, bonuses)
+}
+
+// load returns the user's saved streak and their report counts by day
+func (s *streakService) load(userID uint, now time.Time) (*models.ReportingStreak, map[string]int64, error) {
+	streak, err := s.streakRepo.GetStreak(userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	days, err := s.streakRepo.ReportDays(userID, now.AddDate(0, 0, -streakLookbackDays))
+	if err != nil {
+		return nil, nil, err
+	}
+	counts := make(map[string]int64, len(days))
+	for _, day := range days {
+		counts[day.Day] = day.Reports
+	}
+	return streak, counts, nil
+}
+
+func (s *streakService) goal(streak *models.ReportingStreak) int {
+	if streak.DailyGoal > 0 {
+		return streak.DailyGoal
+	}
+	return max(s.Config.StreakDailyGoal, 1)
+}
+
+// streakRun finds the run of days, in Lagos time, meeting goal that ends
+// today, or yesterday while today's goal is still to be met, returning its
+// first and last days and its length
+func streakRun(counts map[string]int64, goal int, now time.Time) (start, last string, length int) {
+	day := now.In(locale.TimeZone)
+	if counts[day.Format(time.DateOnly)] < int64(goal) {
+		day = day.AddDate(0, 0, -1)
+	}
+	for counts[day.Format(time.DateOnly)] >= int64(goal) {
+		if last == "" {
+			last = day.Format(time.DateOnly)
+		}
+		start = day.Format(time.DateOnly)
+		length++
+		day = day.AddDate(0, 0, -1)
+	}
+	return start, last, length
+}
+
+// Progress returns the user's streak as it stands now, and how far they are
+// towards today's goal
+func (s *streakService) Progress(userID uint) (models.StreakProgress, error) {
+	now := time.Now()
+	streak, counts, err := s.load(userID, now)
+	if err != nil {
+		return models.StreakProgress{}, err
+	}
+	goal := s.goal(streak)
+	_, _, current := streakRun(counts, goal, now)
+	progress := models.StreakProgress{
+		Current:      current,
+		Longest:      max(streak.Longest, current),
+		DailyGoal:    goal,
+		TodayReports: counts[now.In(locale.TimeZone).Format(time.DateOnly)],
+	}
+	progress.GoalMet = progress.TodayReports >= int64(goal)
+	progress.AtRisk = current > 0 && !progress.GoalMet
+	return progress, nil
+}
+
+// SetDailyGoal sets how many reports a day keep the user's streak going
+func (s *streakService) SetDailyGoal(userID uint, goal int) (models.StreakProgress, error) {
+	if goal < 1 || goal > MaxDailyGoal {
+		return models.StreakProgress{}, ErrInvalidDailyGoal
+	}
+	if err := s.streakRepo.SetDailyGoal(userID, goal); err != nil {
+		return models.StreakProgress{}, err
+	}
+	// The new goal may lengthen or break the streak
+	if err := s.refresh(userID, time.Now()); err != nil {
+		return models.StreakProgress{}, err
+	}
+	return s.Progress(userID)
+}
+
+// RemindAtRisk reminds users whose streak ends at midnight that they have
+// yet to meet today's goal, once a day from streak_reminder_hour. It returns
+// how many were sent.
+func (s *streakService) RemindAtRisk() (int, error) {
+	now := time.Now().In(locale.TimeZone)
+	if s.Config.StreakReminderHour < 0 || now.Hour() < s.Config.StreakReminderHour {
+		return 0, nil
+	}
+	today := now.Format(time.DateOnly)
+	yesterday := now.AddDate(0, 0, -1).Format(time.DateOnly)
+
+	sent := 0
+	var afterID uint
+	for {
+		streaks, err := s.streakRepo.StreaksAtRisk(yesterday, today, streakReminderMinDays, afterID, streakBatchSize)
+		if err != nil {
+			return sent, err
+		}
+		if len(streaks) == 0 {
+			return sent, nil
+		}
+		for i := range streaks {
+			streak := &streaks[i]
+			goal := "a report"
+			if g := s.goal(streak); g > 1 {
+				goal = fmt.Sprintf("%d reports", g)
+			}
+			message := fmt.Sprintf("Your %d-day reporting streak ends at midnight. Submit %s today to keep it going.", streak.Current, goal)
+			reminded, err := s.notificationService.Remind(streak.UserID, "Keep your streak going", message)
+			if err != nil {
+				return sent, err
+			}
+			if reminded {
+				sent++
+			}
+			// Users who turned reminders off are not asked again today either
+			if err := s.streakRepo.MarkReminded(streak.UserID, today); err != nil {
+				return sent, err
+			}
+		}
+		afterID = streaks[len(streaks)-1].UserID
+	}
+}
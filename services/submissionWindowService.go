@@ -0,0 +1,137 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrSubmissionWindowNotFound is returned for windows that do not exist.
+	ErrSubmissionWindowNotFound = errors.New("submission window not found")
+	// ErrInvalidSubmissionWindow is returned for a window that closes before
+	// it opens.
+	ErrInvalidSubmissionWindow = errors.New("a submission window must close after it opens")
+	// ErrCategoryClosed is returned when reporting in a category outside its
+	// submission windows.
+	ErrCategoryClosed = errors.New("this category is not accepting reports right now")
+)
+
+// SubmissionWindowService restricts categories to the time windows admins
+// configure
+type SubmissionWindowService interface {
+	ListWindows() ([]models.SubmissionWindow, error)
+	CreateWindow(window *models.SubmissionWindow, adminID uint) error
+	DeleteWindow(id uint) error
+	Check(category string, now time.Time) error
+	Availability(now time.Time) ([]models.CategoryAvailability, error)
+}
+
+type submissionWindowService struct {
+	Config               *config.Config
+	submissionWindowRepo db.SubmissionWindowRepository
+}
+
+// NewSubmissionWindowService creates a new instance of SubmissionWindowService
+func NewSubmissionWindowService(submissionWindowRepo db.SubmissionWindowRepository, conf *config.Config) SubmissionWindowService {
+	return &submissionWindowService{
+		Config:               conf,
+		submissionWindowRepo: submissionWindowRepo,
+	}
+}
+
+func (s *submissionWindowService) ListWindows() ([]models.SubmissionWindow, error) {
+	return s.submissionWindowRepo.ListWindows()
+}
+
+// CreateWindow adds a window to a category. Categories are matched without
+// regard to case.
+func (s *submissionWindowService) CreateWindow(window *models.SubmissionWindow, adminID uint) error {
+	window.ID = 0
+	window.Category = strings.ToLower(strings.TrimSpace(window.Category))
+	window.Campaign = strings.TrimSpace(window.Campaign)
+	if window.Category == "" || window.ClosesAt <= window.OpensAt {
+		return ErrInvalidSubmissionWindow
+	}
+	window.CreatedBy = adminID
+	window.CreatedAt = time.Now().Unix()
+	return s.submissionWindowRepo.CreateWindow(window)
+}
+
+func (s *submissionWindowService) DeleteWindow(id uint) error {
+	err := s.submissionWindowRepo.DeleteWindow(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrSubmissionWindowNotFound
+	}
+	return err
+}
+
+// Check returns ErrCategoryClosed, saying when the category next opens, when
+// category has windows and none of them is open at now
+func (s *submissionWindowService) Check(category string, now time.Time) error {
+	windows, err := s.submissionWindowRepo.CategoryWindows(strings.ToLower(strings.TrimSpace(category)))
+	if err != nil {
+		return err
+	}
+	if len(windows) == 0 {
+		return nil
+	}
+	availability := availabilityOf(windows, now)
+	switch {
+	case availability.Open:
+		return nil
+	case availability.OpensAt != 0:
+		return fmt.Errorf("%w: it opens at %s", ErrCategoryClosed, time.Unix(availability.OpensAt, 0).UTC().Format(time.RFC3339))
+	default:
+		return fmt.Errorf("%w: its submission windows have closed", ErrCategoryClosed)
+	}
+}
+
+// Availability describes every category that has windows, for clients to
+// hide or explain closed categories
+func (s *submissionWindowService) Availability(now time.Time) ([]models.CategoryAvailability, error) {
+	windows, err := s.submissionWindowRepo.ListWindows()
+	if err != nil {
+		return nil, err
+	}
+	byCategory := map[string][]models.SubmissionWindow{}
+	var categories []string
+	for _, window := range windows {
+		if _, ok := byCategory[window.Category]; !ok {
+			categories = append(categories, window.Category)
+		}
+		byCategory[window.Category] = append(byCategory[window.Category], window)
+	}
+	availability := make([]models.CategoryAvailability, 0, len(categories))
+	for _, category := range categories {
+		availability = append(availability, availabilityOf(byCategory[category], now))
+	}
+	return availability, nil
+}
+
+// availabilityOf reports whether one of a category's windows, sorted by
+// opening time, is open at now, or else which opens next
+func availabilityOf(windows []models.SubmissionWindow, now time.Time) models.CategoryAvailability {
+	at := now.Unix()
+	availability := models.CategoryAvailability{Category: windows[0].Category}
+	for _, window := range windows {
+		if window.OpensAt <= at && at < window.ClosesAt {
+			availability.Open = true
+		} else if window.OpensAt <= at || availability.OpensAt != 0 {
+			continue
+		}
+		availability.Campaign = window.Campaign
+		availability.OpensAt = window.OpensAt
+		availability.ClosesAt = window.ClosesAt
+		if availability.Open {
+			break
+		}
+	}
+	return availability
+}
@@ -0,0 +1,246 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/storage"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrUploadNotFound is returned for uploads that do not exist or belong
+	// to another user.
+	ErrUploadNotFound = errors.New("upload not found")
+	// ErrUploadOffsetMismatch is returned when a chunk does not start where
+	// the stored bytes end.
+	ErrUploadOffsetMismatch = errors.New("upload offset does not match")
+	// ErrUploadTooLarge is returned for uploads over the configured size.
+	ErrUploadTooLarge = errors.New("upload exceeds the maximum size")
+	// ErrUploadComplete is returned when writing to a finished upload.
+	ErrUploadComplete = errors.New("upload is already complete")
+)
+
+// UploadService accepts report media in resumable chunks, so a large video
+// interrupted on a poor connection continues from the last stored byte
+// instead of starting over
+type UploadService interface {
+	CreateUpload(userID uint, draftID string, length int64, filename, fileType string) (*models.Upload, error)
+	GetUpload(uploadID string, userID uint) (*models.Upload, error)
+	WriteChunk(upload *models.Upload, offset int64, body io.Reader) error
+	DeleteUpload(upload *models.Upload) error
+	PurgeStaleUploads() (int, error)
+	MaxSize() int64
+}
+
+type uploadService struct {
+	Config       *config.Config
+	uploadRepo   db.UploadRepository
+	draftRepo    db.ReportDraftRepository
+	mediaService MediaService
+	objects      ObjectService
+}
+
+// NewUploadService creates a new instance of UploadService
+func NewUploadService(uploadRepo db.UploadRepository, draftRepo db.ReportDraftRepository, mediaService MediaService, objects ObjectService, conf *config.Config) UploadService {
+	return &uploadService{
+		Config:       conf,
+		uploadRepo:   uploadRepo,
+		draftRepo:    draftRepo,
+		mediaService: mediaService,
+		objects:      objects,
+	}
+}
+
+func (s *uploadService) MaxSize() int64 {
+	return s.Config.MaxUploadSize
+}
+
+// CreateUpload starts an upload of length bytes for one of the user's drafts
+func (s *uploadService) CreateUpload(userID uint, draftID string, length int64, filename, fileType string) (*models.Upload, error) {
+	if length > s.Config.MaxUploadSize {
+		return nil, ErrUploadTooLarge
+	}
+	if err := storage.LimitsFromConfig(s.Config).CheckSize(storage.KindOf(fileType), length); err != nil {
+		return nil, err
+	}
+	draft, err := s.draftRepo.GetDraft(draftID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrDraftNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	upload := &models.Upload{
+		ID:        uuid.New(),
+		UserID:    userID,
+		DraftID:   draft.ID,
+		Filename:  path.Base(filename),
+		FileType:  fileType,
+		Length:    length,
+		CreatedAt: time.Now().Unix(),
+	}
+	if err := s.uploadRepo.CreateUpload(upload); err != nil {
+		return nil, err
+	}
+	return upload, nil
+}
+
+func (s *uploadService) GetUpload(uploadID string, userID uint) (*models.Upload, error) {
+	upload, err := s.uploadRepo.GetUpload(uploadID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUploadNotFound
+	}
+	return upload, err
+}
+
+// WriteChunk stores the bytes of body starting at offset. Whatever arrived
+// before the connection dropped is kept, so the client can resume from the
+// new offset. The upload is assembled and attached to its draft once the
+// last byte is stored.
+func (s *uploadService) WriteChunk(upload *models.Upload, offset int64, body io.Reader) error {
+	if upload.CompletedAt > 0 {
+		return ErrUploadComplete
+	}
+	if offset != upload.Offset {
+		return ErrUploadOffsetMismatch
+	}
+
+	// Spool to disk first: the store needs the chunk size up front and the
+	// body may be cut short
+	spool, err := os.CreateTemp("", "upload-chunk-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(spool.Name())
+	defer spool.Close()
+
+	remaining := upload.Length - offset
+	size, readErr := io.Copy(spool, io.LimitReader(body, remaining))
+	if size > 0 {
+		if _, err := spool.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		key := fmt.Sprintf("uploads/%s/%d", upload.ID, offset)
+		if _, err := s.objects.Put(key, spool, size, "application/octet-stream", models.ObjectOwnerUpload, upload.ID.String()); err != nil {
+			return err
+		}
+		chunk := &models.UploadChunk{UploadID: upload.ID, Offset: offset, Size: size, Key: key}
+		if err := s.uploadRepo.AddChunk(upload, chunk); err != nil {
+			s.deleteObject(key)
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrUploadOffsetMismatch
+			}
+			return err
+		}
+	}
+	if readErr != nil {
+		return fmt.Errorf("reading upload body: %w", readErr)
+	}
+
+	if upload.Offset == upload.Length {
+		return s.complete(upload)
+	}
+	return nil
+}
+
+// complete joins the chunks into the original and hands it to the media
+// pipeline
+func (s *uploadService) complete(upload *models.Upload) error {
+	chunks, err := s.uploadRepo.ListChunks(upload.ID.String())
+	if err != nil {
+		return err
+	}
+
+	var data bytes.Buffer
+	data.Grow(int(upload.Length))
+	for _, chunk := range chunks {
+		r, err := s.objects.Open(chunk.Key)
+		if err != nil {
+			return fmt.Errorf("reading chunk at %d: %w", chunk.Offset, err)
+		}
+		_, err = io.Copy(&data, r)
+		r.Close()
+		if err != nil {
+			return fmt.Errorf("reading chunk at %d: %w", chunk.Offset, err)
+		}
+	}
+	if int64(data.Len()) != upload.Length {
+		return fmt.Errorf("assembled upload is %d bytes, expected %d", data.Len(), upload.Length)
+	}
+
+	media, err := s.mediaService.ProcessUpload(data.Bytes(), upload.Filename, upload.FileType, upload.DraftID.String(), upload.UserID)
+	var invalid *storage.ValidationError
+	if errors.As(err, &invalid) {
+		// Resuming cannot fix a rejected file, so drop it
+		invalid.Filename = upload.Filename
+		if err := s.DeleteUpload(upload); err != nil {
+			log.Printf("Error discarding rejected upload %s: %v", upload.ID, err)
+		}
+		return invalid
+	}
+	if err != nil {
+		return err
+	}
+	if err := s.uploadRepo.CompleteUpload(upload.ID.String(), media.ID); err != nil {
+		return err
+	}
+	upload.MediaID = media.ID
+	upload.CompletedAt = time.Now().Unix()
+
+	for _, chunk := range chunks {
+		s.deleteObject(chunk.Key)
+	}
+	return nil
+}
+
+// DeleteUpload abandons an upload and removes the chunks stored so far
+func (s *uploadService) DeleteUpload(upload *models.Upload) error {
+	chunks, err := s.uploadRepo.ListChunks(upload.ID.String())
+	if err != nil {
+		return err
+	}
+	if err := s.uploadRepo.DeleteUpload(upload.ID.String()); err != nil {
+		return err
+	}
+	for _, chunk := range chunks {
+		s.deleteObject(chunk.Key)
+	}
+	return nil
+}
+
+// PurgeStaleUploads removes unfinished uploads older than a draft may live,
+// since their draft is gone by then
+func (s *uploadService) PurgeStaleUploads() (int, error) {
+	ttl := time.Duration(s.Config.DraftReportTTLHours) * time.Hour
+	if ttl <= 0 {
+		return 0, nil
+	}
+	uploads, err := s.uploadRepo.StaleUploads(time.Now().Add(-ttl))
+	if err != nil {
+		return 0, err
+	}
+	for i := range uploads {
+		if err := s.DeleteUpload(&uploads[i]); err != nil {
+			return i, err
+		}
+	}
+	return len(uploads), nil
+}
+
+func (s *uploadService) deleteObject(key string) {
+	if err := s.objects.Delete(key); err != nil {
+		log.Printf("Error deleting stored object %s: %v", key, err)
+	}
+}
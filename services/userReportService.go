@@ -0,0 +1,68 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/policy"
+	"gorm.io/gorm"
+)
+
+// MaxWithdrawReasonLength bounds the reason given for withdrawing a report
+const MaxWithdrawReasonLength = 500
+
+var (
+	// ErrReportNotPending is returned when withdrawing a report that has
+	// already been moderated or withdrawn.
+	ErrReportNotPending = errors.New("only pending reports can be withdrawn")
+	// ErrWithdrawReasonRequired is returned when a report is withdrawn
+	// without a reason, or with one that is too long.
+	ErrWithdrawReasonRequired = errors.New("a reason of up to 500 characters is required to withdraw a report")
+)
+
+// ListUserReports returns a page of the user's own reports and their counts
+// by status
+func (s *IncidentService) ListUserReports(userID uint, filter db.UserReportFilter, page int) (*models.UserReportPage, error) {
+	reports, err := s.incidentRepo.ListUserReports(userID, filter, page, db.ReportCard()...)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.incidentRepo.CountUserReports(userID, filter)
+	if err != nil {
+		return nil, err
+	}
+	return &models.UserReportPage{Reports: reports, Counts: counts, Page: page}, nil
+}
+
+// WithdrawReport lets a reporter retract their report while it still awaits
+// moderation
+func (s *IncidentService) WithdrawReport(userID uint, reportID, reason string) error {
+	reason = strings.TrimSpace(reason)
+	if reason == "" || len([]rune(reason)) > MaxWithdrawReasonLength {
+		return ErrWithdrawReasonRequired
+	}
+	id, err := uuid.Parse(reportID)
+	if err != nil {
+		return ErrReportNotFound
+	}
+
+	withdrawn, err := s.incidentRepo.WithdrawReport(id, userID, reason)
+	if err != nil || withdrawn {
+		return err
+	}
+	// Work out why nothing was withdrawn
+	report, err := s.incidentRepo.GetIncidentReportByID(reportID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrReportNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if policy.Authorize(policy.Subject{UserID: userID}, policy.EditReport, policy.Report(report)) != nil {
+		return ErrNotReportOwner
+	}
+	return ErrReportNotPending
+}
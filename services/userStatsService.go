@@ -0,0 +1,140 @@
+package services
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/techagentng/citizenx/cache"
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/locale"
+	"github.com/techagentng/citizenx/models"
+)
+
+const (
+	// UserStatsCacheTTL is how long a user's profile statistics are reused;
+	// the profile screen is opened far more often than reports change
+	UserStatsCacheTTL = 5 * time.Minute
+	// userStatsMonths is how many months of points the statistics cover
+	userStatsMonths = 12
+)
+
+// badge is an achievement reached when one of a user's statistics meets its
+// target
+type badge struct {
+	id, name string
+	target   int64
+	progress func(stats *models.UserStats) int64
+}
+
+func verifiedReports(stats *models.UserStats) int64 {
+	var verified int64
+	for _, status := range models.PublishedReportStatuses {
+		verified += stats.ReportsByStatus[status]
+	}
+	return verified
+}
+
+// streakBadges are earned by the longest streak a user has had, and pay a
+// bonus each time a streak reaches them
+var streakBadges = []badge{
+	{"streak_3", "Three days running", 3, longestStreak},
+	{"streak_7", "Week-long streak", 7, longestStreak},
+	{"streak_30", "Month-long streak", 30, longestStreak},
+}
+
+func longestStreak(stats *models.UserStats) int64 {
+	return int64(stats.Streak.Longest)
+}
+
+var badges = append([]badge{
+	{"first_report", "First report", 1, func(s *models.UserStats) int64 { return s.TotalReports }},
+	{"regular_reporter", "Regular reporter", 10, func(s *models.UserStats) int64 { return s.TotalReports }},
+	{"dedicated_reporter", "Dedicated reporter", 50, func(s *models.UserStats) int64 { return s.TotalReports }},
+	{"verified_voice", "Verified voice", 5, verifiedReports},
+	{"watched", "Widely read", 1000, func(s *models.UserStats) int64 { return s.TotalViews }},
+	{"endorsed", "Community endorsed", 100, func(s *models.UserStats) int64 { return s.Endorsements }},
+	{"verified_citizen", "Verified citizen", 1, func(s *models.UserStats) int64 {
+		if s.IdentityVerified {
+			return 1
+		}
+		return 0
+	}},
+}, streakBadges...)
+
+// UserStatsService summarizes a user's reporting for their profile
+type UserStatsService interface {
+	Stats(user *models.User) (*models.UserStats, error)
+}
+
+type userStatsService struct {
+	Config        *config.Config
+	userStatsRepo db.UserStatsRepository
+	streaks       StreakService
+	stats         *cache.TTL[*models.UserStats]
+}
+
+// NewUserStatsService creates a new instance of UserStatsService
+func NewUserStatsService(userStatsRepo db.UserStatsRepository, streaks StreakService, conf *config.Config) UserStatsService {
+	return &userStatsService{
+		Config:        conf,
+		userStatsRepo: userStatsRepo,
+		streaks:       streaks,
+		stats:         cache.New[*models.UserStats](UserStatsCacheTTL),
+	}
+}
+
+// Stats returns the user's report counts, reach, points, LGA rank, streak
+// and badge progress, computed at most once every UserStatsCacheTTL
+func (s *userStatsService) Stats(user *models.User) (*models.UserStats, error) {
+	return s.stats.GetOrLoad(strconv.FormatUint(uint64(user.ID), 10), func() (*models.UserStats, error) {
+		return s.compute(user)
+	})
+}
+
+func (s *userStatsService) compute(user *models.User) (*models.UserStats, error) {
+	now := time.Now()
+	stats := &models.UserStats{LGAName: user.LGAName, IdentityVerified: user.IdentityVerifiedAt != nil, ComputedAt: now.Unix()}
+
+	var err error
+	if stats.ReportsByStatus, err = s.userStatsRepo.ReportStatusCounts(user.ID); err != nil {
+		return nil, err
+	}
+	totals, err := s.userStatsRepo.ReportTotals(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	stats.TotalReports, stats.TotalViews, stats.Endorsements = totals.Reports, totals.Views, totals.Endorsements
+
+	// The chart starts on the first of the month, eleven months back
+	lagos := now.In(locale.TimeZone)
+	since := time.Date(lagos.Year(), lagos.Month()-userStatsMonths+1, 1, 0, 0, 0, 0, locale.TimeZone)
+	if stats.PointsByMonth, err = s.userStatsRepo.PointsByMonth(user.ID, since.Unix()); err != nil {
+		return nil, err
+	}
+	if stats.TotalPoints, err = s.userStatsRepo.TotalPoints(user.ID); err != nil {
+		return nil, err
+	}
+	if user.LGAName != "" {
+		if stats.LGARank, stats.LGAUsers, err = s.userStatsRepo.LGARank(user.LGAName, stats.TotalPoints); err != nil {
+			return nil, err
+		}
+	}
+
+	if stats.Streak, err = s.streaks.Progress(user.ID); err != nil {
+		return nil, err
+	}
+
+	stats.Badges = make([]models.BadgeProgress, len(badges))
+	for i, b := range badges {
+		progress := b.progress(stats)
+		stats.Badges[i] = models.BadgeProgress{
+			Badge:    b.id,
+			Name:     b.name,
+			Progress: min(progress, b.target),
+			Target:   b.target,
+			Earned:   progress >= b.target,
+		}
+	}
+	return stats, nil
+}
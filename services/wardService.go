@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/events"
+	"github.com/techagentng/citizenx/geo"
+	"github.com/techagentng/citizenx/models"
+)
+
+// WardService places new reports in their ward by reverse geocoding them
+type WardService interface {
+	Subscribe(bus events.Bus)
+}
+
+type wardService struct {
+	Config   *config.Config
+	geoRepo  db.GeoRepository
+	geocoder geo.Geocoder
+}
+
+// NewWardService creates a new instance of WardService
+func NewWardService(geoRepo db.GeoRepository, geocoder geo.Geocoder, conf *config.Config) WardService {
+	return &wardService{
+		Config:   conf,
+		geoRepo:  geoRepo,
+		geocoder: geocoder,
+	}
+}
+
+// Subscribe registers the service for newly created reports
+func (s *wardService) Subscribe(bus events.Bus) {
+	bus.Subscribe(events.ReportCreatedEvent, s.handleReportCreated)
+}
+
+func (s *wardService) handleReportCreated(ctx context.Context, event events.Event) error {
+	e, ok := event.(events.ReportCreated)
+	// Reports submitted without a location cannot be placed
+	if !ok || (e.Latitude == 0 && e.Longitude == 0) {
+		return nil
+	}
+
+	place, err := s.geocoder.ReverseGeocode(ctx, e.Latitude, e.Longitude)
+	if err != nil {
+		return err
+	}
+	if place.Ward == "" {
+		return nil
+	}
+
+	// The ward hangs off the state and LGA the report was filed under, so
+	// ward totals roll up into the existing state and LGA figures
+	ward := &models.Ward{Name: place.Ward, LGAName: e.LGAName, StateName: e.StateName}
+	if ward.LGAName == "" {
+		ward.LGAName = place.LGA
+	}
+	if ward.StateName == "" {
+		ward.StateName = place.State
+	}
+	return s.geoRepo.SetReportWard(e.ReportID, ward)
+}
@@ -0,0 +1,318 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/storage"
+)
+
+// maxWarehouseDaysPerRun bounds how many days of one dataset a single run
+// exports, so a first export of a long history is spread over several runs
+const maxWarehouseDaysPerRun = 31
+
+// warehouseReport is a row of the reports dataset, the report as it stood
+// at updated_at. A report is exported again each day it changes, so loaders
+// keep the row with the latest updated_at per id. Contact details and names
+// are left out, coordinates are coarsened, and the reporter is a pseudonym
+// that anonymous reports and deleted accounts do not carry.
+type warehouseReport struct {
+	ID           string    `parquet:"id"`
+	CreatedAt    time.Time `parquet:"created_at,timestamp"`
+	UpdatedAt    time.Time `parquet:"updated_at,timestamp"`
+	IncidentDate time.Time `parquet:"incident_date,timestamp"`
+	Category     string    `parquet:"category,dict"`
+	SubCategory  string    `parquet:"sub_category,dict"`
+	State        string    `parquet:"state,dict"`
+	LGA          string    `parquet:"lga,dict"`
+	Ward         string    `parquet:"ward,dict"`
+	Latitude     float64   `parquet:"latitude"`
+	Longitude    float64   `parquet:"longitude"`
+	Status       string    `parquet:"status,dict"`
+	Verified     bool      `parquet:"verified"`
+	Anonymous    bool      `parquet:"anonymous"`
+	Reporter     *string   `parquet:"reporter,optional"`
+	IncidentID   *string   `parquet:"incident_id,optional"`
+	Upvotes      int64     `parquet:"upvotes"`
+	Downvotes    int64     `parquet:"downvotes"`
+	Views        int64     `parquet:"views"`
+}
+
+// warehouseActivity is a row of the engagement dataset. The user is a
+// pseudonym, missing once the account is deleted.
+type warehouseActivity struct {
+	ID         int64     `parquet:"id"`
+	User       *string   `parquet:"user,optional"`
+	EventType  string    `parquet:"event_type,dict"`
+	OccurredAt time.Time `parquet:"occurred_at,timestamp"`
+	Metadata   string    `parquet:"metadata"`
+}
+
+// warehouseDataset is one dataset the export writes. Version must be
+// raised whenever a column is removed or changes meaning; files of each
+// version live under their own prefix so loaders never mix schemas.
+type warehouseDataset struct {
+	name    string
+	version int
+	schema  *parquet.Schema
+	first   func() (*time.Time, error)
+	// encode writes the rows of the day starting at start as Parquet and
+	// returns how many there were
+	encode func(buf *bytes.Buffer, start, end time.Time) (int64, error)
+}
+
+// WarehouseService exports reports and engagement data as Parquet files
+// partitioned by day, for loading into Athena or BigQuery
+type WarehouseService interface {
+	ExportPending() (int, error)
+	Manifest() ([]models.WarehouseDataset, error)
+}
+
+type warehouseService struct {
+	Config        *config.Config
+	warehouseRepo db.WarehouseRepository
+	store         storage.Store
+	datasets      []warehouseDataset
+}
+
+// NewWarehouseService creates a new instance of WarehouseService. Files are
+// written privately to store under warehouse_prefix.
+func NewWarehouseService(warehouseRepo db.WarehouseRepository, store storage.Store, conf *config.Config) WarehouseService {
+	s := &warehouseService{
+		Config:        conf,
+		warehouseRepo: warehouseRepo,
+		store:         store,
+	}
+	s.datasets = []warehouseDataset{
+		{
+			name:    "reports",
+			version: 2,
+			schema:  parquet.SchemaOf(warehouseReport{}),
+			first:   warehouseRepo.FirstReportAt,
+			encode:  s.encodeReports,
+		},
+		{
+			name:    "engagement",
+			version: 2,
+			schema:  parquet.SchemaOf(warehouseActivity{}),
+			first:   warehouseRepo.FirstActivityAt,
+			encode:  s.encodeActivity,
+		},
+	}
+	return s
+}
+
+// ExportPending exports every complete UTC day of each dataset not yet
+// exported and returns how many partitions were written
+func (s *warehouseService) ExportPending() (int, error) {
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	written := 0
+	for _, dataset := range s.datasets {
+		if err := s.removeSuperseded(dataset); err != nil {
+			return written, fmt.Errorf("%s: %w", dataset.name, err)
+		}
+		day, err := s.nextDay(dataset)
+		if err != nil {
+			return written, fmt.Errorf("%s: %w", dataset.name, err)
+		}
+		for n := 0; day != nil && day.Before(today) && n < maxWarehouseDaysPerRun; n++ {
+			if err := s.exportDay(dataset, *day); err != nil {
+				return written, fmt.Errorf("%s %s: %w", dataset.name, day.Format("2006-01-02"), err)
+			}
+			written++
+			next := day.AddDate(0, 0, 1)
+			day = &next
+		}
+	}
+	return written, nil
+}
+
+// nextDay returns the first day of the dataset still to export, or nil
+// when it has no data at all
+func (s *warehouseService) nextDay(dataset warehouseDataset) (*time.Time, error) {
+	last, err := s.warehouseRepo.LastPartition(dataset.name, dataset.version)
+	if err != nil {
+		return nil, err
+	}
+	if last != nil {
+		next := time.Date(last.Year(), last.Month(), last.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 1)
+		return &next, nil
+	}
+	first, err := dataset.first()
+	if err != nil || first == nil {
+		return nil, err
+	}
+	f := first.UTC()
+	start := time.Date(f.Year(), f.Month(), f.Day(), 0, 0, 0, 0, time.UTC)
+	return &start, nil
+}
+
+// removeSuperseded deletes the files of a dataset's older schema versions.
+// Version 1 of both datasets carried exact coordinates and account IDs,
+// which must not outlive the retention and deletion the database applies.
+func (s *warehouseService) removeSuperseded(dataset warehouseDataset) error {
+	partitions, err := s.warehouseRepo.SupersededPartitions(dataset.name, dataset.version)
+	if err != nil {
+		return err
+	}
+	for _, partition := range partitions {
+		if partition.Key != "" {
+			if err := s.store.Delete(context.Background(), partition.Key); err != nil {
+				return err
+			}
+		}
+		if err := s.warehouseRepo.DeletePartition(partition.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *warehouseService) exportDay(dataset warehouseDataset, day time.Time) error {
+	var buf bytes.Buffer
+	rows, err := dataset.encode(&buf, day, day.AddDate(0, 0, 1))
+	if err != nil {
+		return err
+	}
+
+	partition := &models.WarehousePartition{
+		Dataset:       dataset.name,
+		SchemaVersion: dataset.version,
+		PartitionDate: day,
+		Rows:          rows,
+		ExportedAt:    time.Now(),
+	}
+	if rows > 0 {
+		partition.Key = path.Join(s.prefix(dataset), "dt="+day.Format("2006-01-02"), "part-00000.parquet")
+		partition.Bytes = int64(buf.Len())
+		if err := s.store.PutPrivate(context.Background(), partition.Key, &buf, partition.Bytes, "application/vnd.apache.parquet"); err != nil {
+			return err
+		}
+	}
+	return s.warehouseRepo.SavePartition(partition)
+}
+
+// prefix is where the files of a dataset's current schema version live,
+// laid out as Hive partitions: <prefix>/<dataset>/v<version>/dt=YYYY-MM-DD/
+func (s *warehouseService) prefix(dataset warehouseDataset) string {
+	return path.Join(strings.Trim(s.Config.WarehousePrefix, "/"), dataset.name, fmt.Sprintf("v%d", dataset.version))
+}
+
+func (s *warehouseService) encodeReports(buf *bytes.Buffer, start, end time.Time) (int64, error) {
+	reports, err := s.warehouseRepo.ReportsUpdatedBetween(start, end)
+	if err != nil || len(reports) == 0 {
+		return 0, err
+	}
+	var reporters []uint
+	for _, r := range reports {
+		if !r.UserIsAnonymous && r.UserID != 0 {
+			reporters = append(reporters, r.UserID)
+		}
+	}
+	pseudonyms, err := s.warehouseRepo.Pseudonyms(reporters)
+	if err != nil {
+		return 0, err
+	}
+
+	rows := make([]warehouseReport, len(reports))
+	for i, r := range reports {
+		status := r.ReportStatus
+		if status == "" {
+			status = "pending"
+		}
+		row := warehouseReport{
+			ID:           r.ID.String(),
+			CreatedAt:    time.Unix(r.CreatedAt, 0).UTC(),
+			UpdatedAt:    time.Unix(r.UpdatedAt, 0).UTC(),
+			IncidentDate: r.TimeofIncidence.UTC(),
+			Category:     r.Category,
+			SubCategory:  r.SubReportType,
+			State:        r.StateName,
+			LGA:          r.LGAName,
+			Ward:         r.WardName,
+			Latitude:     coarseCoordinate(r.Latitude),
+			Longitude:    coarseCoordinate(r.Longitude),
+			Status:       status,
+			Verified:     models.IsPublishedReportStatus(r.ReportStatus),
+			Anonymous:    r.UserIsAnonymous,
+			Upvotes:      int64(r.UpvoteCount),
+			Downvotes:    int64(r.DownvoteCount),
+			Views:        int64(r.View),
+		}
+		if reporter, ok := pseudonyms[r.UserID]; ok && !r.UserIsAnonymous {
+			row.Reporter = &reporter
+		}
+		if r.IncidentID != nil {
+			incident := r.IncidentID.String()
+			row.IncidentID = &incident
+		}
+		rows[i] = row
+	}
+	return int64(len(rows)), parquet.Write(buf, rows, parquet.Compression(&parquet.Snappy))
+}
+
+func (s *warehouseService) encodeActivity(buf *bytes.Buffer, start, end time.Time) (int64, error) {
+	activity, err := s.warehouseRepo.ActivityBetween(start, end)
+	if err != nil || len(activity) == 0 {
+		return 0, err
+	}
+	users := make([]uint, len(activity))
+	for i, a := range activity {
+		users[i] = a.UserID
+	}
+	pseudonyms, err := s.warehouseRepo.Pseudonyms(users)
+	if err != nil {
+		return 0, err
+	}
+
+	rows := make([]warehouseActivity, len(activity))
+	for i, a := range activity {
+		rows[i] = warehouseActivity{
+			ID:         int64(a.ID),
+			EventType:  a.EventType,
+			OccurredAt: a.OccurredAt.UTC(),
+			Metadata:   a.Metadata,
+		}
+		if user, ok := pseudonyms[a.UserID]; ok {
+			rows[i].User = &user
+		}
+	}
+	return int64(len(rows)), parquet.Write(buf, rows, parquet.Compression(&parquet.Snappy))
+}
+
+// Manifest describes each dataset's current schema and the partitions
+// exported so far
+func (s *warehouseService) Manifest() ([]models.WarehouseDataset, error) {
+	manifest := make([]models.WarehouseDataset, 0, len(s.datasets))
+	for _, dataset := range s.datasets {
+		partitions, err := s.warehouseRepo.ListPartitions(dataset.name, dataset.version)
+		if err != nil {
+			return nil, err
+		}
+		entry := models.WarehouseDataset{
+			Name:          dataset.name,
+			SchemaVersion: dataset.version,
+			Prefix:        s.prefix(dataset) + "/",
+			Partitions:    partitions,
+		}
+		for _, field := range dataset.schema.Fields() {
+			entry.Columns = append(entry.Columns, models.WarehouseColumn{
+				Name:     field.Name(),
+				Type:     field.Type().String(),
+				Optional: field.Optional(),
+			})
+		}
+		manifest = append(manifest, entry)
+	}
+	return manifest, nil
+}
@@ -0,0 +1,271 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/events"
+	"github.com/techagentng/citizenx/httpclient"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrWebhookNotFound is returned for subscriptions that do not exist.
+	ErrWebhookNotFound = errors.New("webhook subscription not found")
+	// ErrWebhookDeliveryNotFound is returned when replaying a delivery that
+	// does not exist or has not failed.
+	ErrWebhookDeliveryNotFound = errors.New("failed webhook delivery not found")
+	// ErrInvalidWebhook is returned for subscriptions to a URL that is not
+	// http or https, or to events that are not offered.
+	ErrInvalidWebhook = errors.New("a webhook needs an http or https URL and events from the offered list")
+)
+
+// WebhookEvents are the events subscribers can receive: the report's
+// lifecycle, from submission to removal
+var WebhookEvents = []string{
+	events.ReportCreatedEvent,
+	events.ReportVerifiedEvent,
+	events.StatusChangedEvent,
+	events.ReportAcknowledgedEvent,
+	events.ReportResolvedEvent,
+	events.ReportClosedEvent,
+	events.ReportWithdrawnEvent,
+	events.ReportEditedEvent,
+	events.ReportDeletedEvent,
+}
+
+const (
+	// webhookSecretPrefix starts every signing secret, so leaked secrets are
+	// easy to recognise
+	webhookSecretPrefix = "whsec_"
+	// webhookBatchSize is how many deliveries a dispatcher sends per
+	// transaction, and webhookInterval how often it looks for due ones
+	webhookBatchSize = 20
+	webhookInterval  = 5 * time.Second
+)
+
+//go:generate mockgen -destination=../mocks/webhook_mock.go -package=mocks github.com/techagentng/citizenx/services WebhookService
+
+// WebhookService sends report lifecycle events to partners' URLs. Events
+// are saved as deliveries, one per subscriber, which dispatchers send with
+// a signature the partner checks against its secret. Deliveries the
+// partner does not accept are retried with backoff and, once out of
+// attempts, can be replayed by an admin.
+type WebhookService interface {
+	CreateSubscription(request *models.WebhookSubscriptionRequest, adminID uint) (*models.WebhookSubscription, string, error)
+	ListSubscriptions() ([]models.WebhookSubscription, error)
+	DeleteSubscription(id uint) error
+	ListDeliveries(status string, subscriptionID uint, page int) ([]models.WebhookDelivery, error)
+	ReplayDelivery(id uint) error
+	Subscribe(bus events.Bus)
+	Run(ctx context.Context)
+}
+
+type webhookService struct {
+	Config      *config.Config
+	webhookRepo db.WebhookRepository
+	http        *http.Client
+}
+
+// webhookPayload is the body POSTed to subscribers
+type webhookPayload struct {
+	Event string       `json:"event"`
+	ID    string       `json:"id"` // the same for every delivery of the event
+	Data  events.Event `json:"data"`
+}
+
+// NewWebhookService creates a new instance of WebhookService
+func NewWebhookService(webhookRepo db.WebhookRepository, conf *config.Config) WebhookService {
+	return &webhookService{
+		Config:      conf,
+		webhookRepo: webhookRepo,
+		// Failed deliveries are retried from the delivery log
+		http: httpclient.New("webhooks", httpclient.Options{Timeout: 10 * time.Second, Retries: -1}),
+	}
+}
+
+// CreateSubscription records a subscription and returns the secret its
+// payloads are signed with, which cannot be shown again
+func (s *webhookService) CreateSubscription(request *models.WebhookSubscriptionRequest, adminID uint) (*models.WebhookSubscription, string, error) {
+	target, err := url.Parse(strings.TrimSpace(request.URL))
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, "", ErrInvalidWebhook
+	}
+	for _, event := range request.Events {
+		if !offeredWebhookEvent(event) {
+			return nil, "", ErrInvalidWebhook
+		}
+	}
+	random := make([]byte, 32)
+	if _, err := rand.Read(random); err != nil {
+		return nil, "", err
+	}
+	secret := webhookSecretPrefix + base64.RawURLEncoding.EncodeToString(random)
+	subscription := &models.WebhookSubscription{
+		Name:      strings.TrimSpace(request.Name),
+		URL:       target.String(),
+		Secret:    secret,
+		Events:    request.Events,
+		CreatedBy: adminID,
+	}
+	if err := s.webhookRepo.CreateSubscription(subscription); err != nil {
+		return nil, "", err
+	}
+	return subscription, secret, nil
+}
+
+func offeredWebhookEvent(name string) bool {
+	for _, event := range WebhookEvents {
+		if event == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *webhookService) ListSubscriptions() ([]models.WebhookSubscription, error) {
+	return s.webhookRepo.ListSubscriptions()
+}
+
+func (s *webhookService) DeleteSubscription(id uint) error {
+	err := s.webhookRepo.DeleteSubscription(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrWebhookNotFound
+	}
+	return err
+}
+
+func (s *webhookService) ListDeliveries(status string, subscriptionID uint, page int) ([]models.WebhookDelivery, error) {
+	return s.webhookRepo.ListDeliveries(status, subscriptionID, page, db.DefaultPageSize)
+}
+
+func (s *webhookService) ReplayDelivery(id uint) error {
+	err := s.webhookRepo.ReplayDelivery(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrWebhookDeliveryNotFound
+	}
+	return err
+}
+
+// Subscribe registers the service for the events subscribers can receive
+func (s *webhookService) Subscribe(bus events.Bus) {
+	for _, name := range WebhookEvents {
+		bus.Subscribe(name, s.queue)
+	}
+}
+
+// queue saves a delivery of event for each subscriber that wants it
+func (s *webhookService) queue(ctx context.Context, event events.Event) error {
+	subscriptions, err := s.webhookRepo.ListSubscriptions()
+	if err != nil || len(subscriptions) == 0 {
+		return err
+	}
+	payload, err := json.Marshal(webhookPayload{
+		Event: event.EventName(),
+		ID:    event.DedupKey(),
+		Data:  event,
+	})
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	var deliveries []models.WebhookDelivery
+	for i := range subscriptions {
+		if !subscriptions[i].Wants(event.EventName()) {
+			continue
+		}
+		deliveries = append(deliveries, models.WebhookDelivery{
+			SubscriptionID: subscriptions[i].ID,
+			EventName:      event.EventName(),
+			DedupKey:       event.DedupKey(),
+			Payload:        string(payload),
+			Status:         models.WebhookPending,
+			NextAttemptAt:  now,
+		})
+	}
+	return s.webhookRepo.QueueDeliveries(deliveries)
+}
+
+// Run sends due deliveries until ctx is cancelled. Several can run side by
+// side, each sending different deliveries.
+func (s *webhookService) Run(ctx context.Context) {
+	ticker := time.NewTicker(webhookInterval)
+	defer ticker.Stop()
+	for {
+		for ctx.Err() == nil {
+			sent, err := s.webhookRepo.DeliverPending(webhookBatchSize, func(delivery *models.WebhookDelivery, subscription *models.WebhookSubscription) (int, error) {
+				return s.send(ctx, delivery, subscription)
+			})
+			if err != nil {
+				log.Printf("delivering webhooks: %v", err)
+				break
+			}
+			if sent < webhookBatchSize {
+				break
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// send POSTs a delivery, returning the response status. Anything but a 2xx
+// response is an error.
+func (s *webhookService) send(ctx context.Context, delivery *models.WebhookDelivery, subscription *models.WebhookSubscription) (int, error) {
+	body := []byte(delivery.Payload)
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "CitizenX-Webhooks/1.0")
+	req.Header.Set("X-CitizenX-Event", delivery.EventName)
+	req.Header.Set("X-CitizenX-Delivery", strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set("X-CitizenX-Timestamp", timestamp)
+	req.Header.Set("X-CitizenX-Signature", "sha256="+SignWebhook(subscription.Secret, timestamp, body))
+
+	resp, err := s.http.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("subscriber answered %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// SignWebhook returns the hex HMAC-SHA256 of timestamp, a dot and body under
+// secret. Subscribers compute the same from the X-CitizenX-Timestamp header
+// and the raw body, compare it with X-CitizenX-Signature and reject old
+// timestamps so a captured request cannot be replayed.
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
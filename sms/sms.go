@@ -0,0 +1,73 @@
+// Package sms sends text messages to phone numbers.
+package sms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/techagentng/citizenx/httpclient"
+)
+
+// Sender sends one text message.
+type Sender interface {
+	// Send delivers message to a phone number in international format,
+	// such as +2348012345678
+	Send(ctx context.Context, to, message string) error
+}
+
+// Termii sends messages with the Termii API, which reaches every Nigerian
+// network.
+type Termii struct {
+	apiKey   string
+	senderID string
+	http     *http.Client
+}
+
+// NewTermii returns a sender for the Termii account, or nil when no API key
+// is configured. senderID is the registered name messages come from.
+func NewTermii(apiKey, senderID string) *Termii {
+	if apiKey == "" {
+		return nil
+	}
+	return &Termii{apiKey: apiKey, senderID: senderID, http: httpclient.New("termii", httpclient.Options{Timeout: 10 * time.Second})}
+}
+
+func (t *Termii) Send(ctx context.Context, to, message string) error {
+	body, err := json.Marshal(map[string]string{
+		"api_key": t.apiKey,
+		// Termii takes numbers without the leading plus
+		"to":      trimPlus(to),
+		"from":    t.senderID,
+		"sms":     message,
+		"type":    "plain",
+		"channel": "dnd", // reaches numbers on the do-not-disturb list
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.ng.termii.com/api/sms/send", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := t.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("sending sms: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("sending sms: unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+func trimPlus(number string) string {
+	if len(number) > 0 && number[0] == '+' {
+		return number[1:]
+	}
+	return number
+}
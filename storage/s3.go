@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/techagentng/citizenx/config"
+)
+
+// S3 stores objects in an S3 bucket. Public objects are public-read,
+// matching the media uploaded before the store existed; private objects take
+// the bucket's default ACL and are read through presigned URLs.
+type S3 struct {
+	client  *s3.Client
+	presign *s3.PresignClient
+	bucket  string
+	region  string
+	// endpoint is set for S3-compatible stores, which are addressed by path
+	endpoint string
+}
+
+// NewS3 returns a store for the configured bucket. With an endpoint
+// configured it talks to an S3-compatible store, such as MinIO in
+// development and tests, instead of AWS.
+func NewS3(conf *config.Config) (*S3, error) {
+	cfg, err := awsconfig.LoadDefaultConfig(context.Background(),
+		awsconfig.WithRegion(conf.AWS_REGION),
+		awsconfig.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
+			conf.AWS_ACCESS_KEY_ID,
+			conf.AWS_SECRET_ACCESS_KEY,
+			"",
+		)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("loading AWS config: %w", err)
+	}
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if conf.AWS_ENDPOINT != "" {
+			o.BaseEndpoint = aws.String(conf.AWS_ENDPOINT)
+			o.UsePathStyle = true
+		}
+	})
+	return &S3{
+		client:   client,
+		presign:  s3.NewPresignClient(client),
+		bucket:   conf.AWS_BUCKET,
+		region:   conf.AWS_REGION,
+		endpoint: strings.TrimRight(conf.AWS_ENDPOINT, "/"),
+	}, nil
+}
+
+func (s *S3) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) (string, error) {
+	if err := s.put(ctx, key, body, size, contentType, types.ObjectCannedACLPublicRead); err != nil {
+		return "", err
+	}
+	if s.endpoint != "" {
+		return fmt.Sprintf("%s/%s/%s", s.endpoint, s.bucket, key), nil
+	}
+	return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", s.bucket, s.region, key), nil
+}
+
+func (s *S3) PutPrivate(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	return s.put(ctx, key, body, size, contentType, types.ObjectCannedACLPrivate)
+}
+
+func (s *S3) put(ctx context.Context, key string, body io.Reader, size int64, contentType string, acl types.ObjectCannedACL) error {
+	input := &s3.PutObjectInput{
+		Bucket:        aws.String(s.bucket),
+		Key:           aws.String(key),
+		Body:          body,
+		ContentLength: aws.Int64(size),
+		ACL:           acl,
+	}
+	if contentType != "" {
+		input.ContentType = aws.String(contentType)
+	}
+	if _, err := s.client.PutObject(ctx, input); err != nil {
+		return fmt.Errorf("uploading %s: %w", key, err)
+	}
+	return nil
+}
+
+// SignedURL presigns a GET of the object valid for ttl.
+func (s *S3) SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	req, err := s.presign.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(ttl))
+	if err != nil {
+		return "", fmt.Errorf("signing %s: %w", key, err)
+	}
+	return req.URL, nil
+}
+
+func (s *S3) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	var missing *types.NoSuchKey
+	if errors.As(err, &missing) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("downloading %s: %w", key, err)
+	}
+	return out.Body, nil
+}
+
+// Delete removes the object. S3 does not report deleting a missing key, so
+// ErrNotFound is never returned.
+func (s *S3) Delete(ctx context.Context, key string) error {
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(key),
+	})
+	return err
+}
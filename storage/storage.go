@@ -0,0 +1,47 @@
+// Package storage keeps uploaded objects in S3 or, for development, on the
+// local disk behind one interface.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/techagentng/citizenx/config"
+)
+
+var (
+	// ErrNotFound is returned when reading or deleting a key that does not exist.
+	ErrNotFound = errors.New("object not found")
+	// ErrInvalidSignature is returned for signed URLs that were altered or
+	// have expired.
+	ErrInvalidSignature = errors.New("invalid or expired signature")
+)
+
+// Store saves and serves objects by key. Keys are slash separated paths such
+// as "videos/abc.mp4".
+type Store interface {
+	// Put stores a publicly readable object and returns the URL it is
+	// served from.
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) (string, error)
+	// PutPrivate stores an object that can only be read through a signed URL.
+	PutPrivate(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	// SignedURL returns a URL that serves a private object until ttl passes.
+	SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// New returns the store selected by the configuration: S3 when a bucket is
+// configured and the local disk otherwise.
+func New(conf *config.Config) (Store, error) {
+	if conf.AWS_BUCKET != "" {
+		return NewS3(conf)
+	}
+	secret := conf.MediaSigningSecret
+	if secret == "" {
+		secret = conf.JWTSecret
+	}
+	return NewLocal(conf.StorageDir, secret, conf.PrivateMediaBaseURL), nil
+}
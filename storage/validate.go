@@ -0,0 +1,187 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/techagentng/citizenx/config"
+)
+
+// Kind is the broad type of an uploaded file.
+type Kind string
+
+// Kinds of media accepted for upload.
+const (
+	KindImage Kind = "image"
+	KindVideo Kind = "video"
+	KindAudio Kind = "audio"
+)
+
+// sniffedKinds maps the content types http.DetectContentType reports to the
+// kind of media they are. Anything else is rejected.
+var sniffedKinds = map[string]Kind{
+	"image/jpeg":      KindImage,
+	"image/png":       KindImage,
+	"image/gif":       KindImage,
+	"video/mp4":       KindVideo,
+	"video/avi":       KindVideo,
+	"video/quicktime": KindVideo,
+	"audio/mpeg":      KindAudio,
+	"audio/wav":       KindAudio,
+	"audio/ogg":       KindAudio,
+	"audio/flac":      KindAudio,
+	"application/ogg": KindAudio,
+}
+
+// Validation error codes.
+const (
+	CodeUnsupportedType = "unsupported_type"
+	CodeTypeMismatch    = "type_mismatch"
+	CodeTooLarge        = "too_large"
+	CodeEmpty           = "empty"
+	CodeBadDimensions   = "bad_dimensions"
+	CodeCorrupt         = "corrupt"
+)
+
+// ValidationError explains why an upload was rejected. Code is one of the
+// Code constants, for clients to act on; Message is for people.
+type ValidationError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Filename is the name of the rejected file, when known
+	Filename string `json:"filename,omitempty"`
+}
+
+func (e *ValidationError) Error() string {
+	if e.Filename != "" {
+		return e.Filename + ": " + e.Message
+	}
+	return e.Message
+}
+
+func invalid(code, format string, args ...interface{}) *ValidationError {
+	return &ValidationError{Code: code, Message: fmt.Sprintf(format, args...)}
+}
+
+// Limits are the size and dimension caps uploads are checked against.
+type Limits struct {
+	MaxImageBytes int64
+	MaxVideoBytes int64
+	MaxAudioBytes int64
+	// MaxImageDimension caps the width and height of an image
+	MaxImageDimension int
+	// MaxImagePixels caps width times height, guarding against images that
+	// are small on disk but enormous once decoded
+	MaxImagePixels int
+}
+
+// LimitsFromConfig returns the configured upload limits.
+func LimitsFromConfig(conf *config.Config) Limits {
+	return Limits{
+		MaxImageBytes:     conf.MaxImageUploadSize,
+		MaxVideoBytes:     conf.MaxVideoUploadSize,
+		MaxAudioBytes:     conf.MaxAudioUploadSize,
+		MaxImageDimension: conf.MaxImageDimension,
+		MaxImagePixels:    conf.MaxImagePixels,
+	}
+}
+
+// SniffKind returns the content type detected from the first bytes of data
+// and the kind of media it is, or "" when it is not accepted media.
+func SniffKind(data []byte) (string, Kind) {
+	contentType := http.DetectContentType(data)
+	return contentType, sniffedKinds[contentType]
+}
+
+// KindOf returns the kind a declared content type claims, or "" when the
+// type is missing, generic or not media.
+func KindOf(contentType string) Kind {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	switch {
+	case strings.HasPrefix(mediaType, "image/"):
+		return KindImage
+	case strings.HasPrefix(mediaType, "video/"):
+		return KindVideo
+	case strings.HasPrefix(mediaType, "audio/"), mediaType == "application/ogg":
+		return KindAudio
+	}
+	return ""
+}
+
+// CheckSize rejects a file too large for its kind. It lets resumable uploads
+// be refused before any bytes are sent.
+func (l Limits) CheckSize(kind Kind, size int64) error {
+	var max int64
+	switch kind {
+	case KindImage:
+		max = l.MaxImageBytes
+	case KindVideo:
+		max = l.MaxVideoBytes
+	case KindAudio:
+		max = l.MaxAudioBytes
+	}
+	if max > 0 && size > max {
+		return invalid(CodeTooLarge, "%s files may be at most %d bytes", kind, max)
+	}
+	return nil
+}
+
+// Validate checks an upload and returns its kind. The content is sniffed
+// rather than trusted: it must be accepted media of one of the allowed
+// kinds (any kind when none are given), agree with the declared content
+// type when one is given, fit the size cap for its kind, and images must
+// decode to sensible dimensions. Failures are *ValidationError.
+func (l Limits) Validate(data []byte, declaredType string, allowed ...Kind) (Kind, error) {
+	if len(data) == 0 {
+		return "", invalid(CodeEmpty, "file is empty")
+	}
+	sniffed, kind := SniffKind(data)
+	if kind == "" {
+		return "", invalid(CodeUnsupportedType, "unsupported file type %s", sniffed)
+	}
+	if len(allowed) > 0 && !containsKind(allowed, kind) {
+		return "", invalid(CodeUnsupportedType, "%s files are not accepted here", kind)
+	}
+	if declared := KindOf(declaredType); declared != "" && declared != kind {
+		return "", invalid(CodeTypeMismatch, "file was declared as %s but contains %s", declaredType, sniffed)
+	}
+	if err := l.CheckSize(kind, int64(len(data))); err != nil {
+		return "", err
+	}
+
+	if kind == KindImage {
+		cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return "", invalid(CodeCorrupt, "image could not be read")
+		}
+		if cfg.Width <= 0 || cfg.Height <= 0 {
+			return "", invalid(CodeBadDimensions, "image has no pixels")
+		}
+		if l.MaxImageDimension > 0 && (cfg.Width > l.MaxImageDimension || cfg.Height > l.MaxImageDimension) {
+			return "", invalid(CodeBadDimensions, "image is %dx%d, larger than %d pixels on a side", cfg.Width, cfg.Height, l.MaxImageDimension)
+		}
+		if l.MaxImagePixels > 0 && cfg.Width*cfg.Height > l.MaxImagePixels {
+			return "", invalid(CodeBadDimensions, "image has more than %d pixels", l.MaxImagePixels)
+		}
+	}
+	return kind, nil
+}
+
+func containsKind(kinds []Kind, kind Kind) bool {
+	for _, k := range kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
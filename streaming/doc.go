@@ -0,0 +1,56 @@
+// Package streaming publishes domain events to Kafka or NATS JetStream so the
+// analytics pipeline can consume them without querying the production
+// database. It is enabled by setting CITIZENX_STREAM_BACKEND to "kafka" or
+// "nats" and CITIZENX_STREAM_BROKERS to a comma separated list of broker
+// addresses (Kafka) or server URLs (NATS).
+//
+// # Message schema
+//
+// Every message body is a JSON envelope:
+//
+//	{
+//	  "id":           "report.created:0b9e6d1c-...", // stable across redeliveries
+//	  "type":         "report.created",
+//	  "version":      1,
+//	  "published_at": "2024-05-01T09:30:12Z",
+//	  "data":         { ... }
+//	}
+//
+// Delivery is at least once; consumers deduplicate on id. version changes
+// only when a field is removed or changes meaning; new fields may appear in
+// data at any time. The data fields for each type are:
+//
+//	report.created     report_id, user_id, category, state_name, lga_name,
+//	                   latitude, longitude, occurred_at
+//	report.verified    report_id, user_id, status, occurred_at
+//	report.voted       report_id, user_id, vote_type ("upvote", "downvote" or
+//	                   "retracted"), occurred_at
+//	report.bookmarked  report_id, user_id, occurred_at
+//	report.closed      report_id, user_id, previous_status, rule_id,
+//	                   after_days, occurred_at
+//	report.acknowledged
+//	                   report_id, user_id, agency_id, note, occurred_at
+//	report.resolved    report_id, user_id, agency_id, note, evidence,
+//	                   occurred_at
+//	report.withdrawn   report_id, user_id, reason, occurred_at
+//	report.edited      report_id, user_id, version, fields, occurred_at
+//	report.information_requested
+//	                   request_id, report_id, user_id, moderator_id, fields,
+//	                   occurred_at
+//	report.information_provided
+//	                   request_id, report_id, user_id, fields, occurred_at
+//	report.collaborator_added
+//	                   report_id, user_id, owner_id, occurred_at
+//	comment.added      comment_id, report_id, report_owner_id, user_id,
+//	                   occurred_at
+//	reward.earned      user_id, report_id, reward_type, points, occurred_at
+//
+// # Routing
+//
+// On Kafka every event is written to the topic CITIZENX_STREAM_TOPIC
+// (default "citizenx.events") with the id as message key and the type in
+// the "type" header. On NATS each event is published to the subject
+// "<topic>.<type>", for example "citizenx.events.report.created", with the
+// id as Nats-Msg-Id so JetStream discards duplicates within its window. The
+// stream capturing those subjects is provisioned outside the application.
+package streaming
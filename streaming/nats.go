@@ -0,0 +1,36 @@
+package streaming
+
+import (
+	"context"
+	"strings"
+
+	"github.com/nats-io/nats.go"
+)
+
+type natsPublisher struct {
+	conn    *nats.Conn
+	js      nats.JetStreamContext
+	subject string
+}
+
+func newNATSPublisher(servers []string, subject string) (*natsPublisher, error) {
+	conn, err := nats.Connect(strings.Join(servers, ","), nats.Name("citizenx"))
+	if err != nil {
+		return nil, err
+	}
+	js, err := conn.JetStream()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &natsPublisher{conn: conn, js: js, subject: subject}, nil
+}
+
+func (p *natsPublisher) Publish(ctx context.Context, msg Message) error {
+	_, err := p.js.Publish(p.subject+"."+msg.Type, msg.Body, nats.MsgId(msg.ID), nats.Context(ctx))
+	return err
+}
+
+func (p *natsPublisher) Close() error {
+	return p.conn.Drain()
+}
@@ -0,0 +1,79 @@
+package streaming
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/events"
+)
+
+// SchemaVersion is the version of the envelope and data fields documented
+// in the package comment.
+const SchemaVersion = 1
+
+// Envelope wraps an event for publishing.
+type Envelope struct {
+	ID          string       `json:"id"`
+	Type        string       `json:"type"`
+	Version     int          `json:"version"`
+	PublishedAt time.Time    `json:"published_at"`
+	Data        events.Event `json:"data"`
+}
+
+// Message is an encoded event ready to hand to a broker.
+type Message struct {
+	ID   string
+	Type string
+	Body []byte
+}
+
+// Publisher writes messages to a streaming backend.
+type Publisher interface {
+	Publish(ctx context.Context, msg Message) error
+	Close() error
+}
+
+// New returns the publisher configured by conf, or nil when streaming is
+// not enabled.
+func New(conf *config.Config) (Publisher, error) {
+	brokers := strings.Split(conf.StreamBrokers, ",")
+	switch conf.StreamBackend {
+	case "":
+		return nil, nil
+	case "kafka":
+		return newKafkaPublisher(brokers, conf.StreamTopic), nil
+	case "nats":
+		return newNATSPublisher(brokers, conf.StreamTopic)
+	}
+	return nil, fmt.Errorf("unknown stream backend %q", conf.StreamBackend)
+}
+
+// Encode wraps event in the versioned envelope.
+func Encode(event events.Event) (Message, error) {
+	body, err := json.Marshal(Envelope{
+		ID:          event.DedupKey(),
+		Type:        event.EventName(),
+		Version:     SchemaVersion,
+		PublishedAt: time.Now().UTC(),
+		Data:        event,
+	})
+	if err != nil {
+		return Message{}, err
+	}
+	return Message{ID: event.DedupKey(), Type: event.EventName(), Body: body}, nil
+}
+
+// Subscriber returns an event bus handler forwarding every event to p.
+func Subscriber(p Publisher) events.Handler {
+	return func(ctx context.Context, event events.Event) error {
+		msg, err := Encode(event)
+		if err != nil {
+			return err
+		}
+		return p.Publish(ctx, msg)
+	}
+}
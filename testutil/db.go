@@ -0,0 +1,83 @@
+// Package testutil provides database fixtures for integration tests.
+package testutil
+
+import (
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/techagentng/citizenx/db"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// DatabaseURLEnv names the environment variable holding the DSN of the
+// Postgres database integration tests run against.
+const DatabaseURLEnv = "CITIZENX_TEST_DATABASE_URL"
+
+var (
+	openOnce sync.Once
+	testDB   *gorm.DB
+	openErr  error
+)
+
+// DB returns a migrated connection to the test database, shared by every test
+// in the binary. The calling test is skipped when no database is configured.
+func DB(t testing.TB) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv(DatabaseURLEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set, skipping integration test", DatabaseURLEnv)
+	}
+
+	openOnce.Do(func() {
+		testDB, openErr = gorm.Open(postgres.Open(dsn), &gorm.Config{
+			Logger: logger.Default.LogMode(logger.Silent),
+		})
+		if openErr != nil {
+			return
+		}
+		if openErr = testDB.Exec(`CREATE EXTENSION IF NOT EXISTS "uuid-ossp"`).Error; openErr != nil {
+			return
+		}
+		if openErr = db.Migrate(testDB); openErr != nil {
+			return
+		}
+		openErr = db.SeedRoles(testDB)
+	})
+	if openErr != nil {
+		t.Fatalf("opening test database: %v", openErr)
+	}
+	return testDB
+}
+
+// Tx begins a transaction on the test database and rolls it back once the
+// test and its subtests have finished, so nothing a test writes outlives it.
+// The returned GormDB can be handed straight to the repository constructors.
+func Tx(t testing.TB) *db.GormDB {
+	t.Helper()
+
+	tx := DB(t).Begin()
+	if tx.Error != nil {
+		t.Fatalf("beginning test transaction: %v", tx.Error)
+	}
+	t.Cleanup(func() {
+		tx.Rollback()
+	})
+	return &db.GormDB{DB: tx}
+}
+
+// WithTx runs fn inside a transaction that is rolled back when fn returns.
+func WithTx(t *testing.T, fn func(gormDB *db.GormDB)) {
+	t.Helper()
+
+	tx := DB(t).Begin()
+	if tx.Error != nil {
+		t.Fatalf("beginning test transaction: %v", tx.Error)
+	}
+	defer tx.Rollback()
+
+	fn(&db.GormDB{DB: tx})
+}
@@ -0,0 +1,89 @@
+package e2e
+
+import (
+	"context"
+	"sync"
+)
+
+// Email is a message the fake mailer was asked to send
+type Email struct {
+	To      string
+	Subject string
+	Body    string
+	HTML    string
+	Headers map[string]string
+}
+
+// FakeMailer records email instead of sending it through Mailgun
+type FakeMailer struct {
+	mu   sync.Mutex
+	sent []Email
+}
+
+func (m *FakeMailer) SendSimpleMessage(to, subject, body string) (string, error) {
+	return m.record(Email{To: to, Subject: subject, Body: body})
+}
+
+func (m *FakeMailer) SendHTMLMessage(to, subject, text, html string, headers map[string]string) (string, error) {
+	return m.record(Email{To: to, Subject: subject, Body: text, HTML: html, Headers: headers})
+}
+
+func (m *FakeMailer) SendVerifyAccount(to, link string) (string, error) {
+	return m.record(Email{To: to, Subject: "Verify your account", Body: link})
+}
+
+func (m *FakeMailer) SendResetPassword(to, link string) (string, error) {
+	return m.record(Email{To: to, Subject: "Reset your password", Body: link})
+}
+
+func (m *FakeMailer) record(email Email) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sent = append(m.sent, email)
+	return "fake-message-id", nil
+}
+
+// SentTo returns the email sent to an address, oldest first
+func (m *FakeMailer) SentTo(to string) []Email {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var sent []Email
+	for _, email := range m.sent {
+		if email.To == to {
+			sent = append(sent, email)
+		}
+	}
+	return sent
+}
+
+// SMS is a text message the fake provider was asked to send
+type SMS struct {
+	To      string
+	Message string
+}
+
+// FakeSMS records text messages instead of sending them through Termii
+type FakeSMS struct {
+	mu   sync.Mutex
+	sent []SMS
+}
+
+func (s *FakeSMS) Send(ctx context.Context, to, message string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sent = append(s.sent, SMS{To: to, Message: message})
+	return nil
+}
+
+// SentTo returns the messages sent to a phone number, oldest first
+func (s *FakeSMS) SentTo(to string) []SMS {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var sent []SMS
+	for _, message := range s.sent {
+		if message.To == to {
+			sent = append(sent, message)
+		}
+	}
+	return sent
+}
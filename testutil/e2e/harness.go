@@ -0,0 +1,251 @@
+// Package e2e runs the whole API server against the test database for
+// end-to-end tests. Mail and SMS go to in-memory fakes, and uploads go to
+// MinIO when one is configured or to a temporary directory otherwise. The
+// services in docker-compose.test.yml provide the database and MinIO.
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/techagentng/citizenx/bootstrap"
+	"github.com/techagentng/citizenx/config"
+	"github.com/techagentng/citizenx/db"
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/services"
+	"github.com/techagentng/citizenx/storage"
+	"github.com/techagentng/citizenx/testutil"
+	"gorm.io/gorm"
+)
+
+// S3EndpointEnv names the environment variable holding the URL of the MinIO
+// server uploads are stored in. Its bucket is named by S3BucketEnv, or
+// citizenx-test, and must already exist.
+const (
+	S3EndpointEnv = "CITIZENX_TEST_S3_ENDPOINT"
+	S3BucketEnv   = "CITIZENX_TEST_S3_BUCKET"
+)
+
+// testPassword is the password of every user the harness signs in
+const testPassword = "correct-horse-battery"
+
+// Server is the API server under test, listening on a local port
+type Server struct {
+	*httptest.Server
+	App    *bootstrap.App
+	DB     *gorm.DB
+	Config *config.Config
+	Mail   *FakeMailer
+	SMS    *FakeSMS
+}
+
+// NewServer starts the server on the test database, skipping the calling
+// test when no database is configured. configure may adjust the
+// configuration before the server is wired. The server and its workers are
+// stopped when the test finishes.
+func NewServer(t *testing.T, configure ...func(*config.Config)) *Server {
+	t.Helper()
+
+	gormDB := testutil.DB(t)
+
+	conf := &config.Config{}
+	if err := envconfig.Process("citizenx", conf); err != nil {
+		t.Fatalf("loading configuration: %v", err)
+	}
+	conf.Env = "test"
+	conf.JWTSecret = "e2e-secret"
+	conf.NotificationBatchSeconds = 0
+	conf.SearchURL = ""
+	conf.StreamBackend = ""
+	conf.StorageDir = t.TempDir()
+	conf.AWS_BUCKET = ""
+	if endpoint := os.Getenv(S3EndpointEnv); endpoint != "" {
+		conf.AWS_ENDPOINT = endpoint
+		conf.AWS_BUCKET = os.Getenv(S3BucketEnv)
+		if conf.AWS_BUCKET == "" {
+			conf.AWS_BUCKET = "citizenx-test"
+		}
+		conf.AWS_REGION = "us-east-1"
+		conf.AWS_ACCESS_KEY_ID = "minioadmin"
+		conf.AWS_SECRET_ACCESS_KEY = "minioadmin"
+	}
+	for _, fn := range configure {
+		fn(conf)
+	}
+
+	store, err := storage.New(conf)
+	if err != nil {
+		t.Fatalf("opening storage: %v", err)
+	}
+	s := &Server{
+		DB:     gormDB,
+		Config: conf,
+		Mail:   &FakeMailer{},
+		SMS:    &FakeSMS{},
+	}
+	s.App, err = bootstrap.New(conf, &db.GormDB{DB: gormDB}, bootstrap.Providers{
+		Mail:  s.Mail,
+		SMS:   s.SMS,
+		Store: store,
+	})
+	if err != nil {
+		t.Fatalf("wiring server: %v", err)
+	}
+	s.Server = httptest.NewServer(s.App.Handler())
+	t.Cleanup(func() {
+		s.Server.Close()
+		s.App.Close()
+	})
+	return s
+}
+
+// SignIn creates a user with the role and signs them in through the API,
+// returning the user and their access token. Current legal documents are
+// accepted for them so the consent check lets them through.
+func (s *Server) SignIn(t *testing.T, role string, overrides ...func(*models.User)) (*models.User, string) {
+	t.Helper()
+
+	var found models.Role
+	if err := s.DB.Where("name = ?", role).First(&found).Error; err != nil {
+		t.Fatalf("loading %s role: %v", role, err)
+	}
+	hashed, err := services.GenerateHashPassword(testPassword)
+	if err != nil {
+		t.Fatalf("hashing password: %v", err)
+	}
+	overrides = append([]func(*models.User){func(u *models.User) {
+		u.RoleID = found.ID
+		u.HashedPassword = hashed
+	}}, overrides...)
+	user := testutil.CreateUser(t, s.DB, overrides...)
+
+	documents, err := s.App.Server.LegalService.Current()
+	if err != nil {
+		t.Fatalf("loading legal documents: %v", err)
+	}
+	if len(documents) > 0 {
+		ids := make([]uint, len(documents))
+		for i, document := range documents {
+			ids[i] = document.ID
+		}
+		if err := s.App.Server.LegalService.Accept(user.ID, ids, "127.0.0.1"); err != nil {
+			t.Fatalf("accepting legal documents: %v", err)
+		}
+	}
+
+	var login models.LoginResponse
+	s.JSON(t, s.Do(t, http.MethodPost, "/api/v1/auth/login", "", JSONBody(t, models.LoginRequest{
+		Email:    user.Email,
+		Password: testPassword,
+	})), http.StatusOK, &login)
+	return user, login.AccessToken
+}
+
+// Request is a request body with its content type
+type Request struct {
+	Body        io.Reader
+	ContentType string
+}
+
+// JSONBody encodes v as a JSON request body
+func JSONBody(t *testing.T, v interface{}) *Request {
+	t.Helper()
+	body, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("encoding request: %v", err)
+	}
+	return &Request{Body: bytes.NewReader(body), ContentType: "application/json"}
+}
+
+// FormBody encodes fields as a multipart form, the way the apps submit
+// reports
+func FormBody(t *testing.T, fields map[string]string) *Request {
+	t.Helper()
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	for name, value := range fields {
+		if err := form.WriteField(name, value); err != nil {
+			t.Fatalf("writing form field %s: %v", name, err)
+		}
+	}
+	if err := form.Close(); err != nil {
+		t.Fatalf("closing form: %v", err)
+	}
+	return &Request{Body: &body, ContentType: form.FormDataContentType()}
+}
+
+// Do sends a request to the server, signed in with token unless it is
+// empty. The response body is closed when the test finishes.
+func (s *Server) Do(t *testing.T, method, path, token string, body *Request) *http.Response {
+	t.Helper()
+
+	var reader io.Reader
+	if body != nil {
+		reader = body.Body
+	}
+	req, err := http.NewRequest(method, s.URL+path, reader)
+	if err != nil {
+		t.Fatalf("building %s %s: %v", method, path, err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", body.ContentType)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := s.Client().Do(req)
+	if err != nil {
+		t.Fatalf("%s %s: %v", method, path, err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+// JSON checks the response status and decodes the data of the response
+// envelope into data, when it is not nil
+func (s *Server) JSON(t *testing.T, resp *http.Response, status int, data interface{}) {
+	t.Helper()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("reading response: %v", err)
+	}
+	if resp.StatusCode != status {
+		t.Fatalf("%s %s: got status %d, want %d: %s", resp.Request.Method, resp.Request.URL.Path, resp.StatusCode, status, body)
+	}
+	if data == nil {
+		return
+	}
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		t.Fatalf("decoding response: %v: %s", err, body)
+	}
+	if err := json.Unmarshal(envelope.Data, data); err != nil {
+		t.Fatalf("decoding response data: %v: %s", err, envelope.Data)
+	}
+}
+
+// Eventually calls check until it returns true, failing the test if it does
+// not within timeout. Events are delivered by background workers, so their
+// effects are only eventually visible.
+func Eventually(t *testing.T, timeout time.Duration, what string, check func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(timeout)
+	for !check() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out after %s waiting for %s", timeout, what)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
@@ -0,0 +1,60 @@
+package e2e_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/techagentng/citizenx/models"
+	"github.com/techagentng/citizenx/testutil/e2e"
+)
+
+// TestReportModerationNotifies follows a report from submission through
+// approval by a moderator to the reporter hearing about it in the app and
+// by email.
+func TestReportModerationNotifies(t *testing.T) {
+	s := e2e.NewServer(t)
+	reporter, reporterToken := s.SignIn(t, models.RoleUser)
+	_, adminToken := s.SignIn(t, models.RoleAdmin)
+
+	var submitted struct {
+		ReportID string `json:"reportID"`
+	}
+	s.JSON(t, s.Do(t, http.MethodPost, "/api/v1/user/report/", reporterToken, e2e.FormBody(t, map[string]string{
+		"category":          "Roads",
+		"sub_report_type":   "Pothole",
+		"description":       "A pothole has opened across both lanes",
+		"state_name":        "Lagos",
+		"lga_name":          "Ikeja",
+		"latitude":          "6.6018",
+		"longitude":         "3.3515",
+		"rating":            "bad",
+		"date_of_incidence": time.Now().Format("2006-01-02"),
+	})), http.StatusCreated, &submitted)
+	if submitted.ReportID == "" {
+		t.Fatal("submitting the report returned no report ID")
+	}
+
+	approve := fmt.Sprintf("/api/v1/approve/%s/%d/report", submitted.ReportID, reporter.ID)
+	s.JSON(t, s.Do(t, http.MethodGet, approve, adminToken, nil), http.StatusOK, nil)
+
+	e2e.Eventually(t, 10*time.Second, "the in-app notification", func() bool {
+		var notifications []models.Notification
+		s.JSON(t, s.Do(t, http.MethodGet, "/api/v1/me/notifications", reporterToken, nil), http.StatusOK, &notifications)
+		for _, notification := range notifications {
+			if strings.Contains(notification.Message, "verified") {
+				return true
+			}
+		}
+		return false
+	})
+
+	e2e.Eventually(t, 10*time.Second, "the email notification", func() bool {
+		if _, err := s.App.Server.NotificationService.FlushPending(); err != nil {
+			t.Fatalf("FlushPending: %v", err)
+		}
+		return len(s.Mail.SentTo(reporter.Email)) > 0
+	})
+}
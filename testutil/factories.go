@@ -0,0 +1,142 @@
+package testutil
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/techagentng/citizenx/models"
+	"gorm.io/gorm"
+)
+
+var sequence uint64
+
+// next returns a number unique within the test binary, used to keep unique
+// columns such as email and telephone from colliding.
+func next() uint64 {
+	return atomic.AddUint64(&sequence, 1)
+}
+
+// CreateUser inserts a user with the User role. Overrides are applied before
+// the insert.
+func CreateUser(t testing.TB, tx *gorm.DB, overrides ...func(*models.User)) *models.User {
+	t.Helper()
+
+	var role models.Role
+	if err := tx.Where("name = ?", models.RoleUser).First(&role).Error; err != nil {
+		t.Fatalf("loading %s role: %v", models.RoleUser, err)
+	}
+
+	n := next()
+	user := &models.User{
+		Fullname:       fmt.Sprintf("Test User %d", n),
+		Username:       fmt.Sprintf("testuser%d", n),
+		Telephone:      fmt.Sprintf("+23480%08d", n),
+		Email:          fmt.Sprintf("testuser%d@example.com", n),
+		HashedPassword: "not-a-real-hash",
+		IsEmailActive:  true,
+		LGAName:        "Ikeja",
+		RoleID:         role.ID,
+	}
+	for _, override := range overrides {
+		override(user)
+	}
+
+	if err := tx.Create(user).Error; err != nil {
+		t.Fatalf("creating user: %v", err)
+	}
+	return user
+}
+
+// CreateReportType inserts a report type. A user is created for it unless
+// an override sets UserID.
+func CreateReportType(t testing.TB, tx *gorm.DB, overrides ...func(*models.ReportType)) *models.ReportType {
+	t.Helper()
+
+	reportType := &models.ReportType{
+		ID:                   uuid.New(),
+		Category:             "Roads",
+		StateName:            "Lagos",
+		LGAName:              "Ikeja",
+		IncidentReportRating: "bad",
+		DateOfIncidence:      time.Now(),
+	}
+	for _, override := range overrides {
+		override(reportType)
+	}
+	if reportType.UserID == 0 {
+		reportType.UserID = CreateUser(t, tx).ID
+	}
+
+	if err := tx.Create(reportType).Error; err != nil {
+		t.Fatalf("creating report type: %v", err)
+	}
+	return reportType
+}
+
+// CreateIncidentReport inserts an incident report. A user and a matching
+// report type are created for it unless overrides set UserID and
+// ReportTypeID.
+func CreateIncidentReport(t testing.TB, tx *gorm.DB, overrides ...func(*models.IncidentReport)) *models.IncidentReport {
+	t.Helper()
+
+	n := next()
+	report := &models.IncidentReport{
+		ID:              uuid.New(),
+		CreatedAt:       time.Now().Unix(),
+		Description:     fmt.Sprintf("Test incident %d", n),
+		StateName:       "Lagos",
+		LGAName:         "Ikeja",
+		Latitude:        6.6018,
+		Longitude:       3.3515,
+		Category:        "Roads",
+		TimeofIncidence: time.Now(),
+		DateOfIncidence: time.Now().Format("2006-01-02"),
+		ReportStatus:    "pending",
+	}
+	for _, override := range overrides {
+		override(report)
+	}
+	if report.UserID == 0 {
+		report.UserID = CreateUser(t, tx).ID
+	}
+	if report.ReportTypeID == uuid.Nil {
+		report.ReportTypeID = CreateReportType(t, tx, func(rt *models.ReportType) {
+			rt.UserID = report.UserID
+			rt.IncidentReportID = report.ID
+			rt.Category = report.Category
+			rt.StateName = report.StateName
+			rt.LGAName = report.LGAName
+		}).ID
+	}
+
+	if err := tx.Create(report).Error; err != nil {
+		t.Fatalf("creating incident report: %v", err)
+	}
+	return report
+}
+
+// CreateReward inserts a reward. A user is created for it unless an override
+// sets UserID.
+func CreateReward(t testing.TB, tx *gorm.DB, overrides ...func(*models.Reward)) *models.Reward {
+	t.Helper()
+
+	reward := &models.Reward{
+		RewardType: "incident_report",
+		Point:      10,
+		Balance:    10,
+	}
+	for _, override := range overrides {
+		override(reward)
+	}
+	if reward.UserID == 0 {
+		reward.UserID = CreateUser(t, tx).ID
+	}
+
+	if err := tx.Create(reward).Error; err != nil {
+		t.Fatalf("creating reward: %v", err)
+	}
+	return reward
+}
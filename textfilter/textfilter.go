@@ -0,0 +1,75 @@
+// Package textfilter masks personal data and banned words in user written
+// text before it is shown publicly.
+package textfilter
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+var (
+	// phoneNumber matches Nigerian mobile numbers written locally or with
+	// the country code, with optional spaces or dashes between digits
+	phoneNumber = regexp.MustCompile(`(?:\+?234[\s-]?|\b0)[789][01](?:[\s-]?\d){8}\b`)
+	// longNumber matches runs of 10 to 19 digits, the length of bank account
+	// (NUBAN) and card numbers
+	longNumber = regexp.MustCompile(`\b\d(?:[\s-]?\d){9,18}\b`)
+)
+
+// Filter masks phone numbers, account numbers and a configurable list of
+// banned words.
+type Filter struct {
+	banned *regexp.Regexp
+}
+
+// New returns a filter for the given banned words. Matching ignores case
+// and only whole words are masked.
+func New(bannedWords []string) *Filter {
+	var quoted []string
+	for _, word := range bannedWords {
+		if word = strings.TrimSpace(word); word != "" {
+			quoted = append(quoted, regexp.QuoteMeta(word))
+		}
+	}
+
+	f := &Filter{}
+	if len(quoted) > 0 {
+		f.banned = regexp.MustCompile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)\b`)
+	}
+	return f
+}
+
+// Mask returns text with every digit of phone and account numbers replaced
+// by an asterisk and banned words reduced to their first letter followed by
+// asterisks.
+func (f *Filter) Mask(text string) string {
+	text = phoneNumber.ReplaceAllStringFunc(text, maskDigits)
+	text = longNumber.ReplaceAllStringFunc(text, maskDigits)
+	if f.banned != nil {
+		text = f.banned.ReplaceAllStringFunc(text, maskWord)
+	}
+	return text
+}
+
+// HasBanned reports whether text contains a banned word
+func (f *Filter) HasBanned(text string) bool {
+	return f.banned != nil && f.banned.MatchString(text)
+}
+
+func maskDigits(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return '*'
+		}
+		return r
+	}, s)
+}
+
+func maskWord(s string) string {
+	runes := []rune(s)
+	for i := 1; i < len(runes); i++ {
+		runes[i] = '*'
+	}
+	return string(runes)
+}
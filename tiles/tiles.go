@@ -0,0 +1,177 @@
+// Package tiles encodes report points as Mapbox Vector Tiles (MVT 2.1) in
+// the Web Mercator z/x/y tiling scheme.
+package tiles
+
+import (
+	"errors"
+	"math"
+	"sort"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Extent is the number of units across a tile's edge.
+const Extent = 4096
+
+// MaxZoom is the deepest zoom level tiles are served for.
+const MaxZoom = 22
+
+// ErrInvalidTile is returned for coordinates outside the tiling scheme.
+var ErrInvalidTile = errors.New("invalid tile coordinates")
+
+// Tile addresses one tile.
+type Tile struct {
+	Z, X, Y int
+}
+
+// Validate reports whether the tile exists at its zoom level.
+func (t Tile) Validate() error {
+	if t.Z < 0 || t.Z > MaxZoom {
+		return ErrInvalidTile
+	}
+	n := 1 << t.Z
+	if t.X < 0 || t.X >= n || t.Y < 0 || t.Y >= n {
+		return ErrInvalidTile
+	}
+	return nil
+}
+
+// Bounds returns the tile's extent in degrees.
+func (t Tile) Bounds() (west, south, east, north float64) {
+	n := float64(int(1) << t.Z)
+	west = float64(t.X)/n*360 - 180
+	east = float64(t.X+1)/n*360 - 180
+	north = tileLat(float64(t.Y), n)
+	south = tileLat(float64(t.Y+1), n)
+	return
+}
+
+func tileLat(y, n float64) float64 {
+	return math.Atan(math.Sinh(math.Pi*(1-2*y/n))) * 180 / math.Pi
+}
+
+// Feature is a point in tile units with its properties. Property values may
+// be strings, integers or floats.
+type Feature struct {
+	X, Y       int
+	Properties map[string]interface{}
+}
+
+// MVT protobuf field numbers, from vector_tile.proto.
+const (
+	tileLayers = 3
+
+	layerName     = 1
+	layerFeatures = 2
+	layerKeys     = 3
+	layerValues   = 4
+	layerExtent   = 5
+	layerVersion  = 15
+
+	featureTags     = 2
+	featureType     = 3
+	featureGeometry = 4
+
+	valueString = 1
+	valueDouble = 3
+	valueInt    = 4
+
+	geomTypePoint = 1
+	cmdMoveTo     = 1
+)
+
+// Encode returns a tile holding a single point layer.
+func Encode(layer string, features []Feature) []byte {
+	var (
+		keys      []string
+		keyIndex  = map[string]uint64{}
+		values    [][]byte
+		valueIdx  = map[string]uint64{}
+		encodedFs [][]byte
+	)
+
+	for _, f := range features {
+		names := make([]string, 0, len(f.Properties))
+		for name := range f.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		var tags []byte
+		for _, name := range names {
+			value := encodeValue(f.Properties[name])
+			if value == nil {
+				continue
+			}
+			k, ok := keyIndex[name]
+			if !ok {
+				k = uint64(len(keys))
+				keyIndex[name] = k
+				keys = append(keys, name)
+			}
+			v, ok := valueIdx[string(value)]
+			if !ok {
+				v = uint64(len(values))
+				valueIdx[string(value)] = v
+				values = append(values, value)
+			}
+			tags = protowire.AppendVarint(tags, k)
+			tags = protowire.AppendVarint(tags, v)
+		}
+
+		var geometry []byte
+		geometry = protowire.AppendVarint(geometry, cmdMoveTo|1<<3)
+		geometry = protowire.AppendVarint(geometry, protowire.EncodeZigZag(int64(f.X)))
+		geometry = protowire.AppendVarint(geometry, protowire.EncodeZigZag(int64(f.Y)))
+
+		var feature []byte
+		feature = appendBytes(feature, featureTags, tags)
+		feature = protowire.AppendTag(feature, featureType, protowire.VarintType)
+		feature = protowire.AppendVarint(feature, geomTypePoint)
+		feature = appendBytes(feature, featureGeometry, geometry)
+		encodedFs = append(encodedFs, feature)
+	}
+
+	var l []byte
+	l = protowire.AppendTag(l, layerVersion, protowire.VarintType)
+	l = protowire.AppendVarint(l, 2)
+	l = appendBytes(l, layerName, []byte(layer))
+	for _, f := range encodedFs {
+		l = appendBytes(l, layerFeatures, f)
+	}
+	for _, k := range keys {
+		l = appendBytes(l, layerKeys, []byte(k))
+	}
+	for _, v := range values {
+		l = appendBytes(l, layerValues, v)
+	}
+	l = protowire.AppendTag(l, layerExtent, protowire.VarintType)
+	l = protowire.AppendVarint(l, Extent)
+
+	return appendBytes(nil, tileLayers, l)
+}
+
+func encodeValue(v interface{}) []byte {
+	var b []byte
+	switch v := v.(type) {
+	case string:
+		b = appendBytes(b, valueString, []byte(v))
+	case int:
+		b = protowire.AppendTag(b, valueInt, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(v))
+	case int64:
+		b = protowire.AppendTag(b, valueInt, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(v))
+	case float64:
+		b = protowire.AppendTag(b, valueDouble, protowire.Fixed64Type)
+		b = protowire.AppendFixed64(b, math.Float64bits(v))
+	default:
+		return nil
+	}
+	return b
+}
+
+func appendBytes(b []byte, field protowire.Number, v []byte) []byte {
+	b = protowire.AppendTag(b, field, protowire.BytesType)
+	return protowire.AppendBytes(b, v)
+}
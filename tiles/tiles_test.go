@@ -0,0 +1,239 @@
+package tiles_test
+
+import (
+	"bytes"
+	"flag"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/techagentng/citizenx/tiles"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden tiles in testdata")
+
+// goldenTiles are encoded and compared byte for byte with testdata/<name>.mvt
+var goldenTiles = []struct {
+	name     string
+	layer    string
+	features []tiles.Feature
+}{
+	{"empty", "reports", nil},
+	{"clusters", "reports", []tiles.Feature{
+		{X: 128, Y: 3968, Properties: map[string]interface{}{"point_count": 12}},
+		{X: 2048, Y: 2048, Properties: map[string]interface{}{
+			"point_count": 1,
+			"report_id":   "0b0f8a52-7c1e-4a39-9d55-1b5e3c9f2a10",
+			"category":    "Roads",
+		}},
+		// Points just outside the tile, in its buffer, have negative
+		// coordinates
+		{X: -16, Y: 4100, Properties: map[string]interface{}{"point_count": 12, "weight": 0.5}},
+	}},
+	{"unsupported_property", "reports", []tiles.Feature{
+		{X: 1, Y: 2, Properties: map[string]interface{}{"point_count": int64(3), "skipped": true}},
+	}},
+}
+
+func TestEncodeGolden(t *testing.T) {
+	for _, tc := range goldenTiles {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tiles.Encode(tc.layer, tc.features)
+			path := filepath.Join("testdata", tc.name+".mvt")
+			if *update {
+				if err := os.WriteFile(path, got, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("reading golden tile: %v (run with -update to create it)", err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Fatalf("tile differs from %s:\n got %x\nwant %x", path, got, want)
+			}
+		})
+	}
+}
+
+// decodedFeature is a feature read back from a tile, with its tags resolved
+type decodedFeature struct {
+	Type       uint64
+	Geometry   []uint64
+	Properties map[string]interface{}
+}
+
+type decodedLayer struct {
+	Version  uint64
+	Name     string
+	Extent   uint64
+	Features []decodedFeature
+}
+
+// TestEncodeDecodes reads the golden clusters tile back field by field, to
+// check the encoding against the vector tile spec rather than against itself
+func TestEncodeDecodes(t *testing.T) {
+	layer := decodeTile(t, tiles.Encode(goldenTiles[1].layer, goldenTiles[1].features))
+
+	if layer.Version != 2 || layer.Name != "reports" || layer.Extent != tiles.Extent {
+		t.Fatalf("got layer version %d, name %q, extent %d", layer.Version, layer.Name, layer.Extent)
+	}
+	want := []decodedFeature{
+		{Type: 1, Geometry: []uint64{9, 256, 7936}, Properties: map[string]interface{}{"point_count": int64(12)}},
+		{Type: 1, Geometry: []uint64{9, 4096, 4096}, Properties: map[string]interface{}{
+			"point_count": int64(1),
+			"report_id":   "0b0f8a52-7c1e-4a39-9d55-1b5e3c9f2a10",
+			"category":    "Roads",
+		}},
+		{Type: 1, Geometry: []uint64{9, 31, 8200}, Properties: map[string]interface{}{"point_count": int64(12), "weight": 0.5}},
+	}
+	if !reflect.DeepEqual(layer.Features, want) {
+		t.Fatalf("got features %+v\nwant %+v", layer.Features, want)
+	}
+}
+
+func decodeTile(t *testing.T, tile []byte) decodedLayer {
+	t.Helper()
+	var layers [][]byte
+	eachField(t, tile, func(num protowire.Number, typ protowire.Type, b []byte) int {
+		if num != 3 || typ != protowire.BytesType {
+			t.Fatalf("unexpected tile field %d", num)
+		}
+		v, n := protowire.ConsumeBytes(b)
+		layers = append(layers, v)
+		return n
+	})
+	if len(layers) != 1 {
+		t.Fatalf("got %d layers, want 1", len(layers))
+	}
+
+	var layer decodedLayer
+	var keys []string
+	var values []interface{}
+	var features [][]byte
+	eachField(t, layers[0], func(num protowire.Number, typ protowire.Type, b []byte) int {
+		switch num {
+		case 1, 2, 3, 4:
+			v, n := protowire.ConsumeBytes(b)
+			switch num {
+			case 1:
+				layer.Name = string(v)
+			case 2:
+				features = append(features, v)
+			case 3:
+				keys = append(keys, string(v))
+			case 4:
+				values = append(values, decodeValue(t, v))
+			}
+			return n
+		case 5, 15:
+			v, n := protowire.ConsumeVarint(b)
+			if num == 5 {
+				layer.Extent = v
+			} else {
+				layer.Version = v
+			}
+			return n
+		}
+		t.Fatalf("unexpected layer field %d", num)
+		return 0
+	})
+
+	for _, raw := range features {
+		f := decodedFeature{Properties: map[string]interface{}{}}
+		eachField(t, raw, func(num protowire.Number, typ protowire.Type, b []byte) int {
+			switch num {
+			case 2, 4:
+				v, n := protowire.ConsumeBytes(b)
+				packed := unpack(t, v)
+				if num == 4 {
+					f.Geometry = packed
+					return n
+				}
+				for i := 0; i+1 < len(packed); i += 2 {
+					f.Properties[keys[packed[i]]] = values[packed[i+1]]
+				}
+				return n
+			case 3:
+				v, n := protowire.ConsumeVarint(b)
+				f.Type = v
+				return n
+			}
+			t.Fatalf("unexpected feature field %d", num)
+			return 0
+		})
+		layer.Features = append(layer.Features, f)
+	}
+	return layer
+}
+
+func decodeValue(t *testing.T, b []byte) interface{} {
+	t.Helper()
+	var value interface{}
+	eachField(t, b, func(num protowire.Number, typ protowire.Type, b []byte) int {
+		switch num {
+		case 1:
+			v, n := protowire.ConsumeBytes(b)
+			value = string(v)
+			return n
+		case 3:
+			v, n := protowire.ConsumeFixed64(b)
+			value = math.Float64frombits(v)
+			return n
+		case 4:
+			v, n := protowire.ConsumeVarint(b)
+			value = int64(v)
+			return n
+		}
+		t.Fatalf("unexpected value field %d", num)
+		return 0
+	})
+	return value
+}
+
+// eachField calls fn with the body of every field in b; fn returns how many
+// bytes of the body it consumed
+func eachField(t *testing.T, b []byte, fn func(num protowire.Number, typ protowire.Type, body []byte) int) {
+	t.Helper()
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			t.Fatalf("bad tag: %v", protowire.ParseError(n))
+		}
+		b = b[n:]
+		n = fn(num, typ, b)
+		if n < 0 {
+			t.Fatalf("bad field %d: %v", num, protowire.ParseError(n))
+		}
+		b = b[n:]
+	}
+}
+
+func unpack(t *testing.T, b []byte) []uint64 {
+	t.Helper()
+	var out []uint64
+	for len(b) > 0 {
+		v, n := protowire.ConsumeVarint(b)
+		if n < 0 {
+			t.Fatalf("bad packed varint: %v", protowire.ParseError(n))
+		}
+		out = append(out, v)
+		b = b[n:]
+	}
+	return out
+}
+
+func TestTileBounds(t *testing.T) {
+	west, south, east, north := tiles.Tile{Z: 0}.Bounds()
+	if west != -180 || east != 180 || math.Abs(north-85.0511) > 1e-4 || math.Abs(south+85.0511) > 1e-4 {
+		t.Fatalf("got world bounds %f %f %f %f", west, south, east, north)
+	}
+	for _, tile := range []tiles.Tile{{Z: -1}, {Z: tiles.MaxZoom + 1}, {Z: 1, X: 2}, {Z: 3, Y: -1}} {
+		if err := tile.Validate(); err == nil {
+			t.Errorf("tile %+v validated", tile)
+		}
+	}
+}
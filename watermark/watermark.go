@@ -0,0 +1,102 @@
+// Package watermark stamps public image renditions with a provenance mark
+// that can be checked against the server's records.
+package watermark
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"time"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+// Brand is the mark shown at the start of every watermark.
+const Brand = "CitizenX"
+
+// codeLength is the number of hex characters of the signature printed on the
+// image, short enough to read off a photo and still hard to guess
+const codeLength = 12
+
+// Mark is the provenance stamped on a rendition: the report it belongs to,
+// when it was processed and a code signing both together with the digest of
+// the original upload.
+type Mark struct {
+	ReportID  string
+	Timestamp time.Time
+	Code      string
+}
+
+// New returns the mark for an original whose SHA-256 is digest. Altering the
+// report ID or timestamp printed on an image breaks the code, and only the
+// holder of secret can produce a matching one.
+func New(secret, reportID string, at time.Time, digest string) Mark {
+	at = at.UTC().Truncate(time.Second)
+	return Mark{
+		ReportID:  reportID,
+		Timestamp: at,
+		Code:      sign(secret, reportID, at, digest),
+	}
+}
+
+// Verify reports whether the mark was issued for the original with the given
+// digest.
+func Verify(secret string, m Mark, digest string) bool {
+	return hmac.Equal([]byte(m.Code), []byte(sign(secret, m.ReportID, m.Timestamp, digest)))
+}
+
+func sign(secret, reportID string, at time.Time, digest string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%s|%d|%s", reportID, at.Unix(), digest)
+	return hex.EncodeToString(mac.Sum(nil))[:codeLength]
+}
+
+// Text returns the full watermark line.
+func (m Mark) Text() string {
+	return fmt.Sprintf("%s | %s | %s | %s", Brand, m.ReportID, m.Timestamp.Format(time.RFC3339), m.Code)
+}
+
+// shortText is used on renditions too narrow for the full line.
+func (m Mark) shortText() string {
+	return Brand + " " + m.Code
+}
+
+// Apply returns a copy of img with the mark drawn on a translucent band along
+// the bottom edge. Images too small to hold even the short form are returned
+// unmarked.
+func Apply(img image.Image, m Mark) image.Image {
+	face := basicfont.Face7x13
+	const padding = 4
+
+	bounds := img.Bounds()
+	text := m.Text()
+	if font.MeasureString(face, text).Ceil()+2*padding > bounds.Dx() {
+		text = m.shortText()
+	}
+	width := font.MeasureString(face, text).Ceil() + 2*padding
+	height := face.Metrics().Height.Ceil() + 2*padding
+	if width > bounds.Dx() || height > bounds.Dy() {
+		return img
+	}
+
+	out := image.NewRGBA(bounds)
+	draw.Draw(out, bounds, img, bounds.Min, draw.Src)
+
+	band := image.Rect(bounds.Min.X, bounds.Max.Y-height, bounds.Max.X, bounds.Max.Y)
+	draw.Draw(out, band, image.NewUniform(color.NRGBA{A: 140}), image.Point{}, draw.Over)
+
+	drawer := font.Drawer{
+		Dst:  out,
+		Src:  image.NewUniform(color.White),
+		Face: face,
+		Dot:  fixed.P(bounds.Max.X-width+padding, bounds.Max.Y-padding-face.Metrics().Descent.Ceil()),
+	}
+	drawer.DrawString(text)
+	return out
+}